@@ -0,0 +1,176 @@
+package smartmontools
+
+import (
+	"slices"
+)
+
+// AttributePolarity describes which direction of change indicates degradation.
+type AttributePolarity int
+
+const (
+	// PolarityNeutral marks informational counters where neither direction is bad
+	// (e.g. power-on hours) or where the value is vendor-encoded and not comparable.
+	PolarityNeutral AttributePolarity = iota
+	// PolarityHigherIsWorse marks attributes where growth indicates degradation.
+	PolarityHigherIsWorse
+	// PolarityLowerIsWorse marks attributes where a decrease indicates degradation.
+	PolarityLowerIsWorse
+)
+
+// String returns the polarity name.
+func (p AttributePolarity) String() string {
+	switch p {
+	case PolarityHigherIsWorse:
+		return "higher_is_worse"
+	case PolarityLowerIsWorse:
+		return "lower_is_worse"
+	default:
+		return "neutral"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p AttributePolarity) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// AttributeCriticality classifies how strongly an attribute is linked to drive failure.
+type AttributeCriticality int
+
+const (
+	// CriticalityInformational attributes describe usage or environment.
+	CriticalityInformational AttributeCriticality = iota
+	// CriticalityWarning attributes indicate wear or secondary problems worth watching.
+	CriticalityWarning
+	// CriticalityCritical attributes correlate strongly with imminent failure when non-zero.
+	CriticalityCritical
+)
+
+// String returns the criticality name.
+func (c AttributeCriticality) String() string {
+	switch c {
+	case CriticalityCritical:
+		return "critical"
+	case CriticalityWarning:
+		return "warning"
+	default:
+		return "informational"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c AttributeCriticality) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// AttributeUnit names the unit of the meaningful attribute value.
+type AttributeUnit string
+
+// Attribute units used by the registry.
+const (
+	UnitNone         AttributeUnit = ""
+	UnitCount        AttributeUnit = "count"
+	UnitSectors      AttributeUnit = "sectors"
+	UnitHours        AttributeUnit = "hours"
+	UnitMilliseconds AttributeUnit = "ms"
+	UnitCelsius      AttributeUnit = "celsius"
+	UnitPercent      AttributeUnit = "percent"
+	UnitLBAs         AttributeUnit = "lbas"
+)
+
+// AttributeDescriptor holds the semantic metadata for a SMART attribute ID.
+type AttributeDescriptor struct {
+	ID          int                  `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Unit        AttributeUnit        `json:"unit,omitempty"`
+	Polarity    AttributePolarity    `json:"polarity"`
+	Criticality AttributeCriticality `json:"criticality"`
+	// Normalized is true when the normalized value, rather than the raw value,
+	// carries the meaning (e.g. remaining-life percentages).
+	Normalized bool `json:"normalized,omitempty"`
+	// DiskType restricts the meaning to "HDD" or "SSD"; empty applies to both.
+	DiskType string `json:"disk_type,omitempty"`
+}
+
+// attributeRegistry maps well-known attribute IDs to their descriptors. Only
+// IDs with a widely agreed meaning are listed; vendor-overloaded IDs (e.g. 202,
+// 234) are deliberately left out.
+var attributeRegistry = map[int]AttributeDescriptor{
+	1:   {ID: 1, Name: "Raw_Read_Error_Rate", Description: "Rate of hardware read errors; raw value is vendor-encoded", Unit: UnitCount},
+	2:   {ID: 2, Name: "Throughput_Performance", Description: "Overall throughput performance", Polarity: PolarityLowerIsWorse, Normalized: true, DiskType: "HDD"},
+	3:   {ID: 3, Name: "Spin_Up_Time", Description: "Average time to spin up the spindle", Unit: UnitMilliseconds, Polarity: PolarityHigherIsWorse, DiskType: "HDD"},
+	4:   {ID: 4, Name: "Start_Stop_Count", Description: "Number of spindle start/stop cycles", Unit: UnitCount, DiskType: "HDD"},
+	5:   {ID: 5, Name: "Reallocated_Sector_Ct", Description: "Sectors remapped to the spare area after read/write failures", Unit: UnitSectors, Polarity: PolarityHigherIsWorse, Criticality: CriticalityCritical},
+	7:   {ID: 7, Name: "Seek_Error_Rate", Description: "Rate of seek errors; raw value is vendor-encoded", Unit: UnitCount, DiskType: "HDD"},
+	8:   {ID: 8, Name: "Seek_Time_Performance", Description: "Average seek performance", Polarity: PolarityLowerIsWorse, Normalized: true, DiskType: "HDD"},
+	9:   {ID: 9, Name: "Power_On_Hours", Description: "Time spent in the powered-on state", Unit: UnitHours},
+	10:  {ID: 10, Name: "Spin_Retry_Count", Description: "Spin-up attempts that needed a retry", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "HDD"},
+	11:  {ID: 11, Name: "Calibration_Retry_Count", Description: "Recalibrations that needed a retry", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "HDD"},
+	12:  {ID: 12, Name: "Power_Cycle_Count", Description: "Number of full power on/off cycles", Unit: UnitCount},
+	13:  {ID: 13, Name: "Read_Soft_Error_Rate", Description: "Uncorrected read errors reported to the operating system", Unit: UnitCount, Polarity: PolarityHigherIsWorse},
+	22:  {ID: 22, Name: "Helium_Level", Description: "Remaining helium level of sealed drives", Unit: UnitPercent, Polarity: PolarityLowerIsWorse, Criticality: CriticalityWarning, Normalized: true, DiskType: "HDD"},
+	160: {ID: 160, Name: "Uncorrectable_Error_Cnt", Description: "Uncorrectable sectors found during read/write", Unit: UnitSectors, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	161: {ID: 161, Name: "Valid_Spare_Block_Cnt", Description: "Spare blocks still available", Unit: UnitCount, Polarity: PolarityLowerIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	169: {ID: 169, Name: "Remaining_Lifetime_Perc", Description: "Estimated remaining endurance", Unit: UnitPercent, Polarity: PolarityLowerIsWorse, Criticality: CriticalityWarning, Normalized: true, DiskType: "SSD"},
+	170: {ID: 170, Name: "Available_Reservd_Space", Description: "Reserved blocks still available", Unit: UnitPercent, Polarity: PolarityLowerIsWorse, Criticality: CriticalityWarning, Normalized: true, DiskType: "SSD"},
+	171: {ID: 171, Name: "Program_Fail_Count", Description: "Flash program operations that failed", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	172: {ID: 172, Name: "Erase_Fail_Count", Description: "Flash erase operations that failed", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	173: {ID: 173, Name: "Wear_Leveling_Count", Description: "Flash wear indicator; raw value is often the average erase count", Unit: UnitCount, Polarity: PolarityHigherIsWorse, DiskType: "SSD"},
+	174: {ID: 174, Name: "Unexpect_Power_Loss_Ct", Description: "Power losses without a clean shutdown", Unit: UnitCount, DiskType: "SSD"},
+	175: {ID: 175, Name: "Program_Fail_Count_Chip", Description: "Flash program failures on the worst chip", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	176: {ID: 176, Name: "Erase_Fail_Count_Chip", Description: "Flash erase failures on the worst chip", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	177: {ID: 177, Name: "Wear_Leveling_Count", Description: "Remaining flash endurance; raw value is the erase count", Unit: UnitPercent, Polarity: PolarityLowerIsWorse, Criticality: CriticalityWarning, Normalized: true, DiskType: "SSD"},
+	179: {ID: 179, Name: "Used_Rsvd_Blk_Cnt_Tot", Description: "Reserved blocks consumed to replace bad ones", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	180: {ID: 180, Name: "Unused_Rsvd_Blk_Cnt_Tot", Description: "Reserved blocks still unused", Unit: UnitCount, Polarity: PolarityLowerIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	181: {ID: 181, Name: "Program_Fail_Cnt_Total", Description: "Total flash program failures", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	182: {ID: 182, Name: "Erase_Fail_Count_Total", Description: "Total flash erase failures", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "SSD"},
+	183: {ID: 183, Name: "Runtime_Bad_Block", Description: "Bad blocks found at runtime or SATA downshifts, depending on vendor", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning},
+	184: {ID: 184, Name: "End-to-End_Error", Description: "Parity errors in the data path between host and media", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityCritical},
+	187: {ID: 187, Name: "Reported_Uncorrect", Description: "Errors that could not be recovered by hardware ECC", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityCritical},
+	188: {ID: 188, Name: "Command_Timeout", Description: "Operations aborted because of a device timeout", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityCritical},
+	189: {ID: 189, Name: "High_Fly_Writes", Description: "Writes with the head flying outside its normal range", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "HDD"},
+	190: {ID: 190, Name: "Airflow_Temperature_Cel", Description: "Airflow temperature", Unit: UnitCelsius, Polarity: PolarityHigherIsWorse},
+	191: {ID: 191, Name: "G-Sense_Error_Rate", Description: "Errors caused by externally induced shock or vibration", Unit: UnitCount, Polarity: PolarityHigherIsWorse, DiskType: "HDD"},
+	192: {ID: 192, Name: "Power-Off_Retract_Count", Description: "Emergency head retracts on power loss", Unit: UnitCount},
+	193: {ID: 193, Name: "Load_Cycle_Count", Description: "Head load/unload cycles", Unit: UnitCount, Polarity: PolarityHigherIsWorse, DiskType: "HDD"},
+	194: {ID: 194, Name: "Temperature_Celsius", Description: "Internal drive temperature", Unit: UnitCelsius, Polarity: PolarityHigherIsWorse},
+	195: {ID: 195, Name: "Hardware_ECC_Recovered", Description: "Errors corrected by hardware ECC; raw value is vendor-encoded", Unit: UnitCount},
+	196: {ID: 196, Name: "Reallocated_Event_Count", Description: "Remap operations, successful or not", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning},
+	197: {ID: 197, Name: "Current_Pending_Sector", Description: "Unstable sectors waiting to be remapped", Unit: UnitSectors, Polarity: PolarityHigherIsWorse, Criticality: CriticalityCritical},
+	198: {ID: 198, Name: "Offline_Uncorrectable", Description: "Sectors that could not be read during offline scans", Unit: UnitSectors, Polarity: PolarityHigherIsWorse, Criticality: CriticalityCritical},
+	199: {ID: 199, Name: "UDMA_CRC_Error_Count", Description: "Interface CRC errors, usually caused by cabling or backplanes", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning},
+	200: {ID: 200, Name: "Multi_Zone_Error_Rate", Description: "Errors found while writing a sector", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "HDD"},
+	201: {ID: 201, Name: "Soft_Read_Error_Rate", Description: "Off-track read errors", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning},
+	220: {ID: 220, Name: "Disk_Shift", Description: "Distance the platters shifted relative to the spindle", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "HDD"},
+	222: {ID: 222, Name: "Loaded_Hours", Description: "Time spent with the heads loaded", Unit: UnitHours, DiskType: "HDD"},
+	223: {ID: 223, Name: "Load_Retry_Count", Description: "Head loads that needed a retry", Unit: UnitCount, Polarity: PolarityHigherIsWorse, Criticality: CriticalityWarning, DiskType: "HDD"},
+	224: {ID: 224, Name: "Load_Friction", Description: "Mechanical friction while loading the heads", Unit: UnitCount, Polarity: PolarityHigherIsWorse, DiskType: "HDD"},
+	226: {ID: 226, Name: "Load-in_Time", Description: "Time spent loading the heads", Unit: UnitMilliseconds, DiskType: "HDD"},
+	231: {ID: 231, Name: "SSD_Life_Left", Description: "Remaining flash endurance", Unit: UnitPercent, Polarity: PolarityLowerIsWorse, Criticality: CriticalityWarning, Normalized: true, DiskType: "SSD"},
+	232: {ID: 232, Name: "Available_Reservd_Space", Description: "Reserved space still available", Unit: UnitPercent, Polarity: PolarityLowerIsWorse, Criticality: CriticalityWarning, Normalized: true, DiskType: "SSD"},
+	233: {ID: 233, Name: "Media_Wearout_Indicator", Description: "Remaining flash endurance", Unit: UnitPercent, Polarity: PolarityLowerIsWorse, Criticality: CriticalityWarning, Normalized: true, DiskType: "SSD"},
+	240: {ID: 240, Name: "Head_Flying_Hours", Description: "Time spent with the heads positioned over the platters", Unit: UnitHours, DiskType: "HDD"},
+	241: {ID: 241, Name: "Total_LBAs_Written", Description: "Data written by the host; unit is vendor-specific", Unit: UnitLBAs},
+	242: {ID: 242, Name: "Total_LBAs_Read", Description: "Data read by the host; unit is vendor-specific", Unit: UnitLBAs},
+	250: {ID: 250, Name: "Read_Error_Retry_Rate", Description: "Read operations that needed a retry", Unit: UnitCount, Polarity: PolarityHigherIsWorse},
+	254: {ID: 254, Name: "Free_Fall_Sensor", Description: "Free-fall events detected", Unit: UnitCount, Polarity: PolarityHigherIsWorse, DiskType: "HDD"},
+}
+
+// DescribeAttribute returns the registry metadata for a SMART attribute ID.
+// It returns false for vendor-specific IDs without a widely agreed meaning;
+// callers can then fall back to the name reported by smartctl.
+func DescribeAttribute(id int) (AttributeDescriptor, bool) {
+	d, ok := attributeRegistry[id]
+	return d, ok
+}
+
+// AttributeDescriptors returns all registry entries ordered by ID.
+func AttributeDescriptors() []AttributeDescriptor {
+	out := make([]AttributeDescriptor, 0, len(attributeRegistry))
+	for _, d := range attributeRegistry {
+		out = append(out, d)
+	}
+	slices.SortFunc(out, func(a, b AttributeDescriptor) int { return a.ID - b.ID })
+	return out
+}
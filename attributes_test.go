@@ -0,0 +1,118 @@
+package smartmontools
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAttributeTable() *AtaSmartData {
+	return &AtaSmartData{Table: []SmartAttribute{
+		{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Raw: Raw{Value: 8, String: "8"}},
+		{ID: 9, Name: "Power_On_Hours", Value: 60, Raw: Raw{Value: 35825, String: "35825h+02m+39.040s"}},
+		{ID: 197, Name: "Current_Pending_Sector", Value: 100, Raw: Raw{Value: 0x0001_0000_0002, String: "2 (1 0)"}},
+		{ID: 198, Name: "Offline_Uncorrectable", Value: 100, Raw: Raw{Value: 0, String: "0"}},
+	}}
+}
+
+func TestGetAttributeByID(t *testing.T) {
+	data := newAttributeTable()
+	attr := data.GetAttributeByID(SmartAttrPowerOnHours)
+	require.NotNil(t, attr)
+	assert.Equal(t, "Power_On_Hours", attr.Name)
+	assert.Nil(t, data.GetAttributeByID(194))
+
+	var nilData *AtaSmartData
+	assert.Nil(t, nilData.GetAttributeByID(5))
+}
+
+func TestSMARTInfoAttributes(t *testing.T) {
+	info := &SMARTInfo{AtaSmartData: newAttributeTable()}
+	var ids []int
+	for attr := range info.Attributes() {
+		ids = append(ids, attr.ID)
+		if attr.ID == 197 {
+			break
+		}
+	}
+	assert.Equal(t, []int{5, 9, 197}, ids)
+	assert.Len(t, slices.Collect(info.Attributes()), 4)
+
+	var nilInfo *SMARTInfo
+	assert.Empty(t, slices.Collect(nilInfo.Attributes()))
+	assert.Empty(t, slices.Collect((&SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{}}).Attributes()))
+}
+
+func TestGetAttributeByName(t *testing.T) {
+	data := newAttributeTable()
+	attr := data.GetAttributeByName("current_pending_sector")
+	require.NotNil(t, attr)
+	assert.Equal(t, 197, attr.ID)
+	assert.Nil(t, data.GetAttributeByName("Temperature_Celsius"))
+}
+
+func TestAttributeCounterGetters(t *testing.T) {
+	data := newAttributeTable()
+
+	realloc, ok := data.ReallocatedSectors()
+	assert.True(t, ok)
+	assert.Equal(t, int64(8), realloc)
+
+	pending, ok := data.PendingSectors()
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), pending, "raw16 vendor words must not leak into the count")
+
+	offline, ok := data.OfflineUncorrectable()
+	assert.True(t, ok)
+	assert.Zero(t, offline)
+
+	_, ok = data.ReportedUncorrectable()
+	assert.False(t, ok)
+
+	var nilData *AtaSmartData
+	_, ok = nilData.ReallocatedSectors()
+	assert.False(t, ok)
+}
+
+func TestRawCount_FallsBackToValue(t *testing.T) {
+	assert.Equal(t, int64(42), Raw{Value: 42}.Count())
+	assert.Equal(t, int64(35825), Raw{Value: 1, String: "35825h+02m"}.Count())
+}
+
+func TestDescribeAttribute(t *testing.T) {
+	d, ok := DescribeAttribute(SmartAttrCurrentPendingSector)
+	require.True(t, ok)
+	assert.Equal(t, "Current_Pending_Sector", d.Name)
+	assert.Equal(t, PolarityHigherIsWorse, d.Polarity)
+	assert.Equal(t, CriticalityCritical, d.Criticality)
+	assert.Equal(t, UnitSectors, d.Unit)
+
+	d, ok = DescribeAttribute(SmartAttrSSDLifeLeft)
+	require.True(t, ok)
+	assert.True(t, d.Normalized)
+	assert.Equal(t, PolarityLowerIsWorse, d.Polarity)
+
+	_, ok = DescribeAttribute(202)
+	assert.False(t, ok)
+}
+
+func TestAttributeDescriptors_SortedAndConsistent(t *testing.T) {
+	all := AttributeDescriptors()
+	require.NotEmpty(t, all)
+	for i, d := range all {
+		if i > 0 {
+			assert.Less(t, all[i-1].ID, d.ID)
+		}
+		assert.NotEmpty(t, d.Name, "attribute %d", d.ID)
+	}
+}
+
+func TestAttributeEnumsMarshalText(t *testing.T) {
+	text, err := CriticalityWarning.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "warning", string(text))
+	assert.Equal(t, "lower_is_worse", PolarityLowerIsWorse.String())
+	assert.Equal(t, "neutral", PolarityNeutral.String())
+}
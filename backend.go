@@ -7,3 +7,30 @@ type Backend = smtypes.Backend
 
 // DiscoveryBackend extends Backend with richer device discovery details.
 type DiscoveryBackend = smtypes.DiscoveryBackend
+
+// ScanBackend extends Backend with device scans restricted by ScanOptions.
+type ScanBackend = smtypes.ScanBackend
+
+// RawBackend extends Backend with access to the original smartctl JSON output.
+type RawBackend = smtypes.RawBackend
+
+// SmartctlBackend extends Backend with a raw smartctl passthrough.
+type SmartctlBackend = smtypes.SmartctlBackend
+
+// VersionBackend extends Backend with the version of the smartctl it runs.
+type VersionBackend = smtypes.VersionBackend
+
+// ExtendedBackend extends Backend with the extended "smartctl -x" information.
+type ExtendedBackend = smtypes.ExtendedBackend
+
+// PowerModeBackend extends Backend with drive power mode checks, spindown
+// control and explicit wake-up.
+type PowerModeBackend = smtypes.PowerModeBackend
+
+// SelfTestBackend extends Backend with self-tests run with SelfTestOptions and
+// selective self-tests over LBA spans.
+type SelfTestBackend = smtypes.SelfTestBackend
+
+// FeatureBackend extends Backend with drive feature settings such as the
+// write cache and DSN.
+type FeatureBackend = smtypes.FeatureBackend
@@ -0,0 +1,108 @@
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// globDevices lists device nodes; tests replace it to fake /dev contents.
+var globDevices = filepath.Glob
+
+var (
+	// camPeripheralRe matches the peripheral list at the end of a
+	// "camcontrol devlist" line, e.g. "(ada0,pass0)".
+	camPeripheralRe = regexp.MustCompile(`\(([^()]+)\)\s*$`)
+	// freebsdDiskRe matches FreeBSD whole-disk nodes: ATA (ada), SCSI/SAS/USB
+	// (da) and NVMe controllers (nvme). Partitions such as ada0p1 are excluded.
+	freebsdDiskRe = regexp.MustCompile(`^(ada|da|nvme)\d+$`)
+)
+
+// freebsdDisks lists FreeBSD disks. "camcontrol devlist" is preferred because
+// it also exposes devices that are only reachable through a CAM passthrough
+// node (/dev/passN). /dev is globbed when camcontrol is unavailable. NVMe
+// controllers are not CAM peripherals and are always taken from /dev.
+func (b *ExecBackend) freebsdDisks(ctx context.Context) []string {
+	var paths []string
+	res, err := b.runTool(ctx, "camcontrol", "devlist")
+	if err == nil {
+		paths = parseCamcontrolDevlist(res.Stdout)
+	} else {
+		b.logHandler.DebugContext(ctx, "camcontrol enumeration failed, falling back to /dev", "err", err)
+	}
+	matches, _ := globDevices("/dev/*")
+	for _, m := range matches {
+		name := filepath.Base(m)
+		if !freebsdDiskRe.MatchString(name) {
+			continue
+		}
+		if err == nil && !strings.HasPrefix(name, "nvme") {
+			continue
+		}
+		paths = append(paths, "/dev/"+name)
+	}
+	return paths
+}
+
+// parseCamcontrolDevlist picks one node per CAM device from "camcontrol
+// devlist" output: the ada/da disk when present, otherwise its pass device.
+// Enclosures (ses), optical drives (cd) and NVMe namespaces (nda, reached via
+// their nvme controller instead) are skipped.
+func parseCamcontrolDevlist(output []byte) []string {
+	var paths []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		m := camPeripheralRe.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		var disk, pass string
+		skip := false
+		for _, p := range strings.Split(m[1], ",") {
+			p = strings.TrimSpace(p)
+			switch {
+			case strings.HasPrefix(p, "ada"), strings.HasPrefix(p, "da"):
+				disk = p
+			case strings.HasPrefix(p, "pass"):
+				pass = p
+			case strings.HasPrefix(p, "ses"), strings.HasPrefix(p, "cd"), strings.HasPrefix(p, "nda"), strings.HasPrefix(p, "sa"):
+				skip = true
+			}
+		}
+		switch {
+		case skip:
+		case disk != "":
+			paths = append(paths, "/dev/"+disk)
+		case pass != "":
+			paths = append(paths, "/dev/"+pass)
+		}
+	}
+	return paths
+}
+
+// openbsdDisks lists OpenBSD disks from "sysctl -n hw.disknames". smartctl
+// addresses them through the raw "c" partition (/dev/wd0c, /dev/sd0c).
+func (b *ExecBackend) openbsdDisks(ctx context.Context) []string {
+	res, err := b.runTool(ctx, "sysctl", "-n", "hw.disknames")
+	if err != nil {
+		b.logHandler.DebugContext(ctx, "sysctl hw.disknames failed", "err", err)
+		return nil
+	}
+	return parseOpenBSDDisknames(string(res.Stdout))
+}
+
+// parseOpenBSDDisknames parses the "sd0:4f1b2c3d4e5f6a7b,wd0:,cd0:" form of
+// hw.disknames, keeping only wd (ATA) and sd (SCSI/SATA/USB) disks.
+func parseOpenBSDDisknames(disknames string) []string {
+	var paths []string
+	for entry := range strings.SplitSeq(strings.TrimSpace(disknames), ",") {
+		name, _, _ := strings.Cut(entry, ":")
+		if strings.HasPrefix(name, "wd") || strings.HasPrefix(name, "sd") {
+			paths = append(paths, "/dev/"+name+"c")
+		}
+	}
+	return paths
+}
@@ -0,0 +1,93 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const camcontrolDevlist = `<WDC WD40EFRX-68N32N0 82.00A82>     at scbus0 target 0 lun 0 (ada0,pass0)
+<ST8000VN004-2M2101 SC60>          at scbus1 target 0 lun 0 (pass1,ada1)
+<ATA ST4000NM0033-9ZM SN06>        at scbus2 target 4 lun 0 (da0,pass2)
+<LSI SAS2X36 0e12>                 at scbus2 target 9 lun 0 (ses0,pass3)
+<HL-DT-ST DVDRAM GH24NSD1 LG00>    at scbus3 target 0 lun 0 (cd0,pass4)
+<Samsung SSD 970 EVO 2B2QEXE7>     at scbus4 target 0 lun 1 (pass5,nda0)
+<Marvell Console 1.01>             at scbus5 target 0 lun 0 (pass6)
+`
+
+func withGlob(t *testing.T, nodes []string) {
+	t.Helper()
+	orig := globDevices
+	globDevices = func(string) ([]string, error) { return nodes, nil }
+	t.Cleanup(func() { globDevices = orig })
+}
+
+func TestParseCamcontrolDevlist(t *testing.T) {
+	assert.Equal(t, []string{"/dev/ada0", "/dev/ada1", "/dev/da0", "/dev/pass6"}, parseCamcontrolDevlist([]byte(camcontrolDevlist)))
+}
+
+func TestParseOpenBSDDisknames(t *testing.T) {
+	assert.Equal(t, []string{"/dev/sd0c", "/dev/wd0c", "/dev/sd1c"},
+		parseOpenBSDDisknames("sd0:4f1b2c3d4e5f6a7b,cd0:,wd0:0123456789abcdef,sd1:\n"))
+	assert.Empty(t, parseOpenBSDDisknames(""))
+}
+
+func TestFreeBSDDisks_CamcontrolWithNVMe(t *testing.T) {
+	withGlob(t, []string{"/dev/ada0", "/dev/ada0p1", "/dev/nvme0", "/dev/nvme0ns1", "/dev/nda0"})
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"camcontrol devlist": {output: []byte(camcontrolDevlist)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/local/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/dev/ada0", "/dev/ada1", "/dev/da0", "/dev/pass6", "/dev/nvme0"}, b.freebsdDisks(context.Background()))
+}
+
+func TestFreeBSDDisks_GlobFallback(t *testing.T) {
+	withGlob(t, []string{"/dev/ada0", "/dev/ada0p1", "/dev/da3", "/dev/da3s1a", "/dev/nvme1", "/dev/pass0", "/dev/null"})
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"camcontrol devlist": {err: errors.New("not found")},
+	}}
+	b, err := New(WithSmartctlPath("/usr/local/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/dev/ada0", "/dev/da3", "/dev/nvme1"}, b.freebsdDisks(context.Background()))
+}
+
+func TestScanDevices_FreeBSDProbesMissingDisks(t *testing.T) {
+	withGOOS(t, "freebsd")
+	withGlob(t, []string{"/dev/ada0", "/dev/da0", "/dev/nvme0"})
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/local/sbin/smartctl --scan-open --json": {output: []byte(`{"devices":[{"name":"/dev/ada0","type":"atacam"},{"name":"/dev/nvme0","type":"nvme"}]}`)},
+		"camcontrol devlist":                          {err: errors.New("not found")},
+		"/usr/local/sbin/smartctl -i -j /dev/da0":     {output: []byte(`{"device":{"name":"/dev/da0","type":"scsi"}}`)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/local/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	devices, err := b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Device{
+		{Name: "/dev/ada0", Type: "atacam"},
+		{Name: "/dev/nvme0", Type: "nvme"},
+		{Name: "/dev/da0", Type: "scsi"},
+	}, devices)
+}
+
+func TestScanDevices_OpenBSD(t *testing.T) {
+	withGOOS(t, "openbsd")
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/local/sbin/smartctl --scan-open --json": {output: []byte(`{"devices":[]}`)},
+		"sysctl -n hw.disknames":                      {output: []byte("wd0:0123456789abcdef,cd0:,sd0:\n")},
+		"/usr/local/sbin/smartctl -i -j /dev/wd0c":    {output: []byte(`{"device":{"name":"/dev/wd0c","type":"ata"}}`)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/local/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	devices, err := b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Device{{Name: "/dev/wd0c", Type: "ata"}}, devices)
+}
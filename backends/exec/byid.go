@@ -0,0 +1,56 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// diskLinksDir holds the udev symlinks to block devices (by-id, by-path,
+// by-uuid, ...). Tests point it at a temporary directory.
+var diskLinksDir = "/dev/disk"
+
+// ResolveDeviceAlias resolves a udev alias such as
+// "/dev/disk/by-id/ata-WDC_WD40EFRX-68N32N0_WD-WCC7K0123456" to the device it
+// links to, e.g. "/dev/sda". Paths outside /dev/disk are returned unchanged.
+func ResolveDeviceAlias(devicePath string) (string, error) {
+	if !isDiskLink(devicePath) {
+		return devicePath, nil
+	}
+	return filepath.EvalSymlinks(devicePath)
+}
+
+// ByIDAliases returns the /dev/disk/by-id links leading to the same device as
+// devicePath, which may itself be an alias, sorted by name. Unlike "/dev/sdX"
+// names, these stay the same across reboots and USB re-enumeration. A device
+// without by-id links, or a system without /dev/disk/by-id, yields no aliases
+// and no error.
+func ByIDAliases(devicePath string) ([]string, error) {
+	target, err := filepath.EvalSymlinks(devicePath)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(diskLinksDir, "by-id")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var aliases []string
+	for _, entry := range entries {
+		link := filepath.Join(dir, entry.Name())
+		if resolved, err := filepath.EvalSymlinks(link); err == nil && resolved == target {
+			aliases = append(aliases, link)
+		}
+	}
+	slices.Sort(aliases)
+	return aliases, nil
+}
+
+// isDiskLink reports whether devicePath lies under diskLinksDir.
+func isDiskLink(devicePath string) bool {
+	return strings.HasPrefix(filepath.Clean(devicePath), diskLinksDir+string(filepath.Separator))
+}
@@ -0,0 +1,93 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDiskLinks lays out a /dev tree with udev-style relative links to sda
+// and points diskLinksDir at it. It returns the dev directory.
+func fakeDiskLinks(t *testing.T) string {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	dev := filepath.Join(root, "dev")
+	require.NoError(t, os.MkdirAll(filepath.Join(dev, "disk", "by-id"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dev, "disk", "by-path"), 0o755))
+	for _, name := range []string{"sda", "sdb"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dev, name), nil, 0o644))
+	}
+	links := map[string]string{
+		"by-id/wwn-0x50026b77560145cf":                   "../../sda",
+		"by-id/ata-WDC_WD40EFRX-68N32N0_WD-WCC7K0123456": "../../sda",
+		"by-id/usb-JMicron_Generic_0123456789-0:0":       "../../sdb",
+		"by-path/pci-0000:00:17.0-ata-1":                 "../../sda",
+	}
+	for link, target := range links {
+		require.NoError(t, os.Symlink(target, filepath.Join(dev, "disk", link)))
+	}
+	old := diskLinksDir
+	diskLinksDir = filepath.Join(dev, "disk")
+	t.Cleanup(func() { diskLinksDir = old })
+	return dev
+}
+
+func TestByIDAliases(t *testing.T) {
+	dev := fakeDiskLinks(t)
+	byID := filepath.Join(dev, "disk", "by-id")
+	want := []string{
+		filepath.Join(byID, "ata-WDC_WD40EFRX-68N32N0_WD-WCC7K0123456"),
+		filepath.Join(byID, "wwn-0x50026b77560145cf"),
+	}
+
+	aliases, err := ByIDAliases(filepath.Join(dev, "sda"))
+	require.NoError(t, err)
+	assert.Equal(t, want, aliases)
+
+	aliases, err = ByIDAliases(filepath.Join(dev, "disk", "by-path", "pci-0000:00:17.0-ata-1"))
+	require.NoError(t, err)
+	assert.Equal(t, want, aliases, "aliases of an alias")
+
+	_, err = ByIDAliases(filepath.Join(dev, "sdz"))
+	assert.Error(t, err)
+
+	require.NoError(t, os.RemoveAll(byID))
+	aliases, err = ByIDAliases(filepath.Join(dev, "sda"))
+	require.NoError(t, err)
+	assert.Empty(t, aliases)
+}
+
+func TestResolveDeviceAlias(t *testing.T) {
+	dev := fakeDiskLinks(t)
+	sdb := filepath.Join(dev, "sdb")
+
+	resolved, err := ResolveDeviceAlias(filepath.Join(dev, "disk", "by-id", "usb-JMicron_Generic_0123456789-0:0"))
+	require.NoError(t, err)
+	assert.Equal(t, sdb, resolved)
+
+	resolved, err = ResolveDeviceAlias(sdb)
+	require.NoError(t, err)
+	assert.Equal(t, sdb, resolved)
+
+	missing := filepath.Join(dev, "disk", "by-id", "ata-gone")
+	_, err = ResolveDeviceAlias(missing)
+	assert.Error(t, err)
+	assert.Equal(t, missing, NormalizeDevicePath(missing))
+}
+
+func TestExecBackend_ByIDAliasSharesDeviceType(t *testing.T) {
+	dev := fakeDiskLinks(t)
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(&mockCommander{}))
+	require.NoError(t, err)
+	b.SetDeviceTypeHint(filepath.Join(dev, "sda"), "sat")
+
+	for _, alias := range []string{"by-id/wwn-0x50026b77560145cf", "by-path/pci-0000:00:17.0-ata-1"} {
+		deviceType, ok := b.DeviceTypeHint(filepath.Join(dev, "disk", alias))
+		require.True(t, ok, alias)
+		assert.Equal(t, "sat", deviceType)
+	}
+}
@@ -1,15 +1,102 @@
 package exec
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"os"
 	osexec "os/exec"
+	"time"
 )
 
-// execCommander implements Commander using os/exec.
+// commandWaitDelay bounds how long a killed command may keep its output pipes open.
+const commandWaitDelay = time.Second
+
+// execCommander implements ContextCommander (and the legacy Commander) using os/exec.
 type execCommander struct{}
 
+// DefaultCommander returns the ContextCommander the backend uses unless
+// WithContextCommander replaces it: it runs commands with os/exec. Decorators
+// such as recording commanders wrap it.
+func DefaultCommander() ContextCommander {
+	return execCommander{}
+}
+
 func (e execCommander) Command(ctx context.Context, logger LogAdapter, name string, arg ...string) Cmd {
 	logger.DebugContext(ctx, "Executing command", "name", name, "args", arg)
 	cmd := osexec.CommandContext(ctx, name, arg...)
+	setProcessGroup(cmd)
 	return cmd
 }
+
+func (e execCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+	logger.DebugContext(ctx, "Executing command", "name", req.Name, "args", req.Args)
+	cmd := osexec.CommandContext(ctx, commandPath(req.Name, req.Env), req.Args...)
+	if len(req.Env) > 0 {
+		cmd.Env = append(os.Environ(), req.Env...)
+	}
+	setProcessGroup(cmd)
+	// Stop waiting for output from orphaned children (e.g. a wrapper script's
+	// subprocess) once the process itself has been killed.
+	cmd.WaitDelay = commandWaitDelay
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	result := &CommandResult{Stdout: stdout.Bytes(), Stderr: stderr.Bytes(), ExitCode: -1}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if err != nil && ctx.Err() != nil {
+		// Report why the process was killed rather than just "signal: killed".
+		// result keeps the output printed until then.
+		return result, errors.Join(err, ctx.Err())
+	}
+	return result, err
+}
+
+// legacyCommander adapts a deprecated Commander to ContextCommander.
+// Commands are looked up in the PATH of the environment overrides, which are
+// otherwise applied only when the Cmd is an *exec.Cmd, and
+// stderr is available only for failed commands via *exec.ExitError.
+type legacyCommander struct {
+	Commander
+}
+
+func (l legacyCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+	cmd := l.Command(ctx, logger, commandPath(req.Name, req.Env), req.Args...)
+	if c, ok := cmd.(*osexec.Cmd); ok && len(req.Env) > 0 {
+		c.Env = append(os.Environ(), req.Env...)
+	}
+	output, err := cmd.Output()
+	result := &CommandResult{Stdout: output}
+	var exitErr *osexec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.Stderr = exitErr.Stderr
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.ExitCode = -1
+	}
+	return result, err
+}
+
+// asContextCommander returns c itself when it already implements
+// ContextCommander and wraps it in legacyCommander otherwise.
+func asContextCommander(c Commander) ContextCommander {
+	if cc, ok := c.(ContextCommander); ok {
+		return cc
+	}
+	return legacyCommander{Commander: c}
+}
@@ -0,0 +1,148 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	osexec "os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingCommander implements ContextCommander and records every request.
+type recordingCommander struct {
+	requests []CommandRequest
+	result   *CommandResult
+	err      error
+}
+
+func (r *recordingCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	r.requests = append(r.requests, req)
+	return r.result, r.err
+}
+
+func requireShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	if _, err := osexec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+}
+
+func TestExecCommander_SeparatesStdoutAndStderr(t *testing.T) {
+	requireShell(t)
+	res, err := execCommander{}.CommandContext(context.Background(), newMinimalTestLogger(), CommandRequest{
+		Name: "sh",
+		Args: []string{"-c", `echo "$SMARTGO_TEST"; echo diag >&2; exit 4`},
+		Env:  []string{"SMARTGO_TEST=from-env"},
+	})
+	var exitErr *osexec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, "from-env\n", string(res.Stdout))
+	assert.Equal(t, "diag\n", string(res.Stderr))
+	assert.Equal(t, 4, res.ExitCode)
+}
+
+func TestExecCommander_TimeoutKillsProcess(t *testing.T) {
+	requireShell(t)
+	start := time.Now()
+	res, err := execCommander{}.CommandContext(context.Background(), newMinimalTestLogger(), CommandRequest{
+		Name:    "sh",
+		Args:    []string{"-c", "sleep 10"},
+		Timeout: 50 * time.Millisecond,
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, -1, res.ExitCode)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestExecCommander_NotFound(t *testing.T) {
+	res, err := execCommander{}.CommandContext(context.Background(), newMinimalTestLogger(), CommandRequest{Name: "/nonexistent/smartctl"})
+	require.Error(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, -1, res.ExitCode)
+}
+
+func TestLegacyCommander_Adapter(t *testing.T) {
+	exitErr := &osexec.ExitError{Stderr: []byte("diag")}
+	adapter := asContextCommander(&mockCommander{cmds: map[string]*mockCmd{
+		"smartctl -H /dev/sda": {output: []byte("out"), err: exitErr},
+	}})
+	res, err := adapter.CommandContext(context.Background(), newMinimalTestLogger(), CommandRequest{Name: "smartctl", Args: []string{"-H", "/dev/sda"}})
+	assert.Same(t, exitErr, err)
+	assert.Equal(t, "out", string(res.Stdout))
+	assert.Equal(t, "diag", string(res.Stderr))
+
+	res, err = adapter.CommandContext(context.Background(), newMinimalTestLogger(), CommandRequest{Name: "missing"})
+	require.Error(t, err)
+	assert.Equal(t, -1, res.ExitCode)
+
+	// The default commander implements ContextCommander natively.
+	_, native := asContextCommander(execCommander{}).(execCommander)
+	assert.True(t, native)
+}
+
+func TestWithContextCommander_PassesEnvAndTimeout(t *testing.T) {
+	rec := &recordingCommander{result: &CommandResult{Stdout: []byte(`{"devices":[{"name":"/dev/sda","type":"sat"}]}`)}}
+	b, err := New(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithContextCommander(rec),
+		WithCommandEnv("LC_ALL=C"),
+		WithCommandTimeout(30*time.Second),
+	)
+	require.NoError(t, err)
+	assert.False(t, b.defaultCommander)
+
+	_, err = b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rec.requests, 1)
+	assert.Equal(t, CommandRequest{
+		Name:    "/usr/sbin/smartctl",
+		Args:    []string{"--scan-open", "--json"},
+		Env:     []string{"LC_ALL=C", "LANG=C", "LC_ALL=C"},
+		Timeout: 30 * time.Second,
+	}, rec.requests[0])
+}
+
+func TestWithLocale(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{"C by default", nil, []string{"LC_ALL=C", "LANG=C"}},
+		{"custom locale", []Option{WithLocale("C.UTF-8")}, []string{"LC_ALL=C.UTF-8", "LANG=C.UTF-8"}},
+		{"empty inherits", []Option{WithLocale("")}, nil},
+		{"command env comes last", []Option{WithCommandEnv("LC_ALL=POSIX")}, []string{"LC_ALL=C", "LANG=C", "LC_ALL=POSIX"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingCommander{result: &CommandResult{Stdout: []byte("SMART overall-health self-assessment test result: PASSED\n")}}
+			b, err := New(append([]Option{WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec)}, tt.opts...)...)
+			require.NoError(t, err)
+			_, err = b.CheckHealth(context.Background(), "/dev/sda")
+			require.NoError(t, err)
+			require.Len(t, rec.requests, 1)
+			assert.Equal(t, tt.want, rec.requests[0].Env)
+		})
+	}
+}
+
+func TestRunSelfTest_ReportsStderr(t *testing.T) {
+	rec := &recordingCommander{
+		result: &CommandResult{Stderr: []byte("Smartctl open device: /dev/sda failed: Permission denied"), ExitCode: 2},
+		err:    errors.New("exit status 2"),
+	}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec))
+	require.NoError(t, err)
+
+	err = b.RunSelfTest(context.Background(), "/dev/sda", "short")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Permission denied")
+}
@@ -0,0 +1,47 @@
+package exec
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// WithCommandHook calls hook after every smartctl invocation with its
+// arguments, run time, exit code, error and, for retries, the fallback that
+// triggered it, e.g. to record latency and failure metrics. Hooks added by
+// repeated options are called in order.
+func WithCommandHook(hook CommandHook) Option {
+	return func(b *ExecBackend) {
+		if hook != nil {
+			b.commandHooks = append(b.commandHooks, hook)
+		}
+	}
+}
+
+type fallbackKey struct{}
+
+// withFallback marks the smartctl invocations made with ctx as a fallback.
+func withFallback(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, fallbackKey{}, reason)
+}
+
+// notifyCommandHooks reports a finished invocation to the command hooks.
+func (b *ExecBackend) notifyCommandHooks(ctx context.Context, args []string, start time.Time, duration time.Duration, res *CommandResult, err error) {
+	if len(b.commandHooks) == 0 {
+		return
+	}
+	fallback, _ := ctx.Value(fallbackKey{}).(string)
+	event := CommandEvent{
+		Command:  b.smartctlPath,
+		Args:     slices.Clone(args),
+		Device:   commandDevice(args),
+		Fallback: fallback,
+		Start:    start,
+		Duration: duration,
+		ExitCode: res.ExitCode,
+		Err:      err,
+	}
+	for _, hook := range b.commandHooks {
+		hook(ctx, event)
+	}
+}
@@ -0,0 +1,64 @@
+package exec
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder collects CommandEvents.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []CommandEvent
+}
+
+func (r *eventRecorder) hook(_ context.Context, e CommandEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func TestCommandHook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("open retries", func(t *testing.T) {
+		rec := &eventRecorder{}
+		b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(&busyCommander{busy: 1}),
+			WithOpenRetry(1, time.Millisecond), WithCommandHook(rec.hook))
+		require.NoError(t, err)
+
+		_, err = b.CheckHealth(ctx, "/dev/sda")
+		require.NoError(t, err)
+		require.Len(t, rec.events, 2)
+		first, retry := rec.events[0], rec.events[1]
+		assert.Equal(t, "/usr/sbin/smartctl", first.Command)
+		assert.Equal(t, []string{"-H", "--nocheck=standby", "/dev/sda"}, first.Args)
+		assert.Equal(t, "/dev/sda", first.Device)
+		assert.Empty(t, first.Fallback)
+		assert.Equal(t, 2, first.ExitCode)
+		assert.ErrorIs(t, first.Err, ErrDeviceBusy)
+		assert.Equal(t, FallbackOpenRetry, retry.Fallback)
+		assert.NoError(t, retry.Err)
+	})
+
+	t.Run("scan and device type fallbacks", func(t *testing.T) {
+		rec := &eventRecorder{}
+		rc := &recordingCommander{result: &CommandResult{Stdout: []byte(`{"devices":[]}`)}}
+		b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithCommandHook(rec.hook))
+		require.NoError(t, err)
+
+		_, err = b.ScanDevices(ctx)
+		require.NoError(t, err)
+		b.retrySATFallback(ctx, "/dev/sdb", "-a")
+		require.Len(t, rec.events, 3)
+		assert.Empty(t, rec.events[0].Fallback)
+		assert.Empty(t, rec.events[0].Device)
+		assert.Equal(t, FallbackScan, rec.events[1].Fallback)
+		assert.Equal(t, FallbackDeviceType, rec.events[2].Fallback)
+		assert.Contains(t, rec.events[2].Args, "sat")
+	})
+}
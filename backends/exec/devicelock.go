@@ -0,0 +1,39 @@
+package exec
+
+import (
+	"context"
+	"strings"
+)
+
+// lockDevice serializes smartctl invocations per device: some USB bridges
+// misbehave when two commands reach the same disk at once. Commands for
+// different devices, and commands without a device (e.g. --scan), run in
+// parallel. The returned function releases the lock; it fails only when ctx
+// is done while waiting.
+func (b *ExecBackend) lockDevice(ctx context.Context, args []string) (func(), error) {
+	device := commandDevice(args)
+	if device == "" {
+		return func() {}, nil
+	}
+	v, _ := b.deviceLocks.LoadOrStore(device, make(chan struct{}, 1))
+	sem := v.(chan struct{})
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// commandDevice returns the device path of a smartctl command line, which
+// the backend always passes last, or "" for device-less commands.
+func commandDevice(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	last := args[len(args)-1]
+	if strings.HasPrefix(last, "-") {
+		return ""
+	}
+	return last
+}
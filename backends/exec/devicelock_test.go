@@ -0,0 +1,85 @@
+package exec
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyCommander records the peak number of overlapping commands per device.
+type concurrencyCommander struct {
+	mu      sync.Mutex
+	active  map[string]int
+	peak    map[string]int
+	overall atomic.Int32
+	maxAll  atomic.Int32
+}
+
+func (c *concurrencyCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	device := req.Args[len(req.Args)-1]
+	c.mu.Lock()
+	c.active[device]++
+	c.peak[device] = max(c.peak[device], c.active[device])
+	c.mu.Unlock()
+	n := c.overall.Add(1)
+	for {
+		m := c.maxAll.Load()
+		if n <= m || c.maxAll.CompareAndSwap(m, n) {
+			break
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	c.overall.Add(-1)
+	c.mu.Lock()
+	c.active[device]--
+	c.mu.Unlock()
+	return &CommandResult{Stdout: []byte(`{"device":{"name":"` + device + `","type":"sat"}}`)}, nil
+}
+
+func TestDeviceLock_SerializesSameDevice(t *testing.T) {
+	cc := &concurrencyCommander{active: map[string]int{}, peak: map[string]int{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(cc))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for _, dev := range []string{"/dev/sda", "/dev/sda", "/dev/sda", "/dev/sdb", "/dev/sdb"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.GetSMARTInfo(context.Background(), dev)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, cc.peak["/dev/sda"])
+	assert.Equal(t, 1, cc.peak["/dev/sdb"])
+	assert.GreaterOrEqual(t, cc.maxAll.Load(), int32(2), "different devices should run in parallel")
+}
+
+func TestDeviceLock_ContextCancelledWhileWaiting(t *testing.T) {
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(&recordingCommander{result: &CommandResult{}}))
+	require.NoError(t, err)
+
+	unlock, err := b.lockDevice(context.Background(), []string{"-a", "/dev/sda"})
+	require.NoError(t, err)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = b.AbortSelfTest(ctx, "/dev/sda")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCommandDevice(t *testing.T) {
+	assert.Equal(t, "/dev/sda", commandDevice([]string{"-a", "-j", "/dev/sda"}))
+	assert.Empty(t, commandDevice([]string{"--scan-open", "--json"}))
+	assert.Empty(t, commandDevice(nil))
+}
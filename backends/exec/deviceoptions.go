@@ -0,0 +1,76 @@
+package exec
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// WithDeviceOptions overrides the device type, extra smartctl arguments,
+// tolerance and --nocheck policy of the listed devices, keyed by device path.
+// Keys under /dev/disk (by-id, by-path, ...) are resolved on every lookup, so
+// the options follow a hot-plugged or re-enumerated drive to its new
+// "/dev/sdX" name. A configured type is never replaced by auto-detection or
+// SAT probing. Calls made with ContextWithTolerance or ContextWithNoCheck
+// still override the device's settings. Later options for the same device
+// replace earlier ones. New fails for invalid options.
+func WithDeviceOptions(options map[string]DeviceOptions) Option {
+	return func(b *ExecBackend) {
+		if b.deviceOptions == nil {
+			b.deviceOptions = make(map[string]DeviceOptions, len(options))
+		}
+		for path, o := range options {
+			o.ExtraArgs = slices.Clone(o.ExtraArgs)
+			if o.NoCheck != nil {
+				policy := *o.NoCheck
+				o.NoCheck = &policy
+			}
+			if isDiskLink(path) {
+				path = filepath.Clean(path)
+				if !slices.Contains(b.deviceOptionLinks, path) {
+					b.deviceOptionLinks = append(b.deviceOptionLinks, path)
+				}
+			} else {
+				path = NormalizeDevicePath(path)
+			}
+			b.deviceOptions[path] = o
+		}
+		slices.Sort(b.deviceOptionLinks)
+	}
+}
+
+// validateDeviceOptions reports the first device with invalid options.
+func (b *ExecBackend) validateDeviceOptions() error {
+	for path, o := range b.deviceOptions {
+		if err := o.Validate(); err != nil {
+			return fmt.Errorf("device options for %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// optionsFor returns the options configured for devicePath, directly or
+// through a /dev/disk alias that now leads to it. The options are not
+// modified after New, so no lock is needed.
+func (b *ExecBackend) optionsFor(devicePath string) DeviceOptions {
+	if o, ok := b.deviceOptions[devicePath]; ok {
+		return o
+	}
+	for _, link := range b.deviceOptionLinks {
+		if resolved, err := ResolveDeviceAlias(link); err == nil && resolved == devicePath {
+			return b.deviceOptions[link]
+		}
+	}
+	return DeviceOptions{}
+}
+
+// deviceExtraArgs inserts the extra arguments configured for the device of
+// args before its path. Commands without a device are unchanged.
+func (b *ExecBackend) deviceExtraArgs(args []string) []string {
+	device := commandDevice(args)
+	extra := b.optionsFor(device).ExtraArgs
+	if device == "" || len(extra) == 0 {
+		return args
+	}
+	return slices.Concat(args[:len(args)-1], extra, []string{device})
+}
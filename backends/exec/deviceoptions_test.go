@@ -0,0 +1,76 @@
+package exec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceOptions_OverrideBackendSettings(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{Stdout: []byte(`PASSED`)}}
+	never := NoCheckPolicy{Mode: NoCheckNever}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc),
+		WithTolerance(ToleranceNormal),
+		WithDeviceOptions(map[string]DeviceOptions{
+			"/dev/sdc": {Type: "sat", ExtraArgs: []string{"-F", "samsung3"}, Tolerance: TolerancePermissive, NoCheck: &never},
+		}))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = b.CheckHealth(ctx, "/dev/sdc")
+	require.NoError(t, err)
+	_, err = b.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, err = b.CheckHealth(ContextWithTolerance(ctx, ToleranceVeryPermissive), "/dev/sdc")
+	require.NoError(t, err)
+	b.SetDeviceTypeHint("/dev/sdc", "nvme")
+
+	require.Len(t, rc.requests, 3)
+	assert.Equal(t, []string{"-T", "permissive", "-H", "--nocheck=never", "-d", "sat", "-F", "samsung3", "/dev/sdc"}, rc.requests[0].Args)
+	assert.Equal(t, []string{"-T", "normal", "-H", "--nocheck=standby", "/dev/sda"}, rc.requests[1].Args)
+	assert.Equal(t, "verypermissive", rc.requests[2].Args[1], "the context overrides the device tolerance")
+	deviceType, ok := b.DeviceTypeHint("/dev/sdc")
+	assert.True(t, ok)
+	assert.Equal(t, "sat", deviceType, "the configured type is never replaced")
+}
+
+func TestDeviceOptions_Invalid(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{}}
+	_, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc),
+		WithDeviceOptions(map[string]DeviceOptions{"/dev/sdc": {Tolerance: "lenient"}}))
+	assert.ErrorContains(t, err, "device options for /dev/sdc: invalid tolerance")
+
+	_, err = New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc),
+		WithDeviceOptions(map[string]DeviceOptions{"/dev/sdc": {NoCheck: &NoCheckPolicy{Mode: "asleep"}}}))
+	assert.ErrorContains(t, err, "device options for /dev/sdc")
+}
+
+func TestDeviceOptions_AliasFollowsReenumeration(t *testing.T) {
+	dev := fakeDiskLinks(t)
+	link := filepath.Join(dev, "disk", "by-id", "usb-JMicron_Generic_0123456789-0:0")
+	rc := &recordingCommander{result: &CommandResult{Stdout: []byte(`PASSED`)}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc),
+		WithDeviceOptions(map[string]DeviceOptions{link: {Type: "sntjmicron"}}))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = b.CheckHealth(ctx, filepath.Join(dev, "sdb"))
+	require.NoError(t, err)
+
+	// The enclosure is unplugged and comes back as sda.
+	require.NoError(t, os.Remove(link))
+	require.NoError(t, os.Symlink("../../sda", link))
+	_, err = b.CheckHealth(ctx, filepath.Join(dev, "sda"))
+	require.NoError(t, err)
+	_, err = b.CheckHealth(ctx, filepath.Join(dev, "sdb"))
+	require.NoError(t, err)
+
+	require.Len(t, rc.requests, 3)
+	assert.Contains(t, rc.requests[0].Args, "sntjmicron")
+	assert.Contains(t, rc.requests[1].Args, "sntjmicron", "the options follow the alias")
+	assert.NotContains(t, rc.requests[2].Args, "sntjmicron")
+}
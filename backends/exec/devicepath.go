@@ -0,0 +1,136 @@
+package exec
+
+import (
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// windowsPhysicalDrivePrefixes are the Win32 device namespace forms accepted
+// for physical disks, compared case-insensitively.
+var windowsPhysicalDrivePrefixes = []string{`\\.\physicaldrive`, `//./physicaldrive`}
+
+// NormalizeDevicePath rewrites Windows physical drive paths such as
+// `\\.\PhysicalDrive0` into the "/dev/sdX" alias smartctl uses for the same
+// disk ("a" is drive 0, "aa" is drive 26), and resolves udev aliases under
+// /dev/disk (by-id, by-path, ...) to the device they link to, so that every
+// name of a disk matches the one reported by ScanDevices. Any other path, and
+// aliases that cannot be resolved, are returned unchanged.
+func NormalizeDevicePath(devicePath string) string {
+	if isDiskLink(devicePath) {
+		if resolved, err := ResolveDeviceAlias(devicePath); err == nil {
+			return resolved
+		}
+		return devicePath
+	}
+	lower := strings.ToLower(devicePath)
+	for _, prefix := range windowsPhysicalDrivePrefixes {
+		rest, ok := strings.CutPrefix(lower, prefix)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 0 {
+			return devicePath
+		}
+		return "/dev/sd" + driveLetters(n)
+	}
+	return devicePath
+}
+
+// NVMe controller device names, e.g. "nvme0", and namespace names, "nvme0n1"
+// on Linux and "nvme0ns1" on FreeBSD, capturing the controller name.
+var (
+	nvmeControllerRe = regexp.MustCompile(`^nvme\d+$`)
+	nvmeNamespaceRe  = regexp.MustCompile(`^(nvme\d+)(?:n|ns)\d+$`)
+)
+
+// nvmeController returns the controller path of an NVMe namespace path, e.g.
+// "/dev/nvme0" for "/dev/nvme0n1".
+func nvmeController(devicePath string) (string, bool) {
+	m := nvmeNamespaceRe.FindStringSubmatch(filepath.Base(devicePath))
+	if m == nil {
+		return "", false
+	}
+	return filepath.Join(filepath.Dir(devicePath), m[1]), true
+}
+
+// mergeNVMeNamespaces collapses the scan entries of an NVMe controller and its
+// namespaces, which smartctl and the platform probes may both report, into one
+// device per controller, so that a drive is counted once. The merged device
+// keeps the controller entry, or the first namespace when the controller was
+// not listed, and records the controller and namespace paths.
+func mergeNVMeNamespaces(devices []Device) []Device {
+	merged := make([]Device, 0, len(devices))
+	index := make(map[string]int) // controller path -> position in merged
+	for _, d := range devices {
+		controller, isNamespace := nvmeController(d.Name)
+		if !isNamespace {
+			if i, ok := index[d.Name]; ok {
+				// The controller follows its namespaces: it takes their place.
+				d.Controller, d.Namespaces = d.Name, merged[i].Namespaces
+				merged[i] = d
+				continue
+			}
+			if nvmeControllerRe.MatchString(filepath.Base(d.Name)) {
+				index[d.Name] = len(merged)
+			}
+			merged = append(merged, d)
+			continue
+		}
+		i, ok := index[controller]
+		if !ok {
+			index[controller] = len(merged)
+			d.Controller, d.Namespaces = controller, []string{d.Name}
+			merged = append(merged, d)
+			continue
+		}
+		merged[i].Controller = controller
+		merged[i].Namespaces = append(merged[i].Namespaces, d.Name)
+	}
+	return merged
+}
+
+// driveLetters converts a zero-based disk index into smartctl's letter suffix,
+// in bijective base 26 like Linux disk names: 0–25 map to "a"–"z", 26 onward
+// to "aa", "ab", ..., "zz" and 702 onward to "aaa", ...
+func driveLetters(n int) string {
+	var letters []byte
+	for n++; n > 0; n = (n - 1) / 26 {
+		letters = append(letters, byte('a'+(n-1)%26))
+	}
+	slices.Reverse(letters)
+	return string(letters)
+}
+
+// windowsSmartctlSearchPaths lists the locations used by the official
+// smartmontools installer and common package managers on Windows. getenv is
+// injected so the list can be built on any platform in tests.
+func windowsSmartctlSearchPaths(getenv func(string) string) []string {
+	var paths []string
+	for _, env := range []string{"ProgramW6432", "ProgramFiles", "ProgramFiles(x86)"} {
+		if dir := getenv(env); dir != "" {
+			paths = append(paths, filepath.Join(dir, "smartmontools", "bin", "smartctl.exe"))
+		}
+	}
+	if dir := getenv("ProgramData"); dir != "" {
+		// Chocolatey
+		paths = append(paths, filepath.Join(dir, "chocolatey", "bin", "smartctl.exe"))
+	}
+	if dir := getenv("USERPROFILE"); dir != "" {
+		// Scoop
+		paths = append(paths, filepath.Join(dir, "scoop", "shims", "smartctl.exe"))
+	}
+	return paths
+}
+
+// platformSmartctlSearchPaths returns the fallback locations for the running OS.
+func platformSmartctlSearchPaths(getenv func(string) string) []string {
+	if runtime.GOOS == "windows" {
+		return windowsSmartctlSearchPaths(getenv)
+	}
+	return smartctlSearchPaths
+}
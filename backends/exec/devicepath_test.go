@@ -0,0 +1,82 @@
+package exec
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDevicePath(t *testing.T) {
+	cases := map[string]string{
+		`\\.\PhysicalDrive0`:   "/dev/sda",
+		`\\.\physicaldrive25`:  "/dev/sdz",
+		`\\.\PhysicalDrive26`:  "/dev/sdaa",
+		`\\.\PhysicalDrive53`:  "/dev/sdbb",
+		`\\.\PhysicalDrive701`: "/dev/sdzz",
+		`\\.\PhysicalDrive702`: "/dev/sdaaa",
+		`\\.\PhysicalDrive728`: "/dev/sdaba",
+		`//./PhysicalDrive1`:   "/dev/sdb",
+		`\\.\PhysicalDriveX`:   `\\.\PhysicalDriveX`,
+		`\\.\C:`:               `\\.\C:`,
+		"/dev/sda":             "/dev/sda",
+		"/dev/csmi0,1":         "/dev/csmi0,1",
+		"/dev/disk2":           "/dev/disk2",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, NormalizeDevicePath(in), in)
+	}
+}
+
+func TestMergeNVMeNamespaces(t *testing.T) {
+	devices := mergeNVMeNamespaces([]Device{
+		{Name: "/dev/nvme0", Type: "nvme"},
+		{Name: "/dev/sda", Type: "sat"},
+		{Name: "/dev/nvme1n1", Type: "nvme"},
+		{Name: "/dev/nvme0n1", Type: "nvme"},
+		{Name: "/dev/nvme0n2", Type: "nvme"},
+		{Name: "/dev/nvme1", Type: "nvme"},
+		{Name: "/dev/nvme2ns1", Type: "nvme"},
+		{Name: "/dev/nvme3", Type: "nvme"},
+	})
+	assert.Equal(t, []Device{
+		{Name: "/dev/nvme0", Type: "nvme", Controller: "/dev/nvme0", Namespaces: []string{"/dev/nvme0n1", "/dev/nvme0n2"}},
+		{Name: "/dev/sda", Type: "sat"},
+		{Name: "/dev/nvme1", Type: "nvme", Controller: "/dev/nvme1", Namespaces: []string{"/dev/nvme1n1"}},
+		{Name: "/dev/nvme2ns1", Type: "nvme", Controller: "/dev/nvme2", Namespaces: []string{"/dev/nvme2ns1"}},
+		{Name: "/dev/nvme3", Type: "nvme"},
+	}, devices)
+}
+
+func TestWindowsSmartctlSearchPaths(t *testing.T) {
+	env := map[string]string{
+		"ProgramW6432":      `C:\Program Files`,
+		"ProgramFiles(x86)": `C:\Program Files (x86)`,
+		"ProgramData":       `C:\ProgramData`,
+	}
+	paths := windowsSmartctlSearchPaths(func(k string) string { return env[k] })
+	assert.Equal(t, []string{
+		filepath.Join(`C:\Program Files`, "smartmontools", "bin", "smartctl.exe"),
+		filepath.Join(`C:\Program Files (x86)`, "smartmontools", "bin", "smartctl.exe"),
+		filepath.Join(`C:\ProgramData`, "chocolatey", "bin", "smartctl.exe"),
+	}, paths)
+}
+
+func TestExecBackend_WindowsPhysicalDrivePath(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -H --nocheck=standby -d ata /dev/sdb": {output: []byte("SMART overall-health self-assessment test result: PASSED")},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+	b.SetDeviceTypeHint("/dev/sdb", "ata")
+
+	deviceType, ok := b.DeviceTypeHint(`\\.\PhysicalDrive1`)
+	require.True(t, ok)
+	assert.Equal(t, "ata", deviceType)
+
+	healthy, err := b.CheckHealth(context.Background(), `\\.\PhysicalDrive1`)
+	require.NoError(t, err)
+	assert.True(t, healthy)
+}
@@ -0,0 +1,172 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"runtime"
+)
+
+// goos is the operating system used to pick platform-specific enumerators.
+// Tests override it to exercise other platforms.
+var goos = runtime.GOOS
+
+// satSmartDriverHint is logged when an external macOS disk cannot be opened,
+// which almost always means the SAT SMART kernel extension is missing.
+const satSmartDriverHint = "USB/Thunderbolt disks on macOS need the SAT SMART driver (https://github.com/kasbert/OS-X-SAT-SMART-Driver) for SMART access"
+
+// appendPlatformDevices adds devices that smartctl's own scan misses on some
+// platforms. Candidates already present in devices are skipped; the rest are
+// probed with smartctl and only devices it can open are returned. When types
+// is not empty, candidates are only probed as those device types ("-d TYPE"),
+// so that disks of other types are not sent identify commands that could
+// wake them.
+func (b *ExecBackend) appendPlatformDevices(ctx context.Context, devices []Device, types []string) []Device {
+	var candidates []string
+	switch goos {
+	case "darwin":
+		candidates = b.diskutilWholeDisks(ctx)
+	case "freebsd":
+		candidates = b.freebsdDisks(ctx)
+	case "openbsd":
+		candidates = b.openbsdDisks(ctx)
+	default:
+		return devices
+	}
+
+	known := make(map[string]bool, len(devices))
+	for _, d := range devices {
+		known[d.Name] = true
+	}
+	for _, path := range candidates {
+		if known[path] {
+			continue
+		}
+		known[path] = true
+		if len(types) == 0 {
+			if dev, ok := b.probeDevice(ctx, path, ""); ok {
+				devices = append(devices, dev)
+			}
+			continue
+		}
+		for _, deviceType := range types {
+			if dev, ok := b.probeDevice(ctx, path, deviceType); ok {
+				devices = append(devices, dev)
+				break
+			}
+		}
+	}
+	return devices
+}
+
+// diskutilWholeDisks lists physical whole disks via "diskutil list -plist
+// physical" as /dev/diskN paths. Errors are logged and yield no candidates.
+func (b *ExecBackend) diskutilWholeDisks(ctx context.Context) []string {
+	res, err := b.runTool(ctx, "diskutil", "list", "-plist", "physical")
+	if err != nil {
+		b.logHandler.DebugContext(ctx, "diskutil enumeration failed", "err", err)
+		return nil
+	}
+	disks, err := parseDiskutilWholeDisks(res.Stdout)
+	if err != nil {
+		b.logHandler.DebugContext(ctx, "failed to parse diskutil output", "err", err)
+		return nil
+	}
+	paths := make([]string, len(disks))
+	for i, d := range disks {
+		paths[i] = "/dev/" + d
+	}
+	return paths
+}
+
+// parseDiskutilWholeDisks extracts the WholeDisks array (e.g. "disk0",
+// "disk4") from the property list printed by "diskutil list -plist".
+func parseDiskutilWholeDisks(plist []byte) ([]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(plist))
+	// depth tracks nesting below the root <dict>; WholeDisks is a top-level key.
+	depth := 0
+	var lastKey string
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("WholeDisks key not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "key" && depth == 2:
+				var key string
+				if err := dec.DecodeElement(&key, &t); err != nil {
+					return nil, err
+				}
+				lastKey = key
+				continue
+			case t.Name.Local == "array" && depth == 2 && lastKey == "WholeDisks":
+				var arr struct {
+					Strings []string `xml:"string"`
+				}
+				if err := dec.DecodeElement(&arr, &t); err != nil {
+					return nil, err
+				}
+				return arr.Strings, nil
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// probeDevice asks smartctl to identify path, as deviceType when it is not
+// empty. Devices that cannot be opened are skipped; on macOS the SAT SMART
+// driver hint is logged for them.
+func (b *ExecBackend) probeDevice(ctx context.Context, path string, deviceType string) (Device, bool) {
+	if b.textOutput {
+		return b.probeDeviceText(ctx, path, deviceType)
+	}
+	args := []string{"-i", "-j"}
+	if deviceType != "" {
+		args = append(args, "-d", deviceType)
+	}
+	res, err := b.run(ctx, append(args, path)...)
+	output := res.Stdout
+	var result struct {
+		Device Device `json:"device"`
+	}
+	if len(output) == 0 || json.Unmarshal(output, &result) != nil || result.Device.Type == "" {
+		if goos == "darwin" {
+			b.logHandler.WarnContext(ctx, "Cannot open disk with smartctl", "devicePath", path, "err", err, "hint", satSmartDriverHint)
+		} else {
+			b.logHandler.DebugContext(ctx, "Cannot open disk with smartctl", "devicePath", path, "err", err)
+		}
+		return Device{}, false
+	}
+	if isUnsupportedDarwinDevice(output) {
+		b.logHandler.WarnContext(ctx, "Disk found but SMART is unavailable", "devicePath", path, "hint", satSmartDriverHint)
+	}
+	if _, cached := b.getCachedDeviceType(path); !cached {
+		b.setCachedDeviceType(path, result.Device.Type)
+	}
+	return Device{Name: path, Type: result.Device.Type}, true
+}
+
+// isUnsupportedDarwinDevice reports whether an identify response on macOS
+// lacks SMART support, as seen for external disks without the SAT SMART driver.
+func isUnsupportedDarwinDevice(output []byte) bool {
+	if goos != "darwin" {
+		return false
+	}
+	var result struct {
+		SmartSupport *SmartSupport `json:"smart_support"`
+	}
+	if json.Unmarshal(output, &result) != nil {
+		return false
+	}
+	return result.SmartSupport == nil || !result.SmartSupport.Available
+}
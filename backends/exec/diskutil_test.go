@@ -0,0 +1,136 @@
+package exec
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diskutilPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>AllDisks</key>
+	<array>
+		<string>disk0</string>
+		<string>disk0s1</string>
+		<string>disk4</string>
+		<string>disk5</string>
+	</array>
+	<key>AllDisksAndPartitions</key>
+	<array>
+		<dict>
+			<key>DeviceIdentifier</key>
+			<string>disk0</string>
+			<key>WholeDisks</key>
+			<array><string>nested</string></array>
+		</dict>
+	</array>
+	<key>VolumesFromDisks</key>
+	<array/>
+	<key>WholeDisks</key>
+	<array>
+		<string>disk0</string>
+		<string>disk4</string>
+		<string>disk5</string>
+	</array>
+</dict>
+</plist>`
+
+func withGOOS(t *testing.T, os string) {
+	t.Helper()
+	orig := goos
+	goos = os
+	t.Cleanup(func() { goos = orig })
+}
+
+func TestParseDiskutilWholeDisks(t *testing.T) {
+	disks, err := parseDiskutilWholeDisks([]byte(diskutilPlist))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"disk0", "disk4", "disk5"}, disks)
+
+	_, err = parseDiskutilWholeDisks([]byte(`<plist><dict></dict></plist>`))
+	assert.Error(t, err)
+}
+
+func TestScanDevices_DarwinDiskutilFallback(t *testing.T) {
+	withGOOS(t, "darwin")
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --scan-open --json": {output: []byte(`{"devices":[{"name":"/dev/disk0","type":"nvme"}]}`)},
+		"diskutil list -plist physical":         {output: []byte(diskutilPlist)},
+		"/usr/sbin/smartctl -i -j /dev/disk4":   {output: []byte(`{"device":{"name":"/dev/disk4","type":"sat"},"smart_support":{"available":true,"enabled":true}}`)},
+		// disk5 is an external drive without the SAT SMART driver: smartctl cannot open it.
+		"/usr/sbin/smartctl -i -j /dev/disk5": {output: []byte(`{"smartctl":{"exit_status":2}}`)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	devices, err := b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Device{{Name: "/dev/disk0", Type: "nvme"}, {Name: "/dev/disk4", Type: "sat"}}, devices)
+
+	deviceType, ok := b.DeviceTypeHint("/dev/disk4")
+	require.True(t, ok)
+	assert.Equal(t, "sat", deviceType)
+}
+
+func TestScanDevices_DarwinDiskutilUnavailable(t *testing.T) {
+	withGOOS(t, "darwin")
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --scan-open --json": {output: []byte(`{"devices":[{"name":"/dev/disk0","type":"nvme"}]}`)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	devices, err := b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, devices, 1)
+}
+
+// callLog records the commands run through a mockCommander.
+type callLog struct {
+	*mockCommander
+	calls []string
+}
+
+func (c *callLog) Command(ctx context.Context, logger LogAdapter, name string, arg ...string) Cmd {
+	c.calls = append(c.calls, strings.Join(append([]string{name}, arg...), " "))
+	return c.mockCommander.Command(ctx, logger, name, arg...)
+}
+
+func TestScanDevicesWithOptions(t *testing.T) {
+	withGOOS(t, "darwin")
+	mock := &callLog{mockCommander: &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --scan-open --json -d nvme": {output: []byte(`{"devices":[{"name":"/dev/disk0","type":"nvme"}]}`)},
+		"/usr/sbin/smartctl --scan-open --json -d sat":  {output: []byte(`{"devices":[{"name":"/dev/disk3","type":"sat"}]}`)},
+		"/usr/sbin/smartctl --scan-open --json":         {output: []byte(`{"devices":[{"name":"/dev/disk0","type":"nvme"},{"name":"/dev/disk3","type":"sat"}]}`)},
+		"diskutil list -plist physical":                 {output: []byte(diskutilPlist)},
+		"/usr/sbin/smartctl -i -j /dev/disk4":           {output: []byte(`{"device":{"name":"/dev/disk4","type":"sat"},"smart_support":{"available":true,"enabled":true}}`)},
+		"/usr/sbin/smartctl -i -j -d sat /dev/disk4":    {output: []byte(`{"device":{"name":"/dev/disk4","type":"sat"},"smart_support":{"available":true,"enabled":true}}`)},
+		"/usr/sbin/smartctl -i -j /dev/disk5":           {output: []byte(`{"smartctl":{"exit_status":2}}`)},
+	}}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	mock.calls = nil
+	devices, err := b.ScanDevicesWithOptions(ctx, ScanOptions{Types: []string{"nvme"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Device{{Name: "/dev/disk0", Type: "nvme"}}, devices, "probed sat disks are not of the requested type")
+	for _, call := range mock.calls {
+		if strings.Contains(call, " -i ") {
+			assert.Contains(t, call, "-d nvme", "platform disks are only probed as the requested type")
+		}
+	}
+
+	devices, err = b.ScanDevicesWithOptions(ctx, ScanOptions{Types: []string{"nvme", "sat"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Device{{Name: "/dev/disk0", Type: "nvme"}, {Name: "/dev/disk3", Type: "sat"}, {Name: "/dev/disk4", Type: "sat"}}, devices)
+
+	devices, err = b.ScanDevicesWithOptions(ctx, ScanOptions{Interfaces: []string{"sat"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Device{{Name: "/dev/disk3", Type: "sat"}, {Name: "/dev/disk4", Type: "sat"}}, devices)
+}
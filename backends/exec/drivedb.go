@@ -10,8 +10,6 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-
-	"github.com/dianlight/tlog"
 )
 
 // drivedbCache holds the parsed drivedb entries to avoid reparsing on each access.
@@ -47,6 +45,12 @@ var drivedbH string
 //
 // Returns a map with keys in format "usb:0x152d:0x0578" -> device type "sat"
 func loadDrivedbAddendum() map[string]string {
+	return parseDrivedb(drivedbH)
+}
+
+// parseDrivedb extracts the USB bridge entries of drivedb.h source src.
+// Malformed entries are skipped.
+func parseDrivedb(src string) map[string]string {
 	cache := make(map[string]string)
 
 	// Regular expressions to parse drivedb.h entries
@@ -58,7 +62,7 @@ func loadDrivedbAddendum() map[string]string {
 	deviceTypePattern := regexp.MustCompile(`-d\s+(\S+)`)
 
 	// Split into lines and process
-	lines := strings.Split(drivedbH, "\n")
+	lines := strings.Split(src, "\n")
 	var inUSBEntry bool
 	var currentFields []string
 
@@ -119,7 +123,6 @@ func loadDrivedbAddendum() map[string]string {
 		}
 	}
 
-	tlog.Debug("Loaded drivedb from smartmontools drivedb.h", "entries", len(cache))
 	return cache
 }
 
@@ -160,61 +163,81 @@ func extractUSBIDs(modelregexp string) []string {
 	return ids
 }
 
-// expandProductIDPattern expands a product ID pattern like "7[789]" to actual hex values
+// expandProductIDPattern expands a product ID pattern like "7[789]" or
+// "0[0-3]" to actual hex values. Patterns that are not plain hex digits or a
+// single character class of them are skipped.
 func expandProductIDPattern(vendor, prefix, pattern string) []string {
-	var ids []string
-
-	// Handle character class patterns like "7[789]"
-	charClassPattern := regexp.MustCompile(`^(\w)\[([^\]]+)\]$`)
+	// Handle character class patterns like "7[789]" and "0[0-3]"
+	charClassPattern := regexp.MustCompile(`^([0-9a-fA-F])\[([0-9a-fA-F-]+)\]$`)
 	if match := charClassPattern.FindStringSubmatch(pattern); len(match) >= 3 {
-		firstChar := match[1]
-		chars := match[2]
-		// Pre-allocate slice based on character class size
-		ids = make([]string, 0, len(chars))
+		chars := expandCharClass(match[2])
+		ids := make([]string, 0, len(chars))
 		for _, c := range chars {
-			var buf strings.Builder
-			buf.WriteString(vendor)
-			buf.WriteString(":0x")
-			buf.WriteString(prefix)
-			buf.WriteRune(rune(firstChar[0]))
-			buf.WriteRune(c)
-			ids = append(ids, buf.String())
+			ids = append(ids, vendor+":0x"+prefix+match[1]+string(c))
 		}
 		return ids
 	}
 
-	// Handle simple hex values like "80"
-	if len(pattern) == 2 {
-		var buf strings.Builder
-		buf.WriteString(vendor)
-		buf.WriteString(":0x")
-		buf.WriteString(prefix)
-		buf.WriteString(pattern)
-		ids = append(ids, buf.String())
-		return ids
+	// Handle simple hex values like "80" and full 4-digit hex like "0562"
+	if !isHex(pattern) {
+		return nil
 	}
-
-	// Handle full 4-digit hex like "0562"
-	if len(pattern) == 4 {
-		var buf strings.Builder
-		buf.WriteString(vendor)
-		buf.WriteString(":0x")
-		buf.WriteString(pattern)
-		ids = append(ids, buf.String())
-		return ids
+	switch len(pattern) {
+	case 2:
+		return []string{vendor + ":0x" + prefix + pattern}
+	case 4:
+		return []string{vendor + ":0x" + pattern}
 	}
 
 	// For other complex patterns, skip for now
-	return ids
+	return nil
+}
+
+// expandCharClass returns the hex digits of a regexp character class body
+// such as "789" or "0-3a-c". Malformed ranges are skipped.
+func expandCharClass(class string) []byte {
+	var chars []byte
+	for i := 0; i < len(class); i++ {
+		if i+2 < len(class) && class[i+1] == '-' {
+			lo, hi := class[i], class[i+2]
+			i += 2
+			if lo > hi || !isHex(string([]byte{lo, hi})) {
+				continue
+			}
+			for c := lo; c <= hi; c++ {
+				if isHex(string(c)) {
+					chars = append(chars, c)
+				}
+			}
+			continue
+		}
+		if class[i] != '-' {
+			chars = append(chars, class[i])
+		}
+	}
+	return chars
+}
+
+// isHex reports whether s is a non-empty string of hex digits.
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", rune(s[i])) {
+			return false
+		}
+	}
+	return true
 }
 
-// isUnknownUSBBridge checks if the smartctl messages contain an "Unknown USB bridge" error
+// isUnknownUSBBridge checks if the smartctl messages report an unknown USB bridge
 func isUnknownUSBBridge(smartInfo *SMARTInfo) bool {
 	if smartInfo == nil || smartInfo.Smartctl == nil {
 		return false
 	}
 	for _, msg := range smartInfo.Smartctl.Messages {
-		if strings.Contains(msg.String, "Unknown USB bridge") {
+		if msg.Code() == WarningUnknownUSBBridge {
 			return true
 		}
 	}
@@ -0,0 +1,88 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrElevationFailed is returned when the configured elevation command (for
+// example "sudo -n") refuses to run smartctl, typically because a password
+// would be required or the user is not allowed to run it.
+var ErrElevationFailed = errors.New("privilege elevation failed")
+
+// geteuid is swapped out by tests to simulate running as an unprivileged user.
+var geteuid = os.Geteuid
+
+// WithSudo runs smartctl through "sudo -n" when the process is not root. The
+// -n flag makes sudo fail instead of prompting, so a sudoers rule allowing
+// smartctl without a password is required.
+func WithSudo() Option {
+	return WithElevationCommand("sudo", "-n")
+}
+
+// WithElevationCommand runs smartctl through command (e.g. "doas" or "pkexec")
+// when the process is not root. args are placed between command and the
+// smartctl path; pass the tool's non-interactive flag (such as "-n" for doas)
+// so a missing credential fails fast instead of blocking on a prompt.
+// An empty command disables elevation.
+func WithElevationCommand(command string, args ...string) Option {
+	return func(b *ExecBackend) {
+		if command == "" {
+			b.elevation = nil
+			return
+		}
+		b.elevation = append([]string{command}, args...)
+	}
+}
+
+// smartctlRequest builds a smartctl invocation, prefixing it with the
+// elevation command when one is configured and the process is not already
+// root. os.Geteuid reports -1 on Windows, where elevation is never applied.
+// The returned tool name is empty when no elevation is applied.
+func (b *ExecBackend) smartctlRequest(ctx context.Context, args []string) (req CommandRequest, tool string) {
+	req = CommandRequest{Name: b.smartctlPath, Args: args, Env: b.env(), Timeout: b.timeout(ctx)}
+	if slices.Contains(args, "-C") {
+		// Captive self-tests run for as long as the test takes; only ctx bounds them.
+		req.Timeout = 0
+	}
+	if len(b.elevation) == 0 || geteuid() <= 0 {
+		return req, ""
+	}
+	full := make([]string, 0, len(b.elevation)+len(args))
+	full = append(full, b.elevation[1:]...)
+	full = append(full, b.smartctlPath)
+	full = append(full, args...)
+	req.Name, req.Args = b.elevation[0], full
+	return req, filepath.Base(b.elevation[0])
+}
+
+// checkElevation inspects a failed invocation made through the elevation tool
+// and converts the tool's own failures into ErrElevationFailed, so they are not
+// mistaken for smartctl exit status bits. smartctl never writes lines prefixed
+// with the tool's name, so such a line on stderr identifies a refusal by the
+// tool itself (e.g. "sudo: a password is required").
+func checkElevation(tool string, err error, res *CommandResult) error {
+	if err == nil || tool == "" {
+		return err
+	}
+	var execErr *osexec.Error
+	if errors.As(err, &execErr) {
+		return fmt.Errorf("%w: %w", ErrElevationFailed, err)
+	}
+	if len(res.Stdout) > 0 {
+		return err
+	}
+	prefix := tool + ":"
+	for line := range strings.Lines(string(res.Stderr)) {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, prefix) {
+			return fmt.Errorf("%w: %s", ErrElevationFailed, line)
+		}
+	}
+	return err
+}
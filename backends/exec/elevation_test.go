@@ -0,0 +1,86 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	osexec "os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withEUID(t *testing.T, uid int) {
+	t.Helper()
+	orig := geteuid
+	geteuid = func() int { return uid }
+	t.Cleanup(func() { geteuid = orig })
+}
+
+func TestElevation_PrefixesWhenNotRoot(t *testing.T) {
+	withEUID(t, 1000)
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"sudo -n /usr/sbin/smartctl --scan-open --json": {output: []byte(`{"devices":[{"name":"/dev/sda","type":"sat"}]}`)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock), WithSudo())
+	require.NoError(t, err)
+
+	devices, err := b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "/dev/sda", devices[0].Name)
+}
+
+func TestElevation_SkippedForRoot(t *testing.T) {
+	withEUID(t, 0)
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --scan-open --json": {output: []byte(`{"devices":[]}`)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock), WithElevationCommand("doas", "-n"))
+	require.NoError(t, err)
+
+	_, err = b.ScanDevices(context.Background())
+	require.NoError(t, err)
+}
+
+func TestElevation_EmptyCommandDisables(t *testing.T) {
+	withEUID(t, 1000)
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(&mockCommander{}), WithSudo(), WithElevationCommand(""))
+	require.NoError(t, err)
+	assert.Empty(t, b.elevation)
+}
+
+func TestCheckElevation(t *testing.T) {
+	exitErr := &osexec.ExitError{}
+
+	err := checkElevation("sudo", exitErr, &CommandResult{Stderr: []byte("sudo: a password is required\n")})
+	require.ErrorIs(t, err, ErrElevationFailed)
+	assert.Contains(t, err.Error(), "a password is required")
+
+	err = checkElevation("sudo", &osexec.Error{Name: "sudo", Err: osexec.ErrNotFound}, &CommandResult{})
+	assert.ErrorIs(t, err, ErrElevationFailed)
+
+	// smartctl's own failures pass through untouched so exit bits stay visible.
+	err = checkElevation("sudo", exitErr, &CommandResult{Stderr: []byte("Smartctl open device: /dev/sda failed\n")})
+	assert.Same(t, exitErr, err)
+	err = checkElevation("sudo", exitErr, &CommandResult{Stdout: []byte(`{"device":{}}`), Stderr: []byte("sudo: ignored when smartctl produced output")})
+	assert.Same(t, exitErr, err)
+	// Without elevation nothing is rewritten.
+	err = checkElevation("", exitErr, &CommandResult{Stderr: []byte("sudo: a password is required")})
+	assert.Same(t, exitErr, err)
+
+	assert.NoError(t, checkElevation("sudo", nil, &CommandResult{}))
+}
+
+func TestElevation_FailureSurfacesFromBackend(t *testing.T) {
+	withEUID(t, 1000)
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"doas -n /usr/sbin/smartctl -X /dev/sda": {err: &osexec.Error{Name: "doas", Err: osexec.ErrNotFound}},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock), WithElevationCommand("doas", "-n"))
+	require.NoError(t, err)
+
+	err = b.AbortSelfTest(context.Background(), "/dev/sda")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrElevationFailed))
+}
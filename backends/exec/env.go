@@ -0,0 +1,89 @@
+package exec
+
+import (
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// lookupEnv returns the value of key in env, a list of "KEY=value" entries
+// where the last entry for a key wins, as it does for os/exec.
+func lookupEnv(env []string, key string) (string, bool) {
+	for i := len(env) - 1; i >= 0; i-- {
+		if k, v, ok := strings.Cut(env[i], "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// getenvWith returns an os.Getenv that sees the entries of env first.
+func getenvWith(env []string) func(string) string {
+	return func(key string) string {
+		if v, ok := lookupEnv(env, key); ok {
+			return v
+		}
+		return os.Getenv(key)
+	}
+}
+
+// commandPath resolves a bare command name against the PATH set in env, so
+// that WithCommandEnv("PATH=...") selects which binary runs: os/exec itself
+// always searches the PATH of the process. name is returned unchanged when
+// env sets no PATH, name contains a path separator or it is not found there.
+func commandPath(name string, env []string) string {
+	path, ok := lookupEnv(env, "PATH")
+	if !ok || strings.ContainsAny(name, `/\`) {
+		return name
+	}
+	if resolved, ok := searchPath(name, path); ok {
+		return resolved
+	}
+	return name
+}
+
+// lookPath is exec.LookPath searching the PATH of env when env sets one.
+func lookPath(name string, env []string) (string, error) {
+	path, ok := lookupEnv(env, "PATH")
+	if !ok {
+		return osexec.LookPath(name)
+	}
+	if resolved, ok := searchPath(name, path); ok {
+		return resolved, nil
+	}
+	return "", &osexec.Error{Name: name, Err: osexec.ErrNotFound}
+}
+
+// searchPath looks for an executable file called name in the directories of
+// path. On Windows it also tries name with an ".exe" suffix.
+func searchPath(name, path string) (string, bool) {
+	names := []string{name}
+	if runtime.GOOS == "windows" && filepath.Ext(name) == "" {
+		names = append(names, name+".exe")
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if !filepath.IsAbs(dir) {
+			// os/exec refuses results relative to the current directory too.
+			continue
+		}
+		for _, n := range names {
+			candidate := filepath.Join(dir, n)
+			if isExecutableFile(candidate) {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// isExecutableFile reports whether path is a regular file that can be run.
+// Windows has no execute permission bits; the .exe suffix suffices.
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0o111 != 0
+}
@@ -0,0 +1,83 @@
+package exec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupEnv(t *testing.T) {
+	env := []string{"PATH=/a", "LANG=C", "PATH=/b", "EMPTY="}
+	v, ok := lookupEnv(env, "PATH")
+	assert.True(t, ok)
+	assert.Equal(t, "/b", v, "the last entry wins")
+	v, ok = lookupEnv(env, "EMPTY")
+	assert.True(t, ok)
+	assert.Empty(t, v)
+	_, ok = lookupEnv(env, "LC_ALL")
+	assert.False(t, ok)
+
+	t.Setenv("SMARTGO_TEST_VAR", "process")
+	getenv := getenvWith([]string{"LANG=C"})
+	assert.Equal(t, "C", getenv("LANG"))
+	assert.Equal(t, "process", getenv("SMARTGO_TEST_VAR"))
+}
+
+// writeExecutable creates an executable shell script called name in dir.
+func writeExecutable(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestCommandPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	dir := t.TempDir()
+	tool := writeExecutable(t, dir, "smartctl", "")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plain"), nil, 0o644))
+
+	env := []string{"PATH=relative:" + dir}
+	assert.Equal(t, tool, commandPath("smartctl", env))
+	assert.Equal(t, "plain", commandPath("plain", env), "non-executable files are skipped")
+	assert.Equal(t, "smartctl", commandPath("smartctl", nil), "without PATH os/exec looks it up")
+	assert.Equal(t, "/usr/sbin/smartctl", commandPath("/usr/sbin/smartctl", env))
+
+	_, err := lookPath("smartctl", []string{"PATH=" + t.TempDir()})
+	assert.Error(t, err)
+}
+
+func TestResolveSmartctlPath_CommandEnvPATH(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	dir := t.TempDir()
+	bundled := writeExecutable(t, dir, "smartctl", "")
+	t.Setenv("PATH", "")
+
+	got, err := resolveSmartctlPath([]string{"PATH=" + dir})
+	require.NoError(t, err)
+	assert.Equal(t, bundled, got)
+}
+
+func TestExecCommander_CommandEnvPATH(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	dir := t.TempDir()
+	writeExecutable(t, dir, "bundled-tool", `echo "$BUNDLED_LIB $LC_ALL"`)
+
+	res, err := execCommander{}.CommandContext(context.Background(), newMinimalTestLogger(), CommandRequest{
+		Name: "bundled-tool",
+		Env:  []string{"PATH=" + dir + string(os.PathListSeparator) + os.Getenv("PATH"), "BUNDLED_LIB=/opt/lib", "LC_ALL=C"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/lib C\n", string(res.Stdout))
+}
@@ -3,6 +3,7 @@ package exec
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dianlight/tlog"
 )
@@ -19,9 +21,13 @@ import (
 var (
 	_ Backend          = (*ExecBackend)(nil)
 	_ DiscoveryBackend = (*ExecBackend)(nil)
+	_ ScanBackend      = (*ExecBackend)(nil)
+	_ RawBackend       = (*ExecBackend)(nil)
+	_ SmartctlBackend  = (*ExecBackend)(nil)
+	_ VersionBackend   = (*ExecBackend)(nil)
 )
 
-// smartctlSearchPaths contains platform-specific locations tried in order when
+// smartctlSearchPaths contains Unix-like platform locations tried in order when
 // smartctl is not found in PATH. Ordered from most-common to most-specific to
 // minimise stat calls on standard Linux installs.
 var smartctlSearchPaths = []string{
@@ -53,13 +59,46 @@ type Option func(*ExecBackend)
 // ExecBackend is a [Backend] implementation that shells out to the smartctl binary.
 type ExecBackend struct {
 	smartctlPath       string
-	commander          Commander
+	commander          Commander        // default or WithCommander; adapted when runner is nil
+	runner             ContextCommander // set by WithContextCommander
 	defaultCommander   bool
+	commandEnv         []string
+	locale             string // LC_ALL and LANG of every command; empty inherits the process locale
+	commandTimeout     time.Duration
 	deviceTypeCache    map[string]string
 	deviceTypeCacheMux sync.RWMutex
 	healthBitsCache    map[string]int
 	healthBitsCacheMux sync.RWMutex
 	logHandler         LogAdapter
+	elevation          []string // e.g. ["sudo", "-n"]; applied only when not running as root
+	textOutput         bool     // parse classic text output; smartctl < 7.0 has no -j
+	strictJSONFormat   bool     // reject unsupported json_format_version instead of warning
+	version            *SmartctlVersionInfo
+	driveDBVersion     string // learned from device responses; "smartctl -V" omits it
+	versionMux         sync.Mutex
+	deviceLocks        sync.Map // device path -> chan struct{}; see lockDevice
+	rateInterval       time.Duration
+	rateBurst          int
+	rateBuckets        sync.Map         // device path -> *deviceBucket; see waitDeviceRate
+	virtualDevices     map[string]error // device path -> *VirtualDeviceError of the last scan; see markVirtual
+	virtualMux         sync.Mutex
+	sharedWindow       time.Duration
+	sharedQueries      map[string]*sharedQuery // see querySMARTInfo
+	sharedMux          sync.Mutex
+	noCheck            NoCheckPolicy
+	noCheckSkips       map[string]int // consecutive skipped queries per device; see recordNoCheck
+	noCheckMux         sync.Mutex
+	tolerance          Tolerance // smartctl -T level; empty leaves smartctl's default
+	openRetries        int       // see WithOpenRetry
+	openRetryDelay     time.Duration
+	commandHooks       []CommandHook            // see WithCommandHook
+	deviceOptions      map[string]DeviceOptions // per-device overrides; see WithDeviceOptions
+	deviceOptionLinks  []string                 // /dev/disk keys of deviceOptions, resolved at lookup
+
+	attributePresets      map[string][]string // user '-v' overrides keyed by device path
+	drivedbPresets        map[string][]string // presets learned from the embedded drivedb
+	presetsMux            sync.RWMutex
+	disableDrivedbPresets bool
 }
 
 // WithSmartctlPath sets a custom path to the smartctl binary.
@@ -69,14 +108,81 @@ func WithSmartctlPath(path string) Option {
 	}
 }
 
-// WithCommander sets a custom commander, typically for testing.
+// WithCommander sets a custom commander, typically for testing. Commanders
+// that also implement ContextCommander are used through that interface.
+//
+// Deprecated: Use WithContextCommander.
 func WithCommander(commander Commander) Option {
 	return func(b *ExecBackend) {
 		b.commander = commander
+		b.runner = nil
+		b.defaultCommander = false
+	}
+}
+
+// WithContextCommander sets a custom command executor, typically for testing.
+func WithContextCommander(commander ContextCommander) Option {
+	return func(b *ExecBackend) {
+		b.commander = nil
+		b.runner = commander
 		b.defaultCommander = false
 	}
 }
 
+// DefaultCommandTimeout is the command timeout unless WithCommandTimeout
+// changes it. smartctl answers within seconds on healthy drives but can hang
+// for minutes on dying ones, especially behind USB bridges.
+const DefaultCommandTimeout = 5 * time.Minute
+
+// WithCommandTimeout kills any smartctl (or helper tool) invocation that runs
+// longer than timeout, independently of the caller's context, and fails it
+// with a *CommandTimeoutError matching ErrCommandTimeout. The default is
+// DefaultCommandTimeout; zero disables the per-command limit. Captive
+// self-tests are never limited, and ContextWithCommandTimeout overrides the
+// timeout per call.
+func WithCommandTimeout(timeout time.Duration) Option {
+	return func(b *ExecBackend) {
+		b.commandTimeout = timeout
+	}
+}
+
+// WithCommandEnv adds "KEY=value" entries to the environment of every command
+// run by the backend, e.g. "LD_LIBRARY_PATH=/opt/smartmontools/lib" for a
+// bundled smartctl. A "PATH" entry also decides where smartctl, unless set
+// with WithSmartctlPath, and the helper tools are looked up. Entries are
+// passed through CommandRequest.Env, so custom commanders see them. Elevation
+// tools such as sudo reset the environment; variables reach smartctl through
+// them only as far as their policy keeps them, and sudo always drops LD_*.
+func WithCommandEnv(env ...string) Option {
+	return func(b *ExecBackend) {
+		b.commandEnv = append(b.commandEnv, env...)
+	}
+}
+
+// DefaultLocale is the locale smartctl runs with unless WithLocale changes it.
+const DefaultLocale = "C"
+
+// WithLocale sets LC_ALL and LANG of every command run by the backend to
+// locale, DefaultLocale ("C") unless set, so that smartctl's text output,
+// such as "PASSED" from -H and the -V banner, is not translated. An empty
+// locale inherits the locale of the process. Entries added with
+// WithCommandEnv take precedence.
+func WithLocale(locale string) Option {
+	return func(b *ExecBackend) {
+		b.locale = locale
+	}
+}
+
+// WithTextOutput makes the backend parse smartctl's classic text output
+// instead of JSON. It is enabled automatically when the detected smartctl is
+// older than 7.0; text mode recovers identity, health, attributes and
+// self-test status only.
+func WithTextOutput(enabled bool) Option {
+	return func(b *ExecBackend) {
+		b.textOutput = enabled
+	}
+}
+
 // WithSlogHandler sets a custom slog.Logger for the backend.
 func WithSlogHandler(logger *slog.Logger) Option {
 	return withLogHandler(logger)
@@ -92,6 +198,22 @@ func WithLogHandler(logger LogAdapter) Option {
 	return withLogHandler(logger)
 }
 
+// WithAttributePresets sets attribute interpretation overrides for a device,
+// passed to smartctl as '-v' options (e.g. "9,minutes").
+func WithAttributePresets(devicePath string, presets ...string) Option {
+	return func(b *ExecBackend) {
+		b.SetAttributePresets(devicePath, presets...)
+	}
+}
+
+// WithDrivedbPresets controls whether presets from the embedded drivedb are
+// applied to drives missing from the installed smartctl database. Enabled by default.
+func WithDrivedbPresets(enabled bool) Option {
+	return func(b *ExecBackend) {
+		b.disableDrivedbPresets = !enabled
+	}
+}
+
 func withLogHandler(logger LogAdapter) Option {
 	return func(b *ExecBackend) {
 		b.logHandler = logger
@@ -105,45 +227,64 @@ func New(opts ...Option) (*ExecBackend, error) {
 		defaultCommander: true,
 		deviceTypeCache:  cloneDeviceTypeCache(),
 		healthBitsCache:  make(map[string]int),
+		sharedQueries:    make(map[string]*sharedQuery),
+		noCheckSkips:     make(map[string]int),
 		logHandler:       tlog.NewLoggerWithLevel(tlog.LevelDebug),
+		attributePresets: make(map[string][]string),
+		drivedbPresets:   make(map[string][]string),
+		locale:           DefaultLocale,
+		commandTimeout:   DefaultCommandTimeout,
 	}
 	for _, opt := range opts {
 		opt(b)
 	}
+	if err := b.noCheck.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.tolerance.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.validateDeviceOptions(); err != nil {
+		return nil, err
+	}
+	b.logHandler.Debug("Loaded drivedb from smartmontools drivedb.h", "entries", len(drivedbCache))
 	if b.smartctlPath == "" {
-		path, err := resolveSmartctlPath()
+		path, err := resolveSmartctlPath(b.commandEnv)
 		if err != nil {
 			return nil, err
 		}
 		b.smartctlPath = path
 	}
 	if b.defaultCommander {
-		if err := ensureCompatibleSmartctl(b.smartctlPath); err != nil {
+		version, err := ensureCompatibleSmartctl(b.smartctlPath, b.env())
+		if err != nil {
 			return nil, err
 		}
+		b.version = version
+		if version.Major < minJSONMajor && !b.textOutput {
+			b.logHandler.WarnContext(context.Background(), "smartctl older than 7.0 has no JSON output, falling back to degraded text parsing", "smartctlPath", b.smartctlPath)
+			b.textOutput = true
+		}
 	}
 	return b, nil
 }
 
 // resolveSmartctlPath searches PATH and then platform-specific fallback
-// locations for a usable smartctl binary. The WithSmartctlPath option always
-// takes precedence and bypasses this function entirely.
-func resolveSmartctlPath() (string, error) {
+// locations for a usable smartctl binary. Variables set in env, the
+// WithCommandEnv entries, replace those of the process, so a PATH given there
+// is searched instead. The WithSmartctlPath option always takes precedence
+// and bypasses this function entirely.
+func resolveSmartctlPath(env []string) (string, error) {
 	// 1. Prefer PATH so that user-installed or version-managed binaries win.
-	if path, err := exec.LookPath("smartctl"); err == nil {
+	if path, err := lookPath("smartctl", env); err == nil {
 		return path, nil
 	}
 
 	// 2. Search known platform-specific paths.
-	for _, candidate := range smartctlSearchPaths {
-		info, err := os.Stat(candidate)
-		if err != nil || info.IsDir() {
-			continue
-		}
-		if info.Mode()&0o111 == 0 {
-			continue // not executable
+	for _, candidate := range platformSmartctlSearchPaths(getenvWith(env)) {
+		if isExecutableFile(candidate) {
+			return candidate, nil
 		}
-		return candidate, nil
 	}
 
 	return "", fmt.Errorf(
@@ -155,26 +296,34 @@ func resolveSmartctlPath() (string, error) {
 			"  Synology:              Install SynoCli Disk Tools from SynoCommunity\n" +
 			"  QNAP:                  Install smartmontools via Entware (opkg install smartmontools)\n" +
 			"  FreeBSD/TrueNAS:       pkg install smartmontools\n" +
+			"  Windows:               choco install smartmontools, or the installer from smartmontools.org\n" +
 			"More info: https://www.smartmontools.org/wiki/Download",
 	)
 }
 
+// Oldest smartctl major versions supported through the text and JSON parsers.
+const minTextMajor, minJSONMajor = 6, 7
+
 // ensureCompatibleSmartctl runs "smartctl -V" and checks the version is supported.
-// The library depends on JSON output (-j), which requires smartctl >= 7.0.
-func ensureCompatibleSmartctl(smartctlPath string) error {
-	out, err := exec.Command(smartctlPath, "-V").Output()
+// JSON output (-j) requires smartctl >= 7.0; 6.x is supported through the text
+// output parser.
+func ensureCompatibleSmartctl(smartctlPath string, env []string) (*SmartctlVersionInfo, error) {
+	cmd := exec.Command(commandPath(smartctlPath, env), "-V")
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	out, err := cmd.Output()
 	if err != nil {
-		return fmt.Errorf("failed to check smartctl version: %w", err)
+		return nil, fmt.Errorf("failed to check smartctl version: %w", err)
 	}
-	major, minor, err := parseSmartctlVersion(string(out))
+	version, err := parseSmartctlVersionInfo(string(out))
 	if err != nil {
-		return fmt.Errorf("unable to parse smartctl version: %w", err)
+		return nil, fmt.Errorf("unable to parse smartctl version: %w", err)
 	}
-	const minMajor, minMinor = 7, 0
-	if major < minMajor || (major == minMajor && minor < minMinor) {
-		return fmt.Errorf("unsupported smartctl version %d.%d; require >= %d.%d", major, minor, minMajor, minMinor)
+	if version.Major < minTextMajor {
+		return nil, fmt.Errorf("unsupported smartctl version %d.%d; require >= %d.0", version.Major, version.Minor, minTextMajor)
 	}
-	return nil
+	return version, nil
 }
 
 // parseSmartctlVersion extracts the major and minor version numbers from
@@ -217,12 +366,12 @@ func (b *ExecBackend) SmartctlPath() string {
 
 // SetDeviceTypeHint stores a device type hint in the backend cache.
 func (b *ExecBackend) SetDeviceTypeHint(path, deviceType string) {
-	b.setCachedDeviceType(path, deviceType)
+	b.setCachedDeviceType(NormalizeDevicePath(path), deviceType)
 }
 
 // DeviceTypeHint returns a cached device type hint for the provided path.
 func (b *ExecBackend) DeviceTypeHint(path string) (string, bool) {
-	return b.getCachedDeviceType(path)
+	return b.getCachedDeviceType(NormalizeDevicePath(path))
 }
 
 // NewExecBackend preserves the legacy constructor name.
@@ -235,26 +384,123 @@ func NewExecBackend(opts ...Option) (*ExecBackend, error) {
 // accessibility) and falls back to --scan on failure. --scan-open may fail in
 // container sandboxes, on older kernels, or when the caller lacks the required
 // permissions; --scan still returns the device list without the open step.
+//
+// On macOS, where the smartctl scan often misses external drives, physical
+// disks reported by diskutil are probed as well and added when smartctl can
+// open them. FreeBSD (camcontrol, including CAM passthrough nodes) and OpenBSD
+// (hw.disknames) disks are probed the same way.
 func (b *ExecBackend) ScanDevices(ctx context.Context) ([]Device, error) {
+	return b.ScanDevicesWithOptions(ctx, ScanOptions{})
+}
+
+// ScanDevicesWithOptions is ScanDevices restricted by opts. Each of opts.Types
+// is scanned separately with "--scan-open -d TYPE", so smartctl only probes
+// those device classes; disks found by the platform enumerators are likewise
+// only probed as the listed types. opts.Interfaces then filters the result.
+func (b *ExecBackend) ScanDevicesWithOptions(ctx context.Context, opts ScanOptions) ([]Device, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, "--scan-open", "--json")
-	output, err := cmd.Output()
-	if err != nil {
-		// Fall back to --scan when --scan-open is unsupported or fails.
-		b.logHandler.WarnContext(ctx, "--scan-open failed, retrying with --scan", "err", err)
-		fallbackCmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, "--scan", "--json")
-		output, err = fallbackCmd.Output()
+	var devices []Device
+	if len(opts.Types) == 0 {
+		found, err := b.scanDevices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		devices = found
+	}
+	seen := make(map[string]bool)
+	for _, deviceType := range opts.Types {
+		found, err := b.scanDevices(ctx, "-d", deviceType)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan devices: %w", err)
+			return nil, err
+		}
+		for _, d := range found {
+			if !seen[d.Name] {
+				seen[d.Name] = true
+				devices = append(devices, d)
+			}
+		}
+	}
+
+	scanned := len(devices)
+	devices = b.appendPlatformDevices(ctx, devices, opts.Types)
+	matching := devices[:scanned]
+	for _, d := range devices[scanned:] {
+		if opts.HasType(d.Type) {
+			matching = append(matching, d)
+		}
+	}
+	matching = mergeNVMeNamespaces(matching)
+	b.addSysfsInfo(matching)
+	b.markVirtual(matching)
+	devices = matching[:0]
+	for _, d := range matching {
+		if opts.MatchesInterface(d) {
+			devices = append(devices, d)
 		}
 	}
+	return devices, nil
+}
 
+// scanDevices runs a single smartctl scan with the extra args, such as
+// "-d nvme", and caches the device types it reports. When --scan-open fails
+// or finds nothing, which is common without root, it lists the devices with
+// --scan instead and marks them NotOpened.
+func (b *ExecBackend) scanDevices(ctx context.Context, args ...string) ([]Device, error) {
+	if b.textOutput {
+		return b.scanDevicesText(ctx, args...)
+	}
+	res, err := b.run(ctx, append([]string{"--scan-open", "--json"}, args...)...)
+	var devices []Device
+	if err == nil {
+		if devices, err = parseScanJSON(res.Stdout); err != nil {
+			return nil, err
+		}
+	}
+	if err != nil || len(devices) == 0 {
+		if err != nil {
+			b.logHandler.WarnContext(ctx, "--scan-open failed, retrying with --scan", "err", err)
+		} else {
+			b.logHandler.DebugContext(ctx, "--scan-open found no devices, retrying with --scan")
+		}
+		listed, scanErr := b.run(withFallback(ctx, FallbackScan), append([]string{"--scan", "--json"}, args...)...)
+		if scanErr != nil {
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan devices: %w", scanErr)
+			}
+			b.logHandler.DebugContext(ctx, "--scan failed", "err", scanErr)
+			return devices, nil
+		}
+		if devices, err = parseScanJSON(listed.Stdout); err != nil {
+			return nil, err
+		}
+		markNotOpened(ctx, b.logHandler, devices)
+		return devices, nil
+	}
+
+	for _, d := range devices {
+		// Cache device type discovered by --scan-open so all subsequent methods
+		// can use --nocheck=standby and the correct -d <type> argument without
+		// needing an extra disk query.
+		if d.Name != "" && d.Type != "" && d.OpenError == "" {
+			if _, cached := b.getCachedDeviceType(d.Name); !cached {
+				b.setCachedDeviceType(d.Name, d.Type)
+			}
+		}
+	}
+	return devices, nil
+}
+
+// parseScanJSON parses the JSON output of "smartctl --scan" or --scan-open.
+func parseScanJSON(output []byte) ([]Device, error) {
 	var result struct {
 		Devices []struct {
-			Name string `json:"name"`
-			Type string `json:"type"`
+			Name      string `json:"name"`
+			InfoName  string `json:"info_name"`
+			Type      string `json:"type"`
+			Protocol  string `json:"protocol"`
+			OpenError string `json:"open_error"`
 		} `json:"devices"`
 	}
 
@@ -266,45 +512,68 @@ func (b *ExecBackend) ScanDevices(ctx context.Context) ([]Device, error) {
 	devices := make([]Device, len(result.Devices))
 	for i, d := range result.Devices {
 		devices[i] = Device{
-			Name: d.Name,
-			Type: d.Type,
-		}
-		// Cache device type discovered by --scan-open so all subsequent methods
-		// can use --nocheck=standby and the correct -d <type> argument without
-		// needing an extra disk query.
-		if d.Name != "" && d.Type != "" {
-			if _, cached := b.getCachedDeviceType(d.Name); !cached {
-				b.setCachedDeviceType(d.Name, d.Type)
-			}
+			Name:      d.Name,
+			Type:      d.Type,
+			InfoName:  d.InfoName,
+			Protocol:  d.Protocol,
+			OpenError: d.OpenError,
 		}
 	}
-
 	return devices, nil
 }
 
+// markNotOpened flags devices listed by --scan only. Their types are
+// smartctl's guesses from the device names (e.g. "scsi" for a SATA disk), so
+// unlike --scan-open results they are not cached.
+func markNotOpened(ctx context.Context, logger LogAdapter, devices []Device) {
+	for i := range devices {
+		devices[i].NotOpened = true
+	}
+	if len(devices) > 0 {
+		logger.WarnContext(ctx, "devices could not be opened; SMART queries may need root or disk group permissions", "devices", len(devices))
+	}
+}
+
 // GetSMARTInfo retrieves SMART information for a device.
 func (b *ExecBackend) GetSMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	info, _, err := b.getSMARTInfoInternal(ctx, devicePath)
+	devicePath = NormalizeDevicePath(devicePath)
+	info, _, _, err := b.querySMARTInfo(ctx, devicePath)
 	return info, err
 }
 
+// GetSMARTInfoRaw is like GetSMARTInfo but also returns the JSON document
+// printed by smartctl, unmodified. raw is nil when no output was produced,
+// e.g. for a device in standby that answered without data.
+func (b *ExecBackend) GetSMARTInfoRaw(ctx context.Context, devicePath string) (*SMARTInfo, json.RawMessage, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	info, raw, _, err := b.querySMARTInfo(ctx, devicePath)
+	return info, raw, err
+}
+
 // CheckHealth checks if a device is healthy according to SMART.
 func (b *ExecBackend) CheckHealth(ctx context.Context, devicePath string) (bool, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, b.buildArgs(devicePath, "-H")...)
-	output, err := cmd.Output()
+	devicePath = NormalizeDevicePath(devicePath)
+	if b.sharing() {
+		return b.sharedHealth(ctx, devicePath)
+	}
+	res, err := b.run(ctx, b.buildArgs(ctx, devicePath, "-H")...)
+	output := res.Stdout
 	if err != nil {
 		// Exit code 2: device in standby
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode := exitErr.ExitCode()
+		if exitErr, ok := asExitError(err); ok {
+			exitCode := exitCodeOf(res, exitErr)
 			// exitCode == -1 means ProcessState is not set (mock/testing scenario)
 			// exitCode&2 != 0 means device is in standby mode
-			if exitCode != -1 && exitCode&2 != 0 {
+			if exitCode != -1 && exitCode&2 != 0 && !isOpenFailure(err) {
 				// Device in standby - cannot determine health
 				b.logHandler.DebugContext(ctx, "Device in standby mode, cannot check health", "devicePath", devicePath)
 				return false, nil
@@ -328,12 +597,16 @@ func (b *ExecBackend) GetDeviceInfo(ctx context.Context, devicePath string) (map
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, b.buildArgs(devicePath, "-i", "-j")...)
-	output, err := cmd.Output()
+	devicePath = NormalizeDevicePath(devicePath)
+	if b.textOutput {
+		return b.getDeviceInfoText(ctx, devicePath)
+	}
+	res, err := b.run(ctx, b.buildArgs(ctx, devicePath, "-i", "-j")...)
+	output := res.Stdout
 	if err != nil {
 		// Exit code 2: device in standby
-		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode()&2 != 0 {
-			return nil, fmt.Errorf("device in standby mode")
+		if exitErr, ok := asExitError(err); ok && exitCodeOf(res, exitErr)&2 != 0 && !isOpenFailure(err) {
+			return nil, fmt.Errorf("%w: %s", ErrDeviceInStandby, devicePath)
 		}
 		return nil, fmt.Errorf("failed to get device info: %w", err)
 	}
@@ -348,17 +621,37 @@ func (b *ExecBackend) GetDeviceInfo(ctx context.Context, devicePath string) (map
 
 // RunSelfTest initiates a SMART self-test.
 func (b *ExecBackend) RunSelfTest(ctx context.Context, devicePath string, testType string) error {
+	return b.RunSelfTestWithOptions(ctx, devicePath, testType, SelfTestOptions{})
+}
+
+// RunSelfTestWithOptions initiates a SMART self-test. A captive test ("-C")
+// blocks until the drive has finished it and is not subject to the command
+// timeout; a test that fails in captive mode returns an error.
+func (b *ExecBackend) RunSelfTestWithOptions(ctx context.Context, devicePath string, testType string, opts SelfTestOptions) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	devicePath = NormalizeDevicePath(devicePath)
 	// Valid test types: short, long, conveyance, offline
 	if !slices.Contains(validSelfTestTypes, testType) {
 		return fmt.Errorf("invalid test type: %s (must be one of: short, long, conveyance, offline)", testType)
 	}
+	args := []string{"-t", testType}
+	if opts.Captive {
+		args = append(args, "-C")
+	}
+	return b.startSelfTest(ctx, devicePath, testType, args)
+}
 
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, "-t", testType, devicePath)
-	if err := cmd.Run(); err != nil {
-		output, _ := cmd.CombinedOutput()
+// startSelfTest runs smartctl with the self-test args for devicePath, marking
+// failures the drive reports as unsupported with ErrTestNotSupported.
+func (b *ExecBackend) startSelfTest(ctx context.Context, devicePath string, testType string, args []string) error {
+	defer b.forgetSharedQuery(devicePath)
+	if res, err := b.run(ctx, append(args, devicePath)...); err != nil {
+		output := append(append([]byte(nil), res.Stdout...), res.Stderr...)
+		if strings.Contains(strings.ToLower(string(output)), "not supported") {
+			err = fmt.Errorf("%w: %w", ErrTestNotSupported, err)
+		}
 		return fmt.Errorf("failed to run self-test: %w (devicePath: %s, testType: %s, output: %s)", err, devicePath, testType, string(output))
 	}
 
@@ -370,14 +663,27 @@ func (b *ExecBackend) GetAvailableSelfTests(ctx context.Context, devicePath stri
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, b.buildArgs(devicePath, "-c", "-j")...)
-	output, err := cmd.Output()
+	devicePath = NormalizeDevicePath(devicePath)
+	if b.textOutput {
+		return b.getAvailableSelfTestsText(ctx, devicePath)
+	}
+	if b.sharing() {
+		return b.sharedSelfTests(ctx, devicePath)
+	}
+	res, err := b.run(ctx, b.buildArgs(ctx, devicePath, "-c", "-j")...)
+	output := res.Stdout
 	if err != nil {
 		// Exit code 2: device in standby
-		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode()&2 != 0 {
-			return nil, fmt.Errorf("device in standby mode")
+		exitErr, ok := asExitError(err)
+		if ok && exitCodeOf(res, exitErr)&2 != 0 && !isOpenFailure(err) {
+			return nil, fmt.Errorf("%w: %s", ErrDeviceInStandby, devicePath)
+		}
+		// NVMe-oF targets may reject some of the requests (exit bit 2) and
+		// still report their capabilities.
+		if !ok || exitCodeOf(res, exitErr) != 4 || !b.isNVMeFabrics(devicePath) || !json.Valid(output) {
+			return nil, fmt.Errorf("failed to get capabilities: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get capabilities: %w", err)
+		b.logHandler.DebugContext(ctx, "NVMe over Fabrics device reported partial capabilities", "devicePath", devicePath)
 	}
 
 	var caps CapabilitiesOutput
@@ -398,8 +704,9 @@ func (b *ExecBackend) EnableSMART(ctx context.Context, devicePath string) error
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, "-s", "on", devicePath)
-	if err := cmd.Run(); err != nil {
+	devicePath = NormalizeDevicePath(devicePath)
+	defer b.forgetSharedQuery(devicePath)
+	if _, err := b.run(ctx, "-s", "on", devicePath); err != nil {
 		return fmt.Errorf("failed to enable SMART: %w", err)
 	}
 	return nil
@@ -411,6 +718,7 @@ func (b *ExecBackend) DisableSMART(ctx context.Context, devicePath string) error
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	devicePath = NormalizeDevicePath(devicePath)
 
 	// Check the cached device type first to avoid an unnecessary full disk query.
 	// GetSMARTInfo populates the cache on its first successful call, so this path
@@ -430,8 +738,8 @@ func (b *ExecBackend) DisableSMART(ctx context.Context, devicePath string) error
 		}
 	}
 
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, "-s", "off", devicePath)
-	if err := cmd.Run(); err != nil {
+	defer b.forgetSharedQuery(devicePath)
+	if _, err := b.run(ctx, "-s", "off", devicePath); err != nil {
 		return fmt.Errorf("failed to disable SMART: %w", err)
 	}
 	return nil
@@ -442,8 +750,9 @@ func (b *ExecBackend) AbortSelfTest(ctx context.Context, devicePath string) erro
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, "-X", devicePath)
-	if err := cmd.Run(); err != nil {
+	devicePath = NormalizeDevicePath(devicePath)
+	defer b.forgetSharedQuery(devicePath)
+	if _, err := b.run(ctx, "-X", devicePath); err != nil {
 		return fmt.Errorf("failed to abort self-test: %w", err)
 	}
 	return nil
@@ -468,7 +777,7 @@ func (b *ExecBackend) DiscoverDevices(ctx context.Context) ([]DiscoveryResult, e
 			DetectedProtocol: dev.Type,
 		}
 
-		info, usedSATFallback, infoErr := b.getSMARTInfoInternal(ctx, dev.Name)
+		info, _, usedSATFallback, infoErr := b.querySMARTInfo(ctx, dev.Name)
 		if infoErr == nil && info != nil {
 			result.SMARTReadable = true
 			result.SATFallbackRequired = usedSATFallback
@@ -478,9 +787,9 @@ func (b *ExecBackend) DiscoverDevices(ctx context.Context) ([]DiscoveryResult, e
 				result.Model = info.ModelFamily
 			}
 			result.Serial = info.SerialNumber
-		} else {
+		} else if !errors.Is(infoErr, ErrVirtualDevice) {
 			// The auto-detected protocol failed; try SAT explicitly.
-			if satInfo, ok := b.retryWithDeviceType(ctx, dev.Name, "sat"); ok && satInfo != nil {
+			if satInfo, _, ok := b.retryWithDeviceType(ctx, dev.Name, "sat", b.smartInfoFlag()); ok && satInfo != nil {
 				result.SMARTReadable = true
 				result.SATFallbackRequired = true
 				result.DetectedProtocol = "sat"
@@ -497,8 +806,12 @@ func (b *ExecBackend) DiscoverDevices(ctx context.Context) ([]DiscoveryResult, e
 	return results, nil
 }
 
-// getCachedDeviceType retrieves a cached device type for the given device path.
+// getCachedDeviceType retrieves a cached device type for the given device
+// path. A type set with WithDeviceOptions takes precedence over the cache.
 func (b *ExecBackend) getCachedDeviceType(devicePath string) (string, bool) {
+	if deviceType := b.optionsFor(devicePath).Type; deviceType != "" {
+		return deviceType, true
+	}
 	b.deviceTypeCacheMux.RLock()
 	defer b.deviceTypeCacheMux.RUnlock()
 	deviceType, ok := b.deviceTypeCache[devicePath]
@@ -514,19 +827,171 @@ func (b *ExecBackend) setCachedDeviceType(devicePath, deviceType string) {
 }
 
 // buildArgs assembles smartctl arguments for devicePath, prepending flags and
-// inserting --nocheck=standby (ATA only) plus -d <type> when the device type
-// is already known from the cache. Falls back to the ATA-safe default when the
-// cache is cold.
-func (b *ExecBackend) buildArgs(devicePath string, flags ...string) []string {
+// inserting the --nocheck power policy (ATA only) plus -d <type> when the
+// device type is already known from the cache. Falls back to the ATA-safe
+// default when the cache is cold. Attribute presets, if any, are placed before
+// the device path.
+func (b *ExecBackend) buildArgs(ctx context.Context, devicePath string, flags ...string) []string {
+	b.learnNVMeFabrics(ctx, devicePath)
 	if cachedType, ok := b.getCachedDeviceType(devicePath); ok {
 		args := append([]string(nil), flags...)
 		if isATADevice(cachedType) {
-			args = append(args, "--nocheck=standby")
+			args = append(args, b.noCheckArg(ctx, devicePath))
 		}
-		return append(args, "-d", cachedType, devicePath)
+		args = append(args, "-d", cachedType)
+		return append(append(args, b.presetArgs(devicePath)...), devicePath)
 	}
-	// Unknown device type — assume ATA and add --nocheck=standby.
-	return append(append([]string(nil), flags...), "--nocheck=standby", devicePath)
+	// Unknown device type — assume ATA and add the --nocheck policy.
+	args := append(append([]string(nil), flags...), b.noCheckArg(ctx, devicePath))
+	return append(append(args, b.presetArgs(devicePath)...), devicePath)
+}
+
+// run executes smartctl with args, applying the device's extra arguments, the
+// -T tolerance, elevation, the command environment, the per-command timeout
+// and open retries. Commands for the same device never overlap. The result is
+// never nil. Non-zero exits with a known status are returned as
+// *SmartctlError. JSON output with an unsupported json_format_version fails
+// with ErrUnsupportedJSONFormat in strict mode.
+func (b *ExecBackend) run(ctx context.Context, args ...string) (*CommandResult, error) {
+	args = b.toleranceArgs(ctx, b.deviceExtraArgs(args))
+	res, err := b.runWithOpenRetry(ctx, args)
+	b.recordNoCheck(args, res, err)
+	return res, err
+}
+
+func (b *ExecBackend) runCommand(ctx context.Context, args []string) (*CommandResult, error) {
+	if err := b.waitDeviceRate(ctx, args); err != nil {
+		return &CommandResult{ExitCode: -1}, err
+	}
+	unlock, err := b.lockDevice(ctx, args)
+	if err != nil {
+		return &CommandResult{ExitCode: -1}, err
+	}
+	req, tool := b.smartctlRequest(ctx, args)
+	start := time.Now()
+	res, err := b.executor().CommandContext(ctx, b.logHandler, req)
+	duration := time.Since(start)
+	unlock()
+	if killedErr := killedError(ctx, req, res, err); killedErr != nil {
+		if errors.Is(killedErr, ErrCommandTimeout) {
+			b.logHandler.WarnContext(ctx, "smartctl killed after command timeout", "args", args, "timeout", req.Timeout)
+		}
+		err = killedErr
+	} else {
+		err = b.classifyResult(ctx, tool, args, res, err)
+	}
+	b.notifyCommandHooks(ctx, args, start, duration, res, err)
+	return res, err
+}
+
+// classifyResult turns the error of a finished invocation into the error
+// callers see: elevation failures, unsupported JSON formats, *SmartctlError
+// and the open failure kinds.
+func (b *ExecBackend) classifyResult(ctx context.Context, tool string, args []string, res *CommandResult, err error) error {
+	err = checkElevation(tool, err, res)
+	if formatErr := b.checkJSONFormat(ctx, args, res.Stdout); formatErr != nil {
+		return formatErr
+	}
+	if _, ok := asExitError(err); !ok {
+		return err
+	}
+	if res.ExitCode > 0 {
+		err = &SmartctlError{Status: ExitStatus(res.ExitCode), Stderr: res.Stderr, Err: err}
+	}
+	if kind := classifyOpenFailure(string(res.Stdout) + string(res.Stderr)); kind != nil {
+		err = fmt.Errorf("%w: %w", kind, err)
+	}
+	return err
+}
+
+// isOpenFailure reports whether err was classified as a permission, missing
+// or busy device failure, which smartctl also signals with exit bit 1 (the
+// standby bit).
+func isOpenFailure(err error) bool {
+	return errors.Is(err, ErrPermissionDenied) || errors.Is(err, ErrDeviceNotFound) || errors.Is(err, ErrDeviceBusy)
+}
+
+// asExitError finds the *exec.ExitError of a failed run, which may be wrapped
+// in a SmartctlError. Runs killed by a signal, such as on a timeout or
+// cancellation, have no exit status to decode and are not reported.
+func asExitError(err error) (*exec.ExitError, bool) {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return nil, false
+	}
+	if exitErr.ProcessState != nil && exitErr.ExitCode() < 0 {
+		return nil, false
+	}
+	return exitErr, true
+}
+
+// exitCodeOf returns the exit status of a failed invocation: the one in res,
+// which commanders replaying recorded output set without a process behind
+// exitErr, or else the one of exitErr.
+func exitCodeOf(res *CommandResult, exitErr *exec.ExitError) int {
+	if res != nil && res.ExitCode > 0 {
+		return res.ExitCode
+	}
+	return exitErr.ExitCode()
+}
+
+// runTool executes a platform helper such as diskutil or camcontrol. Helpers
+// never need elevation to enumerate devices.
+func (b *ExecBackend) runTool(ctx context.Context, name string, args ...string) (*CommandResult, error) {
+	req := CommandRequest{Name: name, Args: args, Env: b.env(), Timeout: b.timeout(ctx)}
+	res, err := b.executor().CommandContext(ctx, b.logHandler, req)
+	if killedErr := killedError(ctx, req, res, err); killedErr != nil {
+		return res, killedErr
+	}
+	return res, err
+}
+
+// timeout returns the command timeout for calls made with ctx: the one set
+// with ContextWithCommandTimeout, or else the backend's.
+func (b *ExecBackend) timeout(ctx context.Context) time.Duration {
+	if timeout, ok := CommandTimeoutFromContext(ctx); ok {
+		return max(timeout, 0)
+	}
+	return b.commandTimeout
+}
+
+// killedError returns the error of an invocation of req that was killed: a
+// *CommandTimeoutError when its own timeout fired, a *CommandCanceledError
+// when ctx ended, both with the output res collected until then. It returns
+// nil for invocations that ran to completion.
+func killedError(ctx context.Context, req CommandRequest, res *CommandResult, err error) error {
+	if err == nil {
+		return nil
+	}
+	var stdout, stderr []byte
+	if res != nil {
+		stdout, stderr = res.Stdout, res.Stderr
+	}
+	switch {
+	case ctx.Err() != nil:
+		return &CommandCanceledError{Name: req.Name, Args: req.Args, Stdout: stdout, Stderr: stderr, Err: err}
+	case req.Timeout > 0 && errors.Is(err, context.DeadlineExceeded):
+		return &CommandTimeoutError{Name: req.Name, Args: req.Args, Timeout: req.Timeout, Stdout: stdout, Stderr: stderr, Err: err}
+	}
+	return nil
+}
+
+// env returns the environment entries added to every command: the locale,
+// then the WithCommandEnv entries, which override it since the last entry
+// for a key wins.
+func (b *ExecBackend) env() []string {
+	if b.locale == "" {
+		return b.commandEnv
+	}
+	return append([]string{"LC_ALL=" + b.locale, "LANG=" + b.locale}, b.commandEnv...)
+}
+
+// executor returns the ContextCommander used to run commands.
+func (b *ExecBackend) executor() ContextCommander {
+	if b.runner != nil {
+		return b.runner
+	}
+	return asContextCommander(b.commander)
 }
 
 // logSmartctlMessages logs messages from a smartctl response, deduplicating via
@@ -543,83 +1008,86 @@ func (b *ExecBackend) logSmartctlMessages(ctx context.Context, info *SMARTInfo)
 		if !globalMessageCache.shouldLog(msg.String, severity) {
 			continue
 		}
+		attrs := []any{"devicePath", info.Device.Name, "code", msg.Code()}
 		switch severity {
 		case "error":
-			b.logHandler.ErrorContext(ctx, msg.String)
+			b.logHandler.ErrorContext(ctx, msg.String, attrs...)
 		case "warning":
-			b.logHandler.WarnContext(ctx, msg.String)
+			b.logHandler.WarnContext(ctx, msg.String, attrs...)
 		default:
-			b.logHandler.InfoContext(ctx, msg.String)
+			b.logHandler.InfoContext(ctx, msg.String, attrs...)
 		}
 	}
 }
 
-// retryWithDeviceType retries the SMART query for devicePath using an explicit
-// -d <deviceType> flag and --nocheck=standby. It is the common implementation
-// behind both the execution-failure SAT-probe path and the USB bridge
-// protocol-selection path.
+// retryWithDeviceType retries the SMART query (flag is "-a" or "-x") for
+// devicePath using an explicit -d <deviceType> flag and the --nocheck policy.
+// It is the common implementation behind both the execution-failure SAT-probe
+// path and the USB bridge protocol-selection path.
 //
 // On success the device type is written to the device type cache so subsequent
 // calls use buildArgs directly without re-probing.
 //
-// Returns (info, true) when the attempt produces a usable result (including
-// standby). Returns (nil, false) when the device cannot be opened with this
+// Returns (info, output, true) when the attempt produces a usable result
+// (including standby; output is nil when smartctl printed nothing). Returns
+// (nil, nil, false) when the device cannot be opened with this
 // type, the output cannot be parsed, or the response has an empty device name
 // indicating the protocol did not produce valid SMART data.
-func (b *ExecBackend) retryWithDeviceType(ctx context.Context, devicePath, deviceType string) (*SMARTInfo, bool) {
-	args := []string{"-a", "-j", "--nocheck=standby", "-d", deviceType, devicePath}
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, args...)
-	output, err := cmd.Output()
+func (b *ExecBackend) retryWithDeviceType(ctx context.Context, devicePath, deviceType, flag string) (*SMARTInfo, []byte, bool) {
+	ctx = withFallback(ctx, FallbackDeviceType)
+	args := append([]string{flag, "-j", b.noCheckArg(ctx, devicePath), "-d", deviceType}, b.presetArgs(devicePath)...)
+	args = append(args, devicePath)
+	res, err := b.run(ctx, args...)
+	output := res.Stdout
 
 	if err != nil {
-		exitErr, isExit := err.(*exec.ExitError)
+		exitErr, isExit := asExitError(err)
 		if !isExit {
-			return nil, false
+			return nil, nil, false
 		}
-		code := exitErr.ExitCode()
+		code := exitCodeOf(res, exitErr)
 		// Execution failure bits 0 or 2: device still cannot be read with this type.
 		if code&0x05 != 0 {
-			return nil, false
+			return nil, nil, false
 		}
 		// Bit 1: device is in standby but responds to this protocol — cache the
 		// type so future buildArgs invocations use the correct -d flag.
-		if code&0x02 != 0 {
+		if code&0x02 != 0 && !isOpenFailure(err) {
 			b.setCachedDeviceType(devicePath, deviceType)
 			if len(output) > 0 {
 				var info SMARTInfo
 				if json.Unmarshal(output, &info) == nil {
 					info.InStandby = true
-					info.DiskType = determineDiskType(&info)
-					info.SmartStatus = checkSmartStatus(&info)
-					return &info, true
+					finishSMARTInfo(&info)
+					return &info, output, true
 				}
 			}
 			return &SMARTInfo{
 				Device:       Device{Name: devicePath, Type: deviceType},
 				InStandby:    true,
 				SmartSupport: &SmartSupport{Available: true, Enabled: true},
-			}, true
+			}, nil, true
 		}
 	}
 
 	if len(output) == 0 {
-		return nil, false
+		return nil, nil, false
 	}
 	var info SMARTInfo
 	if jsonErr := json.Unmarshal(output, &info); jsonErr != nil {
-		return nil, false
+		return nil, nil, false
 	}
 	// An empty device name indicates the protocol couldn't read SMART data.
 	if info.Device.Name == "" {
-		return nil, false
+		return nil, nil, false
 	}
 	b.setCachedDeviceType(devicePath, deviceType)
 	b.logHandler.InfoContext(ctx, "Device type retry succeeded", "devicePath", devicePath, "deviceType", deviceType)
-	info.DiskType = determineDiskType(&info)
-	info.SmartStatus = checkSmartStatus(&info)
+	b.learnDrivedbPresets(ctx, devicePath, &info)
+	finishSMARTInfo(&info)
 	b.logHealthBits(ctx, devicePath, &info)
 	b.logSmartctlMessages(ctx, &info)
-	return &info, true
+	return &info, output, true
 }
 
 // retrySATFallback is called when the initial smartctl query failed with
@@ -630,45 +1098,74 @@ func (b *ExecBackend) retryWithDeviceType(ctx context.Context, devicePath, devic
 // On success the "sat" protocol is written to the device type cache so that
 // all subsequent calls use it directly without re-probing.
 //
-// Returns (info, true) when the SAT attempt produces a usable result
-// (including standby). Returns (nil, false) when the SAT attempt also fails
+// Returns (info, output, true) when the SAT attempt produces a usable result
+// (including standby). Returns (nil, nil, false) when the SAT attempt also fails
 // with execution failure bits or produces unparseable output.
-func (b *ExecBackend) retrySATFallback(ctx context.Context, devicePath string) (*SMARTInfo, bool) {
+func (b *ExecBackend) retrySATFallback(ctx context.Context, devicePath, flag string) (*SMARTInfo, []byte, bool) {
 	b.logHandler.InfoContext(ctx, "execution failure with default protocol, retrying with -d sat", "devicePath", devicePath)
-	return b.retryWithDeviceType(ctx, devicePath, "sat")
+	return b.retryWithDeviceType(ctx, devicePath, "sat", flag)
 }
 
-// getSMARTInfoInternal is the implementation behind GetSMARTInfo. The second
-// return value is true when the internal SAT fallback (retrySATFallback) was
+// getSMARTInfoInternal is the implementation behind GetSMARTInfo and
+// GetSMARTInfoRaw. It also returns the smartctl JSON the result was parsed
+// from (nil for synthesized standby placeholders). The third return value is
+// true when the internal SAT fallback (retrySATFallback) was
 // invoked and succeeded, allowing DiscoverDevices to surface SATFallbackRequired
 // without changing the public GetSMARTInfo signature.
-func (b *ExecBackend) getSMARTInfoInternal(ctx context.Context, devicePath string) (*SMARTInfo, bool, error) {
+func (b *ExecBackend) getSMARTInfoInternal(ctx context.Context, devicePath string) (*SMARTInfo, []byte, bool, error) {
+	return b.readSMARTInfo(ctx, devicePath, b.smartInfoFlag())
+}
+
+// readSMARTInfo runs smartctl with flag ("-a" or "-x") and -j for devicePath,
+// with the standby, SAT fallback and USB bridge handling of GetSMARTInfo.
+func (b *ExecBackend) readSMARTInfo(ctx context.Context, devicePath, flag string) (*SMARTInfo, []byte, bool, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	cmd := b.commander.Command(ctx, b.logHandler, b.smartctlPath, b.buildArgs(devicePath, "-a", "-j")...)
-	output, err := cmd.Output()
+	if err := b.virtualDevice(devicePath); err != nil {
+		return nil, nil, false, err
+	}
+	if b.textOutput {
+		return b.getSMARTInfoText(ctx, devicePath)
+	}
+	res, err := b.run(ctx, b.buildArgs(ctx, devicePath, flag, "-j")...)
+	output := res.Stdout
 	if err != nil {
+		// Virtual LUNs fail every SMART command; probing them with other
+		// device types only adds noise.
+		var smartInfo SMARTInfo
+		parsed := json.Unmarshal(output, &smartInfo) == nil
+		if parsed {
+			if vErr := infoVirtualDevice(devicePath, &smartInfo); vErr != nil {
+				return &smartInfo, output, false, vErr
+			}
+		}
+		if vErr := b.suspectedVirtual(devicePath); vErr != nil && (!parsed || !hasSMARTData(&smartInfo)) {
+			return nil, nil, false, vErr
+		}
+
 		// smartctl returns non-zero exit codes for various conditions
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode := exitErr.ExitCode()
+		if exitErr, ok := asExitError(err); ok {
+			exitCode := exitCodeOf(res, exitErr)
 
 			// Bits 0, 2 (mask 0x05): execution failures — retry with -d sat on
 			// first contact. Handles Synology /dev/sata* paths, USB bridges, and
 			// RAID passthrough devices that fail with the auto-detected protocol.
-			// Bit 1 (standby) is excluded: --nocheck=standby is always passed, so
-			// bit 1 means the device is in standby mode, not a protocol mismatch.
+			// NVMe devices are never probed: they do not speak SAT.
+			// Bit 1 (standby) is excluded: the --nocheck policy is always passed,
+			// so bit 1 means the device is in standby mode, not a protocol mismatch.
 			// The standby check below handles it without triggering a SAT probe.
-			if exitCode&0x05 != 0 {
+			if exitCode&0x05 != 0 && !isNVMePath(devicePath) {
 				if _, hasCached := b.getCachedDeviceType(devicePath); !hasCached {
-					if info, satOK := b.retrySATFallback(ctx, devicePath); satOK {
-						return info, true, nil
+					if info, raw, satOK := b.retrySATFallback(ctx, devicePath, flag); satOK {
+						return info, raw, true, nil
 					}
 				}
 			}
 
-			// Bit 1 (value 2): Device is in standby/sleep mode
-			if exitCode&2 != 0 {
+			// Bit 1 (value 2): Device is in standby/sleep mode, unless smartctl
+			// reported that the device could not be opened at all.
+			if exitCode&2 != 0 && !isOpenFailure(err) {
 				// Parse partial output if available
 				if len(output) > 0 {
 					var smartInfo SMARTInfo
@@ -683,16 +1180,21 @@ func (b *ExecBackend) getSMARTInfoInternal(ctx context.Context, devicePath strin
 								b.setCachedDeviceType(devicePath, smartInfo.Device.Type)
 							}
 						}
-						smartInfo.DiskType = determineDiskType(&smartInfo)
-						smartInfo.SmartStatus = checkSmartStatus(&smartInfo)
-						return &smartInfo, false, nil
+						finishSMARTInfo(&smartInfo)
+						return &smartInfo, output, false, nil
 					}
 				}
 				// If parsing fails, return a minimal SMARTInfo indicating standby
-				return &SMARTInfo{InStandby: true}, false, nil
+				return &SMARTInfo{InStandby: true}, nil, false, nil
 			}
 		}
 
+		// Partial output of a device that could not be opened carries no SMART
+		// data, and output rejected by strict format checking must not be parsed.
+		if isOpenFailure(err) || errors.Is(err, ErrUnsupportedJSONFormat) {
+			return nil, nil, false, fmt.Errorf("failed to get SMART info: %w", err)
+		}
+
 		// We still want to parse the output if available and it's valid JSON
 		if len(output) > 0 {
 			var smartInfo SMARTInfo
@@ -721,37 +1223,39 @@ func (b *ExecBackend) getSMARTInfoInternal(ctx context.Context, devicePath strin
 						if deviceType == "sat" {
 							b.logHandler.InfoContext(ctx, "Unknown USB bridge detected, retrying with -d sat", "devicePath", devicePath)
 						}
-						if info, ok := b.retryWithDeviceType(ctx, devicePath, deviceType); ok {
-							return info, false, nil
+						if info, raw, ok := b.retryWithDeviceType(ctx, devicePath, deviceType, flag); ok {
+							return info, raw, false, nil
 						}
 						b.logHandler.ErrorContext(ctx, "Retry with device type failed", "devicePath", devicePath, "deviceType", deviceType)
 					}
 				}
 
-				smartInfo.DiskType = determineDiskType(&smartInfo)
-				smartInfo.SmartStatus = checkSmartStatus(&smartInfo)
+				finishSMARTInfo(&smartInfo)
 				// If device name is empty after USB bridge fallback, SMART is likely not supported
 				if smartInfo.Device.Name == "" {
-					return &smartInfo, false, fmt.Errorf("SMART Not Supported")
+					return &smartInfo, output, false, ErrSmartNotSupported
 				}
-				return &smartInfo, false, nil
+				return &smartInfo, output, false, nil
 			}
 		}
-		return nil, false, fmt.Errorf("failed to get SMART info: %w", err)
+		return nil, nil, false, fmt.Errorf("failed to get SMART info: %w", err)
 	}
 
 	var smartInfo SMARTInfo
 	if err := json.Unmarshal(output, &smartInfo); err != nil {
-		return nil, false, fmt.Errorf("failed to parse SMART info: %w", err)
+		return nil, nil, false, fmt.Errorf("failed to parse SMART info: %w", err)
 	}
 
+	if err := infoVirtualDevice(devicePath, &smartInfo); err != nil {
+		return &smartInfo, output, false, err
+	}
 	b.logSmartctlMessages(ctx, &smartInfo)
+	b.recordDriveDatabaseVersion(smartInfo.Smartctl)
 
-	// Determine disk type based on rotation rate and device type
-	smartInfo.DiskType = determineDiskType(&smartInfo)
-	// Populate SmartStatus.Running field based on test status
-	smartInfo.SmartStatus = checkSmartStatus(&smartInfo)
+	// Determine disk type, SmartStatus.Running and Warnings
+	finishSMARTInfo(&smartInfo)
 	b.logHealthBits(ctx, devicePath, &smartInfo)
+	b.learnDrivedbPresets(ctx, devicePath, &smartInfo)
 
 	// Cache the device type from the successful response so all subsequent
 	// methods can use --nocheck=standby and the correct -d <type> argument
@@ -762,7 +1266,7 @@ func (b *ExecBackend) getSMARTInfoInternal(ctx context.Context, devicePath strin
 		}
 	}
 
-	return &smartInfo, false, nil
+	return &smartInfo, output, false, nil
 }
 
 // logHealthBits emits a single WARNING per device per unique health-bit pattern.
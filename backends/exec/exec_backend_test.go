@@ -2,6 +2,7 @@ package exec
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,6 +33,73 @@ func TestNewExecBackend_WithCommander(t *testing.T) {
 	assert.Equal(t, "/usr/sbin/smartctl", backend.smartctlPath)
 }
 
+func TestExecBackend_ScanDevicesNotOpened(t *testing.T) {
+	for name, scanOpen := range map[string]*mockCmd{
+		"failed": {err: errors.New("permission denied")},
+		"empty":  {output: []byte(`{"devices":[]}`)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+				"/usr/sbin/smartctl --scan-open --json": scanOpen,
+				"/usr/sbin/smartctl --scan --json":      {output: []byte(`{"devices":[{"name":"/dev/sda","type":"scsi"}]}`)},
+			}}))
+			require.NoError(t, err)
+
+			devices, err := b.ScanDevices(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, []Device{{Name: "/dev/sda", Type: "scsi", NotOpened: true}}, devices)
+			// "scsi" is only smartctl's guess from the name; it must not
+			// override probing.
+			_, cached := b.getCachedDeviceType("/dev/sda")
+			assert.False(t, cached)
+		})
+	}
+}
+
+func TestExecBackend_ScanDevicesNotOpenedText(t *testing.T) {
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithTextOutput(true), WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --scan-open": {output: []byte("")},
+		"/usr/sbin/smartctl --scan":      {output: []byte("/dev/sda -d scsi # /dev/sda, SCSI device\n")},
+	}}))
+	require.NoError(t, err)
+
+	devices, err := b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Device{{Name: "/dev/sda", Type: "scsi", InfoName: "/dev/sda", Protocol: "SCSI", NotOpened: true}}, devices)
+}
+
+func TestExecBackend_ScanDevicesDetails(t *testing.T) {
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --scan-open --json": {output: []byte(`{"devices":[
+			{"name":"/dev/sda","info_name":"/dev/sda [SAT]","type":"sat","protocol":"ATA"},
+			{"name":"/dev/sdb","info_name":"/dev/sdb","type":"scsi","protocol":"SCSI","open_error":"Permission denied"}
+		]}`)},
+	}}))
+	require.NoError(t, err)
+
+	devices, err := b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Device{
+		{Name: "/dev/sda", Type: "sat", InfoName: "/dev/sda [SAT]", Protocol: "ATA"},
+		{Name: "/dev/sdb", Type: "scsi", InfoName: "/dev/sdb", Protocol: "SCSI", OpenError: "Permission denied"},
+	}, devices)
+	_, cached := b.getCachedDeviceType("/dev/sda")
+	assert.True(t, cached)
+	_, cached = b.getCachedDeviceType("/dev/sdb")
+	assert.False(t, cached, "types of devices that failed to open are not cached")
+}
+
+func TestExecBackend_ScanDevicesMergesNVMeNamespaces(t *testing.T) {
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --scan-open --json": {output: []byte(`{"devices":[{"name":"/dev/nvme0","type":"nvme"},{"name":"/dev/nvme0n1","type":"nvme"}]}`)},
+	}}))
+	require.NoError(t, err)
+
+	devices, err := b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Device{{Name: "/dev/nvme0", Type: "nvme", Controller: "/dev/nvme0", Namespaces: []string{"/dev/nvme0n1"}}}, devices)
+}
+
 func TestExecBackend_DiscoverDevices(t *testing.T) {
 	scanJSON := `{
 "devices": [
@@ -0,0 +1,40 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// GetExtendedSMARTInfo retrieves the extended SMART information of "smartctl
+// -x", which adds SCT status, SATA Phy event counters, device statistics and
+// the log directory to the GetSMARTInfo data. It shares the invocation with
+// GetSMARTInfo when WithSharedQueries is enabled. In text output mode the
+// extended sections are not parsed and stay nil.
+func (b *ExecBackend) GetExtendedSMARTInfo(ctx context.Context, devicePath string) (*ExtendedSMARTInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	var (
+		info *SMARTInfo
+		raw  []byte
+		err  error
+	)
+	if b.sharing() {
+		info, raw, _, err = b.querySMARTInfo(ctx, devicePath)
+	} else {
+		info, raw, _, err = b.readSMARTInfo(ctx, devicePath, "-x")
+	}
+	if info == nil {
+		return nil, err
+	}
+	if b.textOutput {
+		raw = nil
+	}
+	ext, parseErr := newExtendedSMARTInfo(info, raw)
+	if parseErr != nil {
+		return ext, errors.Join(err, fmt.Errorf("failed to parse extended SMART info: %w", parseErr))
+	}
+	return ext, err
+}
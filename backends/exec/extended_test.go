@@ -0,0 +1,79 @@
+package exec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const extendedJSON = `{
+	"device": {"name": "/dev/sda", "type": "sat"},
+	"model_name": "Extended Disk",
+	"smart_status": {"passed": true},
+	"ata_sct_status": {
+		"format_version": 3,
+		"sct_version": 522,
+		"device_state": {"value": 0, "string": "Active"},
+		"temperature": {"current": 31, "power_cycle_min": 24, "power_cycle_max": 33, "lifetime_min": 18, "lifetime_max": 52, "op_limit_max": 70}
+	},
+	"sata_phy_event_counters": {
+		"table": [{"id": 1, "name": "Command failed due to ICRC error", "size": 16, "value": 3, "overflow": false}],
+		"reset": false
+	},
+	"ata_device_statistics": {
+		"pages": [{"number": 1, "name": "General Statistics", "revision": 1, "table": [
+			{"offset": 8, "name": "Lifetime Power-On Resets", "size": 4, "value": 43, "flags": {"value": 192, "string": "CN---- ", "valid": true, "normalized": false}}
+		]}]
+	},
+	"ata_log_directory": {
+		"gp_dir_version": 1,
+		"smart_dir_version": 1,
+		"smart_dir_multi_sector": true,
+		"table": [{"address": 4, "name": "Device Statistics", "read": true, "write": false, "gp_sectors": 8, "smart_sectors": 8}]
+	},
+	"ata_sct_temperature_history": {"version": 2}
+}`
+
+func TestGetExtendedSMARTInfo(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -x -j --nocheck=standby /dev/sda": {output: []byte(extendedJSON)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	info, err := b.GetExtendedSMARTInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "Extended Disk", info.ModelName)
+	assert.Equal(t, "sat", info.Device.Type)
+
+	require.NotNil(t, info.AtaSctStatus)
+	assert.Equal(t, "Active", info.AtaSctStatus.DeviceState.String)
+	assert.Equal(t, 52, info.AtaSctStatus.Temperature.LifetimeMax)
+	require.NotNil(t, info.SataPhyEventCounters)
+	assert.Equal(t, int64(3), info.SataPhyEventCounters.Table[0].Value)
+	require.NotNil(t, info.AtaDeviceStatistics)
+	assert.Equal(t, "Lifetime Power-On Resets", info.AtaDeviceStatistics.Pages[0].Table[0].Name)
+	assert.True(t, info.AtaDeviceStatistics.Pages[0].Table[0].Flags.Valid)
+	require.NotNil(t, info.AtaLogDirectory)
+	assert.Equal(t, 8, info.AtaLogDirectory.Table[0].GpSectors)
+
+	// Modeled extended sections are not duplicated in Extra.
+	assert.Contains(t, info.Extra, "ata_sct_temperature_history")
+	assert.NotContains(t, info.Extra, "ata_sct_status")
+}
+
+func TestGetExtendedSMARTInfo_SharesInvocation(t *testing.T) {
+	cc := &sharedCommander{}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(cc), WithSharedQueries(time.Minute))
+	require.NoError(t, err)
+
+	_, err = b.GetSMARTInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	info, err := b.GetExtendedSMARTInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "Shared Disk", info.ModelName)
+	assert.Equal(t, int32(1), cc.xRuns.Load())
+}
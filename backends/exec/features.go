@@ -0,0 +1,215 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// driveFeature describes a setting read with "smartctl -g NAME" and changed
+// with "smartctl -s NAME,on|off".
+type driveFeature struct {
+	name  string
+	desc  string
+	state *regexp.Regexp
+}
+
+var (
+	// "Write cache is:   Enabled" (ATA) or "Writeback Cache is:  Disabled" (SCSI).
+	featureWriteCache = driveFeature{
+		name:  "wcache",
+		desc:  "write cache",
+		state: regexp.MustCompile(`(?mi)^(?:Write cache|Writeback Cache) is:\s*(.+?)\s*$`),
+	}
+	// "Wt Cache Reorder: Enabled", ATA only.
+	featureWriteCacheReorder = driveFeature{
+		name:  "wcreorder",
+		desc:  "write cache reordering",
+		state: regexp.MustCompile(`(?m)^Wt Cache Reorder:\s*(.+?)\s*$`),
+	}
+	// "DSN feature is:   Disabled", ATA only.
+	featureDSN = driveFeature{
+		name:  "dsn",
+		desc:  "DSN feature",
+		state: regexp.MustCompile(`(?m)^DSN feature is:\s*(.+?)\s*$`),
+	}
+)
+
+// GetWriteCache reports whether the volatile write cache of devicePath is
+// enabled ("smartctl -g wcache"). Devices that cannot report it fail with
+// ErrFeatureNotSupported.
+func (b *ExecBackend) GetWriteCache(ctx context.Context, devicePath string) (bool, error) {
+	return b.getFeature(ctx, devicePath, featureWriteCache)
+}
+
+// SetWriteCache enables or disables the volatile write cache of devicePath
+// ("smartctl -s wcache,on|off").
+func (b *ExecBackend) SetWriteCache(ctx context.Context, devicePath string, enabled bool) error {
+	return b.setFeature(ctx, devicePath, featureWriteCache, enabled)
+}
+
+// GetWriteCacheReorder reports whether an ATA drive may reorder writes in its
+// cache ("smartctl -g wcreorder"). Devices that cannot report it fail with
+// ErrFeatureNotSupported.
+func (b *ExecBackend) GetWriteCacheReorder(ctx context.Context, devicePath string) (bool, error) {
+	return b.getFeature(ctx, devicePath, featureWriteCacheReorder)
+}
+
+// SetWriteCacheReorder enables or disables write cache reordering on an ATA
+// drive ("smartctl -s wcreorder,on|off").
+func (b *ExecBackend) SetWriteCacheReorder(ctx context.Context, devicePath string, enabled bool) error {
+	return b.setFeature(ctx, devicePath, featureWriteCacheReorder, enabled)
+}
+
+// GetDSN reports whether Device Statistics Notification is enabled on an ATA
+// drive ("smartctl -g dsn"). Drives without DSN fail with
+// ErrFeatureNotSupported.
+func (b *ExecBackend) GetDSN(ctx context.Context, devicePath string) (bool, error) {
+	return b.getFeature(ctx, devicePath, featureDSN)
+}
+
+// SetDSN enables or disables Device Statistics Notification on an ATA drive
+// ("smartctl -s dsn,on|off").
+func (b *ExecBackend) SetDSN(ctx context.Context, devicePath string, enabled bool) error {
+	return b.setFeature(ctx, devicePath, featureDSN, enabled)
+}
+
+// EnableAttributeAutosave lets an ATA drive save its SMART attributes across
+// power cycles on its own ("smartctl -S on").
+func (b *ExecBackend) EnableAttributeAutosave(ctx context.Context, devicePath string) error {
+	return b.toggleFeature(ctx, devicePath, "-S", "attribute autosave", true)
+}
+
+// DisableAttributeAutosave turns off SMART attribute autosave on an ATA drive
+// ("smartctl -S off").
+func (b *ExecBackend) DisableAttributeAutosave(ctx context.Context, devicePath string) error {
+	return b.toggleFeature(ctx, devicePath, "-S", "attribute autosave", false)
+}
+
+// EnableAutoOfflineCollection makes an ATA drive run its offline data
+// collection every four hours ("smartctl -o on").
+func (b *ExecBackend) EnableAutoOfflineCollection(ctx context.Context, devicePath string) error {
+	return b.toggleFeature(ctx, devicePath, "-o", "automatic offline data collection", true)
+}
+
+// DisableAutoOfflineCollection stops automatic offline data collection on an
+// ATA drive ("smartctl -o off").
+func (b *ExecBackend) DisableAutoOfflineCollection(ctx context.Context, devicePath string) error {
+	return b.toggleFeature(ctx, devicePath, "-o", "automatic offline data collection", false)
+}
+
+// SecurityFreeze issues ATA SECURITY FREEZE LOCK ("smartctl -s
+// security-freeze"), so the drive rejects security commands such as setting a
+// password or erasing until its next power cycle.
+func (b *ExecBackend) SecurityFreeze(ctx context.Context, devicePath string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	defer b.forgetSharedQuery(devicePath)
+	if _, err := b.run(ctx, b.featureArgs(devicePath, "-s", "security-freeze")...); err != nil {
+		return fmt.Errorf("failed to freeze ATA security: %w", err)
+	}
+	return nil
+}
+
+// identifyWordPattern matches the word rows of "smartctl --identify=wn", e.g.
+// "  89      -      0x8012  Time required for Normal Erase mode".
+var identifyWordPattern = regexp.MustCompile(`(?m)^\s*(\d{1,3})\s+-\s+0x([0-9a-fA-F]{4})\b`)
+
+// GetSecureEraseInfo reads the ATA security state and secure-erase time
+// estimates of devicePath from its IDENTIFY DEVICE data ("smartctl
+// --identify=wn"). Devices without ATA identify data, such as NVMe drives,
+// fail with ErrFeatureNotSupported.
+func (b *ExecBackend) GetSecureEraseInfo(ctx context.Context, devicePath string) (*SecureEraseInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	args := []string{"--identify=wn"}
+	if cachedType, ok := b.getCachedDeviceType(devicePath); ok {
+		args = append(args, "-d", cachedType)
+	}
+	res, err := b.run(ctx, append(args, devicePath)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identify data: %w", err)
+	}
+	words := make(map[int]uint16)
+	for _, m := range identifyWordPattern.FindAllStringSubmatch(string(res.Stdout), -1) {
+		word, _ := strconv.Atoi(m[1])
+		value, _ := strconv.ParseUint(m[2], 16, 16)
+		words[word] = uint16(value)
+	}
+	if _, ok := words[128]; !ok {
+		return nil, fmt.Errorf("%w: %s reports no ATA security data", ErrFeatureNotSupported, devicePath)
+	}
+	return newSecureEraseInfo(words[89], words[90], words[128]), nil
+}
+
+func (b *ExecBackend) getFeature(ctx context.Context, devicePath string, f driveFeature) (bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	res, err := b.run(ctx, b.featureArgs(devicePath, "-g", f.name)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s: %w", f.desc, err)
+	}
+	m := f.state.FindStringSubmatch(string(res.Stdout))
+	if m == nil {
+		return false, fmt.Errorf("%w: %s state not reported for %s", ErrFeatureNotSupported, f.desc, devicePath)
+	}
+	switch state := strings.ToLower(m[1]); {
+	case strings.HasPrefix(state, "enabled"):
+		return true, nil
+	case strings.HasPrefix(state, "disabled"):
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: %s is %s on %s", ErrFeatureNotSupported, f.desc, m[1], devicePath)
+}
+
+func (b *ExecBackend) setFeature(ctx context.Context, devicePath string, f driveFeature, enabled bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	defer b.forgetSharedQuery(devicePath)
+	value := f.name + ",off"
+	if enabled {
+		value = f.name + ",on"
+	}
+	if _, err := b.run(ctx, b.featureArgs(devicePath, "-s", value)...); err != nil {
+		return fmt.Errorf("failed to set %s: %w", f.desc, err)
+	}
+	return nil
+}
+
+// toggleFeature runs "smartctl flag on|off" for settings that have their own
+// command line option instead of a -s NAME.
+func (b *ExecBackend) toggleFeature(ctx context.Context, devicePath, flag, desc string, enabled bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	defer b.forgetSharedQuery(devicePath)
+	value, action := "off", "disable"
+	if enabled {
+		value, action = "on", "enable"
+	}
+	if _, err := b.run(ctx, b.featureArgs(devicePath, flag, value)...); err != nil {
+		return fmt.Errorf("failed to %s %s: %w", action, desc, err)
+	}
+	return nil
+}
+
+// featureArgs builds "flag value [-d type] devicePath". The power mode check
+// is left out: reading or changing a setting is an explicit request.
+func (b *ExecBackend) featureArgs(devicePath, flag, value string) []string {
+	args := []string{flag, value}
+	if cachedType, ok := b.getCachedDeviceType(devicePath); ok {
+		args = append(args, "-d", cachedType)
+	}
+	return append(args, devicePath)
+}
@@ -0,0 +1,185 @@
+package exec
+
+import (
+	"context"
+	osexec "os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWriteCache(t *testing.T) {
+	const banner = "smartctl 7.3 2022-02-28 r5338 [x86_64-linux-6.1.0] (local build)\n\n=== START OF READ SMART DATA SECTION ===\n"
+	tests := []struct {
+		name    string
+		output  string
+		want    bool
+		wantErr error
+	}{
+		{"ata enabled", banner + "Write cache is:   Enabled\n", true, nil},
+		{"ata disabled", banner + "Write cache is:   Disabled\n", false, nil},
+		{"scsi enabled", banner + "Writeback Cache is:  Enabled\n", true, nil},
+		{"unavailable", banner + "Write cache is:   Unavailable\n", false, ErrFeatureNotSupported},
+		{"not reported", banner, false, ErrFeatureNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockCommander{cmds: map[string]*mockCmd{
+				"/usr/sbin/smartctl -g wcache /dev/sda": {output: []byte(tt.output)},
+			}}
+			b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+			require.NoError(t, err)
+
+			enabled, err := b.GetWriteCache(context.Background(), "/dev/sda")
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, enabled)
+		})
+	}
+}
+
+func TestGetWriteCacheReorder_UsesCachedType(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -g wcreorder -d sat /dev/sdb": {output: []byte("Wt Cache Reorder: Enabled\n")},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+	b.setCachedDeviceType("/dev/sdb", "sat")
+
+	enabled, err := b.GetWriteCacheReorder(context.Background(), "/dev/sdb")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+}
+
+func TestSetWriteCache(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc))
+	require.NoError(t, err)
+
+	require.NoError(t, b.SetWriteCache(context.Background(), "/dev/sda", false))
+	require.NoError(t, b.SetWriteCacheReorder(context.Background(), "/dev/sda", true))
+	require.Len(t, rc.requests, 2)
+	assert.Equal(t, []string{"-s", "wcache,off", "/dev/sda"}, rc.requests[0].Args)
+	assert.Equal(t, []string{"-s", "wcreorder,on", "/dev/sda"}, rc.requests[1].Args)
+}
+
+func TestDSN(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -g dsn /dev/sda":    {output: []byte("DSN feature is:   Disabled\n")},
+		"/usr/sbin/smartctl -g dsn /dev/sdb":    {output: []byte("DSN feature is:   Unavailable\n")},
+		"/usr/sbin/smartctl -s dsn,on /dev/sda": {output: []byte("DSN enabled\n")},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	enabled, err := b.GetDSN(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	require.NoError(t, b.SetDSN(context.Background(), "/dev/sda", true))
+
+	_, err = b.GetDSN(context.Background(), "/dev/sdb")
+	assert.ErrorIs(t, err, ErrFeatureNotSupported)
+}
+
+func TestAttributeAutosave(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc))
+	require.NoError(t, err)
+	b.setCachedDeviceType("/dev/sdb", "sat")
+
+	require.NoError(t, b.EnableAttributeAutosave(context.Background(), "/dev/sda"))
+	require.NoError(t, b.DisableAttributeAutosave(context.Background(), "/dev/sdb"))
+	require.Len(t, rc.requests, 2)
+	assert.Equal(t, []string{"-S", "on", "/dev/sda"}, rc.requests[0].Args)
+	assert.Equal(t, []string{"-S", "off", "-d", "sat", "/dev/sdb"}, rc.requests[1].Args)
+}
+
+func TestAutoOfflineCollection(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc))
+	require.NoError(t, err)
+
+	require.NoError(t, b.EnableAutoOfflineCollection(context.Background(), "/dev/sda"))
+	require.NoError(t, b.DisableAutoOfflineCollection(context.Background(), "/dev/sda"))
+	require.Len(t, rc.requests, 2)
+	assert.Equal(t, []string{"-o", "on", "/dev/sda"}, rc.requests[0].Args)
+	assert.Equal(t, []string{"-o", "off", "/dev/sda"}, rc.requests[1].Args)
+}
+
+func TestSecurityFreeze(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -s security-freeze /dev/sda": {output: []byte("ATA Security set to frozen mode\n")},
+		"/usr/sbin/smartctl -s security-freeze /dev/sdb": {
+			output: []byte("ATA SECURITY FREEZE LOCK failed: Input/output error\n"),
+			err:    &osexec.ExitError{},
+		},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	require.NoError(t, b.SecurityFreeze(context.Background(), "/dev/sda"))
+	assert.ErrorContains(t, b.SecurityFreeze(context.Background(), "/dev/sdb"), "failed to freeze ATA security")
+}
+
+func TestGetSecureEraseInfo(t *testing.T) {
+	identify := `=== START OF INFORMATION SECTION ===
+Word     Bit    Value   Description
+  0      -      0x0040  General configuration
+ 89      -      0x8012  Time required for Normal Erase mode
+ 90      -      0x00ff  Time required for Enhanced Erase mode
+128      -      0x0029  Security status
+`
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --identify=wn /dev/sda":   {output: []byte(identify)},
+		"/usr/sbin/smartctl --identify=wn /dev/nvme0": {output: []byte("Read NVMe Identify Controller failed\n")},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	info, err := b.GetSecureEraseInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, &SecureEraseInfo{
+		Supported:         true,
+		Frozen:            true,
+		EnhancedSupported: true,
+		NormalTime:        36 * time.Minute,
+		EnhancedTime:      508 * time.Minute,
+		EnhancedOver:      true,
+	}, info)
+
+	_, err = b.GetSecureEraseInfo(context.Background(), "/dev/nvme0")
+	assert.ErrorIs(t, err, ErrFeatureNotSupported)
+}
+
+func TestAttributeAutosave_Failure(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -S on /dev/sda": {
+			output: []byte("SMART Enable Attribute Autosave failed: Input/output error\n"),
+			err:    &osexec.ExitError{},
+		},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	err = b.EnableAttributeAutosave(context.Background(), "/dev/sda")
+	assert.ErrorContains(t, err, "failed to enable attribute autosave")
+}
+
+func TestSetWriteCache_Failure(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -s wcache,on /dev/sda": {
+			output: []byte("Write cache enable failed: Input/output error\n"),
+			err:    &osexec.ExitError{},
+		},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	err = b.SetWriteCache(context.Background(), "/dev/sda", true)
+	assert.ErrorContains(t, err, "failed to set write cache")
+}
@@ -0,0 +1,108 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// fuzzCommander answers every command with the same output and exit code.
+type fuzzCommander struct {
+	stdout   []byte
+	exitCode int
+}
+
+func (f fuzzCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	res := &CommandResult{Stdout: f.stdout, ExitCode: f.exitCode}
+	if f.exitCode != 0 {
+		return res, fmt.Errorf("exit status %d: %w", f.exitCode, &osexec.ExitError{})
+	}
+	return res, nil
+}
+
+// FuzzSmartctlOutput checks that no smartctl output or exit status, however
+// malformed or truncated, makes a query panic, in JSON and text mode.
+func FuzzSmartctlOutput(f *testing.F) {
+	files, _ := filepath.Glob(filepath.Join("..", "..", "smartmontoolstest", "corpus", "*", "*.json"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		var fixture struct {
+			Output   json.RawMessage `json:"output"`
+			Stdout   string          `json:"stdout"`
+			ExitCode int             `json:"exit_code"`
+		}
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			f.Fatal(err)
+		}
+		f.Add(append([]byte(fixture.Output), fixture.Stdout...), uint8(fixture.ExitCode), len(fixture.Output) == 0)
+	}
+	f.Add([]byte(`{"devices":[{"name":"/dev/nvme0n1","type":"nvme"},{"name":"/dev/nvme0"}]}`), uint8(0), false)
+	f.Add([]byte(`{"smartctl":{"messages":[{"string":"Unknown USB bridge [0x152d:0x"}]}}`), uint8(1), false)
+	f.Add([]byte("ID# ATTRIBUTE_NAME FLAG VALUE WORST THRESH TYPE UPDATED WHEN_FAILED RAW_VALUE\n  9 Power_On_Hours 0x0032 099 099 000 Old_age Always - \n"), uint8(0), true)
+
+	logger := slog.New(slog.DiscardHandler)
+	f.Fuzz(func(t *testing.T, output []byte, exitCode uint8, text bool) {
+		b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithLogHandler(logger), WithTextOutput(text),
+			WithContextCommander(fuzzCommander{stdout: output, exitCode: int(exitCode)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.Background()
+		_, _ = b.ScanDevices(ctx)
+		for _, device := range []string{"/dev/sda", "/dev/nvme0"} {
+			_, _ = b.GetSMARTInfo(ctx, device)
+			_, _ = b.GetExtendedSMARTInfo(ctx, device)
+			_, _ = b.CheckHealth(ctx, device)
+			_, _ = b.GetDeviceInfo(ctx, device)
+			_, _ = b.GetAvailableSelfTests(ctx, device)
+			_, _ = b.GetPowerMode(ctx, device)
+			_, _ = b.GetSelectiveSelfTestLog(ctx, device)
+			_, _ = b.GetSecureEraseInfo(ctx, device)
+			_, _ = b.GetWriteCache(ctx, device)
+		}
+		_, _, _ = b.RunSmartctl(ctx, "-l", "devstat", "/dev/sda")
+		_, _ = b.SmartctlVersion(ctx)
+	})
+}
+
+var usbIDRe = regexp.MustCompile(`^0x[0-9a-fA-F]{4}:0x[0-9a-fA-F]{4}$`)
+
+// FuzzExtractUSBIDs checks that modelregexp patterns only ever expand to
+// well-formed vendor:product IDs.
+func FuzzExtractUSBIDs(f *testing.F) {
+	for _, pattern := range []string{"0x152d:0x0578", "0x152d:0x05(7[789]|80)", "0x0bc2:0x(2312|3312)", "0x1234:0x0[0-9a-f]", "0x0480:0x....", "0x152d:0x05(7[^8]|)"} {
+		f.Add(pattern)
+	}
+	f.Fuzz(func(t *testing.T, pattern string) {
+		for _, id := range extractUSBIDs(pattern) {
+			if !usbIDRe.MatchString(id) {
+				t.Fatalf("extractUSBIDs(%q) returned %q", pattern, id)
+			}
+		}
+	})
+}
+
+// FuzzParseDrivedb checks that a malformed drivedb.h is parsed without
+// panicking and yields only well-formed USB IDs.
+func FuzzParseDrivedb(f *testing.F) {
+	f.Add("{ \"USB: Bridge; \",\n  \"0x152d:0x05(7[789]|80)\",\n  \"\",\n  \"\",\n  \"-d sat\"\n},\n")
+	f.Add("{ \"USB: ; \", \"0x1234:0x5678\", \"\", \"\", \"-d \" },")
+	f.Add("{ \"USB:")
+	f.Fuzz(func(t *testing.T, src string) {
+		for key := range parseDrivedb(src) {
+			if id, ok := strings.CutPrefix(key, "usb:"); !ok || !usbIDRe.MatchString(id) {
+				t.Fatalf("parseDrivedb returned key %q", key)
+			}
+		}
+	})
+}
@@ -1,6 +1,10 @@
 package exec
 
-import "strings"
+import (
+	"strings"
+
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
+)
 
 // isATADevice checks if a device type is ATA-based (ata, sat, sata, etc.)
 func isATADevice(deviceType string) bool {
@@ -49,9 +53,20 @@ func determineDiskType(info *SMARTInfo) string {
 	return "Unknown"
 }
 
+// finishSMARTInfo computes the fields smartctl does not report from a parsed
+// SMARTInfo: DiskType, SmartStatus and Warnings.
+func finishSMARTInfo(smartInfo *SMARTInfo) {
+	smartInfo.DiskType = determineDiskType(smartInfo)
+	smartInfo.SmartStatus = checkSmartStatus(smartInfo)
+	smartInfo.Warnings = classifyMessages(smartInfo.Smartctl)
+}
+
+// checkSmartStatus returns the SmartStatus of smartInfo completed with the
+// exit-status bits and the self-test state. When smartctl reported no
+// overall-health verdict, the status is marked Synthesized.
 func checkSmartStatus(smartInfo *SMARTInfo) *SmartStatus {
 	if smartInfo.SmartStatus == nil {
-		smartInfo.SmartStatus = &SmartStatus{}
+		smartInfo.SmartStatus = &SmartStatus{Synthesized: true}
 	}
 
 	var damaged, critical bool
@@ -68,13 +83,11 @@ func checkSmartStatus(smartInfo *SMARTInfo) *SmartStatus {
 		}
 	}
 
-	status := &SmartStatus{Passed: smartInfo.SmartStatus.Passed, Damaged: damaged, Critical: critical}
-	switch {
-	case smartInfo.AtaSmartData != nil && smartInfo.AtaSmartData.SelfTest != nil && smartInfo.AtaSmartData.SelfTest.Status != nil:
-		v := smartInfo.AtaSmartData.SelfTest.Status.Value
-		status.Running = v >= 240 && v <= 253
-	case smartInfo.NvmeSmartTestLog != nil:
-		status.Running = smartInfo.NvmeSmartTestLog.CurrentOpeation != nil && *smartInfo.NvmeSmartTestLog.CurrentOpeation != 0
+	return &SmartStatus{
+		Passed:      smartInfo.SmartStatus.Passed,
+		Damaged:     damaged,
+		Critical:    critical,
+		Running:     smtypes.SelfTestInProgress(smartInfo),
+		Synthesized: smartInfo.SmartStatus.Synthesized,
 	}
-	return status
 }
@@ -41,7 +41,7 @@ func TestResolveSmartctlPath_SearchPathFallback(t *testing.T) {
 
 	t.Setenv("PATH", "")
 
-	got, err := resolveSmartctlPath()
+	got, err := resolveSmartctlPath(nil)
 	require.NoError(t, err)
 	assert.Equal(t, fakeSmartctl, got)
 }
@@ -53,7 +53,7 @@ func TestResolveSmartctlPath_NotFound(t *testing.T) {
 
 	t.Setenv("PATH", "")
 
-	_, err := resolveSmartctlPath()
+	_, err := resolveSmartctlPath(nil)
 	require.Error(t, err)
 	assert.True(t, strings.Contains(err.Error(), "smartctl not found"),
 		"error should mention 'smartctl not found', got: %v", err)
@@ -74,7 +74,7 @@ func TestResolveSmartctlPath_SkipsNonExecutable(t *testing.T) {
 
 	t.Setenv("PATH", "")
 
-	got, err := resolveSmartctlPath()
+	got, err := resolveSmartctlPath(nil)
 	require.NoError(t, err)
 	assert.Equal(t, execFile, got, "non-executable candidate should be skipped")
 }
@@ -94,7 +94,7 @@ func TestResolveSmartctlPath_SkipsDirectories(t *testing.T) {
 
 	t.Setenv("PATH", "")
 
-	got, err := resolveSmartctlPath()
+	got, err := resolveSmartctlPath(nil)
 	require.NoError(t, err)
 	assert.Equal(t, execFile, got, "directory entry should be skipped")
 }
@@ -3,10 +3,12 @@ package exec
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
 	osexec "os/exec"
 	"testing"
 
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,34 +29,34 @@ func newMinimalTestLogger() *slog.Logger {
 
 func TestBuildArgs_ColdCache(t *testing.T) {
 	b := newMinimalBackend(t)
-	got := b.buildArgs("/dev/sda", "-a", "-j")
+	got := b.buildArgs(context.Background(), "/dev/sda", "-a", "-j")
 	assert.Equal(t, []string{"-a", "-j", "--nocheck=standby", "/dev/sda"}, got)
 }
 
 func TestBuildArgs_CachedATA(t *testing.T) {
 	b := newMinimalBackend(t)
 	b.setCachedDeviceType("/dev/sda", "ata")
-	got := b.buildArgs("/dev/sda", "-a", "-j")
+	got := b.buildArgs(context.Background(), "/dev/sda", "-a", "-j")
 	assert.Equal(t, []string{"-a", "-j", "--nocheck=standby", "-d", "ata", "/dev/sda"}, got)
 }
 
 func TestBuildArgs_CachedSAT(t *testing.T) {
 	b := newMinimalBackend(t)
 	b.setCachedDeviceType("/dev/sda", "sat")
-	got := b.buildArgs("/dev/sda", "-a", "-j")
+	got := b.buildArgs(context.Background(), "/dev/sda", "-a", "-j")
 	assert.Equal(t, []string{"-a", "-j", "--nocheck=standby", "-d", "sat", "/dev/sda"}, got)
 }
 
 func TestBuildArgs_CachedNVMe(t *testing.T) {
 	b := newMinimalBackend(t)
 	b.setCachedDeviceType("/dev/nvme0", "nvme")
-	got := b.buildArgs("/dev/nvme0", "-a", "-j")
+	got := b.buildArgs(context.Background(), "/dev/nvme0", "-a", "-j")
 	assert.Equal(t, []string{"-a", "-j", "-d", "nvme", "/dev/nvme0"}, got)
 }
 
 func TestBuildArgs_MultipleFlags(t *testing.T) {
 	b := newMinimalBackend(t)
-	got := b.buildArgs("/dev/sda", "-c", "-j")
+	got := b.buildArgs(context.Background(), "/dev/sda", "-c", "-j")
 	assert.Equal(t, []string{"-c", "-j", "--nocheck=standby", "/dev/sda"}, got)
 }
 
@@ -171,7 +173,7 @@ func TestRetrySATFallback_DirectCall_Success(t *testing.T) {
 	b := newMinimalBackend(t)
 	b.commander = commander
 
-	info, ok := b.retrySATFallback(context.Background(), satFallbackDevice)
+	info, _, ok := b.retrySATFallback(context.Background(), satFallbackDevice, "-a")
 	require.True(t, ok)
 	assert.Equal(t, satFallbackDevice, info.Device.Name)
 	assert.Equal(t, "SAT Test Drive", info.ModelName)
@@ -183,7 +185,7 @@ func TestRetrySATFallback_DirectCall_Success(t *testing.T) {
 
 func TestRetrySATFallback_DirectCall_FallsThrough(t *testing.T) {
 	b := newMinimalBackend(t)
-	info, ok := b.retrySATFallback(context.Background(), satFallbackDevice)
+	info, _, ok := b.retrySATFallback(context.Background(), satFallbackDevice, "-a")
 	assert.False(t, ok)
 	assert.Nil(t, info)
 }
@@ -236,7 +238,7 @@ func TestCheckSmartStatus_ATABoundaryValues(t *testing.T) {
 		{"value 240 - running", 240, true},
 		{"value 245 - running", 245, true},
 		{"value 253 - running", 253, true},
-		{"value 254 - not running", 254, false},
+		{"value 255 - running", 255, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -256,6 +258,11 @@ func TestCheckSmartStatus_NVMeRunning(t *testing.T) {
 	assert.True(t, status.Passed)
 }
 
+func TestCheckSmartStatus_NVMeSelfTestLogRunning(t *testing.T) {
+	smartInfo := &SMARTInfo{SmartStatus: &SmartStatus{Passed: true}, NvmeSelfTestLog: &smtypes.NvmeSelfTestLog{CurrentSelfTestOperation: &StatusField{Value: 2, String: "Extended self-test in progress"}}}
+	assert.True(t, checkSmartStatus(smartInfo).Running)
+}
+
 func TestCheckSmartStatus_NVMeNotRunning(t *testing.T) {
 	currentOp := 0
 	smartInfo := &SMARTInfo{SmartStatus: &SmartStatus{Passed: true}, NvmeSmartTestLog: &NvmeSmartTestLog{CurrentOpeation: &currentOp}}
@@ -297,6 +304,25 @@ func TestCheckSmartStatus_ATASelfTestNilStatus(t *testing.T) {
 	assert.True(t, status.Passed)
 }
 
+func TestCheckSmartStatus_Synthesized(t *testing.T) {
+	var standby SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{"device": {"name": "/dev/sda", "type": "sat"}, "smartctl": {"exit_status": 2}}`), &standby))
+	finishSMARTInfo(&standby)
+	require.NotNil(t, standby.SmartStatus)
+	assert.True(t, standby.SmartStatus.Synthesized)
+	_, ok := standby.HealthVerdict()
+	assert.False(t, ok, "no verdict was reported")
+	finishSMARTInfo(&standby)
+	assert.True(t, standby.SmartStatus.Synthesized, "finishing twice keeps the status synthesized")
+
+	var reported SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{"smart_status": {"passed": false}}`), &reported))
+	finishSMARTInfo(&reported)
+	passed, ok := reported.HealthVerdict()
+	assert.True(t, ok)
+	assert.False(t, passed)
+}
+
 func TestCheckSmartStatus_ExitCodeInfo_Zero(t *testing.T) {
 	smartInfo := &SMARTInfo{SmartStatus: &SmartStatus{Passed: true}, Smartctl: &SmartctlInfo{ExitStatus: 0}}
 	checkSmartStatus(smartInfo)
@@ -420,7 +446,79 @@ func TestGetSMARTInfo_WithMockExitErrorFallback(t *testing.T) {
 	}}
 	b := newMinimalBackend(t)
 	b.commander = commander
-	info, _, err := b.getSMARTInfoInternal(context.Background(), satFallbackDevice)
+	info, _, _, err := b.getSMARTInfoInternal(context.Background(), satFallbackDevice)
 	require.NoError(t, err)
 	assert.Equal(t, satFallbackDevice, info.Device.Name)
 }
+
+func TestParseDrivedbEntries_ConcatenatesAndSkipsComments(t *testing.T) {
+	src := `/* header { "ignored" } */
+  { "Family A", // comment
+    "MODEL-(1|2)|"
+    "MODEL-3", "",
+    "",
+  //"-v 1,raw48,Commented "
+    "-v 9,minutes "
+    "-v 194,tempminmax,Temperature_Celsius"
+  },`
+	entries := parseDrivedbEntries(src)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"Family A", "MODEL-(1|2)|MODEL-3", "", "", "-v 9,minutes -v 194,tempminmax,Temperature_Celsius"}, entries[0])
+}
+
+func TestParsePresetArgs(t *testing.T) {
+	assert.Equal(t, []string{"-v", "9,halfminutes", "-F", "samsung"}, parsePresetArgs("-v 9,halfminutes -F samsung"))
+	assert.Empty(t, parsePresetArgs("-d sat"))
+	assert.Empty(t, parsePresetArgs(""))
+}
+
+func TestLookupDrivePresets(t *testing.T) {
+	presets := lookupDrivePresets("APPLE SSD TS064E", "TQAABBF0")
+	assert.Contains(t, presets, "241,raw48,Host_Writes_GiB")
+	assert.Nil(t, lookupDrivePresets("Unknown Vendor Drive", "1.0"))
+	assert.Nil(t, lookupDrivePresets("", ""))
+}
+
+func TestBuildArgs_AttributePresets(t *testing.T) {
+	b := newMinimalBackend(t)
+	b.SetAttributePresets("/dev/sda", "9,minutes", " ", "198,offlinescanuncsectorct")
+	assert.Equal(t, []string{"9,minutes", "198,offlinescanuncsectorct"}, b.AttributePresets("/dev/sda"))
+	got := b.buildArgs(context.Background(), "/dev/sda", "-a", "-j")
+	assert.Equal(t, []string{"-a", "-j", "--nocheck=standby", "-v", "9,minutes", "-v", "198,offlinescanuncsectorct", "/dev/sda"}, got)
+
+	b.SetAttributePresets("/dev/sda")
+	assert.Empty(t, b.AttributePresets("/dev/sda"))
+}
+
+func TestLearnDrivedbPresets_UserOverrideWins(t *testing.T) {
+	b := newMinimalBackend(t)
+	b.SetDeviceTypeHint("/dev/sda", "sat")
+	b.SetAttributePresets("/dev/sda", "241,raw48,Total_LBAs_Written")
+	known := false
+	b.learnDrivedbPresets(context.Background(), "/dev/sda", &SMARTInfo{ModelName: "APPLE SSD TS064E", InSmartctlDatabase: &known})
+
+	got := b.buildArgs(context.Background(), "/dev/sda", "-a", "-j")
+	assert.Equal(t, []string{
+		"-a", "-j", "--nocheck=standby", "-d", "sat",
+		"-v", "241,raw48,Total_LBAs_Written",
+		"-v", "173,raw48,Wear_Leveling_Count",
+		"-v", "242,raw48,Host_Reades_GiB",
+		"/dev/sda",
+	}, got)
+}
+
+func TestLearnDrivedbPresets_SkippedWhenKnownOrDisabled(t *testing.T) {
+	known, unknown := true, false
+	b := newMinimalBackend(t)
+	b.learnDrivedbPresets(context.Background(), "/dev/sda", &SMARTInfo{ModelName: "APPLE SSD TS064E", InSmartctlDatabase: &known})
+	assert.Nil(t, b.presetArgs("/dev/sda"))
+
+	disabled, err := New(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{}}),
+		WithDrivedbPresets(false),
+	)
+	require.NoError(t, err)
+	disabled.learnDrivedbPresets(context.Background(), "/dev/sda", &SMARTInfo{ModelName: "APPLE SSD TS064E", InSmartctlDatabase: &unknown})
+	assert.Nil(t, disabled.presetArgs("/dev/sda"))
+}
@@ -0,0 +1,52 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
+)
+
+// WithStrictJSONFormat makes every smartctl call fail with
+// ErrUnsupportedJSONFormat when the output uses a json_format_version major
+// version the library does not support. By default such output is still
+// parsed and a warning is logged once per version.
+func WithStrictJSONFormat(enabled bool) Option {
+	return func(b *ExecBackend) {
+		b.strictJSONFormat = enabled
+	}
+}
+
+// checkJSONFormat validates the json_format_version of a JSON response.
+// Output that is empty or not JSON is left for the caller to reject.
+func (b *ExecBackend) checkJSONFormat(ctx context.Context, args []string, output []byte) error {
+	if len(output) == 0 || !slices.ContainsFunc(args, isJSONFlag) {
+		return nil
+	}
+	var header struct {
+		JSONFormatVersion []int `json:"json_format_version"`
+	}
+	if json.Unmarshal(output, &header) != nil {
+		return nil
+	}
+	err := smtypes.CheckJSONFormatVersion(header.JSONFormatVersion)
+	if err == nil || b.strictJSONFormat {
+		return err
+	}
+	version := fmt.Sprint(header.JSONFormatVersion)
+	if globalMessageCache.shouldLog("json_format_version "+version, "warning") {
+		b.logHandler.WarnContext(ctx, "smartctl JSON schema is newer than supported, results may be incomplete",
+			"jsonFormatVersion", header.JSONFormatVersion,
+			"supportedMajor", smtypes.SupportedJSONFormatMajor,
+			"smartctlPath", b.smartctlPath,
+		)
+	}
+	return nil
+}
+
+func isJSONFlag(arg string) bool {
+	return arg == "-j" || arg == "--json" || strings.HasPrefix(arg, "--json=")
+}
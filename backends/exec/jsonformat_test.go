@@ -0,0 +1,48 @@
+package exec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const futureSchemaJSON = `{"json_format_version":[2,0],"device":{"name":"/dev/sda","type":"sat"},"serial_number":"NEXT1"}`
+
+func TestJSONFormat_WarnsAndParses(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -a -j --nocheck=standby /dev/sda": {output: []byte(futureSchemaJSON)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	info, err := b.GetSMARTInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "NEXT1", info.SerialNumber)
+	assert.Equal(t, []int{2, 0}, info.JSONFormatVersion)
+}
+
+func TestJSONFormat_Strict(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -a -j --nocheck=standby /dev/sda": {output: []byte(futureSchemaJSON)},
+		"/usr/sbin/smartctl --scan-open --json":               {output: []byte(`{"json_format_version":[1,0],"devices":[]}`)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock), WithStrictJSONFormat(true))
+	require.NoError(t, err)
+
+	_, err = b.GetSMARTInfo(context.Background(), "/dev/sda")
+	require.ErrorIs(t, err, ErrUnsupportedJSONFormat)
+	assert.Contains(t, err.Error(), "2.0")
+
+	_, err = b.ScanDevices(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestIsJSONFlag(t *testing.T) {
+	assert.True(t, isJSONFlag("-j"))
+	assert.True(t, isJSONFlag("--json"))
+	assert.True(t, isJSONFlag("--json=c"))
+	assert.False(t, isJSONFlag("-a"))
+	assert.False(t, isJSONFlag("--jsonx"))
+}
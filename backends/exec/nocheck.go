@@ -0,0 +1,71 @@
+package exec
+
+import (
+	"context"
+	"strings"
+)
+
+// WithNoCheckPolicy sets when SMART queries skip drives in a low-power mode
+// instead of waking them (smartctl --nocheck). The default skips drives in
+// standby indefinitely. A policy with MaxSkips wakes a drive after that many
+// consecutive skipped queries, like smartd's "-n standby,N". Individual calls
+// can override it with ContextWithNoCheck. New fails for invalid policies.
+func WithNoCheckPolicy(policy NoCheckPolicy) Option {
+	return func(b *ExecBackend) {
+		b.noCheck = policy
+	}
+}
+
+// noCheckArg returns the --nocheck argument for a query of devicePath, using
+// the policy of ctx, the device or the backend. Once MaxSkips consecutive
+// queries were skipped, it returns --nocheck=never so the next query reads the
+// drive.
+func (b *ExecBackend) noCheckArg(ctx context.Context, devicePath string) string {
+	policy := b.noCheck
+	if p := b.optionsFor(devicePath).NoCheck; p != nil {
+		policy = *p
+	}
+	if override, ok := NoCheckFromContext(ctx); ok && override.Validate() == nil {
+		policy = override
+	}
+	mode := policy.Effective()
+	if mode != NoCheckNever && policy.MaxSkips > 0 {
+		b.noCheckMux.Lock()
+		skips := b.noCheckSkips[devicePath]
+		b.noCheckMux.Unlock()
+		if skips >= policy.MaxSkips {
+			b.logHandler.DebugContext(ctx, "Skip limit reached, waking device", "devicePath", devicePath, "skips", skips)
+			mode = NoCheckNever
+		}
+	}
+	return "--nocheck=" + string(mode)
+}
+
+// recordNoCheck counts consecutive queries that smartctl skipped because the
+// device was in a low-power mode, and resets the count once one reads it.
+func (b *ExecBackend) recordNoCheck(args []string, res *CommandResult, err error) {
+	if !hasNoCheckArg(args) {
+		return
+	}
+	device := commandDevice(args)
+	if device == "" {
+		return
+	}
+	skipped := res.ExitCode > 0 && ExitStatus(res.ExitCode).DeviceOpenFailed() && !isOpenFailure(err)
+	b.noCheckMux.Lock()
+	defer b.noCheckMux.Unlock()
+	if skipped {
+		b.noCheckSkips[device]++
+	} else {
+		delete(b.noCheckSkips, device)
+	}
+}
+
+func hasNoCheckArg(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--nocheck=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package exec
+
+import (
+	"context"
+	osexec "os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNoCheckPolicy(t *testing.T) {
+	p, err := ParseNoCheckPolicy("standby,3")
+	require.NoError(t, err)
+	assert.Equal(t, NoCheckPolicy{Mode: NoCheckStandby, MaxSkips: 3}, p)
+	assert.Equal(t, "standby,3", p.String())
+
+	p, err = ParseNoCheckPolicy("Never")
+	require.NoError(t, err)
+	assert.Equal(t, NoCheckNever, p.Mode)
+
+	_, err = ParseNoCheckPolicy("deep")
+	assert.ErrorContains(t, err, "invalid nocheck mode")
+	_, err = ParseNoCheckPolicy("standby,x")
+	assert.ErrorContains(t, err, "invalid nocheck skip count")
+
+	assert.Equal(t, "standby", NoCheckPolicy{}.String())
+}
+
+func TestNoCheckPolicy_BackendAndContextOverride(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{Stdout: []byte(`PASSED`)}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithNoCheckPolicy(NoCheckPolicy{Mode: NoCheckIdle}))
+	require.NoError(t, err)
+
+	_, err = b.CheckHealth(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	ctx := ContextWithNoCheck(context.Background(), NoCheckPolicy{Mode: NoCheckNever})
+	_, err = b.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+
+	require.Len(t, rc.requests, 2)
+	assert.Equal(t, []string{"-H", "--nocheck=idle", "/dev/sda"}, rc.requests[0].Args)
+	assert.Equal(t, []string{"-H", "--nocheck=never", "/dev/sda"}, rc.requests[1].Args)
+
+	_, err = New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithNoCheckPolicy(NoCheckPolicy{Mode: "deep"}))
+	assert.Error(t, err)
+}
+
+func TestNoCheckPolicy_WakesAfterMaxSkips(t *testing.T) {
+	standby := &CommandResult{ExitCode: 2}
+	rc := &recordingCommander{result: standby, err: &osexec.ExitError{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithNoCheckPolicy(NoCheckPolicy{Mode: NoCheckStandby, MaxSkips: 2}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for range 2 {
+		_, _ = b.CheckHealth(ctx, "/dev/sda")
+	}
+	// The third query wakes the drive; once it is read the count starts over.
+	rc.result, rc.err = &CommandResult{Stdout: []byte("PASSED")}, nil
+	healthy, err := b.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.True(t, healthy)
+	_, err = b.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+
+	var modes []string
+	for _, req := range rc.requests {
+		modes = append(modes, req.Args[1])
+	}
+	assert.Equal(t, []string{"--nocheck=standby", "--nocheck=standby", "--nocheck=never", "--nocheck=standby"}, modes)
+}
@@ -0,0 +1,69 @@
+package exec
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/dianlight/smartmontools-go/internal/sysfs"
+)
+
+// nvmeFabricsTransports are the NVMe controller transports of NVMe over
+// Fabrics (NVMe-oF) devices, as reported by /sys/class/nvme/nvmeX/transport.
+// Local drives report "pcie".
+var nvmeFabricsTransports = map[string]bool{"tcp": true, "rdma": true, "fc": true, "loop": true}
+
+// nvmeTransport returns the transport of the NVMe controller behind
+// devicePath, a controller (/dev/nvme1) or a namespace (/dev/nvme1n1), or ""
+// when devicePath is not an NVMe device known to sysfs. With native NVMe
+// multipathing a namespace belongs to the subsystem, which links to its
+// controllers by name, instead of to a single controller.
+func nvmeTransport(devicePath string) string {
+	name := filepath.Base(devicePath)
+	if nvmeControllerRe.MatchString(name) {
+		return sysfs.ReadLine(filepath.Join(sysfsDir, "class", "nvme", name, "transport"))
+	}
+	if !nvmeNamespaceRe.MatchString(name) {
+		return ""
+	}
+	device := filepath.Join(sysfsDir, "class", "block", name, "device")
+	if transport := sysfs.ReadLine(filepath.Join(device, "transport")); transport != "" {
+		return transport
+	}
+	paths, _ := filepath.Glob(filepath.Join(device, "nvme*", "transport"))
+	for _, path := range paths {
+		if transport := sysfs.ReadLine(path); transport != "" {
+			return transport
+		}
+	}
+	return ""
+}
+
+// isNVMeFabrics reports whether devicePath is attached through NVMe over
+// Fabrics. Like addSysfsInfo it only looks at sysfs on Linux with the default
+// commander.
+func (b *ExecBackend) isNVMeFabrics(devicePath string) bool {
+	if goos != "linux" || !b.defaultCommander {
+		return false
+	}
+	return nvmeFabricsTransports[nvmeTransport(devicePath)]
+}
+
+// isNVMePath reports whether devicePath names an NVMe controller or namespace.
+// Such devices never speak SAT, so probing them with -d sat is pointless.
+func isNVMePath(devicePath string) bool {
+	name := filepath.Base(devicePath)
+	return nvmeControllerRe.MatchString(name) || nvmeNamespaceRe.MatchString(name)
+}
+
+// learnNVMeFabrics caches the "nvme" device type of an NVMe-oF device that
+// has none yet. smartctl does not always recognize fabrics namespaces, and
+// without a type the first failure would trigger a SAT probe; the targets
+// commonly reject the error information and self-test log requests, which
+// smartctl reports with exit bit 2.
+func (b *ExecBackend) learnNVMeFabrics(ctx context.Context, devicePath string) {
+	if _, cached := b.getCachedDeviceType(devicePath); cached || !b.isNVMeFabrics(devicePath) {
+		return
+	}
+	b.logHandler.DebugContext(ctx, "NVMe over Fabrics device", "devicePath", devicePath)
+	b.setCachedDeviceType(devicePath, "nvme")
+}
@@ -0,0 +1,55 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	osexec "os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exitError returns the error of a process that exited with code.
+func exitError(t *testing.T, code int) *osexec.ExitError {
+	t.Helper()
+	requireShell(t)
+	var exitErr *osexec.ExitError
+	require.ErrorAs(t, osexec.Command("sh", "-c", fmt.Sprintf("exit %d", code)).Run(), &exitErr)
+	return exitErr
+}
+
+func TestNVMeTransport(t *testing.T) {
+	fakeSysfs(t)
+	assert.Equal(t, "pcie", nvmeTransport("/dev/nvme0"))
+	assert.Equal(t, "pcie", nvmeTransport("/dev/nvme0n1"))
+	assert.Equal(t, "tcp", nvmeTransport("/dev/nvme1"))
+	assert.Equal(t, "tcp", nvmeTransport("/dev/nvme1n1"), "multipath namespaces are found through the subsystem")
+	assert.Empty(t, nvmeTransport("/dev/sda"))
+	assert.True(t, isNVMePath("/dev/nvme1n1"))
+	assert.False(t, isNVMePath("/dev/sda"))
+}
+
+func TestExecBackend_NVMeFabrics(t *testing.T) {
+	fakeSysfs(t)
+	withGOOS(t, "linux")
+	partial := `{"device":{"name":"/dev/nvme1n1","type":"nvme","protocol":"NVMe"},"model_name":"Linux","smart_status":{"passed":true},` +
+		`"nvme_smart_health_information_log":{"critical_warning":0,"temperature":0,"available_spare":100}}`
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+		// The target rejects the error information log: exit bit 2.
+		"/usr/sbin/smartctl -a -j -d nvme /dev/nvme1n1": {output: []byte(partial), err: exitError(t, 4)},
+		"/usr/sbin/smartctl -c -j -d nvme /dev/nvme1n1": {output: []byte(`{"device":{"name":"/dev/nvme1n1"}}`), err: exitError(t, 4)},
+	}}))
+	require.NoError(t, err)
+	b.defaultCommander = true // sysfs is only consulted for local smartctl runs
+
+	ctx := context.Background()
+	info, err := b.GetSMARTInfo(ctx, "/dev/nvme1n1")
+	require.NoError(t, err, "no SAT probe, partial output is used")
+	assert.Equal(t, "/dev/nvme1n1", info.Device.Name)
+	assert.True(t, info.SmartStatus.Passed)
+
+	tests, err := b.GetAvailableSelfTests(ctx, "/dev/nvme1n1")
+	require.NoError(t, err)
+	assert.Empty(t, tests.Available)
+}
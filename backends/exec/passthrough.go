@@ -0,0 +1,86 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RunSmartctl runs smartctl with args and returns its JSON output, for
+// features SMARTInfo does not model yet, e.g. RunSmartctl(ctx, "-l",
+// "devstat", "/dev/sda"). "-j" is added unless args already select JSON
+// output. The device, when args end with one, is normalized, locked, rate
+// limited and given its tolerance and extra arguments like in every other
+// call; the smartctl path, elevation, environment and timeout apply too, but
+// no -d type or --nocheck policy is added.
+//
+// The exit status is returned whenever smartctl ran. Health bits (3–7) alone
+// are not an error; execution failures (bits 0–2) return a *SmartctlError
+// together with whatever JSON smartctl printed. Output that is not JSON fails.
+func (b *ExecBackend) RunSmartctl(ctx context.Context, args ...string) (json.RawMessage, *ExitStatus, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if b.version != nil && b.version.Major < minJSONMajor {
+		return nil, nil, fmt.Errorf("smartctl %d.%d has no JSON output", b.version.Major, b.version.Minor)
+	}
+	args = slices.Clone(args)
+	if device := commandDevice(args); device != "" {
+		args[len(args)-1] = NormalizeDevicePath(device)
+	}
+	if device := ModifiedDevice(args...); device != "" {
+		defer b.forgetSharedQuery(device)
+	}
+	if !slices.ContainsFunc(args, isJSONFlag) {
+		args = append([]string{"-j"}, args...)
+	}
+
+	res, err := b.run(ctx, args...)
+	var status *ExitStatus
+	if res.ExitCode >= 0 {
+		s := ExitStatus(res.ExitCode)
+		status = &s
+	}
+	if err != nil {
+		if _, ok := asExitError(err); !ok {
+			return nil, status, err
+		}
+	}
+	var raw json.RawMessage
+	if output := bytes.TrimSpace(res.Stdout); json.Valid(output) {
+		raw = output
+	}
+	if status != nil && status.ExecFailed() {
+		return raw, status, err
+	}
+	if raw == nil {
+		return nil, status, errors.Join(err, fmt.Errorf("smartctl output is not valid JSON"))
+	}
+	return raw, status, nil
+}
+
+// ModifiedDevice returns the device of a smartctl command line that changes
+// its state — with -s, -t, -X, -o or -S, or their long forms — so that
+// cached results of the device can be dropped. It returns "" for read-only
+// and device-less commands.
+func ModifiedDevice(args ...string) string {
+	device := commandDevice(args)
+	if device == "" {
+		return ""
+	}
+	for _, arg := range args[:len(args)-1] {
+		name, _, _ := strings.Cut(arg, "=")
+		switch {
+		case name == "--smart", name == "--set", name == "--test", name == "--abort",
+			name == "--offlineauto", name == "--saveauto":
+			return device
+		case len(arg) >= 2 && arg[0] == '-' && arg[1] != '-' && strings.ContainsRune("stXoS", rune(arg[1])):
+			return device
+		}
+	}
+	return ""
+}
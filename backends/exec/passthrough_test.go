@@ -0,0 +1,78 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSmartctl(t *testing.T) {
+	ctx := context.Background()
+	newBackend := func(t *testing.T, rc *recordingCommander) *ExecBackend {
+		t.Helper()
+		b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithTolerance(TolerancePermissive))
+		require.NoError(t, err)
+		return b
+	}
+
+	t.Run("health bits are not an error", func(t *testing.T) {
+		rc := &recordingCommander{result: &CommandResult{Stdout: []byte(" {\"ata_device_statistics\":{}}\n"), ExitCode: 8}, err: exitError(t, 8)}
+		raw, status, err := newBackend(t, rc).RunSmartctl(ctx, "-l", "devstat", "/dev/sda")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"ata_device_statistics":{}}`, string(raw))
+		require.NotNil(t, status)
+		assert.True(t, status.DiskFailing())
+		require.Len(t, rc.requests, 1)
+		assert.Equal(t, []string{"-T", "permissive", "-j", "-l", "devstat", "/dev/sda"}, rc.requests[0].Args)
+	})
+
+	t.Run("execution failures return the error and output", func(t *testing.T) {
+		rc := &recordingCommander{result: &CommandResult{Stdout: []byte(`{"smartctl":{"exit_status":4}}`), ExitCode: 4}, err: exitError(t, 4)}
+		raw, status, err := newBackend(t, rc).RunSmartctl(ctx, "--json=c", "-l", "xerror", "/dev/sda")
+		var smartctlErr *SmartctlError
+		require.True(t, errors.As(err, &smartctlErr))
+		assert.True(t, smartctlErr.Status.CommandFailed())
+		assert.NotEmpty(t, raw)
+		assert.Equal(t, ExitStatus(4), *status)
+		assert.Equal(t, []string{"-T", "permissive", "--json=c", "-l", "xerror", "/dev/sda"}, rc.requests[0].Args, "JSON flags are not added twice")
+	})
+
+	t.Run("output must be JSON", func(t *testing.T) {
+		rc := &recordingCommander{result: &CommandResult{Stdout: []byte("smartctl 7.4\n")}}
+		raw, status, err := newBackend(t, rc).RunSmartctl(ctx, "--scan")
+		assert.ErrorContains(t, err, "not valid JSON")
+		assert.Nil(t, raw)
+		assert.Equal(t, ExitStatus(0), *status)
+		assert.Equal(t, []string{"-j", "--scan"}, rc.requests[0].Args, "commands without a device get no tolerance")
+	})
+
+	t.Run("start failures have no status", func(t *testing.T) {
+		rc := &recordingCommander{result: &CommandResult{ExitCode: -1}, err: errors.New("exec: not found")}
+		_, status, err := newBackend(t, rc).RunSmartctl(ctx, "-i", "/dev/sda")
+		assert.ErrorContains(t, err, "not found")
+		assert.Nil(t, status)
+	})
+}
+
+func TestModifiedDevice(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-l", "devstat", "/dev/sda"}, ""},
+		{[]string{"-j", "-x", "-d", "sat", "/dev/sda"}, ""},
+		{[]string{"-s", "wcache,off", "/dev/sda"}, "/dev/sda"},
+		{[]string{"--smart=on", "/dev/sda"}, "/dev/sda"},
+		{[]string{"-t", "short", "-d", "nvme", "/dev/nvme0"}, "/dev/nvme0"},
+		{[]string{"--test=long", "/dev/sda"}, "/dev/sda"},
+		{[]string{"-X", "/dev/sda"}, "/dev/sda"},
+		{[]string{"-o", "on", "-S", "on", "/dev/sda"}, "/dev/sda"},
+		{[]string{"--scan"}, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ModifiedDevice(tt.args...), "%v", tt.args)
+	}
+}
@@ -0,0 +1,93 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	// "Device is in STANDBY mode, exit(2)", printed when -n skips the device.
+	powerModeSkippedRe = regexp.MustCompile(`Device is in (\S+(?: \(OS\))?) mode`)
+	// "Power mode is:    ACTIVE or IDLE" or "Power mode was:   IDLE_A".
+	powerModeLineRe = regexp.MustCompile(`(?m)^Power mode (?:is|was):\s*(.+?)\s*$`)
+)
+
+// GetPowerMode reports the power mode of devicePath without waking it. It
+// runs "smartctl -i -n idle": smartctl checks the mode first and stops with
+// exit bit 1 when the drive is idle, in standby or asleep, so the drive is
+// only queried further when it is already active. Devices without ATA power
+// management report PowerModeUnknown.
+func (b *ExecBackend) GetPowerMode(ctx context.Context, devicePath string) (PowerMode, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	args := []string{"-i", "-n", "idle"}
+	if cachedType, ok := b.getCachedDeviceType(devicePath); ok {
+		args = append(args, "-d", cachedType)
+	}
+	res, err := b.run(ctx, append(args, devicePath)...)
+	output := string(res.Stdout)
+	if m := powerModeSkippedRe.FindStringSubmatch(output); m != nil {
+		return parsePowerMode(m[1]), nil
+	}
+	if err != nil {
+		return PowerModeUnknown, fmt.Errorf("failed to get power mode: %w", err)
+	}
+	if m := powerModeLineRe.FindStringSubmatch(output); m != nil {
+		return parsePowerMode(m[1]), nil
+	}
+	return PowerModeUnknown, nil
+}
+
+// SetStandbyTimer sets the spindown timeout of devicePath with "smartctl -s
+// standby,N". The timeout is rounded up to the next value ATA can express;
+// zero disables the timer.
+func (b *ExecBackend) SetStandbyTimer(ctx context.Context, devicePath string, timeout time.Duration) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	value, err := standbyTimerValue(timeout)
+	if err != nil {
+		return err
+	}
+	defer b.forgetSharedQuery(devicePath)
+	if _, err := b.run(ctx, b.featureArgs(devicePath, "-s", "standby,"+strconv.Itoa(value))...); err != nil {
+		return fmt.Errorf("failed to set standby timer: %w", err)
+	}
+	return nil
+}
+
+// StandbyNow spins devicePath down immediately with "smartctl -s standby,now".
+func (b *ExecBackend) StandbyNow(ctx context.Context, devicePath string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	defer b.forgetSharedQuery(devicePath)
+	if _, err := b.run(ctx, b.featureArgs(devicePath, "-s", "standby,now")...); err != nil {
+		return fmt.Errorf("failed to enter standby: %w", err)
+	}
+	return nil
+}
+
+// WakeDevice spins devicePath up on purpose by reading its SMART attributes
+// with --nocheck=never, e.g. before a series of queries or a self-test. Each
+// wake is logged at info level so spin-ups caused by the library are visible.
+func (b *ExecBackend) WakeDevice(ctx context.Context, devicePath string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	b.logHandler.InfoContext(ctx, "Waking device", "devicePath", devicePath)
+	// Shared results may still report the drive in standby.
+	defer b.forgetSharedQuery(devicePath)
+	if _, err := b.run(ctx, b.featureArgs(devicePath, "-A", "--nocheck=never")...); err != nil {
+		return fmt.Errorf("failed to wake device: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package exec
+
+import (
+	"context"
+	osexec "os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPowerMode(t *testing.T) {
+	const banner = "smartctl 7.3 2022-02-28 r5338 [x86_64-linux-6.1.0] (local build)\n\n"
+	tests := []struct {
+		name   string
+		output string
+		err    error
+		want   PowerMode
+	}{
+		{"active", banner + "=== START OF INFORMATION SECTION ===\nDevice Model:     WDC WD40EFRX\nPower mode is:    ACTIVE or IDLE\n", nil, PowerModeActive},
+		{"idle", banner + "Device is in IDLE_B mode, exit(2)\n", &osexec.ExitError{}, PowerModeIdle},
+		{"standby", banner + "Device is in STANDBY mode, exit(2)\n", &osexec.ExitError{}, PowerModeStandby},
+		{"standby os", banner + "Device is in STANDBY (OS) mode, exit(2)\n", &osexec.ExitError{}, PowerModeStandby},
+		{"sleep", banner + "Device is in SLEEP mode, exit(2)\n", &osexec.ExitError{}, PowerModeSleep},
+		{"nvme", banner + "=== START OF INFORMATION SECTION ===\nModel Number:     Samsung SSD 980\n", nil, PowerModeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockCommander{cmds: map[string]*mockCmd{
+				"/usr/sbin/smartctl -i -n idle /dev/sda": {output: []byte(tt.output), err: tt.err},
+			}}
+			b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+			require.NoError(t, err)
+
+			mode, err := b.GetPowerMode(context.Background(), "/dev/sda")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, mode)
+		})
+	}
+}
+
+func TestGetPowerMode_UsesCachedTypeAndReportsFailures(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -i -n idle -d sat /dev/sdb": {
+			output: []byte("Smartctl open device: /dev/sdb failed: No such device\n"),
+			err:    &osexec.ExitError{},
+		},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+	b.setCachedDeviceType("/dev/sdb", "sat")
+
+	mode, err := b.GetPowerMode(context.Background(), "/dev/sdb")
+	require.ErrorIs(t, err, ErrDeviceNotFound)
+	assert.Equal(t, PowerModeUnknown, mode)
+}
+
+func TestPowerModeAwake(t *testing.T) {
+	assert.True(t, PowerModeActive.Awake())
+	assert.True(t, PowerModeIdle.Awake())
+	assert.False(t, PowerModeStandby.Awake())
+	assert.False(t, PowerModeSleep.Awake())
+	assert.Equal(t, PowerModeStandby, parsePowerMode("STANDBY_Y"))
+	assert.Equal(t, PowerModeUnknown, parsePowerMode("weird"))
+}
+
+func TestStandbyTimerValue(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    int
+	}{
+		{0, 0},
+		{time.Second, 1},
+		{5 * time.Second, 1},
+		{10 * time.Minute, 120},
+		{20 * time.Minute, 240},
+		{21 * time.Minute, 252},
+		{30 * time.Minute, 241},
+		{time.Hour, 242},
+		{61 * time.Minute, 243},
+		{330 * time.Minute, 251},
+	}
+	for _, tt := range tests {
+		got, err := standbyTimerValue(tt.timeout)
+		require.NoError(t, err, tt.timeout)
+		assert.Equal(t, tt.want, got, tt.timeout)
+	}
+	_, err := standbyTimerValue(330*time.Minute + time.Second)
+	assert.Error(t, err)
+	_, err = standbyTimerValue(-time.Second)
+	assert.Error(t, err)
+}
+
+func TestSetStandbyTimerAndStandbyNow(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, b.SetStandbyTimer(ctx, "/dev/sda", 10*time.Minute))
+	require.NoError(t, b.SetStandbyTimer(ctx, "/dev/sda", 0))
+	require.NoError(t, b.StandbyNow(ctx, "/dev/sda"))
+	assert.Error(t, b.SetStandbyTimer(ctx, "/dev/sda", 24*time.Hour))
+
+	require.Len(t, rc.requests, 3)
+	assert.Equal(t, []string{"-s", "standby,120", "/dev/sda"}, rc.requests[0].Args)
+	assert.Equal(t, []string{"-s", "standby,0", "/dev/sda"}, rc.requests[1].Args)
+	assert.Equal(t, []string{"-s", "standby,now", "/dev/sda"}, rc.requests[2].Args)
+
+	b.setCachedDeviceType("/dev/sdb", "sat")
+	require.NoError(t, b.SetStandbyTimer(ctx, "/dev/sdb", time.Minute))
+	require.NoError(t, b.StandbyNow(ctx, "/dev/sdb"))
+	assert.Equal(t, []string{"-s", "standby,12", "-d", "sat", "/dev/sdb"}, rc.requests[3].Args)
+	assert.Equal(t, []string{"-s", "standby,now", "-d", "sat", "/dev/sdb"}, rc.requests[4].Args)
+
+	rc.err = &osexec.ExitError{}
+	assert.ErrorContains(t, b.StandbyNow(ctx, "/dev/sda"), "failed to enter standby")
+}
+
+func TestWakeDevice(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithNoCheckPolicy(NoCheckPolicy{Mode: NoCheckStandby, MaxSkips: 5}))
+	require.NoError(t, err)
+	b.setCachedDeviceType("/dev/sda", "sat")
+	b.noCheckSkips["/dev/sda"] = 3
+
+	require.NoError(t, b.WakeDevice(context.Background(), "/dev/sda"))
+	require.Len(t, rc.requests, 1)
+	assert.Equal(t, []string{"-A", "--nocheck=never", "-d", "sat", "/dev/sda"}, rc.requests[0].Args)
+	assert.NotContains(t, b.noCheckSkips, "/dev/sda", "a wake resets the skip count")
+
+	rc.err = &osexec.ExitError{}
+	assert.ErrorContains(t, b.WakeDevice(context.Background(), "/dev/sda"), "failed to wake device")
+}
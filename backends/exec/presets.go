@@ -0,0 +1,249 @@
+package exec
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// drivePresetEntry is a non-USB drivedb.h entry carrying vendor-specific
+// attribute ('-v') and firmware bug ('-F') presets.
+type drivePresetEntry struct {
+	family   string
+	model    *regexp.Regexp
+	firmware *regexp.Regexp
+	presets  []string
+}
+
+var (
+	drivePresetTable     []drivePresetEntry
+	drivePresetTableOnce sync.Once
+)
+
+// loadDrivePresets returns the preset table parsed from the embedded drivedb.h.
+// Parsing and regexp compilation are deferred until the first lookup because
+// most callers never need presets for drives unknown to smartctl.
+func loadDrivePresets() []drivePresetEntry {
+	drivePresetTableOnce.Do(func() {
+		drivePresetTable = parseDrivePresets(drivedbH)
+	})
+	return drivePresetTable
+}
+
+// parseDrivePresets extracts drive entries with presets from drivedb.h source.
+// USB, VERSION and DEFAULT entries are skipped (smartctl applies DEFAULT on its
+// own), as are entries whose POSIX regular expressions are not valid RE2.
+func parseDrivePresets(src string) []drivePresetEntry {
+	var entries []drivePresetEntry
+	for _, fields := range parseDrivedbEntries(src) {
+		if len(fields) < 5 {
+			continue
+		}
+		family := fields[0]
+		if strings.HasPrefix(family, "USB:") || strings.HasPrefix(family, "VERSION") ||
+			strings.HasPrefix(family, "$") || family == "DEFAULT" {
+			continue
+		}
+		presets := parsePresetArgs(fields[4])
+		if len(presets) == 0 {
+			continue
+		}
+		model, err := regexp.Compile(`^(?:` + fields[1] + `)$`)
+		if err != nil {
+			continue
+		}
+		entry := drivePresetEntry{family: family, model: model, presets: presets}
+		if fields[2] != "" {
+			firmware, err := regexp.Compile(`^(?:` + fields[2] + `)$`)
+			if err != nil {
+				continue
+			}
+			entry.firmware = firmware
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parseDrivedbEntries tokenizes drivedb.h into brace-delimited entries, each
+// returned as its list of fields. Adjacent C string literals are concatenated,
+// escape sequences are decoded and comments are ignored, mirroring how the C
+// compiler sees builtin_knowndrives[].
+func parseDrivedbEntries(src string) [][]string {
+	var (
+		entries [][]string
+		fields  []string
+		field   strings.Builder
+		inEntry bool
+	)
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return entries
+			}
+			i += end + 3
+		case c == '"':
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+					switch src[i] {
+					case 'n':
+						field.WriteByte('\n')
+					case 't':
+						field.WriteByte('\t')
+					default:
+						field.WriteByte(src[i])
+					}
+					continue
+				}
+				field.WriteByte(src[i])
+			}
+		case c == '{':
+			inEntry = true
+			fields = nil
+			field.Reset()
+		case c == ',' && inEntry:
+			fields = append(fields, field.String())
+			field.Reset()
+		case c == '}' && inEntry:
+			fields = append(fields, field.String())
+			field.Reset()
+			entries = append(entries, fields)
+			inEntry = false
+		}
+	}
+	return entries
+}
+
+// parsePresetArgs splits a drivedb presets string such as
+// "-v 9,minutes -F samsung" into smartctl arguments, keeping only the
+// '-v' and '-F' options.
+func parsePresetArgs(presets string) []string {
+	tokens := strings.Fields(presets)
+	var args []string
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i] == "-v" || tokens[i] == "-F" {
+			args = append(args, tokens[i], tokens[i+1])
+			i++
+		}
+	}
+	return args
+}
+
+// lookupDrivePresets returns the presets of the first drivedb entry matching
+// model and firmware, or nil when no entry matches.
+func lookupDrivePresets(model, firmware string) []string {
+	if model == "" {
+		return nil
+	}
+	for _, entry := range loadDrivePresets() {
+		if !entry.model.MatchString(model) {
+			continue
+		}
+		if entry.firmware != nil && !entry.firmware.MatchString(firmware) {
+			continue
+		}
+		return entry.presets
+	}
+	return nil
+}
+
+// presetAttributeID returns the attribute ID part of a '-v' value
+// ("9,minutes" -> "9").
+func presetAttributeID(preset string) string {
+	id, _, _ := strings.Cut(preset, ",")
+	return strings.TrimSpace(id)
+}
+
+// SetAttributePresets stores per-device attribute interpretation overrides,
+// passed to smartctl as '-v' options (e.g. "9,minutes" or
+// "198,offlinescanuncsectorct"). Calling it with no presets clears them.
+func (b *ExecBackend) SetAttributePresets(devicePath string, presets ...string) {
+	devicePath = NormalizeDevicePath(devicePath)
+	b.presetsMux.Lock()
+	defer b.presetsMux.Unlock()
+	cleaned := make([]string, 0, len(presets))
+	for _, p := range presets {
+		if p = strings.TrimSpace(p); p != "" {
+			cleaned = append(cleaned, p)
+		}
+	}
+	if len(cleaned) == 0 {
+		delete(b.attributePresets, devicePath)
+		return
+	}
+	if b.attributePresets == nil {
+		b.attributePresets = make(map[string][]string)
+	}
+	b.attributePresets[devicePath] = cleaned
+}
+
+// AttributePresets returns the user-supplied attribute presets for devicePath.
+func (b *ExecBackend) AttributePresets(devicePath string) []string {
+	devicePath = NormalizeDevicePath(devicePath)
+	b.presetsMux.RLock()
+	defer b.presetsMux.RUnlock()
+	return append([]string(nil), b.attributePresets[devicePath]...)
+}
+
+// presetArgs returns the '-v'/'-F' arguments for devicePath. User presets come
+// first; drivedb '-v' presets for attributes the user already overrides are
+// dropped so the user's interpretation always wins.
+func (b *ExecBackend) presetArgs(devicePath string) []string {
+	b.presetsMux.RLock()
+	defer b.presetsMux.RUnlock()
+	user := b.attributePresets[devicePath]
+	learned := b.drivedbPresets[devicePath]
+	if len(user) == 0 && len(learned) == 0 {
+		return nil
+	}
+	overridden := make(map[string]bool, len(user))
+	args := make([]string, 0, 2*len(user)+len(learned))
+	for _, p := range user {
+		overridden[presetAttributeID(p)] = true
+		args = append(args, "-v", p)
+	}
+	for i := 0; i+1 < len(learned); i += 2 {
+		if learned[i] == "-v" && overridden[presetAttributeID(learned[i+1])] {
+			continue
+		}
+		args = append(args, learned[i], learned[i+1])
+	}
+	return args
+}
+
+// learnDrivedbPresets looks up presets in the embedded drivedb for drives that
+// the installed smartctl does not know about (in_smartctl_database=false),
+// typically because its own drive database is older than the embedded one.
+// The presets are applied to every subsequent query of devicePath. The lookup
+// result, including a miss, is cached so the regexp scan runs once per device.
+func (b *ExecBackend) learnDrivedbPresets(ctx context.Context, devicePath string, info *SMARTInfo) {
+	if b.disableDrivedbPresets || info == nil || info.ModelName == "" ||
+		info.InSmartctlDatabase == nil || *info.InSmartctlDatabase {
+		return
+	}
+	b.presetsMux.RLock()
+	_, seen := b.drivedbPresets[devicePath]
+	b.presetsMux.RUnlock()
+	if seen {
+		return
+	}
+	presets := lookupDrivePresets(info.ModelName, info.Firmware)
+	b.presetsMux.Lock()
+	if b.drivedbPresets == nil {
+		b.drivedbPresets = make(map[string][]string)
+	}
+	b.drivedbPresets[devicePath] = presets
+	b.presetsMux.Unlock()
+	if len(presets) > 0 {
+		b.logHandler.InfoContext(ctx, "Applying embedded drivedb presets", "devicePath", devicePath, "model", info.ModelName, "presets", presets)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package exec
+
+import osexec "os/exec"
+
+// setProcessGroup leaves cmd unchanged: without Unix process groups,
+// cancellation kills only the process itself.
+func setProcessGroup(cmd *osexec.Cmd) {}
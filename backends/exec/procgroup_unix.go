@@ -0,0 +1,23 @@
+//go:build unix
+
+package exec
+
+import (
+	osexec "os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in a process group of its own and makes
+// cancellation kill the whole group, so that children of smartctl or of a
+// wrapper script do not outlive it and hold its output open.
+func setProcessGroup(cmd *osexec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+			// kill(2) fails only when no member of the group could be
+			// signaled; kill at least the process itself.
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+//go:build unix
+
+package exec
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hangingSmartctl writes a smartctl stand-in that prints some output and
+// then waits for a child that sleeps: only killing the process group ends
+// it before commandWaitDelay.
+func hangingSmartctl(t *testing.T) string {
+	t.Helper()
+	return writeExecutable(t, t.TempDir(), "smartctl", `case "$1" in
+-V) echo "smartctl 7.4 2023-08-01 r5530 [x86_64-linux-6.1.0] (local build)" ;;
+*) echo '{"device": {"name": '; sleep 30 & wait ;;
+esac
+`)
+}
+
+func TestProcessGroup_TimeoutKillsChildren(t *testing.T) {
+	b, err := New(WithSmartctlPath(hangingSmartctl(t)), WithCommandTimeout(200*time.Millisecond))
+	require.NoError(t, err)
+
+	start := time.Now()
+	info, err := b.GetSMARTInfo(context.Background(), "/dev/sda")
+	elapsed := time.Since(start)
+
+	assert.Nil(t, info, "a killed smartctl is neither a standby drive nor a reason for a SAT probe")
+	var timeoutErr *CommandTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Less(t, elapsed, commandWaitDelay, "the sleeping child is killed with its parent")
+	assert.Equal(t, "{\"device\": {\"name\": \n", string(timeoutErr.Stdout), "partial output is kept")
+}
+
+func TestProcessGroup_CancelKillsChildren(t *testing.T) {
+	b, err := New(WithSmartctlPath(hangingSmartctl(t)), WithCommandTimeout(0))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = b.GetSMARTInfo(ctx, "/dev/sda")
+	elapsed := time.Since(start)
+
+	var canceledErr *CommandCanceledError
+	require.ErrorAs(t, err, &canceledErr)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrCommandTimeout)
+	assert.Less(t, elapsed, commandWaitDelay)
+	assert.Contains(t, string(canceledErr.Stdout), `"device"`)
+	assert.Contains(t, canceledErr.Args, "/dev/sda")
+}
+
+func TestProcessGroup_LegacyCommand(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	cmd := execCommander{}.Command(ctx, newMinimalTestLogger(), hangingSmartctl(t), "-H", "/dev/sda")
+
+	start := time.Now()
+	_, err := cmd.Output()
+	require.Error(t, err)
+	var exitErr interface{ ExitCode() int }
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Less(t, time.Since(start), 5*time.Second, "no WaitDelay: only the group kill ends the command")
+}
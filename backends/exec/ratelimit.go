@@ -0,0 +1,79 @@
+package exec
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithDeviceRateLimit limits smartctl invocations per device to one every
+// interval on average, allowing bursts of up to burst back-to-back calls
+// (values below 1 are treated as 1). Calls over the limit wait, bounded by
+// their context, instead of failing. Commands without a device (e.g. --scan)
+// are not limited. A zero interval, the default, disables the limit.
+func WithDeviceRateLimit(interval time.Duration, burst int) Option {
+	return func(b *ExecBackend) {
+		b.rateInterval = interval
+		b.rateBurst = max(burst, 1)
+	}
+}
+
+// deviceBucket is the token bucket of one device. tokens may go negative:
+// each pending caller has reserved a future token and waits for it.
+type deviceBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token at now and returns how long the caller must wait
+// before it becomes available.
+func (d *deviceBucket) reserve(now time.Time, interval time.Duration, burst int) time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if elapsed := now.Sub(d.last); elapsed > 0 {
+		d.tokens = min(float64(burst), d.tokens+float64(elapsed)/float64(interval))
+		d.last = now
+	}
+	d.tokens--
+	if d.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-d.tokens * float64(interval))
+}
+
+// cancel returns a token reserved by a caller that gave up waiting.
+func (d *deviceBucket) cancel(burst int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.tokens = min(float64(burst), d.tokens+1)
+}
+
+// waitDeviceRate blocks until the device of args may run another command
+// under WithDeviceRateLimit. It fails only when ctx is done while waiting.
+func (b *ExecBackend) waitDeviceRate(ctx context.Context, args []string) error {
+	if b.rateInterval <= 0 {
+		return nil
+	}
+	device := commandDevice(args)
+	if device == "" {
+		return nil
+	}
+	now := time.Now()
+	v, _ := b.rateBuckets.LoadOrStore(device, &deviceBucket{tokens: float64(b.rateBurst), last: now})
+	bucket := v.(*deviceBucket)
+	wait := bucket.reserve(now, b.rateInterval, b.rateBurst)
+	if wait <= 0 {
+		return nil
+	}
+	b.logHandler.DebugContext(ctx, "Rate limiting smartctl invocation", "devicePath", device, "wait", wait)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		bucket.cancel(b.rateBurst)
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,61 @@
+package exec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceBucket_Reserve(t *testing.T) {
+	start := time.Unix(1000, 0)
+	d := &deviceBucket{tokens: 2, last: start}
+
+	assert.Zero(t, d.reserve(start, time.Second, 2))
+	assert.Zero(t, d.reserve(start, time.Second, 2))
+	assert.Equal(t, time.Second, d.reserve(start, time.Second, 2))
+	assert.Equal(t, 2*time.Second, d.reserve(start, time.Second, 2), "pending callers queue up")
+
+	d.cancel(2)
+	assert.Equal(t, 2*time.Second, d.reserve(start, time.Second, 2), "a cancelled reservation frees its slot")
+
+	// After a long idle period the bucket refills only up to burst.
+	later := start.Add(time.Hour)
+	assert.Zero(t, d.reserve(later, time.Second, 2))
+	assert.Zero(t, d.reserve(later, time.Second, 2))
+	assert.Equal(t, time.Second, d.reserve(later, time.Second, 2))
+}
+
+func TestDeviceRateLimit_SpacesSameDevice(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithDeviceRateLimit(30*time.Millisecond, 1))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(t, b.AbortSelfTest(ctx, "/dev/sda"))
+	require.NoError(t, b.AbortSelfTest(ctx, "/dev/sdb"))
+	assert.Less(t, time.Since(start), 30*time.Millisecond, "devices have separate buckets")
+
+	require.NoError(t, b.AbortSelfTest(ctx, "/dev/sda"))
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestDeviceRateLimit_ContextCancelledWhileWaiting(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithDeviceRateLimit(time.Hour, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 1, b.rateBurst)
+
+	require.NoError(t, b.AbortSelfTest(context.Background(), "/dev/sda"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = b.AbortSelfTest(ctx, "/dev/sda")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Device-less commands are never limited.
+	_, err = b.run(context.Background(), "--scan-open", "--json")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,43 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// maxOpenRetryDelay caps the backoff between open retries.
+const maxOpenRetryDelay = 30 * time.Second
+
+// WithOpenRetry retries smartctl invocations that fail because the device is
+// busy (ErrDeviceBusy), as happens right after hotplug or while another tool
+// holds the device open. After the first failure it waits delay, doubling the
+// wait after every further failure up to 30s, and gives up after retries
+// additional attempts or when the call's context ends. Zero retries, the
+// default, returns the first failure.
+func WithOpenRetry(retries int, delay time.Duration) Option {
+	return func(b *ExecBackend) {
+		b.openRetries = max(retries, 0)
+		b.openRetryDelay = delay
+	}
+}
+
+// runWithOpenRetry runs args through runCommand, retrying busy devices under
+// WithOpenRetry.
+func (b *ExecBackend) runWithOpenRetry(ctx context.Context, args []string) (*CommandResult, error) {
+	res, err := b.runCommand(ctx, args)
+	delay := b.openRetryDelay
+	for attempt := 1; attempt <= b.openRetries && errors.Is(err, ErrDeviceBusy); attempt++ {
+		b.logHandler.DebugContext(ctx, "Device busy, retrying smartctl", "devicePath", commandDevice(args), "attempt", attempt, "wait", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return res, err
+		}
+		delay = min(2*delay, maxOpenRetryDelay)
+		res, err = b.runCommand(withFallback(ctx, FallbackOpenRetry), args)
+	}
+	return res, err
+}
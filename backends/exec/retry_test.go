@@ -0,0 +1,65 @@
+package exec
+
+import (
+	"context"
+	osexec "os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// busyCommander reports the device busy for the first busy calls.
+type busyCommander struct {
+	busy  int
+	calls int
+}
+
+func (c *busyCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	c.calls++
+	if c.calls <= c.busy {
+		return &CommandResult{ExitCode: 2, Stdout: []byte("Smartctl open device: /dev/sda failed: Device or resource busy\n")}, &osexec.ExitError{}
+	}
+	return &CommandResult{Stdout: []byte("PASSED")}, nil
+}
+
+func TestOpenRetry_RetriesBusyDevice(t *testing.T) {
+	bc := &busyCommander{busy: 2}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(bc), WithOpenRetry(3, time.Millisecond))
+	require.NoError(t, err)
+
+	healthy, err := b.CheckHealth(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.True(t, healthy)
+	assert.Equal(t, 3, bc.calls)
+}
+
+func TestOpenRetry_GivesUp(t *testing.T) {
+	bc := &busyCommander{busy: 10}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(bc), WithOpenRetry(2, time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = b.run(context.Background(), "-H", "/dev/sda")
+	assert.ErrorIs(t, err, ErrDeviceBusy)
+	assert.Equal(t, 3, bc.calls)
+
+	bc.calls = 0
+	b, err = New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(bc))
+	require.NoError(t, err)
+	_, err = b.run(context.Background(), "-H", "/dev/sda")
+	assert.ErrorIs(t, err, ErrDeviceBusy)
+	assert.Equal(t, 1, bc.calls, "no retries by default")
+}
+
+func TestOpenRetry_StopsWhenContextEnds(t *testing.T) {
+	bc := &busyCommander{busy: 10}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(bc), WithOpenRetry(5, time.Hour))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = b.run(ctx, "-H", "/dev/sda")
+	assert.ErrorIs(t, err, ErrDeviceBusy)
+	assert.Equal(t, 1, bc.calls)
+}
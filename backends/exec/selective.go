@@ -0,0 +1,78 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// RunSelectiveSelfTest starts an ATA selective self-test over up to
+// MaxSelectiveSpans LBA spans ("smartctl -t select,START-END ...").
+func (b *ExecBackend) RunSelectiveSelfTest(ctx context.Context, devicePath string, spans []LBASpan) error {
+	return b.RunSelectiveSelfTestWithOptions(ctx, devicePath, spans, SelectiveSelfTestOptions{})
+}
+
+// RunSelectiveSelfTestWithOptions starts an ATA selective self-test over
+// explicit spans or, with opts.Continue, over the spans following or repeating
+// those of the previous run ("-t select,next|redo|cont"). The remainder scan
+// and its power-up delay are set in the same invocation.
+func (b *ExecBackend) RunSelectiveSelfTestWithOptions(ctx context.Context, devicePath string, spans []LBASpan, opts SelectiveSelfTestOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	if err := opts.Validate(spans); err != nil {
+		return err
+	}
+	args := make([]string, 0, 2*len(spans)+4)
+	if opts.Continue != "" {
+		args = append(args, "-t", "select,"+string(opts.Continue))
+	}
+	for _, span := range spans {
+		args = append(args, "-t", "select,"+span.String())
+	}
+	if opts.AfterSelect != nil {
+		value := "afterselect,off"
+		if *opts.AfterSelect {
+			value = "afterselect,on"
+		}
+		args = append(args, "-t", value)
+	}
+	if opts.PendingMinutes != nil {
+		args = append(args, "-t", "pending,"+strconv.Itoa(*opts.PendingMinutes))
+	}
+	return b.startSelfTest(ctx, devicePath, "select", args)
+}
+
+// GetSelectiveSelfTestLog reads the ATA selective self-test log of devicePath
+// ("smartctl -l selective -j"). Devices without one fail with
+// ErrTestNotSupported.
+func (b *ExecBackend) GetSelectiveSelfTestLog(ctx context.Context, devicePath string) (*SelectiveSelfTestLog, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devicePath = NormalizeDevicePath(devicePath)
+	res, err := b.run(ctx, b.buildArgs(ctx, devicePath, "-l", "selective", "-j")...)
+	if err != nil {
+		var smartctlErr *SmartctlError
+		switch exitErr, ok := asExitError(err); {
+		case errors.As(err, &smartctlErr) && !smartctlErr.Status.ExecFailed():
+			// Only health bits are set; the log is still valid.
+		case ok && exitCodeOf(res, exitErr)&2 != 0 && !isOpenFailure(err):
+			return nil, fmt.Errorf("%w: %s", ErrDeviceInStandby, devicePath)
+		default:
+			return nil, fmt.Errorf("failed to get selective self-test log: %w", err)
+		}
+	}
+
+	var info SMARTInfo
+	if err := json.Unmarshal(res.Stdout, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse selective self-test log: %w", err)
+	}
+	if info.AtaSmartSelectiveSelfTestLog == nil {
+		return nil, fmt.Errorf("%w: %s has no selective self-test log", ErrTestNotSupported, devicePath)
+	}
+	return info.AtaSmartSelectiveSelfTestLog, nil
+}
@@ -0,0 +1,138 @@
+package exec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSelfTestWithOptions_Captive(t *testing.T) {
+	rec := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec), WithCommandTimeout(30*time.Second))
+	require.NoError(t, err)
+
+	require.NoError(t, b.RunSelfTestWithOptions(context.Background(), "/dev/sda", "short", SelfTestOptions{Captive: true}))
+	require.NoError(t, b.RunSelfTest(context.Background(), "/dev/sda", "short"))
+	require.Len(t, rec.requests, 2)
+
+	assert.Equal(t, []string{"-t", "short", "-C", "/dev/sda"}, rec.requests[0].Args)
+	assert.Zero(t, rec.requests[0].Timeout, "captive tests are bounded by ctx only")
+	assert.Equal(t, []string{"-t", "short", "/dev/sda"}, rec.requests[1].Args)
+	assert.Equal(t, 30*time.Second, rec.requests[1].Timeout)
+}
+
+func TestRunSelfTestWithOptions_InvalidType(t *testing.T) {
+	rec := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec))
+	require.NoError(t, err)
+
+	err = b.RunSelfTestWithOptions(context.Background(), "/dev/sda", "select", SelfTestOptions{Captive: true})
+	assert.ErrorContains(t, err, "invalid test type")
+	assert.Empty(t, rec.requests)
+}
+
+const selectiveLogJSON = `{
+	"device": {"name": "/dev/sda", "type": "sat"},
+	"ata_smart_selective_self_test_log": {
+		"revision": 1,
+		"table": [
+			{"lba_min": 0, "lba_max": 999999, "status": {"value": 0, "string": "Completed"}},
+			{"lba_min": 1000000, "lba_max": 1999999, "status": {"value": 249, "string": "Self_test_in_progress [90% left]", "remaining_percent": 90}},
+			{"lba_min": 0, "lba_max": 0, "status": {"value": 249, "string": "Not_testing"}},
+			{"lba_min": 0, "lba_max": 0, "status": {"value": 249, "string": "Not_testing"}},
+			{"lba_min": 0, "lba_max": 0, "status": {"value": 249, "string": "Not_testing"}}
+		],
+		"flags": {"value": 16, "remainder_scan_enabled": true},
+		"power_up_scan_resume_minutes": 0
+	}
+}`
+
+func TestRunSelectiveSelfTest(t *testing.T) {
+	rec := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec))
+	require.NoError(t, err)
+
+	spans := []LBASpan{{Start: 0, End: 999999}, {Start: 1000000, End: 1999999}}
+	require.NoError(t, b.RunSelectiveSelfTest(context.Background(), "/dev/sda", spans))
+	require.Len(t, rec.requests, 1)
+	assert.Equal(t, []string{"-t", "select,0-999999", "-t", "select,1000000-1999999", "/dev/sda"}, rec.requests[0].Args)
+}
+
+func TestRunSelectiveSelfTest_InvalidSpans(t *testing.T) {
+	rec := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec))
+	require.NoError(t, err)
+
+	assert.Error(t, b.RunSelectiveSelfTest(context.Background(), "/dev/sda", nil))
+	assert.ErrorContains(t, b.RunSelectiveSelfTest(context.Background(), "/dev/sda", []LBASpan{{Start: 10, End: 5}}), "start is after end")
+	assert.ErrorContains(t, b.RunSelectiveSelfTest(context.Background(), "/dev/sda", make([]LBASpan, 6)), "at most 5")
+	assert.Empty(t, rec.requests)
+}
+
+func TestGetSelectiveSelfTestLog(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -l selective -j --nocheck=standby /dev/sda":   {output: []byte(selectiveLogJSON)},
+		"/usr/sbin/smartctl -l selective -j --nocheck=standby /dev/nvme0": {output: []byte(`{"device": {"name": "/dev/nvme0", "type": "nvme"}}`)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	log, err := b.GetSelectiveSelfTestLog(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, []LBASpan{{Start: 0, End: 999999}, {Start: 1000000, End: 1999999}}, log.Spans())
+	require.NotNil(t, log.Table[1].Status.RemainingPercent)
+	assert.Equal(t, 90, *log.Table[1].Status.RemainingPercent)
+	assert.True(t, log.Flags.RemainderScanEnabled)
+
+	_, err = b.GetSelectiveSelfTestLog(context.Background(), "/dev/nvme0")
+	assert.ErrorIs(t, err, ErrTestNotSupported)
+}
+
+func TestRunSelectiveSelfTestWithOptions(t *testing.T) {
+	on, pending := true, 120
+	tests := []struct {
+		name  string
+		spans []LBASpan
+		opts  SelectiveSelfTestOptions
+		want  []string
+	}{
+		{"next", nil, SelectiveSelfTestOptions{Continue: SelectiveNext}, []string{"-t", "select,next", "/dev/sda"}},
+		{"redo", nil, SelectiveSelfTestOptions{Continue: SelectiveRedo}, []string{"-t", "select,redo", "/dev/sda"}},
+		{
+			"spans with remainder scan",
+			[]LBASpan{{Start: 0, End: 1000}},
+			SelectiveSelfTestOptions{AfterSelect: &on, PendingMinutes: &pending},
+			[]string{"-t", "select,0-1000", "-t", "afterselect,on", "-t", "pending,120", "/dev/sda"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingCommander{result: &CommandResult{}}
+			b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec))
+			require.NoError(t, err)
+
+			require.NoError(t, b.RunSelectiveSelfTestWithOptions(context.Background(), "/dev/sda", tt.spans, tt.opts))
+			require.Len(t, rec.requests, 1)
+			assert.Equal(t, tt.want, rec.requests[0].Args)
+		})
+	}
+}
+
+func TestRunSelectiveSelfTestWithOptions_Invalid(t *testing.T) {
+	rec := &recordingCommander{result: &CommandResult{}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec))
+	require.NoError(t, err)
+	ctx := context.Background()
+	tooLong := MaxPendingMinutes + 1
+
+	err = b.RunSelectiveSelfTestWithOptions(ctx, "/dev/sda", []LBASpan{{Start: 0, End: 10}}, SelectiveSelfTestOptions{Continue: SelectiveNext})
+	assert.ErrorContains(t, err, "cannot be combined")
+	err = b.RunSelectiveSelfTestWithOptions(ctx, "/dev/sda", nil, SelectiveSelfTestOptions{Continue: "skip"})
+	assert.ErrorContains(t, err, "invalid selective self-test continuation")
+	err = b.RunSelectiveSelfTestWithOptions(ctx, "/dev/sda", nil, SelectiveSelfTestOptions{Continue: SelectiveCont, PendingMinutes: &tooLong})
+	assert.ErrorContains(t, err, "invalid pending time")
+	assert.Empty(t, rec.requests)
+}
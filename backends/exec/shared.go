@@ -1,20 +1,37 @@
 package exec
 
-import smtypes "github.com/dianlight/smartmontools-go/internal/types"
+import (
+	"context"
+	"time"
+
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
+)
 
 // Shared interface aliases keep the exec backend decoupled from the root package.
 type (
 	LogAdapter       = smtypes.LogAdapter
 	Backend          = smtypes.Backend
 	DiscoveryBackend = smtypes.DiscoveryBackend
+	ScanBackend      = smtypes.ScanBackend
+	RawBackend       = smtypes.RawBackend
+	SmartctlBackend  = smtypes.SmartctlBackend
+	VersionBackend   = smtypes.VersionBackend
+	ExtendedBackend  = smtypes.ExtendedBackend
+	PowerModeBackend = smtypes.PowerModeBackend
 	Commander        = smtypes.Commander
+	ContextCommander = smtypes.ContextCommander
+	CommandRequest   = smtypes.CommandRequest
+	CommandResult    = smtypes.CommandResult
 	Cmd              = smtypes.Cmd
 )
 
 // Shared type aliases reuse the module's SMART domain model in the exec backend.
 type (
 	Device                     = smtypes.Device
+	SysfsInfo                  = smtypes.SysfsInfo
+	ScanOptions                = smtypes.ScanOptions
 	SMARTInfo                  = smtypes.SMARTInfo
+	ExtendedSMARTInfo          = smtypes.ExtendedSMARTInfo
 	NvmeControllerCapabilities = smtypes.NvmeControllerCapabilities
 	NvmeSmartHealth            = smtypes.NvmeSmartHealth
 	NvmeSmartTestLog           = smtypes.NvmeSmartTestLog
@@ -22,12 +39,18 @@ type (
 	SmartStatus                = smtypes.SmartStatus
 	SmartSupport               = smtypes.SmartSupport
 	AtaSmartData               = smtypes.AtaSmartData
+	AtaSmartErrorLog           = smtypes.AtaSmartErrorLog
+	AtaErrorLogSummary         = smtypes.AtaErrorLogSummary
 	StatusField                = smtypes.StatusField
 	OfflineDataCollection      = smtypes.OfflineDataCollection
 	PollingMinutes             = smtypes.PollingMinutes
 	SelfTest                   = smtypes.SelfTest
 	Capabilities               = smtypes.Capabilities
 	SelfTestInfo               = smtypes.SelfTestInfo
+	SelfTestOptions            = smtypes.SelfTestOptions
+	LBASpan                    = smtypes.LBASpan
+	SelectiveSelfTestLog       = smtypes.SelectiveSelfTestLog
+	SelectiveSelfTestOptions   = smtypes.SelectiveSelfTestOptions
 	NvmeOptionalAdminCommands  = smtypes.NvmeOptionalAdminCommands
 	CapabilitiesOutput         = smtypes.CapabilitiesOutput
 	SmartAttribute             = smtypes.SmartAttribute
@@ -36,9 +59,25 @@ type (
 	Temperature                = smtypes.Temperature
 	PowerOnTime                = smtypes.PowerOnTime
 	Message                    = smtypes.Message
+	Warning                    = smtypes.Warning
+	CommandTimeoutError        = smtypes.CommandTimeoutError
+	CommandCanceledError       = smtypes.CommandCanceledError
+	WarningCode                = smtypes.WarningCode
 	SmartctlInfo               = smtypes.SmartctlInfo
+	SmartctlVersionInfo        = smtypes.SmartctlVersionInfo
 	ProgressCallback           = smtypes.ProgressCallback
 	ExitCodeInfo               = smtypes.ExitCodeInfo
+	ExitStatus                 = smtypes.ExitStatus
+	PowerMode                  = smtypes.PowerMode
+	NoCheckPolicy              = smtypes.NoCheckPolicy
+	NoCheckMode                = smtypes.NoCheckMode
+	Tolerance                  = smtypes.Tolerance
+	CommandEvent               = smtypes.CommandEvent
+	CommandHook                = smtypes.CommandHook
+	DeviceOptions              = smtypes.DeviceOptions
+	SecureEraseInfo            = smtypes.SecureEraseInfo
+	SmartctlError              = smtypes.SmartctlError
+	VirtualDeviceError         = smtypes.VirtualDeviceError
 	DiscoveryResult            = smtypes.DiscoveryResult
 )
 
@@ -51,8 +90,132 @@ const (
 	SmartAttrTotalLBAsWritten  = smtypes.SmartAttrTotalLBAsWritten
 )
 
+// Sentinel errors shared with the root package.
+var (
+	ErrSmartNotSupported = smtypes.ErrSmartNotSupported
+	ErrPermissionDenied  = smtypes.ErrPermissionDenied
+	ErrDeviceNotFound    = smtypes.ErrDeviceNotFound
+	ErrDeviceBusy        = smtypes.ErrDeviceBusy
+	ErrDeviceInStandby   = smtypes.ErrDeviceInStandby
+	ErrTestNotSupported  = smtypes.ErrTestNotSupported
+
+	ErrFeatureNotSupported   = smtypes.ErrFeatureNotSupported
+	ErrUnsupportedJSONFormat = smtypes.ErrUnsupportedJSONFormat
+	ErrVirtualDevice         = smtypes.ErrVirtualDevice
+	ErrCommandTimeout        = smtypes.ErrCommandTimeout
+)
+
 var validSelfTestTypes = smtypes.ValidSelfTestTypes
 
+// Power modes shared with the root package.
+const (
+	PowerModeActive  = smtypes.PowerModeActive
+	PowerModeIdle    = smtypes.PowerModeIdle
+	PowerModeStandby = smtypes.PowerModeStandby
+	PowerModeSleep   = smtypes.PowerModeSleep
+	PowerModeUnknown = smtypes.PowerModeUnknown
+)
+
+// Warning codes shared with the root package.
+const (
+	WarningUnknownUSBBridge       = smtypes.WarningUnknownUSBBridge
+	WarningUnsupportedDevice      = smtypes.WarningUnsupportedDevice
+	WarningPermissionDenied       = smtypes.WarningPermissionDenied
+	WarningMandatoryCommandFailed = smtypes.WarningMandatoryCommandFailed
+	WarningOther                  = smtypes.WarningOther
+)
+
+// Selective self-test constants shared with the root package.
+const (
+	SelectiveNext = smtypes.SelectiveNext
+	SelectiveRedo = smtypes.SelectiveRedo
+	SelectiveCont = smtypes.SelectiveCont
+
+	MaxPendingMinutes = smtypes.MaxPendingMinutes
+)
+
+// NoCheck modes shared with the root package.
+const (
+	NoCheckNever   = smtypes.NoCheckNever
+	NoCheckSleep   = smtypes.NoCheckSleep
+	NoCheckStandby = smtypes.NoCheckStandby
+	NoCheckIdle    = smtypes.NoCheckIdle
+)
+
+// ContextWithNoCheck returns a context overriding the NoCheckPolicy of calls made with it.
+func ContextWithNoCheck(ctx context.Context, p NoCheckPolicy) context.Context {
+	return smtypes.ContextWithNoCheck(ctx, p)
+}
+
+// ParseNoCheckPolicy parses "never", "sleep", "standby", "idle" or "standby,N".
+func ParseNoCheckPolicy(s string) (NoCheckPolicy, error) {
+	return smtypes.ParseNoCheckPolicy(s)
+}
+
+// NoCheckFromContext returns the policy set by ContextWithNoCheck.
+func NoCheckFromContext(ctx context.Context) (NoCheckPolicy, bool) {
+	return smtypes.NoCheckFromContext(ctx)
+}
+
+// Tolerance levels shared with the root package.
+const (
+	ToleranceConservative   = smtypes.ToleranceConservative
+	ToleranceNormal         = smtypes.ToleranceNormal
+	TolerancePermissive     = smtypes.TolerancePermissive
+	ToleranceVeryPermissive = smtypes.ToleranceVeryPermissive
+)
+
+// ContextWithTolerance returns a context overriding the Tolerance of calls made with it.
+func ContextWithTolerance(ctx context.Context, t Tolerance) context.Context {
+	return smtypes.ContextWithTolerance(ctx, t)
+}
+
+// ToleranceFromContext returns the tolerance set by ContextWithTolerance.
+func ToleranceFromContext(ctx context.Context) (Tolerance, bool) {
+	return smtypes.ToleranceFromContext(ctx)
+}
+
+// ContextWithCommandTimeout returns a context overriding the command timeout of calls made with it.
+func ContextWithCommandTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return smtypes.ContextWithCommandTimeout(ctx, timeout)
+}
+
+// CommandTimeoutFromContext returns the timeout set by ContextWithCommandTimeout.
+func CommandTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	return smtypes.CommandTimeoutFromContext(ctx)
+}
+
+// Command event fallback reasons shared with the root package.
+const (
+	FallbackDeviceType = smtypes.FallbackDeviceType
+	FallbackScan       = smtypes.FallbackScan
+	FallbackOpenRetry  = smtypes.FallbackOpenRetry
+)
+
+func parsePowerMode(name string) PowerMode {
+	return smtypes.ParsePowerMode(name)
+}
+
+func standbyTimerValue(timeout time.Duration) (int, error) {
+	return smtypes.StandbyTimerValue(timeout)
+}
+
+func newExtendedSMARTInfo(info *SMARTInfo, raw []byte) (*ExtendedSMARTInfo, error) {
+	return smtypes.NewExtendedSMARTInfo(info, raw)
+}
+
 func populateSelfTestInfo(info *SelfTestInfo, ata *AtaSmartData, nvmeCaps *NvmeControllerCapabilities, nvmeOptional *NvmeOptionalAdminCommands) {
 	smtypes.PopulateSelfTestInfo(info, ata, nvmeCaps, nvmeOptional)
 }
+
+func newSecureEraseInfo(word89, word90, word128 uint16) *SecureEraseInfo {
+	return smtypes.NewSecureEraseInfo(word89, word90, word128)
+}
+
+func classifyMessages(s *SmartctlInfo) []Warning {
+	return smtypes.ClassifyMessages(s)
+}
+
+func classifyOpenFailure(output string) error {
+	return smtypes.ClassifyOpenFailure(output)
+}
@@ -0,0 +1,142 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithSharedQueries makes GetSMARTInfo, GetSMARTInfoRaw, GetAvailableSelfTests
+// and CheckHealth read one "smartctl -x -j" invocation per device and reuse
+// its result for window, so a caller asking for info, health and self-test
+// capabilities in a row wakes the disk once instead of three times. Concurrent
+// calls for a device wait for the invocation already in flight. Failed
+// invocations are not reused, and self-test and SMART enable/disable calls
+// drop the device's result. Calls overriding the --nocheck policy through
+// ContextWithNoCheck or the tolerance through ContextWithTolerance always run
+// their own invocation. Zero, the default, runs a separate command per
+// call. Text output mode (smartctl 6.x) is not affected.
+func WithSharedQueries(window time.Duration) Option {
+	return func(b *ExecBackend) {
+		b.sharedWindow = window
+	}
+}
+
+// sharedQuery is one -x invocation whose result is handed to every caller
+// arriving while it runs or within the sharing window after it completes.
+type sharedQuery struct {
+	done    chan struct{} // closed once the fields below are set
+	info    *SMARTInfo
+	raw     []byte
+	usedSAT bool
+	err     error
+	at      time.Time
+}
+
+// result returns a shallow copy of the shared SMARTInfo so callers cannot
+// modify each other's top-level fields.
+func (q *sharedQuery) result() (*SMARTInfo, []byte, bool, error) {
+	info := q.info
+	if info != nil {
+		cp := *info
+		info = &cp
+	}
+	return info, q.raw, q.usedSAT, q.err
+}
+
+// sharing reports whether queries are served from shared -x invocations.
+func (b *ExecBackend) sharing() bool {
+	return b.sharedWindow > 0 && !b.textOutput
+}
+
+// smartInfoFlag is the smartctl flag selecting the SMART info sections: -x
+// when queries are shared, since it also covers capabilities and health.
+func (b *ExecBackend) smartInfoFlag() string {
+	if b.sharing() {
+		return "-x"
+	}
+	return "-a"
+}
+
+// querySMARTInfo returns the SMART information of devicePath, from a shared
+// invocation when WithSharedQueries is enabled and directly otherwise.
+func (b *ExecBackend) querySMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, []byte, bool, error) {
+	_, noCheckOverride := NoCheckFromContext(ctx)
+	_, toleranceOverride := ToleranceFromContext(ctx)
+	if !b.sharing() || noCheckOverride || toleranceOverride {
+		return b.getSMARTInfoInternal(ctx, devicePath)
+	}
+	for {
+		b.sharedMux.Lock()
+		q, ok := b.sharedQueries[devicePath]
+		if ok {
+			select {
+			case <-q.done:
+				ok = q.err == nil && time.Since(q.at) < b.sharedWindow
+			default:
+			}
+		}
+		if !ok {
+			q = &sharedQuery{done: make(chan struct{})}
+			b.sharedQueries[devicePath] = q
+			b.sharedMux.Unlock()
+			q.info, q.raw, q.usedSAT, q.err = b.getSMARTInfoInternal(ctx, devicePath)
+			q.at = time.Now()
+			close(q.done)
+			return q.result()
+		}
+		b.sharedMux.Unlock()
+
+		select {
+		case <-q.done:
+		case <-ctx.Done():
+			return nil, nil, false, ctx.Err()
+		}
+		// The invocation was cancelled by its caller's context, not ours: retry.
+		if q.err != nil && ctx.Err() == nil &&
+			(errors.Is(q.err, context.Canceled) || errors.Is(q.err, context.DeadlineExceeded)) {
+			continue
+		}
+		return q.result()
+	}
+}
+
+// forgetSharedQuery drops the shared result of devicePath after a command
+// that changes the device state.
+func (b *ExecBackend) forgetSharedQuery(devicePath string) {
+	b.sharedMux.Lock()
+	defer b.sharedMux.Unlock()
+	delete(b.sharedQueries, devicePath)
+}
+
+// sharedSelfTests derives GetAvailableSelfTests from a shared -x invocation.
+func (b *ExecBackend) sharedSelfTests(ctx context.Context, devicePath string) (*SelfTestInfo, error) {
+	info, _, _, err := b.querySMARTInfo(ctx, devicePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capabilities: %w", err)
+	}
+	if info.InStandby {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceInStandby, devicePath)
+	}
+	tests := &SelfTestInfo{
+		Available: []string{},
+		Durations: make(map[string]int),
+	}
+	populateSelfTestInfo(tests, info.AtaSmartData, info.NvmeControllerCapabilities, info.NvmeOptionalAdminCommands)
+	return tests, nil
+}
+
+// sharedHealth derives CheckHealth from a shared -x invocation. Like the
+// dedicated -H query, a device in standby reports false without an error.
+func (b *ExecBackend) sharedHealth(ctx context.Context, devicePath string) (bool, error) {
+	info, _, _, err := b.querySMARTInfo(ctx, devicePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to check health: %w", err)
+	}
+	if info.InStandby {
+		b.logHandler.DebugContext(ctx, "Device in standby mode, cannot check health", "devicePath", devicePath)
+		return false, nil
+	}
+	return info.SmartStatus != nil && info.SmartStatus.Passed, nil
+}
@@ -0,0 +1,132 @@
+package exec
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sharedQueryJSON = `{
+	"device": {"name": "/dev/sda", "type": "sat"},
+	"model_name": "Shared Disk",
+	"smart_status": {"passed": true},
+	"ata_smart_data": {
+		"capabilities": {"self_tests_supported": true, "exec_offline_immediate_supported": true},
+		"self_test": {"polling_minutes": {"short": 2, "extended": 90}}
+	}
+}`
+
+// sharedCommander answers every smartctl call with sharedQueryJSON after a
+// short delay and counts the -x invocations.
+type sharedCommander struct {
+	mu    sync.Mutex
+	args  []string
+	xRuns atomic.Int32
+}
+
+func (c *sharedCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	c.mu.Lock()
+	c.args = append(c.args, strings.Join(req.Args, " "))
+	c.mu.Unlock()
+	if req.Args[0] == "-x" {
+		c.xRuns.Add(1)
+	}
+	time.Sleep(10 * time.Millisecond)
+	return &CommandResult{Stdout: []byte(sharedQueryJSON)}, nil
+}
+
+func TestSharedQueries_OneInvocationServesAllQueries(t *testing.T) {
+	cc := &sharedCommander{}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(cc), WithSharedQueries(time.Minute))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	info, err := b.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "Shared Disk", info.ModelName)
+	info.ModelName = "mutated"
+
+	healthy, err := b.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.True(t, healthy)
+
+	tests, err := b.GetAvailableSelfTests(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"short", "long", "offline"}, tests.Available)
+	assert.Equal(t, 90, tests.Durations["long"])
+
+	again, raw, err := b.GetSMARTInfoRaw(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "Shared Disk", again.ModelName, "callers get their own copy")
+	assert.JSONEq(t, sharedQueryJSON, string(raw))
+
+	assert.Equal(t, []string{"-x -j --nocheck=standby /dev/sda"}, cc.args)
+}
+
+func TestSharedQueries_ConcurrentCallersWaitForInFlight(t *testing.T) {
+	cc := &sharedCommander{}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(cc), WithSharedQueries(time.Minute))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.GetSMARTInfo(context.Background(), "/dev/sda")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), cc.xRuns.Load())
+}
+
+func TestSharedQueries_ExpiryAndInvalidation(t *testing.T) {
+	cc := &sharedCommander{}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(cc), WithSharedQueries(20*time.Millisecond))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = b.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+	_, err = b.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), cc.xRuns.Load(), "results expire after the window")
+
+	require.NoError(t, b.AbortSelfTest(ctx, "/dev/sda"))
+	_, err = b.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), cc.xRuns.Load(), "state changes drop the shared result")
+
+	require.NoError(t, b.WakeDevice(ctx, "/dev/sda"))
+	_, err = b.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), cc.xRuns.Load(), "a wake drops the shared result")
+
+	_, _, err = b.RunSmartctl(ctx, "-s", "wcache,off", "/dev/sda")
+	require.NoError(t, err)
+	_, err = b.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), cc.xRuns.Load(), "commands changing the device drop the shared result")
+}
+
+func TestSharedQueries_DisabledByDefault(t *testing.T) {
+	cc := &sharedCommander{}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(cc))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = b.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, err = b.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-a -j --nocheck=standby /dev/sda", "-H --nocheck=standby -d sat /dev/sda"}, cc.args)
+	assert.Zero(t, cc.xRuns.Load())
+}
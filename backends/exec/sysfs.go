@@ -0,0 +1,92 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dianlight/smartmontools-go/internal/sysfs"
+)
+
+// Roots of the Linux sysfs and udev database. Tests point them at fixtures.
+var (
+	sysfsDir    = "/sys"
+	udevDataDir = "/run/udev/data"
+)
+
+// addSysfsInfo fills Device.Sysfs for the devices found in sysfs. It only
+// runs on Linux with the default commander: a custom commander may run
+// smartctl on another host, whose devices the local sysfs knows nothing of.
+func (b *ExecBackend) addSysfsInfo(devices []Device) {
+	if goos != "linux" || !b.defaultCommander {
+		return
+	}
+	for i := range devices {
+		devices[i].Sysfs = readSysfsInfo(devices[i].Name)
+	}
+}
+
+// readSysfsInfo returns what sysfs and udev report about devicePath, or nil
+// when it is neither a block device nor an NVMe controller. smartctl scans
+// NVMe drives as their controller (/dev/nvme0), which is described under
+// /sys/class/nvme instead of /sys/class/block.
+func readSysfsInfo(devicePath string) *SysfsInfo {
+	name := filepath.Base(devicePath)
+	if dir := filepath.Join(sysfsDir, "class", "block", name); sysfs.Exists(dir) {
+		info := &SysfsInfo{
+			Driver:    linkName(filepath.Join(dir, "device", "driver")),
+			Scheduler: activeScheduler(sysfs.ReadLine(filepath.Join(dir, "queue", "scheduler"))),
+			Removable: sysfs.ReadLine(filepath.Join(dir, "removable")) == "1",
+			Vendor:    sysfs.ReadLine(filepath.Join(dir, "device", "vendor")),
+			Model:     sysfs.ReadLine(filepath.Join(dir, "device", "model")),
+		}
+		if info.Driver == "" {
+			// NVMe namespaces: device is the controller, bound one level up.
+			info.Driver = linkName(filepath.Join(dir, "device", "device", "driver"))
+		}
+		switch sysfs.ReadLine(filepath.Join(dir, "queue", "rotational")) {
+		case "0":
+			info.Rotational = new(bool)
+		case "1":
+			rotational := true
+			info.Rotational = &rotational
+		}
+		if sectors, err := strconv.ParseInt(sysfs.ReadLine(filepath.Join(dir, "size")), 10, 64); err == nil {
+			info.Size = sectors * 512 // sysfs counts 512-byte sectors whatever the block size
+		}
+		info.Transport = sysfs.UdevProperties(udevDataDir, sysfs.ReadLine(filepath.Join(dir, "dev")))["ID_BUS"]
+		if info.Transport == "" {
+			info.Transport = sysfs.ReadLine(filepath.Join(dir, "device", "transport"))
+		}
+		return info
+	}
+	if dir := filepath.Join(sysfsDir, "class", "nvme", name); sysfs.Exists(dir) {
+		return &SysfsInfo{
+			Driver:    linkName(filepath.Join(dir, "device", "driver")),
+			Transport: sysfs.ReadLine(filepath.Join(dir, "transport")),
+			Model:     sysfs.ReadLine(filepath.Join(dir, "model")),
+		}
+	}
+	return nil
+}
+
+// activeScheduler picks the bracketed entry of a queue/scheduler list such as
+// "mq-deadline kyber [bfq] none".
+func activeScheduler(list string) string {
+	for _, s := range strings.Fields(list) {
+		if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+			return strings.Trim(s, "[]")
+		}
+	}
+	return list
+}
+
+// linkName returns the base name of the symlink target at path, or "".
+func linkName(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
@@ -0,0 +1,98 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSysfs lays out a SATA disk, a USB stick, an NVMe drive and an NVMe/TCP
+// namespace behind a multipath subsystem, and points sysfsDir and udevDataDir
+// at them.
+func fakeSysfs(t *testing.T) {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"sys/class/block/sda/device/vendor":              "ATA     \n",
+		"sys/class/block/sda/device/model":               "WDC WD40EFRX-68N\n",
+		"sys/class/block/sda/queue/scheduler":            "mq-deadline kyber [bfq] none\n",
+		"sys/class/block/sda/queue/rotational":           "1\n",
+		"sys/class/block/sda/removable":                  "0\n",
+		"sys/class/block/sda/size":                       "7814037168\n",
+		"sys/class/block/sda/dev":                        "8:0\n",
+		"sys/class/block/sdb/device/vendor":              "SanDisk\n",
+		"sys/class/block/sdb/queue/scheduler":            "[none] mq-deadline\n",
+		"sys/class/block/sdb/removable":                  "1\n",
+		"sys/class/block/sdb/dev":                        "8:16\n",
+		"sys/class/block/nvme0n1/queue/rotational":       "0\n",
+		"sys/class/block/nvme0n1/device/model":           "Samsung SSD 980 PRO 1TB\n",
+		"sys/class/block/nvme0n1/device/transport":       "pcie\n",
+		"sys/class/nvme/nvme0/model":                     "Samsung SSD 980 PRO 1TB                 \n",
+		"sys/class/nvme/nvme0/transport":                 "pcie\n",
+		"sys/class/nvme/nvme1/transport":                 "tcp\n",
+		"sys/class/block/nvme1n1/device/nvme1/transport": "tcp\n",
+		"udev/b8:0":  "E:ID_BUS=ata\nE:ID_MODEL=WDC_WD40EFRX-68N32N0\n",
+		"udev/b8:16": "E:ID_BUS=usb\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	links := map[string]string{
+		"sys/class/block/sda/device/driver":            "../../../bus/scsi/drivers/sd",
+		"sys/class/block/nvme0n1/device/device/driver": "../../../../bus/pci/drivers/nvme",
+		"sys/class/nvme/nvme0/device/driver":           "../../../bus/pci/drivers/nvme",
+	}
+	for link, target := range links {
+		path := filepath.Join(root, link)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.Symlink(target, path))
+	}
+	oldSys, oldUdev := sysfsDir, udevDataDir
+	sysfsDir, udevDataDir = filepath.Join(root, "sys"), filepath.Join(root, "udev")
+	t.Cleanup(func() { sysfsDir, udevDataDir = oldSys, oldUdev })
+}
+
+func TestReadSysfsInfo(t *testing.T) {
+	fakeSysfs(t)
+	rotational, solid := true, false
+
+	assert.Equal(t, &SysfsInfo{
+		Driver:     "sd",
+		Transport:  "ata",
+		Scheduler:  "bfq",
+		Rotational: &rotational,
+		Vendor:     "ATA",
+		Model:      "WDC WD40EFRX-68N",
+		Size:       4000787030016,
+	}, readSysfsInfo("/dev/sda"))
+	assert.Equal(t, &SysfsInfo{Transport: "usb", Scheduler: "none", Removable: true, Vendor: "SanDisk"}, readSysfsInfo("/dev/sdb"))
+	assert.Equal(t, &SysfsInfo{Driver: "nvme", Transport: "pcie", Rotational: &solid, Model: "Samsung SSD 980 PRO 1TB"}, readSysfsInfo("/dev/nvme0n1"))
+	assert.Equal(t, &SysfsInfo{Driver: "nvme", Transport: "pcie", Model: "Samsung SSD 980 PRO 1TB"}, readSysfsInfo("/dev/nvme0"))
+	assert.Nil(t, readSysfsInfo("/dev/bus/0"))
+}
+
+func TestAddSysfsInfo(t *testing.T) {
+	fakeSysfs(t)
+	oldGOOS := goos
+	t.Cleanup(func() { goos = oldGOOS })
+
+	goos = "linux"
+	devices := []Device{{Name: "/dev/sda", Type: "sat"}, {Name: "/dev/sdz", Type: "scsi"}}
+	(&ExecBackend{defaultCommander: true}).addSysfsInfo(devices)
+	require.NotNil(t, devices[0].Sysfs)
+	assert.Equal(t, "sd", devices[0].Sysfs.Driver)
+	assert.Nil(t, devices[1].Sysfs)
+
+	devices = []Device{{Name: "/dev/sda"}}
+	(&ExecBackend{}).addSysfsInfo(devices)
+	assert.Nil(t, devices[0].Sysfs, "custom commanders may run smartctl elsewhere")
+
+	goos = "freebsd"
+	(&ExecBackend{defaultCommander: true}).addSysfsInfo(devices)
+	assert.Nil(t, devices[0].Sysfs)
+}
@@ -0,0 +1,535 @@
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Text output mode is the degraded fallback for smartctl releases older than
+// 7.0, which cannot print JSON. Identity, health, ATA attributes, self-test
+// status and the basic NVMe and SCSI health fields are recovered from the
+// classic report; everything else stays unset. The SAT and USB bridge retries
+// of the JSON path are not attempted.
+
+var (
+	// textAttributeRe matches a row of the "Vendor Specific SMART Attributes"
+	// table: ID, name, flag, value, worst, thresh, type, updated, when failed, raw.
+	textAttributeRe = regexp.MustCompile(`^\s*(\d+)\s+(\S+)\s+0x([0-9a-fA-F]+)\s+(\d+)\s+(\d+)\s+(\d+|---)\s+\S+\s+\S+\s+(\S+)\s+(.*?)\s*$`)
+	// textParenValueRe extracts the value of "(   2) minutes." or "( 249)	Self-test ...".
+	textParenValueRe = regexp.MustCompile(`\(\s*(0x[0-9a-fA-F]+|\d+)\s*\)`)
+	// textLeadingIntRe extracts the leading, possibly comma-grouped, integer of a value.
+	textLeadingIntRe = regexp.MustCompile(`^\s*(-?[\d,]+)`)
+	// textScanCommentRe splits the comment of a scan line, "/dev/sda [SAT],
+	// ATA device", into info name, protocol and --scan-open's open error.
+	textScanCommentRe = regexp.MustCompile(`^(.*), (\S+) device(?: open failed: (.*))?$`)
+)
+
+// parseSmartctlText builds a SMARTInfo from the classic text report of
+// "smartctl -a" (or -i/-c/-H subsets). Fields missing from output are left
+// unset; Device, exit status and computed fields are filled by the caller.
+func parseSmartctlText(output []byte) *SMARTInfo {
+	info := &SMARTInfo{}
+	if major, minor, err := parseSmartctlVersion(string(output)); err == nil {
+		info.Smartctl = &SmartctlInfo{Version: []int{major, minor}}
+	}
+
+	var (
+		inAttributes bool
+		inNvmeLog    bool
+		pollingFor   string
+		sectorSize   int64
+		scsiVendor   string
+	)
+	ata := func() *AtaSmartData {
+		if info.AtaSmartData == nil {
+			info.AtaSmartData = &AtaSmartData{}
+		}
+		return info.AtaSmartData
+	}
+	selfTest := func() *SelfTest {
+		if ata().SelfTest == nil {
+			info.AtaSmartData.SelfTest = &SelfTest{}
+		}
+		return info.AtaSmartData.SelfTest
+	}
+	capabilities := func() *Capabilities {
+		if ata().Capabilities == nil {
+			info.AtaSmartData.Capabilities = &Capabilities{}
+		}
+		return info.AtaSmartData.Capabilities
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if inAttributes {
+			if trimmed == "" {
+				inAttributes = false
+				continue
+			}
+			if attr, ok := parseTextAttribute(line); ok {
+				ata().Table = append(ata().Table, attr)
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "ID# ATTRIBUTE_NAME") {
+			inAttributes = true
+			continue
+		}
+		if strings.HasPrefix(trimmed, "SMART/Health Information (NVMe Log") {
+			inNvmeLog = true
+			info.NvmeSmartHealth = &NvmeSmartHealth{}
+			continue
+		}
+		if trimmed == "" {
+			inNvmeLog = false
+		}
+
+		// Capability flags are printed as continuation lines of the
+		// "Offline data collection capabilities" block, the first one after
+		// the "capabilities: (0x53)" label.
+		if strings.HasPrefix(trimmed, "capabilities:") {
+			_, flag, _ := strings.Cut(trimmed, ")")
+			trimmed = strings.TrimSpace(flag)
+		}
+		switch trimmed {
+		case "SMART execute Offline immediate.":
+			capabilities().ExecOfflineImmediate = true
+			continue
+		case "Self-test supported.":
+			capabilities().SelfTestsSupported = true
+			continue
+		case "Conveyance Self-test supported.":
+			capabilities().ConveyanceSelfTestSupported = true
+			continue
+		}
+
+		// Polling times span two lines: the test name, then the minutes.
+		switch {
+		case strings.HasPrefix(trimmed, "Short self-test routine"):
+			pollingFor = "short"
+		case strings.HasPrefix(trimmed, "Extended self-test routine"):
+			pollingFor = "extended"
+		case strings.HasPrefix(trimmed, "Conveyance self-test routine"):
+			pollingFor = "conveyance"
+		}
+		if strings.HasPrefix(trimmed, "recommended polling time:") {
+			if minutes, ok := textParenInt(trimmed); ok {
+				st := selfTest()
+				if st.PollingMinutes == nil {
+					st.PollingMinutes = &PollingMinutes{}
+				}
+				switch pollingFor {
+				case "short":
+					st.PollingMinutes.Short = minutes
+				case "extended":
+					st.PollingMinutes.Extended = minutes
+				case "conveyance":
+					st.PollingMinutes.Conveyance = minutes
+				}
+			}
+			pollingFor = ""
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if inNvmeLog {
+			parseNvmeTextField(info, key, value)
+			continue
+		}
+		switch key {
+		case "Model Family":
+			info.ModelFamily = value
+		case "Device Model", "Model Number":
+			info.ModelName = value
+		case "Vendor":
+			scsiVendor = value
+		case "Product":
+			info.ModelName = strings.TrimSpace(scsiVendor + " " + value)
+		case "Serial Number", "Serial number":
+			info.SerialNumber = value
+		case "Firmware Version", "Revision":
+			info.Firmware = value
+		case "User Capacity", "Total NVM Capacity":
+			if n, ok := textLeadingInt(value); ok {
+				info.UserCapacity = &UserCapacity{Bytes: n}
+			}
+		case "Sector Size", "Logical block size":
+			sectorSize, _ = textLeadingInt(value)
+		case "Rotation Rate":
+			rate := 0
+			if !strings.HasPrefix(value, "Solid State") {
+				n, ok := textLeadingInt(value)
+				if !ok {
+					continue
+				}
+				rate = int(n)
+			}
+			info.RotationRate = &rate
+		case "Device is":
+			known := strings.HasPrefix(value, "In smartctl database")
+			info.InSmartctlDatabase = &known
+		case "SMART support is":
+			if info.SmartSupport == nil {
+				info.SmartSupport = &SmartSupport{}
+			}
+			switch {
+			case strings.HasPrefix(value, "Available"):
+				info.SmartSupport.Available = true
+			case strings.HasPrefix(value, "Enabled"):
+				info.SmartSupport.Available = true
+				info.SmartSupport.Enabled = true
+			}
+		case "SMART overall-health self-assessment test result", "SMART Health Status":
+			info.SmartStatus = &SmartStatus{Passed: value == "PASSED" || value == "OK"}
+		case "Self-test execution status":
+			if v, ok := textParenInt(value); ok {
+				status := &StatusField{Value: v}
+				if _, text, found := strings.Cut(value, ")"); found {
+					status.String = strings.TrimSpace(text)
+				}
+				if v>>4 == 0x0f {
+					remaining := (v & 0x0f) * 10
+					status.RemainingPercent = &remaining
+				}
+				selfTest().Status = status
+			}
+		case "Current Drive Temperature":
+			if n, ok := textLeadingInt(value); ok {
+				info.Temperature = &Temperature{Current: int(n)}
+			}
+		case "Accumulated power on time, hours":
+			// "Accumulated power on time, hours:minutes 1234:56"
+			hours, minutes, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(value, "minutes")), ":")
+			if n, ok := textLeadingInt(hours); ok {
+				info.PowerOnTime = &PowerOnTime{Hours: int(n)}
+				if m, ok := textLeadingInt(minutes); ok {
+					info.PowerOnTime.Minutes = int(m)
+				}
+			}
+		}
+	}
+
+	if info.UserCapacity != nil && sectorSize > 0 {
+		info.UserCapacity.Blocks = info.UserCapacity.Bytes / sectorSize
+	}
+	if info.NvmeSmartHealth != nil {
+		info.Device.Type = "nvme"
+	}
+	applyTextAttributeSummaries(info)
+	return info
+}
+
+// parseNvmeTextField applies one "Key: value" line of the NVMe SMART/Health
+// Information log to info.
+func parseNvmeTextField(info *SMARTInfo, key, value string) {
+	nvme := info.NvmeSmartHealth
+	switch key {
+	case "Critical Warning":
+		if v, err := strconv.ParseInt(value, 0, 32); err == nil {
+			nvme.CriticalWarning = int(v)
+		}
+	case "Temperature":
+		if n, ok := textLeadingInt(value); ok {
+			nvme.Temperature = int(n)
+			info.Temperature = &Temperature{Current: int(n)}
+		}
+	case "Available Spare":
+		if n, ok := textLeadingInt(value); ok {
+			nvme.AvailableSpare = int(n)
+		}
+	case "Available Spare Threshold":
+		if n, ok := textLeadingInt(value); ok {
+			nvme.AvailableSpareThresh = int(n)
+		}
+	case "Percentage Used":
+		if n, ok := textLeadingInt(value); ok {
+			nvme.PercentageUsed = int(n)
+		}
+	case "Data Units Read":
+		nvme.DataUnitsRead, _ = textLeadingInt(value)
+	case "Data Units Written":
+		nvme.DataUnitsWritten, _ = textLeadingInt(value)
+	case "Power Cycles":
+		if n, ok := textLeadingInt(value); ok {
+			nvme.PowerCycles = n
+			info.PowerCycleCount = int(n)
+		}
+	case "Power On Hours":
+		if n, ok := textLeadingInt(value); ok {
+			nvme.PowerOnHours = n
+			info.PowerOnTime = &PowerOnTime{Hours: int(n)}
+		}
+	case "Unsafe Shutdowns":
+		nvme.UnsafeShutdowns, _ = textLeadingInt(value)
+	case "Media and Data Integrity Errors":
+		nvme.MediaErrors, _ = textLeadingInt(value)
+	case "Error Information Log Entries":
+		nvme.NumErrLogEntries, _ = textLeadingInt(value)
+	}
+}
+
+// parseTextAttribute parses one row of the ATA attribute table.
+func parseTextAttribute(line string) (SmartAttribute, bool) {
+	m := textAttributeRe.FindStringSubmatch(line)
+	if m == nil {
+		return SmartAttribute{}, false
+	}
+	id, _ := strconv.Atoi(m[1])
+	flags, _ := strconv.ParseInt(m[3], 16, 32)
+	value, _ := strconv.Atoi(m[4])
+	worst, _ := strconv.Atoi(m[5])
+	thresh, _ := strconv.Atoi(m[6])
+	attr := SmartAttribute{
+		ID:     id,
+		Name:   m[2],
+		Value:  value,
+		Worst:  worst,
+		Thresh: thresh,
+		Flags: Flags{
+			Value:         int(flags),
+			PreFailure:    flags&0x01 != 0,
+			UpdatedOnline: flags&0x02 != 0,
+			Performance:   flags&0x04 != 0,
+			ErrorRate:     flags&0x08 != 0,
+			EventCount:    flags&0x10 != 0,
+			AutoKeep:      flags&0x20 != 0,
+		},
+		Raw: Raw{String: m[8]},
+	}
+	switch m[7] {
+	case "FAILING_NOW":
+		attr.WhenFailed = "now"
+	case "In_the_past":
+		attr.WhenFailed = "past"
+	}
+	attr.Raw.Value, _ = textLeadingInt(m[8])
+	return attr, true
+}
+
+// applyTextAttributeSummaries fills the top-level temperature, power-on time
+// and power cycle count that smartctl's JSON output derives from attributes.
+func applyTextAttributeSummaries(info *SMARTInfo) {
+	if info.AtaSmartData == nil {
+		return
+	}
+	for _, attr := range info.AtaSmartData.Table {
+		switch attr.ID {
+		case 9:
+			if info.PowerOnTime == nil {
+				if d, ok := attr.Raw.Duration(); ok {
+					info.PowerOnTime = &PowerOnTime{Hours: int(d / time.Hour), Minutes: int(d % time.Hour / time.Minute)}
+				} else {
+					info.PowerOnTime = &PowerOnTime{Hours: int(attr.Raw.Value)}
+				}
+			}
+		case 12:
+			info.PowerCycleCount = int(attr.Raw.Value)
+		case 190, 194:
+			if info.Temperature == nil || attr.ID == 194 {
+				info.Temperature = &Temperature{Current: int(attr.Raw.Value)}
+			}
+		}
+	}
+}
+
+// parseScanText parses "smartctl --scan" lines such as
+// "/dev/sda -d sat # /dev/sda [SAT], ATA device". --scan-open comments out
+// the devices it cannot open, "# /dev/sdb -d sat # /dev/sdb [SAT], ATA device
+// open failed: Permission denied", which are returned with their OpenError.
+func parseScanText(output []byte) []Device {
+	var devices []Device
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimPrefix(scanner.Text(), "# ")
+		line, comment, _ := strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+			continue
+		}
+		dev := Device{Name: fields[0]}
+		if len(fields) >= 3 && fields[1] == "-d" {
+			dev.Type = fields[2]
+		}
+		if m := textScanCommentRe.FindStringSubmatch(strings.TrimSpace(comment)); m != nil {
+			dev.InfoName, dev.Protocol, dev.OpenError = m[1], m[2], m[3]
+		} else if strings.HasPrefix(scanner.Text(), "#") {
+			continue
+		}
+		devices = append(devices, dev)
+	}
+	return devices
+}
+
+func textParenInt(s string) (int, bool) {
+	m := textParenValueRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(m[1], 0, 32)
+	return int(v), err == nil
+}
+
+func textLeadingInt(s string) (int64, bool) {
+	m := textLeadingIntRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(strings.ReplaceAll(m[1], ",", ""), 10, 64)
+	return v, err == nil
+}
+
+// runText runs smartctl with flags for devicePath and parses the text report.
+// A device in standby is returned as a placeholder with InStandby set.
+func (b *ExecBackend) runText(ctx context.Context, devicePath string, flags ...string) (*SMARTInfo, error) {
+	res, err := b.run(ctx, b.buildArgs(ctx, devicePath, flags...)...)
+	exitCode := 0
+	if err != nil {
+		exitErr, ok := asExitError(err)
+		if !ok || isOpenFailure(err) {
+			return nil, err
+		}
+		exitCode = exitCodeOf(res, exitErr)
+		if exitCode > 0 && exitCode&0x02 != 0 {
+			return &SMARTInfo{Device: Device{Name: devicePath}, InStandby: true}, nil
+		}
+		if len(res.Stdout) == 0 {
+			return nil, err
+		}
+	}
+
+	info := parseSmartctlText(res.Stdout)
+	info.Device.Name = devicePath
+	if cachedType, ok := b.getCachedDeviceType(devicePath); ok {
+		info.Device.Type = cachedType
+	}
+	if exitCode > 0 {
+		if info.Smartctl == nil {
+			info.Smartctl = &SmartctlInfo{}
+		}
+		info.Smartctl.ExitStatus = exitCode
+	}
+	return info, nil
+}
+
+// getSMARTInfoText is getSMARTInfoInternal for text output mode. The returned
+// document is the parsed SMARTInfo encoded as JSON, since smartctl printed none.
+func (b *ExecBackend) getSMARTInfoText(ctx context.Context, devicePath string) (*SMARTInfo, []byte, bool, error) {
+	info, err := b.runText(ctx, devicePath, "-a")
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to get SMART info: %w", err)
+	}
+	if info.InStandby {
+		return info, nil, false, nil
+	}
+	finishSMARTInfo(info)
+	b.logHealthBits(ctx, devicePath, info)
+
+	raw, err := json.Marshal(info)
+	if err != nil {
+		raw = nil
+	}
+	return info, raw, false, nil
+}
+
+// getDeviceInfoText is GetDeviceInfo for text output mode. The map holds the
+// identity fields of SMARTInfo under their JSON names.
+func (b *ExecBackend) getDeviceInfoText(ctx context.Context, devicePath string) (map[string]interface{}, error) {
+	info, err := b.runText(ctx, devicePath, "-i")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device info: %w", err)
+	}
+	if info.InStandby {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceInStandby, devicePath)
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode device info: %w", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse device info: %w", err)
+	}
+	return result, nil
+}
+
+// getAvailableSelfTestsText is GetAvailableSelfTests for text output mode.
+func (b *ExecBackend) getAvailableSelfTestsText(ctx context.Context, devicePath string) (*SelfTestInfo, error) {
+	info, err := b.runText(ctx, devicePath, "-c")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capabilities: %w", err)
+	}
+	if info.InStandby {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceInStandby, devicePath)
+	}
+	result := &SelfTestInfo{
+		Available: []string{},
+		Durations: make(map[string]int),
+	}
+	populateSelfTestInfo(result, info.AtaSmartData, nil, nil)
+	return result, nil
+}
+
+// scanDevicesText is ScanDevices for text output mode.
+func (b *ExecBackend) scanDevicesText(ctx context.Context, args ...string) ([]Device, error) {
+	res, err := b.run(ctx, append([]string{"--scan-open"}, args...)...)
+	var devices []Device
+	if err == nil {
+		devices = parseScanText(res.Stdout)
+	}
+	if err != nil || len(devices) == 0 {
+		if err != nil {
+			b.logHandler.WarnContext(ctx, "--scan-open failed, retrying with --scan", "err", err)
+		} else {
+			b.logHandler.DebugContext(ctx, "--scan-open found no devices, retrying with --scan")
+		}
+		listed, scanErr := b.run(withFallback(ctx, FallbackScan), append([]string{"--scan"}, args...)...)
+		if scanErr != nil {
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan devices: %w", scanErr)
+			}
+			b.logHandler.DebugContext(ctx, "--scan failed", "err", scanErr)
+			return devices, nil
+		}
+		devices = parseScanText(listed.Stdout)
+		markNotOpened(ctx, b.logHandler, devices)
+		return devices, nil
+	}
+	for _, d := range devices {
+		if d.Type != "" && d.OpenError == "" {
+			if _, cached := b.getCachedDeviceType(d.Name); !cached {
+				b.setCachedDeviceType(d.Name, d.Type)
+			}
+		}
+	}
+	return devices, nil
+}
+
+// probeDeviceText is probeDevice for text output mode. The text report does
+// not name the device type, so the returned Device only has the requested
+// one.
+func (b *ExecBackend) probeDeviceText(ctx context.Context, path string, deviceType string) (Device, bool) {
+	args := []string{"-i"}
+	if deviceType != "" {
+		args = append(args, "-d", deviceType)
+	}
+	res, err := b.run(ctx, append(args, path)...)
+	if !bytes.Contains(res.Stdout, []byte("=== START OF INFORMATION SECTION ===")) {
+		b.logHandler.DebugContext(ctx, "Cannot open disk with smartctl", "devicePath", path, "err", err)
+		return Device{}, false
+	}
+	return Device{Name: path, Type: deviceType}, true
+}
@@ -0,0 +1,224 @@
+package exec
+
+import (
+	"context"
+	osexec "os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const textATAOutput = `smartctl 6.6 2017-11-05 r4594 [x86_64-linux-4.19.0-6-amd64] (local build)
+Copyright (C) 2002-17, Bruce Allen, Christian Franke, www.smartmontools.org
+
+=== START OF INFORMATION SECTION ===
+Model Family:     Samsung based SSDs
+Device Model:     Samsung SSD 850 EVO 250GB
+Serial Number:    S21NNXAG123456
+LU WWN Device Id: 5 002538 d4012a4e0
+Firmware Version: EMT02B6Q
+User Capacity:    250,059,350,016 bytes [250 GB]
+Sector Size:      512 bytes logical/physical
+Rotation Rate:    Solid State Device
+Device is:        In smartctl database [for details use: -P show]
+ATA Version is:   ACS-2, ATA8-ACS T13/1699-D revision 4c
+Local Time is:    Mon Jan  1 00:00:00 2024 UTC
+SMART support is: Available - device has SMART capability.
+SMART support is: Enabled
+
+=== START OF READ SMART DATA SECTION ===
+SMART overall-health self-assessment test result: PASSED
+
+General SMART Values:
+Offline data collection status:  (0x00)	Offline data collection activity
+					was never started.
+Self-test execution status:      ( 249)	Self-test routine in progress...
+					90% of test remaining.
+Offline data collection
+capabilities: 			 (0x53) SMART execute Offline immediate.
+					Auto Offline data collection on/off support.
+					Self-test supported.
+					No Conveyance Self-test supported.
+Short self-test routine 
+recommended polling time: 	 (   2) minutes.
+Extended self-test routine
+recommended polling time: 	 ( 133) minutes.
+
+SMART Attributes Data Structure revision number: 1
+Vendor Specific SMART Attributes with Thresholds:
+ID# ATTRIBUTE_NAME          FLAG     VALUE WORST THRESH TYPE      UPDATED  WHEN_FAILED RAW_VALUE
+  5 Reallocated_Sector_Ct   0x0033   100   100   010    Pre-fail  Always       -       0
+  9 Power_On_Hours          0x0032   095   095   000    Old_age   Always       -       21350
+ 12 Power_Cycle_Count       0x0032   099   099   000    Old_age   Always       -       512
+177 Wear_Leveling_Count     0x0013   001   001   005    Pre-fail  Always   FAILING_NOW 3012
+194 Temperature_Celsius     0x0022   067   052   000    Old_age   Always       -       33 (Min/Max 18/48)
+
+SMART Self-test log structure revision number 1
+`
+
+const textNVMeOutput = `smartctl 6.6 2017-11-05 r4594 [x86_64-linux-4.19.0-6-amd64] (local build)
+Copyright (C) 2002-17, Bruce Allen, Christian Franke, www.smartmontools.org
+
+=== START OF INFORMATION SECTION ===
+Model Number:                       Samsung SSD 970 EVO Plus 500GB
+Serial Number:                      S4EVNX0M123456
+Firmware Version:                   2B2QEXM7
+Total NVM Capacity:                 500,107,862,016 [500 GB]
+
+=== START OF SMART DATA SECTION ===
+SMART overall-health self-assessment test result: FAILED!
+
+SMART/Health Information (NVMe Log 0x02, NSID 0xffffffff)
+Critical Warning:                   0x04
+Temperature:                        38 Celsius
+Available Spare:                    100%
+Available Spare Threshold:          10%
+Percentage Used:                    3%
+Data Units Read:                    1,234,567 [632 GB]
+Power Cycles:                       1,024
+Power On Hours:                     5,678
+Unsafe Shutdowns:                   12
+Media and Data Integrity Errors:    0
+Error Information Log Entries:      7
+`
+
+func TestParseSmartctlText_ATA(t *testing.T) {
+	info := parseSmartctlText([]byte(textATAOutput))
+
+	assert.Equal(t, []int{6, 6}, info.Smartctl.Version)
+	assert.Equal(t, "Samsung based SSDs", info.ModelFamily)
+	assert.Equal(t, "Samsung SSD 850 EVO 250GB", info.ModelName)
+	assert.Equal(t, "S21NNXAG123456", info.SerialNumber)
+	assert.Equal(t, "EMT02B6Q", info.Firmware)
+	require.NotNil(t, info.UserCapacity)
+	assert.Equal(t, int64(250059350016), info.UserCapacity.Bytes)
+	assert.Equal(t, int64(488397168), info.UserCapacity.Blocks)
+	require.NotNil(t, info.RotationRate)
+	assert.Equal(t, 0, *info.RotationRate)
+	require.NotNil(t, info.InSmartctlDatabase)
+	assert.True(t, *info.InSmartctlDatabase)
+	assert.Equal(t, &SmartSupport{Available: true, Enabled: true}, info.SmartSupport)
+	require.NotNil(t, info.SmartStatus)
+	assert.True(t, info.SmartStatus.Passed)
+
+	require.NotNil(t, info.AtaSmartData)
+	status := info.AtaSmartData.SelfTest.Status
+	assert.Equal(t, 249, status.Value)
+	assert.Equal(t, "Self-test routine in progress...", status.String)
+	require.NotNil(t, status.RemainingPercent)
+	assert.Equal(t, 90, *status.RemainingPercent)
+	assert.Equal(t, &PollingMinutes{Short: 2, Extended: 133}, info.AtaSmartData.SelfTest.PollingMinutes)
+	assert.Equal(t, &Capabilities{ExecOfflineImmediate: true, SelfTestsSupported: true}, info.AtaSmartData.Capabilities)
+
+	require.Len(t, info.AtaSmartData.Table, 5)
+	attr := info.AtaSmartData.Table[0]
+	assert.Equal(t, 5, attr.ID)
+	assert.Equal(t, "Reallocated_Sector_Ct", attr.Name)
+	assert.Equal(t, 10, attr.Thresh)
+	assert.Equal(t, 0x33, attr.Flags.Value)
+	assert.True(t, attr.Flags.PreFailure)
+	assert.True(t, attr.Flags.UpdatedOnline)
+	assert.False(t, attr.Flags.Performance)
+	assert.Equal(t, "now", info.AtaSmartData.Table[3].WhenFailed)
+	assert.Equal(t, "33 (Min/Max 18/48)", info.AtaSmartData.Table[4].Raw.String)
+	assert.Equal(t, int64(33), info.AtaSmartData.Table[4].Raw.Value)
+
+	assert.Equal(t, &Temperature{Current: 33}, info.Temperature)
+	assert.Equal(t, &PowerOnTime{Hours: 21350}, info.PowerOnTime)
+	assert.Equal(t, 512, info.PowerCycleCount)
+	assert.Nil(t, info.NvmeSmartHealth)
+}
+
+func TestParseSmartctlText_NVMe(t *testing.T) {
+	info := parseSmartctlText([]byte(textNVMeOutput))
+
+	assert.Equal(t, "nvme", info.Device.Type)
+	assert.Equal(t, "Samsung SSD 970 EVO Plus 500GB", info.ModelName)
+	assert.Equal(t, int64(500107862016), info.UserCapacity.Bytes)
+	require.NotNil(t, info.SmartStatus)
+	assert.False(t, info.SmartStatus.Passed)
+	require.NotNil(t, info.NvmeSmartHealth)
+	assert.Equal(t, NvmeSmartHealth{
+		CriticalWarning:      4,
+		Temperature:          38,
+		AvailableSpare:       100,
+		AvailableSpareThresh: 10,
+		PercentageUsed:       3,
+		DataUnitsRead:        1234567,
+		PowerCycles:          1024,
+		PowerOnHours:         5678,
+		UnsafeShutdowns:      12,
+		NumErrLogEntries:     7,
+	}, *info.NvmeSmartHealth)
+	assert.Equal(t, &Temperature{Current: 38}, info.Temperature)
+	assert.Equal(t, &PowerOnTime{Hours: 5678}, info.PowerOnTime)
+	assert.Equal(t, 1024, info.PowerCycleCount)
+	assert.Nil(t, info.AtaSmartData)
+}
+
+func TestApplyTextAttributeSummaries_PowerOnMinutes(t *testing.T) {
+	info := &SMARTInfo{AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+		{ID: 9, Raw: Raw{Value: 35825, String: "35825h+02m+39.040s"}},
+	}}}
+	applyTextAttributeSummaries(info)
+	assert.Equal(t, &PowerOnTime{Hours: 35825, Minutes: 2}, info.PowerOnTime)
+}
+
+func TestParseScanText(t *testing.T) {
+	output := "/dev/sda -d sat # /dev/sda [SAT], ATA device\n/dev/nvme0 -d nvme # /dev/nvme0, NVMe device\n# comment only\n/dev/sdb # no type\n" +
+		"# /dev/sdc -d scsi # /dev/sdc, SCSI device open failed: Permission denied\n"
+	assert.Equal(t, []Device{
+		{Name: "/dev/sda", Type: "sat", InfoName: "/dev/sda [SAT]", Protocol: "ATA"},
+		{Name: "/dev/nvme0", Type: "nvme", InfoName: "/dev/nvme0", Protocol: "NVMe"},
+		{Name: "/dev/sdb"},
+		{Name: "/dev/sdc", Type: "scsi", InfoName: "/dev/sdc", Protocol: "SCSI", OpenError: "Permission denied"},
+	}, parseScanText([]byte(output)))
+}
+
+func TestTextOutput_Backend(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --scan-open":                          {output: []byte("/dev/sda -d sat # /dev/sda [SAT], ATA device\n")},
+		"/usr/sbin/smartctl -a --nocheck=standby -d sat /dev/sda": {output: []byte(textATAOutput)},
+		"/usr/sbin/smartctl -i --nocheck=standby -d sat /dev/sda": {output: []byte(textATAOutput)},
+		"/usr/sbin/smartctl -c --nocheck=standby -d sat /dev/sda": {output: []byte(textATAOutput)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock), WithTextOutput(true))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	devices, err := b.ScanDevices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Device{{Name: "/dev/sda", Type: "sat", InfoName: "/dev/sda [SAT]", Protocol: "ATA"}}, devices)
+
+	info, raw, err := b.GetSMARTInfoRaw(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, Device{Name: "/dev/sda", Type: "sat"}, info.Device)
+	assert.Equal(t, "SSD", info.DiskType)
+	assert.True(t, info.SmartStatus.Passed)
+	assert.True(t, info.SmartStatus.Running)
+	assert.Contains(t, string(raw), `"serial_number":"S21NNXAG123456"`)
+
+	devInfo, err := b.GetDeviceInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "Samsung SSD 850 EVO 250GB", devInfo["model_name"])
+
+	tests, err := b.GetAvailableSelfTests(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"short", "long", "offline"}, tests.Available)
+	assert.Equal(t, map[string]int{"short": 2, "long": 133}, tests.Durations)
+}
+
+func TestTextOutput_OpenFailure(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -a --nocheck=standby /dev/sdz": {
+			output: []byte("smartctl 6.6 2017-11-05 r4594\n\nSmartctl open device: /dev/sdz failed: No such device\n"),
+			err:    &osexec.ExitError{},
+		},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock), WithTextOutput(true))
+	require.NoError(t, err)
+
+	_, err = b.GetSMARTInfo(context.Background(), "/dev/sdz")
+	assert.ErrorIs(t, err, ErrDeviceNotFound)
+}
@@ -0,0 +1,112 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hangingCommander is a ContextCommander whose commands never finish on
+// their own, like smartctl stuck on a dying drive. It honors req.Timeout the
+// way execCommander does.
+type hangingCommander struct {
+	mu       sync.Mutex
+	requests []CommandRequest
+}
+
+func (h *hangingCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	h.mu.Lock()
+	h.requests = append(h.requests, req)
+	h.mu.Unlock()
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+	<-ctx.Done()
+	return &CommandResult{ExitCode: -1}, errors.Join(errors.New("signal: killed"), ctx.Err())
+}
+
+func TestCommandTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("backend timeout", func(t *testing.T) {
+		hc := &hangingCommander{}
+		b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(hc), WithCommandTimeout(20*time.Millisecond))
+		require.NoError(t, err)
+		_, err = b.CheckHealth(ctx, "/dev/sda")
+		assert.ErrorIs(t, err, ErrCommandTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		var timeoutErr *CommandTimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, 20*time.Millisecond, timeoutErr.Timeout)
+		assert.Equal(t, "/usr/sbin/smartctl", timeoutErr.Name)
+		assert.Contains(t, timeoutErr.Args, "/dev/sda")
+		assert.Contains(t, err.Error(), "smartctl command timed out after 20ms")
+	})
+
+	t.Run("per-call timeout", func(t *testing.T) {
+		hc := &hangingCommander{}
+		b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(hc), WithCommandTimeout(0))
+		require.NoError(t, err)
+		_, err = b.GetSMARTInfo(ContextWithCommandTimeout(ctx, 10*time.Millisecond), "/dev/sda")
+		assert.ErrorIs(t, err, ErrCommandTimeout)
+		require.Len(t, hc.requests, 1, "timeouts are not retried")
+		assert.Equal(t, 10*time.Millisecond, hc.requests[0].Timeout)
+	})
+
+	t.Run("caller deadline is not a command timeout", func(t *testing.T) {
+		hc := &hangingCommander{}
+		b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(hc))
+		require.NoError(t, err)
+		cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err = b.CheckHealth(cctx, "/dev/sda")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, ErrCommandTimeout)
+		assert.Equal(t, DefaultCommandTimeout, hc.requests[0].Timeout)
+	})
+}
+
+func TestCommandTimeout_Requests(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{Stdout: []byte("PASSED")}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = b.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, err = b.CheckHealth(ContextWithCommandTimeout(ctx, time.Minute), "/dev/sda")
+	require.NoError(t, err)
+	_, err = b.CheckHealth(ContextWithCommandTimeout(ctx, -1), "/dev/sda")
+	require.NoError(t, err)
+
+	require.Len(t, rc.requests, 3)
+	assert.Equal(t, DefaultCommandTimeout, rc.requests[0].Timeout)
+	assert.Equal(t, time.Minute, rc.requests[1].Timeout)
+	assert.Zero(t, rc.requests[2].Timeout, "non-positive timeouts disable the limit")
+}
+
+func TestCommandTimeout_KillsSmartctl(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	smartctl := writeExecutable(t, t.TempDir(), "smartctl", `case "$1" in
+-V) echo "smartctl 7.4 2023-08-01 r5530 [x86_64-linux-6.1.0] (local build)" ;;
+*) exec sleep 30 ;;
+esac
+`)
+	b, err := New(WithSmartctlPath(smartctl), WithCommandTimeout(100*time.Millisecond))
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = b.CheckHealth(context.Background(), "/dev/sda")
+	assert.ErrorIs(t, err, ErrCommandTimeout)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
@@ -0,0 +1,31 @@
+package exec
+
+import "context"
+
+// WithTolerance passes "-T permissive", "-T verypermissive" or another
+// tolerance level to every smartctl invocation for a device. Some USB bridges
+// and old drives fail mandatory ATA command checks but still return usable
+// SMART data with a permissive level. Individual calls can override it with
+// ContextWithTolerance. New fails for unknown levels.
+func WithTolerance(t Tolerance) Option {
+	return func(b *ExecBackend) {
+		b.tolerance = t
+	}
+}
+
+// toleranceArgs prepends the -T option of ctx, the device or the backend to
+// args, keeping the device last. Commands without a device, such as --scan,
+// are unchanged.
+func (b *ExecBackend) toleranceArgs(ctx context.Context, args []string) []string {
+	tolerance := b.tolerance
+	if t := b.optionsFor(commandDevice(args)).Tolerance; t != "" {
+		tolerance = t
+	}
+	if override, ok := ToleranceFromContext(ctx); ok && override.Validate() == nil {
+		tolerance = override
+	}
+	if tolerance == "" || commandDevice(args) == "" {
+		return args
+	}
+	return append([]string{"-T", string(tolerance)}, args...)
+}
@@ -0,0 +1,41 @@
+package exec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTolerance_BackendAndContextOverride(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{Stdout: []byte(`PASSED`)}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithTolerance(TolerancePermissive))
+	require.NoError(t, err)
+
+	_, err = b.CheckHealth(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	ctx := ContextWithTolerance(context.Background(), ToleranceVeryPermissive)
+	_, err = b.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, _ = b.ScanDevices(context.Background())
+
+	require.GreaterOrEqual(t, len(rc.requests), 3)
+	assert.Equal(t, []string{"-T", "permissive", "-H", "--nocheck=standby", "/dev/sda"}, rc.requests[0].Args)
+	assert.Equal(t, []string{"-T", "verypermissive", "-H", "--nocheck=standby", "/dev/sda"}, rc.requests[1].Args)
+	assert.NotContains(t, rc.requests[2].Args, "-T", "commands without a device are unchanged")
+
+	_, err = New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc), WithTolerance("lenient"))
+	assert.ErrorContains(t, err, "invalid tolerance")
+}
+
+func TestTolerance_DefaultAddsNothing(t *testing.T) {
+	rc := &recordingCommander{result: &CommandResult{Stdout: []byte(`PASSED`)}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rc))
+	require.NoError(t, err)
+
+	_, err = b.CheckHealth(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	require.Len(t, rc.requests, 1)
+	assert.NotContains(t, rc.requests[0].Args, "-T")
+}
@@ -0,0 +1,94 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// smartctlBannerRe matches the first line of smartctl's banner, e.g.
+	// "smartctl 7.3 2022-02-28 r5338 [x86_64-linux-5.15.0] (local build)".
+	smartctlBannerRe = regexp.MustCompile(`(?m)^smartctl\s+\d+\.\d+.*$`)
+	svnRevisionRe    = regexp.MustCompile(`\br(\d+)\b`)
+	platformRe       = regexp.MustCompile(`\[([^\]]+)\]\s*(.*)$`)
+)
+
+// SmartctlVersion reports the version of the smartctl binary. The result is
+// cached; the drive database version is filled in once a device response
+// has reported it.
+func (b *ExecBackend) SmartctlVersion(ctx context.Context) (*SmartctlVersionInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b.versionMux.Lock()
+	defer b.versionMux.Unlock()
+	if b.version == nil {
+		version, err := b.querySmartctlVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.version = version
+	}
+	v := *b.version
+	if v.DriveDatabaseVersion == "" {
+		v.DriveDatabaseVersion = b.driveDBVersion
+	}
+	return &v, nil
+}
+
+// querySmartctlVersion asks smartctl for its version, preferring the JSON
+// smartctl block and falling back to the text banner.
+func (b *ExecBackend) querySmartctlVersion(ctx context.Context) (*SmartctlVersionInfo, error) {
+	if !b.textOutput {
+		res, err := b.run(ctx, "-j", "-V")
+		var out struct {
+			Smartctl *SmartctlInfo `json:"smartctl"`
+		}
+		if err == nil && json.Unmarshal(res.Stdout, &out) == nil {
+			if v := out.Smartctl.VersionInfo(); v != nil {
+				return v, nil
+			}
+		}
+	}
+	res, err := b.run(ctx, "-V")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get smartctl version: %w", err)
+	}
+	v, err := parseSmartctlVersionInfo(string(res.Stdout))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse smartctl version: %w", err)
+	}
+	return v, nil
+}
+
+// recordDriveDatabaseVersion remembers the drive database version reported in
+// the smartctl block of a device response.
+func (b *ExecBackend) recordDriveDatabaseVersion(info *SmartctlInfo) {
+	if info == nil || info.DriveDatabaseVersion == nil || info.DriveDatabaseVersion.String == "" {
+		return
+	}
+	b.versionMux.Lock()
+	b.driveDBVersion = info.DriveDatabaseVersion.String
+	b.versionMux.Unlock()
+}
+
+// parseSmartctlVersionInfo parses the banner printed by "smartctl -V".
+func parseSmartctlVersionInfo(output string) (*SmartctlVersionInfo, error) {
+	major, minor, err := parseSmartctlVersion(output)
+	if err != nil {
+		return nil, err
+	}
+	v := &SmartctlVersionInfo{Major: major, Minor: minor}
+	banner := smartctlBannerRe.FindString(output)
+	if m := svnRevisionRe.FindStringSubmatch(banner); m != nil {
+		v.SvnRevision = m[1]
+	}
+	if m := platformRe.FindStringSubmatch(banner); m != nil {
+		v.Platform = m[1]
+		v.BuildInfo = strings.TrimSpace(m[2])
+	}
+	return v, nil
+}
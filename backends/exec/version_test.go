@@ -0,0 +1,67 @@
+package exec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const versionBanner = `smartctl 7.3 2022-02-28 r5338 [x86_64-linux-6.1.0-18-amd64] (local build)
+Copyright (C) 2002-22, Bruce Allen, Christian Franke, www.smartmontools.org
+`
+
+func TestParseSmartctlVersionInfo(t *testing.T) {
+	v, err := parseSmartctlVersionInfo(versionBanner)
+	require.NoError(t, err)
+	assert.Equal(t, &SmartctlVersionInfo{
+		Major:       7,
+		Minor:       3,
+		SvnRevision: "5338",
+		Platform:    "x86_64-linux-6.1.0-18-amd64",
+		BuildInfo:   "(local build)",
+	}, v)
+	assert.Equal(t, "7.3 r5338", v.String())
+
+	_, err = parseSmartctlVersionInfo("not smartctl")
+	assert.Error(t, err)
+}
+
+func TestSmartctlVersion_JSON(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -j -V":                            {output: []byte(`{"json_format_version":[1,0],"smartctl":{"version":[7,4],"svn_revision":"5530","platform_info":"x86_64-linux-6.5.0","build_info":"(sf-7.4-1)"}}`)},
+		"/usr/sbin/smartctl -a -j --nocheck=standby /dev/sda": {output: []byte(`{"device":{"name":"/dev/sda","type":"sat"},"smartctl":{"version":[7,4],"drive_database_version":{"string":"7.3/5528"}}}`)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	v, err := b.SmartctlVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 7, v.Major)
+	assert.Equal(t, 4, v.Minor)
+	assert.Equal(t, "5530", v.SvnRevision)
+	assert.Equal(t, "x86_64-linux-6.5.0", v.Platform)
+	assert.Equal(t, "(sf-7.4-1)", v.BuildInfo)
+	assert.Empty(t, v.DriveDatabaseVersion)
+
+	_, err = b.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	v, err = b.SmartctlVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "7.3/5528", v.DriveDatabaseVersion)
+}
+
+func TestSmartctlVersion_TextFallback(t *testing.T) {
+	mock := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -V": {output: []byte(versionBanner)},
+	}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(mock))
+	require.NoError(t, err)
+
+	v, err := b.SmartctlVersion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "7.3 r5338", v.String())
+	assert.Equal(t, "x86_64-linux-6.1.0-18-amd64", v.Platform)
+}
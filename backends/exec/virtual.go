@@ -0,0 +1,165 @@
+package exec
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// virtualDisks are the vendor and model prefixes of virtual disks: SCSI LUNs
+// exported by software targets and storage arrays over iSCSI, FC or SAS, and
+// the disks hypervisors emulate. They have no SMART data of their own:
+// smartctl answers with errors or empty reports. An empty model matches any
+// model of the vendor, and an empty vendor any vendor, as ATA disks report
+// none ("ATA" in sysfs).
+var virtualDisks = []struct{ vendor, model string }{
+	{"LIO-ORG", ""},               // Linux LIO target
+	{"IET", "VIRTUAL-DISK"},       // iSCSI Enterprise Target
+	{"SCST_FIO", ""},              // SCST file I/O
+	{"SCST_BIO", ""},              // SCST block I/O
+	{"FreeNAS", "iSCSI Disk"},     // FreeNAS/TrueNAS CORE
+	{"TrueNAS", "iSCSI Disk"},     // TrueNAS SCALE
+	{"SYNOLOGY", "iSCSI Storage"}, // Synology DSM
+	{"QNAP", "iSCSI Storage"},     // QNAP QTS
+	{"MSFT", "Virtual HD"},        // Windows Server iSCSI target
+	{"NETAPP", "LUN"},
+	{"PURE", "FlashArray"},
+	{"3PARdata", "VV"},
+	{"HITACHI", "OPEN-V"},
+	{"COMPELNT", "Compellent Vol"},
+	{"QEMU", ""},             // QEMU/KVM SCSI and virtio-scsi
+	{"", "QEMU HARDDISK"},    // QEMU/KVM IDE and SATA
+	{"VMware", ""},           // VMware PVSCSI ("VMware  Virtual disk")
+	{"VMware,", ""},          // VMware LSI Logic ("VMware, VMware Virtual S")
+	{"", "VMware Virtual"},   // VMware IDE and SATA
+	{"Msft", "Virtual Disk"}, // Hyper-V
+	{"VBOX", ""},             // VirtualBox SCSI
+	{"", "VBOX HARDDISK"},    // VirtualBox IDE and SATA
+}
+
+// virtualDiskNameRe matches the device names of paravirtualized disks, which
+// report no vendor or model: virtio-blk (vda on Linux, vtbd0 on FreeBSD) and
+// Xen (xvda).
+var virtualDiskNameRe = regexp.MustCompile(`^(?:x?vd[a-z]+|vtbd\d+)$`)
+
+// isVirtualDisk reports whether the vendor and model strings identify a
+// virtual disk. Both are compared case-insensitively, ignoring padding.
+func isVirtualDisk(vendor, model string) bool {
+	vendor, model = strings.TrimSpace(vendor), strings.TrimSpace(model)
+	for _, v := range virtualDisks {
+		if (v.vendor == "" || strings.EqualFold(vendor, v.vendor)) && len(model) >= len(v.model) && strings.EqualFold(model[:len(v.model)], v.model) {
+			return true
+		}
+	}
+	return false
+}
+
+// virtualDeviceError returns a *VirtualDeviceError for devicePath when vendor
+// and model identify a virtual disk, and nil otherwise.
+func virtualDeviceError(devicePath, vendor, model string) error {
+	if !isVirtualDisk(vendor, model) {
+		return nil
+	}
+	return &VirtualDeviceError{Device: devicePath, Vendor: strings.TrimSpace(vendor), Model: strings.TrimSpace(model)}
+}
+
+// virtualDevice returns a *VirtualDeviceError for devicePath when its name is
+// that of a paravirtualized disk, which never passes SMART data through, so
+// that it is rejected without running smartctl. Devices whose type is set
+// with WithDeviceOptions are always queried.
+func (b *ExecBackend) virtualDevice(devicePath string) error {
+	if b.optionsFor(devicePath).Type != "" {
+		return nil
+	}
+	if virtualDiskNameRe.MatchString(filepath.Base(devicePath)) {
+		return &VirtualDeviceError{Device: devicePath}
+	}
+	return nil
+}
+
+// suspectedVirtual returns the *VirtualDeviceError of a device ScanDevices
+// found to be virtual or, on Linux with the default commander, whose vendor
+// and model read by the kernel are those of a virtual disk. Such a device is
+// still queried once, since a hypervisor may pass a physical disk and its
+// SMART data through, but when smartctl reports no SMART data the error is
+// returned instead of probing other device types. Devices whose type is set
+// with WithDeviceOptions are never suspected.
+func (b *ExecBackend) suspectedVirtual(devicePath string) error {
+	if b.optionsFor(devicePath).Type != "" {
+		return nil
+	}
+	b.virtualMux.Lock()
+	err, ok := b.virtualDevices[devicePath]
+	b.virtualMux.Unlock()
+	if ok {
+		return err
+	}
+	if goos != "linux" || !b.defaultCommander {
+		return nil
+	}
+	if info := readSysfsInfo(devicePath); info != nil {
+		return virtualDeviceError(devicePath, info.Vendor, info.Model)
+	}
+	return nil
+}
+
+// markVirtual sets Virtual on the scanned devices that are virtual disks,
+// judging by their name and sysfs vendor and model, and remembers them so
+// that SMART queries do not probe them with other device types; see
+// suspectedVirtual. Each scan replaces the verdicts of the previous one, so a
+// path reused by another disk is not rejected for what it was before.
+func (b *ExecBackend) markVirtual(devices []Device) {
+	virtual := make(map[string]error)
+	for i := range devices {
+		d := &devices[i]
+		var err error
+		if d.Sysfs != nil {
+			err = virtualDeviceError(d.Name, d.Sysfs.Vendor, d.Sysfs.Model)
+		}
+		if err == nil && virtualDiskNameRe.MatchString(filepath.Base(d.Name)) {
+			err = &VirtualDeviceError{Device: d.Name}
+		}
+		if err != nil {
+			d.Virtual = true
+			virtual[d.Name] = err
+		}
+	}
+	b.virtualMux.Lock()
+	b.virtualDevices = virtual
+	b.virtualMux.Unlock()
+}
+
+// infoVirtualDevice checks the SCSI vendor and product, or the ATA model,
+// smartctl reported for devicePath. Virtual devices that pass SMART data through, as some
+// hypervisors do for physical disks, are not reported.
+func infoVirtualDevice(devicePath string, info *SMARTInfo) error {
+	if hasSMARTData(info) {
+		return nil
+	}
+	vendor, model := extraString(info, "scsi_vendor"), extraString(info, "scsi_product")
+	if vendor == "" {
+		// smartctl before 7.3
+		vendor, model = extraString(info, "vendor"), extraString(info, "product")
+	}
+	if vendor == "" && model == "" {
+		model = info.ModelName // ATA
+	}
+	return virtualDeviceError(devicePath, vendor, model)
+}
+
+// hasSMARTData reports whether smartctl returned SMART data for a device, or
+// at least found SMART support on it.
+func hasSMARTData(info *SMARTInfo) bool {
+	return info.AtaSmartData != nil || info.NvmeSmartHealth != nil || (info.SmartSupport != nil && info.SmartSupport.Available)
+}
+
+// extraString returns the string value of an unmodeled top-level key of the
+// smartctl JSON output, or "".
+func extraString(info *SMARTInfo, key string) string {
+	var value string
+	if raw, ok := info.Extra[key]; ok {
+		_ = json.Unmarshal(raw, &value)
+	}
+	return value
+}
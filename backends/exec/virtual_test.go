@@ -0,0 +1,163 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsVirtualDisk(t *testing.T) {
+	assert.True(t, isVirtualDisk("LIO-ORG ", "disk01          "))
+	assert.True(t, isVirtualDisk("SYNOLOGY", "iSCSI Storage"))
+	assert.True(t, isVirtualDisk("netapp", "LUN C-Mode"))
+	assert.False(t, isVirtualDisk("NETAPP", "X477_HMKPX04TA07"))
+	assert.False(t, isVirtualDisk("ATA", "WDC WD40EFRX-68N"))
+	assert.True(t, isVirtualDisk("QEMU", "QEMU HARDDISK"))
+	assert.True(t, isVirtualDisk("ATA", "QEMU HARDDISK"))
+	assert.True(t, isVirtualDisk("VMware", "Virtual disk"))
+	assert.True(t, isVirtualDisk("ATA", "VMware Virtual S"))
+	assert.True(t, isVirtualDisk("Msft", "Virtual Disk"))
+	assert.True(t, isVirtualDisk("ATA", "VBOX HARDDISK"))
+	assert.False(t, isVirtualDisk("Msft", "XBOX"))
+	assert.False(t, isVirtualDisk("", ""))
+}
+
+func TestExecBackend_VirtualLUN(t *testing.T) {
+	lun := `{"device":{"name":"/dev/sdc","type":"scsi","protocol":"SCSI"},"scsi_vendor":"LIO-ORG","scsi_product":"disk01","smart_support":{"available":false}}`
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -a -j --nocheck=standby /dev/sdc":        {output: []byte(lun), err: &osexec.ExitError{}},
+		"/usr/sbin/smartctl -a -j --nocheck=standby -d sat /dev/sdc": {output: []byte(satFallbackJSON)},
+	}}))
+	require.NoError(t, err)
+
+	info, err := b.GetSMARTInfo(context.Background(), "/dev/sdc")
+	assert.ErrorIs(t, err, ErrVirtualDevice)
+	assert.ErrorIs(t, err, ErrSmartNotSupported)
+	var vErr *VirtualDeviceError
+	require.ErrorAs(t, err, &vErr)
+	assert.Equal(t, VirtualDeviceError{Device: "/dev/sdc", Vendor: "LIO-ORG", Model: "disk01"}, *vErr)
+	assert.Equal(t, "SMART not applicable: virtual device: /dev/sdc (LIO-ORG disk01)", err.Error())
+	require.NotNil(t, info)
+	_, cached := b.getCachedDeviceType("/dev/sdc")
+	assert.False(t, cached, "no SAT probe")
+}
+
+func TestExecBackend_VirtualLUNFromSysfs(t *testing.T) {
+	withGOOS(t, "linux")
+	root := t.TempDir()
+	for name, content := range map[string]string{"vendor": "QNAP    \n", "model": "iSCSI Storage   \n"} {
+		path := filepath.Join(root, "class", "block", "sdd", "device", name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	oldSys := sysfsDir
+	sysfsDir = root
+	t.Cleanup(func() { sysfsDir = oldSys })
+
+	rec := &recordingCommander{result: &CommandResult{ExitCode: 2}, err: errors.New("open failed")}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec))
+	require.NoError(t, err)
+	b.defaultCommander = true
+
+	_, err = b.GetSMARTInfo(context.Background(), "/dev/sdd")
+	assert.ErrorIs(t, err, ErrVirtualDevice)
+	assert.Len(t, rec.requests, 1, "no fallback probing")
+}
+
+func TestExecBackend_VirtualPassthroughFromSysfs(t *testing.T) {
+	withGOOS(t, "linux")
+	root := t.TempDir()
+	for name, content := range map[string]string{"vendor": "QEMU    \n", "model": "QEMU HARDDISK   \n"} {
+		path := filepath.Join(root, "class", "block", "sde", "device", name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	oldSys := sysfsDir
+	sysfsDir = root
+	t.Cleanup(func() { sysfsDir = oldSys })
+
+	passthrough := `{"device":{"name":"/dev/sde","type":"sat"},"model_name":"QEMU HARDDISK","smart_support":{"available":true,"enabled":true},"ata_smart_data":{}}`
+	rec := &recordingCommander{result: &CommandResult{Stdout: []byte(passthrough)}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec))
+	require.NoError(t, err)
+	b.defaultCommander = true
+
+	info, err := b.GetSMARTInfo(context.Background(), "/dev/sde")
+	require.NoError(t, err, "SMART data passed through")
+	assert.NotNil(t, info.AtaSmartData)
+}
+
+func TestExecBackend_VirtualDeviceTypeOption(t *testing.T) {
+	rec := &recordingCommander{result: &CommandResult{Stdout: []byte(satFallbackJSON)}}
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithContextCommander(rec),
+		WithDeviceOptions(map[string]DeviceOptions{"/dev/vdb": {Type: "sat"}}))
+	require.NoError(t, err)
+
+	_, err = b.GetSMARTInfo(context.Background(), "/dev/vdb")
+	require.NoError(t, err)
+	require.Len(t, rec.requests, 1)
+	assert.Contains(t, rec.requests[0].Args, "sat")
+}
+
+func TestExecBackend_ScanMarksVirtualDisks(t *testing.T) {
+	withGOOS(t, "linux")
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"class/block/sda/device/vendor": "QEMU    \n",
+		"class/block/sda/device/model":  "QEMU HARDDISK   \n",
+		"class/block/sdb/device/vendor": "ATA     \n",
+		"class/block/sdb/device/model":  "WDC WD40EFRX-68N\n",
+	} {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	oldSys := sysfsDir
+	sysfsDir = root
+	t.Cleanup(func() { sysfsDir = oldSys })
+
+	b, err := New(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl --scan-open --json": {output: []byte(`{"devices":[{"name":"/dev/sda","type":"scsi"},{"name":"/dev/sdb","type":"sat"},{"name":"/dev/vda","type":"scsi"}]}`)},
+	}}))
+	require.NoError(t, err)
+	b.defaultCommander = true
+
+	devices, err := b.ScanDevices(context.Background())
+	require.NoError(t, err)
+	require.Len(t, devices, 3)
+	assert.True(t, devices[0].Virtual)
+	assert.False(t, devices[1].Virtual)
+	assert.True(t, devices[2].Virtual, "virtio-blk")
+
+	// No fallback probing: the queries fail without running smartctl.
+	b.defaultCommander = false
+	_, err = b.GetSMARTInfo(context.Background(), "/dev/sda")
+	var vErr *VirtualDeviceError
+	require.ErrorAs(t, err, &vErr)
+	assert.Equal(t, "QEMU", vErr.Vendor)
+	_, err = b.GetSMARTInfo(context.Background(), "/dev/vda")
+	assert.EqualError(t, err, "SMART not applicable: virtual device: /dev/vda")
+
+	results, err := b.DiscoverDevices(context.Background())
+	require.NoError(t, err)
+	assert.False(t, results[0].SMARTReadable)
+}
+
+func TestExecBackend_RescanClearsVirtualVerdicts(t *testing.T) {
+	b := newMinimalBackend(t)
+
+	devices := []Device{{Name: "/dev/sdf", Sysfs: &SysfsInfo{Vendor: "LIO-ORG", Model: "disk01"}}}
+	b.markVirtual(devices)
+	require.True(t, devices[0].Virtual)
+	require.Error(t, b.suspectedVirtual("/dev/sdf"))
+
+	// The path now belongs to a physical disk.
+	b.markVirtual([]Device{{Name: "/dev/sdf", Sysfs: &SysfsInfo{Vendor: "ATA", Model: "WDC WD40EFRX-68N"}}})
+	assert.NoError(t, b.suspectedVirtual("/dev/sdf"))
+}
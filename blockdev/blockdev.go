@@ -0,0 +1,264 @@
+/*
+Package blockdev maps a block device to the partitions, stacked devices and
+mounted filesystems it hosts, so that a SMART alert for /dev/sdb can say which
+data is at risk, e.g. "/srv/photos".
+
+The layout is read from Linux sysfs (/sys/class/block), the udev database
+(/run/udev/data) and /proc/self/mounts; no external tools are run. Stacked
+devices such as LUKS and LVM volumes (dm-N) and MD arrays (mdN) are followed
+through the sysfs holders links, so a filesystem on an LVM volume spanning the
+disk is reported too. Filesystems whose mount source is not a block device,
+such as ZFS datasets, cannot be mapped. On other systems Lookup fails because
+sysfs is missing.
+*/
+package blockdev
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dianlight/smartmontools-go/internal/sysfs"
+)
+
+// Paths of the kernel and udev interfaces. Tests point them at fixtures.
+var (
+	sysClassBlock = "/sys/class/block"
+	procMounts    = "/proc/self/mounts"
+	udevDataDir   = "/run/udev/data"
+)
+
+// Layout describes what a block device hosts.
+type Layout struct {
+	// Device is the device path as given to Lookup and Name its kernel name,
+	// e.g. "sdb".
+	Device string `json:"device"`
+	Name   string `json:"name"`
+	// Filesystem is set when the whole device carries a filesystem or other
+	// signature instead of a partition table.
+	Filesystem *Filesystem  `json:"filesystem,omitempty"`
+	Partitions []Partition  `json:"partitions,omitempty"`
+	Holders    []StackedDev `json:"holders,omitempty"`
+	// Mounts are the mounted filesystems on the device, its partitions or
+	// any device stacked on them, in /proc/self/mounts order.
+	Mounts []Mount `json:"mounts,omitempty"`
+}
+
+// Partition is a partition of the device.
+type Partition struct {
+	Name       string       `json:"name"`
+	Path       string       `json:"path"`
+	Filesystem *Filesystem  `json:"filesystem,omitempty"`
+	Holders    []StackedDev `json:"holders,omitempty"`
+}
+
+// StackedDev is a device built on top of a disk or partition, directly or
+// through other stacked devices: a device-mapper target (LUKS, LVM) or an MD
+// array. MapperName is the device-mapper name, e.g. "vg0-photos".
+type StackedDev struct {
+	Name       string      `json:"name"`
+	MapperName string      `json:"mapper_name,omitempty"`
+	Filesystem *Filesystem `json:"filesystem,omitempty"`
+}
+
+// Filesystem is the signature udev's blkid probe found on a device, such as
+// "ext4", "xfs", "crypto_LUKS", "LVM2_member" or "zfs_member".
+type Filesystem struct {
+	Type  string `json:"type"`
+	Label string `json:"label,omitempty"`
+	UUID  string `json:"uuid,omitempty"`
+}
+
+// Mount is a mounted filesystem. Device is the kernel name of the mounted
+// block device, e.g. "sdb1" or "dm-0".
+type Mount struct {
+	Source     string `json:"source"`
+	Device     string `json:"device"`
+	Mountpoint string `json:"mountpoint"`
+	FSType     string `json:"fstype"`
+	ReadOnly   bool   `json:"read_only,omitempty"`
+}
+
+// Lookup returns the layout of devicePath, which may be a /dev/disk alias.
+func Lookup(devicePath string) (*Layout, error) {
+	name := filepath.Base(devicePath)
+	if resolved, err := filepath.EvalSymlinks(devicePath); err == nil {
+		name = filepath.Base(resolved)
+	}
+	if _, err := os.Stat(filepath.Join(sysClassBlock, name)); err != nil {
+		return nil, fmt.Errorf("blockdev: %s: %w", devicePath, err)
+	}
+
+	layout := &Layout{Device: devicePath, Name: name, Filesystem: filesystem(name)}
+	// Kernel names of the device and everything it hosts, and the
+	// device-mapper names mount sources may use instead.
+	hosted := map[string]bool{name: true}
+	mapperNames := make(map[string]string)
+	addHolders := func(holders []StackedDev) {
+		for _, h := range holders {
+			hosted[h.Name] = true
+			if h.MapperName != "" {
+				mapperNames[h.MapperName] = h.Name
+			}
+		}
+	}
+
+	layout.Holders = holders(name)
+	addHolders(layout.Holders)
+	entries, err := os.ReadDir(filepath.Join(sysClassBlock, name))
+	if err != nil {
+		return nil, fmt.Errorf("blockdev: %w", err)
+	}
+	for _, entry := range entries {
+		part := entry.Name()
+		if _, err := os.Stat(filepath.Join(sysClassBlock, name, part, "partition")); err != nil {
+			continue
+		}
+		p := Partition{Name: part, Path: "/dev/" + part, Filesystem: filesystem(part), Holders: holders(part)}
+		layout.Partitions = append(layout.Partitions, p)
+		hosted[part] = true
+		addHolders(p.Holders)
+	}
+
+	mounts, err := readMounts()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mounts {
+		m.Device = mountDevice(m.Source, mapperNames)
+		if hosted[m.Device] {
+			layout.Mounts = append(layout.Mounts, m)
+		}
+	}
+	return layout, nil
+}
+
+// Mountpoints returns the mountpoints of the filesystems the device hosts.
+func (l *Layout) Mountpoints() []string {
+	mountpoints := make([]string, 0, len(l.Mounts))
+	for _, m := range l.Mounts {
+		mountpoints = append(mountpoints, m.Mountpoint)
+	}
+	return mountpoints
+}
+
+// Mountpoints returns the mountpoints of the filesystems devicePath hosts.
+func Mountpoints(devicePath string) ([]string, error) {
+	layout, err := Lookup(devicePath)
+	if err != nil {
+		return nil, err
+	}
+	return layout.Mountpoints(), nil
+}
+
+// holders returns the devices stacked on name, depth first, each once.
+func holders(name string) []StackedDev {
+	var stacked []StackedDev
+	seen := make(map[string]bool)
+	var walk func(string)
+	walk = func(name string) {
+		entries, err := os.ReadDir(filepath.Join(sysClassBlock, name, "holders"))
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			holder := entry.Name()
+			if seen[holder] {
+				continue
+			}
+			seen[holder] = true
+			stacked = append(stacked, StackedDev{Name: holder, MapperName: sysfs.ReadLine(filepath.Join(sysClassBlock, holder, "dm", "name")), Filesystem: filesystem(holder)})
+			walk(holder)
+		}
+	}
+	walk(name)
+	return stacked
+}
+
+// filesystem returns the signature udev recorded for the device, or nil.
+func filesystem(name string) *Filesystem {
+	dev := sysfs.ReadLine(filepath.Join(sysClassBlock, name, "dev"))
+	if dev == "" {
+		return nil
+	}
+	props := sysfs.UdevProperties(udevDataDir, dev)
+	fs := Filesystem{Type: props["ID_FS_TYPE"], Label: props["ID_FS_LABEL"], UUID: props["ID_FS_UUID"]}
+	if fs.Type == "" {
+		return nil
+	}
+	return &fs
+}
+
+// readMounts parses procMounts.
+func readMounts() ([]Mount, error) {
+	f, err := os.Open(procMounts)
+	if err != nil {
+		return nil, fmt.Errorf("blockdev: %w", err)
+	}
+	defer f.Close()
+	var mounts []Mount
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		mounts = append(mounts, Mount{
+			Source:     unescapeMountField(fields[0]),
+			Mountpoint: unescapeMountField(fields[1]),
+			FSType:     fields[2],
+			ReadOnly:   slices.Contains(strings.Split(fields[3], ","), "ro"),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("blockdev: read %s: %w", procMounts, err)
+	}
+	return mounts, nil
+}
+
+// mountDevice returns the kernel name of the block device a mount source
+// refers to, resolving /dev symlinks and /dev/mapper names.
+func mountDevice(source string, mapperNames map[string]string) string {
+	if !strings.HasPrefix(source, "/dev/") {
+		return ""
+	}
+	if mapper, ok := strings.CutPrefix(source, "/dev/mapper/"); ok {
+		if name, ok := mapperNames[mapper]; ok {
+			return name
+		}
+	}
+	if resolved, err := filepath.EvalSymlinks(source); err == nil {
+		source = resolved
+	}
+	return filepath.Base(source)
+}
+
+// unescapeMountField decodes the octal escapes (\040 for a space) the kernel
+// uses in /proc/self/mounts.
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) && isOctal(field[i+1:i+4]) {
+			b.WriteByte((field[i+1]-'0')<<6 | (field[i+2]-'0')<<3 | (field[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
+func isOctal(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '7' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package blockdev
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates files with the given contents under root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+// fakeHost lays out sdb with an ext4 partition and a LUKS partition holding an
+// LVM volume, and points the package at it.
+func fakeHost(t *testing.T) {
+	t.Helper()
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"sys/sda/dev":              "8:0\n",
+		"sys/sda/sda1/partition":   "1\n",
+		"sys/sda1/dev":             "8:1\n",
+		"sys/sdb/dev":              "8:16\n",
+		"sys/sdb/sdb1/partition":   "1\n",
+		"sys/sdb/sdb2/partition":   "2\n",
+		"sys/sdb/queue/rotational": "1\n",
+		"sys/sdb1/dev":             "8:17\n",
+		"sys/sdb2/dev":             "8:18\n",
+		"sys/sdb2/holders/dm-0":    "",
+		"sys/dm-0/dev":             "253:0\n",
+		"sys/dm-0/dm/name":         "luks-photos\n",
+		"sys/dm-0/holders/dm-1":    "",
+		"sys/dm-1/dev":             "253:1\n",
+		"sys/dm-1/dm/name":         "vg0-photos\n",
+		"udev/b8:17":               "S:disk/by-uuid/1111\nE:ID_FS_TYPE=ext4\nE:ID_FS_LABEL=backup\nE:ID_FS_UUID=1111\n",
+		"udev/b8:18":               "E:ID_FS_TYPE=crypto_LUKS\n",
+		"udev/b253:1":              "E:ID_FS_TYPE=xfs\n",
+		"mounts": "/dev/sda1 / ext4 rw,relatime 0 0\n" +
+			"proc /proc proc rw 0 0\n" +
+			"/dev/sdb1 /mnt/back\\040up ext4 ro,relatime 0 0\n" +
+			"/dev/mapper/vg0-photos /srv/photos xfs rw 0 0\n",
+	})
+	oldSys, oldMounts, oldUdev := sysClassBlock, procMounts, udevDataDir
+	sysClassBlock, procMounts, udevDataDir = filepath.Join(root, "sys"), filepath.Join(root, "mounts"), filepath.Join(root, "udev")
+	t.Cleanup(func() { sysClassBlock, procMounts, udevDataDir = oldSys, oldMounts, oldUdev })
+}
+
+func TestLookup(t *testing.T) {
+	fakeHost(t)
+	layout, err := Lookup("/dev/sdb")
+	require.NoError(t, err)
+
+	assert.Equal(t, "sdb", layout.Name)
+	assert.Nil(t, layout.Filesystem)
+	require.Len(t, layout.Partitions, 2)
+	assert.Equal(t, Partition{Name: "sdb1", Path: "/dev/sdb1", Filesystem: &Filesystem{Type: "ext4", Label: "backup", UUID: "1111"}}, layout.Partitions[0])
+	assert.Equal(t, []StackedDev{
+		{Name: "dm-0", MapperName: "luks-photos"},
+		{Name: "dm-1", MapperName: "vg0-photos", Filesystem: &Filesystem{Type: "xfs"}},
+	}, layout.Partitions[1].Holders)
+
+	require.Len(t, layout.Mounts, 2)
+	assert.Equal(t, Mount{Source: "/dev/sdb1", Device: "sdb1", Mountpoint: "/mnt/back up", FSType: "ext4", ReadOnly: true}, layout.Mounts[0])
+	assert.Equal(t, "dm-1", layout.Mounts[1].Device)
+	assert.Equal(t, []string{"/mnt/back up", "/srv/photos"}, layout.Mountpoints())
+
+	mountpoints, err := Mountpoints("/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/"}, mountpoints)
+
+	_, err = Lookup("/dev/sdz")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestUnescapeMountField(t *testing.T) {
+	assert.Equal(t, "/mnt/a b", unescapeMountField(`/mnt/a\040b`))
+	assert.Equal(t, `/mnt/tab\011`, unescapeMountField(`/mnt/tab\134011`))
+	assert.Equal(t, `/mnt/x\9`, unescapeMountField(`/mnt/x\9`))
+}
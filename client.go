@@ -2,22 +2,45 @@ package smartmontools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
 	"log/slog"
-	"slices"
 	"time"
 
+	smexec "github.com/dianlight/smartmontools-go/backends/exec"
 	smtypes "github.com/dianlight/smartmontools-go/internal/types"
 	"github.com/dianlight/tlog"
 )
 
 // SMART attribute IDs for SSD detection and wear-level computation.
 const (
+	SmartAttrRemainingLifetime = smtypes.SmartAttrRemainingLifetime
 	SmartAttrSSDLifeUsed       = smtypes.SmartAttrSSDLifeUsed
 	SmartAttrWearLevelingCount = smtypes.SmartAttrWearLevelingCount
+	SmartAttrPercentLifetime   = smtypes.SmartAttrPercentLifetime
 	SmartAttrSSDLifeLeft       = smtypes.SmartAttrSSDLifeLeft
 	SmartAttrSandForceInternal = smtypes.SmartAttrSandForceInternal
+	SmartAttrMediaWearout      = smtypes.SmartAttrMediaWearout
 	SmartAttrTotalLBAsWritten  = smtypes.SmartAttrTotalLBAsWritten
+	SmartAttrHostWrites        = smtypes.SmartAttrHostWrites
+	SmartAttrHostSectorWrites  = smtypes.SmartAttrHostSectorWrites
+)
+
+// SMART attribute IDs for common health and usage counters.
+const (
+	SmartAttrReallocatedSectors    = smtypes.SmartAttrReallocatedSectors
+	SmartAttrPowerOnHours          = smtypes.SmartAttrPowerOnHours
+	SmartAttrPowerCycleCount       = smtypes.SmartAttrPowerCycleCount
+	SmartAttrReportedUncorrect     = smtypes.SmartAttrReportedUncorrect
+	SmartAttrCommandTimeout        = smtypes.SmartAttrCommandTimeout
+	SmartAttrAirflowTemperature    = smtypes.SmartAttrAirflowTemperature
+	SmartAttrTemperatureCelsius    = smtypes.SmartAttrTemperatureCelsius
+	SmartAttrReallocatedEventCount = smtypes.SmartAttrReallocatedEventCount
+	SmartAttrCurrentPendingSector  = smtypes.SmartAttrCurrentPendingSector
+	SmartAttrOfflineUncorrectable  = smtypes.SmartAttrOfflineUncorrectable
+	SmartAttrUDMACRCErrorCount     = smtypes.SmartAttrUDMACRCErrorCount
 )
 
 // ClientOption is a function that configures a Client.
@@ -32,13 +55,28 @@ func WithSmartctlPath(path string) ClientOption {
 	}
 }
 
-// WithLogHandler sets a custom slog.Logger for the client.
-func WithLogHandler(logger *slog.Logger) ClientOption {
+// WithLogger sends the library's log output (smartctl messages, commands
+// run, fallback decisions, device type cache writes) to logger instead of the
+// default debug-level tlog logger. Records about a device carry its path
+// under "devicePath", and its smartctl -d type under "deviceType"; errors are
+// logged under "err". A nil logger keeps the default. This option is only
+// effective when using the default ExecBackend; backends passed to
+// WithBackend keep their own logger.
+func WithLogger(logger *slog.Logger) ClientOption {
 	return func(c *Client) {
-		c.logHandler = logger
+		if logger != nil {
+			c.logHandler = logger
+		}
 	}
 }
 
+// WithLogHandler sets a custom slog.Logger for the client.
+//
+// Deprecated: Use WithLogger.
+func WithLogHandler(logger *slog.Logger) ClientOption {
+	return WithLogger(logger)
+}
+
 // WithTLogHandler sets a custom tlog.Logger for the client.
 func WithTLogHandler(logger *tlog.Logger) ClientOption {
 	return func(c *Client) {
@@ -49,12 +87,234 @@ func WithTLogHandler(logger *tlog.Logger) ClientOption {
 // WithCommander sets a custom commander for testing purposes.
 // This option is only effective when using the default ExecBackend.
 // It is silently ignored when WithBackend is also provided.
+//
+// Deprecated: Use WithContextCommander.
 func WithCommander(commander Commander) ClientOption {
 	return func(c *Client) {
 		c.pendingExecOpts = append(c.pendingExecOpts, WithExecCommander(commander))
 	}
 }
 
+// WithContextCommander sets a custom command executor, typically for testing.
+// This option is only effective when using the default ExecBackend.
+func WithContextCommander(commander ContextCommander) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecContextCommander(commander))
+	}
+}
+
+// WithCommandTimeout kills any smartctl invocation that runs longer than
+// timeout, so a hung device cannot block the caller indefinitely; the call
+// fails with a *CommandTimeoutError matching ErrCommandTimeout. The default is
+// DefaultCommandTimeout; zero leaves commands bounded only by the caller's
+// context. ContextWithCommandTimeout overrides it per call. This option is
+// only effective when using the default ExecBackend.
+func WithCommandTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecCommandTimeout(timeout))
+	}
+}
+
+// WithCommandEnv adds "KEY=value" entries to the environment of every smartctl
+// invocation, e.g. "LD_LIBRARY_PATH=/opt/smartmontools/lib" for a bundled
+// binary. A "PATH" entry also decides where smartctl is looked up unless
+// WithSmartctlPath sets it. The entries reach custom commanders as
+// CommandRequest.Env. This option is only effective when using the default
+// ExecBackend.
+func WithCommandEnv(env ...string) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecCommandEnv(env...))
+	}
+}
+
+// WithLocale sets LC_ALL and LANG of every smartctl invocation, DefaultLocale
+// ("C") unless set, so that non-JSON output such as "PASSED" is never
+// translated. An empty locale inherits the process locale. This option is
+// only effective when using the default ExecBackend.
+func WithLocale(locale string) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecLocale(locale))
+	}
+}
+
+// WithAttributePresets sets attribute interpretation overrides for a device,
+// passed to smartctl as '-v' options (e.g. "9,minutes" or
+// "198,offlinescanuncsectorct"). This option is only effective when using the
+// default ExecBackend.
+func WithAttributePresets(devicePath string, presets ...string) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecAttributePresets(devicePath, presets...))
+	}
+}
+
+// WithDrivedbPresets controls whether '-v'/'-F' presets from the embedded
+// drivedb are applied automatically to drives that the installed smartctl does
+// not recognize. It is enabled by default and only effective with ExecBackend.
+func WithDrivedbPresets(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecDrivedbPresets(enabled))
+	}
+}
+
+// WithDeviceRateLimit allows at most burst back-to-back smartctl invocations
+// per device and one every interval after that; further calls wait, bounded
+// by their context. It protects slow drives, such as USB disks, from callers
+// that query them in a tight loop. This option is only effective when using
+// the default ExecBackend.
+func WithDeviceRateLimit(interval time.Duration, burst int) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecDeviceRateLimit(interval, burst))
+	}
+}
+
+// WithSharedQueries makes GetSMARTInfo, GetSMARTInfoRaw, IsSMARTSupported,
+// CheckHealth and GetAvailableSelfTests share a single "smartctl -x -j"
+// invocation per device within window, instead of each launching its own
+// smartctl process and waking the disk again. This option is only effective
+// when using the default ExecBackend.
+func WithSharedQueries(window time.Duration) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecSharedQueries(window))
+	}
+}
+
+// WithNoCheckPolicy sets when SMART queries skip drives in a low-power mode
+// instead of waking them (smartctl --nocheck): never, sleep, standby (the
+// default) or idle, optionally waking a drive after MaxSkips consecutive
+// skipped queries. Use ContextWithNoCheck to override it for a single call.
+// NewClient fails for invalid policies. This option is only effective when
+// using the default ExecBackend.
+func WithNoCheckPolicy(policy NoCheckPolicy) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecNoCheckPolicy(policy))
+	}
+}
+
+// WithTolerance passes "-T permissive", "-T verypermissive" or another
+// tolerance level to smartctl, for USB bridges and old drives that fail
+// mandatory ATA command checks but still return usable SMART data. Use
+// ContextWithTolerance to set it for a single call. NewClient fails for
+// unknown levels. This option is only effective when using the default
+// ExecBackend.
+func WithTolerance(t Tolerance) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecTolerance(t))
+	}
+}
+
+// WithDeviceOptions overrides settings per device path for mixed fleets, e.g.
+// a USB enclosure that needs "-d sat" and a permissive tolerance while
+// other drives use the client's defaults:
+//
+//	smartmontools.WithDeviceOptions(map[string]smartmontools.DeviceOptions{
+//		"/dev/sdc": {Type: "sat", Tolerance: smartmontools.TolerancePermissive},
+//	})
+//
+// A configured Type disables auto-detection and SAT probing for the device;
+// ExtraArgs are passed to every smartctl invocation for it. Calls made with
+// ContextWithTolerance or ContextWithNoCheck still take precedence. NewClient
+// fails for invalid options. This option is only effective when using the
+// default ExecBackend.
+func WithDeviceOptions(options map[string]DeviceOptions) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecDeviceOptions(options))
+	}
+}
+
+// WithCommandHook calls hook after every smartctl invocation with the
+// arguments, run time, exit code and error, and the fallback that caused it
+// (FallbackDeviceType for SAT and USB bridge probes, FallbackScan,
+// FallbackOpenRetry), so applications can record latency, failure counts and
+// fallbacks. Hooks run on the calling goroutine and must be safe for
+// concurrent use. This option is only effective when using the default
+// ExecBackend.
+func WithCommandHook(hook CommandHook) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecCommandHook(hook))
+	}
+}
+
+// WithOpenRetry retries smartctl up to retries more times when the device is
+// busy (ErrDeviceBusy), as is common right after hotplug, waiting delay before
+// the first retry and doubling the wait after each one. Zero retries, the
+// default, fails immediately. This option is only effective when using the
+// default ExecBackend.
+func WithOpenRetry(retries int, delay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecOpenRetry(retries, delay))
+	}
+}
+
+// WithCacheTTL memoizes GetSMARTInfo, GetSMARTInfoRaw and GetDeviceInfo
+// results per device for ttl, so frequent pollers such as UIs do not run
+// smartctl (and wake drives) on every call. Errors are not cached. Self-test
+// and SMART enable/disable calls drop the device's entries. Every caller gets
+// its own deep copy of a cached SMARTInfo, so changing it does not affect the
+// cache or other callers. Zero disables caching.
+// It works with any backend.
+func WithCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.cache = nil
+		if ttl > 0 {
+			c.cache = newResultCache(ttl)
+		}
+	}
+}
+
+// WithSelfTestPollInterval sets how often RunSelfTestWithProgress,
+// RunSelfTestWithOptions and RunSelfTestAndWait poll a running self-test.
+// Zero, the default, selects an adaptive schedule: a first poll after 5
+// seconds, so a test the drive refused or aborted at once is noticed early,
+// then rare polls in a long test, up to every 15 minutes, and more frequent
+// ones, down to every 5 seconds, as the expected end approaches. A test that
+// overruns its expected duration is polled at least every minute. Use a fixed
+// interval for drives whose advertised test duration is unreliable, and
+// ContextWithSelfTestPollInterval to override it for one call.
+func WithSelfTestPollInterval(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.selfTestPoll = max(interval, 0)
+	}
+}
+
+// WithTextOutput forces the degraded text output parser, which is otherwise
+// selected automatically for smartctl releases older than 7.0 that cannot
+// print JSON. Only identity, health, attributes and self-test status are
+// available in this mode. This option is only effective with ExecBackend.
+func WithTextOutput(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecTextOutput(enabled))
+	}
+}
+
+// WithStrictJSONFormat makes calls fail with ErrUnsupportedJSONFormat when
+// smartctl reports a json_format_version major version newer than
+// SupportedJSONFormatMajor, instead of parsing it on a best-effort basis and
+// logging a warning. This option is only effective with ExecBackend.
+func WithStrictJSONFormat(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecStrictJSONFormat(enabled))
+	}
+}
+
+// WithSudo runs smartctl through "sudo -n" when the process is not root, so
+// callers need not wrap the binary path themselves. sudo must allow smartctl
+// without a password; otherwise calls fail with ErrElevationFailed instead of
+// blocking on a prompt. This option is only effective with ExecBackend.
+func WithSudo() ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecSudo())
+	}
+}
+
+// WithElevationCommand is like WithSudo but uses another elevation tool, e.g.
+// WithElevationCommand("doas", "-n"). args are inserted between command and the
+// smartctl path. This option is only effective with ExecBackend.
+func WithElevationCommand(command string, args ...string) ClientOption {
+	return func(c *Client) {
+		c.pendingExecOpts = append(c.pendingExecOpts, WithExecElevationCommand(command, args...))
+	}
+}
+
 // WithContext sets a default context to use when methods are called with nil context.
 func WithContext(ctx context.Context) ClientOption {
 	return func(c *Client) {
@@ -80,13 +340,25 @@ var (
 )
 
 // SmartClient interface defines the methods for interacting with smartmontools.
+// *Client implements it. Methods are added as the library grows, so other
+// implementations should embed a SmartClient or use
+// smartmontoolstest.MockClient to keep compiling across minor versions.
 type SmartClient interface {
 	ScanDevices(ctx context.Context) ([]Device, error)
+	ScanDevicesWithOptions(ctx context.Context, opts ScanOptions) ([]Device, error)
+	Devices(ctx context.Context) iter.Seq2[Device, error]
 	GetSMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, error)
+	GetSMARTInfoRaw(ctx context.Context, devicePath string) (*SMARTInfo, json.RawMessage, error)
+	RunSmartctl(ctx context.Context, args ...string) (json.RawMessage, *ExitStatus, error)
 	CheckHealth(ctx context.Context, devicePath string) (bool, error)
 	GetDeviceInfo(ctx context.Context, devicePath string) (map[string]interface{}, error)
 	RunSelfTest(ctx context.Context, devicePath string, testType string) error
 	RunSelfTestWithProgress(ctx context.Context, devicePath string, testType string, callback ProgressCallback) error
+	RunSelfTestWithOptions(ctx context.Context, devicePath string, testType string, opts SelfTestOptions, callback ProgressCallback) error
+	RunSelfTestAndWait(ctx context.Context, devicePath string, testType string) (*SelfTestResult, error)
+	RunSelectiveSelfTest(ctx context.Context, devicePath string, spans []LBASpan) error
+	RunSelectiveSelfTestWithOptions(ctx context.Context, devicePath string, spans []LBASpan, opts SelectiveSelfTestOptions) error
+	GetSelectiveSelfTestLog(ctx context.Context, devicePath string) (*SelectiveSelfTestLog, error)
 	GetAvailableSelfTests(ctx context.Context, devicePath string) (*SelfTestInfo, error)
 	GetAvailableSelfTestsFromInfo(smartInfo *SMARTInfo) *SelfTestInfo
 	IsSMARTSupported(ctx context.Context, devicePath string) (*SmartSupport, error)
@@ -95,6 +367,27 @@ type SmartClient interface {
 	DisableSMART(ctx context.Context, devicePath string) error
 	AbortSelfTest(ctx context.Context, devicePath string) error
 	DiscoverDevices(ctx context.Context) ([]DiscoveryResult, error)
+	SmartctlVersion(ctx context.Context) (*SmartctlVersionInfo, error)
+	GetExtendedSMARTInfo(ctx context.Context, devicePath string) (*ExtendedSMARTInfo, error)
+	Watch(ctx context.Context, devicePath string, interval time.Duration) <-chan Reading
+	GetPowerMode(ctx context.Context, devicePath string) (PowerMode, error)
+	SetStandbyTimer(ctx context.Context, devicePath string, timeout time.Duration) error
+	StandbyNow(ctx context.Context, devicePath string) error
+	WakeDevice(ctx context.Context, devicePath string) error
+	GetWriteCache(ctx context.Context, devicePath string) (bool, error)
+	SetWriteCache(ctx context.Context, devicePath string, enabled bool) error
+	GetWriteCacheReorder(ctx context.Context, devicePath string) (bool, error)
+	SetWriteCacheReorder(ctx context.Context, devicePath string, enabled bool) error
+	GetDSN(ctx context.Context, devicePath string) (bool, error)
+	SetDSN(ctx context.Context, devicePath string, enabled bool) error
+	EnableAttributeAutosave(ctx context.Context, devicePath string) error
+	DisableAttributeAutosave(ctx context.Context, devicePath string) error
+	EnableAutoOfflineCollection(ctx context.Context, devicePath string) error
+	DisableAutoOfflineCollection(ctx context.Context, devicePath string) error
+	SecurityFreeze(ctx context.Context, devicePath string) error
+	GetSecureEraseInfo(ctx context.Context, devicePath string) (*SecureEraseInfo, error)
+	CollectAll(ctx context.Context, opts CollectOptions) (map[string]CollectResult, error)
+	FleetHealth(ctx context.Context) (*FleetSummary, error)
 	Close() error
 }
 
@@ -105,6 +398,9 @@ type Client struct {
 	logHandler      LogAdapter // staging: propagated to ExecBackend during NewClient
 	defaultCtx      context.Context
 	pendingExecOpts []ExecBackendOption // staging: collected during option application, consumed by NewClient
+	cache           *resultCache        // set by WithCacheTTL
+	filter          *deviceFilter       // set by WithDeviceFilter
+	selfTestPoll    time.Duration       // set by WithSelfTestPollInterval
 }
 
 // NewClient creates a new smartmontools client with optional configuration.
@@ -120,6 +416,11 @@ func NewClient(opts ...ClientOption) (SmartClient, error) {
 	for _, opt := range opts {
 		opt(client)
 	}
+	if client.filter != nil {
+		if err := client.filter.validate(); err != nil {
+			return nil, err
+		}
+	}
 	if client.backend == nil {
 		execOpts := append([]ExecBackendOption{WithExecLogHandler(client.logHandler)}, client.pendingExecOpts...)
 		backend, err := NewExecBackend(execOpts...)
@@ -147,12 +448,132 @@ func (c *Client) Close() error {
 
 // ScanDevices scans for available storage devices.
 func (c *Client) ScanDevices(ctx context.Context) ([]Device, error) {
-	return c.backend.ScanDevices(c.resolveCtx(ctx))
+	devices, err := c.backend.ScanDevices(c.resolveCtx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return c.filterDevices(devices), nil
+}
+
+// Devices returns an iterator over the devices reported by ScanDevices. The
+// scan runs when the iteration starts. A scan failure, or ctx ending before
+// all devices were yielded, is yielded once as the error with a zero Device
+// and ends the iteration.
+func (c *Client) Devices(ctx context.Context) iter.Seq2[Device, error] {
+	return func(yield func(Device, error) bool) {
+		ctx := c.resolveCtx(ctx)
+		devices, err := c.ScanDevices(ctx)
+		if err != nil {
+			yield(Device{}, err)
+			return
+		}
+		for _, d := range devices {
+			if err := ctx.Err(); err != nil {
+				yield(Device{}, err)
+				return
+			}
+			if !yield(d, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ScanDevicesWithOptions scans for the storage devices matching opts. Backends
+// implementing ScanBackend only probe the requested device types; with other
+// backends all devices are scanned and the result is filtered by type.
+func (c *Client) ScanDevicesWithOptions(ctx context.Context, opts ScanOptions) ([]Device, error) {
+	ctx = c.resolveCtx(ctx)
+	if sb, ok := c.backend.(ScanBackend); ok {
+		devices, err := sb.ScanDevicesWithOptions(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return c.filterDevices(devices), nil
+	}
+	devices, err := c.ScanDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var matching []Device
+	for _, d := range devices {
+		if opts.HasType(d.Type) && opts.MatchesInterface(d) {
+			matching = append(matching, d)
+		}
+	}
+	return matching, nil
 }
 
 // GetSMARTInfo retrieves SMART information for a device.
 func (c *Client) GetSMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, error) {
-	return c.backend.GetSMARTInfo(c.resolveCtx(ctx), devicePath)
+	if c.readsCache(ctx) {
+		if info, _, ok := c.cache.getSMARTInfo(devicePath, false); ok {
+			return info, nil
+		}
+	}
+	return c.refreshSMARTInfo(ctx, devicePath)
+}
+
+// refreshSMARTInfo queries the backend, bypassing and then updating the cache.
+func (c *Client) refreshSMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, error) {
+	info, err := c.backend.GetSMARTInfo(c.resolveCtx(ctx), devicePath)
+	if err == nil && c.cache != nil {
+		c.cache.putSMARTInfo(devicePath, info, nil)
+	}
+	return info, err
+}
+
+// GetSMARTInfoRaw is like GetSMARTInfo but also returns the original smartctl
+// JSON output, so applications can store or forward it and read fields that
+// SMARTInfo does not model. Backends that do not implement RawBackend get the
+// parsed SMARTInfo re-encoded as JSON instead.
+func (c *Client) GetSMARTInfoRaw(ctx context.Context, devicePath string) (*SMARTInfo, json.RawMessage, error) {
+	ctx = c.resolveCtx(ctx)
+	if c.readsCache(ctx) {
+		if info, raw, ok := c.cache.getSMARTInfo(devicePath, true); ok {
+			return info, raw, nil
+		}
+	}
+	info, raw, err := c.getSMARTInfoRaw(ctx, devicePath)
+	if err == nil && c.cache != nil {
+		c.cache.putSMARTInfo(devicePath, info, raw)
+	}
+	return info, raw, err
+}
+
+func (c *Client) getSMARTInfoRaw(ctx context.Context, devicePath string) (*SMARTInfo, json.RawMessage, error) {
+	if rb, ok := c.backend.(RawBackend); ok {
+		return rb.GetSMARTInfoRaw(ctx, devicePath)
+	}
+	info, err := c.backend.GetSMARTInfo(ctx, devicePath)
+	if info == nil {
+		return nil, nil, err
+	}
+	raw, marshalErr := json.Marshal(info)
+	if marshalErr != nil {
+		return info, nil, errors.Join(err, fmt.Errorf("failed to encode SMART info: %w", marshalErr))
+	}
+	return info, raw, err
+}
+
+// RunSmartctl runs smartctl with args, adding -j unless they select JSON
+// output, and returns the JSON it printed and its exit status. It is an escape
+// hatch for smartctl features the typed API does not cover yet, e.g.
+// RunSmartctl(ctx, "-l", "devstat", "/dev/sda"), which still goes through the
+// client's smartctl path, elevation, per-device locking and device options.
+// Health bits in the exit status are not an error; execution failures are
+// returned as *SmartctlError along with any JSON output. Commands that change
+// the device's state (-s, -t, -X, -o, -S) drop its cached results. It fails
+// for backends that do not implement SmartctlBackend.
+func (c *Client) RunSmartctl(ctx context.Context, args ...string) (json.RawMessage, *ExitStatus, error) {
+	ctx = c.resolveCtx(ctx)
+	if sb, ok := c.backend.(SmartctlBackend); ok {
+		if device := smexec.ModifiedDevice(args...); device != "" {
+			defer c.invalidate(device)
+		}
+		return sb.RunSmartctl(ctx, args...)
+	}
+	return nil, nil, fmt.Errorf("backend %q does not run smartctl commands", c.backend.Name())
 }
 
 // CheckHealth checks if a device is healthy according to SMART.
@@ -162,151 +583,206 @@ func (c *Client) CheckHealth(ctx context.Context, devicePath string) (bool, erro
 
 // GetDeviceInfo retrieves basic device information.
 func (c *Client) GetDeviceInfo(ctx context.Context, devicePath string) (map[string]interface{}, error) {
-	return c.backend.GetDeviceInfo(c.resolveCtx(ctx), devicePath)
+	if c.readsCache(ctx) {
+		if info, ok := c.cache.getDeviceInfo(devicePath); ok {
+			return info, nil
+		}
+	}
+	info, err := c.backend.GetDeviceInfo(c.resolveCtx(ctx), devicePath)
+	if err == nil && c.cache != nil {
+		c.cache.putDeviceInfo(devicePath, info)
+	}
+	return info, err
 }
 
 // RunSelfTest initiates a SMART self-test.
 func (c *Client) RunSelfTest(ctx context.Context, devicePath string, testType string) error {
+	defer c.invalidate(devicePath)
 	return c.backend.RunSelfTest(c.resolveCtx(ctx), devicePath, testType)
 }
 
-// RunSelfTestWithProgress starts a SMART self-test and reports progress.
-func (c *Client) RunSelfTestWithProgress(ctx context.Context, devicePath string, testType string, callback ProgressCallback) error {
+// RunSelfTestWithOptions runs a SMART self-test with opts. Without options it
+// behaves like RunSelfTestWithProgress, or like RunSelfTest when callback is
+// nil. A captive test blocks until the drive has finished it; callback, when
+// set, is told when it starts and whether it completed or failed. Captive
+// tests fail for backends that do not implement SelfTestBackend. The final
+// update of a captive test passes SelfTestUnknown as its outcome; read the
+// self-test log for the result.
+func (c *Client) RunSelfTestWithOptions(ctx context.Context, devicePath string, testType string, opts SelfTestOptions, callback ProgressCallback) error {
+	if !opts.Captive {
+		if callback == nil {
+			return c.RunSelfTest(ctx, devicePath, testType)
+		}
+		return c.runSelfTestWithProgress(ctx, devicePath, testType, c.selfTestPollInterval(ctx), callback)
+	}
 	ctx = c.resolveCtx(ctx)
-	// Valid test types: short, long, conveyance, offline
-	if !slices.Contains(smtypes.ValidSelfTestTypes, testType) {
-		return fmt.Errorf("invalid test type: %s (must be one of: short, long, conveyance, offline)", testType)
+	sb, ok := c.backend.(SelfTestBackend)
+	if !ok {
+		return fmt.Errorf("backend %q does not run captive self-tests", c.backend.Name())
+	}
+	defer c.invalidate(devicePath)
+	if callback != nil {
+		callback(0, fmt.Sprintf("Captive test started (devicePath: %s, testType: %s)", devicePath, testType), ProgressEstimated, "")
+	}
+	if err := sb.RunSelfTestWithOptions(ctx, devicePath, testType, opts); err != nil {
+		if callback != nil {
+			callback(100, fmt.Sprintf("Captive test failed: %v (devicePath: %s, testType: %s)", err, devicePath, testType), ProgressMeasured, SelfTestUnknown)
+		}
+		return err
+	}
+	if callback != nil {
+		callback(100, fmt.Sprintf("Captive test completed (devicePath: %s, testType: %s)", devicePath, testType), ProgressMeasured, SelfTestUnknown)
 	}
+	return nil
+}
 
-	// First check if self-tests are supported and get durations
-	selfTestInfo, err := c.GetAvailableSelfTests(ctx, devicePath)
-	if err != nil {
-		return fmt.Errorf("failed to get self-test info: %w", err)
+// RunSelectiveSelfTest starts an ATA selective self-test over up to
+// MaxSelectiveSpans LBA spans, so large drives can be surface-scanned region
+// by region. Follow it with GetSelectiveSelfTestLog. It fails for backends
+// that do not implement SelfTestBackend.
+func (c *Client) RunSelectiveSelfTest(ctx context.Context, devicePath string, spans []LBASpan) error {
+	ctx = c.resolveCtx(ctx)
+	if sb, ok := c.backend.(SelfTestBackend); ok {
+		defer c.invalidate(devicePath)
+		return sb.RunSelectiveSelfTest(ctx, devicePath, spans)
 	}
+	return fmt.Errorf("backend %q does not run selective self-tests", c.backend.Name())
+}
 
-	if len(selfTestInfo.Available) == 0 {
-		return fmt.Errorf("self-tests are not supported by this device")
+// RunSelectiveSelfTestWithOptions starts an ATA selective self-test over
+// explicit spans or, with opts.Continue, over the spans following or
+// repeating those of the previous run, so a scheduled job can walk a whole
+// drive across nights. opts also controls the scan of the rest of the disk
+// after the spans. It fails for backends that do not implement
+// SelfTestBackend.
+func (c *Client) RunSelectiveSelfTestWithOptions(ctx context.Context, devicePath string, spans []LBASpan, opts SelectiveSelfTestOptions) error {
+	ctx = c.resolveCtx(ctx)
+	if sb, ok := c.backend.(SelfTestBackend); ok {
+		defer c.invalidate(devicePath)
+		return sb.RunSelectiveSelfTestWithOptions(ctx, devicePath, spans, opts)
 	}
+	return fmt.Errorf("backend %q does not run selective self-tests", c.backend.Name())
+}
+
+// GetSelectiveSelfTestLog reads the ATA selective self-test log: the
+// configured spans, their status and the remainder scan settings. It fails
+// for backends that do not implement SelfTestBackend.
+func (c *Client) GetSelectiveSelfTestLog(ctx context.Context, devicePath string) (*SelectiveSelfTestLog, error) {
+	ctx = c.resolveCtx(ctx)
+	if sb, ok := c.backend.(SelfTestBackend); ok {
+		return sb.GetSelectiveSelfTestLog(ctx, devicePath)
+	}
+	return nil, fmt.Errorf("backend %q does not run selective self-tests", c.backend.Name())
+}
 
-	// Check if the requested test is available
-	if !slices.Contains(selfTestInfo.Available, testType) {
-		return fmt.Errorf("test type %s is not available for this device", testType)
+// readsCache reports whether a call may be answered from the WithCacheTTL
+// cache. Calls overriding the --nocheck policy want a fresh reading.
+func (c *Client) readsCache(ctx context.Context) bool {
+	if c.cache == nil {
+		return false
+	}
+	_, override := smtypes.NoCheckFromContext(ctx)
+	return !override
+}
+
+// invalidate drops cached results of devicePath. State-changing methods defer
+// it, so that it runs once the backend command has returned, and a read that
+// raced with the command cannot leave the previous state cached.
+func (c *Client) invalidate(devicePath string) {
+	if c.cache != nil {
+		c.cache.invalidate(devicePath)
+	}
+}
+
+// RunSelfTestWithProgress starts a SMART self-test and reports progress. The
+// callback receives the percentage the drive measured (ATA remaining_percent
+// or NVMe current completion) when it reports one, and otherwise an estimate
+// extrapolated from the elapsed time; the ProgressSource tells which. Reported
+// progress never goes backwards and reaches 100 only when the test has ended.
+// As in RunSelfTestAndWait, the newest self-test log entry is read before the
+// test starts, so that the previous test's entry is not reported as the end
+// of this one while the drive has not flagged it as running yet.
+//
+// The test runs in the background, so its outcome cannot be returned. The
+// final update, at 100, passes it to the callback as a SelfTestOutcome; when
+// the test was aborted or interrupted, its status also starts with
+// "Test aborted" or "Test interrupted" instead of the usual completion text.
+// Use RunSelfTestAndWait to get the outcome as a SelfTestResult and an error.
+//
+// The drive is polled as described for WithSelfTestPollInterval, or as set by
+// ContextWithSelfTestPollInterval for this call.
+func (c *Client) RunSelfTestWithProgress(ctx context.Context, devicePath string, testType string, callback ProgressCallback) error {
+	return c.runSelfTestWithProgress(ctx, devicePath, testType, c.selfTestPollInterval(ctx), callback)
+}
+
+// runSelfTestWithProgress implements RunSelfTestWithProgress, polling every
+// poll or, when poll is zero, on the adaptive schedule.
+func (c *Client) runSelfTestWithProgress(ctx context.Context, devicePath string, testType string, poll time.Duration, callback ProgressCallback) error {
+	ctx = c.resolveCtx(ctx)
+	selfTestInfo, err := c.checkSelfTest(ctx, devicePath, testType)
+	if err != nil {
+		return err
 	}
+	baseline := c.selfTestBaseline(ctx, devicePath)
 
 	// Start the self-test
 	if err := c.RunSelfTest(ctx, devicePath, testType); err != nil {
 		return err
 	}
-	go func() {
-
+	report := func(progress int, status string, source ProgressSource, outcome SelfTestOutcome) {
 		if callback != nil {
-			callback(0, "Test started")
+			callback(progress, fmt.Sprintf("%s (devicePath: %s, testType: %s)", status, devicePath, testType), source, outcome)
 		}
-
-		// Get expected duration based on test type
-		expectedMinutes := map[string]int{
-			"short":      2,
-			"long":       120,
-			"conveyance": 5,
-			"offline":    10,
-		}[testType]
-
-		// Use duration from capabilities if available
-		if duration, ok := selfTestInfo.Durations[testType]; ok && duration > 0 {
-			expectedMinutes = duration
+	}
+	go func() {
+		if callback != nil {
+			callback(0, "Test started", ProgressEstimated, "")
 		}
 
-		// Poll for completion using an adaptive interval: at most 24 samples over
-		// the expected duration, clamped between 5 s and 60 s. This avoids waking
-		// the disk 1 440 times for a 2-hour long test.
-		pollIntervalSecs := max(5, min(60, expectedMinutes*60/24))
-		ticker := time.NewTicker(time.Duration(pollIntervalSecs) * time.Second)
-		defer ticker.Stop()
-
-		elapsed := 0
+		start := time.Now()
+		estimator := &progressEstimator{expected: time.Duration(expectedSelfTestMinutes(testType, selfTestInfo)) * time.Minute}
+		timer := time.NewTimer(selfTestFirstPoll(poll))
+		defer timer.Stop()
+		seenRunning := false
 		for {
 			select {
-			case <-ticker.C:
-				elapsed += pollIntervalSecs
-				progress := (elapsed * 100) / (expectedMinutes * 60)
-				if progress > 100 {
-					progress = 100
-				}
-
-				// Check if test is complete
-				info, err := c.GetSMARTInfo(ctx, devicePath)
-				if err != nil {
-					if callback != nil {
-						callback(progress, fmt.Sprintf("Error checking status: %v (devicePath: %s, testType: %s)", err, devicePath, testType))
-					}
-					continue
-				}
-
-				// Using SMART infor remaining_percent if available
-				if info.AtaSmartData != nil && info.AtaSmartData.SelfTest != nil && info.AtaSmartData.SelfTest.Status != nil && info.AtaSmartData.SelfTest.Status.RemainingPercent != nil {
-					remaining := *info.AtaSmartData.SelfTest.Status.RemainingPercent
-					calculatedProgress := 100 - remaining
-					if calculatedProgress > progress {
-						progress = calculatedProgress
-					}
-				}
-
-				// Check ATA self-test status
-				if info.AtaSmartData != nil && info.AtaSmartData.SelfTest != nil {
-					if info.AtaSmartData.SelfTest.Status != nil {
-
-						if callback != nil {
-							callback(progress, fmt.Sprintf("%s (devicePath: %s, testType: %s)", info.AtaSmartData.SelfTest.Status.String, devicePath, testType))
-						}
-
-						if info.AtaSmartData.SelfTest.Status.Value <= 240 || progress >= 100 {
-							// Test complete
-							if callback != nil {
-								callback(100, fmt.Sprintf("%s (devicePath: %s, testType: %s)", info.AtaSmartData.SelfTest.Status.String, devicePath, testType))
-							}
-							return
-						}
-
-					}
-				}
-
-				// For NVMe, check self-test log
-				if info.NvmeSmartTestLog != nil {
-					if info.NvmeSmartTestLog.CurrentOpeation != nil && *info.NvmeSmartTestLog.CurrentOpeation == 0 {
-						// No current operation means test is complete
-						if callback != nil {
-							callback(100, fmt.Sprintf("Test completed (devicePath: %s, testType: %s)", devicePath, testType))
-						}
-						return
-					} else if info.NvmeSmartTestLog.CurrentCompletion != nil {
-						progress = *info.NvmeSmartTestLog.CurrentCompletion
-						if callback != nil {
-							callback(progress, fmt.Sprintf("Test in progress (devicePath: %s, testType: %s)", devicePath, testType))
-						}
-					}
-				}
-
-				// For NVMe devices, check completion differently
-				// NVMe devices may not report progress the same way
-				if info.NvmeSmartHealth != nil {
-					// If we've reached expected duration, assume complete
-					if elapsed >= expectedMinutes*60 {
-						if callback != nil {
-							callback(100, fmt.Sprintf("Test completed (devicePath: %s, testType: %s)", devicePath, testType))
-						}
-						return
-					}
-					if callback != nil {
-						callback(progress, "Test in progress")
-					}
-				}
-
+			case <-timer.C:
 			case <-ctx.Done():
 				if callback != nil {
-					callback(0, "Test cancelled")
+					callback(0, "Test cancelled", ProgressEstimated, "")
 				}
 				return
 			}
+			elapsed := time.Since(start)
+			timer.Reset(selfTestPollDelay(poll, estimator.expected, elapsed))
+
+			info, err := c.refreshSMARTInfo(ctx, devicePath)
+			if err != nil {
+				report(estimator.estimate(elapsed), fmt.Sprintf("Error checking status: %v", err), ProgressEstimated, "")
+				continue
+			}
+			overdue := elapsed >= estimator.expected
+			status, source, done := selfTestFinished(info, overdue)
+			if smtypes.SelfTestInProgress(info) {
+				seenRunning = true
+			} else if done && !selfTestStarted(info, baseline, seenRunning, overdue) {
+				done = false
+				status, source = "Test in progress", ProgressEstimated
+			}
+			switch {
+			case done:
+				outcome, detail := selfTestOutcome(info)
+				if outcome == SelfTestAborted || outcome == SelfTestInterrupted {
+					status = fmt.Sprintf("Test %s: %s", outcome, detail)
+				}
+				report(100, status, source, outcome)
+				return
+			case source == ProgressMeasured:
+				percent, _ := measuredSelfTestProgress(info)
+				report(estimator.measure(elapsed, percent), status, ProgressMeasured, "")
+			default:
+				report(estimator.estimate(elapsed), status, ProgressEstimated, "")
+			}
 		}
 	}()
 	return nil
@@ -445,29 +921,257 @@ func (c *Client) IsSMARTSupported(ctx context.Context, devicePath string) (*Smar
 
 // EnableSMART enables SMART monitoring on a device.
 func (c *Client) EnableSMART(ctx context.Context, devicePath string) error {
+	defer c.invalidate(devicePath)
 	return c.backend.EnableSMART(c.resolveCtx(ctx), devicePath)
 }
 
 // DisableSMART disables SMART monitoring on a device.
 func (c *Client) DisableSMART(ctx context.Context, devicePath string) error {
+	defer c.invalidate(devicePath)
 	return c.backend.DisableSMART(c.resolveCtx(ctx), devicePath)
 }
 
 // AbortSelfTest aborts a running self-test on a device.
 func (c *Client) AbortSelfTest(ctx context.Context, devicePath string) error {
+	defer c.invalidate(devicePath)
 	return c.backend.AbortSelfTest(c.resolveCtx(ctx), devicePath)
 }
 
+// SmartctlVersion returns the version, svn revision, platform and drive
+// database version of the smartctl binary used by the backend. It fails for
+// backends that do not implement VersionBackend.
+func (c *Client) SmartctlVersion(ctx context.Context) (*SmartctlVersionInfo, error) {
+	ctx = c.resolveCtx(ctx)
+	if vb, ok := c.backend.(VersionBackend); ok {
+		return vb.SmartctlVersion(ctx)
+	}
+	return nil, fmt.Errorf("backend %q does not report a smartctl version", c.backend.Name())
+}
+
+// GetExtendedSMARTInfo retrieves the "smartctl -x" information of a device:
+// the GetSMARTInfo data plus SCT status, SATA Phy event counters, device
+// statistics and the log directory. It fails for backends that do not
+// implement ExtendedBackend.
+func (c *Client) GetExtendedSMARTInfo(ctx context.Context, devicePath string) (*ExtendedSMARTInfo, error) {
+	ctx = c.resolveCtx(ctx)
+	if eb, ok := c.backend.(ExtendedBackend); ok {
+		return eb.GetExtendedSMARTInfo(ctx, devicePath)
+	}
+	return nil, fmt.Errorf("backend %q does not provide extended SMART info", c.backend.Name())
+}
+
+// GetPowerMode reports whether a drive is active, idle, in standby or asleep
+// without waking it, so callers can decide whether to poll it now. It fails
+// for backends that do not implement PowerModeBackend.
+func (c *Client) GetPowerMode(ctx context.Context, devicePath string) (PowerMode, error) {
+	ctx = c.resolveCtx(ctx)
+	if pb, ok := c.backend.(PowerModeBackend); ok {
+		return pb.GetPowerMode(ctx, devicePath)
+	}
+	return PowerModeUnknown, fmt.Errorf("backend %q does not report power modes", c.backend.Name())
+}
+
+// SetStandbyTimer sets the spindown timeout of a drive ("smartctl -s
+// standby,N"), rounded up to the next value ATA can express, at most
+// MaxStandbyTimer. Zero disables spindown. It fails for backends that do not
+// implement PowerModeBackend.
+func (c *Client) SetStandbyTimer(ctx context.Context, devicePath string, timeout time.Duration) error {
+	ctx = c.resolveCtx(ctx)
+	if pb, ok := c.backend.(PowerModeBackend); ok {
+		defer c.invalidate(devicePath)
+		return pb.SetStandbyTimer(ctx, devicePath, timeout)
+	}
+	return fmt.Errorf("backend %q does not manage power modes", c.backend.Name())
+}
+
+// StandbyNow spins a drive down immediately ("smartctl -s standby,now"). It
+// fails for backends that do not implement PowerModeBackend.
+func (c *Client) StandbyNow(ctx context.Context, devicePath string) error {
+	ctx = c.resolveCtx(ctx)
+	if pb, ok := c.backend.(PowerModeBackend); ok {
+		defer c.invalidate(devicePath)
+		return pb.StandbyNow(ctx, devicePath)
+	}
+	return fmt.Errorf("backend %q does not manage power modes", c.backend.Name())
+}
+
+// WakeDevice spins a drive up on purpose, so waking it before a battery of
+// queries or a self-test is an explicit, logged action rather than a side
+// effect. It fails for backends that do not implement PowerModeBackend.
+func (c *Client) WakeDevice(ctx context.Context, devicePath string) error {
+	ctx = c.resolveCtx(ctx)
+	if pb, ok := c.backend.(PowerModeBackend); ok {
+		defer c.invalidate(devicePath)
+		return pb.WakeDevice(ctx, devicePath)
+	}
+	return fmt.Errorf("backend %q does not manage power modes", c.backend.Name())
+}
+
+// GetWriteCache reports whether the volatile write cache of a drive is
+// enabled ("smartctl -g wcache"). Drives that cannot report it fail with
+// ErrFeatureNotSupported. It fails for backends that do not implement
+// FeatureBackend.
+func (c *Client) GetWriteCache(ctx context.Context, devicePath string) (bool, error) {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		return fb.GetWriteCache(ctx, devicePath)
+	}
+	return false, fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// SetWriteCache enables or disables the volatile write cache of a drive
+// ("smartctl -s wcache,on|off"). It fails for backends that do not implement
+// FeatureBackend.
+func (c *Client) SetWriteCache(ctx context.Context, devicePath string, enabled bool) error {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		defer c.invalidate(devicePath)
+		return fb.SetWriteCache(ctx, devicePath, enabled)
+	}
+	return fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// GetWriteCacheReorder reports whether an ATA drive may reorder writes in its
+// cache ("smartctl -g wcreorder"). It fails for backends that do not
+// implement FeatureBackend.
+func (c *Client) GetWriteCacheReorder(ctx context.Context, devicePath string) (bool, error) {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		return fb.GetWriteCacheReorder(ctx, devicePath)
+	}
+	return false, fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// SetWriteCacheReorder enables or disables write cache reordering on an ATA
+// drive ("smartctl -s wcreorder,on|off"). It fails for backends that do not
+// implement FeatureBackend.
+func (c *Client) SetWriteCacheReorder(ctx context.Context, devicePath string, enabled bool) error {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		defer c.invalidate(devicePath)
+		return fb.SetWriteCacheReorder(ctx, devicePath, enabled)
+	}
+	return fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// GetDSN reports whether Device Statistics Notification is enabled on an ATA
+// drive ("smartctl -g dsn"). Drives without DSN fail with
+// ErrFeatureNotSupported. It fails for backends that do not implement
+// FeatureBackend.
+func (c *Client) GetDSN(ctx context.Context, devicePath string) (bool, error) {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		return fb.GetDSN(ctx, devicePath)
+	}
+	return false, fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// SetDSN enables or disables Device Statistics Notification on an ATA drive
+// ("smartctl -s dsn,on|off"). It fails for backends that do not implement
+// FeatureBackend.
+func (c *Client) SetDSN(ctx context.Context, devicePath string, enabled bool) error {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		defer c.invalidate(devicePath)
+		return fb.SetDSN(ctx, devicePath, enabled)
+	}
+	return fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// EnableAttributeAutosave lets an ATA drive save its SMART attributes across
+// power cycles on its own ("smartctl -S on"), as smartd does at startup. It
+// fails for backends that do not implement FeatureBackend.
+func (c *Client) EnableAttributeAutosave(ctx context.Context, devicePath string) error {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		defer c.invalidate(devicePath)
+		return fb.EnableAttributeAutosave(ctx, devicePath)
+	}
+	return fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// DisableAttributeAutosave turns off SMART attribute autosave on an ATA drive
+// ("smartctl -S off"). It fails for backends that do not implement
+// FeatureBackend.
+func (c *Client) DisableAttributeAutosave(ctx context.Context, devicePath string) error {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		defer c.invalidate(devicePath)
+		return fb.DisableAttributeAutosave(ctx, devicePath)
+	}
+	return fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// EnableAutoOfflineCollection makes an ATA drive run its offline data
+// collection every four hours ("smartctl -o on"), keeping attributes that are
+// only updated offline current. It fails for backends that do not implement
+// FeatureBackend.
+func (c *Client) EnableAutoOfflineCollection(ctx context.Context, devicePath string) error {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		defer c.invalidate(devicePath)
+		return fb.EnableAutoOfflineCollection(ctx, devicePath)
+	}
+	return fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// DisableAutoOfflineCollection stops automatic offline data collection on an
+// ATA drive ("smartctl -o off"). It fails for backends that do not implement
+// FeatureBackend.
+func (c *Client) DisableAutoOfflineCollection(ctx context.Context, devicePath string) error {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		defer c.invalidate(devicePath)
+		return fb.DisableAutoOfflineCollection(ctx, devicePath)
+	}
+	return fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// SecurityFreeze freezes the ATA security feature set of devicePath until its
+// next power cycle ("smartctl -s security-freeze"). Hosts typically do this at
+// boot so malware cannot set a drive password or start an erase. It fails for
+// backends that do not implement FeatureBackend.
+func (c *Client) SecurityFreeze(ctx context.Context, devicePath string) error {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		defer c.invalidate(devicePath)
+		return fb.SecurityFreeze(ctx, devicePath)
+	}
+	return fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
+// GetSecureEraseInfo returns the ATA security state of devicePath and its
+// estimates for a normal and an enhanced secure erase, so decommissioning
+// workflows can plan erase windows. The library never issues erase commands.
+// It fails for backends that do not implement FeatureBackend.
+func (c *Client) GetSecureEraseInfo(ctx context.Context, devicePath string) (*SecureEraseInfo, error) {
+	ctx = c.resolveCtx(ctx)
+	if fb, ok := c.backend.(FeatureBackend); ok {
+		return fb.GetSecureEraseInfo(ctx, devicePath)
+	}
+	return nil, fmt.Errorf("backend %q does not manage drive features", c.backend.Name())
+}
+
 // DiscoverDevices scans all available storage devices and probes each one to
 // determine SMART readability and protocol compatibility.
 func (c *Client) DiscoverDevices(ctx context.Context) ([]DiscoveryResult, error) {
 	ctx = c.resolveCtx(ctx)
 	if db, ok := c.backend.(DiscoveryBackend); ok {
-		return db.DiscoverDevices(ctx)
+		results, err := db.DiscoverDevices(ctx)
+		if err != nil || c.filter == nil {
+			return results, err
+		}
+		allowed := make([]DiscoveryResult, 0, len(results))
+		for _, r := range results {
+			if c.filter.allows(r.DevicePath) {
+				allowed = append(allowed, r)
+			}
+		}
+		return allowed, nil
 	}
 	// Generic fallback for backends that don't implement DiscoveryBackend.
 	// No SAT-fallback details are available in this path.
-	devices, err := c.backend.ScanDevices(ctx)
+	devices, err := c.ScanDevices(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan devices for discovery: %w", err)
 	}
@@ -136,3 +136,19 @@ func TestGetSMARTInfo_SATFallback_SkippedWhenCached(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "Cached Drive", info.ModelName)
 }
+
+func TestGetSMARTInfo_WithAttributePresets(t *testing.T) {
+	mockJSON := `{"device":{"name":"/dev/sda","type":"sat"},"model_name":"Preset Drive","smart_status":{"passed":true}}`
+	commander := &mockCommander{cmds: map[string]*mockCmd{
+		"/usr/sbin/smartctl -a -j --nocheck=standby -v 9,minutes /dev/sda": {output: []byte(mockJSON)},
+	}}
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(commander),
+		WithAttributePresets("/dev/sda", "9,minutes"),
+	)
+	require.NoError(t, err)
+	info, err := client.GetSMARTInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "Preset Drive", info.ModelName)
+}
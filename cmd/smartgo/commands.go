@@ -0,0 +1,460 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/export"
+	"github.com/dianlight/smartmontools-go/history"
+	"github.com/dianlight/smartmontools-go/monitor"
+	"github.com/dianlight/smartmontools-go/rules"
+)
+
+func (a *app) printJSON(v any) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (a *app) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+}
+
+func runScan(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("scan", "")
+	types := fs.String("d", "", "comma-separated device types to scan for, e.g. nvme,sat")
+	interfaces := fs.String("interface", "", "comma-separated device types or transports to keep, e.g. usb")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	var (
+		devices []smartmontools.Device
+		err     error
+	)
+	if *types == "" && *interfaces == "" {
+		devices, err = a.client.ScanDevices(ctx)
+	} else {
+		devices, err = a.client.ScanDevicesWithOptions(ctx, smartmontools.ScanOptions{Types: splitList(*types), Interfaces: splitList(*interfaces)})
+	}
+	if err != nil {
+		return err
+	}
+	if a.json {
+		type device struct {
+			Name       string                   `json:"name"`
+			Type       string                   `json:"type"`
+			InfoName   string                   `json:"info_name,omitempty"`
+			Protocol   string                   `json:"protocol,omitempty"`
+			OpenError  string                   `json:"open_error,omitempty"`
+			NotOpened  bool                     `json:"not_opened,omitempty"`
+			Controller string                   `json:"controller,omitempty"`
+			Namespaces []string                 `json:"namespaces,omitempty"`
+			Virtual    bool                     `json:"virtual,omitempty"`
+			Sysfs      *smartmontools.SysfsInfo `json:"sysfs,omitempty"`
+		}
+		out := make([]device, 0, len(devices))
+		for _, d := range devices {
+			out = append(out, device{Name: d.Name, Type: d.Type, InfoName: d.InfoName, Protocol: d.Protocol, OpenError: d.OpenError, NotOpened: d.NotOpened, Controller: d.Controller, Namespaces: d.Namespaces, Virtual: d.Virtual, Sysfs: d.Sysfs})
+		}
+		return a.printJSON(out)
+	}
+	tw := a.table()
+	fmt.Fprintln(tw, "DEVICE\tTYPE\tTRANSPORT\tMODEL")
+	notOpened := false
+	for _, d := range devices {
+		var transport, model string
+		if d.Sysfs != nil {
+			transport, model = d.Sysfs.Transport, d.Sysfs.Model
+		}
+		name := d.Name
+		if d.NotOpened || d.OpenError != "" {
+			name += "*"
+			notOpened = true
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, d.Type, transport, model)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if notOpened {
+		fmt.Fprintln(a.stderr, "* could not be opened; SMART access may need root or disk group permissions")
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func runInfo(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("info", "<device>")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	info, err := a.client.GetSMARTInfo(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(info)
+	}
+
+	tw := a.table()
+	row := func(label string, value any) { fmt.Fprintf(tw, "%s:\t%v\n", label, value) }
+	row("Device", fs.Arg(0))
+	if info.InStandby {
+		row("Power mode", "STANDBY (SMART data not read)")
+		return tw.Flush()
+	}
+	row("Model", info.ModelName)
+	if info.ModelFamily != "" {
+		row("Family", info.ModelFamily)
+	}
+	row("Serial", info.SerialNumber)
+	row("Firmware", info.Firmware)
+	row("Type", info.DiskType)
+	if info.UserCapacity != nil {
+		row("Capacity", fmt.Sprintf("%.1f GB", float64(info.UserCapacity.Bytes)/1e9))
+	}
+	if info.SmartStatus != nil {
+		row("Health", healthString(info.SmartStatus.Passed))
+	}
+	score := smartmontools.HealthScore(info)
+	if score.Evaluated {
+		row("Health score", score.Value)
+	}
+	if info.Temperature != nil {
+		row("Temperature", fmt.Sprintf("%d °C", info.Temperature.Current))
+	}
+	if info.PowerOnTime != nil {
+		row("Power-on hours", info.PowerOnTime.Hours)
+	}
+	if wear := info.WearLevelPercent(); wear != nil {
+		row("Wear level", fmt.Sprintf("%d%%", *wear))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if info.AtaSmartData != nil && len(info.AtaSmartData.Table) > 0 {
+		fmt.Fprintln(a.stdout)
+		tw = a.table()
+		fmt.Fprintln(tw, "ID\tATTRIBUTE\tVALUE\tWORST\tTHRESH\tRAW")
+		for _, attr := range info.AtaSmartData.Table {
+			raw := attr.Raw.String
+			if raw == "" {
+				raw = fmt.Sprint(attr.Raw.Value)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%s\n", attr.ID, attr.Name, attr.Value, attr.Worst, attr.Thresh, raw)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if h := info.NvmeSmartHealth; h != nil {
+		fmt.Fprintln(a.stdout)
+		tw = a.table()
+		row("Critical warning", fmt.Sprintf("0x%02x", h.CriticalWarning))
+		row("Available spare", fmt.Sprintf("%d%% (threshold %d%%)", h.AvailableSpare, h.AvailableSpareThresh))
+		row("Percentage used", fmt.Sprintf("%d%%", h.PercentageUsed))
+		row("Media errors", h.MediaErrors)
+		row("Error log entries", h.NumErrLogEntries)
+		row("Unsafe shutdowns", h.UnsafeShutdowns)
+		return tw.Flush()
+	}
+	return nil
+}
+
+func healthString(passed bool) string {
+	if passed {
+		return "PASSED"
+	}
+	return "FAILED"
+}
+
+func runHealth(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("health", "<device>...")
+	if err := parse(fs, args, 1, -1); err != nil {
+		return err
+	}
+	type result struct {
+		Device string `json:"device"`
+		Passed bool   `json:"passed"`
+		Error  string `json:"error,omitempty"`
+	}
+	results := make([]result, 0, fs.NArg())
+	failed := false
+	for _, device := range fs.Args() {
+		passed, err := a.client.CheckHealth(ctx, device)
+		r := result{Device: device, Passed: passed}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		failed = failed || !passed || err != nil
+		results = append(results, r)
+	}
+
+	if a.json {
+		if err := a.printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		tw := a.table()
+		fmt.Fprintln(tw, "DEVICE\tHEALTH")
+		for _, r := range results {
+			status := healthString(r.Passed)
+			if r.Error != "" {
+				status = "ERROR: " + r.Error
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", r.Device, status)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if failed {
+		return exitError(exitFailure)
+	}
+	return nil
+}
+
+func runTest(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("test", "<device>")
+	testType := fs.String("type", "short", "self-test type: short, long, conveyance or offline")
+	wait := fs.Bool("wait", false, "wait for the self-test to finish, print its result and exit 1 unless it passed")
+	poll := fs.Duration("poll", 0, "with -wait, poll the drive at this fixed interval instead of the adaptive schedule")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	device := fs.Arg(0)
+	if !*wait {
+		if err := a.client.RunSelfTest(ctx, device, *testType); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "%s self-test started on %s\n", *testType, device)
+		return nil
+	}
+
+	if *poll > 0 {
+		ctx = smartmontools.ContextWithSelfTestPollInterval(ctx, *poll)
+	}
+	if !a.json {
+		fmt.Fprintf(a.stderr, "waiting for the %s self-test on %s to finish\n", *testType, device)
+	}
+	result, err := a.client.RunSelfTestAndWait(ctx, device, *testType)
+	if result == nil {
+		return err
+	}
+	if a.json {
+		if err := a.printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		tw := a.table()
+		row := func(label string, value any) { fmt.Fprintf(tw, "%s:\t%v\n", label, value) }
+		row("Device", result.DevicePath)
+		row("Test", result.TestType)
+		row("Outcome", result.Outcome)
+		row("Status", result.Status)
+		row("Duration", result.Duration.Round(time.Second))
+		if result.RemainingPercent > 0 {
+			row("Remaining", fmt.Sprintf("%d%%", result.RemainingPercent))
+		}
+		if result.FirstErrorLBA != nil {
+			row("First error LBA", *result.FirstErrorLBA)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !result.Passed {
+		return exitError(exitFailure)
+	}
+	return nil
+}
+
+func runWatch(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("watch", "[device...]")
+	interval := fs.Duration("interval", monitor.DefaultInterval, "polling interval")
+	temp := fs.Int("temp", 0, "emit an event when the temperature exceeds this value in °C (0 disables)")
+	tempPolicy := fs.Bool("temp-policy", false, "emit events above the default warning and critical temperatures of each disk type")
+	states := fs.Bool("states", false, "emit an event when a device's health state (ok, warning, failing, unknown, standby) changes")
+	rulesFile := fs.String("rules", "", "JSON file with an array of alert rules ({\"name\",\"expr\",\"severity\",\"message\"})")
+	if err := parse(fs, args, 0, -1); err != nil {
+		return err
+	}
+	if *temp > 0 && *tempPolicy {
+		fmt.Fprintln(a.stderr, "smartgo watch: -temp and -temp-policy cannot be combined")
+		return errUsage
+	}
+	opts := []monitor.Option{monitor.WithInterval(*interval), monitor.WithDevices(fs.Args()...)}
+	switch {
+	case *temp > 0:
+		opts = append(opts, monitor.WithTemperatureThreshold(*temp))
+	case *tempPolicy:
+		opts = append(opts, monitor.WithTemperaturePolicy(smartmontools.DefaultTemperaturePolicy()))
+	}
+	if *states {
+		opts = append(opts, monitor.WithHealthStates())
+	}
+	if *rulesFile != "" {
+		engine, err := loadRules(*rulesFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, monitor.WithRules(engine))
+	}
+
+	m := monitor.New(a.client, opts...)
+	errc := make(chan error, 1)
+	go func() { errc <- m.Run(ctx) }()
+	for event := range m.Events() {
+		if a.json {
+			_ = json.NewEncoder(a.stdout).Encode(struct {
+				Kind  string `json:"kind"`
+				Event any    `json:"event"`
+			}{event.Kind(), event})
+			continue
+		}
+		fmt.Fprintf(a.stdout, "%s  %-8s %-20s %s\n", event.Time().Format(time.RFC3339), event.Device(), event.Kind(), describeEvent(event))
+	}
+	if err := <-errc; err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+func loadRules(path string) (*rules.Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []rules.Rule
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return rules.Compile(list...)
+}
+
+func describeEvent(event monitor.Event) string {
+	switch e := event.(type) {
+	case monitor.HealthChanged:
+		return healthString(e.Passed)
+	case monitor.TemperatureExceeded:
+		return fmt.Sprintf("%s: %d °C > %d °C", e.Level, e.Current, e.Threshold)
+	case monitor.AttributeDegraded:
+		return fmt.Sprintf("%s (%d): value %d -> %d, raw %d -> %d",
+			e.Change.Name, e.Change.ID, e.Change.OldValue, e.Change.NewValue, e.Change.OldRaw, e.Change.NewRaw)
+	case monitor.SelfTestCompleted:
+		return e.Status
+	case monitor.HealthStateChanged:
+		if e.Previous == "" {
+			return fmt.Sprintf("%s: %s", e.Current, e.Reason)
+		}
+		return fmt.Sprintf("%s -> %s: %s", e.Previous, e.Current, e.Reason)
+	case monitor.AlertRaised:
+		return fmt.Sprintf("[%s] %s", e.Alert.Severity, e.Alert.Message)
+	case monitor.PollFailed:
+		return e.Err.Error()
+	}
+	return ""
+}
+
+func runExport(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("export", "<device>... | -db file -serial S")
+	format := fs.String("format", "json", "output format: csv, json or influx")
+	db := fs.String("db", "", "history database (bbolt) to export from instead of reading devices")
+	serial := fs.String("serial", "", "device serial number to export from -db")
+	if err := parse(fs, args, 0, -1); err != nil {
+		return err
+	}
+
+	var snapshots []history.Snapshot
+	if *db != "" {
+		if *serial == "" || fs.NArg() > 0 {
+			fs.Usage()
+			return errUsage
+		}
+		store, err := history.OpenBoltStore(*db, nil)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		if snapshots, err = store.Snapshots(ctx, *serial, history.Query{}); err != nil {
+			return err
+		}
+	} else {
+		if fs.NArg() == 0 {
+			fs.Usage()
+			return errUsage
+		}
+		now := time.Now()
+		for _, device := range fs.Args() {
+			info, err := a.client.GetSMARTInfo(ctx, device)
+			if err != nil {
+				return fmt.Errorf("%s: %w", device, err)
+			}
+			snapshots = append(snapshots, history.Snapshot{Time: now, Serial: info.SerialNumber, Info: info})
+		}
+	}
+
+	switch strings.ToLower(*format) {
+	case "csv":
+		return export.WriteCSV(a.stdout, snapshots)
+	case "json":
+		return export.WriteJSON(a.stdout, snapshots)
+	case "influx":
+		enc := &export.InfluxEncoder{}
+		for _, s := range snapshots {
+			if err := enc.Encode(a.stdout, s.Time, s.Info); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		fmt.Fprintf(a.stderr, "smartgo export: unknown format %q\n", *format)
+		return errUsage
+	}
+}
+
+func runVersion(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("version", "")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	v, err := a.client.SmartctlVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(v)
+	}
+	tw := a.table()
+	fmt.Fprintf(tw, "smartctl\t%s\n", v)
+	if v.Platform != "" {
+		fmt.Fprintf(tw, "platform\t%s\n", v.Platform)
+	}
+	if v.BuildInfo != "" {
+		fmt.Fprintf(tw, "build\t%s\n", v.BuildInfo)
+	}
+	if v.DriveDatabaseVersion != "" {
+		fmt.Fprintf(tw, "drive database\t%s\n", v.DriveDatabaseVersion)
+	}
+	return tw.Flush()
+}
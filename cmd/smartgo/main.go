@@ -0,0 +1,200 @@
+// Command smartgo is a command-line front end for the smartmontools-go
+// library. It scans devices, prints SMART information and health, runs
+// self-tests, watches devices for changes and exports snapshots.
+//
+// Usage:
+//
+//	smartgo [global flags] <command> [flags] [arguments]
+//
+// Commands:
+//
+//	scan                         list devices
+//	info <device>                print SMART information
+//	health <device>...           print the overall-health result
+//	test [-type short] [-wait] [-poll 1m] <device>
+//	                             start a self-test, or run it to the end
+//	                             with -wait and exit 1 unless it passed
+//	watch [-interval 30m] [-temp 55] [-rules file] [device...]
+//	                             poll devices and print events
+//	export [-format csv|json|influx] <device>...
+//	export -db file -serial S [-format csv|json]
+//	                             export snapshots or recorded history
+//	version                      print the smartctl version in use
+//
+// Global flags:
+//
+//	-json            print JSON instead of tables
+//	-smartctl path   use a specific smartctl binary
+//	-v               log debug messages to stderr
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// Exit codes.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// errUsage marks errors caused by invalid command-line arguments.
+var errUsage = errors.New("usage error")
+
+// app carries the state shared by all commands.
+type app struct {
+	client smartmontools.SmartClient
+	stdout io.Writer
+	stderr io.Writer
+	json   bool
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = []command{
+	{"scan", "list devices", runScan},
+	{"info", "print SMART information of a device", runInfo},
+	{"health", "print the overall-health self-assessment of devices", runHealth},
+	{"test", "start a SMART self-test", runTest},
+	{"watch", "poll devices and print health events", runWatch},
+	{"export", "export snapshots or recorded history as CSV, JSON or InfluxDB line protocol", runExport},
+	{"version", "print the version of the smartctl binary in use", runVersion},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr, newClient)
+	stop()
+	os.Exit(code)
+}
+
+// newClient creates the library client used by the commands.
+func newClient(smartctlPath string, logger *slog.Logger) (smartmontools.SmartClient, error) {
+	opts := []smartmontools.ClientOption{smartmontools.WithLogger(logger)}
+	if smartctlPath != "" {
+		opts = append(opts, smartmontools.WithSmartctlPath(smartctlPath))
+	}
+	return smartmontools.NewClient(opts...)
+}
+
+// run parses the global flags, dispatches to a command and returns the
+// process exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer,
+	clientFactory func(smartctlPath string, logger *slog.Logger) (smartmontools.SmartClient, error)) int {
+	fs := flag.NewFlagSet("smartgo", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonOut := fs.Bool("json", false, "print JSON instead of tables")
+	smartctlPath := fs.String("smartctl", "", "path to the smartctl binary")
+	verbose := fs.Bool("v", false, "log debug messages to stderr")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	name := fs.Arg(0)
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "smartgo: unknown command %q\n", name)
+		fs.Usage()
+		return exitUsage
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level}))
+	client, err := clientFactory(*smartctlPath, logger)
+	if err != nil {
+		fmt.Fprintf(stderr, "smartgo: %v\n", err)
+		return exitFailure
+	}
+	defer client.Close()
+
+	a := &app{client: client, stdout: stdout, stderr: stderr, json: *jsonOut}
+	if err := cmd.run(ctx, a, fs.Args()[1:]); err != nil {
+		var exit exitError
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case errors.Is(err, errUsage):
+			return exitUsage
+		case errors.As(err, &exit):
+			return int(exit)
+		}
+		fmt.Fprintf(stderr, "smartgo %s: %v\n", name, err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// exitError makes a command exit with the given code without printing an
+// error, e.g. health reporting a failing drive.
+type exitError int
+
+func (e exitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+func usage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintln(out, "Usage: smartgo [global flags] <command> [flags] [arguments]")
+	fmt.Fprintln(out, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(out, "\nGlobal flags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(out, "\nRun 'smartgo <command> -h' for command flags.")
+}
+
+// newFlagSet returns the flag set of a command, reporting errors to a.stderr.
+func (a *app) newFlagSet(name, arguments string) *flag.FlagSet {
+	fs := flag.NewFlagSet("smartgo "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: smartgo %s [flags] %s\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses args into fs and checks the positional argument count.
+func parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() < minArgs || (maxArgs >= 0 && fs.NArg() > maxArgs) {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
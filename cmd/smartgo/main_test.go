@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient implements the SmartClient methods used by the commands; calling
+// any other method panics through the nil embedded interface.
+type fakeClient struct {
+	smartmontools.SmartClient
+	infos   map[string]*smartmontools.SMARTInfo
+	results map[string]*smartmontools.SelfTestResult
+	started []string
+}
+
+func (f *fakeClient) ScanDevices(ctx context.Context) ([]smartmontools.Device, error) {
+	return []smartmontools.Device{
+		{Name: "/dev/sda", Type: "sat", Sysfs: &smartmontools.SysfsInfo{Transport: "usb", Model: "Elements 25A2"}},
+		{Name: "/dev/nvme0", Type: "nvme", InfoName: "/dev/nvme0", Protocol: "NVMe"},
+		{Name: "/dev/sdb", Type: "scsi", NotOpened: true, Virtual: true},
+	}, nil
+}
+
+func (f *fakeClient) ScanDevicesWithOptions(ctx context.Context, opts smartmontools.ScanOptions) ([]smartmontools.Device, error) {
+	devices, _ := f.ScanDevices(ctx)
+	var matching []smartmontools.Device
+	for _, d := range devices {
+		if opts.HasType(d.Type) && opts.MatchesInterface(d) {
+			matching = append(matching, d)
+		}
+	}
+	return matching, nil
+}
+
+func (f *fakeClient) GetSMARTInfo(ctx context.Context, devicePath string) (*smartmontools.SMARTInfo, error) {
+	if info, ok := f.infos[devicePath]; ok {
+		return info, nil
+	}
+	return nil, errors.New("no such device")
+}
+
+func (f *fakeClient) CheckHealth(ctx context.Context, devicePath string) (bool, error) {
+	info, err := f.GetSMARTInfo(ctx, devicePath)
+	if err != nil {
+		return false, err
+	}
+	return info.SmartStatus.Passed, nil
+}
+
+func (f *fakeClient) RunSelfTest(ctx context.Context, devicePath string, testType string) error {
+	f.started = append(f.started, devicePath+":"+testType)
+	return nil
+}
+
+func (f *fakeClient) RunSelfTestAndWait(ctx context.Context, devicePath string, testType string) (*smartmontools.SelfTestResult, error) {
+	f.started = append(f.started, devicePath+":"+testType)
+	result := f.results[devicePath]
+	if result == nil {
+		return nil, errors.New("no such device")
+	}
+	result.DevicePath, result.TestType = devicePath, testType
+	return result, result.Err()
+}
+
+func (f *fakeClient) SmartctlVersion(ctx context.Context) (*smartmontools.SmartctlVersionInfo, error) {
+	return &smartmontools.SmartctlVersionInfo{Major: 7, Minor: 4, SvnRevision: "5530", Platform: "x86_64-linux-6.1.0", DriveDatabaseVersion: "7.3/5528"}, nil
+}
+
+func (f *fakeClient) Close() error { return nil }
+
+func newFake() *fakeClient {
+	return &fakeClient{infos: map[string]*smartmontools.SMARTInfo{
+		"/dev/sda": {
+			Device:       smartmontools.Device{Name: "/dev/sda"},
+			ModelName:    "TestDisk",
+			SerialNumber: "SER1",
+			SmartStatus:  &smartmontools.SmartStatus{Passed: true},
+			Temperature:  &smartmontools.Temperature{Current: 33},
+			AtaSmartData: &smartmontools.AtaSmartData{Table: []smartmontools.SmartAttribute{
+				{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, Thresh: 10, Raw: smartmontools.Raw{Value: 0, String: "0"}},
+			}},
+		},
+		"/dev/sdb": {
+			Device:       smartmontools.Device{Name: "/dev/sdb"},
+			SerialNumber: "SER2",
+			SmartStatus:  &smartmontools.SmartStatus{Passed: false},
+		},
+	}}
+}
+
+func runWith(t *testing.T, client *fakeClient, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	factory := func(string, *slog.Logger) (smartmontools.SmartClient, error) { return client, nil }
+	code := run(context.Background(), args, &stdout, &stderr, factory)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runWith(t, newFake())
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "Commands:")
+
+	code, _, stderr = runWith(t, newFake(), "bogus")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, `unknown command "bogus"`)
+
+	code, _, _ = runWith(t, newFake(), "info")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runWith(t, newFake(), "-h")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestRun_Scan(t *testing.T) {
+	code, stdout, stderr := runWith(t, newFake(), "scan")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "DEVICE")
+	assert.Contains(t, stdout, "/dev/nvme0  nvme")
+	assert.Contains(t, stdout, "usb        Elements 25A2")
+	assert.Contains(t, stdout, "/dev/sdb*")
+	assert.Contains(t, stderr, "could not be opened")
+
+	code, stdout, _ = runWith(t, newFake(), "-json", "scan")
+	assert.Equal(t, exitOK, code)
+	var devices []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &devices))
+	assert.Equal(t, "sat", devices[0]["type"])
+	assert.Equal(t, "usb", devices[0]["sysfs"].(map[string]any)["transport"])
+	assert.NotContains(t, devices[1], "sysfs")
+	assert.NotContains(t, devices[1], "not_opened")
+	assert.Equal(t, "NVMe", devices[1]["protocol"])
+	assert.Equal(t, true, devices[2]["not_opened"])
+	assert.Equal(t, true, devices[2]["virtual"])
+
+	code, stdout, _ = runWith(t, newFake(), "scan", "-d", "nvme")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "/dev/nvme0")
+	assert.NotContains(t, stdout, "/dev/sda")
+}
+
+func TestRun_Info(t *testing.T) {
+	code, stdout, _ := runWith(t, newFake(), "info", "/dev/sda")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "TestDisk")
+	assert.Contains(t, stdout, "PASSED")
+	assert.Contains(t, stdout, "Reallocated_Sector_Ct")
+
+	code, stdout, _ = runWith(t, newFake(), "-json", "info", "/dev/sda")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"serial_number": "SER1"`)
+
+	code, _, stderr := runWith(t, newFake(), "info", "/dev/missing")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "no such device")
+}
+
+func TestRun_Version(t *testing.T) {
+	code, stdout, _ := runWith(t, newFake(), "version")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "7.4 r5530")
+	assert.Contains(t, stdout, "7.3/5528")
+
+	code, stdout, _ = runWith(t, newFake(), "-json", "version")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"svn_revision": "5530"`)
+}
+
+func TestRun_Health(t *testing.T) {
+	code, stdout, _ := runWith(t, newFake(), "health", "/dev/sda")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "PASSED")
+
+	code, stdout, _ = runWith(t, newFake(), "-json", "health", "/dev/sda", "/dev/sdb")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stdout, `"passed": false`)
+}
+
+func TestRun_Test(t *testing.T) {
+	client := newFake()
+	code, stdout, _ := runWith(t, client, "test", "-type", "long", "/dev/sda")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, []string{"/dev/sda:long"}, client.started)
+	assert.Contains(t, stdout, "long self-test started")
+
+	client.results = map[string]*smartmontools.SelfTestResult{
+		"/dev/sda":   {Outcome: smartmontools.SelfTestPassed, Passed: true, Status: "Completed without error"},
+		"/dev/sdb":   {Outcome: smartmontools.SelfTestFailed, Status: "Completed: read failure", RemainingPercent: 90},
+		"/dev/nvme0": {Outcome: smartmontools.SelfTestAborted, Status: "Aborted: Self-test command"},
+	}
+	code, stdout, _ = runWith(t, client, "test", "-wait", "-poll", "30s", "/dev/sda")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "Outcome:   passed")
+	assert.Contains(t, stdout, "Status:    Completed without error")
+
+	code, stdout, _ = runWith(t, client, "test", "-wait", "/dev/sdb")
+	assert.Equal(t, exitFailure, code, "a failed test exits non-zero")
+	assert.Contains(t, stdout, "Completed: read failure")
+	assert.Contains(t, stdout, "Remaining:  90%")
+
+	code, stdout, stderr := runWith(t, client, "-json", "test", "-wait", "/dev/nvme0")
+	assert.Equal(t, exitFailure, code, "an aborted test exits non-zero")
+	assert.Contains(t, stdout, `"outcome": "aborted"`)
+	assert.Contains(t, stderr, "aborted")
+}
+
+func TestRun_Watch(t *testing.T) {
+	client := newFake()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	factory := func(string, *slog.Logger) (smartmontools.SmartClient, error) { return client, nil }
+	code := run(ctx, []string{"watch", "-interval", "1h", "/dev/sdb"}, &stdout, &stderr, factory)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout.String(), "health_changed")
+	assert.Contains(t, stdout.String(), "FAILED")
+}
+
+func TestRun_WatchTemperatureFlagsConflict(t *testing.T) {
+	code, _, stderr := runWith(t, newFake(), "watch", "-temp", "55", "-temp-policy")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "cannot be combined")
+}
+
+func TestRun_Export(t *testing.T) {
+	code, stdout, _ := runWith(t, newFake(), "export", "-format", "csv", "/dev/sda")
+	assert.Equal(t, exitOK, code)
+	assert.True(t, strings.HasPrefix(stdout, "time,device,serial"))
+
+	code, stdout, _ = runWith(t, newFake(), "export", "-format", "influx", "/dev/sda")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "smart_attribute,")
+
+	code, _, _ = runWith(t, newFake(), "export", "-format", "xml", "/dev/sda")
+	assert.Equal(t, exitUsage, code)
+
+	db := filepath.Join(t.TempDir(), "history.db")
+	store, err := history.OpenBoltStore(db, nil)
+	require.NoError(t, err)
+	require.NoError(t, store.Record(context.Background(), time.Now(), newFake().infos["/dev/sda"]))
+	require.NoError(t, store.Close())
+
+	code, stdout, _ = runWith(t, newFake(), "export", "-db", db, "-serial", "SER1")
+	assert.Equal(t, exitOK, code)
+	var snaps []history.Snapshot
+	require.NoError(t, json.Unmarshal([]byte(stdout), &snaps))
+	assert.Len(t, snaps, 1)
+
+	code, _, _ = runWith(t, newFake(), "export", "-db", db)
+	assert.Equal(t, exitUsage, code)
+}
@@ -0,0 +1,106 @@
+package smartmontools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultCollectWorkers is the number of devices CollectAll queries at once
+// when CollectOptions.Workers is zero.
+const DefaultCollectWorkers = 4
+
+// CollectOptions configures CollectAll.
+type CollectOptions struct {
+	// Devices restricts collection to these device paths, less those excluded
+	// by WithDeviceFilter. When empty, the devices reported by ScanDevices are
+	// collected.
+	Devices []string
+	// Workers bounds the number of concurrent device queries. Zero means
+	// DefaultCollectWorkers.
+	Workers int
+	// Timeout bounds each device query. Zero leaves queries bounded only by ctx.
+	Timeout time.Duration
+}
+
+// CollectResult is the outcome of collecting SMART information from one device.
+type CollectResult struct {
+	Info *SMARTInfo
+	Err  error
+}
+
+// CollectAll fetches SMART information for many devices concurrently, using a
+// bounded pool of workers, and returns the outcome keyed by device path.
+// Per-device failures are reported in CollectResult.Err; the returned error is
+// non-nil only when device scanning fails. Devices not reached before ctx is
+// done report ctx.Err().
+func (c *Client) CollectAll(ctx context.Context, opts CollectOptions) (map[string]CollectResult, error) {
+	ctx = c.resolveCtx(ctx)
+	var devices []string
+	for _, device := range opts.Devices {
+		if c.filter.allows(device) {
+			devices = append(devices, device)
+		}
+	}
+	if len(opts.Devices) == 0 {
+		scanned, err := c.ScanDevices(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan devices: %w", err)
+		}
+		for _, d := range scanned {
+			devices = append(devices, d.Name)
+		}
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultCollectWorkers
+	}
+	workers = min(workers, len(devices))
+
+	results := make(map[string]CollectResult, len(devices))
+	var mu sync.Mutex
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for device := range jobs {
+				info, err := c.collectOne(ctx, device, opts.Timeout)
+				mu.Lock()
+				results[device] = CollectResult{Info: info, Err: err}
+				mu.Unlock()
+			}
+		}()
+	}
+
+send:
+	for i, device := range devices {
+		select {
+		case jobs <- device:
+		case <-ctx.Done():
+			mu.Lock()
+			for _, skipped := range devices[i:] {
+				if _, done := results[skipped]; !done {
+					results[skipped] = CollectResult{Err: ctx.Err()}
+				}
+			}
+			mu.Unlock()
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return results, nil
+}
+
+func (c *Client) collectOne(ctx context.Context, devicePath string, timeout time.Duration) (*SMARTInfo, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return c.GetSMARTInfo(ctx, devicePath)
+}
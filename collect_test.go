@@ -0,0 +1,161 @@
+package smartmontools
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectBackend answers GetSMARTInfo after a short delay and records the
+// peak number of concurrent calls.
+type collectBackend struct {
+	Backend
+	devices []Device
+	active  atomic.Int32
+	peak    atomic.Int32
+}
+
+func (b *collectBackend) Name() string { return "collect" }
+
+func (b *collectBackend) ScanDevices(ctx context.Context) ([]Device, error) {
+	if b.devices == nil {
+		return nil, errors.New("scan failed")
+	}
+	return b.devices, nil
+}
+
+func (b *collectBackend) GetSMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, error) {
+	n := b.active.Add(1)
+	defer b.active.Add(-1)
+	for {
+		p := b.peak.Load()
+		if n <= p || b.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	if devicePath == "/dev/bad" {
+		return nil, ErrDeviceNotFound
+	}
+	select {
+	case <-time.After(10 * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &SMARTInfo{Device: Device{Name: devicePath}}, nil
+}
+
+func TestCollectAll_ScansAndBoundsWorkers(t *testing.T) {
+	backend := &collectBackend{devices: []Device{
+		{Name: "/dev/sda"}, {Name: "/dev/sdb"}, {Name: "/dev/sdc"}, {Name: "/dev/sdd"}, {Name: "/dev/bad"},
+	}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	results, err := client.CollectAll(context.Background(), CollectOptions{Workers: 2})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	assert.LessOrEqual(t, backend.peak.Load(), int32(2))
+	assert.Equal(t, "/dev/sdc", results["/dev/sdc"].Info.Device.Name)
+	assert.NoError(t, results["/dev/sdc"].Err)
+	assert.ErrorIs(t, results["/dev/bad"].Err, ErrDeviceNotFound)
+}
+
+func TestCollectAll_ExplicitDevicesAndTimeout(t *testing.T) {
+	backend := &collectBackend{}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	results, err := client.CollectAll(context.Background(), CollectOptions{
+		Devices: []string{"/dev/sda"},
+		Timeout: time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.ErrorIs(t, results["/dev/sda"].Err, context.DeadlineExceeded)
+}
+
+func TestCollectAll_ScanError(t *testing.T) {
+	client, err := NewClient(WithBackend(&collectBackend{}))
+	require.NoError(t, err)
+
+	_, err = client.CollectAll(context.Background(), CollectOptions{})
+	assert.ErrorContains(t, err, "scan failed")
+}
+
+func TestCollectAll_CancelledContext(t *testing.T) {
+	client, err := NewClient(WithBackend(&collectBackend{}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := client.CollectAll(ctx, CollectOptions{Devices: []string{"/dev/sda", "/dev/sdb", "/dev/sdc"}})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, r := range results {
+		assert.ErrorIs(t, r.Err, context.Canceled)
+	}
+}
+
+func TestScanDevicesWithOptions_FiltersWithoutScanBackend(t *testing.T) {
+	backend := &collectBackend{devices: []Device{
+		{Name: "/dev/sda", Type: "sat", Sysfs: &SysfsInfo{Transport: "usb"}},
+		{Name: "/dev/sdb", Type: "sat,12"},
+		{Name: "/dev/nvme0", Type: "nvme"},
+	}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	devices, err := client.ScanDevicesWithOptions(ctx, ScanOptions{Types: []string{"SAT"}})
+	require.NoError(t, err)
+	assert.Equal(t, backend.devices[:2], devices)
+
+	devices, err = client.ScanDevicesWithOptions(ctx, ScanOptions{Interfaces: []string{"usb", "nvme"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Device{backend.devices[0], backend.devices[2]}, devices)
+
+	devices, err = client.ScanDevicesWithOptions(ctx, ScanOptions{})
+	require.NoError(t, err)
+	assert.Len(t, devices, 3)
+}
+
+func TestDevices_Iterator(t *testing.T) {
+	backend := &collectBackend{devices: []Device{{Name: "/dev/sda"}, {Name: "/dev/sdb"}, {Name: "/dev/sdc"}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	var names []string
+	for d, err := range client.Devices(context.Background()) {
+		require.NoError(t, err)
+		names = append(names, d.Name)
+		if d.Name == "/dev/sdb" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"/dev/sda", "/dev/sdb"}, names)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var errs []error
+	for d, err := range client.Devices(ctx) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		assert.Equal(t, "/dev/sda", d.Name)
+		cancel()
+	}
+	require.Len(t, errs, 1, "cancellation ends the iteration")
+	assert.ErrorIs(t, errs[0], context.Canceled)
+
+	client, err = NewClient(WithBackend(&collectBackend{}))
+	require.NoError(t, err)
+	for d, err := range client.Devices(context.Background()) {
+		assert.ErrorContains(t, err, "scan failed")
+		assert.Zero(t, d)
+	}
+}
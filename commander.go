@@ -1,9 +1,32 @@
 package smartmontools
 
-import smtypes "github.com/dianlight/smartmontools-go/internal/types"
+import (
+	smexec "github.com/dianlight/smartmontools-go/backends/exec"
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
+)
+
+// ContextCommander executes external commands with context cancellation,
+// timeouts, environment overrides and separate stdout/stderr capture.
+type ContextCommander = smtypes.ContextCommander
+
+// CommandRequest describes a single external command invocation.
+type CommandRequest = smtypes.CommandRequest
+
+// CommandResult holds the captured output of a finished command.
+type CommandResult = smtypes.CommandResult
 
 // Commander is the interface for executing OS commands.
+//
+// Deprecated: Implement ContextCommander instead.
 type Commander = smtypes.Commander
 
 // Cmd is the interface for a running command.
+//
+// Deprecated: Used only by the deprecated Commander interface.
 type Cmd = smtypes.Cmd
+
+// DefaultCommander returns the ContextCommander that runs smartctl with
+// os/exec, for decorators that wrap the real commander.
+func DefaultCommander() ContextCommander {
+	return smexec.DefaultCommander()
+}
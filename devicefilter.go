@@ -0,0 +1,105 @@
+package smartmontools
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// deviceAliases lists the /dev/disk/by-id names of a device. Tests replace it.
+var deviceAliases = ByIDAliases
+
+// deviceFilter holds the glob patterns of WithDeviceFilter.
+type deviceFilter struct {
+	include []string
+	exclude []string
+}
+
+// WithDeviceFilter limits the client to the devices matching at least one of
+// the include patterns, when any are given, and none of the exclude patterns.
+// Patterns are filepath.Match globs. Patterns containing a slash are matched
+// against the device path and, for patterns under /dev/disk/, its
+// /dev/disk/by-id aliases, e.g. "/dev/disk/by-id/ata-*"; others against the
+// device name, e.g. "nvme0". The filter applies to ScanDevices,
+// ScanDevicesWithOptions, DiscoverDevices and CollectAll, including its
+// explicit device list, and so to monitors scanning through the client;
+// methods addressing a single device are not filtered. Backends implementing
+// DiscoveryBackend still probe every device, only their results are
+// filtered. NewClient fails on malformed patterns.
+func WithDeviceFilter(include, exclude []string) ClientOption {
+	return func(c *Client) {
+		c.filter = nil
+		if len(include) > 0 || len(exclude) > 0 {
+			c.filter = &deviceFilter{include: slices.Clone(include), exclude: slices.Clone(exclude)}
+		}
+	}
+}
+
+// validate reports the first malformed pattern.
+func (f *deviceFilter) validate() error {
+	for _, pattern := range slices.Concat(f.include, f.exclude) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid device filter pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// allows reports whether devicePath passes the filter.
+func (f *deviceFilter) allows(devicePath string) bool {
+	if f == nil {
+		return true
+	}
+	var aliases []string
+	if slices.ContainsFunc(slices.Concat(f.include, f.exclude), isDiskLinkPattern) {
+		aliases, _ = deviceAliases(devicePath)
+	}
+	if len(f.include) > 0 && !matchesAny(f.include, devicePath, aliases) {
+		return false
+	}
+	return !matchesAny(f.exclude, devicePath, aliases)
+}
+
+func isDiskLinkPattern(pattern string) bool {
+	return strings.HasPrefix(pattern, "/dev/disk/")
+}
+
+// matchesAny reports whether one of patterns matches devicePath or, for
+// /dev/disk patterns, one of its aliases.
+func matchesAny(patterns []string, devicePath string, aliases []string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := filepath.Match(pattern, filepath.Base(devicePath)); ok {
+				return true
+			}
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, devicePath); ok {
+			return true
+		}
+		if !isDiskLinkPattern(pattern) {
+			continue
+		}
+		for _, alias := range aliases {
+			if ok, _ := filepath.Match(pattern, alias); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterDevices returns the devices passing the client's device filter.
+func (c *Client) filterDevices(devices []Device) []Device {
+	if c.filter == nil {
+		return devices
+	}
+	var allowed []Device
+	for _, d := range devices {
+		if c.filter.allows(d.Name) {
+			allowed = append(allowed, d)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,61 @@
+package smartmontools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDeviceFilter(t *testing.T) {
+	old := deviceAliases
+	deviceAliases = func(devicePath string) ([]string, error) {
+		if devicePath == "/dev/sdb" {
+			return []string{"/dev/disk/by-id/ata-WDC_WD40EFRX_WD-1", "/dev/disk/by-id/wwn-0x50014ee2"}, nil
+		}
+		return nil, nil
+	}
+	t.Cleanup(func() { deviceAliases = old })
+
+	backend := &collectBackend{devices: []Device{
+		{Name: "/dev/nvme0", Type: "nvme"}, {Name: "/dev/sda", Type: "sat"}, {Name: "/dev/sdb", Type: "sat"}, {Name: "/dev/sdc", Type: "sat"},
+	}}
+	ctx := context.Background()
+	names := func(devices []Device) []string {
+		var out []string
+		for _, d := range devices {
+			out = append(out, d.Name)
+		}
+		return out
+	}
+
+	client, err := NewClient(WithBackend(backend), WithDeviceFilter([]string{"/dev/disk/by-id/ata-*", "sd[ac]"}, []string{"/dev/sdc"}))
+	require.NoError(t, err)
+	devices, err := client.ScanDevices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/dev/sda", "/dev/sdb"}, names(devices))
+
+	devices, err = client.ScanDevicesWithOptions(ctx, ScanOptions{Types: []string{"sat"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/dev/sda", "/dev/sdb"}, names(devices))
+
+	results, err := client.DiscoverDevices(ctx)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// The boot SSD is excluded from scans and explicit collections alike.
+	client, err = NewClient(WithBackend(backend), WithDeviceFilter(nil, []string{"nvme*"}))
+	require.NoError(t, err)
+	collected, err := client.CollectAll(ctx, CollectOptions{})
+	require.NoError(t, err)
+	assert.Len(t, collected, 3)
+	assert.NotContains(t, collected, "/dev/nvme0")
+	collected, err = client.CollectAll(ctx, CollectOptions{Devices: []string{"/dev/nvme0", "/dev/sda"}})
+	require.NoError(t, err)
+	assert.Len(t, collected, 1)
+	assert.Contains(t, collected, "/dev/sda")
+
+	_, err = NewClient(WithBackend(backend), WithDeviceFilter([]string{"/dev/sd["}, nil))
+	assert.ErrorContains(t, err, `invalid device filter pattern "/dev/sd["`)
+}
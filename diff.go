@@ -0,0 +1,285 @@
+package smartmontools
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// AttributeChange describes how one ATA attribute changed between two snapshots.
+type AttributeChange struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Added is set when the attribute only exists in the newer snapshot,
+	// Removed when it only exists in the older one.
+	Added    bool  `json:"added,omitempty"`
+	Removed  bool  `json:"removed,omitempty"`
+	OldValue int   `json:"old_value"`
+	NewValue int   `json:"new_value"`
+	OldWorst int   `json:"old_worst"`
+	NewWorst int   `json:"new_worst"`
+	OldRaw   int64 `json:"old_raw"`
+	NewRaw   int64 `json:"new_raw"`
+	// Thresh is the failure threshold of the normalized value in the newer
+	// snapshot; zero when the drive sets none.
+	Thresh int `json:"thresh,omitempty"`
+	// Degraded reports a change that signals degradation. For attributes the
+	// registry rates warning or critical, that is a change in the bad
+	// direction: a higher raw counter for higher-is-worse attributes, or a
+	// lower normalized value. For any attribute, it is a lower normalized value
+	// that came within attributeThresholdMargin of Thresh. Growing usage
+	// counters such as Load_Cycle_Count are not degradation on their own.
+	// Temperature attributes are excluded; see SMARTInfoDiff.TemperatureDelta.
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+// RawDelta returns NewRaw - OldRaw.
+func (c AttributeChange) RawDelta() int64 {
+	return c.NewRaw - c.OldRaw
+}
+
+// SMARTInfoDiff is the structured difference between two SMARTInfo snapshots
+// of the same device, as returned by CompareSMARTInfo.
+type SMARTInfoDiff struct {
+	// Attributes lists the ATA attributes whose normalized, worst or raw
+	// values changed, ordered by ID.
+	Attributes []AttributeChange `json:"attributes,omitempty"`
+	// NewErrorLogEntries is the growth of the ATA or NVMe error log.
+	NewErrorLogEntries int64 `json:"new_error_log_entries,omitempty"`
+	// TemperatureDelta is the change of the current temperature in °C; nil
+	// when either snapshot lacks a temperature.
+	TemperatureDelta *int `json:"temperature_delta,omitempty"`
+	// PowerCycleDelta is the change of the power cycle count; nil when either
+	// snapshot lacks it.
+	PowerCycleDelta *int64 `json:"power_cycle_delta,omitempty"`
+	// PowerOnHoursDelta is the change of the power-on hours; nil when either
+	// snapshot lacks it.
+	PowerOnHoursDelta *int64 `json:"power_on_hours_delta,omitempty"`
+	// HealthChanged is set when the SMART overall-health result flipped. Both
+	// snapshots must report a verdict; see SMARTInfo.HealthVerdict.
+	HealthChanged bool `json:"health_changed,omitempty"`
+	// HealthPassed is the overall-health result of the newer snapshot, false
+	// when it reports none.
+	HealthPassed bool `json:"health_passed"`
+}
+
+// Degraded reports whether the diff contains a sign of degradation: a
+// degraded attribute, new error log entries or a health check that stopped
+// passing.
+func (d *SMARTInfoDiff) Degraded() bool {
+	if d.NewErrorLogEntries > 0 || (d.HealthChanged && !d.HealthPassed) {
+		return true
+	}
+	return slices.ContainsFunc(d.Attributes, func(c AttributeChange) bool { return c.Degraded })
+}
+
+// CompareSMARTInfo returns the differences between an older and a newer
+// SMARTInfo snapshot of the same device. Either argument may be nil, in which
+// case it is treated as an empty snapshot.
+func CompareSMARTInfo(old, new *SMARTInfo) *SMARTInfoDiff {
+	if old == nil {
+		old = &SMARTInfo{}
+	}
+	if new == nil {
+		new = &SMARTInfo{}
+	}
+	diff := &SMARTInfoDiff{}
+
+	oldAttrs := attributesByID(old.AtaSmartData)
+	newAttrs := attributesByID(new.AtaSmartData)
+	for id, n := range newAttrs {
+		o, ok := oldAttrs[id]
+		change := AttributeChange{
+			ID: id, Name: n.Name,
+			NewValue: n.Value, NewWorst: n.Worst, NewRaw: n.Raw.Count(), Thresh: n.Thresh,
+		}
+		if !ok {
+			change.Added = true
+			diff.Attributes = append(diff.Attributes, change)
+			continue
+		}
+		change.OldValue, change.OldWorst, change.OldRaw = o.Value, o.Worst, o.Raw.Count()
+		if change.OldValue == change.NewValue && change.OldWorst == change.NewWorst && change.OldRaw == change.NewRaw {
+			continue
+		}
+		change.Degraded = attributeDegraded(change)
+		diff.Attributes = append(diff.Attributes, change)
+	}
+	for id, o := range oldAttrs {
+		if _, ok := newAttrs[id]; !ok {
+			diff.Attributes = append(diff.Attributes, AttributeChange{
+				ID: id, Name: o.Name, Removed: true,
+				OldValue: o.Value, OldWorst: o.Worst, OldRaw: o.Raw.Count(),
+			})
+		}
+	}
+	slices.SortFunc(diff.Attributes, func(a, b AttributeChange) int { return cmp.Compare(a.ID, b.ID) })
+
+	if grown := errorLogCount(new) - errorLogCount(old); grown > 0 {
+		diff.NewErrorLogEntries = grown
+	}
+	if o, ok := currentTemperature(old); ok {
+		if n, ok := currentTemperature(new); ok {
+			delta := n - o
+			diff.TemperatureDelta = &delta
+		}
+	}
+	if o, ok := powerCycles(old); ok {
+		if n, ok := powerCycles(new); ok {
+			delta := n - o
+			diff.PowerCycleDelta = &delta
+		}
+	}
+	if o, ok := powerOnHours(old); ok {
+		if n, ok := powerOnHours(new); ok {
+			delta := n - o
+			diff.PowerOnHoursDelta = &delta
+		}
+	}
+	oldPassed, oldOK := old.HealthVerdict()
+	newPassed, newOK := new.HealthVerdict()
+	diff.HealthChanged = oldOK && newOK && oldPassed != newPassed
+	diff.HealthPassed = newPassed
+	return diff
+}
+
+// AttributeDelta is the change of one ATA attribute reported by both
+// snapshots passed to DeltaAttributes. Raw values are counters as interpreted
+// by Raw.Count.
+type AttributeDelta struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	ValueDelta int    `json:"value_delta"`
+	WorstDelta int    `json:"worst_delta"`
+	RawDelta   int64  `json:"raw_delta"`
+}
+
+// AttributeDeltas is the result of DeltaAttributes.
+type AttributeDeltas struct {
+	// Attributes holds every ATA attribute present in both snapshots, changed
+	// or not, ordered by ID.
+	Attributes []AttributeDelta `json:"attributes"`
+	// PowerOnHours is the power-on time elapsed between the snapshots; nil
+	// when either snapshot lacks it.
+	PowerOnHours *int64 `json:"power_on_hours,omitempty"`
+}
+
+// Get returns the delta of attribute id, or false when it is not in both
+// snapshots.
+func (d *AttributeDeltas) Get(id int) (AttributeDelta, bool) {
+	i, found := slices.BinarySearchFunc(d.Attributes, id, func(a AttributeDelta, id int) int { return cmp.Compare(a.ID, id) })
+	if !found {
+		return AttributeDelta{}, false
+	}
+	return d.Attributes[i], true
+}
+
+// RawPerPowerOnHour returns the raw growth of attribute id per power-on hour
+// elapsed between the snapshots. It returns false when the attribute is not
+// in both snapshots or no power-on time elapsed.
+func (d *AttributeDeltas) RawPerPowerOnHour(id int) (float64, bool) {
+	delta, ok := d.Get(id)
+	if !ok || d.PowerOnHours == nil || *d.PowerOnHours <= 0 {
+		return 0, false
+	}
+	return float64(delta.RawDelta) / float64(*d.PowerOnHours), true
+}
+
+// DeltaAttributes returns the raw, normalized and worst value deltas (new
+// minus old) of every ATA attribute reported by both snapshots, and the
+// power-on hours elapsed between them. Unlike CompareSMARTInfo it keeps
+// unchanged attributes and makes no judgement, as a building block for trend
+// logic. Either argument may be nil.
+func DeltaAttributes(old, new *SMARTInfo) *AttributeDeltas {
+	if old == nil {
+		old = &SMARTInfo{}
+	}
+	if new == nil {
+		new = &SMARTInfo{}
+	}
+	deltas := &AttributeDeltas{Attributes: []AttributeDelta{}}
+	oldAttrs := attributesByID(old.AtaSmartData)
+	for id, n := range attributesByID(new.AtaSmartData) {
+		o, ok := oldAttrs[id]
+		if !ok {
+			continue
+		}
+		deltas.Attributes = append(deltas.Attributes, AttributeDelta{
+			ID:         id,
+			Name:       n.Name,
+			ValueDelta: n.Value - o.Value,
+			WorstDelta: n.Worst - o.Worst,
+			RawDelta:   n.Raw.Count() - o.Raw.Count(),
+		})
+	}
+	slices.SortFunc(deltas.Attributes, func(a, b AttributeDelta) int { return cmp.Compare(a.ID, b.ID) })
+	if o, ok := powerOnHours(old); ok {
+		if n, ok := powerOnHours(new); ok {
+			elapsed := n - o
+			deltas.PowerOnHours = &elapsed
+		}
+	}
+	return deltas
+}
+
+func attributesByID(data *AtaSmartData) map[int]SmartAttribute {
+	if data == nil {
+		return nil
+	}
+	attrs := make(map[int]SmartAttribute, len(data.Table))
+	for _, a := range data.Table {
+		attrs[a.ID] = a
+	}
+	return attrs
+}
+
+// attributeThresholdMargin is how close to its threshold a dropping
+// normalized value must come for AttributeChange.Degraded.
+const attributeThresholdMargin = 10
+
+func attributeDegraded(c AttributeChange) bool {
+	d, known := DescribeAttribute(c.ID)
+	if known && d.Unit == UnitCelsius {
+		return false
+	}
+	if c.NewValue < c.OldValue && c.Thresh > 0 && c.NewValue <= c.Thresh+attributeThresholdMargin {
+		return true
+	}
+	if !known || d.Polarity == PolarityNeutral || d.Criticality == CriticalityInformational {
+		return false
+	}
+	if d.Polarity == PolarityHigherIsWorse && c.NewRaw > c.OldRaw {
+		return true
+	}
+	return c.NewValue < c.OldValue
+}
+
+// currentTemperature returns the current temperature from the temperature
+// section or, for NVMe devices, the health log.
+func currentTemperature(info *SMARTInfo) (int, bool) {
+	switch {
+	case info.Temperature != nil:
+		return info.Temperature.Current, true
+	case info.NvmeSmartHealth != nil && info.NvmeSmartHealth.Temperature != 0:
+		return info.NvmeSmartHealth.Temperature, true
+	}
+	return 0, false
+}
+
+func powerCycles(info *SMARTInfo) (int64, bool) {
+	switch {
+	case info.PowerCycleCount > 0:
+		return int64(info.PowerCycleCount), true
+	case info.NvmeSmartHealth != nil:
+		return info.NvmeSmartHealth.PowerCycles, true
+	}
+	if attr := info.AtaSmartData.GetAttributeByID(SmartAttrPowerCycleCount); attr != nil {
+		return attr.Raw.Count(), true
+	}
+	return 0, false
+}
+
+func powerOnHours(info *SMARTInfo) (int64, bool) {
+	d, ok := info.PowerOnDuration()
+	return int64(d / time.Hour), ok
+}
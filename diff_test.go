@@ -0,0 +1,159 @@
+package smartmontools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareSMARTInfo(t *testing.T) {
+	old := &SMARTInfo{
+		SmartStatus:     &SmartStatus{Passed: true},
+		Temperature:     &Temperature{Current: 35},
+		PowerOnTime:     &PowerOnTime{Hours: 1000},
+		PowerCycleCount: 50,
+		AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, Raw: Raw{Value: 0, String: "0"}},
+			{ID: 9, Name: "Power_On_Hours", Value: 90, Worst: 90, Raw: Raw{Value: 1000, String: "1000"}},
+			{ID: 194, Name: "Temperature_Celsius", Value: 65, Worst: 50, Raw: Raw{Value: 35, String: "35"}},
+			{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, Worst: 200, Raw: Raw{Value: 0, String: "0"}},
+			{ID: 240, Name: "Head_Flying_Hours", Value: 100, Worst: 100, Raw: Raw{Value: 10, String: "10"}},
+		}},
+		AtaSmartErrorLog: &AtaSmartErrorLog{Summary: &AtaErrorLogSummary{Count: 1}},
+	}
+	new := &SMARTInfo{
+		SmartStatus:     &SmartStatus{Passed: false},
+		Temperature:     &Temperature{Current: 41},
+		PowerOnTime:     &PowerOnTime{Hours: 1010},
+		PowerCycleCount: 52,
+		AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 99, Worst: 99, Raw: Raw{Value: 8, String: "8"}},
+			{ID: 9, Name: "Power_On_Hours", Value: 90, Worst: 90, Raw: Raw{Value: 1010, String: "1010"}},
+			{ID: 194, Name: "Temperature_Celsius", Value: 59, Worst: 50, Raw: Raw{Value: 41, String: "41"}},
+			{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, Worst: 200, Raw: Raw{Value: 0, String: "0"}},
+			{ID: 197, Name: "Current_Pending_Sector", Value: 100, Worst: 100, Raw: Raw{Value: 0, String: "0"}},
+		}},
+		AtaSmartErrorLog: &AtaSmartErrorLog{Summary: &AtaErrorLogSummary{Count: 3}},
+	}
+
+	diff := CompareSMARTInfo(old, new)
+	var ids []int
+	for _, c := range diff.Attributes {
+		ids = append(ids, c.ID)
+	}
+	assert.Equal(t, []int{5, 9, 194, 197, 240}, ids, "unchanged attribute 199 is omitted")
+
+	realloc := diff.Attributes[0]
+	assert.True(t, realloc.Degraded)
+	assert.Equal(t, int64(8), realloc.RawDelta())
+	assert.Equal(t, 100, realloc.OldValue)
+	assert.Equal(t, 99, realloc.NewValue)
+
+	assert.False(t, diff.Attributes[1].Degraded, "power-on hours are neutral")
+	assert.False(t, diff.Attributes[2].Degraded, "temperature is reported via TemperatureDelta")
+	assert.True(t, diff.Attributes[3].Added)
+	assert.True(t, diff.Attributes[4].Removed)
+	assert.Equal(t, int64(10), diff.Attributes[4].OldRaw)
+
+	assert.Equal(t, int64(2), diff.NewErrorLogEntries)
+	require.NotNil(t, diff.TemperatureDelta)
+	assert.Equal(t, 6, *diff.TemperatureDelta)
+	require.NotNil(t, diff.PowerCycleDelta)
+	assert.Equal(t, int64(2), *diff.PowerCycleDelta)
+	require.NotNil(t, diff.PowerOnHoursDelta)
+	assert.Equal(t, int64(10), *diff.PowerOnHoursDelta)
+	assert.True(t, diff.HealthChanged)
+	assert.False(t, diff.HealthPassed)
+	assert.True(t, diff.Degraded())
+}
+
+func TestCompareSMARTInfo_DegradedOnlyForWarningAttributes(t *testing.T) {
+	old := &SMARTInfo{AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+		{ID: 1, Name: "Raw_Read_Error_Rate", Value: 100, Worst: 100, Thresh: 6},
+		{ID: 3, Name: "Spin_Up_Time", Value: 95, Worst: 95, Thresh: 0},
+		{ID: 7, Name: "Seek_Error_Rate", Value: 80, Worst: 80, Thresh: 45},
+		{ID: 193, Name: "Load_Cycle_Count", Value: 90, Worst: 90, Raw: Raw{Value: 12000}},
+		{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, Worst: 200, Raw: Raw{Value: 0}},
+	}}}
+	new := &SMARTInfo{AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+		{ID: 1, Name: "Raw_Read_Error_Rate", Value: 98, Worst: 98, Thresh: 6},
+		{ID: 3, Name: "Spin_Up_Time", Value: 94, Worst: 94, Thresh: 0},
+		{ID: 7, Name: "Seek_Error_Rate", Value: 54, Worst: 54, Thresh: 45},
+		{ID: 193, Name: "Load_Cycle_Count", Value: 89, Worst: 89, Raw: Raw{Value: 12050}},
+		{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, Worst: 200, Raw: Raw{Value: 2}},
+	}}}
+
+	degraded := map[int]bool{}
+	for _, c := range CompareSMARTInfo(old, new).Attributes {
+		degraded[c.ID] = c.Degraded
+	}
+	assert.Equal(t, map[int]bool{1: false, 3: false, 7: true, 193: false, 199: true}, degraded,
+		"usage counters and normalized dips far from the threshold are not degradation")
+}
+
+func TestCompareSMARTInfo_Nvme(t *testing.T) {
+	old := &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{Temperature: 40, PowerCycles: 10, PowerOnHours: 500, NumErrLogEntries: 4}}
+	new := &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{Temperature: 38, PowerCycles: 11, PowerOnHours: 520, NumErrLogEntries: 4}}
+
+	diff := CompareSMARTInfo(old, new)
+	assert.Empty(t, diff.Attributes)
+	assert.Zero(t, diff.NewErrorLogEntries)
+	require.NotNil(t, diff.TemperatureDelta)
+	assert.Equal(t, -2, *diff.TemperatureDelta)
+	assert.Equal(t, int64(1), *diff.PowerCycleDelta)
+	assert.Equal(t, int64(20), *diff.PowerOnHoursDelta)
+	assert.False(t, diff.Degraded())
+}
+
+func TestCompareSMARTInfo_Nil(t *testing.T) {
+	diff := CompareSMARTInfo(nil, &SMARTInfo{Temperature: &Temperature{Current: 30}})
+	assert.Nil(t, diff.TemperatureDelta)
+	assert.Nil(t, diff.PowerCycleDelta)
+	assert.False(t, diff.HealthChanged)
+	assert.False(t, diff.Degraded())
+}
+
+func TestCompareSMARTInfo_HealthNeedsVerdicts(t *testing.T) {
+	passed := &SMARTInfo{SmartStatus: &SmartStatus{Passed: true}}
+	standby := &SMARTInfo{InStandby: true, SmartStatus: &SmartStatus{Synthesized: true}}
+	diff := CompareSMARTInfo(passed, standby)
+	assert.False(t, diff.HealthChanged, "a synthesized status is no verdict")
+	assert.False(t, diff.Degraded())
+}
+
+func TestDeltaAttributes(t *testing.T) {
+	old := &SMARTInfo{
+		PowerOnTime: &PowerOnTime{Hours: 1000},
+		AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+			{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, Worst: 200, Raw: Raw{Value: 3, String: "3"}},
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, Raw: Raw{Value: 0, String: "0"}},
+			{ID: 240, Name: "Head_Flying_Hours", Value: 100, Worst: 100, Raw: Raw{Value: 10, String: "10"}},
+		}},
+	}
+	new := &SMARTInfo{
+		PowerOnTime: &PowerOnTime{Hours: 1200},
+		AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 98, Worst: 98, Raw: Raw{Value: 40, String: "40"}},
+			{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, Worst: 200, Raw: Raw{Value: 3, String: "3"}},
+			{ID: 197, Name: "Current_Pending_Sector", Value: 100, Worst: 100, Raw: Raw{Value: 0, String: "0"}},
+		}},
+	}
+
+	deltas := DeltaAttributes(old, new)
+	require.Len(t, deltas.Attributes, 2, "only attributes in both snapshots")
+	assert.Equal(t, AttributeDelta{ID: 5, Name: "Reallocated_Sector_Ct", ValueDelta: -2, WorstDelta: -2, RawDelta: 40}, deltas.Attributes[0])
+	assert.Equal(t, AttributeDelta{ID: 199, Name: "UDMA_CRC_Error_Count"}, deltas.Attributes[1])
+	require.NotNil(t, deltas.PowerOnHours)
+	assert.Equal(t, int64(200), *deltas.PowerOnHours)
+
+	rate, ok := deltas.RawPerPowerOnHour(5)
+	require.True(t, ok)
+	assert.InDelta(t, 0.2, rate, 1e-9)
+	_, ok = deltas.RawPerPowerOnHour(197)
+	assert.False(t, ok)
+
+	empty := DeltaAttributes(nil, nil)
+	assert.Empty(t, empty.Attributes)
+	assert.Nil(t, empty.PowerOnHours)
+}
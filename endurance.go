@@ -0,0 +1,133 @@
+package smartmontools
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// nvmeDataUnit is the size of an NVMe data unit: 1000 512-byte blocks.
+const nvmeDataUnit = 512 * 1000
+
+// writeUnitPattern matches the unit suffix vendors put in the names of host
+// write attributes, e.g. "Host_Writes_32MiB" or "Lifetime_Writes_GiB".
+var writeUnitPattern = regexp.MustCompile(`_(\d*)([KMGT])(i?)B$`)
+
+// BytesWritten returns the total number of bytes the host has written to the
+// drive. NVMe drives report it in data_units_written. ATA drives report it in
+// attribute 241 or 246, in a unit that varies by vendor; it is derived from
+// the attribute name smartctl assigns from its drive database:
+//
+//   - names mentioning LBAs or sectors ("Total_LBAs_Written",
+//     "Total_Host_Sector_Write") count logical blocks of LogicalBlockSize
+//     bytes, 512 when unknown;
+//   - names ending in a size ("Host_Writes_GiB", "Host_Writes_32MiB",
+//     "Lifetime_Writes_GB") count multiples of that size.
+//
+// It returns false when the drive reports no host write counter, or only one
+// whose name does not reveal its unit ("Unknown_Attribute"); naming the
+// attribute with a "-v 241,raw48,Host_Writes_GiB" preset fixes the latter.
+func BytesWritten(info *SMARTInfo) (int64, bool) {
+	if info == nil {
+		return 0, false
+	}
+	if info.NvmeSmartHealth != nil && info.NvmeSmartHealth.DataUnitsWritten > 0 {
+		return info.NvmeSmartHealth.DataUnitsWritten * nvmeDataUnit, true
+	}
+	for _, id := range []int{SmartAttrHostWrites, SmartAttrHostSectorWrites} {
+		attr := info.AtaSmartData.GetAttributeByID(id)
+		if attr == nil {
+			continue
+		}
+		if unit, ok := writeUnitBytes(attr.Name, info.LogicalBlockSize); ok {
+			return attr.Raw.Value * unit, true
+		}
+	}
+	return 0, false
+}
+
+// writeUnitBytes returns the size of one unit of a host write attribute
+// named name, or false when the name does not reveal it.
+func writeUnitBytes(name string, logicalBlockSize int) (int64, bool) {
+	lower := strings.ToLower(name)
+	if strings.Contains(lower, "lba") || strings.Contains(lower, "sector") {
+		if logicalBlockSize <= 0 {
+			logicalBlockSize = 512
+		}
+		return int64(logicalBlockSize), true
+	}
+	m := writeUnitPattern.FindStringSubmatch(name)
+	if m == nil {
+		return 0, false
+	}
+	multiple := int64(1)
+	if m[1] != "" {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || n <= 0 {
+			return 0, false
+		}
+		multiple = n
+	}
+	base := int64(1000)
+	if m[3] != "" {
+		base = 1024
+	}
+	unit := int64(1)
+	for range strings.Index("KMGT", m[2]) + 1 {
+		unit *= base
+	}
+	return multiple * unit, true
+}
+
+// EnduranceUsed returns the share of the drive's rated endurance consumed so
+// far, in percent, given its rated total bytes written in terabytes (10^12
+// bytes) as found on the datasheet. The result exceeds 100 once the rating is
+// passed. It returns false when ratedTBW is not positive or BytesWritten
+// cannot determine the bytes written.
+func EnduranceUsed(info *SMARTInfo, ratedTBW float64) (float64, bool) {
+	if ratedTBW <= 0 {
+		return 0, false
+	}
+	written, ok := BytesWritten(info)
+	if !ok {
+		return 0, false
+	}
+	return float64(written) / (ratedTBW * 1e12) * 100, true
+}
+
+// LifeRemaining is a drive's remaining rated life, normalized across vendor
+// conventions by GetSSDLifeRemaining.
+type LifeRemaining struct {
+	// Percent is the remaining life, from 100 (new) to 0 (rated life used up).
+	Percent int `json:"percent"`
+	// Source names where Percent comes from: "nvme_percentage_used" or
+	// "attribute_N".
+	Source string `json:"source"`
+	// AttributeID and AttributeName identify the ATA attribute used, if any.
+	AttributeID   int    `json:"attribute_id,omitempty"`
+	AttributeName string `json:"attribute_name,omitempty"`
+}
+
+// GetSSDLifeRemaining returns the remaining life of an SSD as a single
+// percentage. NVMe drives derive it from percentage_used; ATA drives from the
+// normalized value of AtaSmartData.LifeRemainingAttribute, the first of
+// attributes 231, 233, 169, 177 and 202 that the drive reports under a life
+// or wear name. It returns false for drives with none of them, such as HDDs.
+func GetSSDLifeRemaining(info *SMARTInfo) (LifeRemaining, bool) {
+	if info == nil {
+		return LifeRemaining{}, false
+	}
+	if info.NvmeSmartHealth != nil {
+		return LifeRemaining{Percent: max(0, min(100, 100-info.NvmeSmartHealth.PercentageUsed)), Source: "nvme_percentage_used"}, true
+	}
+	if attr := info.AtaSmartData.LifeRemainingAttribute(); attr != nil {
+		return LifeRemaining{
+			Percent:       max(0, min(100, attr.Value)),
+			Source:        fmt.Sprintf("attribute_%d", attr.ID),
+			AttributeID:   attr.ID,
+			AttributeName: attr.Name,
+		}, true
+	}
+	return LifeRemaining{}, false
+}
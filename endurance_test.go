@@ -0,0 +1,84 @@
+package smartmontools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytesWritten(t *testing.T) {
+	ata := func(id int, name string, raw int64) *SMARTInfo {
+		return &SMARTInfo{AtaSmartData: &AtaSmartData{Table: []SmartAttribute{{ID: id, Name: name, Raw: Raw{Value: raw}}}}}
+	}
+	tests := []struct {
+		name string
+		info *SMARTInfo
+		want int64
+		ok   bool
+	}{
+		{"nvme data units", &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{DataUnitsWritten: 2_000_000}}, 1_024_000_000_000, true},
+		{"lbas", ata(SmartAttrHostWrites, "Total_LBAs_Written", 1000), 512_000, true},
+		{"4Kn lbas", &SMARTInfo{LogicalBlockSize: 4096, AtaSmartData: ata(SmartAttrHostWrites, "Total_LBAs_Written", 1000).AtaSmartData}, 4_096_000, true},
+		{"host sectors", ata(SmartAttrHostSectorWrites, "Total_Host_Sector_Write", 2), 1024, true},
+		{"gib", ata(SmartAttrHostWrites, "Lifetime_Writes_GiB", 3), 3 << 30, true},
+		{"32mib", ata(SmartAttrHostWrites, "Host_Writes_32MiB", 10), 10 * 32 << 20, true},
+		{"decimal gb", ata(SmartAttrHostWrites, "Host_Writes_GB", 5), 5_000_000_000, true},
+		{"unknown unit", ata(SmartAttrHostSectorWrites, "Unknown_Attribute", 5), 0, false},
+		{"no counter", &SMARTInfo{}, 0, false},
+		{"nil", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := BytesWritten(tt.info)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEnduranceUsed(t *testing.T) {
+	// 150 TB written on a 600 TBW drive.
+	info := &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{DataUnitsWritten: 150e12 / nvmeDataUnit}}
+	used, ok := EnduranceUsed(info, 600)
+	require.True(t, ok)
+	assert.InDelta(t, 25.0, used, 1e-9)
+
+	_, ok = EnduranceUsed(info, 0)
+	assert.False(t, ok)
+	_, ok = EnduranceUsed(&SMARTInfo{}, 600)
+	assert.False(t, ok)
+}
+
+func TestGetSSDLifeRemaining(t *testing.T) {
+	ata := func(attrs ...SmartAttribute) *SMARTInfo {
+		return &SMARTInfo{AtaSmartData: &AtaSmartData{Table: attrs}}
+	}
+	tests := []struct {
+		name   string
+		info   *SMARTInfo
+		want   int
+		source string
+		ok     bool
+	}{
+		{"nvme", &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{PercentageUsed: 7}}, 93, "nvme_percentage_used", true},
+		{"nvme past rating", &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{PercentageUsed: 130}}, 0, "nvme_percentage_used", true},
+		{"sandforce", ata(SmartAttribute{ID: 231, Name: "SSD_Life_Left", Value: 88}), 88, "attribute_231", true},
+		{"intel", ata(SmartAttribute{ID: 233, Name: "Media_Wearout_Indicator", Value: 97}), 97, "attribute_233", true},
+		{"sandforce internal 233 skipped", ata(SmartAttribute{ID: 233, Name: "SandForce_Internal", Value: 0}, SmartAttribute{ID: 177, Name: "Wear_Leveling_Count", Value: 91}), 91, "attribute_177", true},
+		{"adata", ata(SmartAttribute{ID: 169, Name: "Remaining_Lifetime_Perc", Value: 80}), 80, "attribute_169", true},
+		{"crucial", ata(SmartAttribute{ID: 202, Name: "Percent_Lifetime_Remain", Value: 95}), 95, "attribute_202", true},
+		{"preference order", ata(SmartAttribute{ID: 177, Name: "Wear_Leveling_Count", Value: 50}, SmartAttribute{ID: 231, Name: "SSD_Life_Left", Value: 60}), 60, "attribute_231", true},
+		{"hdd temperature 231", ata(SmartAttribute{ID: 231, Name: "Temperature_Celsius", Value: 40}), 0, "", false},
+		{"hdd", ata(SmartAttribute{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100}), 0, "", false},
+		{"nil", nil, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := GetSSDLifeRemaining(tt.info)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got.Percent)
+			assert.Equal(t, tt.source, got.Source)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package smartmontools
+
+import (
+	"context"
+	"errors"
+	osexec "os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// routeCommander is a ContextCommander answering by argument suffix (the device path).
+type routeCommander map[string]statusCommander
+
+func (r routeCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	key := req.Args[len(req.Args)-1]
+	if s, ok := r[key]; ok {
+		return s.result, s.err
+	}
+	return &CommandResult{ExitCode: -1}, errors.New("not configured")
+}
+
+func openFailure(message string) statusCommander {
+	return statusCommander{
+		result: &CommandResult{
+			Stdout:   []byte(`{"smartctl":{"messages":[{"string":"Smartctl open device: /dev/x failed: ` + message + `","severity":"error"}],"exit_status":2}}`),
+			ExitCode: 2,
+		},
+		err: &osexec.ExitError{},
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithContextCommander(routeCommander{
+			"/dev/denied":  openFailure("Permission denied"),
+			"/dev/missing": openFailure("No such file or directory"),
+			"/dev/asleep": {
+				result: &CommandResult{Stdout: []byte(`{"smartctl":{"exit_status":2}}`), ExitCode: 2},
+				err:    &osexec.ExitError{},
+			},
+		}),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.GetSMARTInfo(ctx, "/dev/denied")
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	_, err = client.GetDeviceInfo(ctx, "/dev/denied")
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	assert.NotErrorIs(t, err, ErrDeviceInStandby)
+
+	_, err = client.GetSMARTInfo(ctx, "/dev/missing")
+	assert.ErrorIs(t, err, ErrDeviceNotFound)
+	var smartErr *SmartctlError
+	require.ErrorAs(t, err, &smartErr)
+	assert.True(t, smartErr.Status.DeviceOpenFailed())
+
+	_, err = client.GetDeviceInfo(ctx, "/dev/asleep")
+	assert.ErrorIs(t, err, ErrDeviceInStandby)
+	_, err = client.GetAvailableSelfTests(ctx, "/dev/asleep")
+	assert.ErrorIs(t, err, ErrDeviceInStandby)
+}
+
+func TestSentinelErrors_SelfTestNotSupported(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithContextCommander(routeCommander{
+			"/dev/sda": {result: &CommandResult{Stdout: []byte(`{"device":{"name":"/dev/sda","type":"ata"}}`)}},
+		}),
+	)
+	require.NoError(t, err)
+
+	err = client.RunSelfTestWithProgress(context.Background(), "/dev/sda", "short", nil)
+	assert.ErrorIs(t, err, ErrTestNotSupported)
+}
+
+func TestSentinelErrors_VirtualDevice(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithContextCommander(routeCommander{
+			"/dev/sdc": {result: &CommandResult{Stdout: []byte(`{"device":{"name":"/dev/sdc","type":"scsi"},"vendor":"SYNOLOGY","product":"iSCSI Storage","smart_support":{"available":false}}`)}},
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetSMARTInfo(context.Background(), "/dev/sdc")
+	assert.ErrorIs(t, err, ErrVirtualDevice)
+	assert.ErrorIs(t, err, ErrSmartNotSupported)
+	var vErr *VirtualDeviceError
+	require.ErrorAs(t, err, &vErr)
+	assert.Equal(t, "SYNOLOGY", vErr.Vendor)
+}
+
+func TestCheckJSONFormatVersion(t *testing.T) {
+	assert.NoError(t, CheckJSONFormatVersion(nil))
+	assert.NoError(t, CheckJSONFormatVersion([]int{1, 0}))
+	assert.NoError(t, CheckJSONFormatVersion([]int{1, 3}))
+
+	err := CheckJSONFormatVersion([]int{2, 1})
+	require.ErrorIs(t, err, ErrUnsupportedJSONFormat)
+	assert.EqualError(t, err, "unsupported smartctl JSON format version 2.1 (supported: 1.x)")
+}
@@ -187,10 +187,10 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
-	progressCallback := func(progress int, status string) {
-		fmt.Printf("\rProgress: %d%% - %s", progress, status)
+	progressCallback := func(progress int, status string, source smartmontools.ProgressSource, outcome smartmontools.SelfTestOutcome) {
+		fmt.Printf("\rProgress: %d%% (%s) - %s", progress, source, status)
 		if progress == 100 {
-			fmt.Println() // New line after completion
+			fmt.Printf("\nOutcome: %s\n", outcome)
 		}
 	}
 
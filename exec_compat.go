@@ -2,6 +2,7 @@ package smartmontools
 
 import (
 	"log/slog"
+	"time"
 
 	smexec "github.com/dianlight/smartmontools-go/backends/exec"
 	"github.com/dianlight/tlog"
@@ -24,10 +25,41 @@ func WithExecSmartctlPath(path string) ExecBackendOption {
 }
 
 // WithExecCommander sets a custom commander for ExecBackend.
+//
+// Deprecated: Use WithExecContextCommander.
 func WithExecCommander(commander Commander) ExecBackendOption {
 	return smexec.WithCommander(commander)
 }
 
+// WithExecContextCommander sets a custom command executor for ExecBackend.
+func WithExecContextCommander(commander ContextCommander) ExecBackendOption {
+	return smexec.WithContextCommander(commander)
+}
+
+// DefaultCommandTimeout is the ExecBackend command timeout unless
+// WithExecCommandTimeout changes it.
+const DefaultCommandTimeout = smexec.DefaultCommandTimeout
+
+// WithExecCommandTimeout kills ExecBackend commands running longer than timeout.
+func WithExecCommandTimeout(timeout time.Duration) ExecBackendOption {
+	return smexec.WithCommandTimeout(timeout)
+}
+
+// WithExecCommandEnv adds "KEY=value" entries to the environment of ExecBackend commands.
+func WithExecCommandEnv(env ...string) ExecBackendOption {
+	return smexec.WithCommandEnv(env...)
+}
+
+// DefaultLocale is the locale ExecBackend commands run with unless
+// WithExecLocale changes it.
+const DefaultLocale = smexec.DefaultLocale
+
+// WithExecLocale sets LC_ALL and LANG of ExecBackend commands; empty inherits
+// the process locale.
+func WithExecLocale(locale string) ExecBackendOption {
+	return smexec.WithLocale(locale)
+}
+
 // WithExecLogHandler sets a custom logger adapter for ExecBackend.
 func WithExecLogHandler(logger LogAdapter) ExecBackendOption {
 	return smexec.WithLogHandler(logger)
@@ -43,6 +75,100 @@ func WithExecTLogHandler(logger *tlog.Logger) ExecBackendOption {
 	return smexec.WithTLogHandler(logger)
 }
 
+// WithExecAttributePresets sets per-device '-v' attribute presets for ExecBackend.
+func WithExecAttributePresets(devicePath string, presets ...string) ExecBackendOption {
+	return smexec.WithAttributePresets(devicePath, presets...)
+}
+
+// WithExecDrivedbPresets toggles automatic embedded drivedb presets for ExecBackend.
+func WithExecDrivedbPresets(enabled bool) ExecBackendOption {
+	return smexec.WithDrivedbPresets(enabled)
+}
+
+// WithExecTextOutput makes ExecBackend parse smartctl's text output instead of JSON.
+func WithExecTextOutput(enabled bool) ExecBackendOption {
+	return smexec.WithTextOutput(enabled)
+}
+
+// WithExecStrictJSONFormat makes ExecBackend reject smartctl output with an
+// unsupported json_format_version.
+func WithExecStrictJSONFormat(enabled bool) ExecBackendOption {
+	return smexec.WithStrictJSONFormat(enabled)
+}
+
+// WithExecDeviceRateLimit limits ExecBackend smartctl invocations per device
+// to one every interval, with bursts of up to burst calls.
+func WithExecDeviceRateLimit(interval time.Duration, burst int) ExecBackendOption {
+	return smexec.WithDeviceRateLimit(interval, burst)
+}
+
+// WithExecSharedQueries lets one "smartctl -x -j" invocation per device
+// serve ExecBackend info, health and self-test queries for window.
+func WithExecSharedQueries(window time.Duration) ExecBackendOption {
+	return smexec.WithSharedQueries(window)
+}
+
+// WithExecNoCheckPolicy sets when ExecBackend queries skip drives in a
+// low-power mode instead of waking them.
+func WithExecNoCheckPolicy(policy NoCheckPolicy) ExecBackendOption {
+	return smexec.WithNoCheckPolicy(policy)
+}
+
+// WithExecTolerance passes "-T level" to ExecBackend smartctl invocations.
+func WithExecTolerance(t Tolerance) ExecBackendOption {
+	return smexec.WithTolerance(t)
+}
+
+// WithExecDeviceOptions sets per-device overrides of the ExecBackend device
+// type, extra smartctl arguments, tolerance and --nocheck policy.
+func WithExecDeviceOptions(options map[string]DeviceOptions) ExecBackendOption {
+	return smexec.WithDeviceOptions(options)
+}
+
+// WithExecCommandHook calls hook after every ExecBackend smartctl invocation.
+func WithExecCommandHook(hook CommandHook) ExecBackendOption {
+	return smexec.WithCommandHook(hook)
+}
+
+// WithExecOpenRetry retries ExecBackend smartctl invocations failing with
+// ErrDeviceBusy, with exponential backoff starting at delay.
+func WithExecOpenRetry(retries int, delay time.Duration) ExecBackendOption {
+	return smexec.WithOpenRetry(retries, delay)
+}
+
+// WithExecSudo runs smartctl through "sudo -n" for ExecBackend when the process is not root.
+func WithExecSudo() ExecBackendOption {
+	return smexec.WithSudo()
+}
+
+// WithExecElevationCommand runs smartctl through command (e.g. "doas", "-n")
+// for ExecBackend when the process is not root.
+func WithExecElevationCommand(command string, args ...string) ExecBackendOption {
+	return smexec.WithElevationCommand(command, args...)
+}
+
+// ErrElevationFailed is returned when the elevation command refuses to run
+// smartctl. It is re-exported from the exec backend.
+var ErrElevationFailed = smexec.ErrElevationFailed
+
+// NormalizeDevicePath rewrites Windows `\\.\PhysicalDriveN` paths and
+// /dev/disk aliases into the "/dev/sdX" form reported by ScanDevices. Other
+// paths are returned unchanged.
+func NormalizeDevicePath(devicePath string) string {
+	return smexec.NormalizeDevicePath(devicePath)
+}
+
+// ResolveDeviceAlias resolves a /dev/disk alias such as a /dev/disk/by-id
+// link to the device it links to. Other paths are returned unchanged.
+func ResolveDeviceAlias(devicePath string) (string, error) {
+	return smexec.ResolveDeviceAlias(devicePath)
+}
+
+// ByIDAliases returns the stable /dev/disk/by-id links of devicePath, sorted.
+func ByIDAliases(devicePath string) ([]string, error) {
+	return smexec.ByIDAliases(devicePath)
+}
+
 // DrivedbUpstreamCommit is the upstream smartmontools commit SHA from which
 // the embedded drivedb.h was taken. It is re-exported from the exec backend.
 const DrivedbUpstreamCommit = smexec.DrivedbUpstreamCommit
@@ -0,0 +1,71 @@
+package smartmontools
+
+import (
+	"context"
+	"errors"
+	osexec "os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusCommander is a ContextCommander returning a fixed result and error.
+type statusCommander struct {
+	result *CommandResult
+	err    error
+}
+
+func (s statusCommander) CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error) {
+	return s.result, s.err
+}
+
+func TestExitStatusAccessors(t *testing.T) {
+	s := ExitStatus(0x4C)
+	assert.False(t, s.CommandLineError())
+	assert.False(t, s.DeviceOpenFailed())
+	assert.True(t, s.CommandFailed())
+	assert.True(t, s.DiskFailing())
+	assert.False(t, s.PrefailAttributes())
+	assert.False(t, s.PastPrefailAttributes())
+	assert.True(t, s.ErrorLogged())
+	assert.False(t, s.SelfTestErrors())
+	assert.True(t, s.ExecFailed())
+	assert.True(t, s.HealthProblem())
+	assert.True(t, s.Has(ExitDiskFailing|ExitErrorLogged))
+	assert.Equal(t, "0x4c (command failed, disk failing, errors logged)", s.String())
+	assert.Equal(t, "0x00", ExitStatus(0).String())
+	assert.False(t, ExitStatus(0x80).ExecFailed())
+}
+
+func TestSMARTInfoExitStatus(t *testing.T) {
+	var nilInfo *SMARTInfo
+	assert.Equal(t, ExitStatus(0), nilInfo.ExitStatus())
+	info := &SMARTInfo{Smartctl: &SmartctlInfo{ExitStatus: 0x24}}
+	assert.True(t, info.ExitStatus().PastPrefailAttributes())
+	assert.True(t, info.ExitStatus().CommandFailed())
+
+	exitInfo := &ExitCodeInfo{ExecBits: 0x04, HealthBits: 0x20}
+	assert.Equal(t, info.ExitStatus(), exitInfo.Status())
+}
+
+func TestSmartctlErrorFromBackend(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithContextCommander(statusCommander{
+			result: &CommandResult{Stderr: []byte("Smartctl open device: /dev/sdx failed: No such device\n"), ExitCode: 2},
+			err:    &osexec.ExitError{},
+		}),
+	)
+	require.NoError(t, err)
+
+	err = client.EnableSMART(context.Background(), "/dev/sdx")
+	require.Error(t, err)
+	var smartErr *SmartctlError
+	require.True(t, errors.As(err, &smartErr))
+	assert.True(t, smartErr.Status.DeviceOpenFailed())
+	assert.Contains(t, err.Error(), "No such device")
+
+	var exitErr *osexec.ExitError
+	assert.True(t, errors.As(err, &exitErr), "underlying *exec.ExitError must stay reachable")
+}
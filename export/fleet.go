@@ -0,0 +1,260 @@
+package export
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// Fleet report formats.
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// DefaultFleetReportTitle is the title of fleet reports without
+// WithReportTitle.
+const DefaultFleetReportTitle = "Drive health report"
+
+// FleetReportData is the value fleet report templates are executed with: the
+// summary fields are promoted, so templates can use {{.Healthy}} or
+// {{range .Devices}}.
+type FleetReportData struct {
+	Title string
+	*smartmontools.FleetSummary
+}
+
+// FleetReport renders a FleetSummary with its per-device details as a
+// Markdown or HTML document, for nightly email reports or wiki pages.
+//
+// The default document is built from the named templates "summary", "worst"
+// and "devices". A template passed to WithReportTemplate that only contains
+// {{define}} actions replaces those sections; one with a body replaces the
+// whole document. Besides the standard functions, templates can call:
+//
+//	bytes     capacity in decimal units, e.g. "4.0 TB"
+//	score     the score value, or "-" when the device was not scored
+//	temp      the temperature as "35 °C", or "-" when unknown
+//	notes     the error, or the HealthScore factors, of a FleetDevice
+//	md        escapes text for a Markdown table cell
+type FleetReport struct {
+	format Format
+	title  string
+	text   string
+	funcs  map[string]any
+
+	execute func(w io.Writer, data FleetReportData) error
+}
+
+// FleetReportOption configures a FleetReport.
+type FleetReportOption func(*FleetReport)
+
+// WithReportTitle sets the report title (default DefaultFleetReportTitle).
+func WithReportTitle(title string) FleetReportOption {
+	return func(r *FleetReport) {
+		r.title = title
+	}
+}
+
+// WithReportTemplate customizes the report with a text/template (Markdown) or
+// html/template (HTML) source, parsed after the default templates.
+func WithReportTemplate(text string) FleetReportOption {
+	return func(r *FleetReport) {
+		r.text = text
+	}
+}
+
+// WithReportFuncs adds functions that report templates can call, overriding
+// the built-in ones of the same name.
+func WithReportFuncs(funcs map[string]any) FleetReportOption {
+	return func(r *FleetReport) {
+		for name, fn := range funcs {
+			r.funcs[name] = fn
+		}
+	}
+}
+
+// NewFleetReport returns a FleetReport rendering in format, FormatMarkdown or
+// FormatHTML. It fails for other formats and for templates that do not parse.
+func NewFleetReport(format Format, opts ...FleetReportOption) (*FleetReport, error) {
+	r := &FleetReport{format: format, title: DefaultFleetReportTitle, funcs: fleetReportFuncs()}
+	for _, opt := range opts {
+		opt(r)
+	}
+	switch format {
+	case FormatMarkdown:
+		tmpl, err := template.New("report").Funcs(r.funcs).Parse(fleetMarkdownTemplate)
+		if err == nil && r.text != "" {
+			tmpl, err = tmpl.Parse(r.text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("export: fleet report template: %w", err)
+		}
+		r.execute = func(w io.Writer, data FleetReportData) error { return tmpl.Execute(w, data) }
+	case FormatHTML:
+		tmpl, err := htmltemplate.New("report").Funcs(r.funcs).Parse(fleetHTMLTemplate)
+		if err == nil && r.text != "" {
+			tmpl, err = tmpl.Parse(r.text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("export: fleet report template: %w", err)
+		}
+		r.execute = func(w io.Writer, data FleetReportData) error { return tmpl.Execute(w, data) }
+	default:
+		return nil, fmt.Errorf("export: unsupported fleet report format %q", format)
+	}
+	return r, nil
+}
+
+// Render writes the report of summary to w.
+func (r *FleetReport) Render(w io.Writer, summary *smartmontools.FleetSummary) error {
+	if summary == nil {
+		summary = &smartmontools.FleetSummary{}
+	}
+	return r.execute(w, FleetReportData{Title: r.title, FleetSummary: summary})
+}
+
+// WriteFleetMarkdown writes the default Markdown report of summary to w.
+func WriteFleetMarkdown(w io.Writer, summary *smartmontools.FleetSummary) error {
+	return writeFleetReport(w, summary, FormatMarkdown)
+}
+
+// WriteFleetHTML writes the default HTML report of summary to w.
+func WriteFleetHTML(w io.Writer, summary *smartmontools.FleetSummary) error {
+	return writeFleetReport(w, summary, FormatHTML)
+}
+
+func writeFleetReport(w io.Writer, summary *smartmontools.FleetSummary, format Format) error {
+	r, err := NewFleetReport(format)
+	if err != nil {
+		return err
+	}
+	return r.Render(w, summary)
+}
+
+// fleetReportFuncs returns the built-in functions of fleet report templates.
+func fleetReportFuncs() map[string]any {
+	return map[string]any{
+		"bytes": formatBytes,
+		"score": func(s smartmontools.Score) string {
+			if !s.Evaluated {
+				return "-"
+			}
+			return fmt.Sprint(s.Value)
+		},
+		"temp": func(celsius int) string {
+			if celsius == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%d °C", celsius)
+		},
+		"notes": deviceNotes,
+		"md":    markdownCell,
+	}
+}
+
+// formatBytes formats a byte count in decimal units, as drive vendors do.
+func formatBytes(n int64) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	unit := 0
+	for value >= 1000 && unit < len(byteUnits)-1 {
+		value /= 1000
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", value, byteUnits[unit])
+}
+
+var byteUnits = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+
+// deviceNotes explains the status of d: its error, or why it lost points.
+func deviceNotes(d smartmontools.FleetDevice) string {
+	if d.Error != "" {
+		return d.Error
+	}
+	details := make([]string, 0, len(d.Score.Factors))
+	for _, f := range d.Score.Factors {
+		details = append(details, f.Detail)
+	}
+	return strings.Join(details, "; ")
+}
+
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+// markdownCell escapes s for a Markdown table cell.
+func markdownCell(s string) string {
+	return markdownCellEscaper.Replace(s)
+}
+
+const fleetMarkdownTemplate = `# {{.Title}}
+
+Generated {{.Time.Format "2006-01-02 15:04 MST"}}.
+{{template "summary" .}}{{template "worst" .}}{{template "devices" .}}
+{{- define "summary"}}
+| Devices | Healthy | Failing | Standby | Unknown | Capacity |
+|--------:|--------:|--------:|--------:|--------:|---------:|
+| {{.Total}} | {{.Healthy}} | {{.Failing}} | {{.Standby}} | {{.Unknown}} | {{bytes .CapacityBytes}} |
+{{end}}
+{{- define "worst"}}{{if .Worst}}
+## Worst devices
+{{range .Worst}}
+- **{{.DevicePath}}**{{with .Model}} {{.}}{{end}}: score {{.Score.Value}}, {{.Status}}
+{{- range .Score.Factors}}
+  - {{.Detail}}
+{{- end}}
+{{- end}}
+{{end}}{{end}}
+{{- define "devices"}}
+## Devices
+
+| Device | Model | Serial | Type | Capacity | Temperature | Score | Status | Notes |
+|--------|-------|--------|------|---------:|------------:|------:|--------|-------|
+{{range .Devices}}| {{md .DevicePath}} | {{md .Model}} | {{md .Serial}} | {{md .DiskType}} | {{if .CapacityBytes}}{{bytes .CapacityBytes}}{{else}}-{{end}} | {{temp .Temperature}} | {{score .Score}} | {{.Status}} | {{md (notes .)}} |
+{{end}}{{end}}`
+
+const fleetHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.failing { background: #f8d7da; }
+.unknown { background: #fff3cd; }
+.standby { color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated {{.Time.Format "2006-01-02 15:04 MST"}}.</p>
+{{template "summary" .}}{{template "worst" .}}{{template "devices" .}}</body>
+</html>
+{{define "summary"}}<table>
+<tr><th>Devices</th><th>Healthy</th><th>Failing</th><th>Standby</th><th>Unknown</th><th>Capacity</th></tr>
+<tr><td>{{.Total}}</td><td>{{.Healthy}}</td><td>{{.Failing}}</td><td>{{.Standby}}</td><td>{{.Unknown}}</td><td>{{bytes .CapacityBytes}}</td></tr>
+</table>
+{{end}}
+{{- define "worst"}}{{if .Worst}}<h2>Worst devices</h2>
+<ul>
+{{range .Worst}}<li><strong>{{.DevicePath}}</strong>{{with .Model}} {{.}}{{end}}: score {{.Score.Value}}, {{.Status}}
+{{- if .Score.Factors}}
+<ul>
+{{range .Score.Factors}}<li>{{.Detail}}</li>
+{{end}}</ul>
+{{- end}}</li>
+{{end}}</ul>
+{{end}}{{end}}
+{{- define "devices"}}<h2>Devices</h2>
+<table>
+<tr><th>Device</th><th>Model</th><th>Serial</th><th>Type</th><th>Capacity</th><th>Temperature</th><th>Score</th><th>Status</th><th>Notes</th></tr>
+{{range .Devices}}<tr class="{{.Status}}"><td>{{.DevicePath}}</td><td>{{.Model}}</td><td>{{.Serial}}</td><td>{{.DiskType}}</td><td>{{if .CapacityBytes}}{{bytes .CapacityBytes}}{{else}}-{{end}}</td><td>{{temp .Temperature}}</td><td>{{score .Score}}</td><td>{{.Status}}</td><td>{{notes .}}</td></tr>
+{{end}}</table>
+{{end}}`
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFleetSummary() *smartmontools.FleetSummary {
+	worn := smartmontools.FleetDevice{
+		DevicePath:    "/dev/sdb",
+		Status:        smartmontools.FleetHealthy,
+		Model:         "Worn <HDD>",
+		Serial:        "S|2",
+		DiskType:      "HDD",
+		CapacityBytes: 2_000_000_000_000,
+		Score: smartmontools.Score{Value: 84, Evaluated: true, Factors: []smartmontools.ScoreFactor{
+			{Source: "attribute_5", Penalty: 16, Detail: "Reallocated_Sector_Ct raw value is 8"},
+		}},
+	}
+	return &smartmontools.FleetSummary{
+		Time:          time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC),
+		Total:         3,
+		Healthy:       2,
+		Unknown:       1,
+		CapacityBytes: 6_000_000_000_000,
+		Worst:         []smartmontools.FleetDevice{worn},
+		Devices: []smartmontools.FleetDevice{
+			{DevicePath: "/dev/sda", Status: smartmontools.FleetHealthy, Model: "Good HDD", DiskType: "HDD",
+				CapacityBytes: 4_000_000_000_000, Temperature: 35, Score: smartmontools.Score{Value: 100, Evaluated: true}},
+			worn,
+			{DevicePath: "/dev/sdc", Status: smartmontools.FleetUnknown, Error: "permission denied"},
+		},
+	}
+}
+
+func TestWriteFleetMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteFleetMarkdown(&buf, testFleetSummary()))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "# Drive health report\n\nGenerated 2026-03-01 02:00 UTC.\n"), out)
+	assert.Contains(t, out, "| 3 | 2 | 0 | 0 | 1 | 6.0 TB |\n")
+	assert.Contains(t, out, "## Worst devices\n\n- **/dev/sdb** Worn <HDD>: score 84, healthy\n  - Reallocated_Sector_Ct raw value is 8\n")
+	assert.Contains(t, out, "| /dev/sda | Good HDD |  | HDD | 4.0 TB | 35 °C | 100 | healthy |  |\n")
+	assert.Contains(t, out, `| /dev/sdb | Worn <HDD> | S\|2 | HDD | 2.0 TB | - | 84 | healthy | Reallocated_Sector_Ct raw value is 8 |`)
+	assert.Contains(t, out, "| /dev/sdc |  |  |  | - | - | - | unknown | permission denied |\n")
+}
+
+func TestWriteFleetHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteFleetHTML(&buf, testFleetSummary()))
+	out := buf.String()
+
+	assert.Contains(t, out, "<title>Drive health report</title>")
+	assert.Contains(t, out, "<td>3</td><td>2</td><td>0</td><td>0</td><td>1</td><td>6.0 TB</td>")
+	assert.Contains(t, out, "<li><strong>/dev/sdb</strong> Worn &lt;HDD&gt;: score 84, healthy")
+	assert.Contains(t, out, `<tr class="unknown"><td>/dev/sdc</td>`)
+	assert.NotContains(t, out, "<HDD>", "HTML output is escaped")
+}
+
+func TestFleetReport_Customized(t *testing.T) {
+	r, err := NewFleetReport(FormatMarkdown,
+		WithReportTitle("Nightly"),
+		WithReportTemplate(`{{define "devices"}}{{range .Devices}}{{shout .DevicePath}}
+{{end}}{{end}}`),
+		WithReportFuncs(map[string]any{"shout": strings.ToUpper}),
+	)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, r.Render(&buf, testFleetSummary()))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "# Nightly\n"), "sections can be redefined")
+	assert.Contains(t, out, "## Worst devices")
+	assert.Contains(t, out, "/DEV/SDA\n/DEV/SDB\n/DEV/SDC\n")
+	assert.NotContains(t, out, "## Devices")
+
+	r, err = NewFleetReport(FormatHTML, WithReportTemplate(`<p>{{.Failing}} failing of {{.Total}}</p>`))
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, r.Render(&buf, testFleetSummary()))
+	assert.Equal(t, "<p>0 failing of 3</p>", buf.String(), "a template body replaces the document")
+}
+
+func TestNewFleetReport_Errors(t *testing.T) {
+	_, err := NewFleetReport(FormatCSV)
+	assert.ErrorContains(t, err, "unsupported fleet report format")
+	_, err = NewFleetReport(FormatMarkdown, WithReportTemplate("{{.Total"))
+	assert.ErrorContains(t, err, "fleet report template")
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "500.1 GB", formatBytes(500_107_862_016))
+	assert.Equal(t, "18.0 TB", formatBytes(18_000_207_937_536))
+}
@@ -0,0 +1,268 @@
+// Package export converts SMART snapshots into formats consumed by external
+// tools: InfluxDB line protocol for time-series databases, CSV and JSON
+// reports for archiving and spreadsheet analysis, and Markdown and HTML fleet
+// health reports.
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// DefaultInfluxPrefix is the measurement prefix used by InfluxEncoder when
+// Prefix is empty.
+const DefaultInfluxPrefix = "smart"
+
+// InfluxEncoder writes SMARTInfo snapshots as InfluxDB line protocol. Each
+// snapshot produces one "<prefix>_device" line with the overall health,
+// temperature and counters, one "<prefix>_attribute" line per ATA attribute
+// (tagged with id and name) and, for NVMe devices, one "<prefix>_nvme" line
+// with the health log. All lines carry device, serial, model and type tags.
+type InfluxEncoder struct {
+	// Prefix is prepended to measurement names; DefaultInfluxPrefix when empty.
+	Prefix string
+	// Tags are added to every line, e.g. {"host": "nas01"}.
+	Tags map[string]string
+}
+
+// Encode writes the lines for info, timestamped at, to w.
+func (e *InfluxEncoder) Encode(w io.Writer, at time.Time, info *smartmontools.SMARTInfo) error {
+	_, err := w.Write(e.Append(nil, at, info))
+	return err
+}
+
+// Append appends the lines for info, timestamped at, to buf.
+func (e *InfluxEncoder) Append(buf []byte, at time.Time, info *smartmontools.SMARTInfo) []byte {
+	if info == nil {
+		return buf
+	}
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = DefaultInfluxPrefix
+	}
+	tags := map[string]string{
+		"device": info.Device.Name,
+		"serial": info.SerialNumber,
+		"model":  info.ModelName,
+		"type":   info.DiskType,
+	}
+	for k, v := range e.Tags {
+		tags[k] = v
+	}
+	ts := at.UnixNano()
+
+	device := &influxLine{}
+	if info.SmartStatus != nil {
+		device.boolField("health_ok", info.SmartStatus.Passed)
+	}
+	device.boolField("standby", info.InStandby)
+	switch {
+	case info.Temperature != nil:
+		device.intField("temperature", int64(info.Temperature.Current))
+	case info.NvmeSmartHealth != nil && info.NvmeSmartHealth.Temperature != 0:
+		device.intField("temperature", int64(info.NvmeSmartHealth.Temperature))
+	}
+	if info.PowerOnTime != nil {
+		device.intField("power_on_hours", int64(info.PowerOnTime.Hours))
+	}
+	if info.PowerCycleCount > 0 {
+		device.intField("power_cycles", int64(info.PowerCycleCount))
+	}
+	if info.ExitCodeInfo != nil {
+		device.intField("exit_status", int64(info.ExitCodeInfo.ExecBits|info.ExitCodeInfo.HealthBits))
+	}
+	buf = device.append(buf, prefix+"_device", tags, nil, ts)
+
+	if info.AtaSmartData != nil {
+		for _, attr := range info.AtaSmartData.Table {
+			line := &influxLine{}
+			line.intField("value", int64(attr.Value))
+			line.intField("worst", int64(attr.Worst))
+			line.intField("thresh", int64(attr.Thresh))
+			line.intField("raw", attr.Raw.Count())
+			line.boolField("failing", attr.WhenFailed != "")
+			buf = line.append(buf, prefix+"_attribute", tags, map[string]string{
+				"id":   strconv.Itoa(attr.ID),
+				"name": attr.Name,
+			}, ts)
+		}
+	}
+
+	if h := info.NvmeSmartHealth; h != nil {
+		line := &influxLine{}
+		line.intField("critical_warning", int64(h.CriticalWarning))
+		line.intField("temperature", int64(h.Temperature))
+		line.intField("available_spare", int64(h.AvailableSpare))
+		line.intField("available_spare_threshold", int64(h.AvailableSpareThresh))
+		line.intField("percentage_used", int64(h.PercentageUsed))
+		line.intField("data_units_read", h.DataUnitsRead)
+		line.intField("data_units_written", h.DataUnitsWritten)
+		line.intField("host_read_commands", h.HostReadCommands)
+		line.intField("host_write_commands", h.HostWriteCommands)
+		line.intField("controller_busy_time", h.ControllerBusyTime)
+		line.intField("power_cycles", h.PowerCycles)
+		line.intField("power_on_hours", h.PowerOnHours)
+		line.intField("unsafe_shutdowns", h.UnsafeShutdowns)
+		line.intField("media_errors", h.MediaErrors)
+		line.intField("num_err_log_entries", h.NumErrLogEntries)
+		buf = line.append(buf, prefix+"_nvme", tags, nil, ts)
+	}
+	return buf
+}
+
+// influxLine accumulates the field set of one line.
+type influxLine struct {
+	fields []string
+}
+
+func (l *influxLine) intField(key string, v int64) {
+	l.fields = append(l.fields, escapeInfluxKey(key)+"="+strconv.FormatInt(v, 10)+"i")
+}
+
+func (l *influxLine) boolField(key string, v bool) {
+	l.fields = append(l.fields, escapeInfluxKey(key)+"="+strconv.FormatBool(v))
+}
+
+// append writes the line with tags sorted by key, as recommended for
+// InfluxDB write performance. Empty tag values are omitted.
+func (l *influxLine) append(buf []byte, measurement string, tags, extra map[string]string, ts int64) []byte {
+	if len(l.fields) == 0 {
+		return buf
+	}
+	all := make(map[string]string, len(tags)+len(extra))
+	for k, v := range tags {
+		all[k] = v
+	}
+	for k, v := range extra {
+		all[k] = v
+	}
+	keys := make([]string, 0, len(all))
+	for k, v := range all {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	buf = append(buf, influxMeasurementEscaper.Replace(measurement)...)
+	for _, k := range keys {
+		buf = append(buf, ',')
+		buf = append(buf, escapeInfluxKey(k)...)
+		buf = append(buf, '=')
+		buf = append(buf, escapeInfluxKey(all[k])...)
+	}
+	buf = append(buf, ' ')
+	buf = append(buf, strings.Join(l.fields, ",")...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, ts, 10)
+	return append(buf, '\n')
+}
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", `\n`)
+	influxKeyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+)
+
+func escapeInfluxKey(s string) string {
+	return influxKeyEscaper.Replace(s)
+}
+
+// InfluxWriterOption configures an InfluxWriter.
+type InfluxWriterOption func(*InfluxWriter)
+
+// WithInfluxToken sets the API token sent as "Authorization: Token <token>".
+func WithInfluxToken(token string) InfluxWriterOption {
+	return func(w *InfluxWriter) {
+		w.token = token
+	}
+}
+
+// WithInfluxHTTPClient sets the HTTP client (default: 10s timeout).
+func WithInfluxHTTPClient(client *http.Client) InfluxWriterOption {
+	return func(w *InfluxWriter) {
+		if client != nil {
+			w.client = client
+		}
+	}
+}
+
+// WithInfluxEncoder sets the encoder used to format snapshots.
+func WithInfluxEncoder(encoder *InfluxEncoder) InfluxWriterOption {
+	return func(w *InfluxWriter) {
+		if encoder != nil {
+			w.encoder = encoder
+		}
+	}
+}
+
+// InfluxWriter POSTs snapshots in line protocol to an InfluxDB-compatible
+// write endpoint.
+type InfluxWriter struct {
+	writeURL string
+	token    string
+	client   *http.Client
+	encoder  *InfluxEncoder
+}
+
+// NewInfluxWriter returns a writer for writeURL, the complete write endpoint
+// including its query string, for example
+// "http://influx:8086/api/v2/write?org=home&bucket=smart" for InfluxDB 2.x or
+// "http://victoria:8428/write?db=smart" for InfluxDB 1.x and VictoriaMetrics.
+// Timestamps are written with nanosecond precision.
+func NewInfluxWriter(writeURL string, opts ...InfluxWriterOption) (*InfluxWriter, error) {
+	u, err := url.Parse(writeURL)
+	if err != nil {
+		return nil, fmt.Errorf("export: invalid InfluxDB URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("export: invalid InfluxDB URL %q: must be an absolute http(s) URL", writeURL)
+	}
+	w := &InfluxWriter{
+		writeURL: writeURL,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		encoder:  &InfluxEncoder{},
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w, nil
+}
+
+// Write sends the given snapshots, all timestamped at, in a single request.
+func (w *InfluxWriter) Write(ctx context.Context, at time.Time, infos ...*smartmontools.SMARTInfo) error {
+	var body []byte
+	for _, info := range infos {
+		body = w.encoder.Append(body, at, info)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.writeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("export: build InfluxDB request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("export: write to InfluxDB: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("export: InfluxDB returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
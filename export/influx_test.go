@@ -0,0 +1,96 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Unix(1700000000, 0).UTC()
+
+func ataSnapshot() *smartmontools.SMARTInfo {
+	return &smartmontools.SMARTInfo{
+		Device:          smartmontools.Device{Name: "/dev/sda"},
+		ModelName:       "WDC WD40EFRX",
+		SerialNumber:    "WD-123",
+		DiskType:        "HDD",
+		SmartStatus:     &smartmontools.SmartStatus{Passed: true},
+		Temperature:     &smartmontools.Temperature{Current: 34},
+		PowerOnTime:     &smartmontools.PowerOnTime{Hours: 1200},
+		PowerCycleCount: 42,
+		AtaSmartData: &smartmontools.AtaSmartData{Table: []smartmontools.SmartAttribute{
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 200, Worst: 200, Thresh: 140, Raw: smartmontools.Raw{Value: 0, String: "0"}},
+			{ID: 194, Name: "Temperature_Celsius", Value: 116, Worst: 100, Raw: smartmontools.Raw{Value: 0x0032_0014_0022, String: "34 (Min/Max 20/50)"}},
+		}},
+	}
+}
+
+func TestInfluxEncoder_ATA(t *testing.T) {
+	var buf bytes.Buffer
+	enc := &InfluxEncoder{Tags: map[string]string{"host": "nas 01"}}
+	require.NoError(t, enc.Encode(&buf, testTime, ataSnapshot()))
+
+	want := `smart_device,device=/dev/sda,host=nas\ 01,model=WDC\ WD40EFRX,serial=WD-123,type=HDD health_ok=true,standby=false,temperature=34i,power_on_hours=1200i,power_cycles=42i 1700000000000000000
+smart_attribute,device=/dev/sda,host=nas\ 01,id=5,model=WDC\ WD40EFRX,name=Reallocated_Sector_Ct,serial=WD-123,type=HDD value=200i,worst=200i,thresh=140i,raw=0i,failing=false 1700000000000000000
+smart_attribute,device=/dev/sda,host=nas\ 01,id=194,model=WDC\ WD40EFRX,name=Temperature_Celsius,serial=WD-123,type=HDD value=116i,worst=100i,thresh=0i,raw=34i,failing=false 1700000000000000000
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestInfluxEncoder_NVMe(t *testing.T) {
+	info := &smartmontools.SMARTInfo{
+		Device:          smartmontools.Device{Name: "/dev/nvme0"},
+		ModelName:       "Samsung,990",
+		NvmeSmartHealth: &smartmontools.NvmeSmartHealth{Temperature: 40, PercentageUsed: 3, MediaErrors: 1},
+	}
+	out := string((&InfluxEncoder{Prefix: "disk"}).Append(nil, testTime, info))
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], `disk_device,device=/dev/nvme0,model=Samsung\,990 standby=false,temperature=40i `), lines[0])
+	assert.Contains(t, lines[1], "disk_nvme,")
+	assert.Contains(t, lines[1], "percentage_used=3i")
+	assert.Contains(t, lines[1], "media_errors=1i")
+
+	assert.Empty(t, (&InfluxEncoder{}).Append(nil, testTime, nil))
+}
+
+func TestInfluxWriter(t *testing.T) {
+	var body, auth, query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, auth, query = string(data), r.Header.Get("Authorization"), r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w, err := NewInfluxWriter(srv.URL+"/api/v2/write?org=home&bucket=smart", WithInfluxToken("tok"))
+	require.NoError(t, err)
+	require.NoError(t, w.Write(context.Background(), testTime, ataSnapshot()))
+	assert.Equal(t, "Token tok", auth)
+	assert.Equal(t, "org=home&bucket=smart", query)
+	assert.Equal(t, 3, strings.Count(body, "\n"))
+
+	require.NoError(t, w.Write(context.Background(), testTime), "nothing to write")
+}
+
+func TestInfluxWriter_Errors(t *testing.T) {
+	_, err := NewInfluxWriter("influx:8086")
+	assert.Error(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bucket not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+	w, err := NewInfluxWriter(srv.URL + "/write?db=smart")
+	require.NoError(t, err)
+	assert.ErrorContains(t, w.Write(context.Background(), testTime, ataSnapshot()), "bucket not found")
+}
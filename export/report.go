@@ -0,0 +1,174 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/history"
+)
+
+// Format selects the report format of ExportHistory (CSV or JSON) or of a
+// FleetReport (Markdown or HTML).
+type Format string
+
+// Report formats.
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// csvBaseColumns are the leading CSV columns, always present in this order.
+var csvBaseColumns = []string{
+	"time", "device", "serial", "model", "firmware",
+	"health_passed", "temperature", "power_on_hours", "power_cycles",
+}
+
+// csvNvmeColumns are appended when any snapshot carries an NVMe health log.
+var csvNvmeColumns = []struct {
+	name  string
+	value func(*smartmontools.NvmeSmartHealth) int64
+}{
+	{"nvme_critical_warning", func(h *smartmontools.NvmeSmartHealth) int64 { return int64(h.CriticalWarning) }},
+	{"nvme_available_spare", func(h *smartmontools.NvmeSmartHealth) int64 { return int64(h.AvailableSpare) }},
+	{"nvme_percentage_used", func(h *smartmontools.NvmeSmartHealth) int64 { return int64(h.PercentageUsed) }},
+	{"nvme_data_units_read", func(h *smartmontools.NvmeSmartHealth) int64 { return h.DataUnitsRead }},
+	{"nvme_data_units_written", func(h *smartmontools.NvmeSmartHealth) int64 { return h.DataUnitsWritten }},
+	{"nvme_unsafe_shutdowns", func(h *smartmontools.NvmeSmartHealth) int64 { return h.UnsafeShutdowns }},
+	{"nvme_media_errors", func(h *smartmontools.NvmeSmartHealth) int64 { return h.MediaErrors }},
+	{"nvme_num_err_log_entries", func(h *smartmontools.NvmeSmartHealth) int64 { return h.NumErrLogEntries }},
+}
+
+// WriteCSV writes snapshots as CSV, one row per snapshot. Columns are stable:
+// the base columns (time, device, serial, model, firmware, health_passed,
+// temperature, power_on_hours, power_cycles), then attr_<id>_value,
+// attr_<id>_worst and attr_<id>_raw for every ATA attribute seen in any
+// snapshot in ascending ID order, then the nvme_* columns if any snapshot is
+// from an NVMe device. Values a snapshot does not report are left empty.
+// Times are written in RFC 3339 format.
+func WriteCSV(w io.Writer, snapshots []history.Snapshot) error {
+	var attrIDs []int
+	hasNvme := false
+	for _, s := range snapshots {
+		if s.Info == nil {
+			continue
+		}
+		if s.Info.AtaSmartData != nil {
+			for _, a := range s.Info.AtaSmartData.Table {
+				if !slices.Contains(attrIDs, a.ID) {
+					attrIDs = append(attrIDs, a.ID)
+				}
+			}
+		}
+		hasNvme = hasNvme || s.Info.NvmeSmartHealth != nil
+	}
+	slices.Sort(attrIDs)
+
+	header := slices.Clone(csvBaseColumns)
+	for _, id := range attrIDs {
+		header = append(header, fmt.Sprintf("attr_%d_value", id), fmt.Sprintf("attr_%d_worst", id), fmt.Sprintf("attr_%d_raw", id))
+	}
+	if hasNvme {
+		for _, c := range csvNvmeColumns {
+			header = append(header, c.name)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, s := range snapshots {
+		info := s.Info
+		if info == nil {
+			info = &smartmontools.SMARTInfo{}
+		}
+		row := make([]string, 0, len(header))
+		row = append(row, s.Time.Format(time.RFC3339), info.Device.Name, s.Serial, info.ModelName, info.Firmware)
+		row = append(row, optional(info.SmartStatus != nil, func() string { return strconv.FormatBool(info.SmartStatus.Passed) }))
+		row = append(row, optional(info.Temperature != nil, func() string { return strconv.Itoa(info.Temperature.Current) }))
+		row = append(row, optional(info.PowerOnTime != nil, func() string { return strconv.Itoa(info.PowerOnTime.Hours) }))
+		row = append(row, optional(info.PowerCycleCount > 0, func() string { return strconv.Itoa(info.PowerCycleCount) }))
+		for _, id := range attrIDs {
+			if a := info.AtaSmartData.GetAttributeByID(id); a != nil {
+				row = append(row, strconv.Itoa(a.Value), strconv.Itoa(a.Worst), strconv.FormatInt(a.Raw.Count(), 10))
+			} else {
+				row = append(row, "", "", "")
+			}
+		}
+		if hasNvme {
+			for _, c := range csvNvmeColumns {
+				row = append(row, optional(info.NvmeSmartHealth != nil, func() string {
+					return strconv.FormatInt(c.value(info.NvmeSmartHealth), 10)
+				}))
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func optional(ok bool, value func() string) string {
+	if !ok {
+		return ""
+	}
+	return value()
+}
+
+// WriteJSON writes snapshots as an indented JSON array. Each element has
+// time, serial and info fields; info uses smartctl's JSON field names in the
+// declaration order of SMARTInfo, so the output is stable.
+func WriteJSON(w io.Writer, snapshots []history.Snapshot) error {
+	if snapshots == nil {
+		snapshots = []history.Snapshot{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snapshots)
+}
+
+// WriteSnapshotCSV writes a single snapshot as CSV (see WriteCSV).
+func WriteSnapshotCSV(w io.Writer, at time.Time, info *smartmontools.SMARTInfo) error {
+	return WriteCSV(w, []history.Snapshot{snapshotOf(at, info)})
+}
+
+// WriteSnapshotJSON writes a single snapshot as an indented JSON object.
+func WriteSnapshotJSON(w io.Writer, at time.Time, info *smartmontools.SMARTInfo) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snapshotOf(at, info))
+}
+
+func snapshotOf(at time.Time, info *smartmontools.SMARTInfo) history.Snapshot {
+	s := history.Snapshot{Time: at, Info: info}
+	if info != nil {
+		s.Serial = info.SerialNumber
+	}
+	return s
+}
+
+// ExportHistory writes the snapshots of serial matching q from store to w in
+// the given format.
+func ExportHistory(ctx context.Context, store history.Store, serial string, q history.Query, w io.Writer, format Format) error {
+	snapshots, err := store.Snapshots(ctx, serial, q)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, snapshots)
+	case FormatJSON:
+		return WriteJSON(w, snapshots)
+	default:
+		return fmt.Errorf("export: unsupported format %q", format)
+	}
+}
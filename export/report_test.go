@@ -0,0 +1,103 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	older := ataSnapshot()
+	older.AtaSmartData = &smartmontools.AtaSmartData{Table: []smartmontools.SmartAttribute{
+		{ID: 194, Name: "Temperature_Celsius", Value: 118, Worst: 100, Raw: smartmontools.Raw{Value: 32, String: "32"}},
+	}}
+	nvme := &smartmontools.SMARTInfo{
+		Device:          smartmontools.Device{Name: "/dev/nvme0"},
+		SerialNumber:    "WD-123",
+		NvmeSmartHealth: &smartmontools.NvmeSmartHealth{PercentageUsed: 2},
+	}
+	snapshots := []history.Snapshot{
+		{Time: testTime, Serial: "WD-123", Info: older},
+		{Time: testTime.Add(time.Hour), Serial: "WD-123", Info: ataSnapshot()},
+		{Time: testTime.Add(2 * time.Hour), Serial: "WD-123", Info: nvme},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, snapshots))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	header := records[0]
+	assert.Equal(t, csvBaseColumns, header[:len(csvBaseColumns)])
+	assert.Equal(t, []string{
+		"attr_5_value", "attr_5_worst", "attr_5_raw",
+		"attr_194_value", "attr_194_worst", "attr_194_raw",
+	}, header[len(csvBaseColumns):len(csvBaseColumns)+6])
+	assert.Equal(t, "nvme_critical_warning", header[len(csvBaseColumns)+6])
+
+	col := func(row []string, name string) string {
+		for i, h := range header {
+			if h == name {
+				return row[i]
+			}
+		}
+		t.Fatalf("missing column %s", name)
+		return ""
+	}
+	assert.Equal(t, "2023-11-14T22:13:20Z", col(records[1], "time"))
+	assert.Equal(t, "", col(records[1], "attr_5_raw"), "attribute missing from first snapshot")
+	assert.Equal(t, "32", col(records[1], "attr_194_raw"))
+	assert.Equal(t, "0", col(records[2], "attr_5_raw"))
+	assert.Equal(t, "34", col(records[2], "attr_194_raw"))
+	assert.Equal(t, "true", col(records[2], "health_passed"))
+	assert.Equal(t, "", col(records[2], "nvme_percentage_used"))
+	assert.Equal(t, "2", col(records[3], "nvme_percentage_used"))
+	assert.Equal(t, "", col(records[3], "health_passed"))
+}
+
+func TestWriteSnapshotCSV_NoNvmeColumns(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshotCSV(&buf, testTime, ataSnapshot()))
+	assert.NotContains(t, buf.String(), "nvme_")
+	assert.Contains(t, buf.String(), "/dev/sda,WD-123,WDC WD40EFRX")
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshotJSON(&buf, testTime, ataSnapshot()))
+	assert.Contains(t, buf.String(), "\n  \"serial\": \"WD-123\"")
+	var snap history.Snapshot
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &snap))
+	assert.Equal(t, "WD-123", snap.Info.SerialNumber)
+
+	buf.Reset()
+	require.NoError(t, WriteJSON(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestExportHistory(t *testing.T) {
+	ctx := context.Background()
+	store := history.NewMemoryStore()
+	require.NoError(t, store.Record(ctx, testTime, ataSnapshot()))
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportHistory(ctx, store, "WD-123", history.Query{}, &buf, FormatJSON))
+	var snaps []history.Snapshot
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &snaps))
+	assert.Len(t, snaps, 1)
+
+	buf.Reset()
+	require.NoError(t, ExportHistory(ctx, store, "WD-123", history.Query{}, &buf, FormatCSV))
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+
+	assert.Error(t, ExportHistory(ctx, store, "WD-123", history.Query{}, &buf, "xml"))
+}
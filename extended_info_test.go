@@ -0,0 +1,44 @@
+package smartmontools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendedSMARTInfo_JSONRoundTrip(t *testing.T) {
+	input := `{
+		"model_name": "Disk",
+		"ata_sct_status": {"format_version": 3, "sct_version": 522, "temperature": {"current": 30}},
+		"sata_phy_event_counters": {"table": [{"id": 10, "name": "R_ERR response for device-to-host data FIS", "size": 16, "value": 0, "overflow": false}], "reset": false},
+		"unmodeled_section": {"x": 1}
+	}`
+	var info ExtendedSMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(input), &info))
+	assert.Equal(t, "Disk", info.ModelName)
+	require.NotNil(t, info.AtaSctStatus)
+	assert.Equal(t, 30, info.AtaSctStatus.Temperature.Current)
+	assert.Len(t, info.SataPhyEventCounters.Table, 1)
+	assert.Len(t, info.Extra, 1)
+	assert.Contains(t, info.Extra, "unmodeled_section")
+
+	out, err := json.Marshal(info)
+	require.NoError(t, err)
+	var decoded map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Contains(t, decoded, "ata_sct_status")
+	assert.Contains(t, decoded, "sata_phy_event_counters")
+	assert.Contains(t, decoded, "unmodeled_section")
+	assert.JSONEq(t, `"Disk"`, string(decoded["model_name"]))
+}
+
+func TestGetExtendedSMARTInfo_UnsupportedBackend(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	_, err = client.GetExtendedSMARTInfo(context.Background(), "/dev/sda")
+	assert.ErrorContains(t, err, `backend "plain" does not provide extended SMART info`)
+}
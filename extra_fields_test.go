@@ -0,0 +1,53 @@
+package smartmontools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMARTInfo_ExtraFields(t *testing.T) {
+	var info SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(rawInfoJSON), &info))
+	assert.Equal(t, "RAW1", info.SerialNumber)
+	assert.NotContains(t, info.Extra, "serial_number")
+	assert.JSONEq(t, `{"x":1}`, string(info.Extra["future_field"]))
+
+	out, err := json.Marshal(info)
+	require.NoError(t, err)
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(out, &fields))
+	assert.JSONEq(t, `{"x":1}`, string(fields["future_field"]))
+	assert.JSONEq(t, `"RAW1"`, string(fields["serial_number"]))
+
+	// Modeled fields win over a conflicting Extra entry.
+	info.Extra["serial_number"] = json.RawMessage(`"STALE"`)
+	out, err = json.Marshal(&info)
+	require.NoError(t, err)
+	var round SMARTInfo
+	require.NoError(t, json.Unmarshal(out, &round))
+	assert.Equal(t, "RAW1", round.SerialNumber)
+}
+
+func TestSMARTInfo_NoExtraFields(t *testing.T) {
+	var info SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{"device":{"name":"/dev/sda"},"serial_number":"S1"}`), &info))
+	assert.Nil(t, info.Extra)
+}
+
+func TestGetSMARTInfo_ExposesExtraFields(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -a -j --nocheck=standby /dev/sda": {output: []byte(rawInfoJSON)},
+		}}),
+	)
+	require.NoError(t, err)
+
+	info, err := client.GetSMARTInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.Contains(t, info.Extra, "future_field")
+}
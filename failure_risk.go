@@ -0,0 +1,124 @@
+package smartmontools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RiskLevel grades the likelihood that a drive fails in the near term.
+type RiskLevel int
+
+const (
+	// RiskUnknown means the heuristics could not be applied, e.g. because the
+	// device reports no ATA attribute table.
+	RiskUnknown RiskLevel = iota
+	// RiskLow means none of the failure-correlated attributes are non-zero.
+	RiskLow
+	// RiskElevated means a single failure-correlated attribute is non-zero.
+	RiskElevated
+	// RiskHigh means several failure-correlated attributes are non-zero, or
+	// the drive's own SMART self-assessment failed.
+	RiskHigh
+)
+
+// String returns the risk level name.
+func (l RiskLevel) String() string {
+	switch l {
+	case RiskLow:
+		return "low"
+	case RiskElevated:
+		return "elevated"
+	case RiskHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l RiskLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// RiskIndicator is a failure-correlated attribute found non-zero.
+type RiskIndicator struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Raw  int64  `json:"raw"`
+}
+
+// FailureRisk is the result of PredictFailureRisk.
+type FailureRisk struct {
+	Level       RiskLevel       `json:"level"`
+	Explanation string          `json:"explanation"`
+	Indicators  []RiskIndicator `json:"indicators,omitempty"`
+}
+
+// failureIndicatorIDs are the attributes that published field statistics
+// (Backblaze) found most strongly correlated with drive failure.
+var failureIndicatorIDs = []int{
+	SmartAttrReallocatedSectors,
+	SmartAttrReportedUncorrect,
+	SmartAttrCommandTimeout,
+	SmartAttrCurrentPendingSector,
+	SmartAttrOfflineUncorrectable,
+}
+
+// PredictFailureRisk applies the Backblaze-style failure heuristics to an ATA
+// drive: a non-zero raw value in any of attributes 5 (reallocated sectors),
+// 187 (reported uncorrectable), 188 (command timeouts), 197 (pending sectors)
+// or 198 (offline uncorrectable) correlates with imminent failure. One
+// non-zero indicator yields RiskElevated, two or more yield RiskHigh. A failed
+// SMART self-assessment is always RiskHigh. Devices without an ATA attribute
+// table (NVMe, SCSI) get RiskUnknown unless their self-assessment failed.
+func PredictFailureRisk(info *SMARTInfo) FailureRisk {
+	if info == nil {
+		return FailureRisk{Level: RiskUnknown, Explanation: "no SMART data"}
+	}
+	failed := (info.SmartStatus != nil && !info.SmartStatus.Passed && info.SmartStatus.Damaged) ||
+		(info.ExitCodeInfo != nil && info.ExitCodeInfo.HealthBits&0x08 != 0)
+
+	var risk FailureRisk
+	if info.AtaSmartData != nil {
+		for _, id := range failureIndicatorIDs {
+			attr := info.AtaSmartData.GetAttributeByID(id)
+			if attr == nil {
+				continue
+			}
+			if raw := attr.Raw.Count(); raw > 0 {
+				name := attr.Name
+				if d, ok := DescribeAttribute(id); ok {
+					name = d.Name
+				}
+				risk.Indicators = append(risk.Indicators, RiskIndicator{ID: id, Name: name, Raw: raw})
+			}
+		}
+	}
+
+	switch {
+	case failed:
+		risk.Level = RiskHigh
+	case info.AtaSmartData == nil:
+		risk.Level = RiskUnknown
+		risk.Explanation = "failure heuristics require ATA SMART attributes"
+		return risk
+	case len(risk.Indicators) >= 2:
+		risk.Level = RiskHigh
+	case len(risk.Indicators) == 1:
+		risk.Level = RiskElevated
+	default:
+		risk.Level = RiskLow
+		risk.Explanation = "no failure-correlated attributes are non-zero"
+		return risk
+	}
+
+	parts := make([]string, 0, len(risk.Indicators)+1)
+	if failed {
+		parts = append(parts, "SMART overall-health self-assessment failed")
+	}
+	for _, ind := range risk.Indicators {
+		parts = append(parts, fmt.Sprintf("%s (%d) is %d", ind.Name, ind.ID, ind.Raw))
+	}
+	risk.Explanation = strings.Join(parts, "; ")
+	return risk
+}
@@ -0,0 +1,74 @@
+package smartmontools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictFailureRisk(t *testing.T) {
+	tests := []struct {
+		name       string
+		info       *SMARTInfo
+		level      RiskLevel
+		indicators []int
+	}{
+		{name: "nil info", info: nil, level: RiskUnknown},
+		{
+			name:  "nvme without failure",
+			info:  &SMARTInfo{SmartStatus: &SmartStatus{Passed: true}, NvmeSmartHealth: &NvmeSmartHealth{}},
+			level: RiskUnknown,
+		},
+		{
+			name: "all zero",
+			info: &SMARTInfo{AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+				{ID: 5, Raw: Raw{String: "0"}},
+				{ID: 197, Raw: Raw{String: "0"}},
+			}}},
+			level: RiskLow,
+		},
+		{
+			name: "single indicator",
+			info: &SMARTInfo{AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+				{ID: 188, Name: "Command_Timeout", Raw: Raw{Value: 0x0002_0002_0002, String: "2 2 2"}},
+			}}},
+			level:      RiskElevated,
+			indicators: []int{188},
+		},
+		{
+			name:       "multiple indicators",
+			info:       &SMARTInfo{AtaSmartData: newAttributeTable()},
+			level:      RiskHigh,
+			indicators: []int{5, 197},
+		},
+		{
+			name: "self-assessment failed",
+			info: &SMARTInfo{
+				SmartStatus:     &SmartStatus{Passed: false, Damaged: true},
+				NvmeSmartHealth: &NvmeSmartHealth{},
+			},
+			level: RiskHigh,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk := PredictFailureRisk(tt.info)
+			assert.Equal(t, tt.level, risk.Level)
+			assert.NotEmpty(t, risk.Explanation)
+			var ids []int
+			for _, ind := range risk.Indicators {
+				ids = append(ids, ind.ID)
+			}
+			assert.Equal(t, tt.indicators, ids)
+		})
+	}
+}
+
+func TestFailureRisk_JSON(t *testing.T) {
+	data, err := json.Marshal(PredictFailureRisk(&SMARTInfo{AtaSmartData: newAttributeTable()}))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"level":"high"`)
+	assert.Contains(t, string(data), `"name":"Reallocated_Sector_Ct"`)
+}
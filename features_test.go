@@ -0,0 +1,49 @@
+package smartmontools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWriteCache_ExecBackend(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -g wcache /dev/sda":    {output: []byte("Write cache is:   Disabled\n")},
+			"/usr/sbin/smartctl -g wcreorder /dev/sda": {output: []byte("Wt Cache Reorder: Unavailable\n")},
+		}}),
+	)
+	require.NoError(t, err)
+
+	enabled, err := client.GetWriteCache(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	_, err = client.GetWriteCacheReorder(context.Background(), "/dev/sda")
+	assert.ErrorIs(t, err, ErrFeatureNotSupported)
+}
+
+func TestWriteCache_UnsupportedBackend(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	_, err = client.GetWriteCache(context.Background(), "/dev/sda")
+	assert.ErrorContains(t, err, `backend "plain" does not manage drive features`)
+	assert.ErrorContains(t, client.SetWriteCache(context.Background(), "/dev/sda", true), "does not manage drive features")
+	_, err = client.GetWriteCacheReorder(context.Background(), "/dev/sda")
+	assert.ErrorContains(t, err, "does not manage drive features")
+	assert.ErrorContains(t, client.SetWriteCacheReorder(context.Background(), "/dev/sda", false), "does not manage drive features")
+	_, err = client.GetDSN(context.Background(), "/dev/sda")
+	assert.ErrorContains(t, err, "does not manage drive features")
+	assert.ErrorContains(t, client.SetDSN(context.Background(), "/dev/sda", true), "does not manage drive features")
+	assert.ErrorContains(t, client.EnableAttributeAutosave(context.Background(), "/dev/sda"), "does not manage drive features")
+	assert.ErrorContains(t, client.DisableAttributeAutosave(context.Background(), "/dev/sda"), "does not manage drive features")
+	assert.ErrorContains(t, client.EnableAutoOfflineCollection(context.Background(), "/dev/sda"), "does not manage drive features")
+	assert.ErrorContains(t, client.DisableAutoOfflineCollection(context.Background(), "/dev/sda"), "does not manage drive features")
+	assert.ErrorContains(t, client.SecurityFreeze(context.Background(), "/dev/sda"), "does not manage drive features")
+	_, err = client.GetSecureEraseInfo(context.Background(), "/dev/sda")
+	assert.ErrorContains(t, err, "does not manage drive features")
+}
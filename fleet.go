@@ -0,0 +1,177 @@
+package smartmontools
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// FleetWorstDevices is the number of devices listed in FleetSummary.Worst.
+const FleetWorstDevices = 5
+
+// FleetStatus is the health class of one device in a FleetSummary.
+type FleetStatus string
+
+// Device health classes counted by FleetHealth.
+const (
+	// FleetHealthy means the drive passed its SMART self-assessment.
+	FleetHealthy FleetStatus = "healthy"
+	// FleetFailing means smartctl reported the drive failing, or pre-failure
+	// attributes at or below their threshold.
+	FleetFailing FleetStatus = "failing"
+	// FleetStandby means the drive was in standby and was not woken up.
+	FleetStandby FleetStatus = "standby"
+	// FleetUnknown means the drive could not be read or reported no SMART
+	// data to judge.
+	FleetUnknown FleetStatus = "unknown"
+)
+
+// FleetDevice is the health of one device in a FleetSummary.
+type FleetDevice struct {
+	DevicePath string      `json:"device_path"`
+	Status     FleetStatus `json:"status"`
+	Score      Score       `json:"score"`
+	Model      string      `json:"model,omitempty"`
+	Serial     string      `json:"serial,omitempty"`
+	DiskType   string      `json:"disk_type,omitempty"`
+	// CapacityBytes is the user capacity, or the NVM capacity of NVMe drives
+	// that report no user capacity. Zero when unknown.
+	CapacityBytes int64 `json:"capacity_bytes,omitempty"`
+	// Temperature is the current temperature in Celsius, zero when unknown.
+	Temperature int `json:"temperature,omitempty"`
+	// Error is the text of Err.
+	Error string `json:"error,omitempty"`
+	// Err is the error that made the device FleetUnknown or FleetStandby.
+	Err error `json:"-"`
+	// Info is the SMART information the status was derived from; nil when
+	// the device could not be read.
+	Info *SMARTInfo `json:"-"`
+}
+
+// FleetSummary aggregates the health of many devices for at-a-glance
+// dashboards.
+type FleetSummary struct {
+	Time    time.Time `json:"time"`
+	Total   int       `json:"total"`
+	Healthy int       `json:"healthy"`
+	Failing int       `json:"failing"`
+	Standby int       `json:"standby"`
+	Unknown int       `json:"unknown"`
+	// CapacityBytes sums the capacity of the devices that reported one.
+	CapacityBytes int64 `json:"capacity_bytes"`
+	// Worst lists up to FleetWorstDevices scored devices below a perfect
+	// score, lowest HealthScore first.
+	Worst []FleetDevice `json:"worst,omitempty"`
+	// Devices lists every device, sorted by path.
+	Devices []FleetDevice `json:"devices"`
+}
+
+// FleetHealth collects SMART information from every scanned device with
+// CollectAll and summarizes it with SummarizeFleet. The returned error is
+// non-nil only when device scanning fails.
+func (c *Client) FleetHealth(ctx context.Context) (*FleetSummary, error) {
+	results, err := c.CollectAll(ctx, CollectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeFleet(results), nil
+}
+
+// SummarizeFleet classifies the results of CollectAll, scores each device
+// with HealthScore and returns the aggregate counts, total capacity and worst
+// devices. It lets SmartClient implementations and callers that collect with
+// their own CollectOptions build the same summary as FleetHealth.
+func SummarizeFleet(results map[string]CollectResult) *FleetSummary {
+	summary := &FleetSummary{Time: time.Now(), Total: len(results), Devices: make([]FleetDevice, 0, len(results))}
+	for path, r := range results {
+		d := fleetDevice(path, r)
+		switch d.Status {
+		case FleetHealthy:
+			summary.Healthy++
+		case FleetFailing:
+			summary.Failing++
+		case FleetStandby:
+			summary.Standby++
+		default:
+			summary.Unknown++
+		}
+		summary.CapacityBytes += d.CapacityBytes
+		summary.Devices = append(summary.Devices, d)
+	}
+	slices.SortFunc(summary.Devices, func(a, b FleetDevice) int {
+		return cmp.Compare(a.DevicePath, b.DevicePath)
+	})
+
+	for _, d := range summary.Devices {
+		if d.Score.Evaluated && d.Score.Value < 100 {
+			summary.Worst = append(summary.Worst, d)
+		}
+	}
+	slices.SortStableFunc(summary.Worst, func(a, b FleetDevice) int {
+		return cmp.Compare(a.Score.Value, b.Score.Value)
+	})
+	if len(summary.Worst) > FleetWorstDevices {
+		summary.Worst = summary.Worst[:FleetWorstDevices]
+	}
+	return summary
+}
+
+// fleetDevice classifies the CollectAll result of one device.
+func fleetDevice(path string, r CollectResult) FleetDevice {
+	d := FleetDevice{DevicePath: path, Err: r.Err, Info: r.Info}
+	d.Status, _ = ClassifyHealth(r.Info, r.Err)
+	if r.Err != nil {
+		d.Error = r.Err.Error()
+	}
+	info := r.Info
+	if r.Err != nil || info == nil {
+		return d
+	}
+	d.Model = info.ModelName
+	d.Serial = info.SerialNumber
+	d.DiskType = info.DiskType
+	switch {
+	case info.UserCapacity != nil && info.UserCapacity.Bytes > 0:
+		d.CapacityBytes = info.UserCapacity.Bytes
+	case info.NvmeTotalCapacity > 0:
+		d.CapacityBytes = info.NvmeTotalCapacity
+	}
+	if info.Temperature != nil {
+		d.Temperature = info.Temperature.Current
+	}
+	if d.Status != FleetStandby {
+		d.Score = HealthScore(info)
+	}
+	return d
+}
+
+// ClassifyHealth returns the health class of a drive from the outcome of
+// GetSMARTInfo, with a short reason. Errors make the drive FleetUnknown, or
+// FleetStandby for ErrDeviceInStandby. Drives whose SMART self-assessment
+// failed, or whose pre-failure attributes reached their threshold, are
+// FleetFailing; drives reporting no self-assessment are FleetUnknown.
+func ClassifyHealth(info *SMARTInfo, err error) (FleetStatus, string) {
+	switch {
+	case errors.Is(err, ErrDeviceInStandby):
+		return FleetStandby, "device is in standby"
+	case err != nil:
+		return FleetUnknown, err.Error()
+	case info == nil:
+		return FleetUnknown, "no SMART information"
+	case info.InStandby:
+		return FleetStandby, "device is in standby"
+	}
+	exit := info.ExitStatus()
+	status := info.SmartStatus
+	switch {
+	case exit.DiskFailing() || (status != nil && status.Damaged):
+		return FleetFailing, "SMART overall-health self-assessment failed"
+	case exit.PrefailAttributes() || (status != nil && status.Critical):
+		return FleetFailing, "pre-failure attributes are at or below their threshold"
+	case status != nil && status.Passed:
+		return FleetHealthy, "SMART overall-health self-assessment passed"
+	}
+	return FleetUnknown, "no SMART overall-health self-assessment"
+}
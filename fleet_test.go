@@ -0,0 +1,148 @@
+package smartmontools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fleetBackend serves canned SMART information or errors per device.
+type fleetBackend struct {
+	Backend
+	infos map[string]*SMARTInfo
+	errs  map[string]error
+}
+
+func (b *fleetBackend) Name() string { return "fleet" }
+
+func (b *fleetBackend) ScanDevices(ctx context.Context) ([]Device, error) {
+	var devices []Device
+	for path := range b.infos {
+		devices = append(devices, Device{Name: path})
+	}
+	for path := range b.errs {
+		devices = append(devices, Device{Name: path})
+	}
+	return devices, nil
+}
+
+func (b *fleetBackend) GetSMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, error) {
+	if err := b.errs[devicePath]; err != nil {
+		return nil, err
+	}
+	return b.infos[devicePath], nil
+}
+
+func TestFleetHealth(t *testing.T) {
+	backend := &fleetBackend{
+		infos: map[string]*SMARTInfo{
+			"/dev/sda": {
+				ModelName:    "Good HDD",
+				UserCapacity: &UserCapacity{Bytes: 4_000_000_000_000},
+				SmartStatus:  &SmartStatus{Passed: true},
+				Temperature:  &Temperature{Current: 35},
+			},
+			"/dev/sdb": {
+				ModelName:    "Worn HDD",
+				UserCapacity: &UserCapacity{Bytes: 2_000_000_000_000},
+				SmartStatus:  &SmartStatus{Passed: true},
+				AtaSmartData: &AtaSmartData{Table: []SmartAttribute{{ID: SmartAttrReallocatedSectors, Raw: Raw{Value: 8}}}},
+			},
+			"/dev/sdc": {
+				ModelName:    "Dying HDD",
+				UserCapacity: &UserCapacity{Bytes: 1_000_000_000_000},
+				SmartStatus:  &SmartStatus{Passed: false, Damaged: true},
+				Smartctl:     &SmartctlInfo{ExitStatus: 0x08},
+			},
+			"/dev/nvme0": {
+				ModelName:         "NVMe SSD",
+				NvmeTotalCapacity: 500_000_000_000,
+				SmartStatus:       &SmartStatus{Passed: true},
+			},
+			"/dev/sdd": {InStandby: true},
+		},
+		errs: map[string]error{
+			"/dev/sde": fmt.Errorf("read: %w", ErrPermissionDenied),
+			"/dev/sdf": fmt.Errorf("probe: %w", ErrDeviceInStandby),
+		},
+	}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	summary, err := client.FleetHealth(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 7, summary.Total)
+	assert.Equal(t, 3, summary.Healthy)
+	assert.Equal(t, 1, summary.Failing)
+	assert.Equal(t, 2, summary.Standby)
+	assert.Equal(t, 1, summary.Unknown)
+	assert.Equal(t, int64(7_500_000_000_000), summary.CapacityBytes)
+	assert.False(t, summary.Time.IsZero())
+
+	require.Len(t, summary.Devices, 7)
+	assert.Equal(t, "/dev/nvme0", summary.Devices[0].DevicePath, "devices are sorted by path")
+	assert.Equal(t, FleetHealthy, summary.Devices[1].Status)
+	assert.Equal(t, 35, summary.Devices[1].Temperature)
+	sde := summary.Devices[5]
+	assert.Equal(t, FleetUnknown, sde.Status)
+	assert.ErrorIs(t, sde.Err, ErrPermissionDenied)
+	assert.Contains(t, sde.Error, "permission denied")
+
+	require.Len(t, summary.Worst, 2, "only devices below a perfect score are listed")
+	assert.Equal(t, "/dev/sdc", summary.Worst[0].DevicePath)
+	assert.Equal(t, FleetFailing, summary.Worst[0].Status)
+	assert.Equal(t, "/dev/sdb", summary.Worst[1].DevicePath)
+	assert.Equal(t, FleetHealthy, summary.Worst[1].Status)
+	assert.Less(t, summary.Worst[0].Score.Value, summary.Worst[1].Score.Value)
+}
+
+func TestFleetHealth_ScanError(t *testing.T) {
+	client, err := NewClient(WithBackend(&collectBackend{}))
+	require.NoError(t, err)
+	_, err = client.FleetHealth(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSummarizeFleet_WorstIsBounded(t *testing.T) {
+	results := make(map[string]CollectResult)
+	for i := range FleetWorstDevices + 2 {
+		results[fmt.Sprintf("/dev/sd%c", 'a'+i)] = CollectResult{Info: &SMARTInfo{
+			SmartStatus:  &SmartStatus{Passed: true},
+			AtaSmartData: &AtaSmartData{Table: []SmartAttribute{{ID: SmartAttrCurrentPendingSector, Raw: Raw{Value: int64(i + 1)}}}},
+		}}
+	}
+	results["/dev/missing"] = CollectResult{Err: errors.New("gone")}
+
+	summary := SummarizeFleet(results)
+	assert.Equal(t, FleetWorstDevices+2, summary.Healthy)
+	assert.Equal(t, 1, summary.Unknown)
+	require.Len(t, summary.Worst, FleetWorstDevices)
+	assert.Equal(t, fmt.Sprintf("/dev/sd%c", 'a'+FleetWorstDevices+1), summary.Worst[0].DevicePath, "the highest pending count scores lowest")
+}
+
+func TestClassifyHealth(t *testing.T) {
+	tests := []struct {
+		name string
+		info *SMARTInfo
+		err  error
+		want FleetStatus
+	}{
+		{"error", nil, ErrPermissionDenied, FleetUnknown},
+		{"standby error", nil, fmt.Errorf("probe: %w", ErrDeviceInStandby), FleetStandby},
+		{"no info", nil, nil, FleetUnknown},
+		{"standby", &SMARTInfo{InStandby: true}, nil, FleetStandby},
+		{"passed", &SMARTInfo{SmartStatus: &SmartStatus{Passed: true}}, nil, FleetHealthy},
+		{"failing exit bit", &SMARTInfo{Smartctl: &SmartctlInfo{ExitStatus: 0x08}, SmartStatus: &SmartStatus{}}, nil, FleetFailing},
+		{"prefail exit bit", &SMARTInfo{Smartctl: &SmartctlInfo{ExitStatus: 0x10}, SmartStatus: &SmartStatus{Passed: true}}, nil, FleetFailing},
+		{"no self-assessment", &SMARTInfo{}, nil, FleetUnknown},
+	}
+	for _, tt := range tests {
+		got, reason := ClassifyHealth(tt.info, tt.err)
+		assert.Equal(t, tt.want, got, tt.name)
+		assert.NotEmpty(t, reason, tt.name)
+	}
+}
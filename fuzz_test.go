@@ -0,0 +1,126 @@
+package smartmontools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
+)
+
+// addCorpusSeeds seeds f with the smartctl JSON output of the fixture corpus.
+func addCorpusSeeds(f *testing.F) {
+	files, _ := filepath.Glob(filepath.Join("smartmontoolstest", "corpus", "*", "*.json"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		var fixture struct {
+			Output json.RawMessage `json:"output"`
+		}
+		if json.Unmarshal(data, &fixture) == nil && len(fixture.Output) > 0 {
+			f.Add([]byte(fixture.Output))
+		}
+	}
+	f.Add([]byte(`{"ata_smart_data":{"self_test":{"status":"completed"}},"ata_smart_attributes":{"table":[{"id":194,"raw":{"value":-1,"string":"(Min/Max"}}]}}`))
+	f.Add([]byte(`{"nvme_smart_health_information_log":{"percentage_used":-5},"nvme_self_test_log":{"table":[{}]}}`))
+	f.Add([]byte(`{"smartctl":{"version":[7],"exit_status":-1},"json_format_version":[]}`))
+	f.Add([]byte(`null`))
+}
+
+// FuzzSMARTInfo checks that no smartctl output, however malformed, makes the
+// helpers working on a decoded SMARTInfo panic.
+func FuzzSMARTInfo(f *testing.F) {
+	addCorpusSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var info SMARTInfo
+		if json.Unmarshal(data, &info) != nil {
+			return
+		}
+		var previous SMARTInfo
+		HealthScore(&info)
+		HealthScoreSince(&previous, &info)
+		PredictFailureRisk(&info)
+		IdentityOf(&info)
+		CompareSMARTInfo(&previous, &info)
+		DeltaAttributes(&previous, &info)
+		BytesWritten(&info)
+		EnduranceUsed(&info, 600)
+		GetSSDLifeRemaining(&info)
+		NvmeWearWarnings(&info)
+		TemperatureLimit(&info)
+		IsOverheating(&info)
+		smtypes.SelfTestInProgress(&info)
+		smtypes.LastSelfTestResult(&info)
+		_ = info.ExitStatus().String()
+		info.PowerOnDuration()
+		info.WearLevelPercent()
+		info.ZonedModel()
+		if info.Smartctl != nil {
+			info.Smartctl.VersionInfo()
+		}
+		if log := info.AtaSmartSelectiveSelfTestLog; log != nil {
+			log.Spans()
+		}
+		if data := info.AtaSmartData; data != nil {
+			data.ReallocatedSectors()
+			data.PendingSectors()
+			data.GetAttributeByName("Temperature_Celsius")
+			for _, attr := range data.Table {
+				attr.Raw.Temperature()
+				attr.Raw.Duration()
+				attr.Raw.Count()
+				DescribeAttribute(attr.ID)
+			}
+		}
+		if _, err := smtypes.NewExtendedSMARTInfo(&info, data); err != nil {
+			t.Fatalf("decoded SMARTInfo is not extended info: %v", err)
+		}
+		snapshot, err := MarshalSnapshot(&info)
+		if err != nil {
+			t.Fatalf("MarshalSnapshot: %v", err)
+		}
+		if _, err := UnmarshalSnapshot(snapshot); err != nil {
+			t.Fatalf("UnmarshalSnapshot of a marshaled snapshot: %v", err)
+		}
+	})
+}
+
+// FuzzUnmarshalSnapshot checks that stored snapshots are decoded or rejected
+// without panicking.
+func FuzzUnmarshalSnapshot(f *testing.F) {
+	f.Add([]byte(`{"schema_version":1,"disk_type":"SSD","info":{"model_name":"X"}}`))
+	f.Add([]byte(`{"schema_version":-1,"info":null}`))
+	f.Add([]byte(`{"model_name":"bare"}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if info, err := UnmarshalSnapshot(data); err == nil && info == nil {
+			t.Fatal("UnmarshalSnapshot returned neither info nor error")
+		}
+	})
+}
+
+// FuzzStatusField checks both the string and the object form of status fields.
+func FuzzStatusField(f *testing.F) {
+	f.Add([]byte(`"completed"`))
+	f.Add([]byte(`{"value":249,"string":"in progress","passed":false,"remaining_percent":90}`))
+	f.Add([]byte(`"\u00`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var status StatusField
+		_ = json.Unmarshal(data, &status)
+	})
+}
+
+// FuzzParseRaw checks the attribute raw string decoders.
+func FuzzParseRaw(f *testing.F) {
+	for _, raw := range []string{"29 (Min/Max 18/56)", "36 (0 14 0 0 0)", "35825h+02m+39.040s", "12345", "h+m+.s", "(Min/Max /)"} {
+		f.Add(raw)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		ParseRawTemperature(raw)
+		if d, ok := ParseRawDuration(raw); ok && d < 0 {
+			t.Fatalf("ParseRawDuration(%q) = %v", raw, d)
+		}
+	})
+}
@@ -5,6 +5,7 @@ go 1.26
 require (
 	github.com/dianlight/tlog v0.2.2
 	github.com/stretchr/testify v1.11.1
+	go.etcd.io/bbolt v1.4.3
 )
 
 require (
@@ -0,0 +1,15 @@
+// Package grpc exposes a smartmontools-go SmartClient as a gRPC service so
+// remote agents can serve SMART data to a central collector.
+//
+// The service is defined in smartpb/smart.proto. Register a Server on a
+// google.golang.org/grpc server:
+//
+//	client, _ := smartmontools.NewClient()
+//	s := grpc.NewServer()
+//	smartgrpc.Register(s, smartgrpc.NewServer(client))
+//
+// This package lives in its own Go module so that the gRPC dependencies are
+// only pulled in by users who need them.
+package grpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative smartpb/smart.proto
@@ -0,0 +1,34 @@
+module github.com/dianlight/smartmontools-go/grpc
+
+go 1.26
+
+replace github.com/dianlight/smartmontools-go => ../
+
+require (
+	github.com/dianlight/smartmontools-go v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.11.1
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dianlight/tlog v0.2.2 // indirect
+	github.com/fatih/color v1.18.0 // indirect
+	github.com/k0kubun/pp/v3 v3.5.0 // indirect
+	github.com/lmittmann/tint v1.1.2 // indirect
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/samber/lo v1.52.0 // indirect
+	github.com/samber/slog-common v0.19.0 // indirect
+	github.com/samber/slog-formatter v1.2.2 // indirect
+	github.com/samber/slog-multi v1.7.0 // indirect
+	gitlab.com/tozd/go/errors v0.10.0 // indirect
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
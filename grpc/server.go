@@ -0,0 +1,192 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/grpc/smartpb"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validTestTypes are the self-test types accepted by RunSelfTest.
+var validTestTypes = []string{"short", "long", "conveyance", "offline"}
+
+// Server implements smartpb.SmartServiceServer on top of a SmartClient.
+type Server struct {
+	smartpb.UnimplementedSmartServiceServer
+	client smartmontools.SmartClient
+}
+
+var _ smartpb.SmartServiceServer = (*Server)(nil)
+
+// NewServer returns a Server serving client.
+func NewServer(client smartmontools.SmartClient) *Server {
+	return &Server{client: client}
+}
+
+// Register registers srv on a gRPC server.
+func Register(s ggrpc.ServiceRegistrar, srv *Server) {
+	smartpb.RegisterSmartServiceServer(s, srv)
+}
+
+// ScanDevices implements smartpb.SmartServiceServer.
+func (s *Server) ScanDevices(ctx context.Context, _ *smartpb.ScanDevicesRequest) (*smartpb.ScanDevicesResponse, error) {
+	devices, err := s.client.ScanDevices(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &smartpb.ScanDevicesResponse{Devices: make([]*smartpb.Device, 0, len(devices))}
+	for _, d := range devices {
+		resp.Devices = append(resp.Devices, &smartpb.Device{Name: d.Name, Type: d.Type})
+	}
+	return resp, nil
+}
+
+// GetSMARTInfo implements smartpb.SmartServiceServer.
+func (s *Server) GetSMARTInfo(ctx context.Context, req *smartpb.GetSMARTInfoRequest) (*smartpb.SMARTInfo, error) {
+	if req.GetDevice() == "" {
+		return nil, status.Error(codes.InvalidArgument, "device is required")
+	}
+	info, err := s.client.GetSMARTInfo(ctx, req.GetDevice())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return infoToProto(req.GetDevice(), info)
+}
+
+// CheckHealth implements smartpb.SmartServiceServer.
+func (s *Server) CheckHealth(ctx context.Context, req *smartpb.CheckHealthRequest) (*smartpb.CheckHealthResponse, error) {
+	if req.GetDevice() == "" {
+		return nil, status.Error(codes.InvalidArgument, "device is required")
+	}
+	passed, err := s.client.CheckHealth(ctx, req.GetDevice())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &smartpb.CheckHealthResponse{Passed: passed}, nil
+}
+
+// RunSelfTest implements smartpb.SmartServiceServer. Progress reported by
+// SmartClient.RunSelfTestWithProgress is streamed until it reaches 100%.
+// Cancelling the call stops the progress stream, not the self-test itself.
+func (s *Server) RunSelfTest(req *smartpb.RunSelfTestRequest, stream ggrpc.ServerStreamingServer[smartpb.SelfTestProgress]) error {
+	if req.GetDevice() == "" {
+		return status.Error(codes.InvalidArgument, "device is required")
+	}
+	testType := req.GetTestType()
+	if testType == "" {
+		testType = "short"
+	}
+	if !slices.Contains(validTestTypes, testType) {
+		return status.Errorf(codes.InvalidArgument, "invalid test type %q", testType)
+	}
+
+	ctx := stream.Context()
+	updates := make(chan *smartpb.SelfTestProgress, 16)
+	callback := func(progress int, msg string, _ smartmontools.ProgressSource, _ smartmontools.SelfTestOutcome) {
+		update := &smartpb.SelfTestProgress{Progress: int32(progress), Status: msg, Done: progress >= 100}
+		select {
+		case updates <- update:
+		case <-ctx.Done():
+		}
+	}
+	if err := s.client.RunSelfTestWithProgress(ctx, req.GetDevice(), testType, callback); err != nil {
+		return toStatus(err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case update := <-updates:
+			if err := stream.Send(update); err != nil {
+				return err
+			}
+			if update.Done {
+				return nil
+			}
+		}
+	}
+}
+
+// toStatus maps library errors to gRPC status errors.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, smartmontools.ErrDeviceNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, smartmontools.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, smartmontools.ErrSmartNotSupported), errors.Is(err, smartmontools.ErrTestNotSupported):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, smartmontools.ErrDeviceInStandby):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func infoToProto(device string, info *smartmontools.SMARTInfo) (*smartpb.SMARTInfo, error) {
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encode SMART info: %v", err)
+	}
+	out := &smartpb.SMARTInfo{
+		Device:          device,
+		ModelName:       info.ModelName,
+		SerialNumber:    info.SerialNumber,
+		Firmware:        info.Firmware,
+		DiskType:        info.DiskType,
+		InStandby:       info.InStandby,
+		PowerCycleCount: int64(info.PowerCycleCount),
+		InfoJson:        raw,
+	}
+	if info.SmartStatus != nil {
+		passed := info.SmartStatus.Passed
+		out.HealthPassed = &passed
+	}
+	if info.Temperature != nil {
+		temp := int32(info.Temperature.Current)
+		out.Temperature = &temp
+	}
+	if info.PowerOnTime != nil {
+		hours := int64(info.PowerOnTime.Hours)
+		out.PowerOnHours = &hours
+	}
+	if info.AtaSmartData != nil {
+		for _, a := range info.AtaSmartData.Table {
+			out.Attributes = append(out.Attributes, &smartpb.Attribute{
+				Id:         int32(a.ID),
+				Name:       a.Name,
+				Value:      int32(a.Value),
+				Worst:      int32(a.Worst),
+				Thresh:     int32(a.Thresh),
+				RawValue:   a.Raw.Value,
+				RawString:  a.Raw.String,
+				WhenFailed: a.WhenFailed,
+			})
+		}
+	}
+	if h := info.NvmeSmartHealth; h != nil {
+		out.Nvme = &smartpb.NvmeHealth{
+			CriticalWarning:         int32(h.CriticalWarning),
+			Temperature:             int32(h.Temperature),
+			AvailableSpare:          int32(h.AvailableSpare),
+			AvailableSpareThreshold: int32(h.AvailableSpareThresh),
+			PercentageUsed:          int32(h.PercentageUsed),
+			DataUnitsRead:           h.DataUnitsRead,
+			DataUnitsWritten:        h.DataUnitsWritten,
+			PowerCycles:             h.PowerCycles,
+			PowerOnHours:            h.PowerOnHours,
+			UnsafeShutdowns:         h.UnsafeShutdowns,
+			MediaErrors:             h.MediaErrors,
+			NumErrLogEntries:        h.NumErrLogEntries,
+		}
+	}
+	return out, nil
+}
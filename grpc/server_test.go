@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/grpc/smartpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeClient implements the SmartClient methods used by Server; other
+// methods panic through the nil embedded interface.
+type fakeClient struct {
+	smartmontools.SmartClient
+}
+
+func (fakeClient) ScanDevices(ctx context.Context) ([]smartmontools.Device, error) {
+	return []smartmontools.Device{{Name: "/dev/sda", Type: "sat"}}, nil
+}
+
+func (fakeClient) GetSMARTInfo(ctx context.Context, devicePath string) (*smartmontools.SMARTInfo, error) {
+	if devicePath == "/dev/missing" {
+		return nil, fmt.Errorf("failed to get SMART info: %w", smartmontools.ErrDeviceNotFound)
+	}
+	if devicePath != "/dev/sda" {
+		return nil, errors.New("device open failed")
+	}
+	return &smartmontools.SMARTInfo{
+		ModelName:    "TestDisk",
+		SerialNumber: "SER1",
+		SmartStatus:  &smartmontools.SmartStatus{Passed: true},
+		Temperature:  &smartmontools.Temperature{Current: 36},
+		AtaSmartData: &smartmontools.AtaSmartData{Table: []smartmontools.SmartAttribute{
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Raw: smartmontools.Raw{Value: 2, String: "2"}},
+		}},
+	}, nil
+}
+
+func (fakeClient) CheckHealth(ctx context.Context, devicePath string) (bool, error) {
+	return devicePath == "/dev/sda", nil
+}
+
+func (fakeClient) RunSelfTestWithProgress(ctx context.Context, devicePath, testType string, callback smartmontools.ProgressCallback) error {
+	go func() {
+		callback(0, "Test started", smartmontools.ProgressEstimated, "")
+		callback(50, "Self-test routine in progress", smartmontools.ProgressMeasured, "")
+		callback(100, "Completed without error", smartmontools.ProgressMeasured, smartmontools.SelfTestPassed)
+	}()
+	return nil
+}
+
+func newTestClient(t *testing.T) smartpb.SmartServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := ggrpc.NewServer()
+	Register(srv, NewServer(fakeClient{}))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := ggrpc.NewClient("passthrough:///bufnet",
+		ggrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		ggrpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return smartpb.NewSmartServiceClient(conn)
+}
+
+func TestServer_Unary(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	devices, err := client.ScanDevices(ctx, &smartpb.ScanDevicesRequest{})
+	require.NoError(t, err)
+	require.Len(t, devices.GetDevices(), 1)
+	assert.Equal(t, "sat", devices.GetDevices()[0].GetType())
+
+	info, err := client.GetSMARTInfo(ctx, &smartpb.GetSMARTInfoRequest{Device: "/dev/sda"})
+	require.NoError(t, err)
+	assert.Equal(t, "SER1", info.GetSerialNumber())
+	assert.True(t, info.GetHealthPassed())
+	assert.Equal(t, int32(36), info.GetTemperature())
+	assert.False(t, info.PowerOnHours != nil)
+	require.Len(t, info.GetAttributes(), 1)
+	assert.Equal(t, int64(2), info.GetAttributes()[0].GetRawValue())
+	assert.Contains(t, string(info.GetInfoJson()), `"serial_number":"SER1"`)
+
+	_, err = client.GetSMARTInfo(ctx, &smartpb.GetSMARTInfoRequest{Device: "/dev/sdz"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	_, err = client.GetSMARTInfo(ctx, &smartpb.GetSMARTInfoRequest{Device: "/dev/missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.GetSMARTInfo(ctx, &smartpb.GetSMARTInfoRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	health, err := client.CheckHealth(ctx, &smartpb.CheckHealthRequest{Device: "/dev/sda"})
+	require.NoError(t, err)
+	assert.True(t, health.GetPassed())
+}
+
+func TestServer_RunSelfTest(t *testing.T) {
+	client := newTestClient(t)
+	stream, err := client.RunSelfTest(context.Background(), &smartpb.RunSelfTestRequest{Device: "/dev/sda"})
+	require.NoError(t, err)
+
+	var progress []int32
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		progress = append(progress, msg.GetProgress())
+		if msg.GetDone() {
+			assert.Equal(t, "Completed without error", msg.GetStatus())
+		}
+	}
+	assert.Equal(t, []int32{0, 50, 100}, progress)
+
+	stream, err = client.RunSelfTest(context.Background(), &smartpb.RunSelfTestRequest{Device: "/dev/sda", TestType: "quick"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,922 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: smart.proto
+
+package smartpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ScanDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanDevicesRequest) Reset() {
+	*x = ScanDevicesRequest{}
+	mi := &file_smart_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanDevicesRequest) ProtoMessage() {}
+
+func (x *ScanDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ScanDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{0}
+}
+
+type Device struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_smart_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Device) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Device) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type ScanDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*Device              `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanDevicesResponse) Reset() {
+	*x = ScanDevicesResponse{}
+	mi := &file_smart_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanDevicesResponse) ProtoMessage() {}
+
+func (x *ScanDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ScanDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScanDevicesResponse) GetDevices() []*Device {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type GetSMARTInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Device        string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSMARTInfoRequest) Reset() {
+	*x = GetSMARTInfoRequest{}
+	mi := &file_smart_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSMARTInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSMARTInfoRequest) ProtoMessage() {}
+
+func (x *GetSMARTInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSMARTInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetSMARTInfoRequest) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetSMARTInfoRequest) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+type Attribute struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Value         int32                  `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	Worst         int32                  `protobuf:"varint,4,opt,name=worst,proto3" json:"worst,omitempty"`
+	Thresh        int32                  `protobuf:"varint,5,opt,name=thresh,proto3" json:"thresh,omitempty"`
+	RawValue      int64                  `protobuf:"varint,6,opt,name=raw_value,json=rawValue,proto3" json:"raw_value,omitempty"`
+	RawString     string                 `protobuf:"bytes,7,opt,name=raw_string,json=rawString,proto3" json:"raw_string,omitempty"`
+	WhenFailed    string                 `protobuf:"bytes,8,opt,name=when_failed,json=whenFailed,proto3" json:"when_failed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attribute) Reset() {
+	*x = Attribute{}
+	mi := &file_smart_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attribute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attribute) ProtoMessage() {}
+
+func (x *Attribute) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attribute.ProtoReflect.Descriptor instead.
+func (*Attribute) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Attribute) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Attribute) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Attribute) GetValue() int32 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Attribute) GetWorst() int32 {
+	if x != nil {
+		return x.Worst
+	}
+	return 0
+}
+
+func (x *Attribute) GetThresh() int32 {
+	if x != nil {
+		return x.Thresh
+	}
+	return 0
+}
+
+func (x *Attribute) GetRawValue() int64 {
+	if x != nil {
+		return x.RawValue
+	}
+	return 0
+}
+
+func (x *Attribute) GetRawString() string {
+	if x != nil {
+		return x.RawString
+	}
+	return ""
+}
+
+func (x *Attribute) GetWhenFailed() string {
+	if x != nil {
+		return x.WhenFailed
+	}
+	return ""
+}
+
+type NvmeHealth struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	CriticalWarning         int32                  `protobuf:"varint,1,opt,name=critical_warning,json=criticalWarning,proto3" json:"critical_warning,omitempty"`
+	Temperature             int32                  `protobuf:"varint,2,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	AvailableSpare          int32                  `protobuf:"varint,3,opt,name=available_spare,json=availableSpare,proto3" json:"available_spare,omitempty"`
+	AvailableSpareThreshold int32                  `protobuf:"varint,4,opt,name=available_spare_threshold,json=availableSpareThreshold,proto3" json:"available_spare_threshold,omitempty"`
+	PercentageUsed          int32                  `protobuf:"varint,5,opt,name=percentage_used,json=percentageUsed,proto3" json:"percentage_used,omitempty"`
+	DataUnitsRead           int64                  `protobuf:"varint,6,opt,name=data_units_read,json=dataUnitsRead,proto3" json:"data_units_read,omitempty"`
+	DataUnitsWritten        int64                  `protobuf:"varint,7,opt,name=data_units_written,json=dataUnitsWritten,proto3" json:"data_units_written,omitempty"`
+	PowerCycles             int64                  `protobuf:"varint,8,opt,name=power_cycles,json=powerCycles,proto3" json:"power_cycles,omitempty"`
+	PowerOnHours            int64                  `protobuf:"varint,9,opt,name=power_on_hours,json=powerOnHours,proto3" json:"power_on_hours,omitempty"`
+	UnsafeShutdowns         int64                  `protobuf:"varint,10,opt,name=unsafe_shutdowns,json=unsafeShutdowns,proto3" json:"unsafe_shutdowns,omitempty"`
+	MediaErrors             int64                  `protobuf:"varint,11,opt,name=media_errors,json=mediaErrors,proto3" json:"media_errors,omitempty"`
+	NumErrLogEntries        int64                  `protobuf:"varint,12,opt,name=num_err_log_entries,json=numErrLogEntries,proto3" json:"num_err_log_entries,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *NvmeHealth) Reset() {
+	*x = NvmeHealth{}
+	mi := &file_smart_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NvmeHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NvmeHealth) ProtoMessage() {}
+
+func (x *NvmeHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NvmeHealth.ProtoReflect.Descriptor instead.
+func (*NvmeHealth) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NvmeHealth) GetCriticalWarning() int32 {
+	if x != nil {
+		return x.CriticalWarning
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetTemperature() int32 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetAvailableSpare() int32 {
+	if x != nil {
+		return x.AvailableSpare
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetAvailableSpareThreshold() int32 {
+	if x != nil {
+		return x.AvailableSpareThreshold
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetPercentageUsed() int32 {
+	if x != nil {
+		return x.PercentageUsed
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetDataUnitsRead() int64 {
+	if x != nil {
+		return x.DataUnitsRead
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetDataUnitsWritten() int64 {
+	if x != nil {
+		return x.DataUnitsWritten
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetPowerCycles() int64 {
+	if x != nil {
+		return x.PowerCycles
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetPowerOnHours() int64 {
+	if x != nil {
+		return x.PowerOnHours
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetUnsafeShutdowns() int64 {
+	if x != nil {
+		return x.UnsafeShutdowns
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetMediaErrors() int64 {
+	if x != nil {
+		return x.MediaErrors
+	}
+	return 0
+}
+
+func (x *NvmeHealth) GetNumErrLogEntries() int64 {
+	if x != nil {
+		return x.NumErrLogEntries
+	}
+	return 0
+}
+
+// SMARTInfo summarizes the fields most collectors need. info_json carries the
+// complete smartctl-compatible JSON document for everything else.
+type SMARTInfo struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Device          string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	ModelName       string                 `protobuf:"bytes,2,opt,name=model_name,json=modelName,proto3" json:"model_name,omitempty"`
+	SerialNumber    string                 `protobuf:"bytes,3,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	Firmware        string                 `protobuf:"bytes,4,opt,name=firmware,proto3" json:"firmware,omitempty"`
+	DiskType        string                 `protobuf:"bytes,5,opt,name=disk_type,json=diskType,proto3" json:"disk_type,omitempty"`
+	InStandby       bool                   `protobuf:"varint,6,opt,name=in_standby,json=inStandby,proto3" json:"in_standby,omitempty"`
+	HealthPassed    *bool                  `protobuf:"varint,7,opt,name=health_passed,json=healthPassed,proto3,oneof" json:"health_passed,omitempty"`
+	Temperature     *int32                 `protobuf:"varint,8,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
+	PowerOnHours    *int64                 `protobuf:"varint,9,opt,name=power_on_hours,json=powerOnHours,proto3,oneof" json:"power_on_hours,omitempty"`
+	PowerCycleCount int64                  `protobuf:"varint,10,opt,name=power_cycle_count,json=powerCycleCount,proto3" json:"power_cycle_count,omitempty"`
+	Attributes      []*Attribute           `protobuf:"bytes,11,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	Nvme            *NvmeHealth            `protobuf:"bytes,12,opt,name=nvme,proto3" json:"nvme,omitempty"`
+	InfoJson        []byte                 `protobuf:"bytes,13,opt,name=info_json,json=infoJson,proto3" json:"info_json,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SMARTInfo) Reset() {
+	*x = SMARTInfo{}
+	mi := &file_smart_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SMARTInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SMARTInfo) ProtoMessage() {}
+
+func (x *SMARTInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SMARTInfo.ProtoReflect.Descriptor instead.
+func (*SMARTInfo) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SMARTInfo) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *SMARTInfo) GetModelName() string {
+	if x != nil {
+		return x.ModelName
+	}
+	return ""
+}
+
+func (x *SMARTInfo) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *SMARTInfo) GetFirmware() string {
+	if x != nil {
+		return x.Firmware
+	}
+	return ""
+}
+
+func (x *SMARTInfo) GetDiskType() string {
+	if x != nil {
+		return x.DiskType
+	}
+	return ""
+}
+
+func (x *SMARTInfo) GetInStandby() bool {
+	if x != nil {
+		return x.InStandby
+	}
+	return false
+}
+
+func (x *SMARTInfo) GetHealthPassed() bool {
+	if x != nil && x.HealthPassed != nil {
+		return *x.HealthPassed
+	}
+	return false
+}
+
+func (x *SMARTInfo) GetTemperature() int32 {
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
+	}
+	return 0
+}
+
+func (x *SMARTInfo) GetPowerOnHours() int64 {
+	if x != nil && x.PowerOnHours != nil {
+		return *x.PowerOnHours
+	}
+	return 0
+}
+
+func (x *SMARTInfo) GetPowerCycleCount() int64 {
+	if x != nil {
+		return x.PowerCycleCount
+	}
+	return 0
+}
+
+func (x *SMARTInfo) GetAttributes() []*Attribute {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *SMARTInfo) GetNvme() *NvmeHealth {
+	if x != nil {
+		return x.Nvme
+	}
+	return nil
+}
+
+func (x *SMARTInfo) GetInfoJson() []byte {
+	if x != nil {
+		return x.InfoJson
+	}
+	return nil
+}
+
+type CheckHealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Device        string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckHealthRequest) Reset() {
+	*x = CheckHealthRequest{}
+	mi := &file_smart_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckHealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckHealthRequest) ProtoMessage() {}
+
+func (x *CheckHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckHealthRequest.ProtoReflect.Descriptor instead.
+func (*CheckHealthRequest) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CheckHealthRequest) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+type CheckHealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Passed        bool                   `protobuf:"varint,1,opt,name=passed,proto3" json:"passed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckHealthResponse) Reset() {
+	*x = CheckHealthResponse{}
+	mi := &file_smart_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckHealthResponse) ProtoMessage() {}
+
+func (x *CheckHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckHealthResponse.ProtoReflect.Descriptor instead.
+func (*CheckHealthResponse) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CheckHealthResponse) GetPassed() bool {
+	if x != nil {
+		return x.Passed
+	}
+	return false
+}
+
+type RunSelfTestRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Device string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	// test_type is one of short, long, conveyance or offline.
+	TestType      string `protobuf:"bytes,2,opt,name=test_type,json=testType,proto3" json:"test_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunSelfTestRequest) Reset() {
+	*x = RunSelfTestRequest{}
+	mi := &file_smart_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunSelfTestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunSelfTestRequest) ProtoMessage() {}
+
+func (x *RunSelfTestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunSelfTestRequest.ProtoReflect.Descriptor instead.
+func (*RunSelfTestRequest) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RunSelfTestRequest) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *RunSelfTestRequest) GetTestType() string {
+	if x != nil {
+		return x.TestType
+	}
+	return ""
+}
+
+type SelfTestProgress struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Progress int32                  `protobuf:"varint,1,opt,name=progress,proto3" json:"progress,omitempty"`
+	Status   string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// done is set on the last message of the stream.
+	Done          bool `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelfTestProgress) Reset() {
+	*x = SelfTestProgress{}
+	mi := &file_smart_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelfTestProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfTestProgress) ProtoMessage() {}
+
+func (x *SelfTestProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_smart_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfTestProgress.ProtoReflect.Descriptor instead.
+func (*SelfTestProgress) Descriptor() ([]byte, []int) {
+	return file_smart_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SelfTestProgress) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *SelfTestProgress) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SelfTestProgress) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+var File_smart_proto protoreflect.FileDescriptor
+
+const file_smart_proto_rawDesc = "" +
+	"\n" +
+	"\vsmart.proto\x12\x10smartmontools.v1\"\x14\n" +
+	"\x12ScanDevicesRequest\"0\n" +
+	"\x06Device\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\"I\n" +
+	"\x13ScanDevicesResponse\x122\n" +
+	"\adevices\x18\x01 \x03(\v2\x18.smartmontools.v1.DeviceR\adevices\"-\n" +
+	"\x13GetSMARTInfoRequest\x12\x16\n" +
+	"\x06device\x18\x01 \x01(\tR\x06device\"\xd0\x01\n" +
+	"\tAttribute\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x05R\x05value\x12\x14\n" +
+	"\x05worst\x18\x04 \x01(\x05R\x05worst\x12\x16\n" +
+	"\x06thresh\x18\x05 \x01(\x05R\x06thresh\x12\x1b\n" +
+	"\traw_value\x18\x06 \x01(\x03R\brawValue\x12\x1d\n" +
+	"\n" +
+	"raw_string\x18\a \x01(\tR\trawString\x12\x1f\n" +
+	"\vwhen_failed\x18\b \x01(\tR\n" +
+	"whenFailed\"\x83\x04\n" +
+	"\n" +
+	"NvmeHealth\x12)\n" +
+	"\x10critical_warning\x18\x01 \x01(\x05R\x0fcriticalWarning\x12 \n" +
+	"\vtemperature\x18\x02 \x01(\x05R\vtemperature\x12'\n" +
+	"\x0favailable_spare\x18\x03 \x01(\x05R\x0eavailableSpare\x12:\n" +
+	"\x19available_spare_threshold\x18\x04 \x01(\x05R\x17availableSpareThreshold\x12'\n" +
+	"\x0fpercentage_used\x18\x05 \x01(\x05R\x0epercentageUsed\x12&\n" +
+	"\x0fdata_units_read\x18\x06 \x01(\x03R\rdataUnitsRead\x12,\n" +
+	"\x12data_units_written\x18\a \x01(\x03R\x10dataUnitsWritten\x12!\n" +
+	"\fpower_cycles\x18\b \x01(\x03R\vpowerCycles\x12$\n" +
+	"\x0epower_on_hours\x18\t \x01(\x03R\fpowerOnHours\x12)\n" +
+	"\x10unsafe_shutdowns\x18\n" +
+	" \x01(\x03R\x0funsafeShutdowns\x12!\n" +
+	"\fmedia_errors\x18\v \x01(\x03R\vmediaErrors\x12-\n" +
+	"\x13num_err_log_entries\x18\f \x01(\x03R\x10numErrLogEntries\"\xa8\x04\n" +
+	"\tSMARTInfo\x12\x16\n" +
+	"\x06device\x18\x01 \x01(\tR\x06device\x12\x1d\n" +
+	"\n" +
+	"model_name\x18\x02 \x01(\tR\tmodelName\x12#\n" +
+	"\rserial_number\x18\x03 \x01(\tR\fserialNumber\x12\x1a\n" +
+	"\bfirmware\x18\x04 \x01(\tR\bfirmware\x12\x1b\n" +
+	"\tdisk_type\x18\x05 \x01(\tR\bdiskType\x12\x1d\n" +
+	"\n" +
+	"in_standby\x18\x06 \x01(\bR\tinStandby\x12(\n" +
+	"\rhealth_passed\x18\a \x01(\bH\x00R\fhealthPassed\x88\x01\x01\x12%\n" +
+	"\vtemperature\x18\b \x01(\x05H\x01R\vtemperature\x88\x01\x01\x12)\n" +
+	"\x0epower_on_hours\x18\t \x01(\x03H\x02R\fpowerOnHours\x88\x01\x01\x12*\n" +
+	"\x11power_cycle_count\x18\n" +
+	" \x01(\x03R\x0fpowerCycleCount\x12;\n" +
+	"\n" +
+	"attributes\x18\v \x03(\v2\x1b.smartmontools.v1.AttributeR\n" +
+	"attributes\x120\n" +
+	"\x04nvme\x18\f \x01(\v2\x1c.smartmontools.v1.NvmeHealthR\x04nvme\x12\x1b\n" +
+	"\tinfo_json\x18\r \x01(\fR\binfoJsonB\x10\n" +
+	"\x0e_health_passedB\x0e\n" +
+	"\f_temperatureB\x11\n" +
+	"\x0f_power_on_hours\",\n" +
+	"\x12CheckHealthRequest\x12\x16\n" +
+	"\x06device\x18\x01 \x01(\tR\x06device\"-\n" +
+	"\x13CheckHealthResponse\x12\x16\n" +
+	"\x06passed\x18\x01 \x01(\bR\x06passed\"I\n" +
+	"\x12RunSelfTestRequest\x12\x16\n" +
+	"\x06device\x18\x01 \x01(\tR\x06device\x12\x1b\n" +
+	"\ttest_type\x18\x02 \x01(\tR\btestType\"Z\n" +
+	"\x10SelfTestProgress\x12\x1a\n" +
+	"\bprogress\x18\x01 \x01(\x05R\bprogress\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x12\n" +
+	"\x04done\x18\x03 \x01(\bR\x04done2\xf5\x02\n" +
+	"\fSmartService\x12Z\n" +
+	"\vScanDevices\x12$.smartmontools.v1.ScanDevicesRequest\x1a%.smartmontools.v1.ScanDevicesResponse\x12R\n" +
+	"\fGetSMARTInfo\x12%.smartmontools.v1.GetSMARTInfoRequest\x1a\x1b.smartmontools.v1.SMARTInfo\x12Z\n" +
+	"\vCheckHealth\x12$.smartmontools.v1.CheckHealthRequest\x1a%.smartmontools.v1.CheckHealthResponse\x12Y\n" +
+	"\vRunSelfTest\x12$.smartmontools.v1.RunSelfTestRequest\x1a\".smartmontools.v1.SelfTestProgress0\x01B<Z:github.com/dianlight/smartmontools-go/grpc/smartpb;smartpbb\x06proto3"
+
+var (
+	file_smart_proto_rawDescOnce sync.Once
+	file_smart_proto_rawDescData []byte
+)
+
+func file_smart_proto_rawDescGZIP() []byte {
+	file_smart_proto_rawDescOnce.Do(func() {
+		file_smart_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_smart_proto_rawDesc), len(file_smart_proto_rawDesc)))
+	})
+	return file_smart_proto_rawDescData
+}
+
+var file_smart_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_smart_proto_goTypes = []any{
+	(*ScanDevicesRequest)(nil),  // 0: smartmontools.v1.ScanDevicesRequest
+	(*Device)(nil),              // 1: smartmontools.v1.Device
+	(*ScanDevicesResponse)(nil), // 2: smartmontools.v1.ScanDevicesResponse
+	(*GetSMARTInfoRequest)(nil), // 3: smartmontools.v1.GetSMARTInfoRequest
+	(*Attribute)(nil),           // 4: smartmontools.v1.Attribute
+	(*NvmeHealth)(nil),          // 5: smartmontools.v1.NvmeHealth
+	(*SMARTInfo)(nil),           // 6: smartmontools.v1.SMARTInfo
+	(*CheckHealthRequest)(nil),  // 7: smartmontools.v1.CheckHealthRequest
+	(*CheckHealthResponse)(nil), // 8: smartmontools.v1.CheckHealthResponse
+	(*RunSelfTestRequest)(nil),  // 9: smartmontools.v1.RunSelfTestRequest
+	(*SelfTestProgress)(nil),    // 10: smartmontools.v1.SelfTestProgress
+}
+var file_smart_proto_depIdxs = []int32{
+	1,  // 0: smartmontools.v1.ScanDevicesResponse.devices:type_name -> smartmontools.v1.Device
+	4,  // 1: smartmontools.v1.SMARTInfo.attributes:type_name -> smartmontools.v1.Attribute
+	5,  // 2: smartmontools.v1.SMARTInfo.nvme:type_name -> smartmontools.v1.NvmeHealth
+	0,  // 3: smartmontools.v1.SmartService.ScanDevices:input_type -> smartmontools.v1.ScanDevicesRequest
+	3,  // 4: smartmontools.v1.SmartService.GetSMARTInfo:input_type -> smartmontools.v1.GetSMARTInfoRequest
+	7,  // 5: smartmontools.v1.SmartService.CheckHealth:input_type -> smartmontools.v1.CheckHealthRequest
+	9,  // 6: smartmontools.v1.SmartService.RunSelfTest:input_type -> smartmontools.v1.RunSelfTestRequest
+	2,  // 7: smartmontools.v1.SmartService.ScanDevices:output_type -> smartmontools.v1.ScanDevicesResponse
+	6,  // 8: smartmontools.v1.SmartService.GetSMARTInfo:output_type -> smartmontools.v1.SMARTInfo
+	8,  // 9: smartmontools.v1.SmartService.CheckHealth:output_type -> smartmontools.v1.CheckHealthResponse
+	10, // 10: smartmontools.v1.SmartService.RunSelfTest:output_type -> smartmontools.v1.SelfTestProgress
+	7,  // [7:11] is the sub-list for method output_type
+	3,  // [3:7] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_smart_proto_init() }
+func file_smart_proto_init() {
+	if File_smart_proto != nil {
+		return
+	}
+	file_smart_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_smart_proto_rawDesc), len(file_smart_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_smart_proto_goTypes,
+		DependencyIndexes: file_smart_proto_depIdxs,
+		MessageInfos:      file_smart_proto_msgTypes,
+	}.Build()
+	File_smart_proto = out.File
+	file_smart_proto_goTypes = nil
+	file_smart_proto_depIdxs = nil
+}
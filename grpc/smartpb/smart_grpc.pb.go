@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: smart.proto
+
+package smartpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SmartService_ScanDevices_FullMethodName  = "/smartmontools.v1.SmartService/ScanDevices"
+	SmartService_GetSMARTInfo_FullMethodName = "/smartmontools.v1.SmartService/GetSMARTInfo"
+	SmartService_CheckHealth_FullMethodName  = "/smartmontools.v1.SmartService/CheckHealth"
+	SmartService_RunSelfTest_FullMethodName  = "/smartmontools.v1.SmartService/RunSelfTest"
+)
+
+// SmartServiceClient is the client API for SmartService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SmartService exposes a smartmontools-go SmartClient to remote collectors.
+type SmartServiceClient interface {
+	// ScanDevices lists the devices visible to smartctl.
+	ScanDevices(ctx context.Context, in *ScanDevicesRequest, opts ...grpc.CallOption) (*ScanDevicesResponse, error)
+	// GetSMARTInfo returns the SMART information of a device.
+	GetSMARTInfo(ctx context.Context, in *GetSMARTInfoRequest, opts ...grpc.CallOption) (*SMARTInfo, error)
+	// CheckHealth returns the SMART overall-health self-assessment of a device.
+	CheckHealth(ctx context.Context, in *CheckHealthRequest, opts ...grpc.CallOption) (*CheckHealthResponse, error)
+	// RunSelfTest starts a self-test and streams its progress until it
+	// completes or the call is cancelled.
+	RunSelfTest(ctx context.Context, in *RunSelfTestRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SelfTestProgress], error)
+}
+
+type smartServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSmartServiceClient(cc grpc.ClientConnInterface) SmartServiceClient {
+	return &smartServiceClient{cc}
+}
+
+func (c *smartServiceClient) ScanDevices(ctx context.Context, in *ScanDevicesRequest, opts ...grpc.CallOption) (*ScanDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScanDevicesResponse)
+	err := c.cc.Invoke(ctx, SmartService_ScanDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *smartServiceClient) GetSMARTInfo(ctx context.Context, in *GetSMARTInfoRequest, opts ...grpc.CallOption) (*SMARTInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SMARTInfo)
+	err := c.cc.Invoke(ctx, SmartService_GetSMARTInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *smartServiceClient) CheckHealth(ctx context.Context, in *CheckHealthRequest, opts ...grpc.CallOption) (*CheckHealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckHealthResponse)
+	err := c.cc.Invoke(ctx, SmartService_CheckHealth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *smartServiceClient) RunSelfTest(ctx context.Context, in *RunSelfTestRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SelfTestProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SmartService_ServiceDesc.Streams[0], SmartService_RunSelfTest_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RunSelfTestRequest, SelfTestProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SmartService_RunSelfTestClient = grpc.ServerStreamingClient[SelfTestProgress]
+
+// SmartServiceServer is the server API for SmartService service.
+// All implementations must embed UnimplementedSmartServiceServer
+// for forward compatibility.
+//
+// SmartService exposes a smartmontools-go SmartClient to remote collectors.
+type SmartServiceServer interface {
+	// ScanDevices lists the devices visible to smartctl.
+	ScanDevices(context.Context, *ScanDevicesRequest) (*ScanDevicesResponse, error)
+	// GetSMARTInfo returns the SMART information of a device.
+	GetSMARTInfo(context.Context, *GetSMARTInfoRequest) (*SMARTInfo, error)
+	// CheckHealth returns the SMART overall-health self-assessment of a device.
+	CheckHealth(context.Context, *CheckHealthRequest) (*CheckHealthResponse, error)
+	// RunSelfTest starts a self-test and streams its progress until it
+	// completes or the call is cancelled.
+	RunSelfTest(*RunSelfTestRequest, grpc.ServerStreamingServer[SelfTestProgress]) error
+	mustEmbedUnimplementedSmartServiceServer()
+}
+
+// UnimplementedSmartServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSmartServiceServer struct{}
+
+func (UnimplementedSmartServiceServer) ScanDevices(context.Context, *ScanDevicesRequest) (*ScanDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScanDevices not implemented")
+}
+func (UnimplementedSmartServiceServer) GetSMARTInfo(context.Context, *GetSMARTInfoRequest) (*SMARTInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSMARTInfo not implemented")
+}
+func (UnimplementedSmartServiceServer) CheckHealth(context.Context, *CheckHealthRequest) (*CheckHealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckHealth not implemented")
+}
+func (UnimplementedSmartServiceServer) RunSelfTest(*RunSelfTestRequest, grpc.ServerStreamingServer[SelfTestProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method RunSelfTest not implemented")
+}
+func (UnimplementedSmartServiceServer) mustEmbedUnimplementedSmartServiceServer() {}
+func (UnimplementedSmartServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeSmartServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SmartServiceServer will
+// result in compilation errors.
+type UnsafeSmartServiceServer interface {
+	mustEmbedUnimplementedSmartServiceServer()
+}
+
+func RegisterSmartServiceServer(s grpc.ServiceRegistrar, srv SmartServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSmartServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SmartService_ServiceDesc, srv)
+}
+
+func _SmartService_ScanDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SmartServiceServer).ScanDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SmartService_ScanDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SmartServiceServer).ScanDevices(ctx, req.(*ScanDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SmartService_GetSMARTInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSMARTInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SmartServiceServer).GetSMARTInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SmartService_GetSMARTInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SmartServiceServer).GetSMARTInfo(ctx, req.(*GetSMARTInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SmartService_CheckHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SmartServiceServer).CheckHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SmartService_CheckHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SmartServiceServer).CheckHealth(ctx, req.(*CheckHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SmartService_RunSelfTest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RunSelfTestRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SmartServiceServer).RunSelfTest(m, &grpc.GenericServerStream[RunSelfTestRequest, SelfTestProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SmartService_RunSelfTestServer = grpc.ServerStreamingServer[SelfTestProgress]
+
+// SmartService_ServiceDesc is the grpc.ServiceDesc for SmartService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SmartService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "smartmontools.v1.SmartService",
+	HandlerType: (*SmartServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ScanDevices",
+			Handler:    _SmartService_ScanDevices_Handler,
+		},
+		{
+			MethodName: "GetSMARTInfo",
+			Handler:    _SmartService_GetSMARTInfo_Handler,
+		},
+		{
+			MethodName: "CheckHealth",
+			Handler:    _SmartService_CheckHealth_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RunSelfTest",
+			Handler:       _SmartService_RunSelfTest_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "smart.proto",
+}
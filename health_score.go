@@ -0,0 +1,163 @@
+package smartmontools
+
+import (
+	"fmt"
+)
+
+// Score is a 0–100 drive health estimate together with the factors that
+// lowered it. 100 means no known problem; 0 means the drive should be
+// replaced immediately.
+type Score struct {
+	Value int `json:"value"`
+	// Evaluated is false when info carried no SMART data to score (nil info,
+	// a drive in standby, or a status synthesized without attributes or a
+	// reported verdict); Value is then 100.
+	Evaluated bool          `json:"evaluated"`
+	Factors   []ScoreFactor `json:"factors,omitempty"`
+}
+
+// ScoreFactor describes a single penalty applied by HealthScore.
+type ScoreFactor struct {
+	// Source identifies the input, e.g. "smart_status", "attribute_197" or
+	// "nvme_percentage_used".
+	Source  string `json:"source"`
+	Penalty int    `json:"penalty"`
+	Detail  string `json:"detail"`
+}
+
+// criticalAttributePenalty grades a non-zero critical attribute: any non-zero
+// raw count costs base points, growing linearly up to max at saturation.
+type criticalAttributePenalty struct {
+	id         int
+	base, max  int
+	saturation int64
+}
+
+// criticalAttributePenalties lists the ATA attributes most strongly linked to
+// drive failure. Command timeouts weigh less because cabling also causes them.
+var criticalAttributePenalties = []criticalAttributePenalty{
+	{id: SmartAttrReallocatedSectors, base: 10, max: 30, saturation: 100},
+	{id: SmartAttrReportedUncorrect, base: 10, max: 25, saturation: 50},
+	{id: SmartAttrCommandTimeout, base: 5, max: 15, saturation: 50},
+	{id: SmartAttrCurrentPendingSector, base: 15, max: 30, saturation: 50},
+	{id: SmartAttrOfflineUncorrectable, base: 15, max: 30, saturation: 50},
+}
+
+// Penalties applied by HealthScore for status and NVMe conditions.
+const (
+	penaltySmartFailing        = 70
+	penaltyPrefailThreshold    = 30
+	penaltyNvmeCriticalWarning = 40
+	penaltyNvmeSpareLow        = 25
+	penaltyNvmeMediaErrors     = 20
+	penaltyNvmeWornOut         = 30
+	penaltyNvmeWearHigh        = 15
+	penaltyNvmeWearElevated    = 5
+	penaltyErrorLogEntries     = 5
+	penaltyErrorLogGrowth      = 10
+)
+
+// HealthScore computes a 0–100 health score for a single SMART snapshot. It
+// combines the overall SMART status and smartctl health bits, the critical
+// ATA attributes 5/187/188/197/198, NVMe critical warnings, spare, wear
+// (percentage_used) and media errors, and the presence of ATA error log
+// entries. Use HealthScoreSince to also penalize error log growth between polls.
+func HealthScore(info *SMARTInfo) Score {
+	return HealthScoreSince(nil, info)
+}
+
+// HealthScoreSince is like HealthScore but, when previous is non-nil, adds a
+// penalty if the device error log grew since the previous snapshot.
+func HealthScoreSince(previous, info *SMARTInfo) Score {
+	score := Score{Value: 100}
+	if !hasHealthData(info) {
+		return score
+	}
+	score.Evaluated = true
+	add := func(source string, penalty int, format string, args ...any) {
+		score.Factors = append(score.Factors, ScoreFactor{Source: source, Penalty: penalty, Detail: fmt.Sprintf(format, args...)})
+		score.Value -= penalty
+	}
+
+	healthBits := 0
+	if info.ExitCodeInfo != nil {
+		healthBits = info.ExitCodeInfo.HealthBits
+	}
+	switch {
+	case healthBits&0x08 != 0 || (info.SmartStatus != nil && info.SmartStatus.Damaged):
+		add("smart_status", penaltySmartFailing, "SMART overall-health self-assessment reports the disk is failing")
+	case healthBits&0x10 != 0 || (info.SmartStatus != nil && info.SmartStatus.Critical):
+		add("smart_status", penaltyPrefailThreshold, "pre-failure attributes are at or below their threshold")
+	}
+
+	for _, p := range criticalAttributePenalties {
+		attr := info.AtaSmartData.GetAttributeByID(p.id)
+		if attr == nil || attr.Raw.Count() <= 0 {
+			continue
+		}
+		count := attr.Raw.Count()
+		penalty := p.base + int(int64(p.max-p.base)*min(count, p.saturation)/p.saturation)
+		name := fmt.Sprintf("attribute %d", p.id)
+		if d, ok := DescribeAttribute(p.id); ok {
+			name = d.Name
+		}
+		add(fmt.Sprintf("attribute_%d", p.id), penalty, "%s raw value is %d", name, count)
+	}
+
+	if nvme := info.NvmeSmartHealth; nvme != nil {
+		if nvme.CriticalWarning != 0 {
+			add("nvme_critical_warning", penaltyNvmeCriticalWarning, "NVMe critical warning flags 0x%02x are set", nvme.CriticalWarning)
+		}
+		if nvme.AvailableSpareThresh > 0 && nvme.AvailableSpare < nvme.AvailableSpareThresh {
+			add("nvme_available_spare", penaltyNvmeSpareLow, "available spare %d%% is below threshold %d%%", nvme.AvailableSpare, nvme.AvailableSpareThresh)
+		}
+		if nvme.MediaErrors > 0 {
+			add("nvme_media_errors", penaltyNvmeMediaErrors, "%d media and data integrity errors recorded", nvme.MediaErrors)
+		}
+		switch {
+		case nvme.PercentageUsed >= 100:
+			add("nvme_percentage_used", penaltyNvmeWornOut, "rated endurance exhausted (%d%% used)", nvme.PercentageUsed)
+		case nvme.PercentageUsed >= 90:
+			add("nvme_percentage_used", penaltyNvmeWearHigh, "%d%% of rated endurance used", nvme.PercentageUsed)
+		case nvme.PercentageUsed >= 80:
+			add("nvme_percentage_used", penaltyNvmeWearElevated, "%d%% of rated endurance used", nvme.PercentageUsed)
+		}
+	}
+
+	if count, ok := info.AtaSmartErrorLog.ErrorCount(); ok && count > 0 {
+		add("error_log", penaltyErrorLogEntries, "device error log contains %d entries", count)
+	}
+	if previous != nil {
+		if grown := errorLogCount(info) - errorLogCount(previous); grown > 0 {
+			add("error_log_growth", penaltyErrorLogGrowth, "%d new error log entries since the previous snapshot", grown)
+		}
+	}
+
+	score.Value = max(0, min(100, score.Value))
+	return score
+}
+
+// hasHealthData reports whether info carries SMART data to score: attributes,
+// an NVMe health log, a reported overall-health verdict or health bits in the
+// exit status. A drive in standby only reports placeholders.
+func hasHealthData(info *SMARTInfo) bool {
+	if info == nil || info.InStandby {
+		return false
+	}
+	if _, ok := info.HealthVerdict(); ok {
+		return true
+	}
+	return info.AtaSmartData != nil || info.NvmeSmartHealth != nil ||
+		(info.ExitCodeInfo != nil && info.ExitCodeInfo.HealthBits != 0)
+}
+
+// errorLogCount returns the device error log size for ATA or NVMe devices.
+func errorLogCount(info *SMARTInfo) int64 {
+	if count, ok := info.AtaSmartErrorLog.ErrorCount(); ok {
+		return int64(count)
+	}
+	if info.NvmeSmartHealth != nil {
+		return info.NvmeSmartHealth.NumErrLogEntries
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package smartmontools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scoreSources(s Score) []string {
+	sources := make([]string, 0, len(s.Factors))
+	for _, f := range s.Factors {
+		sources = append(sources, f.Source)
+	}
+	return sources
+}
+
+func TestHealthScore_NoData(t *testing.T) {
+	s := HealthScore(nil)
+	assert.False(t, s.Evaluated)
+	assert.Equal(t, 100, s.Value)
+
+	s = HealthScore(&SMARTInfo{Device: Device{Name: "/dev/sda"}})
+	assert.False(t, s.Evaluated)
+	assert.Equal(t, 100, s.Value)
+
+	s = HealthScore(&SMARTInfo{InStandby: true, SmartStatus: &SmartStatus{Synthesized: true}})
+	assert.False(t, s.Evaluated, "a standby placeholder is not SMART data")
+
+	s = HealthScore(&SMARTInfo{SmartStatus: &SmartStatus{Synthesized: true}})
+	assert.False(t, s.Evaluated, "a synthesized status alone is not SMART data")
+
+	s = HealthScore(&SMARTInfo{SmartStatus: &SmartStatus{Passed: true}})
+	assert.True(t, s.Evaluated, "a reported verdict is scored")
+}
+
+func TestHealthScore_Healthy(t *testing.T) {
+	info := &SMARTInfo{
+		SmartStatus: &SmartStatus{Passed: true},
+		AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+			{ID: 5, Raw: Raw{Value: 0, String: "0"}},
+			{ID: 197, Raw: Raw{Value: 0, String: "0"}},
+		}},
+	}
+	s := HealthScore(info)
+	assert.True(t, s.Evaluated)
+	assert.Equal(t, 100, s.Value)
+	assert.Empty(t, s.Factors)
+}
+
+func TestHealthScore_AtaAttributes(t *testing.T) {
+	info := &SMARTInfo{
+		SmartStatus:  &SmartStatus{Passed: true},
+		AtaSmartData: newAttributeTable(),
+	}
+	s := HealthScore(info)
+	assert.True(t, s.Evaluated)
+	assert.Equal(t, []string{"attribute_5", "attribute_197"}, scoreSources(s))
+	// 5: 10 + 20*8/100 = 11; 197: 15 + 15*2/50 = 15.
+	assert.Equal(t, 100-11-15, s.Value)
+	assert.Contains(t, s.Factors[0].Detail, "Reallocated_Sector_Ct")
+}
+
+func TestHealthScore_Failing(t *testing.T) {
+	info := &SMARTInfo{
+		SmartStatus:  &SmartStatus{Passed: false, Damaged: true},
+		ExitCodeInfo: &ExitCodeInfo{HealthBits: 0x08},
+		AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+			{ID: 5, Raw: Raw{Value: 5000, String: "5000"}},
+			{ID: 197, Raw: Raw{Value: 800, String: "800"}},
+		}},
+	}
+	s := HealthScore(info)
+	assert.Equal(t, 0, s.Value, "score is clamped at zero")
+	assert.Equal(t, []string{"smart_status", "attribute_5", "attribute_197"}, scoreSources(s))
+	assert.Equal(t, 30, s.Factors[1].Penalty)
+}
+
+func TestHealthScore_Nvme(t *testing.T) {
+	info := &SMARTInfo{
+		SmartStatus: &SmartStatus{Passed: true},
+		NvmeSmartHealth: &NvmeSmartHealth{
+			CriticalWarning:      0x01,
+			AvailableSpare:       5,
+			AvailableSpareThresh: 10,
+			PercentageUsed:       92,
+			MediaErrors:          3,
+		},
+	}
+	s := HealthScore(info)
+	assert.Equal(t,
+		[]string{"nvme_critical_warning", "nvme_available_spare", "nvme_media_errors", "nvme_percentage_used"},
+		scoreSources(s))
+	assert.Equal(t, 0, s.Value)
+
+	info.NvmeSmartHealth = &NvmeSmartHealth{AvailableSpare: 100, AvailableSpareThresh: 10, PercentageUsed: 85}
+	s = HealthScore(info)
+	assert.Equal(t, 95, s.Value)
+}
+
+func TestHealthScoreSince_ErrorLogGrowth(t *testing.T) {
+	previous := &SMARTInfo{
+		SmartStatus:      &SmartStatus{Passed: true},
+		AtaSmartData:     &AtaSmartData{},
+		AtaSmartErrorLog: &AtaSmartErrorLog{Summary: &AtaErrorLogSummary{Count: 2}},
+	}
+	current := &SMARTInfo{
+		SmartStatus:      &SmartStatus{Passed: true},
+		AtaSmartData:     &AtaSmartData{},
+		AtaSmartErrorLog: &AtaSmartErrorLog{Summary: &AtaErrorLogSummary{Count: 4}},
+	}
+	assert.Equal(t, 95, HealthScore(current).Value)
+
+	s := HealthScoreSince(previous, current)
+	require.Len(t, s.Factors, 2)
+	assert.Equal(t, "error_log_growth", s.Factors[1].Source)
+	assert.Equal(t, 85, s.Value)
+
+	nvmePrev := &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{AvailableSpare: 100, NumErrLogEntries: 10}}
+	nvmeCur := &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{AvailableSpare: 100, NumErrLogEntries: 12}}
+	assert.Equal(t, 90, HealthScoreSince(nvmePrev, nvmeCur).Value)
+}
@@ -0,0 +1,161 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltStore is a Store backed by a bbolt database file. Each device serial
+// has its own bucket, keyed by the big-endian UnixNano snapshot timestamp, so
+// range queries are cursor seeks. Snapshots are stored with
+// smartmontools.MarshalSnapshot, which wraps smartctl-compatible JSON with a
+// schema version; databases written before snapshots were versioned remain
+// readable.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+var boltSnapshotsBucket = []byte("snapshots")
+
+// OpenBoltStore opens (creating if needed) the bbolt database at path.
+// opts may be nil to use bbolt defaults.
+func OpenBoltStore(path string, opts *bolt.Options) (*BoltStore, error) {
+	if opts == nil {
+		opts = &bolt.Options{Timeout: time.Second}
+	}
+	db, err := bolt.Open(path, 0o600, opts)
+	if err != nil {
+		return nil, fmt.Errorf("history: open %s: %w", path, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltSnapshotsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("history: initialize %s: %w", path, err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func boltKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// Record implements Store.
+func (b *BoltStore) Record(ctx context.Context, at time.Time, info *smartmontools.SMARTInfo) error {
+	serial := recordSerial(info)
+	if serial == "" {
+		return ErrNoSerial
+	}
+	data, err := smartmontools.MarshalSnapshot(info)
+	if err != nil {
+		return fmt.Errorf("history: encode snapshot: %w", err)
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(boltSnapshotsBucket).CreateBucketIfNotExists([]byte(serial))
+		if err != nil {
+			return err
+		}
+		return bucket.Put(boltKey(at), data)
+	})
+}
+
+// Snapshots implements Store.
+func (b *BoltStore) Snapshots(ctx context.Context, serial string, q Query) ([]Snapshot, error) {
+	var out []Snapshot
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSnapshotsBucket).Bucket([]byte(serial))
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		var k, v []byte
+		if q.From.IsZero() {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek(boltKey(q.From))
+		}
+		var end []byte
+		if !q.To.IsZero() {
+			end = boltKey(q.To)
+		}
+		for ; k != nil; k, v = c.Next() {
+			if end != nil && bytes.Compare(k, end) >= 0 {
+				break
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			info, err := smartmontools.UnmarshalSnapshot(v)
+			if err != nil {
+				return fmt.Errorf("history: decode snapshot of %s: %w", serial, err)
+			}
+			at := time.Unix(0, int64(binary.BigEndian.Uint64(k)))
+			out = append(out, Snapshot{Time: at, Serial: serial, Info: info})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, nil
+}
+
+// Serials implements Store.
+func (b *BoltStore) Serials(ctx context.Context) ([]string, error) {
+	var serials []string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltSnapshotsBucket).ForEachBucket(func(k []byte) error {
+			serials = append(serials, string(k))
+			return nil
+		})
+	})
+	return serials, err
+}
+
+// Prune implements Store.
+func (b *BoltStore) Prune(ctx context.Context, before time.Time) error {
+	limit := boltKey(before)
+	return b.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(boltSnapshotsBucket)
+		var empty [][]byte
+		err := root.ForEachBucket(func(name []byte) error {
+			bucket := root.Bucket(name)
+			c := bucket.Cursor()
+			for k, _ := c.First(); k != nil && bytes.Compare(k, limit) < 0; k, _ = c.First() {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+			if k, _ := c.First(); k == nil {
+				empty = append(empty, append([]byte(nil), name...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, name := range empty {
+			if err := root.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close implements Store.
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
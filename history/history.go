@@ -0,0 +1,170 @@
+/*
+Package history records timestamped SMARTInfo snapshots per device serial and
+exposes attribute time series built from them. It is the storage foundation
+for trending and alerting on top of the smartmontools client.
+
+Two Store implementations are provided: NewMemoryStore for tests and
+short-lived processes, and OpenBoltStore for persistent storage in a single
+bbolt database file. Other backends (SQLite, a time-series database) can be
+plugged in by implementing Store.
+*/
+package history
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// ErrNoSerial is returned by Store.Record when the snapshot carries no serial
+// number to key it by.
+var ErrNoSerial = errors.New("history: SMART info has no serial number")
+
+// ErrInsufficientHistory is returned by the analysis functions when the
+// snapshots do not span enough time or lack the values to analyze.
+var ErrInsufficientHistory = errors.New("history: not enough snapshots to analyze")
+
+// recordSerial returns the serial number Store.Record keys info by, or "".
+func recordSerial(info *smartmontools.SMARTInfo) string {
+	if info == nil {
+		return ""
+	}
+	return strings.TrimSpace(info.SerialNumber)
+}
+
+// Snapshot is a SMARTInfo captured at a given time.
+type Snapshot struct {
+	Time   time.Time                `json:"time"`
+	Serial string                   `json:"serial"`
+	Info   *smartmontools.SMARTInfo `json:"info"`
+}
+
+// Query restricts the snapshots returned by Store.Snapshots. Zero From/To
+// leave that end of the range open; To is exclusive. Limit > 0 keeps only the
+// most recent Limit snapshots of the range.
+type Query struct {
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// contains reports whether t falls within the query time range.
+func (q Query) contains(t time.Time) bool {
+	if !q.From.IsZero() && t.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !t.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// Store persists SMART snapshots keyed by device serial number.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Record stores a copy of info as the snapshot of its device at time at,
+	// keyed by info.SerialNumber without surrounding spaces, as IdentityOf
+	// reports it. It returns ErrNoSerial when the serial is empty. Recording a
+	// second snapshot for the same serial and timestamp replaces the first.
+	Record(ctx context.Context, at time.Time, info *smartmontools.SMARTInfo) error
+	// Snapshots returns the snapshots of serial matching q, oldest first.
+	// Callers own the returned SMARTInfo values.
+	Snapshots(ctx context.Context, serial string, q Query) ([]Snapshot, error)
+	// Serials returns the serial numbers with at least one snapshot, sorted.
+	Serials(ctx context.Context) ([]string, error)
+	// Prune deletes all snapshots taken before the given time.
+	Prune(ctx context.Context, before time.Time) error
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// SnapshotsOf returns the snapshots of the drive identified by id matching q,
+// oldest first. Stores key snapshots by serial number, which already survives
+// device renames; SnapshotsOf additionally drops snapshots whose WWN shows they
+// belong to another drive with the same serial, as reported by some USB
+// bridges.
+func SnapshotsOf(ctx context.Context, store Store, id smartmontools.DeviceIdentity, q Query) ([]Snapshot, error) {
+	snapshots, err := store.Snapshots(ctx, id.Serial, q)
+	if err != nil {
+		return nil, err
+	}
+	var matching []Snapshot
+	for _, s := range snapshots {
+		if id.Matches(smartmontools.IdentityOf(s.Info)) {
+			matching = append(matching, s)
+		}
+	}
+	return matching, nil
+}
+
+// AttributePoint is one sample of an ATA attribute time series.
+type AttributePoint struct {
+	Time       time.Time `json:"time"`
+	Normalized int       `json:"normalized"`
+	Worst      int       `json:"worst"`
+	Raw        int64     `json:"raw"`
+}
+
+// AttributeSeries returns the time series of ATA attribute id for serial.
+// Snapshots that do not report the attribute are skipped. Raw holds the
+// counter value as interpreted by smartmontools.Raw.Count.
+func AttributeSeries(ctx context.Context, store Store, serial string, id int, q Query) ([]AttributePoint, error) {
+	snapshots, err := store.Snapshots(ctx, serial, q)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]AttributePoint, 0, len(snapshots))
+	for _, s := range snapshots {
+		if s.Info == nil {
+			continue
+		}
+		attr := s.Info.AtaSmartData.GetAttributeByID(id)
+		if attr == nil {
+			continue
+		}
+		points = append(points, AttributePoint{Time: s.Time, Normalized: attr.Value, Worst: attr.Worst, Raw: attr.Raw.Count()})
+	}
+	return points, nil
+}
+
+// Point is one sample of a scalar time series.
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value int64     `json:"value"`
+}
+
+// TemperatureSeries returns the current temperature of serial over time,
+// taken from the temperature section or, for NVMe devices, the health log.
+func TemperatureSeries(ctx context.Context, store Store, serial string, q Query) ([]Point, error) {
+	return Series(ctx, store, serial, q, func(info *smartmontools.SMARTInfo) (int64, bool) {
+		switch {
+		case info.Temperature != nil:
+			return int64(info.Temperature.Current), true
+		case info.NvmeSmartHealth != nil && info.NvmeSmartHealth.Temperature != 0:
+			return int64(info.NvmeSmartHealth.Temperature), true
+		}
+		return 0, false
+	})
+}
+
+// Series returns the values extracted by value from each snapshot of serial.
+// Snapshots for which value reports false are skipped.
+func Series(ctx context.Context, store Store, serial string, q Query, value func(*smartmontools.SMARTInfo) (int64, bool)) ([]Point, error) {
+	snapshots, err := store.Snapshots(ctx, serial, q)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]Point, 0, len(snapshots))
+	for _, s := range snapshots {
+		if s.Info == nil {
+			continue
+		}
+		if v, ok := value(s.Info); ok {
+			points = append(points, Point{Time: s.Time, Value: v})
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,281 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func snapshotInfo(serial string, pending int64, temp int) *smartmontools.SMARTInfo {
+	return &smartmontools.SMARTInfo{
+		SerialNumber: serial,
+		Temperature:  &smartmontools.Temperature{Current: temp},
+		AtaSmartData: &smartmontools.AtaSmartData{Table: []smartmontools.SmartAttribute{
+			{ID: 197, Name: "Current_Pending_Sector", Value: 100, Worst: 100, Raw: smartmontools.Raw{Value: pending}},
+		}},
+	}
+}
+
+func testStores(t *testing.T) map[string]Store {
+	bolt, err := OpenBoltStore(filepath.Join(t.TempDir(), "history.db"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = bolt.Close() })
+	return map[string]Store{"memory": NewMemoryStore(), "bolt": bolt}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, store.Record(ctx, base, &smartmontools.SMARTInfo{}), ErrNoSerial)
+			assert.ErrorIs(t, store.Record(ctx, base, nil), ErrNoSerial)
+
+			// Recorded out of order on purpose.
+			for _, i := range []int{2, 0, 1, 3} {
+				at := base.Add(time.Duration(i) * time.Hour)
+				require.NoError(t, store.Record(ctx, at, snapshotInfo("SER-A", int64(i), 30+i)))
+			}
+			require.NoError(t, store.Record(ctx, base, snapshotInfo("SER-B", 0, 40)))
+
+			serials, err := store.Serials(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"SER-A", "SER-B"}, serials)
+
+			all, err := store.Snapshots(ctx, "SER-A", Query{})
+			require.NoError(t, err)
+			require.Len(t, all, 4)
+			for i, s := range all {
+				assert.True(t, s.Time.Equal(base.Add(time.Duration(i)*time.Hour)))
+				assert.Equal(t, "SER-A", s.Serial)
+			}
+
+			ranged, err := store.Snapshots(ctx, "SER-A", Query{From: base.Add(time.Hour), To: base.Add(3 * time.Hour)})
+			require.NoError(t, err)
+			assert.Len(t, ranged, 2)
+
+			limited, err := store.Snapshots(ctx, "SER-A", Query{Limit: 1})
+			require.NoError(t, err)
+			require.Len(t, limited, 1)
+			assert.True(t, limited[0].Time.Equal(base.Add(3*time.Hour)))
+
+			missing, err := store.Snapshots(ctx, "NOPE", Query{})
+			require.NoError(t, err)
+			assert.Empty(t, missing)
+
+			series, err := AttributeSeries(ctx, store, "SER-A", 197, Query{})
+			require.NoError(t, err)
+			require.Len(t, series, 4)
+			assert.Equal(t, int64(3), series[3].Raw)
+			assert.Equal(t, 100, series[3].Normalized)
+
+			temps, err := TemperatureSeries(ctx, store, "SER-A", Query{})
+			require.NoError(t, err)
+			require.Len(t, temps, 4)
+			assert.Equal(t, int64(31), temps[1].Value)
+
+			require.NoError(t, store.Prune(ctx, base.Add(2*time.Hour)))
+			all, err = store.Snapshots(ctx, "SER-A", Query{})
+			require.NoError(t, err)
+			assert.Len(t, all, 2)
+			serials, err = store.Serials(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"SER-A"}, serials)
+		})
+	}
+}
+
+func TestStore_CopiesAndTrimsSerial(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, store.Record(ctx, base, &smartmontools.SMARTInfo{SerialNumber: "  "}), ErrNoSerial)
+
+			info := snapshotInfo("  SER-A ", 1, 30)
+			require.NoError(t, store.Record(ctx, base, info))
+			info.AtaSmartData.Table[0].Raw.Value = 99
+
+			serials, err := store.Serials(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"SER-A"}, serials, "serials are keyed as IdentityOf reports them")
+
+			snaps, err := store.Snapshots(ctx, "SER-A", Query{})
+			require.NoError(t, err)
+			require.Len(t, snaps, 1)
+			assert.Equal(t, int64(1), snaps[0].Info.AtaSmartData.Table[0].Raw.Value, "the recorded info is copied")
+			snaps[0].Info.AtaSmartData.Table[0].Raw.Value = 42
+
+			again, err := store.Snapshots(ctx, "SER-A", Query{})
+			require.NoError(t, err)
+			require.Len(t, again, 1)
+			assert.Equal(t, int64(1), again[0].Info.AtaSmartData.Table[0].Raw.Value, "returned snapshots are the caller's")
+		})
+	}
+}
+
+func TestBoltStore_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := OpenBoltStore(path, nil)
+	require.NoError(t, err)
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Record(ctx, at, snapshotInfo("SER-A", 7, 35)))
+	require.NoError(t, store.Close())
+
+	store, err = OpenBoltStore(path, nil)
+	require.NoError(t, err)
+	defer store.Close()
+	snaps, err := store.Snapshots(ctx, "SER-A", Query{})
+	require.NoError(t, err)
+	require.Len(t, snaps, 1)
+	assert.True(t, snaps[0].Time.Equal(at))
+	require.NotNil(t, snaps[0].Info.AtaSmartData)
+	assert.Equal(t, int64(7), snaps[0].Info.AtaSmartData.Table[0].Raw.Value)
+}
+
+func TestBoltStore_LegacySnapshots(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenBoltStore(filepath.Join(t.TempDir(), "history.db"), nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	// Snapshots written before versioning hold bare SMARTInfo JSON.
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(boltSnapshotsBucket).CreateBucketIfNotExists([]byte("SER-OLD"))
+		if err != nil {
+			return err
+		}
+		return bucket.Put(boltKey(at), []byte(`{"serial_number":"SER-OLD","temperature":{"current":31}}`))
+	}))
+	require.NoError(t, store.Record(ctx, at.Add(time.Hour), &smartmontools.SMARTInfo{SerialNumber: "SER-OLD", DiskType: "HDD"}))
+
+	snaps, err := store.Snapshots(ctx, "SER-OLD", Query{})
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	assert.Equal(t, 31, snaps[0].Info.Temperature.Current)
+	assert.Equal(t, "HDD", snaps[1].Info.DiskType)
+}
+
+func writesInfo(serial string, unitsWritten int64) *smartmontools.SMARTInfo {
+	return &smartmontools.SMARTInfo{
+		SerialNumber:    serial,
+		UserCapacity:    &smartmontools.UserCapacity{Bytes: 512_000_000_000},
+		NvmeSmartHealth: &smartmontools.NvmeSmartHealth{DataUnitsWritten: unitsWritten},
+	}
+}
+
+func TestSnapshotsOf(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	first := snapshotInfo("USB-BRIDGE", 0, 30)
+	first.WWN = &smartmontools.WWN{NAA: 5, OUI: 0x0026b7, ID: 0x7560145cf}
+	second := snapshotInfo("USB-BRIDGE", 5, 31)
+	second.WWN = &smartmontools.WWN{NAA: 5, OUI: 0x0014ee, ID: 0x123456789}
+	require.NoError(t, store.Record(ctx, base, first))
+	require.NoError(t, store.Record(ctx, base.Add(time.Hour), second))
+	require.NoError(t, store.Record(ctx, base.Add(2*time.Hour), snapshotInfo("USB-BRIDGE", 0, 32)))
+
+	got, err := SnapshotsOf(ctx, store, smartmontools.IdentityOf(first), Query{})
+	require.NoError(t, err)
+	require.Len(t, got, 2, "the snapshot without WWN may belong to either drive")
+	assert.Equal(t, base, got[0].Time)
+	assert.Equal(t, base.Add(2*time.Hour), got[1].Time)
+
+	got, err = SnapshotsOf(ctx, store, smartmontools.DeviceIdentity{Serial: "USB-BRIDGE"}, Query{})
+	require.NoError(t, err)
+	assert.Len(t, got, 3)
+}
+
+func TestWriteRate(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	// 1,000,000 data units of 512,000 bytes are 512 GB, one drive write.
+	require.NoError(t, store.Record(ctx, base, writesInfo("NVME-1", 1_000_000)))
+	require.NoError(t, store.Record(ctx, base.Add(24*time.Hour), &smartmontools.SMARTInfo{SerialNumber: "NVME-1"}))
+	require.NoError(t, store.Record(ctx, base.Add(10*24*time.Hour), writesInfo("NVME-1", 6_000_000)))
+
+	rate, err := WriteRateOf(ctx, store, "NVME-1", Query{})
+	require.NoError(t, err)
+	assert.True(t, rate.From.Equal(base))
+	assert.Equal(t, int64(5_000_000*512_000), rate.Written)
+	assert.Equal(t, int64(6_000_000*512_000), rate.TotalWritten)
+	assert.InDelta(t, 256e9, rate.BytesPerDay, 1)
+	assert.InDelta(t, 0.5, rate.DWPD, 1e-9)
+
+	// 3.072 TB written, 256 GB a day: a 300 TBW rating lasts 1159.875 more days.
+	remaining, ok := rate.Remaining(300)
+	require.True(t, ok)
+	assert.InDelta(t, 1159.875, remaining.Hours()/24, 1e-6)
+	remaining, ok = rate.Remaining(1)
+	require.True(t, ok)
+	assert.Zero(t, remaining)
+	_, ok = rate.Remaining(0)
+	assert.False(t, ok)
+
+	_, err = WriteRateBetween(Snapshot{Time: base, Info: writesInfo("NVME-1", 1)}, Snapshot{Time: base, Info: writesInfo("NVME-1", 2)})
+	assert.ErrorIs(t, err, ErrInsufficientHistory)
+	_, err = WriteRateOf(ctx, store, "NVME-1", Query{To: base.Add(time.Hour)})
+	assert.ErrorIs(t, err, ErrInsufficientHistory)
+}
+
+func sectorInfo(serial string, reallocated, pending int64) *smartmontools.SMARTInfo {
+	return &smartmontools.SMARTInfo{
+		SerialNumber: serial,
+		AtaSmartData: &smartmontools.AtaSmartData{Table: []smartmontools.SmartAttribute{
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Raw: smartmontools.Raw{Value: reallocated}},
+			{ID: 197, Name: "Current_Pending_Sector", Value: 100, Raw: smartmontools.Raw{Value: pending}},
+		}},
+	}
+}
+
+func TestAnalyzeSectorTrend(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	store := NewMemoryStore()
+	record := func(serial string, days int, reallocated, pending int64) {
+		require.NoError(t, store.Record(ctx, base.Add(time.Duration(days)*day), sectorInfo(serial, reallocated, pending)))
+	}
+	record("STABLE", 0, 8, 0)
+	record("STABLE", 30, 8, 0)
+	// Two pending sectors appear and are remapped over a month.
+	record("SLOW", 0, 8, 0)
+	record("SLOW", 10, 8, 2)
+	record("SLOW", 30, 10, 0)
+	record("FAST", 0, 0, 0)
+	record("FAST", 2, 40, 12)
+
+	report, err := AnalyzeSectorTrend(ctx, store, "STABLE", Query{})
+	require.NoError(t, err)
+	assert.Equal(t, SectorsStable, report.Trend)
+	require.Len(t, report.Attributes, 2)
+	assert.Equal(t, "Reallocated_Sector_Ct", report.Attributes[0].Name)
+
+	report, err = AnalyzeSectorTrend(ctx, store, "SLOW", Query{})
+	require.NoError(t, err)
+	assert.Equal(t, SectorsSlowlyDegrading, report.Trend)
+	assert.True(t, report.From.Equal(base))
+	assert.True(t, report.To.Equal(base.Add(30*day)))
+	pending := report.Attributes[1]
+	assert.Equal(t, 197, pending.ID)
+	assert.Equal(t, int64(0), pending.Last)
+	assert.Equal(t, int64(2), pending.Growth)
+
+	report, err = AnalyzeSectorTrend(ctx, store, "FAST", Query{})
+	require.NoError(t, err)
+	assert.Equal(t, SectorsRapidlyDegrading, report.Trend)
+	assert.InDelta(t, 20, report.Attributes[0].PerDay, 1e-9)
+
+	_, err = AnalyzeSectorTrend(ctx, store, "FAST", Query{To: base.Add(day)})
+	assert.ErrorIs(t, err, ErrInsufficientHistory)
+}
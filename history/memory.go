@@ -0,0 +1,110 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// MemoryStore is an in-memory Store. Snapshots are kept encoded with
+// smartmontools.MarshalSnapshot, like BoltStore does, so that neither the
+// recorded SMARTInfo nor the returned ones are shared with the store. They
+// are lost when the process exits.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	snapshots map[string][]memorySnapshot
+}
+
+type memorySnapshot struct {
+	at   time.Time
+	data []byte
+}
+
+// NewMemoryStore returns an empty in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{snapshots: make(map[string][]memorySnapshot)}
+}
+
+// Record implements Store.
+func (m *MemoryStore) Record(ctx context.Context, at time.Time, info *smartmontools.SMARTInfo) error {
+	serial := recordSerial(info)
+	if serial == "" {
+		return ErrNoSerial
+	}
+	data, err := smartmontools.MarshalSnapshot(info)
+	if err != nil {
+		return fmt.Errorf("history: encode snapshot: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap := memorySnapshot{at: at, data: data}
+	list := m.snapshots[serial]
+	i, found := slices.BinarySearchFunc(list, at, func(s memorySnapshot, t time.Time) int { return s.at.Compare(t) })
+	if found {
+		list[i] = snap
+	} else {
+		list = slices.Insert(list, i, snap)
+	}
+	m.snapshots[serial] = list
+	return nil
+}
+
+// Snapshots implements Store.
+func (m *MemoryStore) Snapshots(ctx context.Context, serial string, q Query) ([]Snapshot, error) {
+	m.mu.RLock()
+	var matching []memorySnapshot
+	for _, s := range m.snapshots[serial] {
+		if q.contains(s.at) {
+			matching = append(matching, s)
+		}
+	}
+	m.mu.RUnlock()
+	if q.Limit > 0 && len(matching) > q.Limit {
+		matching = matching[len(matching)-q.Limit:]
+	}
+	var out []Snapshot
+	for _, s := range matching {
+		info, err := smartmontools.UnmarshalSnapshot(s.data)
+		if err != nil {
+			return nil, fmt.Errorf("history: decode snapshot of %s: %w", serial, err)
+		}
+		out = append(out, Snapshot{Time: s.at, Serial: serial, Info: info})
+	}
+	return out, nil
+}
+
+// Serials implements Store.
+func (m *MemoryStore) Serials(ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	serials := make([]string, 0, len(m.snapshots))
+	for serial := range m.snapshots {
+		serials = append(serials, serial)
+	}
+	slices.Sort(serials)
+	return serials, nil
+}
+
+// Prune implements Store.
+func (m *MemoryStore) Prune(ctx context.Context, before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for serial, list := range m.snapshots {
+		i, _ := slices.BinarySearchFunc(list, before, func(s memorySnapshot, t time.Time) int { return s.at.Compare(t) })
+		if i == len(list) {
+			delete(m.snapshots, serial)
+			continue
+		}
+		m.snapshots[serial] = slices.Clone(list[i:])
+	}
+	return nil
+}
+
+// Close implements Store. It is a no-op.
+func (m *MemoryStore) Close() error {
+	return nil
+}
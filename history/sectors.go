@@ -0,0 +1,108 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// SectorTrend classifies how fast a drive's bad sector counters grow.
+type SectorTrend string
+
+// Sector trends, from best to worst.
+const (
+	// SectorsStable means none of the sector counters grew in the window.
+	SectorsStable SectorTrend = "stable"
+	// SectorsSlowlyDegrading means sector counters grew, but slower than
+	// RapidSectorGrowthPerDay.
+	SectorsSlowlyDegrading SectorTrend = "slowly_degrading"
+	// SectorsRapidlyDegrading means a sector counter grew by at least
+	// RapidSectorGrowthPerDay sectors a day.
+	SectorsRapidlyDegrading SectorTrend = "rapidly_degrading"
+)
+
+// RapidSectorGrowthPerDay is the growth rate of a single sector counter,
+// in sectors per day, from which a drive counts as rapidly degrading.
+const RapidSectorGrowthPerDay = 1.0
+
+// sectorAttributeIDs are the ATA attributes tracking bad sectors.
+var sectorAttributeIDs = []int{
+	smartmontools.SmartAttrReallocatedSectors,
+	smartmontools.SmartAttrReallocatedEventCount,
+	smartmontools.SmartAttrCurrentPendingSector,
+	smartmontools.SmartAttrOfflineUncorrectable,
+}
+
+// SectorGrowth is the growth of one sector counter over the analyzed window.
+type SectorGrowth struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+	// First and Last are the raw counts at the start and end of the window.
+	First int64 `json:"first"`
+	Last  int64 `json:"last"`
+	// Growth adds up every increase between consecutive snapshots, so pending
+	// sectors that were later remapped still count.
+	Growth int64   `json:"growth"`
+	PerDay float64 `json:"per_day"`
+}
+
+// SectorTrendReport is the result of AnalyzeSectorTrend.
+type SectorTrendReport struct {
+	Serial     string         `json:"serial"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Trend      SectorTrend    `json:"trend"`
+	Attributes []SectorGrowth `json:"attributes"`
+}
+
+// AnalyzeSectorTrend examines the growth of attributes 5 (reallocated
+// sectors), 196 (reallocation events), 197 (pending sectors) and 198 (offline
+// uncorrectable) of serial over the snapshots matching q, and classifies the
+// drive as stable, slowly degrading or rapidly degrading. Attributes the drive
+// does not report are left out. It returns ErrInsufficientHistory when no
+// attribute has two samples at different times.
+func AnalyzeSectorTrend(ctx context.Context, store Store, serial string, q Query) (SectorTrendReport, error) {
+	report := SectorTrendReport{Serial: serial, Trend: SectorsStable}
+	for _, id := range sectorAttributeIDs {
+		points, err := AttributeSeries(ctx, store, serial, id, q)
+		if err != nil {
+			return SectorTrendReport{}, err
+		}
+		if len(points) < 2 {
+			continue
+		}
+		first, last := points[0], points[len(points)-1]
+		days := last.Time.Sub(first.Time).Hours() / 24
+		if days <= 0 {
+			continue
+		}
+		growth := SectorGrowth{ID: id, First: first.Raw, Last: last.Raw}
+		if d, ok := smartmontools.DescribeAttribute(id); ok {
+			growth.Name = d.Name
+		}
+		for i := 1; i < len(points); i++ {
+			growth.Growth += max(0, points[i].Raw-points[i-1].Raw)
+		}
+		growth.PerDay = float64(growth.Growth) / days
+		report.Attributes = append(report.Attributes, growth)
+
+		if report.From.IsZero() || first.Time.Before(report.From) {
+			report.From = first.Time
+		}
+		if last.Time.After(report.To) {
+			report.To = last.Time
+		}
+		switch {
+		case growth.PerDay >= RapidSectorGrowthPerDay:
+			report.Trend = SectorsRapidlyDegrading
+		case growth.Growth > 0 && report.Trend == SectorsStable:
+			report.Trend = SectorsSlowlyDegrading
+		}
+	}
+	if len(report.Attributes) == 0 {
+		return SectorTrendReport{}, fmt.Errorf("%w: %s has no sector counters over time", ErrInsufficientHistory, serial)
+	}
+	return report, nil
+}
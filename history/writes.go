@@ -0,0 +1,110 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// WriteRate is the host write rate of a device between two snapshots, derived
+// from smartmontools.BytesWritten.
+type WriteRate struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Written is the number of bytes written between From and To, and
+	// TotalWritten the lifetime total at To.
+	Written      int64   `json:"written"`
+	TotalWritten int64   `json:"total_written"`
+	BytesPerDay  float64 `json:"bytes_per_day"`
+	// DWPD is the number of full drive writes per day: BytesPerDay divided by
+	// the user capacity. It is zero when the capacity is unknown.
+	DWPD float64 `json:"dwpd"`
+}
+
+// WriteRateBetween computes the write rate between an older and a newer
+// snapshot of the same device. It returns ErrInsufficientHistory when either
+// snapshot lacks a host write counter or newer is not later than older.
+func WriteRateBetween(older, newer Snapshot) (WriteRate, error) {
+	if older.Info == nil || newer.Info == nil || !newer.Time.After(older.Time) {
+		return WriteRate{}, ErrInsufficientHistory
+	}
+	before, ok := smartmontools.BytesWritten(older.Info)
+	if !ok {
+		return WriteRate{}, fmt.Errorf("%w: no bytes-written counter at %s", ErrInsufficientHistory, older.Time.Format(time.RFC3339))
+	}
+	after, ok := smartmontools.BytesWritten(newer.Info)
+	if !ok {
+		return WriteRate{}, fmt.Errorf("%w: no bytes-written counter at %s", ErrInsufficientHistory, newer.Time.Format(time.RFC3339))
+	}
+
+	// Counters are not expected to go backwards; a drive swapped under the
+	// same serial or a reset counter would otherwise yield a negative rate.
+	written := max(0, after-before)
+	days := newer.Time.Sub(older.Time).Hours() / 24
+	rate := WriteRate{
+		From:         older.Time,
+		To:           newer.Time,
+		Written:      written,
+		TotalWritten: after,
+		BytesPerDay:  float64(written) / days,
+	}
+	if capacity := userCapacity(newer.Info); capacity > 0 {
+		rate.DWPD = rate.BytesPerDay / float64(capacity)
+	}
+	return rate, nil
+}
+
+// WriteRateOf computes the write rate of serial between the oldest and the
+// newest snapshot matching q that report a host write counter.
+func WriteRateOf(ctx context.Context, store Store, serial string, q Query) (WriteRate, error) {
+	snapshots, err := store.Snapshots(ctx, serial, q)
+	if err != nil {
+		return WriteRate{}, err
+	}
+	var first, last *Snapshot
+	for i := range snapshots {
+		if snapshots[i].Info == nil {
+			continue
+		}
+		if _, ok := smartmontools.BytesWritten(snapshots[i].Info); !ok {
+			continue
+		}
+		if first == nil {
+			first = &snapshots[i]
+		}
+		last = &snapshots[i]
+	}
+	if first == nil || first == last {
+		return WriteRate{}, fmt.Errorf("%w: %s needs two snapshots with a bytes-written counter", ErrInsufficientHistory, serial)
+	}
+	return WriteRateBetween(*first, *last)
+}
+
+// Remaining projects how long the device lasts at this write rate before its
+// lifetime writes reach ratedTBW terabytes (10^12 bytes), counted from To. It
+// returns zero once the rating is passed, and false when ratedTBW is not
+// positive or nothing was written in the measured period.
+func (r WriteRate) Remaining(ratedTBW float64) (time.Duration, bool) {
+	if ratedTBW <= 0 || r.BytesPerDay <= 0 {
+		return 0, false
+	}
+	left := ratedTBW*1e12 - float64(r.TotalWritten)
+	if left <= 0 {
+		return 0, true
+	}
+	return time.Duration(left / r.BytesPerDay * float64(24*time.Hour)), true
+}
+
+// userCapacity returns the user-addressable capacity of the device in bytes,
+// or 0 when unknown.
+func userCapacity(info *smartmontools.SMARTInfo) int64 {
+	switch {
+	case info.UserCapacity != nil && info.UserCapacity.Bytes > 0:
+		return info.UserCapacity.Bytes
+	case info.NvmeTotalCapacity > 0:
+		return info.NvmeTotalCapacity
+	}
+	return 0
+}
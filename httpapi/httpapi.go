@@ -0,0 +1,312 @@
+// Package httpapi exposes a smartmontools-go SmartClient over HTTP/JSON so
+// appliance UIs can consume SMART data without extra glue code.
+//
+// Routes:
+//
+//	GET  /devices                  list devices
+//	GET  /devices/{id}/smart       SMART information of a device
+//	GET  /devices/{id}/health      overall-health self-assessment
+//	POST /devices/{id}/selftest    start a self-test
+//
+// A device id is the last element of its path ("sda" for /dev/sda) or the
+// URL-escaped full path ("%2Fdev%2Fsda"). Either must name a device reported
+// by ScanDevices; any other id is answered with 404, so that a request
+// cannot make smartctl open an arbitrary path or read an id as an option.
+//
+// POST /devices/{id}/selftest takes the test type from the "type" query
+// parameter or a {"type": "..."} JSON body (default "short"). With
+// "Accept: text/event-stream" the response is a Server-Sent Events stream of
+// "progress" events ending with a "done" event, whose "outcome" tells how the
+// test ended ("passed", "failed", "aborted", "interrupted" or "unknown");
+// otherwise the handler replies 202 Accepted once the test has started.
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// Authorizer decides whether a request may proceed. A non-nil error rejects
+// the request with 401 Unauthorized, or with the status of an *Error.
+type Authorizer func(r *http.Request) error
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithAuthorizer installs an authorization hook run before every request.
+func WithAuthorizer(auth Authorizer) Option {
+	return func(h *Handler) {
+		h.auth = auth
+	}
+}
+
+// WithReadOnly disables the self-test endpoint, which changes device state.
+func WithReadOnly() Option {
+	return func(h *Handler) {
+		h.readOnly = true
+	}
+}
+
+// BearerToken returns an Authorizer accepting requests carrying
+// "Authorization: Bearer <token>".
+func BearerToken(token string) Authorizer {
+	return func(r *http.Request) error {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return &Error{Status: http.StatusUnauthorized, Message: "invalid or missing bearer token"}
+		}
+		return nil
+	}
+}
+
+// Error is an error with an HTTP status, returned by Authorizers to choose
+// the rejection status (e.g. 403 Forbidden).
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Handler serves the HTTP API. It implements http.Handler.
+type Handler struct {
+	client   smartmontools.SmartClient
+	auth     Authorizer
+	readOnly bool
+	mux      *http.ServeMux
+}
+
+// New returns a Handler serving client.
+func New(client smartmontools.SmartClient, opts ...Option) *Handler {
+	h := &Handler{client: client, mux: http.NewServeMux()}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux.HandleFunc("GET /devices", h.listDevices)
+	h.mux.HandleFunc("GET /devices/{id}/smart", h.getSMART)
+	h.mux.HandleFunc("GET /devices/{id}/health", h.getHealth)
+	h.mux.HandleFunc("POST /devices/{id}/selftest", h.runSelfTest)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.auth != nil {
+		if err := h.auth(r); err != nil {
+			status := http.StatusUnauthorized
+			var apiErr *Error
+			if errors.As(err, &apiErr) && apiErr.Status != 0 {
+				status = apiErr.Status
+			}
+			writeError(w, status, err.Error())
+			return
+		}
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// deviceJSON is the representation of a device in GET /devices.
+type deviceJSON struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func (h *Handler) listDevices(w http.ResponseWriter, r *http.Request) {
+	devices, err := h.client.ScanDevices(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := make([]deviceJSON, 0, len(devices))
+	for _, d := range devices {
+		out = append(out, deviceJSON{ID: path.Base(d.Name), Name: d.Name, Type: d.Type})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// resolveDevice maps a device id to the path of a scanned device: ids
+// containing a slash match the device whose normalized path is the same,
+// others the device whose path ends in id.
+func (h *Handler) resolveDevice(ctx context.Context, id string) (string, error) {
+	devices, err := h.client.ScanDevices(ctx)
+	if err != nil {
+		return "", err
+	}
+	isPath := strings.Contains(id, "/")
+	normalized := smartmontools.NormalizeDevicePath(id)
+	for _, d := range devices {
+		if isPath && smartmontools.NormalizeDevicePath(d.Name) == normalized || !isPath && path.Base(d.Name) == id {
+			return d.Name, nil
+		}
+	}
+	return "", &Error{Status: http.StatusNotFound, Message: fmt.Sprintf("device %q not found", id)}
+}
+
+// device resolves the {id} path value, writing an error response on failure.
+func (h *Handler) device(w http.ResponseWriter, r *http.Request) (string, bool) {
+	devicePath, err := h.resolveDevice(r.Context(), r.PathValue("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			status = apiErr.Status
+		}
+		writeError(w, status, err.Error())
+		return "", false
+	}
+	return devicePath, true
+}
+
+func (h *Handler) getSMART(w http.ResponseWriter, r *http.Request) {
+	devicePath, ok := h.device(w, r)
+	if !ok {
+		return
+	}
+	info, err := h.client.GetSMARTInfo(r.Context(), devicePath)
+	if err != nil {
+		writeError(w, errorStatus(err, http.StatusBadGateway), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (h *Handler) getHealth(w http.ResponseWriter, r *http.Request) {
+	devicePath, ok := h.device(w, r)
+	if !ok {
+		return
+	}
+	passed, err := h.client.CheckHealth(r.Context(), devicePath)
+	if err != nil {
+		writeError(w, errorStatus(err, http.StatusBadGateway), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"device": devicePath, "passed": passed})
+}
+
+type selfTestRequest struct {
+	Type string `json:"type"`
+}
+
+func (h *Handler) runSelfTest(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly {
+		writeError(w, http.StatusForbidden, "self-tests are disabled")
+		return
+	}
+	testType := r.URL.Query().Get("type")
+	if testType == "" && r.ContentLength != 0 {
+		var req selfTestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		testType = req.Type
+	}
+	if testType == "" {
+		testType = "short"
+	}
+	devicePath, ok := h.device(w, r)
+	if !ok {
+		return
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		if err := h.client.RunSelfTest(r.Context(), devicePath, testType); err != nil {
+			writeError(w, errorStatus(err, http.StatusBadRequest), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"device": devicePath, "type": testType, "status": "started"})
+		return
+	}
+	h.streamSelfTest(w, r, devicePath, testType)
+}
+
+type progressEvent struct {
+	Progress int                           `json:"progress"`
+	Status   string                        `json:"status"`
+	Source   smartmontools.ProgressSource  `json:"source"`
+	Outcome  smartmontools.SelfTestOutcome `json:"outcome,omitempty"`
+}
+
+// streamSelfTest starts a self-test and relays its progress as Server-Sent
+// Events. Closing the connection stops the stream and progress polling, not
+// the self-test itself.
+func (h *Handler) streamSelfTest(w http.ResponseWriter, r *http.Request, devicePath, testType string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusNotAcceptable, "streaming is not supported by this connection")
+		return
+	}
+	ctx := r.Context()
+	updates := make(chan progressEvent, 16)
+	callback := func(progress int, status string, source smartmontools.ProgressSource, outcome smartmontools.SelfTestOutcome) {
+		select {
+		case updates <- progressEvent{Progress: progress, Status: status, Source: source, Outcome: outcome}:
+		case <-ctx.Done():
+		}
+	}
+	if err := h.client.RunSelfTestWithProgress(ctx, devicePath, testType, callback); err != nil {
+		writeError(w, errorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-updates:
+			writeEvent(w, "progress", ev)
+			if ev.Progress >= 100 {
+				writeEvent(w, "done", ev)
+				flusher.Flush()
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, name string, data any) {
+	payload, _ := json.Marshal(data)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+}
+
+// errorStatus maps the library's sentinel errors to HTTP status codes and
+// returns fallback for any other error.
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, smartmontools.ErrDeviceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, smartmontools.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, smartmontools.ErrSmartNotSupported), errors.Is(err, smartmontools.ErrTestNotSupported):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, smartmontools.ErrDeviceInStandby):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, smartmontools.ErrCommandTimeout):
+		return http.StatusGatewayTimeout
+	}
+	return fallback
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,184 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient implements the SmartClient methods used by Handler; other
+// methods panic through the nil embedded interface.
+type fakeClient struct {
+	smartmontools.SmartClient
+	started []string
+}
+
+func (f *fakeClient) ScanDevices(ctx context.Context) ([]smartmontools.Device, error) {
+	return []smartmontools.Device{
+		{Name: "/dev/sda", Type: "sat"}, {Name: "/dev/nvme0", Type: "nvme"},
+		{Name: "/dev/sdx", Type: "sat"}, {Name: "/dev/sdh", Type: "sat"},
+	}, nil
+}
+
+func (f *fakeClient) GetSMARTInfo(ctx context.Context, devicePath string) (*smartmontools.SMARTInfo, error) {
+	if devicePath == "/dev/sdx" {
+		return nil, fmt.Errorf("failed to get SMART info: %w", smartmontools.ErrPermissionDenied)
+	}
+	if devicePath == "/dev/sdh" {
+		return nil, &smartmontools.CommandTimeoutError{Name: "smartctl", Args: []string{"-a", "/dev/sdh"}, Timeout: time.Minute, Err: context.DeadlineExceeded}
+	}
+	if devicePath != "/dev/sda" {
+		return nil, errors.New("device open failed")
+	}
+	return &smartmontools.SMARTInfo{SerialNumber: "SER1", SmartStatus: &smartmontools.SmartStatus{Passed: true}}, nil
+}
+
+func (f *fakeClient) CheckHealth(ctx context.Context, devicePath string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeClient) RunSelfTest(ctx context.Context, devicePath, testType string) error {
+	if testType == "bogus" {
+		return errors.New("invalid test type: bogus")
+	}
+	f.started = append(f.started, devicePath+":"+testType)
+	return nil
+}
+
+func (f *fakeClient) RunSelfTestWithProgress(ctx context.Context, devicePath, testType string, callback smartmontools.ProgressCallback) error {
+	go func() {
+		callback(0, "Test started", smartmontools.ProgressEstimated, "")
+		callback(100, "Completed without error", smartmontools.ProgressMeasured, smartmontools.SelfTestPassed)
+	}()
+	return nil
+}
+
+func do(t *testing.T, h http.Handler, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_Devices(t *testing.T) {
+	h := New(&fakeClient{})
+	rec := do(t, h, http.MethodGet, "/devices", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var devices []map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &devices))
+	assert.Equal(t, map[string]string{"id": "sda", "name": "/dev/sda", "type": "sat"}, devices[0])
+}
+
+func TestHandler_SMART(t *testing.T) {
+	h := New(&fakeClient{})
+	for _, target := range []string{"/devices/sda/smart", "/devices/%2Fdev%2Fsda/smart"} {
+		rec := do(t, h, http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, target)
+		assert.Contains(t, rec.Body.String(), `"serial_number":"SER1"`)
+	}
+
+	rec := do(t, h, http.MethodGet, "/devices/sdz/smart", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error"`)
+
+	rec = do(t, h, http.MethodGet, "/devices/nvme0/smart", "", nil)
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+
+	rec = do(t, h, http.MethodGet, "/devices/%2Fdev%2Fsdx/smart", "", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = do(t, h, http.MethodGet, "/devices/%2Fdev%2Fsdh/smart", "", nil)
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code, "hung smartctl")
+
+	rec = do(t, h, http.MethodGet, "/devices/sda/health", "", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"passed":true`)
+
+	rec = do(t, h, http.MethodDelete, "/devices/sda/smart", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestHandler_SelfTest(t *testing.T) {
+	client := &fakeClient{}
+	h := New(client)
+
+	rec := do(t, h, http.MethodPost, "/devices/sda/selftest", `{"type":"long"}`, nil)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	rec = do(t, h, http.MethodPost, "/devices/sda/selftest?type=conveyance", "", nil)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, []string{"/dev/sda:long", "/dev/sda:conveyance"}, client.started)
+
+	rec = do(t, h, http.MethodPost, "/devices/sda/selftest", `{"type":`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = do(t, h, http.MethodPost, "/devices/sda/selftest?type=bogus", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(t, New(client, WithReadOnly()), http.MethodPost, "/devices/sda/selftest", "", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = do(t, h, http.MethodPost, "/devices/-x%2Fy/selftest", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Len(t, client.started, 2, "unknown paths never reach the client")
+}
+
+func TestHandler_UnknownPaths(t *testing.T) {
+	h := New(&fakeClient{})
+	for _, target := range []string{"/devices/%2Fetc%2Fpasswd/smart", "/devices/-x%2Fy/smart", "/devices/%2Fdev%2Fsdz/health"} {
+		rec := do(t, h, http.MethodGet, target, "", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, target)
+	}
+}
+
+func TestHandler_SelfTestSSE(t *testing.T) {
+	srv := httptest.NewServer(New(&fakeClient{}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/devices/sda/selftest", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var events []string
+	var last progressEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, name)
+		}
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			require.NoError(t, json.Unmarshal([]byte(data), &last))
+		}
+	}
+	assert.Equal(t, []string{"progress", "progress", "done"}, events)
+	assert.Equal(t, smartmontools.SelfTestPassed, last.Outcome, "the done event carries the outcome")
+}
+
+func TestHandler_Auth(t *testing.T) {
+	h := New(&fakeClient{}, WithAuthorizer(BearerToken("secret")))
+	assert.Equal(t, http.StatusUnauthorized, do(t, h, http.MethodGet, "/devices", "", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, do(t, h, http.MethodGet, "/devices", "", map[string]string{"Authorization": "Bearer nope"}).Code)
+	assert.Equal(t, http.StatusOK, do(t, h, http.MethodGet, "/devices", "", map[string]string{"Authorization": "Bearer secret"}).Code)
+
+	forbid := New(&fakeClient{}, WithAuthorizer(func(r *http.Request) error {
+		return &Error{Status: http.StatusForbidden, Message: "nope"}
+	}))
+	assert.Equal(t, http.StatusForbidden, do(t, forbid, http.MethodGet, "/devices", "", nil).Code)
+}
@@ -0,0 +1,55 @@
+package smartmontools
+
+import "strings"
+
+// DeviceIdentity identifies a physical drive independently of the path it is
+// attached at, which may change across reboots or when a USB enclosure is
+// re-enumerated. WWN is the World Wide Name in its by-id form (see WWN.String)
+// and is empty for drives that do not report one, such as most NVMe and USB
+// drives.
+type DeviceIdentity struct {
+	WWN    string `json:"wwn,omitempty"`
+	Serial string `json:"serial,omitempty"`
+}
+
+// IdentityOf returns the identity of the drive described by info. It is the
+// zero DeviceIdentity when info is nil or reports neither a WWN nor a serial
+// number.
+func IdentityOf(info *SMARTInfo) DeviceIdentity {
+	if info == nil {
+		return DeviceIdentity{}
+	}
+	id := DeviceIdentity{Serial: strings.TrimSpace(info.SerialNumber)}
+	if info.WWN != nil && (info.WWN.NAA != 0 || info.WWN.OUI != 0 || info.WWN.ID != 0) {
+		id.WWN = info.WWN.String()
+	}
+	return id
+}
+
+// IsZero reports whether the identity is unknown.
+func (id DeviceIdentity) IsZero() bool {
+	return id.WWN == "" && id.Serial == ""
+}
+
+// Key returns a string identifying the drive, for use as a map or storage
+// key: "WWN:SERIAL" when both are known, otherwise whichever is. It is empty
+// for the zero identity.
+func (id DeviceIdentity) Key() string {
+	switch {
+	case id.WWN == "":
+		return id.Serial
+	case id.Serial == "":
+		return id.WWN
+	}
+	return id.WWN + ":" + id.Serial
+}
+
+// Matches reports whether id and other may describe the same drive: their
+// serial numbers are equal and, when both report one, so are their WWNs. It
+// is false when either identity is zero.
+func (id DeviceIdentity) Matches(other DeviceIdentity) bool {
+	if id.IsZero() || other.IsZero() || id.Serial != other.Serial {
+		return false
+	}
+	return id.WWN == "" || other.WWN == "" || id.WWN == other.WWN
+}
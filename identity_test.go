@@ -0,0 +1,26 @@
+package smartmontools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceIdentity(t *testing.T) {
+	wwn := &WWN{NAA: 5, OUI: 0x0026b7, ID: 0x7560145cf}
+	ata := IdentityOf(&SMARTInfo{SerialNumber: " S1 ", WWN: wwn})
+	assert.Equal(t, DeviceIdentity{WWN: "0x50026b77560145cf", Serial: "S1"}, ata)
+	assert.Equal(t, "0x50026b77560145cf:S1", ata.Key())
+
+	nvme := IdentityOf(&SMARTInfo{SerialNumber: "S2", WWN: &WWN{}})
+	assert.Equal(t, "S2", nvme.Key(), "a zero WWN is ignored")
+	assert.Equal(t, "0x50026b77560145cf", IdentityOf(&SMARTInfo{WWN: wwn}).Key())
+
+	assert.True(t, IdentityOf(nil).IsZero())
+	assert.Empty(t, IdentityOf(&SMARTInfo{}).Key())
+
+	assert.True(t, ata.Matches(DeviceIdentity{Serial: "S1"}))
+	assert.False(t, ata.Matches(DeviceIdentity{Serial: "S1", WWN: "0x5000000000000001"}))
+	assert.False(t, ata.Matches(nvme))
+	assert.False(t, DeviceIdentity{}.Matches(DeviceIdentity{}))
+}
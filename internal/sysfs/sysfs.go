@@ -0,0 +1,51 @@
+// Package sysfs reads Linux sysfs attributes and the udev database for the
+// exec backend and package blockdev.
+package sysfs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadLine returns the trimmed content of a one-line sysfs attribute, or ""
+// when it cannot be read.
+func ReadLine(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Exists reports whether path exists.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// UdevProperties returns the properties (E: lines) udev recorded in dataDir
+// for the block device with the given "major:minor" number, or nil.
+func UdevProperties(dataDir, dev string) map[string]string {
+	if dev == "" {
+		return nil
+	}
+	f, err := os.Open(filepath.Join(dataDir, "b"+dev))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(scanner.Text(), "E:")
+		if !ok {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			props[key] = value
+		}
+	}
+	return props
+}
@@ -0,0 +1,30 @@
+package sysfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLineAndExists(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model")
+	require.NoError(t, os.WriteFile(path, []byte("QEMU HARDDISK   \n"), 0o644))
+
+	assert.Equal(t, "QEMU HARDDISK", ReadLine(path))
+	assert.Empty(t, ReadLine(filepath.Join(dir, "missing")))
+	assert.True(t, Exists(path))
+	assert.False(t, Exists(filepath.Join(dir, "missing")))
+}
+
+func TestUdevProperties(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b8:16"), []byte("S:disk/by-id/ata-WDC\nE:ID_BUS=ata\nE:ID_FS_TYPE=ext4\nE:ID_FS_LABEL=a=b\n"), 0o644))
+
+	assert.Equal(t, map[string]string{"ID_BUS": "ata", "ID_FS_TYPE": "ext4", "ID_FS_LABEL": "a=b"}, UdevProperties(dir, "8:16"))
+	assert.Nil(t, UdevProperties(dir, "8:32"))
+	assert.Nil(t, UdevProperties(dir, ""))
+}
@@ -0,0 +1,141 @@
+package types
+
+import (
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// Attributes returns an iterator over the ATA SMART attributes of the drive in
+// table order. It yields nothing for drives without an attribute table, such
+// as NVMe and SCSI drives.
+func (s *SMARTInfo) Attributes() iter.Seq[SmartAttribute] {
+	return func(yield func(SmartAttribute) bool) {
+		if s == nil || s.AtaSmartData == nil {
+			return
+		}
+		for _, attr := range s.AtaSmartData.Table {
+			if !yield(attr) {
+				return
+			}
+		}
+	}
+}
+
+// GetAttributeByID returns the attribute with the given ID, or nil when the
+// table does not contain it.
+func (a *AtaSmartData) GetAttributeByID(id int) *SmartAttribute {
+	if a == nil {
+		return nil
+	}
+	for i := range a.Table {
+		if a.Table[i].ID == id {
+			return &a.Table[i]
+		}
+	}
+	return nil
+}
+
+// lifeRemainingAttributes lists the attributes whose normalized value is the
+// remaining life in percent, in order of preference. The IDs are overloaded
+// across vendors (233 is an internal counter on SandForce drives, 231 a
+// temperature on some HDDs), so an attribute only counts when its smartctl
+// name contains the given marker.
+var lifeRemainingAttributes = []struct {
+	id     int
+	marker string
+}{
+	{SmartAttrSSDLifeLeft, "life"},                // SSD_Life_Left (SandForce, Kingston, ...)
+	{SmartAttrMediaWearout, "wearout"},            // Media_Wearout_Indicator (Intel)
+	{SmartAttrRemainingLifetime, "life"},          // Remaining_Lifetime_Perc (ADATA, WD)
+	{SmartAttrWearLevelingCount, "wear_leveling"}, // Wear_Leveling_Count (Samsung)
+	{SmartAttrPercentLifetime, "remain"},          // Percent_Lifetime_Remain (Crucial, Micron)
+}
+
+// LifeRemainingAttribute returns the first attribute of 231, 233, 169, 177
+// and 202 that the drive reports under a life or wear name, whose normalized
+// value is the remaining life in percent, or nil when there is none.
+// Attributes without a name are accepted.
+func (a *AtaSmartData) LifeRemainingAttribute() *SmartAttribute {
+	for _, candidate := range lifeRemainingAttributes {
+		attr := a.GetAttributeByID(candidate.id)
+		if attr != nil && (attr.Name == "" || strings.Contains(strings.ToLower(attr.Name), candidate.marker)) {
+			return attr
+		}
+	}
+	return nil
+}
+
+// GetAttributeByName returns the first attribute whose name matches name,
+// ignoring case, or nil when none does. Names are the ones reported by
+// smartctl (e.g. "Reallocated_Sector_Ct"), which may vary between vendors.
+func (a *AtaSmartData) GetAttributeByName(name string) *SmartAttribute {
+	if a == nil {
+		return nil
+	}
+	for i := range a.Table {
+		if strings.EqualFold(a.Table[i].Name, name) {
+			return &a.Table[i]
+		}
+	}
+	return nil
+}
+
+// rawCount returns the counter value of attribute id (see [Raw.Count]).
+func (a *AtaSmartData) rawCount(id int) (int64, bool) {
+	attr := a.GetAttributeByID(id)
+	if attr == nil {
+		return 0, false
+	}
+	return attr.Raw.Count(), true
+}
+
+// ReallocatedSectors returns the raw count of attribute 5 (Reallocated_Sector_Ct).
+func (a *AtaSmartData) ReallocatedSectors() (int64, bool) {
+	return a.rawCount(SmartAttrReallocatedSectors)
+}
+
+// ReallocationEvents returns the raw count of attribute 196 (Reallocated_Event_Count).
+func (a *AtaSmartData) ReallocationEvents() (int64, bool) {
+	return a.rawCount(SmartAttrReallocatedEventCount)
+}
+
+// PendingSectors returns the raw count of attribute 197 (Current_Pending_Sector).
+func (a *AtaSmartData) PendingSectors() (int64, bool) {
+	return a.rawCount(SmartAttrCurrentPendingSector)
+}
+
+// OfflineUncorrectable returns the raw count of attribute 198 (Offline_Uncorrectable).
+func (a *AtaSmartData) OfflineUncorrectable() (int64, bool) {
+	return a.rawCount(SmartAttrOfflineUncorrectable)
+}
+
+// ReportedUncorrectable returns the raw count of attribute 187 (Reported_Uncorrect).
+func (a *AtaSmartData) ReportedUncorrectable() (int64, bool) {
+	return a.rawCount(SmartAttrReportedUncorrect)
+}
+
+// CommandTimeouts returns the raw count of attribute 188 (Command_Timeout).
+func (a *AtaSmartData) CommandTimeouts() (int64, bool) {
+	return a.rawCount(SmartAttrCommandTimeout)
+}
+
+// UDMACRCErrors returns the raw count of attribute 199 (UDMA_CRC_Error_Count).
+func (a *AtaSmartData) UDMACRCErrors() (int64, bool) {
+	return a.rawCount(SmartAttrUDMACRCErrorCount)
+}
+
+// Count returns the counter shown by smartctl for this raw value: the leading
+// integer of String when present, otherwise Value. This honours formats such
+// as raw16(raw16), where only the lowest word is the actual count and Value
+// also carries vendor data in the upper words.
+func (r Raw) Count() int64 {
+	field := strings.TrimSpace(r.String)
+	if end := strings.IndexFunc(field, func(c rune) bool { return c < '0' || c > '9' }); end >= 0 {
+		field = field[:end]
+	}
+	if n, err := strconv.ParseInt(field, 10, 64); err == nil {
+		return n
+	}
+	return r.Value
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Fallback reasons reported in CommandEvent.Fallback.
+const (
+	// FallbackDeviceType marks a retry with an explicit -d type, e.g. the SAT
+	// probe after the auto-detected protocol failed or the drivedb type of a
+	// USB bridge.
+	FallbackDeviceType = "device-type"
+	// FallbackScan marks the "smartctl --scan" run after --scan-open failed
+	// or found nothing.
+	FallbackScan = "scan"
+	// FallbackOpenRetry marks a retry of a busy device (WithOpenRetry).
+	FallbackOpenRetry = "open-retry"
+)
+
+// CommandEvent describes a finished smartctl invocation.
+type CommandEvent struct {
+	// Command is the smartctl binary and Args its arguments, without any
+	// elevation command.
+	Command string
+	Args    []string
+	// Device is the device the command addressed, or "" for commands such as
+	// --scan.
+	Device string
+	// Fallback is the reason the invocation was made instead of or after an
+	// earlier one, one of the Fallback constants, or "" for first attempts.
+	Fallback string
+	// Start is when smartctl was started and Duration its run time, excluding
+	// waits for the device lock and rate limit.
+	Start    time.Time
+	Duration time.Duration
+	// ExitCode is the smartctl exit status, or -1 when it could not be
+	// started or was killed.
+	ExitCode int
+	// Err is the error the invocation returned, e.g. a *SmartctlError.
+	Err error
+}
+
+// CommandHook is called after every smartctl invocation with the context of
+// the call, e.g. to attach a trace span to the caller's. It runs on the calling
+// goroutine and must be safe for concurrent use.
+type CommandHook func(context.Context, CommandEvent)
@@ -2,9 +2,29 @@ package types
 
 // SMART attribute IDs for SSD detection and wear-level computation.
 const (
+	SmartAttrRemainingLifetime = 169
 	SmartAttrSSDLifeUsed       = 173
 	SmartAttrWearLevelingCount = 177
+	SmartAttrPercentLifetime   = 202
 	SmartAttrSSDLifeLeft       = 231
 	SmartAttrSandForceInternal = 233
+	SmartAttrMediaWearout      = 233 // Intel's use of SandForce's internal counter
 	SmartAttrTotalLBAsWritten  = 234
+	SmartAttrHostWrites        = 241
+	SmartAttrHostSectorWrites  = 246
+)
+
+// SMART attribute IDs for common health and usage counters.
+const (
+	SmartAttrReallocatedSectors    = 5
+	SmartAttrPowerOnHours          = 9
+	SmartAttrPowerCycleCount       = 12
+	SmartAttrReportedUncorrect     = 187
+	SmartAttrCommandTimeout        = 188
+	SmartAttrAirflowTemperature    = 190
+	SmartAttrTemperatureCelsius    = 194
+	SmartAttrReallocatedEventCount = 196
+	SmartAttrCurrentPendingSector  = 197
+	SmartAttrOfflineUncorrectable  = 198
+	SmartAttrUDMACRCErrorCount     = 199
 )
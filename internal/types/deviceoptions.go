@@ -0,0 +1,36 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeviceOptions overrides backend-wide settings for one device, e.g. a USB
+// enclosure that needs its own device type and flags. Zero fields keep the
+// backend's settings.
+type DeviceOptions struct {
+	// Type is the smartctl -d device type, e.g. "sat" or "sntjmicron". It
+	// replaces auto-detection: the SAT and USB bridge probes are skipped.
+	Type string
+	// ExtraArgs are passed to every smartctl invocation for the device,
+	// before the device path, e.g. {"-F", "samsung3"}.
+	ExtraArgs []string
+	// Tolerance is the smartctl -T level of the device.
+	Tolerance Tolerance
+	// NoCheck is the --nocheck policy of the device; nil keeps the backend's.
+	NoCheck *NoCheckPolicy
+}
+
+// Validate reports an invalid tolerance, nocheck policy or device type.
+func (o DeviceOptions) Validate() error {
+	if strings.HasPrefix(o.Type, "-") {
+		return fmt.Errorf("invalid device type %q", o.Type)
+	}
+	if err := o.Tolerance.Validate(); err != nil {
+		return err
+	}
+	if o.NoCheck != nil {
+		return o.NoCheck.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SupportedJSONFormatMajor is the json_format_version major version the SMART
+// types are modeled on. smartctl bumps it only for incompatible schema changes.
+const SupportedJSONFormatMajor = 1
+
+// Sentinel errors returned (wrapped) by backends. Use errors.Is to test for them.
+var (
+	// ErrSmartNotSupported indicates the device does not provide SMART data.
+	ErrSmartNotSupported = errors.New("SMART Not Supported")
+	// ErrPermissionDenied indicates the device could not be opened for lack of privileges.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrDeviceNotFound indicates the device path does not exist or has no device behind it.
+	ErrDeviceNotFound = errors.New("device not found")
+	// ErrDeviceBusy indicates the device could not be opened because it is busy,
+	// for example right after hotplug or while another tool holds it open.
+	ErrDeviceBusy = errors.New("device busy")
+	// ErrDeviceInStandby indicates the device is in a low-power mode and was not
+	// woken up to answer the request.
+	ErrDeviceInStandby = errors.New("device in standby mode")
+	// ErrTestNotSupported indicates the device does not support the requested self-test.
+	ErrTestNotSupported = errors.New("self-test not supported")
+	// ErrSelfTestAborted indicates a self-test was aborted or interrupted
+	// before it finished, so it gave no verdict on the drive.
+	ErrSelfTestAborted = errors.New("self-test aborted")
+	// ErrFeatureNotSupported indicates the device does not implement the
+	// requested drive feature, such as a write cache setting.
+	ErrFeatureNotSupported = errors.New("feature not supported")
+	// ErrUnsupportedJSONFormat indicates smartctl printed a json_format_version
+	// whose major version this library does not understand.
+	ErrUnsupportedJSONFormat = errors.New("unsupported smartctl JSON format version")
+	// ErrVirtualDevice indicates the device is a virtual disk or LUN, such as
+	// an iSCSI target, that has no SMART data of its own. Errors matching it
+	// are *VirtualDeviceError and also match ErrSmartNotSupported.
+	ErrVirtualDevice = errors.New("SMART not applicable: virtual device")
+)
+
+// VirtualDeviceError reports a device recognized as virtual from its vendor
+// and model strings, e.g. vendor "LIO-ORG" for a Linux iSCSI target LUN, or
+// from its name, e.g. /dev/vda for a virtio disk, which leaves both empty.
+type VirtualDeviceError struct {
+	Device string
+	Vendor string
+	Model  string
+}
+
+func (e *VirtualDeviceError) Error() string {
+	msg := fmt.Sprintf("%s: %s", ErrVirtualDevice, e.Device)
+	if id := strings.TrimSpace(e.Vendor + " " + e.Model); id != "" {
+		msg += " (" + id + ")"
+	}
+	return msg
+}
+
+// Is makes errors.Is match ErrVirtualDevice and ErrSmartNotSupported.
+func (e *VirtualDeviceError) Is(target error) bool {
+	return target == ErrVirtualDevice || target == ErrSmartNotSupported
+}
+
+// ErrUnsupportedSnapshotVersion indicates a serialized snapshot was written
+// with a schema version newer than this library understands.
+var ErrUnsupportedSnapshotVersion = errors.New("unsupported snapshot schema version")
+
+// ClassifyOpenFailure maps smartctl's device open diagnostics (for example
+// "Smartctl open device: /dev/sdx failed: Permission denied") to
+// ErrPermissionDenied, ErrDeviceNotFound or ErrDeviceBusy. It returns nil when
+// output carries no recognizable open failure.
+func ClassifyOpenFailure(output string) error {
+	lower := strings.ToLower(output)
+	switch {
+	case strings.Contains(lower, "permission denied"), strings.Contains(lower, "operation not permitted"):
+		return ErrPermissionDenied
+	case strings.Contains(lower, "no such file or directory"), strings.Contains(lower, "no such device"):
+		return ErrDeviceNotFound
+	case strings.Contains(lower, "device or resource busy"), strings.Contains(lower, "resource temporarily unavailable"),
+		strings.Contains(lower, "device busy"):
+		return ErrDeviceBusy
+	}
+	return nil
+}
+
+// CheckJSONFormatVersion reports ErrUnsupportedJSONFormat, wrapped with the
+// offending version, when version has a major other than
+// SupportedJSONFormatMajor. An absent version is accepted.
+func CheckJSONFormatVersion(version []int) error {
+	if len(version) == 0 || version[0] == SupportedJSONFormatMajor {
+		return nil
+	}
+	v := fmt.Sprint(version[0])
+	for _, n := range version[1:] {
+		v += fmt.Sprintf(".%d", n)
+	}
+	return fmt.Errorf("%w %s (supported: %d.x)", ErrUnsupportedJSONFormat, v, SupportedJSONFormatMajor)
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExitStatus is the smartctl exit status, a bitmask in which each bit reports
+// a distinct condition (see the RETURN VALUES section of smartctl(8)).
+type ExitStatus int
+
+// Exit status bits.
+const (
+	ExitCommandLineError     ExitStatus = 1 << 0 // command line did not parse
+	ExitDeviceOpenFailed     ExitStatus = 1 << 1 // device open failed, or device in low-power mode with --nocheck
+	ExitCommandFailed        ExitStatus = 1 << 2 // a SMART or other ATA command to the disk failed, or checksum error
+	ExitDiskFailing          ExitStatus = 1 << 3 // SMART status check returned "DISK FAILING"
+	ExitPrefailAttributes    ExitStatus = 1 << 4 // pre-failure attributes found at or below threshold
+	ExitPastPrefailAttribute ExitStatus = 1 << 5 // attributes were at or below threshold in the past
+	ExitErrorLogged          ExitStatus = 1 << 6 // device error log contains records of errors
+	ExitSelfTestErrors       ExitStatus = 1 << 7 // self-test log contains records of errors
+
+	// ExitExecMask covers the execution failure bits 0–2.
+	ExitExecMask ExitStatus = 0x07
+	// ExitHealthMask covers the drive health bits 3–7.
+	ExitHealthMask ExitStatus = 0xF8
+)
+
+var exitStatusNames = []struct {
+	bit  ExitStatus
+	name string
+}{
+	{ExitCommandLineError, "command line error"},
+	{ExitDeviceOpenFailed, "device open failed"},
+	{ExitCommandFailed, "command failed"},
+	{ExitDiskFailing, "disk failing"},
+	{ExitPrefailAttributes, "prefail attributes at threshold"},
+	{ExitPastPrefailAttribute, "attributes at threshold in the past"},
+	{ExitErrorLogged, "errors logged"},
+	{ExitSelfTestErrors, "self-test errors logged"},
+}
+
+// Has reports whether all bits in flag are set.
+func (s ExitStatus) Has(flag ExitStatus) bool { return s&flag == flag }
+
+// CommandLineError reports bit 0: smartctl could not parse its command line.
+func (s ExitStatus) CommandLineError() bool { return s.Has(ExitCommandLineError) }
+
+// DeviceOpenFailed reports bit 1: the device could not be opened. With
+// --nocheck (standby by default), which the exec backend passes for ATA
+// devices, this bit also signals that the device is in a low-power mode and
+// was not woken.
+func (s ExitStatus) DeviceOpenFailed() bool { return s.Has(ExitDeviceOpenFailed) }
+
+// CommandFailed reports bit 2: a command to the disk failed or a SMART data
+// structure had a checksum error.
+func (s ExitStatus) CommandFailed() bool { return s.Has(ExitCommandFailed) }
+
+// DiskFailing reports bit 3: the SMART overall-health self-assessment failed.
+func (s ExitStatus) DiskFailing() bool { return s.Has(ExitDiskFailing) }
+
+// PrefailAttributes reports bit 4: pre-failure attributes are at or below threshold.
+func (s ExitStatus) PrefailAttributes() bool { return s.Has(ExitPrefailAttributes) }
+
+// PastPrefailAttributes reports bit 5: some attributes were at or below
+// threshold in the past.
+func (s ExitStatus) PastPrefailAttributes() bool { return s.Has(ExitPastPrefailAttribute) }
+
+// ErrorLogged reports bit 6: the device error log contains records of errors.
+func (s ExitStatus) ErrorLogged() bool { return s.Has(ExitErrorLogged) }
+
+// SelfTestErrors reports bit 7: the self-test log contains records of errors.
+func (s ExitStatus) SelfTestErrors() bool { return s.Has(ExitSelfTestErrors) }
+
+// ExecFailed reports whether any execution failure bit (0–2) is set, meaning
+// the returned data is incomplete or missing.
+func (s ExitStatus) ExecFailed() bool { return s&ExitExecMask != 0 }
+
+// HealthProblem reports whether any drive health bit (3–7) is set.
+func (s ExitStatus) HealthProblem() bool { return s&ExitHealthMask != 0 }
+
+// String lists the set bits, e.g. "0x48 (disk failing, errors logged)".
+func (s ExitStatus) String() string {
+	if s == 0 {
+		return "0x00"
+	}
+	var names []string
+	for _, n := range exitStatusNames {
+		if s.Has(n.bit) {
+			names = append(names, n.name)
+		}
+	}
+	return fmt.Sprintf("0x%02x (%s)", int(s), strings.Join(names, ", "))
+}
+
+// ExitStatus returns the smartctl exit status reported in the JSON output,
+// or zero when it is unavailable.
+func (s *SMARTInfo) ExitStatus() ExitStatus {
+	if s == nil || s.Smartctl == nil {
+		return 0
+	}
+	return ExitStatus(s.Smartctl.ExitStatus)
+}
+
+// Status recombines the execution and health bits into an ExitStatus.
+func (e *ExitCodeInfo) Status() ExitStatus {
+	if e == nil {
+		return 0
+	}
+	return ExitStatus(e.ExecBits | e.HealthBits)
+}
+
+// SmartctlError reports a smartctl invocation that exited with a non-zero
+// status. Use errors.As to inspect Status instead of matching error strings;
+// the underlying *exec.ExitError remains reachable through Unwrap.
+type SmartctlError struct {
+	Status ExitStatus
+	// Stderr holds diagnostics smartctl (or an elevation tool) wrote to stderr.
+	Stderr []byte
+	Err    error
+}
+
+func (e *SmartctlError) Error() string {
+	msg := fmt.Sprintf("smartctl exit status %s", e.Status)
+	if stderr := strings.TrimSpace(string(e.Stderr)); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+func (e *SmartctlError) Unwrap() error { return e.Err }
@@ -0,0 +1,202 @@
+package types
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ExtendedSections holds the sections that "smartctl -x" adds to the "-a"
+// output. Sections the device does not support are nil.
+type ExtendedSections struct {
+	AtaSctStatus         *AtaSctStatus         `json:"ata_sct_status,omitempty"`
+	SataPhyEventCounters *SataPhyEventCounters `json:"sata_phy_event_counters,omitempty"`
+	AtaDeviceStatistics  *AtaDeviceStatistics  `json:"ata_device_statistics,omitempty"`
+	AtaLogDirectory      *AtaLogDirectory      `json:"ata_log_directory,omitempty"`
+}
+
+// ExtendedSMARTInfo is the output of "smartctl -x": everything SMARTInfo
+// covers plus the ExtendedSections. Extra holds only the keys neither models.
+type ExtendedSMARTInfo struct {
+	SMARTInfo
+	ExtendedSections
+}
+
+// AtaSctStatus is the SCT status of an ATA device.
+type AtaSctStatus struct {
+	FormatVersion  int             `json:"format_version"`
+	SctVersion     int             `json:"sct_version"`
+	DeviceState    *SctDeviceState `json:"device_state,omitempty"`
+	Temperature    *SctTemperature `json:"temperature,omitempty"`
+	SmartStatus    *SctSmartStatus `json:"smart_status,omitempty"`
+	VendorSpecific []int           `json:"vendor_specific,omitempty"`
+}
+
+// SctDeviceState is the device activity state reported in the SCT status.
+type SctDeviceState struct {
+	Value  int    `json:"value"`
+	String string `json:"string"`
+}
+
+// SctTemperature holds the SCT status temperatures in Celsius.
+type SctTemperature struct {
+	Current                   int `json:"current"`
+	PowerCycleMin             int `json:"power_cycle_min"`
+	PowerCycleMax             int `json:"power_cycle_max"`
+	LifetimeMin               int `json:"lifetime_min"`
+	LifetimeMax               int `json:"lifetime_max"`
+	OpLimitMax                int `json:"op_limit_max"`
+	LimitMin                  int `json:"limit_min"`
+	LimitMax                  int `json:"limit_max"`
+	LifetimeOverLimitMinutes  int `json:"lifetime_over_limit_minutes"`
+	LifetimeUnderLimitMinutes int `json:"lifetime_under_limit_minutes"`
+}
+
+// SctSmartStatus is the SMART status field of the SCT status.
+type SctSmartStatus struct {
+	Value  int    `json:"value"`
+	String string `json:"string"`
+}
+
+// SataPhyEventCounters is the SATA Phy event counters log.
+type SataPhyEventCounters struct {
+	Table []SataPhyEventCounter `json:"table"`
+	Reset bool                  `json:"reset"`
+}
+
+// SataPhyEventCounter is a single SATA Phy event counter.
+type SataPhyEventCounter struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Size     int    `json:"size"`
+	Value    int64  `json:"value"`
+	Overflow bool   `json:"overflow"`
+}
+
+// AtaDeviceStatistics is the ATA device statistics log (GP log 0x04).
+type AtaDeviceStatistics struct {
+	Pages []DeviceStatisticsPage `json:"pages"`
+}
+
+// DeviceStatisticsPage is one page of the device statistics log.
+type DeviceStatisticsPage struct {
+	Number   int               `json:"number"`
+	Name     string            `json:"name"`
+	Revision int               `json:"revision"`
+	Table    []DeviceStatistic `json:"table"`
+}
+
+// DeviceStatistic is a single entry of a device statistics page.
+type DeviceStatistic struct {
+	Offset int                  `json:"offset"`
+	Name   string               `json:"name"`
+	Size   int                  `json:"size"`
+	Value  int64                `json:"value"`
+	Flags  DeviceStatisticFlags `json:"flags"`
+}
+
+// DeviceStatisticFlags are the flags of a device statistic.
+type DeviceStatisticFlags struct {
+	Value                 int    `json:"value"`
+	String                string `json:"string"`
+	Valid                 bool   `json:"valid"`
+	Normalized            bool   `json:"normalized"`
+	SupportsDSN           bool   `json:"supports_dsn"`
+	MonitoredConditionMet bool   `json:"monitored_condition_met"`
+}
+
+// AtaLogDirectory is the ATA general purpose and SMART log directory.
+type AtaLogDirectory struct {
+	GpDirVersion        int                 `json:"gp_dir_version"`
+	SmartDirVersion     int                 `json:"smart_dir_version"`
+	SmartDirMultiSector bool                `json:"smart_dir_multi_sector"`
+	Table               []LogDirectoryEntry `json:"table"`
+}
+
+// LogDirectoryEntry describes one log address in the log directory.
+type LogDirectoryEntry struct {
+	Address      int    `json:"address"`
+	Name         string `json:"name"`
+	Read         bool   `json:"read"`
+	Write        bool   `json:"write"`
+	GpSectors    int    `json:"gp_sectors,omitempty"`
+	SmartSectors int    `json:"smart_sectors,omitempty"`
+}
+
+// extendedSectionKeys returns the JSON keys modeled by ExtendedSections.
+var extendedSectionKeys = sync.OnceValue(func() []string {
+	var keys []string
+	t := reflect.TypeFor[ExtendedSections]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		keys = append(keys, name)
+	}
+	return keys
+})
+
+// NewExtendedSMARTInfo combines an already parsed SMARTInfo with the extended
+// sections decoded from raw, the "smartctl -x -j" document it came from.
+func NewExtendedSMARTInfo(info *SMARTInfo, raw []byte) (*ExtendedSMARTInfo, error) {
+	ext := &ExtendedSMARTInfo{}
+	if info != nil {
+		ext.SMARTInfo = *info
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &ext.ExtendedSections); err != nil {
+			return ext, err
+		}
+	}
+	ext.dropSectionKeys()
+	return ext, nil
+}
+
+// dropSectionKeys removes the keys of ExtendedSections from Extra without
+// modifying a map shared with the SMARTInfo it was copied from.
+func (e *ExtendedSMARTInfo) dropSectionKeys() {
+	if e.Extra == nil {
+		return
+	}
+	extra := maps.Clone(e.Extra)
+	for _, key := range extendedSectionKeys() {
+		delete(extra, key)
+	}
+	e.Extra = nil
+	if len(extra) > 0 {
+		e.Extra = extra
+	}
+}
+
+// UnmarshalJSON decodes "smartctl -x" output.
+func (e *ExtendedSMARTInfo) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &e.SMARTInfo); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &e.ExtendedSections); err != nil {
+		return err
+	}
+	e.dropSectionKeys()
+	return nil
+}
+
+// MarshalJSON encodes the SMARTInfo fields, the extended sections and Extra.
+func (e ExtendedSMARTInfo) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(e.ExtendedSections)
+	if err != nil {
+		return nil, err
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, err
+	}
+	info := e.SMARTInfo
+	if len(sections) > 0 {
+		info.Extra = maps.Clone(e.Extra)
+		if info.Extra == nil {
+			info.Extra = make(map[string]json.RawMessage, len(sections))
+		}
+		maps.Copy(info.Extra, sections)
+	}
+	return json.Marshal(info)
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// smartInfoKeys returns the JSON keys modeled by SMARTInfo fields.
+var smartInfoKeys = sync.OnceValue(func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeFor[SMARTInfo]()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		keys[name] = true
+	}
+	return keys
+})
+
+// smartInfoFields has SMARTInfo's fields without its JSON methods.
+type smartInfoFields SMARTInfo
+
+// UnmarshalJSON decodes smartctl output, keeping unmodeled top-level keys in Extra.
+func (s *SMARTInfo) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*smartInfoFields)(s)); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	known := smartInfoKeys()
+	for key := range all {
+		if known[key] {
+			delete(all, key)
+		}
+	}
+	s.Extra = nil
+	if len(all) > 0 {
+		s.Extra = all
+	}
+	return nil
+}
+
+// MarshalJSON encodes the modeled fields and merges Extra back in. Modeled
+// fields take precedence over Extra entries with the same key.
+func (s SMARTInfo) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(smartInfoFields(s))
+	if err != nil || len(s.Extra) == 0 {
+		return data, err
+	}
+	merged := make(map[string]json.RawMessage, len(s.Extra)+16)
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range s.Extra {
+		if _, ok := merged[key]; !ok {
+			merged[key] = value
+		}
+	}
+	return json.Marshal(merged)
+}
@@ -0,0 +1,106 @@
+package types
+
+import "fmt"
+
+// WWN is the World Wide Name of a device, as reported by smartctl's "wwn"
+// key: a 4-bit Network Address Authority, a 24-bit IEEE OUI and a 36-bit
+// vendor-assigned ID.
+type WWN struct {
+	NAA int    `json:"naa"`
+	OUI uint64 `json:"oui"`
+	ID  uint64 `json:"id"`
+}
+
+// String formats the WWN as a 64-bit hex number, e.g. "0x50026b77560145cf",
+// the form used by /dev/disk/by-id/wwn-* links. smartctl prints the same WWN
+// as "5 0026b7 7560145cf".
+func (w WWN) String() string {
+	return fmt.Sprintf("0x%x%06x%09x", w.NAA, w.OUI, w.ID)
+}
+
+// FormFactor is the nominal form factor of a drive. Name is smartctl's
+// description, e.g. "2.5 inches"; AtaValue or ScsiValue is the raw code from
+// the IDENTIFY data or the Block Device Characteristics VPD page.
+type FormFactor struct {
+	AtaValue  *int   `json:"ata_value,omitempty"`
+	ScsiValue *int   `json:"scsi_value,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// AtaVersion is the ATA standard a drive claims to support. MajorValue and
+// MinorValue are IDENTIFY words 80 and 81.
+type AtaVersion struct {
+	String     string `json:"string"`
+	MajorValue int    `json:"major_value"`
+	MinorValue int    `json:"minor_value"`
+}
+
+// SataVersion is the SATA revision a drive claims to support, e.g. "SATA 3.3".
+// Value is IDENTIFY word 222.
+type SataVersion struct {
+	String string `json:"string"`
+	Value  int    `json:"value"`
+}
+
+// InterfaceSpeed holds the highest link speed a SATA drive supports and the
+// speed it negotiated. Current is nil when the drive does not report it.
+type InterfaceSpeed struct {
+	Max     *LinkSpeed `json:"max,omitempty"`
+	Current *LinkSpeed `json:"current,omitempty"`
+}
+
+// Degraded reports whether the link negotiated a lower speed than the drive
+// supports, e.g. 3.0 Gb/s on a 6.0 Gb/s drive. On a host port capable of the
+// full speed this usually points to a bad cable or backplane. It is false when
+// either speed is unknown, including on nil receivers.
+func (s *InterfaceSpeed) Degraded() bool {
+	if s == nil {
+		return false
+	}
+	current, supported := s.Current.BitsPerSecond(), s.Max.BitsPerSecond()
+	return current > 0 && current < supported
+}
+
+// LinkSpeed is a SATA link speed, e.g. "6.0 Gb/s". SataValue is the raw
+// speed field from the IDENTIFY data.
+type LinkSpeed struct {
+	SataValue      int    `json:"sata_value"`
+	String         string `json:"string"`
+	UnitsPerSecond int64  `json:"units_per_second"`
+	BitsPerUnit    int64  `json:"bits_per_unit"`
+}
+
+// BitsPerSecond returns the link speed in bits per second, or 0 when unknown.
+func (s *LinkSpeed) BitsPerSecond() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.UnitsPerSecond * s.BitsPerUnit
+}
+
+// NvmeNamespace is one entry of smartctl's "nvme_namespaces" key. Size is the
+// namespace size, Capacity the part that may be allocated and Utilization the
+// part currently allocated; drives without thin provisioning usually report
+// the same value for all three.
+type NvmeNamespace struct {
+	ID               int           `json:"id"`
+	Size             *UserCapacity `json:"size,omitempty"`
+	Capacity         *UserCapacity `json:"capacity,omitempty"`
+	Utilization      *UserCapacity `json:"utilization,omitempty"`
+	FormattedLBASize int           `json:"formatted_lba_size,omitempty"`
+	EUI64            *EUI64        `json:"eui64,omitempty"`
+}
+
+// EUI64 is the IEEE Extended Unique Identifier of an NVMe namespace: a 24-bit
+// OUI followed by a 40-bit vendor extension.
+type EUI64 struct {
+	OUI   uint64 `json:"oui"`
+	ExtID uint64 `json:"ext_id"`
+}
+
+// String formats the EUI-64 as 16 hex digits, e.g. "0025385281b12345", the
+// form used by /dev/disk/by-id/nvme-eui.* links. smartctl prints the same
+// identifier as "002538 5281b12345".
+func (e EUI64) String() string {
+	return fmt.Sprintf("%06x%010x", e.OUI, e.ExtID)
+}
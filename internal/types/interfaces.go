@@ -1,6 +1,10 @@
 package types
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
 
 // LogAdapter captures the logging methods used by this package.
 // It is satisfied by both *slog.Logger and *tlog.Logger.
@@ -34,12 +38,123 @@ type DiscoveryBackend interface {
 	DiscoverDevices(ctx context.Context) ([]DiscoveryResult, error)
 }
 
+// ScanBackend is an optional extension of Backend that restricts device
+// scans to given device types and interfaces.
+type ScanBackend interface {
+	Backend
+	ScanDevicesWithOptions(ctx context.Context, opts ScanOptions) ([]Device, error)
+}
+
+// RawBackend is an optional extension of Backend that returns the original
+// smartctl JSON document together with the parsed SMARTInfo.
+type RawBackend interface {
+	Backend
+	GetSMARTInfoRaw(ctx context.Context, devicePath string) (*SMARTInfo, json.RawMessage, error)
+}
+
+// SmartctlBackend is an optional extension of Backend that runs arbitrary
+// smartctl commands and returns their JSON output and exit status.
+type SmartctlBackend interface {
+	Backend
+	RunSmartctl(ctx context.Context, args ...string) (json.RawMessage, *ExitStatus, error)
+}
+
+// VersionBackend is an optional extension of Backend that reports the
+// smartctl version it runs.
+type VersionBackend interface {
+	Backend
+	SmartctlVersion(ctx context.Context) (*SmartctlVersionInfo, error)
+}
+
+// ExtendedBackend is an optional extension of Backend that returns the
+// extended "smartctl -x" information.
+type ExtendedBackend interface {
+	Backend
+	GetExtendedSMARTInfo(ctx context.Context, devicePath string) (*ExtendedSMARTInfo, error)
+}
+
+// PowerModeBackend is an optional extension of Backend for drive power
+// management: it reports the power mode of a drive without waking it,
+// controls spindown and wakes drives on request.
+type PowerModeBackend interface {
+	Backend
+	GetPowerMode(ctx context.Context, devicePath string) (PowerMode, error)
+	SetStandbyTimer(ctx context.Context, devicePath string, timeout time.Duration) error
+	StandbyNow(ctx context.Context, devicePath string) error
+	WakeDevice(ctx context.Context, devicePath string) error
+}
+
+// SelfTestBackend is an optional extension of Backend that runs self-tests
+// with SelfTestOptions and selective self-tests over LBA spans.
+type SelfTestBackend interface {
+	Backend
+	RunSelfTestWithOptions(ctx context.Context, devicePath string, testType string, opts SelfTestOptions) error
+	RunSelectiveSelfTest(ctx context.Context, devicePath string, spans []LBASpan) error
+	RunSelectiveSelfTestWithOptions(ctx context.Context, devicePath string, spans []LBASpan, opts SelectiveSelfTestOptions) error
+	GetSelectiveSelfTestLog(ctx context.Context, devicePath string) (*SelectiveSelfTestLog, error)
+}
+
+// FeatureBackend is an optional extension of Backend that reads and changes
+// drive feature settings such as the volatile write cache and DSN.
+type FeatureBackend interface {
+	Backend
+	GetWriteCache(ctx context.Context, devicePath string) (bool, error)
+	SetWriteCache(ctx context.Context, devicePath string, enabled bool) error
+	GetWriteCacheReorder(ctx context.Context, devicePath string) (bool, error)
+	SetWriteCacheReorder(ctx context.Context, devicePath string, enabled bool) error
+	GetDSN(ctx context.Context, devicePath string) (bool, error)
+	SetDSN(ctx context.Context, devicePath string, enabled bool) error
+	EnableAttributeAutosave(ctx context.Context, devicePath string) error
+	DisableAttributeAutosave(ctx context.Context, devicePath string) error
+	EnableAutoOfflineCollection(ctx context.Context, devicePath string) error
+	DisableAutoOfflineCollection(ctx context.Context, devicePath string) error
+	SecurityFreeze(ctx context.Context, devicePath string) error
+	GetSecureEraseInfo(ctx context.Context, devicePath string) (*SecureEraseInfo, error)
+}
+
+// CommandRequest describes a single external command invocation.
+type CommandRequest struct {
+	Name string
+	Args []string
+	// Env lists extra "KEY=value" entries appended to the inherited environment.
+	Env []string
+	// Timeout bounds the run time of the command; the process is killed when it
+	// expires. Zero means the command is limited only by its context.
+	Timeout time.Duration
+}
+
+// CommandResult holds the separately captured output of a finished command.
+type CommandResult struct {
+	Stdout []byte
+	Stderr []byte
+	// ExitCode is the process exit status, or -1 when the process could not be
+	// started or was killed.
+	ExitCode int
+}
+
+// ContextCommander executes external commands with context cancellation,
+// per-command timeouts, environment overrides and separate stdout/stderr
+// capture. It supersedes Commander.
+//
+// CommandContext always returns a non-nil result. A non-zero exit status is
+// reported as an error wrapping *exec.ExitError, with the output captured so
+// far still available in the result.
+type ContextCommander interface {
+	CommandContext(ctx context.Context, logger LogAdapter, req CommandRequest) (*CommandResult, error)
+}
+
 // Commander is the interface for executing OS commands.
+//
+// Deprecated: Implement ContextCommander instead. Commanders are still
+// accepted and adapted, but cannot honour environment overrides and lose
+// stderr for commands that succeed.
 type Commander interface {
 	Command(ctx context.Context, logger LogAdapter, name string, arg ...string) Cmd
 }
 
 // Cmd is the interface for a running command.
+//
+// Deprecated: Used only by the deprecated Commander interface.
 type Cmd interface {
 	Output() ([]byte, error)
 	Run() error
@@ -0,0 +1,82 @@
+package types
+
+import "strings"
+
+// Trim describes a drive's support for TRIM (ATA DATA SET MANAGEMENT) or SCSI
+// UNMAP. Deterministic drives return the same data for a trimmed LBA on every
+// read; Zeroed drives return zeros.
+type Trim struct {
+	Supported     bool `json:"supported"`
+	Deterministic bool `json:"deterministic,omitempty"`
+	Zeroed        bool `json:"zeroed,omitempty"`
+}
+
+// ZonedDevice is smartctl's "zoned_device" key, reporting the zoned
+// capabilities of an ATA (IDENTIFY word 69) or SCSI (Block Device
+// Characteristics VPD page) drive.
+type ZonedDevice struct {
+	Capabilities string `json:"capabilities"`
+}
+
+// ScsiDeviceType is the SCSI peripheral device type from the INQUIRY data,
+// e.g. 0 for a disk or 0x14 for a host-managed zoned block device.
+type ScsiDeviceType struct {
+	ScsiTerminology string `json:"scsi_terminology,omitempty"`
+	ScsiValue       int    `json:"scsi_value"`
+	Name            string `json:"name,omitempty"`
+}
+
+// scsiHostManagedZoned is the peripheral device type of ZBC host-managed
+// drives.
+const scsiHostManagedZoned = 0x14
+
+// ZonedModel is the zoned block model of a drive.
+type ZonedModel string
+
+// Zoned block models.
+const (
+	// ZonedNone is a conventional drive, or one that does not report being
+	// zoned.
+	ZonedNone ZonedModel = "none"
+	// ZonedDeviceManaged is a drive that manages its zones itself, such as a
+	// drive-managed SMR disk; it accepts random writes at a performance cost.
+	ZonedDeviceManaged ZonedModel = "device-managed"
+	// ZonedHostAware is a drive that accepts random writes but performs best
+	// when the host writes zones sequentially.
+	ZonedHostAware ZonedModel = "host-aware"
+	// ZonedHostManaged is a drive that only accepts sequential writes within
+	// a zone; it needs a zone-aware file system or device mapper.
+	ZonedHostManaged ZonedModel = "host-managed"
+)
+
+// ZonedModel returns the zoned block model the drive reports, from the SCSI
+// peripheral device type or smartctl's zoned_device capabilities.
+//
+// Many drive-managed SMR disks do not advertise it, so ZonedNone does not
+// prove a drive uses conventional magnetic recording.
+func (s *SMARTInfo) ZonedModel() ZonedModel {
+	if s.ScsiDeviceType != nil && s.ScsiDeviceType.ScsiValue == scsiHostManagedZoned {
+		return ZonedHostManaged
+	}
+	if s.ZonedDevice == nil {
+		return ZonedNone
+	}
+	capabilities := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(s.ZonedDevice.Capabilities))
+	switch {
+	case strings.Contains(capabilities, "hostmanaged"):
+		return ZonedHostManaged
+	case strings.Contains(capabilities, "hostaware"):
+		return ZonedHostAware
+	case strings.Contains(capabilities, "devicemanaged"):
+		return ZonedDeviceManaged
+	default:
+		return ZonedNone
+	}
+}
+
+// Is512e reports whether the drive has 4096-byte physical sectors behind a
+// 512-byte logical interface (Advanced Format 512e), so writes not aligned to
+// 4 KiB incur a read-modify-write. It is false when the sizes are unknown.
+func (s *SMARTInfo) Is512e() bool {
+	return s.LogicalBlockSize == 512 && s.PhysicalBlockSize > s.LogicalBlockSize
+}
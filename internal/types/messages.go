@@ -0,0 +1,84 @@
+package types
+
+import "strings"
+
+// WarningCode classifies a message smartctl printed with its output.
+type WarningCode string
+
+// Warning codes of smartctl messages.
+const (
+	// WarningUnknownUSBBridge means the device sits behind a USB bridge
+	// smartctl does not know, e.g. "Unknown USB bridge [0x152d:0x578e
+	// (0x209)]". "-d sat" usually reaches the drive anyway.
+	WarningUnknownUSBBridge WarningCode = "unknown_usb_bridge"
+	// WarningUnsupportedDevice means smartctl could not detect the device
+	// type or does not support the device: "Unable to detect device type",
+	// "SMART support is: Unavailable - device lacks SMART capability" or
+	// "Device not supported". Unsupported commands or log pages of a
+	// supported device are WarningOther.
+	WarningUnsupportedDevice WarningCode = "unsupported_device"
+	// WarningPermissionDenied means smartctl lacked the privileges to open the
+	// device, e.g. "Smartctl open device: /dev/sda failed: Permission denied".
+	WarningPermissionDenied WarningCode = "permission_denied"
+	// WarningMandatoryCommandFailed means a mandatory SMART command failed,
+	// e.g. "A mandatory SMART command failed: exiting. To continue, add one
+	// or more '-T permissive' options.". See Tolerance.
+	WarningMandatoryCommandFailed WarningCode = "mandatory_command_failed"
+	// WarningOther is any message without a more specific code.
+	WarningOther WarningCode = "other"
+)
+
+// Warning is a smartctl message with its WarningCode.
+type Warning struct {
+	Code     WarningCode `json:"code"`
+	Severity string      `json:"severity,omitempty"` // "information", "warning" or "error", as printed by smartctl
+	Message  string      `json:"message"`
+}
+
+// ClassifyMessage returns the WarningCode of a smartctl message text.
+func ClassifyMessage(text string) WarningCode {
+	lower := strings.ToLower(text)
+	switch {
+	case strings.Contains(lower, "unknown usb bridge"):
+		return WarningUnknownUSBBridge
+	case strings.Contains(lower, "mandatory smart command failed"), strings.Contains(lower, "mandatory command failed"):
+		return WarningMandatoryCommandFailed
+	case strings.Contains(lower, "permission denied"), strings.Contains(lower, "operation not permitted"):
+		return WarningPermissionDenied
+	case strings.Contains(lower, "unable to detect device type"), strings.Contains(lower, "lacks smart capability"),
+		strings.Contains(lower, "device not supported"):
+		return WarningUnsupportedDevice
+	}
+	return WarningOther
+}
+
+// Code returns the WarningCode of the message.
+func (m Message) Code() WarningCode {
+	return ClassifyMessage(m.String)
+}
+
+// ClassifyMessages returns the messages of s as Warnings, in the order
+// smartctl printed them, or nil when there are none.
+func ClassifyMessages(s *SmartctlInfo) []Warning {
+	if s == nil || len(s.Messages) == 0 {
+		return nil
+	}
+	warnings := make([]Warning, 0, len(s.Messages))
+	for _, msg := range s.Messages {
+		warnings = append(warnings, Warning{Code: msg.Code(), Severity: msg.Severity, Message: msg.String})
+	}
+	return warnings
+}
+
+// HasWarning reports whether Warnings include code.
+func (s *SMARTInfo) HasWarning(code WarningCode) bool {
+	if s == nil {
+		return false
+	}
+	for _, w := range s.Warnings {
+		if w.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NoCheckMode is the smartctl --nocheck power mode: a drive in this mode or a
+// lower one is skipped instead of being woken up.
+type NoCheckMode string
+
+// NoCheck modes, from always waking the drive to skipping it the most often.
+const (
+	NoCheckNever   NoCheckMode = "never"   // always query, waking the drive if needed
+	NoCheckSleep   NoCheckMode = "sleep"   // skip drives in sleep mode
+	NoCheckStandby NoCheckMode = "standby" // skip drives in sleep or standby mode (the default)
+	NoCheckIdle    NoCheckMode = "idle"    // skip drives in sleep, standby or idle mode
+)
+
+// NoCheckPolicy controls whether SMART queries wake drives in a low-power mode.
+// The zero value is the default: skip drives in standby, indefinitely.
+type NoCheckPolicy struct {
+	Mode NoCheckMode
+	// MaxSkips, like the N of smartd's "-n standby,N", bounds how many
+	// consecutive queries of a device may be skipped; the next one wakes the
+	// drive. Zero skips indefinitely.
+	MaxSkips int
+}
+
+// DefaultNoCheckPolicy skips drives in standby and never wakes them.
+var DefaultNoCheckPolicy = NoCheckPolicy{Mode: NoCheckStandby}
+
+// ParseNoCheckPolicy parses "never", "sleep", "standby", "idle" or the
+// skip-count form "standby,N".
+func ParseNoCheckPolicy(s string) (NoCheckPolicy, error) {
+	mode, count, hasCount := strings.Cut(strings.TrimSpace(s), ",")
+	p := NoCheckPolicy{Mode: NoCheckMode(strings.ToLower(mode))}
+	if hasCount {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return NoCheckPolicy{}, fmt.Errorf("invalid nocheck skip count %q", count)
+		}
+		p.MaxSkips = n
+	}
+	if err := p.Validate(); err != nil {
+		return NoCheckPolicy{}, err
+	}
+	return p, nil
+}
+
+// Validate reports an unknown mode or a negative skip count.
+func (p NoCheckPolicy) Validate() error {
+	switch p.Mode {
+	case "", NoCheckNever, NoCheckSleep, NoCheckStandby, NoCheckIdle:
+	default:
+		return fmt.Errorf("invalid nocheck mode %q (must be one of: never, sleep, standby, idle)", p.Mode)
+	}
+	if p.MaxSkips < 0 {
+		return fmt.Errorf("invalid nocheck skip count %d", p.MaxSkips)
+	}
+	return nil
+}
+
+// Effective returns the mode to pass to smartctl, mapping the zero value to
+// standby.
+func (p NoCheckPolicy) Effective() NoCheckMode {
+	if p.Mode == "" {
+		return NoCheckStandby
+	}
+	return p.Mode
+}
+
+// String returns the policy in ParseNoCheckPolicy form, e.g. "standby,3".
+func (p NoCheckPolicy) String() string {
+	if p.MaxSkips > 0 {
+		return fmt.Sprintf("%s,%d", p.Effective(), p.MaxSkips)
+	}
+	return string(p.Effective())
+}
+
+type noCheckKey struct{}
+
+// ContextWithNoCheck returns a context that overrides the backend's
+// NoCheckPolicy for the calls made with it.
+func ContextWithNoCheck(ctx context.Context, p NoCheckPolicy) context.Context {
+	return context.WithValue(ctx, noCheckKey{}, p)
+}
+
+// NoCheckFromContext returns the policy set by ContextWithNoCheck.
+func NoCheckFromContext(ctx context.Context) (NoCheckPolicy, bool) {
+	if ctx == nil {
+		return NoCheckPolicy{}, false
+	}
+	p, ok := ctx.Value(noCheckKey{}).(NoCheckPolicy)
+	return p, ok
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PowerMode is the ATA power mode of a drive as reported by smartctl.
+type PowerMode string
+
+// Power modes, from most to least awake.
+const (
+	PowerModeActive  PowerMode = "ACTIVE"
+	PowerModeIdle    PowerMode = "IDLE"
+	PowerModeStandby PowerMode = "STANDBY"
+	PowerModeSleep   PowerMode = "SLEEP"
+	// PowerModeUnknown is reported for devices without ATA power management,
+	// such as most NVMe drives.
+	PowerModeUnknown PowerMode = "UNKNOWN"
+)
+
+// Awake reports whether a drive in this mode answers SMART queries without
+// spinning up first.
+func (m PowerMode) Awake() bool {
+	return m == PowerModeActive || m == PowerModeIdle
+}
+
+// ParsePowerMode maps a smartctl power mode name such as "ACTIVE or IDLE",
+// "IDLE_B", "STANDBY (OS)" or "STANDBY_Y" to a PowerMode.
+func ParsePowerMode(name string) PowerMode {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	switch {
+	case strings.HasPrefix(name, "ACTIVE"):
+		return PowerModeActive
+	case strings.HasPrefix(name, "IDLE"):
+		return PowerModeIdle
+	case strings.HasPrefix(name, "STANDBY"):
+		return PowerModeStandby
+	case strings.HasPrefix(name, "SLEEP"):
+		return PowerModeSleep
+	default:
+		return PowerModeUnknown
+	}
+}
+
+// MaxStandbyTimer is the longest standby timer ATA can express.
+const MaxStandbyTimer = 330 * time.Minute
+
+// StandbyTimerValue converts a spindown timeout into the ATA standby timer
+// value passed to "smartctl -s standby,N", rounding up to the next timeout the
+// encoding can express: 5 second steps up to 20 minutes, 21 minutes, then 30
+// minute steps up to MaxStandbyTimer. Zero disables the timer.
+func StandbyTimerValue(timeout time.Duration) (int, error) {
+	switch {
+	case timeout < 0 || timeout > MaxStandbyTimer:
+		return 0, fmt.Errorf("invalid standby timer %s (must be between 0 and %s)", timeout, MaxStandbyTimer)
+	case timeout == 0:
+		return 0, nil
+	case timeout <= 20*time.Minute:
+		return int((timeout + 5*time.Second - 1) / (5 * time.Second)), nil
+	case timeout <= 21*time.Minute:
+		return 252, nil
+	default:
+		return 240 + int((timeout+30*time.Minute-1)/(30*time.Minute)), nil
+	}
+}
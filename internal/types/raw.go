@@ -0,0 +1,123 @@
+package types
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RawTemperature is a decoded temperature attribute raw string, such as
+// "29 (Min/Max 18/56)" reported for attributes 190 and 194.
+type RawTemperature struct {
+	Current int  `json:"current"`
+	Min     *int `json:"min,omitempty"`
+	Max     *int `json:"max,omitempty"`
+}
+
+var (
+	rawTemperaturePattern = regexp.MustCompile(`^\s*(-?\d+)(?:\s*\((?:.*?Min/Max\s+(-?\d+)\s*/\s*(-?\d+))?.*\))?\s*$`)
+	rawDurationPattern    = regexp.MustCompile(`^\s*(\d+)h(?:\+(\d+)m)?(?:\+(\d+(?:\.\d+)?)s)?`)
+)
+
+// ParseRawTemperature decodes a temperature raw string. Accepted forms are a
+// bare value ("34"), the tempminmax form ("29 (Min/Max 18/56)") and values
+// followed by any other parenthesized vendor detail ("36 (0 21 0 0 0)"), in
+// which case Min and Max are nil. It returns false when no leading integer is found.
+func ParseRawTemperature(raw string) (RawTemperature, bool) {
+	m := rawTemperaturePattern.FindStringSubmatch(raw)
+	if m == nil {
+		return RawTemperature{}, false
+	}
+	current, err := strconv.Atoi(m[1])
+	if err != nil {
+		return RawTemperature{}, false
+	}
+	t := RawTemperature{Current: current}
+	if m[2] != "" && m[3] != "" {
+		lo, errLo := strconv.Atoi(m[2])
+		hi, errHi := strconv.Atoi(m[3])
+		if errLo == nil && errHi == nil {
+			t.Min, t.Max = &lo, &hi
+		}
+	}
+	return t, true
+}
+
+// maxRawHours bounds the hour counts ParseRawDuration accepts, so that any
+// minutes and seconds can still be added without overflowing time.Duration.
+const maxRawHours = int64(math.MaxInt64/time.Hour) - 1
+
+// ParseRawDuration decodes a power-on style raw string into a duration.
+// It understands the msec24hour32 form "35825h+02m+39.040s", its shorter
+// variants ("35825h+02m", "35825h") and a bare integer hour count ("35825",
+// optionally followed by a parenthesized vendor detail). Values a
+// time.Duration cannot hold, and minutes or seconds of 60 or more, are
+// rejected.
+func ParseRawDuration(raw string) (time.Duration, bool) {
+	if m := rawDurationPattern.FindStringSubmatch(raw); m != nil {
+		hours, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || hours > maxRawHours {
+			return 0, false
+		}
+		d := time.Duration(hours) * time.Hour
+		if m[2] != "" {
+			minutes, err := strconv.ParseInt(m[2], 10, 64)
+			if err != nil || minutes >= 60 {
+				return 0, false
+			}
+			d += time.Duration(minutes) * time.Minute
+		}
+		if m[3] != "" {
+			seconds, err := strconv.ParseFloat(m[3], 64)
+			if err != nil || seconds >= 60 {
+				return 0, false
+			}
+			d += time.Duration(seconds * float64(time.Second))
+		}
+		return d, true
+	}
+	field, _, _ := strings.Cut(strings.TrimSpace(raw), " ")
+	hours, err := strconv.ParseInt(field, 10, 64)
+	if err != nil || hours < 0 || hours > maxRawHours {
+		return 0, false
+	}
+	return time.Duration(hours) * time.Hour, true
+}
+
+// RawWords16 splits a 48-bit raw value into three 16-bit words, least
+// significant first, as smartctl does for the raw16 format.
+func RawWords16(value int64) [3]uint16 {
+	return [3]uint16{uint16(value), uint16(value >> 16), uint16(value >> 32)}
+}
+
+// RawBytes splits a 48-bit raw value into six bytes, least significant first,
+// as smartctl does for the raw8 format.
+func RawBytes(value int64) [6]uint8 {
+	var b [6]uint8
+	for i := range b {
+		b[i] = uint8(value >> (8 * i))
+	}
+	return b
+}
+
+// Words16 returns the raw value split into 16-bit words (see [RawWords16]).
+func (r Raw) Words16() [3]uint16 {
+	return RawWords16(r.Value)
+}
+
+// Bytes returns the raw value split into bytes (see [RawBytes]).
+func (r Raw) Bytes() [6]uint8 {
+	return RawBytes(r.Value)
+}
+
+// Temperature decodes the raw string as a temperature (see [ParseRawTemperature]).
+func (r Raw) Temperature() (RawTemperature, bool) {
+	return ParseRawTemperature(r.String)
+}
+
+// Duration decodes the raw string as a power-on duration (see [ParseRawDuration]).
+func (r Raw) Duration() (time.Duration, bool) {
+	return ParseRawDuration(r.String)
+}
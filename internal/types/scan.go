@@ -0,0 +1,46 @@
+package types
+
+import "strings"
+
+// ScanOptions narrows a device scan.
+type ScanOptions struct {
+	// Types are smartctl device types to scan for, each passed as
+	// "--scan-open -d TYPE", e.g. "nvme" or "sat", so that smartctl only
+	// probes those device classes. Empty scans every class.
+	Types []string
+	// Interfaces keeps only the devices whose type ("sat", "nvme", ...) or,
+	// on Linux, sysfs transport ("usb", "ata", "pcie", ...) is listed.
+	// Empty keeps all devices.
+	Interfaces []string
+}
+
+// HasType reports whether deviceType is one of Types, or Types is empty.
+// Types are compared case-insensitively and without parameters, so "sat"
+// matches "sat,12".
+func (o ScanOptions) HasType(deviceType string) bool {
+	return len(o.Types) == 0 || containsFold(o.Types, baseDeviceType(deviceType))
+}
+
+// MatchesInterface reports whether d passes the Interfaces filter.
+func (o ScanOptions) MatchesInterface(d Device) bool {
+	if len(o.Interfaces) == 0 || containsFold(o.Interfaces, baseDeviceType(d.Type)) {
+		return true
+	}
+	return d.Sysfs != nil && d.Sysfs.Transport != "" && containsFold(o.Interfaces, d.Sysfs.Transport)
+}
+
+// baseDeviceType strips the parameters of a smartctl device type, e.g.
+// "megaraid,0" becomes "megaraid".
+func baseDeviceType(deviceType string) string {
+	base, _, _ := strings.Cut(deviceType, ",")
+	return strings.TrimSpace(base)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(baseDeviceType(item), s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package types
+
+import "time"
+
+// SecureEraseInfo is the ATA security state of a drive and its estimates of
+// how long SECURITY ERASE UNIT takes, from IDENTIFY DEVICE words 89, 90 and
+// 128. It is informational: this library never issues erase commands.
+type SecureEraseInfo struct {
+	// Supported reports the ATA security feature set; Enabled means a user
+	// password is set.
+	Supported bool `json:"supported"`
+	Enabled   bool `json:"enabled"`
+	Locked    bool `json:"locked"`
+	// Frozen drives reject security commands until their next power cycle.
+	Frozen            bool `json:"frozen"`
+	EnhancedSupported bool `json:"enhanced_supported"`
+	// NormalTime and EnhancedTime are the drive's estimates for a normal and
+	// an enhanced erase, zero when it reports none. When the matching Over
+	// field is set, the estimate is the largest the drive can express and the
+	// erase takes longer.
+	NormalTime   time.Duration `json:"normal_time"`
+	NormalOver   bool          `json:"normal_over,omitempty"`
+	EnhancedTime time.Duration `json:"enhanced_time"`
+	EnhancedOver bool          `json:"enhanced_over,omitempty"`
+}
+
+// NewSecureEraseInfo decodes IDENTIFY DEVICE words 89 (normal erase time),
+// 90 (enhanced erase time) and 128 (security status).
+func NewSecureEraseInfo(word89, word90, word128 uint16) *SecureEraseInfo {
+	info := &SecureEraseInfo{
+		Supported:         word128&0x0001 != 0,
+		Enabled:           word128&0x0002 != 0,
+		Locked:            word128&0x0004 != 0,
+		Frozen:            word128&0x0008 != 0,
+		EnhancedSupported: word128&0x0020 != 0,
+	}
+	info.NormalTime, info.NormalOver = EraseTime(word89)
+	info.EnhancedTime, info.EnhancedOver = EraseTime(word90)
+	return info
+}
+
+// EraseTime decodes an IDENTIFY DEVICE erase time word. ACS-3 drives set bit
+// 15 and report 2 minute units in bits 14:0; older drives use bits 7:0. The
+// all-ones value means "longer than" the returned duration (508 or 65532
+// minutes); zero means the drive gives no estimate.
+func EraseTime(word uint16) (time.Duration, bool) {
+	value, maxValue := word&0x00FF, uint16(0x00FF)
+	if word&0x8000 != 0 {
+		value, maxValue = word&0x7FFF, 0x7FFF
+	}
+	if value == maxValue {
+		return time.Duration(maxValue-1) * 2 * time.Minute, true
+	}
+	return time.Duration(value) * 2 * time.Minute, false
+}
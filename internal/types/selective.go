@@ -0,0 +1,138 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxSelectiveSpans is the number of LBA spans an ATA selective self-test can
+// cover in one run.
+const MaxSelectiveSpans = 5
+
+// LBASpan is an inclusive range of logical block addresses.
+type LBASpan struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// String formats the span as smartctl expects it after "-t select,".
+func (s LBASpan) String() string {
+	return fmt.Sprintf("%d-%d", s.Start, s.End)
+}
+
+// ValidateSelectiveSpans checks that spans can be passed to a selective
+// self-test: one to MaxSelectiveSpans spans, each with Start <= End.
+func ValidateSelectiveSpans(spans []LBASpan) error {
+	if len(spans) == 0 {
+		return errors.New("selective self-test needs at least one LBA span")
+	}
+	if len(spans) > MaxSelectiveSpans {
+		return fmt.Errorf("selective self-test supports at most %d LBA spans, got %d", MaxSelectiveSpans, len(spans))
+	}
+	for _, span := range spans {
+		if span.Start > span.End {
+			return fmt.Errorf("invalid LBA span %s: start is after end", span)
+		}
+	}
+	return nil
+}
+
+// SelectiveContinuation picks the spans of a selective self-test relative to
+// those of the previous run, so a drive can be walked span by span.
+type SelectiveContinuation string
+
+// Selective self-test continuations.
+const (
+	// SelectiveNext tests the spans following the previous ones, with the
+	// same sizes ("-t select,next").
+	SelectiveNext SelectiveContinuation = "next"
+	// SelectiveRedo tests the previous spans again ("-t select,redo").
+	SelectiveRedo SelectiveContinuation = "redo"
+	// SelectiveCont behaves like SelectiveNext when the previous test
+	// completed and like SelectiveRedo when it was aborted ("-t select,cont").
+	SelectiveCont SelectiveContinuation = "cont"
+)
+
+// MaxPendingMinutes is the longest delay "-t pending,N" accepts.
+const MaxPendingMinutes = 65535
+
+// SelectiveSelfTestOptions tunes a selective self-test.
+type SelectiveSelfTestOptions struct {
+	// Continue, when set, derives the spans from the previous run instead of
+	// taking explicit ones.
+	Continue SelectiveContinuation
+	// AfterSelect, when set, turns the scan of the rest of the disk after the
+	// selected spans on or off ("-t afterselect,on|off").
+	AfterSelect *bool
+	// PendingMinutes, when set, is how long the drive waits after power-up
+	// before resuming an interrupted remainder scan ("-t pending,N").
+	PendingMinutes *int
+}
+
+// Validate checks opts together with the explicit spans of the test: either
+// spans or a continuation must be given, but not both.
+func (o SelectiveSelfTestOptions) Validate(spans []LBASpan) error {
+	switch o.Continue {
+	case "":
+		if err := ValidateSelectiveSpans(spans); err != nil {
+			return err
+		}
+	case SelectiveNext, SelectiveRedo, SelectiveCont:
+		if len(spans) > 0 {
+			return fmt.Errorf("LBA spans cannot be combined with select,%s", o.Continue)
+		}
+	default:
+		return fmt.Errorf("invalid selective self-test continuation %q (must be one of: next, redo, cont)", o.Continue)
+	}
+	if o.PendingMinutes != nil && (*o.PendingMinutes < 0 || *o.PendingMinutes > MaxPendingMinutes) {
+		return fmt.Errorf("invalid pending time %d (must be between 0 and %d minutes)", *o.PendingMinutes, MaxPendingMinutes)
+	}
+	return nil
+}
+
+// SelectiveSelfTestLog is the ATA selective self-test log
+// ("smartctl -l selective").
+type SelectiveSelfTestLog struct {
+	Revision int `json:"revision"`
+	// Table lists the configured spans; unused entries have a zero range.
+	Table []SelectiveSelfTestSpan `json:"table,omitempty"`
+	// CurrentReadScan is the span being scanned after the configured ones
+	// when remainder scanning is enabled.
+	CurrentReadScan          *SelectiveSelfTestSpan `json:"current_read_scan,omitempty"`
+	Flags                    SelectiveSelfTestFlags `json:"flags"`
+	PowerUpScanResumeMinutes int                    `json:"power_up_scan_resume_minutes"`
+}
+
+// SelectiveSelfTestSpan is one span of the selective self-test log with its
+// test status.
+type SelectiveSelfTestSpan struct {
+	LbaMin uint64       `json:"lba_min"`
+	LbaMax uint64       `json:"lba_max"`
+	Status *StatusField `json:"status,omitempty"`
+}
+
+// Span returns the LBA range of the entry.
+func (s SelectiveSelfTestSpan) Span() LBASpan {
+	return LBASpan{Start: s.LbaMin, End: s.LbaMax}
+}
+
+// SelectiveSelfTestFlags are the flags of the selective self-test log.
+type SelectiveSelfTestFlags struct {
+	Value                int  `json:"value"`
+	RemainderScanEnabled bool `json:"remainder_scan_enabled"`
+}
+
+// Spans returns the configured spans, skipping unused entries.
+func (l *SelectiveSelfTestLog) Spans() []LBASpan {
+	if l == nil {
+		return nil
+	}
+	var spans []LBASpan
+	for _, entry := range l.Table {
+		if entry.LbaMin == 0 && entry.LbaMax == 0 {
+			continue
+		}
+		spans = append(spans, entry.Span())
+	}
+	return spans
+}
@@ -0,0 +1,194 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// AtaSelfTestLog is the ATA SMART self-test log ("smartctl -l selftest").
+type AtaSelfTestLog struct {
+	Standard *AtaSelfTestLogTable `json:"standard,omitempty"`
+	Extended *AtaSelfTestLogTable `json:"extended,omitempty"`
+}
+
+// AtaSelfTestLogTable holds the entries of one ATA self-test log, newest first.
+type AtaSelfTestLogTable struct {
+	Revision           int                `json:"revision"`
+	Table              []AtaSelfTestEntry `json:"table,omitempty"`
+	Count              int                `json:"count"`
+	ErrorCountTotal    int                `json:"error_count_total,omitempty"`
+	ErrorCountOutdated int                `json:"error_count_outdated,omitempty"`
+}
+
+// AtaSelfTestEntry is one ATA self-test log entry. Status.RemainingPercent is
+// set for tests that did not run to the end.
+type AtaSelfTestEntry struct {
+	Type          *StatusField `json:"type,omitempty"`
+	Status        *StatusField `json:"status,omitempty"`
+	LifetimeHours int          `json:"lifetime_hours"`
+	LBA           *uint64      `json:"lba,omitempty"`
+}
+
+// NvmeSelfTestLog is the NVMe self-test log ("smartctl -l selftest").
+type NvmeSelfTestLog struct {
+	CurrentSelfTestOperation         *StatusField        `json:"current_self_test_operation,omitempty"`
+	CurrentSelfTestCompletionPercent *int                `json:"current_self_test_completion_percent,omitempty"`
+	Table                            []NvmeSelfTestEntry `json:"table,omitempty"`
+}
+
+// NvmeSelfTestEntry is one NVMe self-test log entry, newest first.
+type NvmeSelfTestEntry struct {
+	SelfTestCode   *StatusField `json:"self_test_code,omitempty"`
+	SelfTestResult *StatusField `json:"self_test_result,omitempty"`
+	PowerOnHours   int          `json:"power_on_hours"`
+	LBA            *uint64      `json:"lba,omitempty"`
+}
+
+// SelfTestOutcome classifies how a self-test ended.
+type SelfTestOutcome string
+
+// Self-test outcomes.
+const (
+	// SelfTestPassed is a test that ran to the end without error.
+	SelfTestPassed SelfTestOutcome = "passed"
+	// SelfTestFailed is a test that found an error, such as a read failure.
+	SelfTestFailed SelfTestOutcome = "failed"
+	// SelfTestAborted is a test stopped by the host before it finished.
+	SelfTestAborted SelfTestOutcome = "aborted"
+	// SelfTestInterrupted is a test stopped by a reset, power loss or other
+	// event before it finished.
+	SelfTestInterrupted SelfTestOutcome = "interrupted"
+	// SelfTestUnknown is a log entry whose status this library cannot map.
+	SelfTestUnknown SelfTestOutcome = "unknown"
+)
+
+// Completed reports whether the test ran far enough to give a verdict on the
+// drive, i.e. it passed or failed rather than being cut short.
+func (o SelfTestOutcome) Completed() bool {
+	return o == SelfTestPassed || o == SelfTestFailed
+}
+
+// AtaSelfTestOutcome maps an ATA self-test execution status byte, as logged
+// in the self-test log, to a SelfTestOutcome.
+func AtaSelfTestOutcome(status int) SelfTestOutcome {
+	switch status >> 4 {
+	case 0x0:
+		return SelfTestPassed
+	case 0x1:
+		return SelfTestAborted
+	case 0x2:
+		return SelfTestInterrupted
+	case 0x3, 0x4, 0x5, 0x6, 0x7, 0x8:
+		return SelfTestFailed
+	default:
+		return SelfTestUnknown
+	}
+}
+
+// NvmeSelfTestOutcome maps an NVMe device self-test result code to a
+// SelfTestOutcome.
+func NvmeSelfTestOutcome(result int) SelfTestOutcome {
+	switch result & 0x0F {
+	case 0x0:
+		return SelfTestPassed
+	case 0x1, 0x3, 0x4, 0x9:
+		// Aborted by a Device Self-test command, namespace removal, format or sanitize.
+		return SelfTestAborted
+	case 0x2, 0x8:
+		// Aborted by a controller reset or for an unknown reason.
+		return SelfTestInterrupted
+	case 0x5, 0x6, 0x7:
+		return SelfTestFailed
+	default:
+		return SelfTestUnknown
+	}
+}
+
+// SelfTestResult is the outcome of a finished self-test, taken from the
+// newest entry of the self-test log.
+type SelfTestResult struct {
+	DevicePath string `json:"device_path"`
+	TestType   string `json:"test_type"`
+	// Status is the log entry's status text, e.g. "Completed without error".
+	Status string `json:"status"`
+	// StatusValue is the raw ATA self-test execution status or NVMe self-test
+	// result code of the entry.
+	StatusValue int             `json:"status_value"`
+	Outcome     SelfTestOutcome `json:"outcome"`
+	Passed      bool            `json:"passed"`
+	// Duration is the time from starting the test until its end was observed.
+	Duration time.Duration `json:"duration"`
+	// RemainingPercent is the part of the test left when it ended, non-zero
+	// only for tests that were aborted, interrupted or failed early.
+	RemainingPercent int `json:"remaining_percent"`
+	// FirstErrorLBA is the first failing LBA, when the drive reported one.
+	FirstErrorLBA *uint64 `json:"first_error_lba,omitempty"`
+	// PowerOnHours is the drive's power-on time recorded with the entry.
+	PowerOnHours int `json:"power_on_hours"`
+}
+
+// Err returns an error wrapping ErrSelfTestAborted when the test was aborted
+// or interrupted, so it produced no verdict on the drive, and nil otherwise.
+// A failed test is a verdict: check Passed for it.
+func (r *SelfTestResult) Err() error {
+	if r == nil || (r.Outcome != SelfTestAborted && r.Outcome != SelfTestInterrupted) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrSelfTestAborted, r.Outcome, r.Status)
+}
+
+// SelfTestInProgress reports whether info shows a self-test still running.
+func SelfTestInProgress(info *SMARTInfo) bool {
+	if info == nil {
+		return false
+	}
+	if ata := info.AtaSmartData; ata != nil && ata.SelfTest != nil && ata.SelfTest.Status != nil {
+		// Execution status 0xF_ means "in progress", with 10% steps left in the low nibble.
+		return ata.SelfTest.Status.Value>>4 == 0xF
+	}
+	if log := info.NvmeSelfTestLog; log != nil && log.CurrentSelfTestOperation != nil && log.CurrentSelfTestOperation.Value != 0 {
+		return true
+	}
+	if log := info.NvmeSmartTestLog; log != nil && log.CurrentOpeation != nil && *log.CurrentOpeation != 0 {
+		return true
+	}
+	return false
+}
+
+// LastSelfTestResult returns the newest entry of the ATA or NVMe self-test log
+// in info as a SelfTestResult, or false when info has no logged self-test.
+// DevicePath, TestType and Duration are left for the caller to fill in.
+func LastSelfTestResult(info *SMARTInfo) (*SelfTestResult, bool) {
+	if info == nil {
+		return nil, false
+	}
+	if log := info.AtaSmartSelfTestLog; log != nil && log.Standard != nil && len(log.Standard.Table) > 0 {
+		entry := log.Standard.Table[0]
+		result := &SelfTestResult{PowerOnHours: entry.LifetimeHours, FirstErrorLBA: entry.LBA}
+		if entry.Status != nil {
+			result.Status = entry.Status.String
+			result.StatusValue = entry.Status.Value
+			result.Outcome = AtaSelfTestOutcome(entry.Status.Value)
+			result.Passed = result.Outcome == SelfTestPassed
+			if entry.Status.Passed != nil {
+				result.Passed = *entry.Status.Passed
+			}
+			if entry.Status.RemainingPercent != nil {
+				result.RemainingPercent = *entry.Status.RemainingPercent
+			}
+		}
+		return result, true
+	}
+	if log := info.NvmeSelfTestLog; log != nil && len(log.Table) > 0 {
+		entry := log.Table[0]
+		result := &SelfTestResult{PowerOnHours: entry.PowerOnHours, FirstErrorLBA: entry.LBA}
+		if entry.SelfTestResult != nil {
+			result.Status = entry.SelfTestResult.String
+			result.StatusValue = entry.SelfTestResult.Value
+			result.Outcome = NvmeSelfTestOutcome(entry.SelfTestResult.Value)
+			result.Passed = result.Outcome == SelfTestPassed
+		}
+		return result, true
+	}
+	return nil, false
+}
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrCommandTimeout indicates a smartctl invocation ran longer than its
+// command timeout and was killed. Errors matching it are
+// *CommandTimeoutError.
+var ErrCommandTimeout = errors.New("smartctl command timed out")
+
+// CommandTimeoutError reports an invocation killed by its command timeout,
+// typically smartctl hanging on a dying drive. It matches ErrCommandTimeout
+// and, through Err, context.DeadlineExceeded.
+type CommandTimeoutError struct {
+	Name    string   // the command that ran, usually the smartctl path
+	Args    []string // its arguments
+	Timeout time.Duration
+	Stdout  []byte // output printed before the command was killed
+	Stderr  []byte
+	Err     error // the error of the killed invocation
+}
+
+func (e *CommandTimeoutError) Error() string {
+	return fmt.Sprintf("%s after %s: %s %s", ErrCommandTimeout, e.Timeout, e.Name, strings.Join(e.Args, " "))
+}
+
+// Unwrap returns the error of the killed invocation.
+func (e *CommandTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is match ErrCommandTimeout.
+func (e *CommandTimeoutError) Is(target error) bool {
+	return target == ErrCommandTimeout
+}
+
+// CommandCanceledError reports an invocation killed because the context of
+// the call was canceled or reached its deadline. It matches the context error
+// through Err.
+type CommandCanceledError struct {
+	Name   string   // the command that ran, usually the smartctl path
+	Args   []string // its arguments
+	Stdout []byte   // output printed before the command was killed
+	Stderr []byte
+	Err    error // the error of the killed invocation, joined with the context error
+}
+
+func (e *CommandCanceledError) Error() string {
+	return fmt.Sprintf("smartctl command canceled: %s %s: %v", e.Name, strings.Join(e.Args, " "), e.Err)
+}
+
+// Unwrap returns the error of the killed invocation.
+func (e *CommandCanceledError) Unwrap() error {
+	return e.Err
+}
+
+type commandTimeoutKey struct{}
+
+// ContextWithCommandTimeout returns a context that overrides the backend's
+// command timeout for the calls made with it. Zero or a negative timeout
+// disables the limit for those calls.
+func ContextWithCommandTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, commandTimeoutKey{}, timeout)
+}
+
+// CommandTimeoutFromContext returns the timeout set by ContextWithCommandTimeout.
+func CommandTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	timeout, ok := ctx.Value(commandTimeoutKey{}).(time.Duration)
+	return timeout, ok
+}
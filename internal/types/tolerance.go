@@ -0,0 +1,45 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+// Tolerance is the smartctl -T level: how smartctl reacts to ATA SMART
+// commands that fail mandatory checks.
+type Tolerance string
+
+// Tolerance levels, from the strictest to the most forgiving.
+const (
+	ToleranceConservative   Tolerance = "conservative"   // fail on any optional command failure
+	ToleranceNormal         Tolerance = "normal"         // fail on mandatory command failures (smartctl's default)
+	TolerancePermissive     Tolerance = "permissive"     // ignore one mandatory command failure
+	ToleranceVeryPermissive Tolerance = "verypermissive" // ignore all mandatory command failures
+)
+
+// Validate reports an unknown tolerance level. The zero value is valid and
+// leaves smartctl's default in place.
+func (t Tolerance) Validate() error {
+	switch t {
+	case "", ToleranceConservative, ToleranceNormal, TolerancePermissive, ToleranceVeryPermissive:
+		return nil
+	}
+	return fmt.Errorf("invalid tolerance %q (must be one of: conservative, normal, permissive, verypermissive)", string(t))
+}
+
+type toleranceKey struct{}
+
+// ContextWithTolerance returns a context that overrides the backend's
+// Tolerance for the calls made with it.
+func ContextWithTolerance(ctx context.Context, t Tolerance) context.Context {
+	return context.WithValue(ctx, toleranceKey{}, t)
+}
+
+// ToleranceFromContext returns the tolerance set by ContextWithTolerance.
+func ToleranceFromContext(ctx context.Context) (Tolerance, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	t, ok := ctx.Value(toleranceKey{}).(Tolerance)
+	return t, ok
+}
@@ -2,12 +2,60 @@ package types
 
 import (
 	"encoding/json"
+	"time"
 )
 
 // Device represents a storage device
 type Device struct {
-	Name string
-	Type string
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// InfoName is smartctl's description of the device, e.g.
+	// "/dev/sda [SAT]", and Protocol the command set it speaks: "ATA", "SCSI"
+	// or "NVMe".
+	InfoName string `json:"info_name,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+	// OpenError is the error smartctl --scan-open reported opening the
+	// device, e.g. "Permission denied". The device is listed but SMART
+	// queries will fail until it is fixed.
+	OpenError string `json:"open_error,omitempty"`
+	// NotOpened is set when the device was only listed by "smartctl --scan"
+	// because --scan-open failed or found nothing, usually for lack of
+	// permissions. Type is then smartctl's guess from the device name, and
+	// SMART queries are likely to fail until access is granted.
+	NotOpened bool `json:"not_opened,omitempty"`
+	// Controller and Namespaces are set on NVMe devices scanned through their
+	// namespaces (/dev/nvme0n1). ScanDevices reports a controller and its
+	// namespaces as a single device, named after the controller (/dev/nvme0)
+	// when that was listed too.
+	Controller string   `json:"controller,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Virtual is set on hypervisor disks (QEMU, VirtIO, VMware, Hyper-V,
+	// VirtualBox, Xen) and virtual LUNs such as iSCSI targets. They have no
+	// SMART data, and SMART queries fail with a VirtualDeviceError.
+	Virtual bool `json:"virtual,omitempty"`
+	// Sysfs is filled in by ScanDevices on Linux from sysfs and the udev
+	// database, without opening the device.
+	Sysfs *SysfsInfo `json:"sysfs,omitempty"`
+}
+
+// SysfsInfo describes a block device as the Linux kernel and udev see it.
+type SysfsInfo struct {
+	// Driver is the kernel driver bound to the device, e.g. "sd" or "nvme".
+	Driver string `json:"driver,omitempty"`
+	// Transport is the bus the device is attached through, as reported by
+	// udev's ID_BUS ("ata", "scsi", "usb") or the NVMe controller ("pcie",
+	// "tcp", "rdma").
+	Transport string `json:"transport,omitempty"`
+	// Scheduler is the active I/O scheduler, e.g. "mq-deadline" or "none".
+	Scheduler  string `json:"scheduler,omitempty"`
+	Removable  bool   `json:"removable,omitempty"`
+	Rotational *bool  `json:"rotational,omitempty"`
+	// Vendor and Model are the identification strings the kernel read from
+	// the device. SATA disks behind libata report the vendor "ATA".
+	Vendor string `json:"vendor,omitempty"`
+	Model  string `json:"model,omitempty"`
+	// Size is the device size in bytes.
+	Size int64 `json:"size,omitempty"`
 }
 
 // NvmeControllerCapabilities represents NVMe controller capabilities
@@ -50,26 +98,53 @@ type UserCapacity struct {
 
 // SMARTInfo represents comprehensive SMART information for a storage device
 type SMARTInfo struct {
-	Device                     Device                      `json:"device"`
-	ModelFamily                string                      `json:"model_family,omitempty"`
-	ModelName                  string                      `json:"model_name,omitempty"`
-	SerialNumber               string                      `json:"serial_number,omitempty"`
-	Firmware                   string                      `json:"firmware_version,omitempty"`
-	UserCapacity               *UserCapacity               `json:"user_capacity,omitempty"`
-	RotationRate               *int                        `json:"rotation_rate,omitempty"` // Rotation rate in RPM (0 for SSDs, >0 for HDDs, nil if not available or not applicable)
-	DiskType                   string                      `json:"-"`                       // Computed disk type: "SSD", "HDD", "NVMe", or "Unknown"
-	InStandby                  bool                        `json:"in_standby,omitempty"`    // True if device is in standby/sleep mode (ATA only)
-	ExitCodeInfo               *ExitCodeInfo               `json:"-"`                       // Computed from Smartctl.ExitStatus; nil when exit status is zero
-	SmartStatus                *SmartStatus                `json:"smart_status,omitempty"`
-	SmartSupport               *SmartSupport               `json:"smart_support,omitempty"`
-	AtaSmartData               *AtaSmartData               `json:"ata_smart_data,omitempty"`
-	NvmeSmartHealth            *NvmeSmartHealth            `json:"nvme_smart_health_information_log,omitempty"`
-	NvmeSmartTestLog           *NvmeSmartTestLog           `json:"nvme_smart_test_log,omitempty"`
-	NvmeControllerCapabilities *NvmeControllerCapabilities `json:"nvme_controller_capabilities,omitempty"`
-	Temperature                *Temperature                `json:"temperature,omitempty"`
-	PowerOnTime                *PowerOnTime                `json:"power_on_time,omitempty"`
-	PowerCycleCount            int                         `json:"power_cycle_count,omitempty"`
-	Smartctl                   *SmartctlInfo               `json:"smartctl,omitempty"`
+	Device                       Device                      `json:"device"`
+	ModelFamily                  string                      `json:"model_family,omitempty"`
+	ModelName                    string                      `json:"model_name,omitempty"`
+	SerialNumber                 string                      `json:"serial_number,omitempty"`
+	Firmware                     string                      `json:"firmware_version,omitempty"`
+	InSmartctlDatabase           *bool                       `json:"in_smartctl_database,omitempty"` // False when the installed smartctl drive database does not know this model
+	WWN                          *WWN                        `json:"wwn,omitempty"`
+	UserCapacity                 *UserCapacity               `json:"user_capacity,omitempty"`
+	LogicalBlockSize             int                         `json:"logical_block_size,omitempty"`
+	PhysicalBlockSize            int                         `json:"physical_block_size,omitempty"`
+	RotationRate                 *int                        `json:"rotation_rate,omitempty"` // Rotation rate in RPM (0 for SSDs, >0 for HDDs, nil if not available or not applicable)
+	DiskType                     string                      `json:"-"`                       // Computed disk type: "SSD", "HDD", "NVMe", or "Unknown"
+	InStandby                    bool                        `json:"in_standby,omitempty"`    // True if device is in standby/sleep mode (ATA only)
+	ExitCodeInfo                 *ExitCodeInfo               `json:"-"`                       // Computed from Smartctl.ExitStatus; nil when exit status is zero
+	Warnings                     []Warning                   `json:"-"`                       // Computed from Smartctl.Messages by ClassifyMessages
+	FormFactor                   *FormFactor                 `json:"form_factor,omitempty"`
+	AtaVersion                   *AtaVersion                 `json:"ata_version,omitempty"`
+	SataVersion                  *SataVersion                `json:"sata_version,omitempty"`
+	InterfaceSpeed               *InterfaceSpeed             `json:"interface_speed,omitempty"`
+	Trim                         *Trim                       `json:"trim,omitempty"`
+	ZonedDevice                  *ZonedDevice                `json:"zoned_device,omitempty"`
+	ScsiDeviceType               *ScsiDeviceType             `json:"device_type,omitempty"`
+	SmartStatus                  *SmartStatus                `json:"smart_status,omitempty"`
+	SmartSupport                 *SmartSupport               `json:"smart_support,omitempty"`
+	AtaSmartData                 *AtaSmartData               `json:"ata_smart_data,omitempty"`
+	AtaSmartErrorLog             *AtaSmartErrorLog           `json:"ata_smart_error_log,omitempty"`
+	AtaSmartSelfTestLog          *AtaSelfTestLog             `json:"ata_smart_self_test_log,omitempty"`
+	AtaSmartSelectiveSelfTestLog *SelectiveSelfTestLog       `json:"ata_smart_selective_self_test_log,omitempty"`
+	NvmeSmartHealth              *NvmeSmartHealth            `json:"nvme_smart_health_information_log,omitempty"`
+	NvmeSmartTestLog             *NvmeSmartTestLog           `json:"nvme_smart_test_log,omitempty"`
+	NvmeSelfTestLog              *NvmeSelfTestLog            `json:"nvme_self_test_log,omitempty"`
+	NvmeControllerCapabilities   *NvmeControllerCapabilities `json:"nvme_controller_capabilities,omitempty"`
+	NvmeOptionalAdminCommands    *NvmeOptionalAdminCommands  `json:"nvme_optional_admin_commands,omitempty"`
+	NvmeIeeeOuiIdentifier        *uint64                     `json:"nvme_ieee_oui_identifier,omitempty"`
+	NvmeTotalCapacity            int64                       `json:"nvme_total_capacity,omitempty"`       // Bytes of NVM in the controller
+	NvmeUnallocatedCapacity      int64                       `json:"nvme_unallocated_capacity,omitempty"` // Bytes not allocated to any namespace
+	NvmeNamespaces               []NvmeNamespace             `json:"nvme_namespaces,omitempty"`
+	Temperature                  *Temperature                `json:"temperature,omitempty"`
+	PowerOnTime                  *PowerOnTime                `json:"power_on_time,omitempty"`
+	PowerCycleCount              int                         `json:"power_cycle_count,omitempty"`
+	Smartctl                     *SmartctlInfo               `json:"smartctl,omitempty"`
+	JSONFormatVersion            []int                       `json:"json_format_version,omitempty"` // Schema version of the smartctl JSON output, e.g. [1, 0]
+
+	// Extra holds top-level keys of the smartctl JSON output that SMARTInfo does
+	// not model, such as fields added by newer smartctl releases. They are
+	// written back out by MarshalJSON.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // SmartStatus represents the overall SMART health status
@@ -78,6 +153,11 @@ type SmartStatus struct {
 	Passed   bool `json:"passed"`
 	Damaged  bool `json:"damaged,omitempty"`
 	Critical bool `json:"critical,omitempty"`
+	// Synthesized is set when smartctl reported no overall-health verdict,
+	// e.g. for a drive in standby: the status only carries the exit-status
+	// bits and the self-test state, and Passed is not a verdict. Use
+	// SMARTInfo.HealthVerdict to read the verdict.
+	Synthesized bool `json:"synthesized,omitempty"`
 }
 
 // SmartSupport represents SMART availability and enablement status.
@@ -94,6 +174,34 @@ type AtaSmartData struct {
 	Table                 []SmartAttribute       `json:"table,omitempty"`
 }
 
+// AtaSmartErrorLog represents the ATA SMART error log summary.
+type AtaSmartErrorLog struct {
+	Summary  *AtaErrorLogSummary `json:"summary,omitempty"`
+	Extended *AtaErrorLogSummary `json:"extended,omitempty"`
+}
+
+// AtaErrorLogSummary holds the error counters of a (summary or extended) ATA error log.
+type AtaErrorLogSummary struct {
+	Revision    int `json:"revision"`
+	Count       int `json:"count"`
+	LoggedCount int `json:"logged_count,omitempty"`
+}
+
+// ErrorCount returns the number of errors recorded by the device, preferring
+// the extended log (-x) over the summary log (-a). It returns false when
+// neither log was reported.
+func (l *AtaSmartErrorLog) ErrorCount() (int, bool) {
+	switch {
+	case l == nil:
+		return 0, false
+	case l.Extended != nil:
+		return l.Extended.Count, true
+	case l.Summary != nil:
+		return l.Summary.Count, true
+	}
+	return 0, false
+}
+
 // StatusField represents a status field that can be either a simple string or a complex object
 type StatusField struct {
 	Value            int    `json:"value"`
@@ -204,14 +312,69 @@ type Raw struct {
 	String string `json:"string"`
 }
 
-// Temperature represents device temperature
+// Temperature represents device temperature in Celsius. The limits are nil
+// unless the drive reports them: ATA drives in the SCT status (-x), NVMe
+// drives as their composite temperature thresholds, SCSI drives in the
+// temperature log page.
 type Temperature struct {
 	Current int `json:"current"`
+	// OpLimitMin and OpLimitMax bound the recommended operating range; NVMe
+	// drives report their warning composite temperature threshold as
+	// OpLimitMax.
+	OpLimitMin *int `json:"op_limit_min,omitempty"`
+	OpLimitMax *int `json:"op_limit_max,omitempty"`
+	// LimitMin and LimitMax bound the range the drive tolerates.
+	LimitMin *int `json:"limit_min,omitempty"`
+	LimitMax *int `json:"limit_max,omitempty"`
+	// CriticalLimitMin and CriticalLimitMax bound the range beyond which the
+	// drive may be damaged; NVMe drives report their critical composite
+	// temperature threshold as CriticalLimitMax.
+	CriticalLimitMin *int `json:"critical_limit_min,omitempty"`
+	CriticalLimitMax *int `json:"critical_limit_max,omitempty"`
+	// DriveTrip is the SCSI trip point above which the drive may shut down.
+	DriveTrip *int `json:"drive_trip,omitempty"`
 }
 
-// PowerOnTime represents power on time
+// PowerOnTime represents power on time. Minutes is set by drives that count
+// power-on time in finer units than hours, such as the msec24hour32 format
+// of attribute 9.
 type PowerOnTime struct {
-	Hours int `json:"hours"`
+	Hours   int `json:"hours"`
+	Minutes int `json:"minutes,omitempty"`
+}
+
+// Duration returns the power-on time as a time.Duration.
+func (p PowerOnTime) Duration() time.Duration {
+	return time.Duration(p.Hours)*time.Hour + time.Duration(p.Minutes)*time.Minute
+}
+
+// HealthVerdict returns the SMART overall-health verdict smartctl reported,
+// and false when it reported none, such as for a drive in standby or a
+// status synthesized from the exit code alone.
+func (s *SMARTInfo) HealthVerdict() (passed bool, ok bool) {
+	if s == nil || s.SmartStatus == nil || s.SmartStatus.Synthesized {
+		return false, false
+	}
+	return s.SmartStatus.Passed, true
+}
+
+// PowerOnDuration returns how long the drive has been powered on, taken from
+// the top-level power_on_time, then from the raw string of attribute 9
+// (decoded with ParseRawDuration, e.g. "35825h+02m+39.040s"), then from the
+// NVMe health log. It returns false when none of them is reported.
+func (s *SMARTInfo) PowerOnDuration() (time.Duration, bool) {
+	if s.PowerOnTime != nil {
+		return s.PowerOnTime.Duration(), true
+	}
+	if attr := s.AtaSmartData.GetAttributeByID(SmartAttrPowerOnHours); attr != nil {
+		if d, ok := attr.Raw.Duration(); ok {
+			return d, true
+		}
+	}
+	if s.NvmeSmartHealth != nil {
+		return time.Duration(s.NvmeSmartHealth.PowerOnHours) * time.Hour, true
+	}
+	return 0, false
 }
 
 // Message represents a message from smartctl
@@ -222,13 +385,40 @@ type Message struct {
 
 // SmartctlInfo represents smartctl metadata and messages
 type SmartctlInfo struct {
-	Version    []int     `json:"version,omitempty"`
-	Messages   []Message `json:"messages,omitempty"`
-	ExitStatus int       `json:"exit_status,omitempty"`
+	Version              []int                 `json:"version,omitempty"`
+	SvnRevision          string                `json:"svn_revision,omitempty"`
+	PlatformInfo         string                `json:"platform_info,omitempty"`
+	BuildInfo            string                `json:"build_info,omitempty"`
+	DriveDatabaseVersion *DriveDatabaseVersion `json:"drive_database_version,omitempty"`
+	Messages             []Message             `json:"messages,omitempty"`
+	ExitStatus           int                   `json:"exit_status,omitempty"`
 }
 
-// ProgressCallback is a function type for reporting progress
-type ProgressCallback func(progress int, status string)
+// ProgressSource tells where a reported self-test progress value comes from.
+type ProgressSource string
+
+// Self-test progress sources.
+const (
+	// ProgressMeasured is a percentage the drive reported, such as the ATA
+	// remaining_percent or the NVMe current completion.
+	ProgressMeasured ProgressSource = "measured"
+	// ProgressEstimated is extrapolated from the elapsed time and the
+	// expected duration of the test.
+	ProgressEstimated ProgressSource = "estimated"
+)
+
+// ProgressCallback is a function type for reporting progress. outcome is
+// empty until the final update of a test that has ended, which carries how it
+// ended.
+type ProgressCallback func(progress int, status string, source ProgressSource, outcome SelfTestOutcome)
+
+// SelfTestOptions tunes how a self-test is run.
+type SelfTestOptions struct {
+	// Captive runs the test in the foreground ("smartctl -C"): the drive does
+	// not answer other commands until the test ends, and the call returns only
+	// then. Use it during maintenance windows, never on a mounted drive.
+	Captive bool
+}
 
 // ExitCodeInfo breaks down the smartctl exit status into semantic groups.
 //
@@ -291,9 +481,8 @@ type DiscoveryResult struct {
 //
 //   - NVMe: nvme_smart_health_information_log.percentage_used
 //   - SSD:  ATA SMART attributes, tried in priority order:
-//     1. Attribute 231 (SSD Life Left)       — used = 100 − normalized value
-//     2. Attribute 177 (Wear Leveling Count) — used = 100 − normalized value
-//     3. Attribute 173 (SSD Life Used)       — used = raw value
+//     1. AtaSmartData.LifeRemainingAttribute — used = 100 − normalized value
+//     2. Attribute 173 (SSD Life Used)       — used = raw value
 //   - HDD / Unknown: nil
 //
 // The returned value is always clamped to [0, 100].
@@ -319,25 +508,13 @@ func (s *SMARTInfo) WearLevelPercent() *int {
 		if s.AtaSmartData == nil {
 			return nil
 		}
-		// Single-pass scan: track the best match found so far by priority.
-		var byAttr231, byAttr177, byAttr173 *int
-		for _, attr := range s.AtaSmartData.Table {
-			switch attr.ID {
-			case SmartAttrSSDLifeLeft: // 231 — normalized value = remaining life %
-				byAttr231 = clamp(100 - attr.Value)
-			case SmartAttrWearLevelingCount: // 177 — normalized value = remaining life %
-				byAttr177 = clamp(100 - attr.Value)
-			case SmartAttrSSDLifeUsed: // 173 — raw value = used life %
-				byAttr173 = clamp(int(attr.Raw.Value))
-			}
-		}
-		if byAttr231 != nil {
-			return byAttr231
+		if attr := s.AtaSmartData.LifeRemainingAttribute(); attr != nil {
+			return clamp(100 - attr.Value)
 		}
-		if byAttr177 != nil {
-			return byAttr177
+		if attr := s.AtaSmartData.GetAttributeByID(SmartAttrSSDLifeUsed); attr != nil { // raw value = used life %
+			return clamp(int(attr.Raw.Value))
 		}
-		return byAttr173
+		return nil
 
 	default:
 		return nil
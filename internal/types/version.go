@@ -0,0 +1,48 @@
+package types
+
+import "fmt"
+
+// SmartctlVersionInfo describes the smartctl binary a backend runs.
+type SmartctlVersionInfo struct {
+	Major       int    `json:"major"`
+	Minor       int    `json:"minor"`
+	SvnRevision string `json:"svn_revision,omitempty"` // e.g. "5338"
+	Platform    string `json:"platform,omitempty"`     // e.g. "x86_64-linux-6.1.0-18-amd64"
+	BuildInfo   string `json:"build_info,omitempty"`   // e.g. "(local build)"
+	// DriveDatabaseVersion is the version of the drive database installed with
+	// smartctl (e.g. "7.3/5528"), when smartctl reports it.
+	DriveDatabaseVersion string `json:"drive_database_version,omitempty"`
+}
+
+// String returns the version as "7.3", followed by the revision when known.
+func (v *SmartctlVersionInfo) String() string {
+	s := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	if v.SvnRevision != "" {
+		s += " r" + v.SvnRevision
+	}
+	return s
+}
+
+// DriveDatabaseVersion is the drive_database_version object of the smartctl block.
+type DriveDatabaseVersion struct {
+	String string `json:"string"`
+}
+
+// VersionInfo converts the smartctl block of a JSON response into a
+// SmartctlVersionInfo. It returns nil when the block carries no version.
+func (s *SmartctlInfo) VersionInfo() *SmartctlVersionInfo {
+	if s == nil || len(s.Version) < 2 {
+		return nil
+	}
+	v := &SmartctlVersionInfo{
+		Major:       s.Version[0],
+		Minor:       s.Version[1],
+		SvnRevision: s.SvnRevision,
+		Platform:    s.PlatformInfo,
+		BuildInfo:   s.BuildInfo,
+	}
+	if s.DriveDatabaseVersion != nil {
+		v.DriveDatabaseVersion = s.DriveDatabaseVersion.String
+	}
+	return v
+}
@@ -0,0 +1,34 @@
+package smartmontools
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	output := `{"smartctl":{"messages":[{"string":"` + t.Name() + ` message","severity":"warning"}]},"device":{"name":"/dev/sda","type":"sat"}}`
+	client, err := NewClient(
+		WithLogger(logger),
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -a -j --nocheck=standby /dev/sda": {output: []byte(output)},
+		}}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetSMARTInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	logged := buf.String()
+	assert.Contains(t, logged, `"msg":"`+t.Name()+` message","devicePath":"/dev/sda"`)
+	assert.Contains(t, logged, `"msg":"Cached device type","devicePath":"/dev/sda","deviceType":"sat"`)
+
+	_, err = NewClient(WithLogger(nil), WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(&mockCommander{}))
+	assert.NoError(t, err, "a nil logger keeps the default")
+}
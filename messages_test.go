@@ -0,0 +1,71 @@
+package smartmontools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    WarningCode
+	}{
+		{"/dev/sdc: Unknown USB bridge [0x0e8d:0x1887 (0x100)]", WarningUnknownUSBBridge},
+		{"Please specify device type with the -d option.", WarningOther},
+		{"/dev/sdx: Unable to detect device type", WarningUnsupportedDevice},
+		{"SMART support is: Unavailable - device lacks SMART capability.", WarningUnsupportedDevice},
+		{"Smartctl open device: /dev/sda failed: Permission denied", WarningPermissionDenied},
+		{"Smartctl open device: /dev/sda failed: Operation not permitted", WarningPermissionDenied},
+		{"A mandatory SMART command failed: exiting. To continue, add one or more '-T permissive' options.", WarningMandatoryCommandFailed},
+		{"Warning: This result is based on an Attribute check.", WarningOther},
+		{"/dev/sdy: Device not supported", WarningUnsupportedDevice},
+		{"Read SMART Self-test Log failed: scsi error unsupported field in scsi command", WarningOther},
+		{"SCT Commands not supported", WarningOther},
+		{"Read SMART Error Log failed: operation not supported", WarningOther},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyMessage(tt.message), tt.message)
+		assert.Equal(t, tt.want, Message{String: tt.message}.Code(), tt.message)
+	}
+}
+
+func TestClassifyMessages(t *testing.T) {
+	assert.Nil(t, ClassifyMessages(nil))
+	assert.Nil(t, ClassifyMessages(&SmartctlInfo{}))
+	warnings := ClassifyMessages(&SmartctlInfo{Messages: []Message{
+		{String: "Unknown USB bridge [0x152d:0x578e (0x209)]", Severity: "error"},
+		{String: "Read SCT Status failed", Severity: "information"},
+	}})
+	assert.Equal(t, []Warning{
+		{Code: WarningUnknownUSBBridge, Severity: "error", Message: "Unknown USB bridge [0x152d:0x578e (0x209)]"},
+		{Code: WarningOther, Severity: "information", Message: "Read SCT Status failed"},
+	}, warnings)
+}
+
+func TestGetSMARTInfo_Warnings(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithContextCommander(statusCommander{result: &CommandResult{Stdout: []byte(`{
+			"device": {"name": "/dev/sda", "type": "sat"},
+			"smartctl": {"messages": [{"string": "A mandatory SMART command failed: exiting. To continue, add one or more '-T permissive' options.", "severity": "error"}]},
+			"smart_status": {"passed": true}
+		}`)}}),
+	)
+	require.NoError(t, err)
+
+	info, err := client.GetSMARTInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	require.Len(t, info.Warnings, 1)
+	assert.Equal(t, WarningMandatoryCommandFailed, info.Warnings[0].Code)
+	assert.True(t, info.HasWarning(WarningMandatoryCommandFailed))
+	assert.False(t, info.HasWarning(WarningUnknownUSBBridge))
+
+	data, err := MarshalSnapshot(info)
+	require.NoError(t, err)
+	restored, err := UnmarshalSnapshot(data)
+	require.NoError(t, err)
+	assert.Equal(t, info.Warnings, restored.Warnings, "snapshots classify the messages again")
+}
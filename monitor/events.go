@@ -0,0 +1,149 @@
+package monitor
+
+import (
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/rules"
+)
+
+// Event kinds returned by Event.Kind.
+const (
+	KindHealthChanged       = "health_changed"
+	KindTemperatureExceeded = "temperature_exceeded"
+	KindAttributeDegraded   = "attribute_degraded"
+	KindSelfTestCompleted   = "self_test_completed"
+	KindPollFailed          = "poll_failed"
+	KindAlertRaised         = "alert_raised"
+	KindHealthStateChanged  = "health_state_changed"
+)
+
+// Event is emitted by Monitor. Concrete events are HealthChanged,
+// TemperatureExceeded, AttributeDegraded, SelfTestCompleted, AlertRaised,
+// HealthStateChanged and PollFailed; use a type switch to handle them.
+type Event interface {
+	// Kind returns the event kind (one of the Kind* constants).
+	Kind() string
+	// Device returns the path of the device the event refers to.
+	Device() string
+	// Time returns when the event was detected.
+	Time() time.Time
+}
+
+// EventBase carries the fields shared by all events.
+type EventBase struct {
+	DevicePath string    `json:"device"`
+	Serial     string    `json:"serial,omitempty"`
+	WWN        string    `json:"wwn,omitempty"`
+	At         time.Time `json:"time"`
+}
+
+// Device implements Event.
+func (e EventBase) Device() string { return e.DevicePath }
+
+// Time implements Event.
+func (e EventBase) Time() time.Time { return e.At }
+
+// HealthChanged is emitted when the SMART overall-health self-assessment
+// changes, and on the first poll of a device that is already failing.
+type HealthChanged struct {
+	EventBase
+	Passed bool `json:"passed"`
+}
+
+// Kind implements Event.
+func (HealthChanged) Kind() string { return KindHealthChanged }
+
+// TemperatureExceeded is emitted when the device temperature rises above the
+// warning or critical threshold of its class. It is not repeated until the
+// temperature has dropped back below that level, so a drive going from warning
+// to critical emits a second event.
+type TemperatureExceeded struct {
+	EventBase
+	Current int `json:"current"`
+	// Threshold is the exceeded threshold of Level.
+	Threshold int                            `json:"threshold"`
+	Level     smartmontools.TemperatureLevel `json:"level"`
+}
+
+// Kind implements Event.
+func (TemperatureExceeded) Kind() string { return KindTemperatureExceeded }
+
+// AttributeDegraded is emitted for each ATA attribute that changed in the bad
+// direction between two polls (see smartmontools.AttributeChange.Degraded).
+type AttributeDegraded struct {
+	EventBase
+	Change smartmontools.AttributeChange `json:"change"`
+}
+
+// Kind implements Event.
+func (AttributeDegraded) Kind() string { return KindAttributeDegraded }
+
+// SelfTestCompleted is emitted when a self-test that was running at the
+// previous poll is no longer running.
+type SelfTestCompleted struct {
+	EventBase
+	// Passed is nil when the device did not report the outcome.
+	Passed *bool  `json:"passed,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// Kind implements Event.
+func (SelfTestCompleted) Kind() string { return KindSelfTestCompleted }
+
+// PollFailed is emitted when reading SMART data from a device fails.
+type PollFailed struct {
+	EventBase
+	Err error `json:"-"`
+}
+
+// Kind implements Event.
+func (PollFailed) Kind() string { return KindPollFailed }
+
+// AlertRaised is emitted when a rule configured with WithRules starts
+// matching a device. It is not repeated while the rule keeps matching.
+type AlertRaised struct {
+	EventBase
+	Alert rules.Alert `json:"alert"`
+}
+
+// Kind implements Event.
+func (AlertRaised) Kind() string { return KindAlertRaised }
+
+// HealthState is the overall state of a device tracked by WithHealthStates.
+type HealthState string
+
+// Device health states.
+const (
+	// HealthOK means the drive passed its self-assessment with nothing else
+	// to report.
+	HealthOK HealthState = "ok"
+	// HealthWarning means the drive passed its self-assessment but is too hot,
+	// matches a rule or lost HealthScore points.
+	HealthWarning HealthState = "warning"
+	// HealthFailing means the drive failed its SMART self-assessment or its
+	// pre-failure attributes reached their threshold.
+	HealthFailing HealthState = "failing"
+	// HealthUnknown means the drive reported no self-assessment or could not
+	// be read; a drive whose state was known must fail HealthUnknownAfter
+	// polls in a row.
+	HealthUnknown HealthState = "unknown"
+	// HealthStandby means the drive was in standby and was not woken up
+	// before its state was known.
+	HealthStandby HealthState = "standby"
+)
+
+// HealthStateChanged is emitted by a Monitor created WithHealthStates when
+// the HealthState of a device changes, and on the first poll of a device that
+// is not HealthOK, with an empty Previous.
+type HealthStateChanged struct {
+	EventBase
+	Previous HealthState `json:"previous,omitempty"`
+	Current  HealthState `json:"current"`
+	// Reason explains Current, e.g. "SMART overall-health self-assessment
+	// failed" or the error of the poll.
+	Reason string `json:"reason"`
+}
+
+// Kind implements Event.
+func (HealthStateChanged) Kind() string { return KindHealthStateChanged }
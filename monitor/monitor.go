@@ -0,0 +1,456 @@
+// Package monitor provides an embeddable smartd: a Monitor polls a set of
+// devices at configurable intervals and emits typed events over a channel
+// when their health, temperature, attributes or self-test state change.
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/rules"
+)
+
+// DefaultInterval is the polling interval used when WithInterval is not given.
+// It matches smartd's default of 30 minutes.
+const DefaultInterval = 30 * time.Minute
+
+// Client is the subset of smartmontools.SmartClient used by Monitor.
+type Client interface {
+	ScanDevices(ctx context.Context) ([]smartmontools.Device, error)
+	GetSMARTInfo(ctx context.Context, devicePath string) (*smartmontools.SMARTInfo, error)
+}
+
+var _ Client = (smartmontools.SmartClient)(nil)
+
+// Option configures a Monitor.
+type Option func(*Monitor)
+
+// WithDevices sets the device paths to monitor. Without it, Run monitors the
+// devices returned by Client.ScanDevices.
+func WithDevices(devicePaths ...string) Option {
+	return func(m *Monitor) {
+		m.devices = append(m.devices, devicePaths...)
+	}
+}
+
+// WithInterval sets the default polling interval.
+func WithInterval(interval time.Duration) Option {
+	return func(m *Monitor) {
+		if interval > 0 {
+			m.interval = interval
+		}
+	}
+}
+
+// WithDeviceInterval overrides the polling interval of a single device.
+func WithDeviceInterval(devicePath string, interval time.Duration) Option {
+	return func(m *Monitor) {
+		if interval > 0 {
+			m.deviceIntervals[devicePath] = interval
+		}
+	}
+}
+
+// WithTemperatureThreshold enables TemperatureExceeded events above celsius
+// for every drive, graded TemperatureWarning. It replaces any
+// WithTemperaturePolicy.
+func WithTemperatureThreshold(celsius int) Option {
+	return func(m *Monitor) {
+		m.tempPolicy = nil
+		if celsius > 0 {
+			m.tempPolicy = &smartmontools.TemperaturePolicy{Default: smartmontools.TemperatureThresholds{Warning: celsius}}
+		}
+	}
+}
+
+// WithTemperaturePolicy enables TemperatureExceeded events when a drive rises
+// above the warning or critical threshold of its class in policy, such as
+// smartmontools.DefaultTemperaturePolicy(). It replaces any
+// WithTemperatureThreshold.
+func WithTemperaturePolicy(policy smartmontools.TemperaturePolicy) Option {
+	return func(m *Monitor) {
+		m.tempPolicy = &policy
+	}
+}
+
+// WithRules evaluates engine against every snapshot and emits AlertRaised
+// events for the rules that start matching.
+func WithRules(engine *rules.Engine) Option {
+	return func(m *Monitor) {
+		m.rules = engine
+	}
+}
+
+// WithHealthStates tracks the HealthState of every device and emits a
+// HealthStateChanged event when it changes, so that alerting layers are not
+// told again and again that a drive is still failing. A drive whose state is
+// known keeps it while it sleeps in standby and across a single failed poll;
+// it only becomes HealthUnknown after HealthUnknownAfter consecutive failures.
+func WithHealthStates() Option {
+	return func(m *Monitor) {
+		m.healthStates = true
+	}
+}
+
+// HealthUnknownAfter is the number of consecutive failed polls after which a
+// drive with a known HealthState becomes HealthUnknown.
+const HealthUnknownAfter = 2
+
+// WithEventBuffer sets the capacity of the events channel (default 64).
+// Polling blocks while the channel is full.
+func WithEventBuffer(size int) Option {
+	return func(m *Monitor) {
+		if size >= 0 {
+			m.bufferSize = size
+		}
+	}
+}
+
+// Monitor polls devices and emits events. Create it with New, consume
+// Events and call Run.
+type Monitor struct {
+	client          Client
+	devices         []string
+	interval        time.Duration
+	deviceIntervals map[string]time.Duration
+	tempPolicy      *smartmontools.TemperaturePolicy
+	rules           *rules.Engine
+	bufferSize      int
+	healthStates    bool
+
+	events  chan Event
+	runOnce sync.Once
+
+	statesMu sync.Mutex              // guards states and paths
+	states   map[string]*deviceState // keyed by DeviceIdentity.Key
+	paths    map[string]string       // device path -> key of the drive last read there
+}
+
+// New creates a Monitor reading SMART data through client.
+func New(client Client, opts ...Option) *Monitor {
+	m := &Monitor{
+		client:          client,
+		interval:        DefaultInterval,
+		deviceIntervals: make(map[string]time.Duration),
+		bufferSize:      64,
+		states:          make(map[string]*deviceState),
+		paths:           make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.events = make(chan Event, m.bufferSize)
+	return m
+}
+
+// Events returns the channel on which events are delivered. It is closed when
+// Run returns.
+func (m *Monitor) Events() <-chan Event {
+	return m.events
+}
+
+// Run polls every device immediately and then at its interval until ctx is
+// cancelled. It returns ctx.Err() on shutdown, or an error if the device scan
+// fails. Run may only be called once.
+func (m *Monitor) Run(ctx context.Context) error {
+	err := fmt.Errorf("monitor: Run called more than once")
+	m.runOnce.Do(func() {
+		defer close(m.events)
+		err = m.run(ctx)
+	})
+	return err
+}
+
+func (m *Monitor) run(ctx context.Context) error {
+	devices := m.devices
+	if len(devices) == 0 {
+		scanned, err := m.client.ScanDevices(ctx)
+		if err != nil {
+			return fmt.Errorf("monitor: scan devices: %w", err)
+		}
+		for _, d := range scanned {
+			devices = append(devices, d.Name)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, devicePath := range devices {
+		interval := m.interval
+		if d, ok := m.deviceIntervals[devicePath]; ok {
+			interval = d
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.watch(ctx, devicePath, interval)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// deviceState is what Monitor remembers about a drive between polls. It is
+// kept per drive identity rather than per path, so a drive that is renamed or
+// re-enumerated keeps its state, and a path that now leads to another drive
+// is not compared against the previous one.
+type deviceState struct {
+	mu           sync.Mutex
+	previous     *smartmontools.SMARTInfo
+	temperature  smartmontools.TemperatureLevel
+	activeAlerts map[string]bool
+	health       HealthState // last reported by WithHealthStates; empty before
+	failedPolls  int         // consecutive failed polls
+}
+
+func (m *Monitor) watch(ctx context.Context, devicePath string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if !m.poll(ctx, devicePath) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads devicePath once and emits the resulting events. It returns false
+// when the device is not worth polling again: virtual disks never have SMART
+// data, so they are reported by a single PollFailed.
+func (m *Monitor) poll(ctx context.Context, devicePath string) bool {
+	info, err := m.client.GetSMARTInfo(ctx, devicePath)
+	if ctx.Err() != nil {
+		return true
+	}
+	now := time.Now()
+	if err != nil {
+		base := EventBase{DevicePath: devicePath, At: now}
+		m.emit(ctx, PollFailed{EventBase: base, Err: err})
+		for _, event := range m.noDataTransition(base, healthStateOf(info, err), true) {
+			m.emit(ctx, event)
+		}
+		return !errors.Is(err, smartmontools.ErrVirtualDevice)
+	}
+	if info.InStandby {
+		// Standby placeholders carry no SMART data; keep the last full snapshot.
+		for _, event := range m.noDataTransition(EventBase{DevicePath: devicePath, At: now}, healthStateOf(info, nil), false) {
+			m.emit(ctx, event)
+		}
+		return true
+	}
+	state := m.state(devicePath, info)
+	state.mu.Lock()
+	events := m.detect(devicePath, now, state, info)
+	state.previous = info
+	state.mu.Unlock()
+	for _, event := range events {
+		m.emit(ctx, event)
+	}
+	return true
+}
+
+// state returns the state of the drive info describes, falling back to
+// devicePath for drives reporting neither a WWN nor a serial number.
+func (m *Monitor) state(devicePath string, info *smartmontools.SMARTInfo) *deviceState {
+	key := smartmontools.IdentityOf(info).Key()
+	if key == "" {
+		key = devicePath
+	}
+	m.statesMu.Lock()
+	defer m.statesMu.Unlock()
+	state, ok := m.states[key]
+	if !ok {
+		state = &deviceState{}
+		if pathState, ok := m.states[devicePath]; ok && key != devicePath && pathState.previous == nil {
+			// Only standby or failed polls were seen at the path so far: the
+			// health state they reported belongs to this drive.
+			state = pathState
+			delete(m.states, devicePath)
+		}
+		m.states[key] = state
+	}
+	m.paths[devicePath] = key
+	return state
+}
+
+// lastState returns the state of the drive last read at devicePath, or of the
+// path itself when no SMART data was read there yet.
+func (m *Monitor) lastState(devicePath string) *deviceState {
+	m.statesMu.Lock()
+	defer m.statesMu.Unlock()
+	key, ok := m.paths[devicePath]
+	if !ok {
+		key = devicePath
+	}
+	state, ok := m.states[key]
+	if !ok {
+		state = &deviceState{}
+		m.states[key] = state
+	}
+	return state
+}
+
+// detect computes the events between state.previous and info, updating the
+// temperature level and active alerts in state.
+func (m *Monitor) detect(devicePath string, now time.Time, state *deviceState, info *smartmontools.SMARTInfo) []Event {
+	id := smartmontools.IdentityOf(info)
+	base := EventBase{DevicePath: devicePath, Serial: id.Serial, WWN: id.WWN, At: now}
+	var events []Event
+
+	// Only verdicts smartctl reported count: a status synthesized for a drive
+	// without one is not a failing health check.
+	if passed, ok := info.HealthVerdict(); ok {
+		switch prevPassed, prevOK := state.previous.HealthVerdict(); {
+		case !prevOK:
+			if !passed {
+				events = append(events, HealthChanged{EventBase: base, Passed: false})
+			}
+		case prevPassed != passed:
+			events = append(events, HealthChanged{EventBase: base, Passed: passed})
+		}
+	}
+
+	if m.tempPolicy != nil {
+		if status := m.tempPolicy.Evaluate(info); status.Level != smartmontools.TemperatureUnknown {
+			if status.Level > state.temperature && status.Level >= smartmontools.TemperatureWarning {
+				events = append(events, TemperatureExceeded{EventBase: base, Current: status.Current, Threshold: status.Threshold(), Level: status.Level})
+			}
+			state.temperature = status.Level
+		}
+	}
+
+	if state.previous != nil {
+		for _, change := range smartmontools.CompareSMARTInfo(state.previous, info).Attributes {
+			if change.Degraded {
+				events = append(events, AttributeDegraded{EventBase: base, Change: change})
+			}
+		}
+		if smartmontools.SelfTestRunning(state.previous) && !smartmontools.SelfTestRunning(info) {
+			event := SelfTestCompleted{EventBase: base}
+			if info.AtaSmartData != nil && info.AtaSmartData.SelfTest != nil && info.AtaSmartData.SelfTest.Status != nil {
+				event.Passed = info.AtaSmartData.SelfTest.Status.Passed
+				event.Status = info.AtaSmartData.SelfTest.Status.String
+			}
+			events = append(events, event)
+		}
+	}
+
+	if m.rules != nil {
+		active := make(map[string]bool)
+		for _, alert := range m.rules.Evaluate(info) {
+			active[alert.Rule] = true
+			if !state.activeAlerts[alert.Rule] {
+				if alert.Device == "" {
+					alert.Device = devicePath
+				}
+				events = append(events, AlertRaised{EventBase: base, Alert: alert})
+			}
+		}
+		state.activeAlerts = active
+	}
+
+	if m.healthStates {
+		state.failedPolls = 0
+		events = append(events, transition(base, state, m.health(state, info))...)
+	}
+	return events
+}
+
+// health returns the HealthState of a drive with SMART data: the class given
+// by smartmontools.ClassifyHealth, raised from HealthOK to HealthWarning by a
+// temperature above its warning threshold, an active rule alert or a
+// HealthScore below 100. The caller holds state.mu, updated by detect.
+func (m *Monitor) health(state *deviceState, info *smartmontools.SMARTInfo) healthState {
+	h := healthStateOf(info, nil)
+	if h.state != HealthOK {
+		return h
+	}
+	if state.temperature >= smartmontools.TemperatureWarning {
+		current, _ := smartmontools.CurrentTemperature(info)
+		return healthState{HealthWarning, fmt.Sprintf("temperature %d °C is %s", current, state.temperature)}
+	}
+	if len(state.activeAlerts) > 0 {
+		rule := slices.Min(slices.Collect(maps.Keys(state.activeAlerts)))
+		return healthState{HealthWarning, "rule " + rule + " matches"}
+	}
+	score := smartmontools.HealthScore(info)
+	if len(score.Factors) > 0 {
+		worst := slices.MaxFunc(score.Factors, func(a, b smartmontools.ScoreFactor) int { return a.Penalty - b.Penalty })
+		return healthState{HealthWarning, worst.Detail}
+	}
+	return h
+}
+
+// healthState is a HealthState with the reason it was assigned.
+type healthState struct {
+	state  HealthState
+	reason string
+}
+
+// healthStateOf maps smartmontools.ClassifyHealth to a HealthState.
+func healthStateOf(info *smartmontools.SMARTInfo, err error) healthState {
+	status, reason := smartmontools.ClassifyHealth(info, err)
+	switch status {
+	case smartmontools.FleetHealthy:
+		return healthState{HealthOK, reason}
+	case smartmontools.FleetFailing:
+		return healthState{HealthFailing, reason}
+	case smartmontools.FleetStandby:
+		return healthState{HealthStandby, reason}
+	default:
+		return healthState{HealthUnknown, reason}
+	}
+}
+
+// noDataTransition handles the HealthState of a poll that returned no SMART
+// data, because the drive was in standby or the poll failed. A drive with a
+// known state keeps it while in standby, and across failed polls until
+// HealthUnknownAfter of them in a row, so that a failing drive spinning down
+// or timing out once is not reported as recovering on its next poll.
+func (m *Monitor) noDataTransition(base EventBase, h healthState, failed bool) []Event {
+	if !m.healthStates {
+		return nil
+	}
+	state := m.lastState(base.DevicePath)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if failed {
+		state.failedPolls++
+	}
+	switch state.health {
+	case HealthOK, HealthWarning, HealthFailing:
+		if !failed || state.failedPolls < HealthUnknownAfter {
+			return nil
+		}
+	}
+	return transition(base, state, h)
+}
+
+// transition records h as the health state of state and returns a
+// HealthStateChanged event when it differs from the previous one. The first
+// state of a device is only reported when it is not HealthOK. The caller
+// holds state.mu.
+func transition(base EventBase, state *deviceState, h healthState) []Event {
+	previous := state.health
+	state.health = h.state
+	if previous == h.state || (previous == "" && h.state == HealthOK) {
+		return nil
+	}
+	return []Event{HealthStateChanged{EventBase: base, Previous: previous, Current: h.state, Reason: h.reason}}
+}
+
+func (m *Monitor) emit(ctx context.Context, event Event) {
+	select {
+	case m.events <- event:
+	case <-ctx.Done():
+	}
+}
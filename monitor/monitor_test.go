@@ -0,0 +1,411 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient returns the scripted snapshots of each device in order,
+// repeating the last one once the script is exhausted.
+type fakeClient struct {
+	mu      sync.Mutex
+	scripts map[string][]*smartmontools.SMARTInfo
+	errs    map[string]error
+	polls   map[string]int
+}
+
+func (f *fakeClient) ScanDevices(ctx context.Context) ([]smartmontools.Device, error) {
+	var devices []smartmontools.Device
+	for name := range f.scripts {
+		devices = append(devices, smartmontools.Device{Name: name})
+	}
+	return devices, nil
+}
+
+func (f *fakeClient) GetSMARTInfo(ctx context.Context, devicePath string) (*smartmontools.SMARTInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.errs[devicePath]; err != nil {
+		return nil, err
+	}
+	script := f.scripts[devicePath]
+	i := min(f.polls[devicePath], len(script)-1)
+	f.polls[devicePath]++
+	if script[i] == nil {
+		return nil, errTimeout
+	}
+	return script[i], nil
+}
+
+// errTimeout is returned for nil entries of a fakeClient script.
+var errTimeout = errors.New("smartctl timed out")
+
+func ataInfo(passed bool, temp int, pending int64, selfTest int) *smartmontools.SMARTInfo {
+	return &smartmontools.SMARTInfo{
+		SerialNumber: "SER1",
+		SmartStatus:  &smartmontools.SmartStatus{Passed: passed},
+		Temperature:  &smartmontools.Temperature{Current: temp},
+		AtaSmartData: &smartmontools.AtaSmartData{
+			SelfTest: &smartmontools.SelfTest{Status: &smartmontools.StatusField{Value: selfTest, String: "status"}},
+			Table: []smartmontools.SmartAttribute{
+				{ID: 197, Name: "Current_Pending_Sector", Value: 100, Worst: 100, Raw: smartmontools.Raw{Value: pending}},
+			},
+		},
+	}
+}
+
+// collect runs m until n events were received and returns them.
+func collect(t *testing.T, m *Monitor, n int) []Event {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+	var events []Event
+	for len(events) < n {
+		select {
+		case e := <-m.Events():
+			events = append(events, e)
+		case <-ctx.Done():
+			t.Fatalf("timed out after %d of %d events", len(events), n)
+		}
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	for range m.Events() {
+		// Drain events sent before shutdown until the channel is closed.
+	}
+	return events
+}
+
+func TestMonitor_Events(t *testing.T) {
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{
+			"/dev/sda": {
+				ataInfo(true, 40, 0, 249),
+				{InStandby: true},
+				ataInfo(true, 60, 0, 249),
+				ataInfo(true, 62, 3, 0),
+				ataInfo(false, 50, 3, 0),
+			},
+		},
+		polls: map[string]int{},
+	}
+	m := New(client, WithInterval(time.Millisecond), WithTemperatureThreshold(55))
+	events := collect(t, m, 4)
+
+	require.IsType(t, TemperatureExceeded{}, events[0])
+	assert.Equal(t, 60, events[0].(TemperatureExceeded).Current)
+	assert.Equal(t, smartmontools.TemperatureWarning, events[0].(TemperatureExceeded).Level)
+	assert.Equal(t, "/dev/sda", events[0].Device())
+
+	require.IsType(t, AttributeDegraded{}, events[1])
+	assert.Equal(t, 197, events[1].(AttributeDegraded).Change.ID)
+	assert.Equal(t, KindAttributeDegraded, events[1].Kind())
+
+	require.IsType(t, SelfTestCompleted{}, events[2])
+	assert.Equal(t, "SER1", events[2].(SelfTestCompleted).Serial)
+
+	require.IsType(t, HealthChanged{}, events[3])
+	assert.False(t, events[3].(HealthChanged).Passed)
+}
+
+func TestMonitor_SelfTestCompleted(t *testing.T) {
+	nvme := func(operation int) *smartmontools.SMARTInfo {
+		return &smartmontools.SMARTInfo{
+			SerialNumber:    "NVME1",
+			NvmeSelfTestLog: &smartmontools.NvmeSelfTestLog{CurrentSelfTestOperation: &smartmontools.StatusField{Value: operation}},
+		}
+	}
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{
+			"/dev/sda":   {ataInfo(true, 30, 0, 0xF0), ataInfo(true, 30, 0, 0)},
+			"/dev/nvme0": {nvme(1), nvme(0)},
+		},
+		polls: map[string]int{},
+	}
+	events := collect(t, New(client, WithInterval(time.Millisecond)), 2)
+
+	devices := map[string]bool{}
+	for _, event := range events {
+		require.IsType(t, SelfTestCompleted{}, event)
+		devices[event.Device()] = true
+	}
+	assert.Equal(t, map[string]bool{"/dev/sda": true, "/dev/nvme0": true}, devices)
+}
+
+func TestMonitor_TemperaturePolicy(t *testing.T) {
+	nvme := func(temp int) *smartmontools.SMARTInfo {
+		return &smartmontools.SMARTInfo{
+			SerialNumber:    "NVME1",
+			DiskType:        "NVMe",
+			NvmeSmartHealth: &smartmontools.NvmeSmartHealth{Temperature: temp},
+		}
+	}
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{
+			"/dev/nvme0": {nvme(50), nvme(75), nvme(85), nvme(84), nvme(72), nvme(60), nvme(71)},
+		},
+		polls: map[string]int{},
+	}
+	m := New(client, WithInterval(time.Millisecond), WithTemperaturePolicy(smartmontools.DefaultTemperaturePolicy()))
+	events := collect(t, m, 3)
+
+	want := []struct {
+		current, threshold int
+		level              smartmontools.TemperatureLevel
+	}{
+		{75, 70, smartmontools.TemperatureWarning},
+		{85, 80, smartmontools.TemperatureCritical},
+		{71, 70, smartmontools.TemperatureWarning},
+	}
+	for i, w := range want {
+		require.IsType(t, TemperatureExceeded{}, events[i])
+		e := events[i].(TemperatureExceeded)
+		assert.Equal(t, w.current, e.Current, i)
+		assert.Equal(t, w.threshold, e.Threshold, i)
+		assert.Equal(t, w.level, e.Level, i)
+	}
+}
+
+func TestMonitor_PollFailed(t *testing.T) {
+	boom := errors.New("boom")
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{"/dev/sdb": {ataInfo(true, 30, 0, 0)}},
+		errs:    map[string]error{"/dev/sdb": boom},
+		polls:   map[string]int{},
+	}
+	events := collect(t, New(client, WithInterval(time.Millisecond)), 1)
+	require.IsType(t, PollFailed{}, events[0])
+	assert.ErrorIs(t, events[0].(PollFailed).Err, boom)
+	assert.Equal(t, "/dev/sdb", events[0].Device())
+}
+
+func TestMonitor_VirtualDeviceReportedOnce(t *testing.T) {
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{"/dev/sdc": {ataInfo(true, 30, 0, 0)}},
+		errs:    map[string]error{"/dev/sdc": &smartmontools.VirtualDeviceError{Device: "/dev/sdc", Vendor: "LIO-ORG"}},
+		polls:   map[string]int{},
+	}
+	m := New(client, WithInterval(time.Millisecond))
+	assert.False(t, m.poll(context.Background(), "/dev/sdc"), "virtual devices are not polled again")
+	event := <-m.Events()
+	require.IsType(t, PollFailed{}, event)
+	assert.ErrorIs(t, event.(PollFailed).Err, smartmontools.ErrVirtualDevice)
+}
+
+func TestMonitor_FailingAtStart(t *testing.T) {
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{"/dev/sdc": {ataInfo(false, 30, 0, 0)}},
+		polls:   map[string]int{},
+	}
+	events := collect(t, New(client, WithDevices("/dev/sdc"), WithInterval(time.Hour)), 1)
+	require.IsType(t, HealthChanged{}, events[0])
+	assert.False(t, events[0].(HealthChanged).Passed)
+}
+
+func TestMonitor_SynthesizedStatusIsNoVerdict(t *testing.T) {
+	noVerdict := ataInfo(false, 30, 0, 0)
+	noVerdict.SmartStatus.Synthesized = true
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{"/dev/sdc": {noVerdict, ataInfo(true, 30, 0, 0), ataInfo(false, 30, 0, 0)}},
+		polls:   map[string]int{},
+	}
+	m := New(client, WithDevices("/dev/sdc"))
+	ctx := context.Background()
+	m.poll(ctx, "/dev/sdc")
+	m.poll(ctx, "/dev/sdc")
+	assert.Empty(t, m.Events(), "neither a status without verdict nor a passing one is a change")
+
+	m.poll(ctx, "/dev/sdc")
+	event := <-m.Events()
+	require.IsType(t, HealthChanged{}, event)
+	assert.False(t, event.(HealthChanged).Passed)
+}
+
+func TestMonitor_RunTwice(t *testing.T) {
+	client := &fakeClient{scripts: map[string][]*smartmontools.SMARTInfo{}, polls: map[string]int{}}
+	m := New(client, WithDevices())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, m.Run(ctx), context.Canceled)
+	assert.Error(t, m.Run(ctx))
+}
+
+func TestMonitor_Rules(t *testing.T) {
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{
+			"/dev/sda": {
+				ataInfo(true, 40, 0, 0),
+				ataInfo(true, 40, 2, 0),
+				ataInfo(true, 40, 4, 0),
+			},
+		},
+		polls: map[string]int{},
+	}
+	engine := rules.MustCompile(rules.Rule{Name: "pending", Expr: "attr(197).raw > 0", Severity: rules.SeverityCritical})
+	events := collect(t, New(client, WithInterval(time.Millisecond), WithRules(engine)), 3)
+
+	var alerts []AlertRaised
+	for _, e := range events {
+		if a, ok := e.(AlertRaised); ok {
+			alerts = append(alerts, a)
+		}
+	}
+	// The rule stays active on the third poll, so it is raised only once.
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "pending", alerts[0].Alert.Rule)
+	assert.Equal(t, "/dev/sda", alerts[0].Alert.Device)
+}
+
+func TestMonitor_StateFollowsIdentity(t *testing.T) {
+	other := ataInfo(true, 40, 3, 0)
+	other.SerialNumber = "SER2"
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{
+			// SER1 moves from /dev/sda to /dev/sdb, and SER2 takes its place.
+			"/dev/sda": {ataInfo(true, 40, 0, 0), other},
+			"/dev/sdb": {ataInfo(true, 40, 2, 0)},
+		},
+		polls: map[string]int{},
+	}
+	m := New(client)
+	ctx := context.Background()
+	m.poll(ctx, "/dev/sda")
+	m.poll(ctx, "/dev/sda")
+	m.poll(ctx, "/dev/sdb")
+	close(m.events)
+
+	var events []Event
+	for e := range m.Events() {
+		events = append(events, e)
+	}
+	// SER2 is not compared against SER1, but SER1 is compared across paths.
+	require.Len(t, events, 1)
+	require.IsType(t, AttributeDegraded{}, events[0])
+	assert.Equal(t, "/dev/sdb", events[0].Device())
+	assert.Equal(t, "SER1", events[0].(AttributeDegraded).Serial)
+	assert.Equal(t, int64(2), events[0].(AttributeDegraded).Change.NewRaw)
+}
+
+func TestMonitor_HealthStates(t *testing.T) {
+	failing := ataInfo(false, 40, 5, 0)
+	failing.SmartStatus.Damaged = true
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{
+			"/dev/sda": {
+				ataInfo(true, 40, 0, 0),
+				ataInfo(true, 40, 0, 0),
+				ataInfo(true, 50, 0, 0),
+				ataInfo(true, 40, 0, 0),
+				ataInfo(true, 40, 3, 0),
+				ataInfo(true, 40, 5, 0),
+				failing,
+				failing,
+			},
+		},
+		polls: map[string]int{},
+	}
+	m := New(client, WithInterval(time.Millisecond), WithTemperatureThreshold(45), WithHealthStates())
+	var changes []HealthStateChanged
+	for _, e := range collect(t, m, 8) {
+		if c, ok := e.(HealthStateChanged); ok {
+			changes = append(changes, c)
+		}
+	}
+
+	want := []struct {
+		previous, current HealthState
+		reason            string
+	}{
+		{HealthOK, HealthWarning, "temperature 50 °C is warning"},
+		{HealthWarning, HealthOK, "SMART overall-health self-assessment passed"},
+		{HealthOK, HealthWarning, "Current_Pending_Sector raw value is 3"},
+		{HealthWarning, HealthFailing, "SMART overall-health self-assessment failed"},
+	}
+	require.Len(t, changes, len(want))
+	for i, w := range want {
+		assert.Equal(t, w.previous, changes[i].Previous, i)
+		assert.Equal(t, w.current, changes[i].Current, i)
+		assert.Equal(t, w.reason, changes[i].Reason, i)
+		assert.Equal(t, "/dev/sda", changes[i].Device())
+		assert.Equal(t, KindHealthStateChanged, changes[i].Kind())
+	}
+}
+
+func TestMonitor_HealthStates_FirstPoll(t *testing.T) {
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{"/dev/sdb": {ataInfo(true, 30, 0, 0)}},
+		errs:    map[string]error{"/dev/sdb": smartmontools.ErrPermissionDenied},
+		polls:   map[string]int{},
+	}
+	events := collect(t, New(client, WithInterval(time.Millisecond), WithHealthStates()), 4)
+	require.IsType(t, PollFailed{}, events[0])
+	require.IsType(t, HealthStateChanged{}, events[1])
+	change := events[1].(HealthStateChanged)
+	assert.Empty(t, change.Previous)
+	assert.Equal(t, HealthUnknown, change.Current)
+	assert.Equal(t, smartmontools.ErrPermissionDenied.Error(), change.Reason)
+	assert.IsType(t, PollFailed{}, events[2], "the state is not reported again")
+	assert.IsType(t, PollFailed{}, events[3])
+}
+
+func TestMonitor_HealthStates_StandbyAndPollFailures(t *testing.T) {
+	failing := ataInfo(false, 40, 5, 0)
+	failing.SmartStatus.Damaged = true
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{
+			"/dev/sda": {failing, {InStandby: true}, failing, nil, failing, nil, nil, failing},
+		},
+		polls: map[string]int{},
+	}
+	events := collect(t, New(client, WithInterval(time.Millisecond), WithHealthStates()), 7)
+	var kinds []string
+	var changes []HealthStateChanged
+	for _, e := range events {
+		kinds = append(kinds, e.Kind())
+		if c, ok := e.(HealthStateChanged); ok {
+			changes = append(changes, c)
+		}
+	}
+	assert.Equal(t, []string{
+		KindHealthChanged, KindHealthStateChanged,
+		KindPollFailed, // a single failure keeps the state
+		KindPollFailed, KindPollFailed, KindHealthStateChanged,
+		KindHealthStateChanged,
+	}, kinds, "standby keeps the state")
+	require.Len(t, changes, 3)
+	assert.Equal(t, HealthFailing, changes[0].Current)
+	assert.Equal(t, HealthFailing, changes[1].Previous)
+	assert.Equal(t, HealthUnknown, changes[1].Current)
+	assert.Equal(t, errTimeout.Error(), changes[1].Reason)
+	assert.Equal(t, HealthUnknown, changes[2].Previous)
+	assert.Equal(t, HealthFailing, changes[2].Current)
+}
+
+func TestMonitor_HealthStates_FollowIdentity(t *testing.T) {
+	failing := ataInfo(false, 40, 5, 0)
+	failing.SmartStatus.Damaged = true
+	other := ataInfo(true, 40, 0, 0)
+	other.SerialNumber = "SER2"
+	otherFailing := ataInfo(false, 40, 0, 0)
+	otherFailing.SerialNumber = "SER2"
+	otherFailing.SmartStatus.Damaged = true
+	client := &fakeClient{
+		scripts: map[string][]*smartmontools.SMARTInfo{"/dev/sda": {failing, other, otherFailing}},
+		polls:   map[string]int{},
+	}
+	events := collect(t, New(client, WithInterval(time.Millisecond), WithHealthStates()), 4)
+	require.IsType(t, HealthStateChanged{}, events[3])
+	change := events[3].(HealthStateChanged)
+	assert.Equal(t, "SER2", change.Serial)
+	assert.Equal(t, HealthOK, change.Previous, "not the state of the drive previously at the path")
+	assert.Equal(t, HealthFailing, change.Current)
+}
@@ -0,0 +1,244 @@
+// Package notify delivers monitor events and rule alerts to external
+// alerting pipelines.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dianlight/smartmontools-go/blockdev"
+	"github.com/dianlight/smartmontools-go/monitor"
+	"github.com/dianlight/smartmontools-go/rules"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of the request body,
+// formatted as "sha256=<hex>", when a secret is configured.
+const SignatureHeader = "X-Smartmontools-Signature"
+
+// Sink receives monitor events.
+type Sink interface {
+	Send(ctx context.Context, event monitor.Event) error
+}
+
+// Payload is the JSON document POSTed by Webhook.
+type Payload struct {
+	Kind   string    `json:"kind"`
+	Device string    `json:"device,omitempty"`
+	Time   time.Time `json:"time"`
+	// Event is the monitor event or rules.Alert being delivered.
+	Event any `json:"event"`
+	// Error is set for monitor.PollFailed events.
+	Error string `json:"error,omitempty"`
+	// Mountpoints lists the filesystems hosted by Device, when enabled with
+	// WithMountpoints.
+	Mountpoints []string `json:"mountpoints,omitempty"`
+}
+
+// KindAlert is the Payload.Kind of alerts sent with Webhook.SendAlert.
+const KindAlert = "alert"
+
+// WebhookOption configures a Webhook.
+type WebhookOption func(*Webhook)
+
+// WithSecret enables HMAC-SHA256 signing of request bodies with secret.
+func WithSecret(secret []byte) WebhookOption {
+	return func(w *Webhook) {
+		w.secret = append([]byte(nil), secret...)
+	}
+}
+
+// WithRetries sets how many times a failed delivery is retried (default 3).
+// Network errors, 429 and 5xx responses are retried; other statuses are not.
+func WithRetries(retries int) WebhookOption {
+	return func(w *Webhook) {
+		if retries >= 0 {
+			w.retries = retries
+		}
+	}
+}
+
+// WithBackoff sets the delay before the first retry (default 1s). The delay
+// doubles after each attempt.
+func WithBackoff(backoff time.Duration) WebhookOption {
+	return func(w *Webhook) {
+		if backoff > 0 {
+			w.backoff = backoff
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client (default: a client with a 10s timeout).
+func WithHTTPClient(client *http.Client) WebhookOption {
+	return func(w *Webhook) {
+		if client != nil {
+			w.client = client
+		}
+	}
+}
+
+// WithHeader adds a header to every request, e.g. for bearer authentication.
+func WithHeader(key, value string) WebhookOption {
+	return func(w *Webhook) {
+		w.headers.Add(key, value)
+	}
+}
+
+// WithMountpoints adds the mountpoints of the filesystems hosted by the
+// device to every event and alert, as found by blockdev.Mountpoints, so
+// receivers can tell which data is at risk. Devices whose layout cannot be
+// read are sent without mountpoints.
+func WithMountpoints() WebhookOption {
+	return func(w *Webhook) {
+		w.mountpoints = blockdev.Mountpoints
+	}
+}
+
+// Webhook is a Sink POSTing events as JSON to a URL.
+type Webhook struct {
+	url         string
+	secret      []byte
+	retries     int
+	backoff     time.Duration
+	client      *http.Client
+	headers     http.Header
+	mountpoints func(devicePath string) ([]string, error) // set by WithMountpoints
+}
+
+var _ Sink = (*Webhook)(nil)
+
+// NewWebhook returns a Webhook posting to rawURL, which must be an absolute
+// http or https URL.
+func NewWebhook(rawURL string, opts ...WebhookOption) (*Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("notify: invalid webhook URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("notify: invalid webhook URL %q: must be an absolute http(s) URL", rawURL)
+	}
+	w := &Webhook{
+		url:     rawURL,
+		retries: 3,
+		backoff: time.Second,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		headers: make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w, nil
+}
+
+// Send delivers a monitor event.
+func (w *Webhook) Send(ctx context.Context, event monitor.Event) error {
+	p := Payload{Kind: event.Kind(), Device: event.Device(), Time: event.Time(), Event: event}
+	if failed, ok := event.(monitor.PollFailed); ok && failed.Err != nil {
+		p.Error = failed.Err.Error()
+	}
+	p.Mountpoints = w.mountpointsOf(p.Device)
+	return w.Post(ctx, p)
+}
+
+// SendAlert delivers a rule alert outside of a Monitor.
+func (w *Webhook) SendAlert(ctx context.Context, alert rules.Alert) error {
+	return w.Post(ctx, Payload{Kind: KindAlert, Device: alert.Device, Time: time.Now(), Event: alert, Mountpoints: w.mountpointsOf(alert.Device)})
+}
+
+// mountpointsOf returns the mountpoints hosted by devicePath when
+// WithMountpoints is enabled.
+func (w *Webhook) mountpointsOf(devicePath string) []string {
+	if w.mountpoints == nil || devicePath == "" {
+		return nil
+	}
+	mountpoints, err := w.mountpoints(devicePath)
+	if err != nil {
+		return nil
+	}
+	return mountpoints
+}
+
+// Post delivers an arbitrary payload, retrying transient failures.
+func (w *Webhook) Post(ctx context.Context, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("notify: encode payload: %w", err)
+	}
+	backoff := w.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil || !retry || attempt >= w.retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post performs a single delivery attempt and reports whether a failure is
+// worth retrying.
+func (w *Webhook) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("notify: build request: %w", err)
+	}
+	for key, values := range w.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.secret, body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("notify: post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("notify: webhook returned %s", resp.Status)
+}
+
+// Sign returns the SignatureHeader value for body: "sha256=" followed by the
+// hex HMAC-SHA256 of body keyed with secret. Receivers should compare it with
+// hmac.Equal.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Forward sends every event received from events to sink until the channel is
+// closed or ctx is cancelled. Delivery errors are passed to onError, which
+// may be nil.
+func Forward(ctx context.Context, events <-chan monitor.Event, sink Sink, onError func(monitor.Event, error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := sink.Send(ctx, event); err != nil && onError != nil {
+				onError(event, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dianlight/smartmontools-go/monitor"
+	"github.com/dianlight/smartmontools-go/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhook_InvalidURL(t *testing.T) {
+	for _, u := range []string{"", "ftp://host/x", "/relative", "http://"} {
+		_, err := NewWebhook(u)
+		assert.Error(t, err, u)
+	}
+}
+
+func TestWebhook_SendSigned(t *testing.T) {
+	secret := []byte("s3cret")
+	var (
+		mu       sync.Mutex
+		body     []byte
+		sig, tok string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ = io.ReadAll(r.Body)
+		sig = r.Header.Get(SignatureHeader)
+		tok = r.Header.Get("Authorization")
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	hook, err := NewWebhook(srv.URL, WithSecret(secret), WithHeader("Authorization", "Bearer x"))
+	require.NoError(t, err)
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := monitor.HealthChanged{EventBase: monitor.EventBase{DevicePath: "/dev/sda", Serial: "SER1", At: at}, Passed: false}
+	require.NoError(t, hook.Send(context.Background(), event))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, Sign(secret, body), sig)
+	assert.Equal(t, "Bearer x", tok)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, monitor.KindHealthChanged, got["kind"])
+	assert.Equal(t, "/dev/sda", got["device"])
+	assert.Equal(t, "SER1", got["event"].(map[string]any)["serial"])
+}
+
+func TestWebhook_Mountpoints(t *testing.T) {
+	bodies := make(chan []byte, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	hook, err := NewWebhook(srv.URL, WithMountpoints())
+	require.NoError(t, err)
+	hook.mountpoints = func(devicePath string) ([]string, error) {
+		if devicePath != "/dev/sdb" {
+			return nil, errors.New("not in sysfs")
+		}
+		return []string{"/srv/photos"}, nil
+	}
+	ctx := context.Background()
+	require.NoError(t, hook.Send(ctx, monitor.HealthChanged{EventBase: monitor.EventBase{DevicePath: "/dev/sdb"}}))
+	require.NoError(t, hook.SendAlert(ctx, rules.Alert{Rule: "r", Device: "/dev/sdc"}))
+
+	var got Payload
+	require.NoError(t, json.Unmarshal(<-bodies, &got))
+	assert.Equal(t, []string{"/srv/photos"}, got.Mountpoints)
+	got = Payload{}
+	require.NoError(t, json.Unmarshal(<-bodies, &got))
+	assert.Empty(t, got.Mountpoints)
+}
+
+func TestWebhook_Retries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	hook, err := NewWebhook(srv.URL, WithBackoff(time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, hook.SendAlert(context.Background(), rules.Alert{Rule: "hot", Device: "/dev/sda"}))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWebhook_NoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	hook, err := NewWebhook(srv.URL, WithBackoff(time.Millisecond))
+	require.NoError(t, err)
+	err = hook.Send(context.Background(), monitor.PollFailed{EventBase: monitor.EventBase{DevicePath: "/dev/sda"}, Err: errors.New("boom")})
+	assert.ErrorContains(t, err, "400")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestWebhook_GivesUp(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	hook, err := NewWebhook(srv.URL, WithRetries(1), WithBackoff(time.Millisecond))
+	require.NoError(t, err)
+	assert.Error(t, hook.SendAlert(context.Background(), rules.Alert{}))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+type recordingSink struct {
+	events []monitor.Event
+}
+
+func (s *recordingSink) Send(ctx context.Context, event monitor.Event) error {
+	s.events = append(s.events, event)
+	if _, ok := event.(monitor.PollFailed); ok {
+		return errors.New("rejected")
+	}
+	return nil
+}
+
+func TestForward(t *testing.T) {
+	events := make(chan monitor.Event, 2)
+	events <- monitor.HealthChanged{}
+	events <- monitor.PollFailed{}
+	close(events)
+
+	sink := &recordingSink{}
+	var failed []monitor.Event
+	Forward(context.Background(), events, sink, func(e monitor.Event, err error) { failed = append(failed, e) })
+	assert.Len(t, sink.events, 2)
+	require.Len(t, failed, 1)
+	assert.Equal(t, monitor.KindPollFailed, failed[0].Kind())
+}
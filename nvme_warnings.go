@@ -0,0 +1,74 @@
+package smartmontools
+
+import "fmt"
+
+// NvmeWarningKind identifies the condition reported by an NvmeWarning.
+type NvmeWarningKind string
+
+// NVMe spare and wear warning kinds.
+const (
+	// NvmeSpareBelowThreshold means available_spare dropped below
+	// available_spare_threshold, or the drive set the spare bit of its
+	// critical warning. The drive may turn read-only soon.
+	NvmeSpareBelowThreshold NvmeWarningKind = "spare_below_threshold"
+	// NvmeNearRatedEndurance means percentage_used reached 90%.
+	NvmeNearRatedEndurance NvmeWarningKind = "near_rated_endurance"
+	// NvmePastRatedEndurance means percentage_used reached 100%: the drive
+	// has written its rated endurance.
+	NvmePastRatedEndurance NvmeWarningKind = "past_rated_endurance"
+)
+
+// nvmeNearEndurancePercent is the percentage_used from which
+// NvmeNearRatedEndurance is reported, matching HealthScore's wear penalty.
+const nvmeNearEndurancePercent = 90
+
+// NvmeWarning is a spare or wear condition found by NvmeWearWarnings.
+type NvmeWarning struct {
+	Kind NvmeWarningKind `json:"kind"`
+	// Critical is set for conditions that call for replacing the drive.
+	Critical bool `json:"critical"`
+	// Value is the percentage that triggered the warning and Threshold the
+	// level it was compared with.
+	Value     int    `json:"value"`
+	Threshold int    `json:"threshold"`
+	Message   string `json:"message"`
+}
+
+// NvmeWearWarnings evaluates the available spare and percentage_used of an
+// NVMe drive and returns the warnings that apply, most severe first, for
+// alerting layers to forward. It returns nil for healthy drives and for
+// drives without an NVMe health log.
+func NvmeWearWarnings(info *SMARTInfo) []NvmeWarning {
+	if info == nil || info.NvmeSmartHealth == nil {
+		return nil
+	}
+	health := info.NvmeSmartHealth
+	var warnings []NvmeWarning
+	if (health.AvailableSpareThresh > 0 && health.AvailableSpare < health.AvailableSpareThresh) || health.CriticalWarning&0x01 != 0 {
+		warnings = append(warnings, NvmeWarning{
+			Kind:      NvmeSpareBelowThreshold,
+			Critical:  true,
+			Value:     health.AvailableSpare,
+			Threshold: health.AvailableSpareThresh,
+			Message:   fmt.Sprintf("spare below threshold: %d%% available, threshold %d%%", health.AvailableSpare, health.AvailableSpareThresh),
+		})
+	}
+	switch {
+	case health.PercentageUsed >= 100:
+		warnings = append(warnings, NvmeWarning{
+			Kind:      NvmePastRatedEndurance,
+			Critical:  true,
+			Value:     health.PercentageUsed,
+			Threshold: 100,
+			Message:   fmt.Sprintf("device past rated endurance: %d%% used", health.PercentageUsed),
+		})
+	case health.PercentageUsed >= nvmeNearEndurancePercent:
+		warnings = append(warnings, NvmeWarning{
+			Kind:      NvmeNearRatedEndurance,
+			Value:     health.PercentageUsed,
+			Threshold: nvmeNearEndurancePercent,
+			Message:   fmt.Sprintf("device nearing rated endurance: %d%% used", health.PercentageUsed),
+		})
+	}
+	return warnings
+}
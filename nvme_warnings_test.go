@@ -0,0 +1,38 @@
+package smartmontools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNvmeWearWarnings(t *testing.T) {
+	healthy := &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{AvailableSpare: 100, AvailableSpareThresh: 10, PercentageUsed: 12}}
+	assert.Empty(t, NvmeWearWarnings(healthy))
+	assert.Empty(t, NvmeWearWarnings(&SMARTInfo{}))
+	assert.Empty(t, NvmeWearWarnings(nil))
+
+	worn := &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{AvailableSpare: 5, AvailableSpareThresh: 10, PercentageUsed: 104}}
+	warnings := NvmeWearWarnings(worn)
+	require.Len(t, warnings, 2)
+	assert.Equal(t, NvmeSpareBelowThreshold, warnings[0].Kind)
+	assert.True(t, warnings[0].Critical)
+	assert.Equal(t, 5, warnings[0].Value)
+	assert.Equal(t, 10, warnings[0].Threshold)
+	assert.Equal(t, "spare below threshold: 5% available, threshold 10%", warnings[0].Message)
+	assert.Equal(t, NvmePastRatedEndurance, warnings[1].Kind)
+	assert.Equal(t, "device past rated endurance: 104% used", warnings[1].Message)
+
+	aging := &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{AvailableSpare: 100, AvailableSpareThresh: 10, PercentageUsed: 92}}
+	warnings = NvmeWearWarnings(aging)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, NvmeNearRatedEndurance, warnings[0].Kind)
+	assert.False(t, warnings[0].Critical)
+
+	// The drive's own critical warning bit counts even without a threshold.
+	flagged := &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{CriticalWarning: 0x01}}
+	warnings = NvmeWearWarnings(flagged)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, NvmeSpareBelowThreshold, warnings[0].Kind)
+}
@@ -0,0 +1,13 @@
+// Package otel traces smartctl invocations with OpenTelemetry: each run
+// becomes a span, a child of the span in the context the client was called
+// with, so slow probes and repeated retries show up in the host
+// application's traces.
+//
+// Install the hook on a client:
+//
+//	client, _ := smartmontools.NewClient(
+//		smartmontools.WithCommandHook(smartotel.CommandHook()))
+//
+// This package lives in its own Go module so that the OpenTelemetry
+// dependencies are only pulled in by users who need them.
+package otel
@@ -0,0 +1,95 @@
+package otel
+
+import (
+	"context"
+	"errors"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer.
+const ScopeName = "github.com/dianlight/smartmontools-go/otel"
+
+// Attribute keys of smartctl spans and fallback events.
+const (
+	DeviceKey     = attribute.Key("smartctl.device")      // e.g. "/dev/sda"
+	ArgsKey       = attribute.Key("smartctl.args")        // arguments, device last
+	ExitCodeKey   = attribute.Key("smartctl.exit_code")   // -1 when smartctl did not run
+	ExitStatusKey = attribute.Key("smartctl.exit_status") // decoded bits, e.g. "0x04 (command failed)"
+	FallbackKey   = attribute.Key("smartctl.fallback")    // smartmontools.Fallback* reason
+)
+
+// FallbackEventName is the name of the event added to the caller's span when
+// the client falls back to another invocation.
+const FallbackEventName = "smartctl.fallback"
+
+// Option configures CommandHook.
+type Option func(*config)
+
+type config struct {
+	provider trace.TracerProvider
+}
+
+// WithTracerProvider creates spans with provider instead of the global
+// provider of go.opentelemetry.io/otel.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// CommandHook returns a command hook that records every smartctl invocation
+// as a "smartctl" span, a child of the span in the context the client was
+// called with. The span covers the run time of smartctl and carries the
+// device, arguments, exit code and decoded exit status. Failed invocations
+// set the span status to Error; drive health bits alone do not. Fallback
+// invocations, such as the SAT probe after the auto-detected protocol failed,
+// also add a FallbackEventName event to the caller's span.
+func CommandHook(opts ...Option) smartmontools.CommandHook {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(ctx context.Context, e smartmontools.CommandEvent) {
+		provider := cfg.provider
+		if provider == nil {
+			provider = otel.GetTracerProvider()
+		}
+		attrs := []attribute.KeyValue{ArgsKey.StringSlice(e.Args), ExitCodeKey.Int(e.ExitCode)}
+		if e.Device != "" {
+			attrs = append(attrs, DeviceKey.String(e.Device))
+		}
+		if e.ExitCode > 0 {
+			attrs = append(attrs, ExitStatusKey.String(smartmontools.ExitStatus(e.ExitCode).String()))
+		}
+		if e.Fallback != "" {
+			attrs = append(attrs, FallbackKey.String(e.Fallback))
+			trace.SpanFromContext(ctx).AddEvent(FallbackEventName, trace.WithTimestamp(e.Start),
+				trace.WithAttributes(FallbackKey.String(e.Fallback), DeviceKey.String(e.Device)))
+		}
+
+		_, span := provider.Tracer(ScopeName).Start(ctx, "smartctl",
+			trace.WithTimestamp(e.Start),
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(attrs...))
+		if failed(e.Err) {
+			span.RecordError(e.Err)
+			span.SetStatus(codes.Error, e.Err.Error())
+		}
+		span.End(trace.WithTimestamp(e.Start.Add(e.Duration)))
+	}
+}
+
+// failed reports whether err is an invocation failure rather than smartctl
+// reporting drive health problems through its exit status.
+func failed(err error) bool {
+	var smartctlErr *smartmontools.SmartctlError
+	if errors.As(err, &smartctlErr) {
+		return smartctlErr.Status.ExecFailed()
+	}
+	return err != nil
+}
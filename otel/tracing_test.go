@@ -0,0 +1,83 @@
+package otel
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// scriptedCommander answers smartctl invocations by their arguments.
+type scriptedCommander map[string]*smartmontools.CommandResult
+
+func (c scriptedCommander) CommandContext(ctx context.Context, logger smartmontools.LogAdapter, req smartmontools.CommandRequest) (*smartmontools.CommandResult, error) {
+	if res, ok := c[strings.Join(req.Args, " ")]; ok {
+		return res, nil
+	}
+	return &smartmontools.CommandResult{ExitCode: -1}, assert.AnError
+}
+
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestCommandHook(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client, err := smartmontools.NewClient(
+		smartmontools.WithSmartctlPath("/usr/sbin/smartctl"),
+		smartmontools.WithContextCommander(scriptedCommander{
+			"-H --nocheck=standby /dev/sda": {Stdout: []byte("PASSED")},
+			"--scan-open --json":            {Stdout: []byte(`{"devices":[]}`)},
+			"--scan --json":                 {Stdout: []byte(`{"devices":[{"name":"/dev/sda","type":"sat"}]}`)},
+		}),
+		smartmontools.WithCommandHook(CommandHook(WithTracerProvider(provider))),
+	)
+	require.NoError(t, err)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "poll")
+	_, err = client.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, err = client.ScanDevices(ctx)
+	require.NoError(t, err)
+	_, _ = client.CheckHealth(ctx, "/dev/sdz")
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 5)
+	health, scanOpen, scan, failure := spans[0], spans[1], spans[2], spans[3]
+	for _, span := range spans[:4] {
+		assert.Equal(t, "smartctl", span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, ScopeName, span.InstrumentationScope().Name)
+	}
+	assert.Equal(t, "/dev/sda", attrs(health)[DeviceKey].AsString())
+	assert.Equal(t, []string{"-H", "--nocheck=standby", "/dev/sda"}, attrs(health)[ArgsKey].AsStringSlice())
+	assert.Equal(t, int64(0), attrs(health)[ExitCodeKey].AsInt64())
+	assert.Equal(t, codes.Unset, health.Status().Code)
+	assert.NotContains(t, attrs(scanOpen), FallbackKey)
+	assert.Equal(t, smartmontools.FallbackScan, attrs(scan)[FallbackKey].AsString())
+	assert.Equal(t, codes.Error, failure.Status().Code)
+
+	events := spans[4].Events()
+	require.Len(t, events, 1, "the fallback is recorded on the caller's span")
+	assert.Equal(t, FallbackEventName, events[0].Name)
+}
+
+func TestFailed(t *testing.T) {
+	assert.False(t, failed(nil))
+	assert.False(t, failed(&smartmontools.SmartctlError{Status: smartmontools.ExitDiskFailing}), "health bits are not failures")
+	assert.True(t, failed(&smartmontools.SmartctlError{Status: smartmontools.ExitCommandFailed}))
+	assert.True(t, failed(assert.AnError))
+}
@@ -0,0 +1,48 @@
+package smartmontools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPowerMode_ExecBackend(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -i -n idle /dev/sda": {output: []byte("Power mode is:    ACTIVE or IDLE\n")},
+		}}),
+	)
+	require.NoError(t, err)
+
+	mode, err := client.GetPowerMode(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, PowerModeActive, mode)
+}
+
+func TestGetPowerMode_UnsupportedBackend(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	mode, err := client.GetPowerMode(context.Background(), "/dev/sda")
+	assert.ErrorContains(t, err, `backend "plain" does not report power modes`)
+	assert.Equal(t, PowerModeUnknown, mode)
+}
+
+func TestSetStandbyTimer_UnsupportedBackend(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, client.SetStandbyTimer(context.Background(), "/dev/sda", time.Minute), "does not manage power modes")
+	assert.ErrorContains(t, client.StandbyNow(context.Background(), "/dev/sda"), "does not manage power modes")
+}
+
+func TestWakeDevice_UnsupportedBackend(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, client.WakeDevice(context.Background(), "/dev/sda"), "does not manage power modes")
+}
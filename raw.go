@@ -0,0 +1,30 @@
+package smartmontools
+
+import (
+	"time"
+
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
+)
+
+// RawTemperature is a decoded temperature attribute raw string.
+type RawTemperature = smtypes.RawTemperature
+
+// ParseRawTemperature decodes temperature raw strings such as "29 (Min/Max 18/56)".
+func ParseRawTemperature(raw string) (RawTemperature, bool) {
+	return smtypes.ParseRawTemperature(raw)
+}
+
+// ParseRawDuration decodes power-on raw strings such as "35825h+02m+39.040s".
+func ParseRawDuration(raw string) (time.Duration, bool) {
+	return smtypes.ParseRawDuration(raw)
+}
+
+// RawWords16 splits a 48-bit raw value into three 16-bit words, least significant first.
+func RawWords16(value int64) [3]uint16 {
+	return smtypes.RawWords16(value)
+}
+
+// RawBytes splits a 48-bit raw value into six bytes, least significant first.
+func RawBytes(value int64) [6]uint8 {
+	return smtypes.RawBytes(value)
+}
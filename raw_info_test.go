@@ -0,0 +1,71 @@
+package smartmontools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rawInfoJSON = `{"json_format_version":[1,0],"device":{"name":"/dev/sda","type":"sat"},"serial_number":"RAW1","future_field":{"x":1}}`
+
+// plainBackend is a Backend without RawBackend support.
+type plainBackend struct {
+	Backend
+}
+
+func (plainBackend) Name() string { return "plain" }
+
+func (plainBackend) GetSMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, error) {
+	return &SMARTInfo{Device: Device{Name: devicePath, Type: "sat"}, SerialNumber: "PLAIN1"}, nil
+}
+
+func TestGetSMARTInfoRaw_ExecBackend(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -a -j --nocheck=standby /dev/sda": {output: []byte(rawInfoJSON)},
+		}}),
+	)
+	require.NoError(t, err)
+
+	info, raw, err := client.GetSMARTInfoRaw(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "RAW1", info.SerialNumber)
+	assert.JSONEq(t, rawInfoJSON, string(raw))
+}
+
+func TestGetSMARTInfoRaw_FallbackEncodesInfo(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	info, raw, err := client.GetSMARTInfoRaw(context.Background(), "/dev/sdb")
+	require.NoError(t, err)
+	assert.Equal(t, "PLAIN1", info.SerialNumber)
+	assert.Contains(t, string(raw), `"serial_number":"PLAIN1"`)
+}
+
+func TestRunSmartctl_ExecBackend(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -j -l devstat /dev/sda": {output: []byte(`{"ata_device_statistics":{"pages":[]}}`)},
+		}}),
+	)
+	require.NoError(t, err)
+
+	raw, status, err := client.RunSmartctl(context.Background(), "-l", "devstat", "/dev/sda")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ata_device_statistics":{"pages":[]}}`, string(raw))
+	require.NotNil(t, status)
+	assert.Equal(t, ExitStatus(0), *status)
+}
+
+func TestRunSmartctl_UnsupportedBackend(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	_, _, err = client.RunSmartctl(context.Background(), "-i", "/dev/sda")
+	assert.ErrorContains(t, err, `backend "plain" does not run smartctl commands`)
+}
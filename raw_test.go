@@ -0,0 +1,87 @@
+package smartmontools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRawTemperature(t *testing.T) {
+	got, ok := ParseRawTemperature("29 (Min/Max 18/56)")
+	require.True(t, ok)
+	assert.Equal(t, 29, got.Current)
+	require.NotNil(t, got.Min)
+	require.NotNil(t, got.Max)
+	assert.Equal(t, 18, *got.Min)
+	assert.Equal(t, 56, *got.Max)
+
+	got, ok = ParseRawTemperature("36 (0 21 0 0 0)")
+	require.True(t, ok)
+	assert.Equal(t, 36, got.Current)
+	assert.Nil(t, got.Min)
+
+	got, ok = ParseRawTemperature("41")
+	require.True(t, ok)
+	assert.Equal(t, 41, got.Current)
+
+	_, ok = ParseRawTemperature("n/a")
+	assert.False(t, ok)
+}
+
+func TestParseRawDuration(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want time.Duration
+		ok   bool
+	}{
+		{"35825h+02m+39.040s", 35825*time.Hour + 2*time.Minute + 39040*time.Millisecond, true},
+		{"1234h+05m", 1234*time.Hour + 5*time.Minute, true},
+		{"17h", 17 * time.Hour, true},
+		{"12345", 12345 * time.Hour, true},
+		{"4321 (212 183 0)", 4321 * time.Hour, true},
+		{"", 0, false},
+		{"garbage", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, ok := ParseRawDuration(tt.raw)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRawSplitHelpers(t *testing.T) {
+	value := int64(0x0003_0002_0001)
+	assert.Equal(t, [3]uint16{1, 2, 3}, RawWords16(value))
+	assert.Equal(t, [6]uint8{0x01, 0x00, 0x02, 0x00, 0x03, 0x00}, RawBytes(value))
+
+	raw := Raw{Value: value, String: "1 (3 2)"}
+	assert.Equal(t, RawWords16(value), raw.Words16())
+	assert.Equal(t, RawBytes(value), raw.Bytes())
+}
+
+func TestPowerOnDuration(t *testing.T) {
+	info := &SMARTInfo{PowerOnTime: &PowerOnTime{Hours: 100, Minutes: 30}}
+	d, ok := info.PowerOnDuration()
+	require.True(t, ok)
+	assert.Equal(t, 100*time.Hour+30*time.Minute, d)
+
+	// Without the top-level field, attribute 9's raw string is decoded.
+	info = &SMARTInfo{AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+		{ID: SmartAttrPowerOnHours, Raw: Raw{Value: 35825, String: "35825h+02m+39.040s"}},
+	}}}
+	d, ok = info.PowerOnDuration()
+	require.True(t, ok)
+	assert.Equal(t, 35825*time.Hour+2*time.Minute+39040*time.Millisecond, d)
+
+	info = &SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{PowerOnHours: 5678}}
+	d, ok = info.PowerOnDuration()
+	require.True(t, ok)
+	assert.Equal(t, 5678*time.Hour, d)
+
+	_, ok = (&SMARTInfo{}).PowerOnDuration()
+	assert.False(t, ok)
+}
@@ -0,0 +1,193 @@
+package smartmontools
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// resultCache memoizes per-device query results for WithCacheTTL. Entries
+// are dropped when they expire or when an operation changes the device state.
+//
+// SMARTInfo entries are keyed by drive identity (WWN and serial number), so
+// every path leading to a drive shares them; drives that report neither are
+// keyed by path. A path is served an identity's entry only while a read
+// through that path has shown, within the TTL, that it leads to the drive.
+// When a read shows that a path now leads to another drive, the other paths
+// bound to either drive are forgotten too, as drives may have swapped paths,
+// and are read again before their data is served.
+type resultCache struct {
+	ttl        time.Duration
+	now        func() time.Time
+	mu         sync.Mutex
+	paths      map[string]pathBinding      // device path -> drive last read through it
+	smartInfo  map[string]cachedSMARTInfo  // entry key -> result
+	deviceInfo map[string]cachedDeviceInfo // device path -> result
+}
+
+// pathBinding records which drive a read through a path has revealed.
+type pathBinding struct {
+	key     string
+	expires time.Time
+}
+
+type cachedSMARTInfo struct {
+	info    *SMARTInfo
+	raw     json.RawMessage // nil when the entry came from GetSMARTInfo
+	rawPath string          // the path raw was read through
+	expires time.Time
+}
+
+type cachedDeviceInfo struct {
+	info    map[string]interface{}
+	expires time.Time
+}
+
+func newResultCache(ttl time.Duration) *resultCache {
+	return &resultCache{
+		ttl:        ttl,
+		now:        time.Now,
+		paths:      make(map[string]pathBinding),
+		smartInfo:  make(map[string]cachedSMARTInfo),
+		deviceInfo: make(map[string]cachedDeviceInfo),
+	}
+}
+
+// entryKey returns the key of the SMARTInfo entry read through devicePath:
+// the drive's identity key, or the path for drives without one.
+func entryKey(devicePath string, info *SMARTInfo) string {
+	if key := IdentityOf(info).Key(); key != "" {
+		return key
+	}
+	return "path:" + devicePath
+}
+
+// getSMARTInfo returns a deep copy of the cached SMARTInfo of the drive
+// devicePath leads to, named after devicePath, so that callers cannot change
+// the cached entry or each other's results. With needRaw, entries stored
+// without the raw document, or with one read through another path, are
+// ignored.
+func (rc *resultCache) getSMARTInfo(devicePath string, needRaw bool) (*SMARTInfo, json.RawMessage, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	now := rc.now()
+	b, ok := rc.paths[devicePath]
+	if !ok || !now.Before(b.expires) {
+		return nil, nil, false
+	}
+	e, ok := rc.smartInfo[b.key]
+	if !ok || !now.Before(e.expires) || (needRaw && (e.raw == nil || e.rawPath != devicePath)) {
+		return nil, nil, false
+	}
+	info, err := cloneSMARTInfo(e.info)
+	if err != nil {
+		return nil, nil, false
+	}
+	info.Device.Name = devicePath
+	var raw json.RawMessage
+	if needRaw {
+		raw = slices.Clone(e.raw)
+	}
+	return info, raw, true
+}
+
+// putSMARTInfo caches a deep copy of info, so that the caller may keep
+// changing its own, and binds devicePath to the drive it reports. Results
+// that cannot be copied are not cached.
+func (rc *resultCache) putSMARTInfo(devicePath string, info *SMARTInfo, raw json.RawMessage) {
+	if info == nil {
+		return
+	}
+	stored, err := cloneSMARTInfo(info)
+	if err != nil {
+		return
+	}
+	key := entryKey(devicePath, info)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	previous, known := rc.paths[devicePath]
+	if !known || previous.key != key {
+		// What was cached through the path may belong to another drive.
+		delete(rc.deviceInfo, devicePath)
+	}
+	if known && previous.key != key {
+		// The path now leads to another drive: the paths that led to either
+		// drive may have been re-enumerated as well.
+		rc.unbind(previous.key, devicePath)
+		rc.unbind(key, devicePath)
+	}
+	expires := rc.now().Add(rc.ttl)
+	rc.paths[devicePath] = pathBinding{key: key, expires: expires}
+	rc.smartInfo[key] = cachedSMARTInfo{info: stored, raw: slices.Clone(raw), rawPath: devicePath, expires: expires}
+}
+
+// unbind forgets which drive the paths bound to key, except devicePath, lead
+// to, along with what was cached through them. rc.mu must be held.
+func (rc *resultCache) unbind(key, devicePath string) {
+	for path, b := range rc.paths {
+		if b.key == key && path != devicePath {
+			delete(rc.paths, path)
+			delete(rc.deviceInfo, path)
+		}
+	}
+}
+
+func (rc *resultCache) getDeviceInfo(devicePath string) (map[string]interface{}, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, ok := rc.deviceInfo[devicePath]
+	if !ok || !rc.now().Before(e.expires) {
+		return nil, false
+	}
+	return maps.Clone(e.info), true
+}
+
+func (rc *resultCache) putDeviceInfo(devicePath string, info map[string]interface{}) {
+	if info == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.deviceInfo[devicePath] = cachedDeviceInfo{info: maps.Clone(info), expires: rc.now().Add(rc.ttl)}
+}
+
+// invalidate drops the cached results of the drive devicePath last led to,
+// including those cached through its other paths.
+func (rc *resultCache) invalidate(devicePath string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.smartInfo, entryKey(devicePath, nil))
+	delete(rc.deviceInfo, devicePath)
+	b, ok := rc.paths[devicePath]
+	if !ok {
+		return
+	}
+	delete(rc.smartInfo, b.key)
+	for path, other := range rc.paths {
+		if other.key == b.key {
+			delete(rc.deviceInfo, path)
+		}
+	}
+}
+
+// cloneSMARTInfo returns a deep copy of info: a JSON round trip, plus the
+// computed fields SMARTInfo does not encode.
+func cloneSMARTInfo(info *SMARTInfo) (*SMARTInfo, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	c := &SMARTInfo{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	c.DiskType = info.DiskType
+	if info.ExitCodeInfo != nil {
+		exitCodeInfo := *info.ExitCodeInfo
+		c.ExitCodeInfo = &exitCodeInfo
+	}
+	c.Warnings = slices.Clone(info.Warnings)
+	return c, nil
+}
@@ -0,0 +1,331 @@
+package smartmontools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingBackend counts backend queries per method.
+type countingBackend struct {
+	Backend
+	smartCalls  int
+	deviceCalls int
+	fail        bool
+	serial      string
+	serials     map[string]string // per device path, overriding serial
+	during      func()            // run while a state-changing command executes
+}
+
+func (b *countingBackend) Name() string { return "counting" }
+
+func (b *countingBackend) GetSMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, error) {
+	b.smartCalls++
+	if b.fail {
+		return nil, errors.New("smartctl failed")
+	}
+	serial := b.serial
+	if s, ok := b.serials[devicePath]; ok {
+		serial = s
+	}
+	return &SMARTInfo{Device: Device{Name: devicePath}, ModelName: "Disk", SerialNumber: serial}, nil
+}
+
+func (b *countingBackend) GetDeviceInfo(ctx context.Context, devicePath string) (map[string]interface{}, error) {
+	b.deviceCalls++
+	return map[string]interface{}{"name": devicePath}, nil
+}
+
+func (b *countingBackend) RunSelfTest(ctx context.Context, devicePath string, testType string) error {
+	if b.during != nil {
+		b.during()
+	}
+	return nil
+}
+
+func (b *countingBackend) EnableSMART(ctx context.Context, devicePath string) error { return nil }
+
+// powerBackend is a countingBackend that manages power modes.
+type powerBackend struct {
+	countingBackend
+}
+
+func (b *powerBackend) GetPowerMode(ctx context.Context, devicePath string) (PowerMode, error) {
+	return PowerModeActive, nil
+}
+
+func (b *powerBackend) SetStandbyTimer(ctx context.Context, devicePath string, timeout time.Duration) error {
+	return nil
+}
+
+func (b *powerBackend) StandbyNow(ctx context.Context, devicePath string) error { return nil }
+
+func (b *powerBackend) WakeDevice(ctx context.Context, devicePath string) error { return nil }
+
+// passthroughBackend is a countingBackend that runs smartctl commands.
+type passthroughBackend struct {
+	countingBackend
+}
+
+func (b *passthroughBackend) RunSmartctl(ctx context.Context, args ...string) (json.RawMessage, *ExitStatus, error) {
+	if b.during != nil {
+		b.during()
+	}
+	return json.RawMessage(`{}`), new(ExitStatus), nil
+}
+
+func newCachedClient(t *testing.T, backend Backend, ttl time.Duration) (*Client, *time.Time) {
+	t.Helper()
+	sc, err := NewClient(WithBackend(backend), WithCacheTTL(ttl))
+	require.NoError(t, err)
+	client := sc.(*Client)
+	now := time.Unix(1000, 0)
+	client.cache.now = func() time.Time { return now }
+	return client, &now
+}
+
+func TestCacheTTL_MemoizesUntilExpiry(t *testing.T) {
+	backend := &countingBackend{}
+	client, now := newCachedClient(t, backend, 30*time.Second)
+	ctx := context.Background()
+
+	first, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	first.ModelName = "mutated"
+
+	second, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "Disk", second.ModelName, "callers must not share the cached value")
+	assert.Equal(t, 1, backend.smartCalls)
+
+	_, err = client.GetSMARTInfo(ctx, "/dev/sdb")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.smartCalls, "entries are per device")
+
+	*now = now.Add(30 * time.Second)
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 3, backend.smartCalls)
+
+	for range 2 {
+		info, err := client.GetDeviceInfo(ctx, "/dev/sda")
+		require.NoError(t, err)
+		assert.Equal(t, "/dev/sda", info["name"])
+	}
+	assert.Equal(t, 1, backend.deviceCalls)
+}
+
+func TestCacheTTL_DeepCopies(t *testing.T) {
+	shared := &SMARTInfo{
+		SerialNumber: "SER1",
+		DiskType:     "HDD",
+		SmartStatus:  &SmartStatus{Passed: true},
+		AtaSmartData: &AtaSmartData{Table: []SmartAttribute{{ID: 5, Raw: Raw{Value: 0}}}},
+		Warnings:     []Warning{{Code: WarningPermissionDenied}},
+	}
+	client, _ := newCachedClient(t, &scriptedBackend{script: []scriptedResult{{info: shared}}}, time.Minute)
+	ctx := context.Background()
+
+	first, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	shared.SmartStatus.Passed = false
+	first.AtaSmartData.Table[0].Raw.Value = 8
+	first.Warnings[0].Code = WarningOther
+
+	second, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.True(t, second.SmartStatus.Passed, "the backend's value is copied when stored")
+	assert.Zero(t, second.AtaSmartData.Table[0].Raw.Value, "callers get their own copy")
+	assert.Equal(t, WarningPermissionDenied, second.Warnings[0].Code)
+	assert.Equal(t, "HDD", second.DiskType)
+}
+
+func TestCacheTTL_RawReusesEntry(t *testing.T) {
+	backend := &countingBackend{}
+	client, _ := newCachedClient(t, backend, time.Minute)
+	ctx := context.Background()
+
+	// A GetSMARTInfo entry has no raw document, so the first raw call queries.
+	_, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, raw, err := client.GetSMARTInfoRaw(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.NotEmpty(t, raw)
+	assert.Equal(t, 2, backend.smartCalls)
+
+	_, again, err := client.GetSMARTInfoRaw(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(raw), string(again))
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.smartCalls)
+}
+
+func TestCacheTTL_ErrorsAndInvalidation(t *testing.T) {
+	backend := &countingBackend{fail: true}
+	client, _ := newCachedClient(t, backend, time.Minute)
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := client.GetSMARTInfo(ctx, "/dev/sda")
+		require.Error(t, err)
+	}
+	assert.Equal(t, 2, backend.smartCalls, "errors are not cached")
+
+	backend.fail = false
+	_, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, err = client.GetDeviceInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+
+	require.NoError(t, client.RunSelfTest(ctx, "/dev/sda", "short"))
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 4, backend.smartCalls)
+
+	require.NoError(t, client.EnableSMART(ctx, "/dev/sda"))
+	_, err = client.GetDeviceInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.deviceCalls)
+}
+
+func TestCacheTTL_PathsShareIdentityEntries(t *testing.T) {
+	backend := &countingBackend{serial: "SER1"}
+	client, _ := newCachedClient(t, backend, time.Minute)
+	ctx := context.Background()
+
+	// The same drive reached through two paths, e.g. after re-enumeration.
+	for _, path := range []string{"/dev/sda", "/dev/sdb", "/dev/sda", "/dev/sdb"} {
+		_, err := client.GetSMARTInfo(ctx, path)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, backend.smartCalls, "each path is read once to learn its drive")
+
+	require.NoError(t, client.RunSelfTest(ctx, "/dev/sda", "short"))
+	_, err := client.GetSMARTInfo(ctx, "/dev/sdb")
+	require.NoError(t, err)
+	assert.Equal(t, 3, backend.smartCalls, "invalidating one path drops the drive's entry for all paths")
+
+	info, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 3, backend.smartCalls, "the entry read through /dev/sdb serves /dev/sda")
+	assert.Equal(t, "/dev/sda", info.Device.Name)
+}
+
+func TestCacheTTL_ReenumeratedPathsAreReadAgain(t *testing.T) {
+	backend := &countingBackend{serials: map[string]string{"/dev/sda": "SER1", "/dev/sdb": "SER2"}}
+	client, _ := newCachedClient(t, backend, time.Minute)
+	ctx := context.Background()
+
+	for _, path := range []string{"/dev/sda", "/dev/sdb"} {
+		_, err := client.GetSMARTInfo(ctx, path)
+		require.NoError(t, err)
+	}
+
+	// The drives swap paths. A fresh read of /dev/sda reveals it, and the
+	// SER2 entry it stores must not be served through /dev/sdb.
+	backend.serials = map[string]string{"/dev/sda": "SER2", "/dev/sdb": "SER1"}
+	require.NoError(t, client.RunSelfTest(ctx, "/dev/sda", "short"))
+	info, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "SER2", info.SerialNumber)
+	info, err = client.GetSMARTInfo(ctx, "/dev/sdb")
+	require.NoError(t, err)
+	assert.Equal(t, "/dev/sdb", info.Device.Name)
+	assert.Equal(t, "SER1", info.SerialNumber)
+	assert.Equal(t, 4, backend.smartCalls)
+}
+
+func TestCacheTTL_DisabledByDefault(t *testing.T) {
+	backend := &countingBackend{}
+	client, err := NewClient(WithBackend(backend), WithCacheTTL(time.Minute), WithCacheTTL(0))
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err := client.GetSMARTInfo(context.Background(), "/dev/sda")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, backend.smartCalls)
+}
+
+func TestCacheTTL_NoCheckOverrideBypassesCache(t *testing.T) {
+	backend := &countingBackend{}
+	client, _ := newCachedClient(t, backend, time.Minute)
+
+	_, err := client.GetSMARTInfo(context.Background(), "/dev/sda")
+	require.NoError(t, err)
+	ctx := ContextWithNoCheck(context.Background(), NoCheckPolicy{Mode: NoCheckNever})
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.smartCalls)
+}
+
+func TestCacheTTL_PowerModeChangesInvalidate(t *testing.T) {
+	backend := &powerBackend{}
+	client, _ := newCachedClient(t, backend, time.Minute)
+	ctx := context.Background()
+
+	_, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	require.NoError(t, client.WakeDevice(ctx, "/dev/sda"))
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.smartCalls, "a wake drops the cached standby result")
+
+	require.NoError(t, client.StandbyNow(ctx, "/dev/sda"))
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	require.NoError(t, client.SetStandbyTimer(ctx, "/dev/sda", time.Minute))
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 4, backend.smartCalls)
+}
+
+func TestCacheTTL_RunSmartctlInvalidates(t *testing.T) {
+	backend := &passthroughBackend{}
+	client, _ := newCachedClient(t, backend, time.Minute)
+	ctx := context.Background()
+
+	_, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, _, err = client.RunSmartctl(ctx, "-l", "devstat", "/dev/sda")
+	require.NoError(t, err)
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 1, backend.smartCalls, "read-only commands keep the cache")
+
+	_, _, err = client.RunSmartctl(ctx, "-s", "wcache,off", "/dev/sda")
+	require.NoError(t, err)
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.smartCalls)
+}
+
+func TestCacheTTL_InvalidatesAfterCommand(t *testing.T) {
+	backend := &passthroughBackend{}
+	client, _ := newCachedClient(t, backend, time.Minute)
+	ctx := context.Background()
+	// A read racing with the command caches the state from before it.
+	backend.during = func() {
+		_, err := client.GetSMARTInfo(ctx, "/dev/sda")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, client.RunSelfTest(ctx, "/dev/sda", "short"))
+	calls := backend.smartCalls
+	_, err := client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, calls+1, backend.smartCalls, "the entry cached during RunSelfTest is dropped")
+
+	_, _, err = client.RunSmartctl(ctx, "-s", "wcache,off", "/dev/sda")
+	require.NoError(t, err)
+	calls = backend.smartCalls
+	_, err = client.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, calls+1, backend.smartCalls, "the entry cached during RunSmartctl is dropped")
+}
@@ -0,0 +1,312 @@
+// Package rules implements a small threshold-based alert rule engine for
+// SMART data. Rules are declarative comparisons such as
+//
+//	attr(197).raw > 0
+//	temperature.current > 55
+//	nvme.percentage_used >= 90
+//	smart_status.passed == false
+//
+// compiled once into an Engine and evaluated against each SMARTInfo snapshot,
+// producing Alert values with a severity.
+//
+// Supported selectors:
+//
+//   - attr(ID).raw, attr(ID).value, attr(ID).worst, attr(ID).thresh: ATA
+//     attribute ID; raw is the counter shown by smartctl (Raw.Count)
+//   - temperature.current: current temperature (NVMe health log as fallback)
+//   - power_on_hours, power_cycle_count
+//   - smart_status.passed: 1 when the overall-health check passed, else 0;
+//     not reported when smartctl gave no verdict (SMARTInfo.HealthVerdict)
+//   - nvme.critical_warning, nvme.available_spare,
+//     nvme.available_spare_threshold, nvme.percentage_used, nvme.media_errors,
+//     nvme.num_err_log_entries, nvme.unsafe_shutdowns, nvme.temperature
+//
+// Comparison operators are >, >=, <, <=, == and !=; the right-hand side is a
+// number or true/false. A rule whose selector is not reported by a device
+// never fires for it.
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// Severity grades an alert.
+type Severity int
+
+// Alert severities, from least to most severe.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+// String returns the severity name.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so rules can be loaded
+// from JSON or YAML configuration.
+func (s *Severity) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "info", "":
+		*s = SeverityInfo
+	case "warning":
+		*s = SeverityWarning
+	case "critical":
+		*s = SeverityCritical
+	default:
+		return fmt.Errorf("rules: unknown severity %q", text)
+	}
+	return nil
+}
+
+// Rule is a declarative alert rule.
+type Rule struct {
+	// Name identifies the rule in alerts; it defaults to Expr.
+	Name     string   `json:"name,omitempty"`
+	Expr     string   `json:"expr"`
+	Severity Severity `json:"severity"`
+	// Message is an optional human-readable description copied into alerts.
+	Message string `json:"message,omitempty"`
+}
+
+// Alert is produced when a rule matches a SMARTInfo snapshot.
+type Alert struct {
+	Rule     string   `json:"rule"`
+	Expr     string   `json:"expr"`
+	Severity Severity `json:"severity"`
+	Device   string   `json:"device,omitempty"`
+	Serial   string   `json:"serial,omitempty"`
+	// Value is the selector value that matched.
+	Value   float64 `json:"value"`
+	Message string  `json:"message"`
+}
+
+type selector func(info *smartmontools.SMARTInfo) (float64, bool)
+
+type compiledRule struct {
+	Rule
+	selector  selector
+	op        string
+	threshold float64
+}
+
+func (r compiledRule) matches(v float64) bool {
+	switch r.op {
+	case ">":
+		return v > r.threshold
+	case ">=":
+		return v >= r.threshold
+	case "<":
+		return v < r.threshold
+	case "<=":
+		return v <= r.threshold
+	case "==":
+		return v == r.threshold
+	default:
+		return v != r.threshold
+	}
+}
+
+// Engine evaluates a fixed set of compiled rules. It is safe for concurrent use.
+type Engine struct {
+	rules []compiledRule
+}
+
+// Compile parses rules into an Engine. It reports the first invalid rule.
+func Compile(rules ...Rule) (*Engine, error) {
+	e := &Engine{rules: make([]compiledRule, 0, len(rules))}
+	for _, r := range rules {
+		c, err := compile(r)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, c)
+	}
+	return e, nil
+}
+
+// MustCompile is like Compile but panics on error. It is intended for rules
+// defined in source code.
+func MustCompile(rules ...Rule) *Engine {
+	e, err := Compile(rules...)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Rules returns the rules of the engine.
+func (e *Engine) Rules() []Rule {
+	out := make([]Rule, len(e.rules))
+	for i, r := range e.rules {
+		out[i] = r.Rule
+	}
+	return out
+}
+
+// Evaluate returns an alert for every rule matching info, in rule order.
+func (e *Engine) Evaluate(info *smartmontools.SMARTInfo) []Alert {
+	if info == nil {
+		return nil
+	}
+	var alerts []Alert
+	for _, r := range e.rules {
+		v, ok := r.selector(info)
+		if !ok || !r.matches(v) {
+			continue
+		}
+		msg := r.Message
+		if msg == "" {
+			msg = fmt.Sprintf("%s (value %s)", r.Expr, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		alerts = append(alerts, Alert{
+			Rule:     r.Name,
+			Expr:     r.Expr,
+			Severity: r.Severity,
+			Device:   info.Device.Name,
+			Serial:   info.SerialNumber,
+			Value:    v,
+			Message:  msg,
+		})
+	}
+	return alerts
+}
+
+var (
+	exprPattern = regexp.MustCompile(`^\s*([a-z_][a-z0-9_]*(?:\(\d+\))?(?:\.[a-z_]+)?)\s*(>=|<=|==|!=|>|<)\s*(\S+)\s*$`)
+	attrPattern = regexp.MustCompile(`^attr\((\d+)\)\.(raw|value|worst|thresh)$`)
+)
+
+func compile(r Rule) (compiledRule, error) {
+	m := exprPattern.FindStringSubmatch(r.Expr)
+	if m == nil {
+		return compiledRule{}, fmt.Errorf("rules: invalid expression %q", r.Expr)
+	}
+	sel, err := parseSelector(m[1])
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("rules: %q: %w", r.Expr, err)
+	}
+	var threshold float64
+	switch m[3] {
+	case "true":
+		threshold = 1
+	case "false":
+		threshold = 0
+	default:
+		threshold, err = strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return compiledRule{}, fmt.Errorf("rules: %q: invalid number %q", r.Expr, m[3])
+		}
+	}
+	if r.Name == "" {
+		r.Name = r.Expr
+	}
+	return compiledRule{Rule: r, selector: sel, op: m[2], threshold: threshold}, nil
+}
+
+func parseSelector(name string) (selector, error) {
+	if m := attrPattern.FindStringSubmatch(name); m != nil {
+		id, err := strconv.Atoi(m[1])
+		if err != nil || id < 1 || id > 255 {
+			return nil, fmt.Errorf("invalid attribute ID %s", m[1])
+		}
+		field := m[2]
+		return func(info *smartmontools.SMARTInfo) (float64, bool) {
+			attr := info.AtaSmartData.GetAttributeByID(id)
+			if attr == nil {
+				return 0, false
+			}
+			switch field {
+			case "raw":
+				return float64(attr.Raw.Count()), true
+			case "value":
+				return float64(attr.Value), true
+			case "worst":
+				return float64(attr.Worst), true
+			default:
+				return float64(attr.Thresh), true
+			}
+		}, nil
+	}
+	if field, ok := strings.CutPrefix(name, "nvme."); ok {
+		get, ok := nvmeFields[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown NVMe field %q", field)
+		}
+		return func(info *smartmontools.SMARTInfo) (float64, bool) {
+			if info.NvmeSmartHealth == nil {
+				return 0, false
+			}
+			return get(info.NvmeSmartHealth), true
+		}, nil
+	}
+	if sel, ok := scalarSelectors[name]; ok {
+		return sel, nil
+	}
+	return nil, fmt.Errorf("unknown selector %q", name)
+}
+
+var nvmeFields = map[string]func(*smartmontools.NvmeSmartHealth) float64{
+	"critical_warning":          func(h *smartmontools.NvmeSmartHealth) float64 { return float64(h.CriticalWarning) },
+	"available_spare":           func(h *smartmontools.NvmeSmartHealth) float64 { return float64(h.AvailableSpare) },
+	"available_spare_threshold": func(h *smartmontools.NvmeSmartHealth) float64 { return float64(h.AvailableSpareThresh) },
+	"percentage_used":           func(h *smartmontools.NvmeSmartHealth) float64 { return float64(h.PercentageUsed) },
+	"media_errors":              func(h *smartmontools.NvmeSmartHealth) float64 { return float64(h.MediaErrors) },
+	"num_err_log_entries":       func(h *smartmontools.NvmeSmartHealth) float64 { return float64(h.NumErrLogEntries) },
+	"unsafe_shutdowns":          func(h *smartmontools.NvmeSmartHealth) float64 { return float64(h.UnsafeShutdowns) },
+	"temperature":               func(h *smartmontools.NvmeSmartHealth) float64 { return float64(h.Temperature) },
+}
+
+var scalarSelectors = map[string]selector{
+	"temperature.current": func(info *smartmontools.SMARTInfo) (float64, bool) {
+		switch {
+		case info.Temperature != nil:
+			return float64(info.Temperature.Current), true
+		case info.NvmeSmartHealth != nil && info.NvmeSmartHealth.Temperature != 0:
+			return float64(info.NvmeSmartHealth.Temperature), true
+		}
+		return 0, false
+	},
+	"power_on_hours": func(info *smartmontools.SMARTInfo) (float64, bool) {
+		d, ok := info.PowerOnDuration()
+		return d.Hours(), ok
+	},
+	"power_cycle_count": func(info *smartmontools.SMARTInfo) (float64, bool) {
+		switch {
+		case info.PowerCycleCount > 0:
+			return float64(info.PowerCycleCount), true
+		case info.NvmeSmartHealth != nil:
+			return float64(info.NvmeSmartHealth.PowerCycles), true
+		}
+		return 0, false
+	},
+	"smart_status.passed": func(info *smartmontools.SMARTInfo) (float64, bool) {
+		passed, ok := info.HealthVerdict()
+		if !ok {
+			return 0, false
+		}
+		if passed {
+			return 1, true
+		}
+		return 0, true
+	},
+}
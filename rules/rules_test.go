@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInfo() *smartmontools.SMARTInfo {
+	return &smartmontools.SMARTInfo{
+		Device:       smartmontools.Device{Name: "/dev/sda"},
+		SerialNumber: "SER1",
+		SmartStatus:  &smartmontools.SmartStatus{Passed: true},
+		Temperature:  &smartmontools.Temperature{Current: 58},
+		PowerOnTime:  &smartmontools.PowerOnTime{Hours: 40000},
+		AtaSmartData: &smartmontools.AtaSmartData{Table: []smartmontools.SmartAttribute{
+			{ID: 5, Value: 100, Worst: 100, Thresh: 10, Raw: smartmontools.Raw{Value: 0, String: "0"}},
+			{ID: 197, Value: 100, Worst: 100, Raw: smartmontools.Raw{Value: 0x0001_0000_0002, String: "2 (1 0)"}},
+		}},
+	}
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	engine, err := Compile(
+		Rule{Name: "pending", Expr: "attr(197).raw > 0", Severity: SeverityCritical},
+		Rule{Expr: "attr(5).raw > 0", Severity: SeverityCritical},
+		Rule{Expr: "attr(5).thresh == 10"},
+		Rule{Expr: "temperature.current > 55", Severity: SeverityWarning, Message: "drive is hot"},
+		Rule{Expr: "power_on_hours >= 35000"},
+		Rule{Expr: "smart_status.passed == false", Severity: SeverityCritical},
+		Rule{Expr: "nvme.percentage_used > 90", Severity: SeverityWarning},
+		Rule{Expr: "attr(231).value < 10"},
+	)
+	require.NoError(t, err)
+
+	alerts := engine.Evaluate(testInfo())
+	require.Len(t, alerts, 4)
+	assert.Equal(t, "pending", alerts[0].Rule)
+	assert.Equal(t, float64(2), alerts[0].Value)
+	assert.Equal(t, SeverityCritical, alerts[0].Severity)
+	assert.Equal(t, "/dev/sda", alerts[0].Device)
+	assert.Equal(t, "SER1", alerts[0].Serial)
+	assert.Equal(t, "attr(5).thresh == 10", alerts[1].Rule)
+	assert.Equal(t, "drive is hot", alerts[2].Message)
+	assert.Equal(t, "power_on_hours >= 35000 (value 40000)", alerts[3].Message)
+
+	nvme := &smartmontools.SMARTInfo{
+		SmartStatus:     &smartmontools.SmartStatus{Passed: false},
+		NvmeSmartHealth: &smartmontools.NvmeSmartHealth{PercentageUsed: 95, Temperature: 40},
+	}
+	alerts = engine.Evaluate(nvme)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "smart_status.passed == false", alerts[0].Rule)
+	assert.Equal(t, "nvme.percentage_used > 90", alerts[1].Rule)
+
+	standby := &smartmontools.SMARTInfo{InStandby: true, SmartStatus: &smartmontools.SmartStatus{Synthesized: true}}
+	assert.Empty(t, engine.Evaluate(standby), "a synthesized status is not a failed health check")
+
+	assert.Nil(t, engine.Evaluate(nil))
+}
+
+func TestCompile_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"attr(197).raw",
+		"attr(197).raw >> 0",
+		"attr(0).raw > 0",
+		"attr(197).foo > 0",
+		"nvme.unknown > 1",
+		"disk.size > 1",
+		"temperature.current > hot",
+	} {
+		_, err := Compile(Rule{Expr: expr})
+		assert.Error(t, err, expr)
+	}
+	assert.Panics(t, func() { MustCompile(Rule{Expr: "bogus"}) })
+}
+
+func TestRule_JSON(t *testing.T) {
+	var rules []Rule
+	require.NoError(t, json.Unmarshal([]byte(`[{"name":"hot","expr":"temperature.current > 50","severity":"warning"}]`), &rules))
+	require.Len(t, rules, 1)
+	assert.Equal(t, SeverityWarning, rules[0].Severity)
+
+	engine := MustCompile(rules...)
+	data, err := json.Marshal(engine.Evaluate(testInfo()))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"severity":"warning"`)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"severity":"fatal"}`), &Rule{}))
+}
@@ -0,0 +1,319 @@
+package smartmontools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
+)
+
+// selfTestMinPoll and selfTestMaxPoll bound the adaptive interval at which a
+// running self-test is polled; selfTestOverrunPoll bounds it once the test
+// runs past its expected duration and is likely to end any moment.
+var (
+	selfTestMinPoll     = 5 * time.Second
+	selfTestMaxPoll     = 15 * time.Minute
+	selfTestOverrunPoll = time.Minute
+)
+
+type selfTestPollKey struct{}
+
+// ContextWithSelfTestPollInterval returns a context that overrides the
+// client's WithSelfTestPollInterval for the self-test calls made with it,
+// e.g. to poll one RunSelfTestAndWait every few seconds. Zero selects the
+// adaptive schedule for those calls.
+func ContextWithSelfTestPollInterval(ctx context.Context, interval time.Duration) context.Context {
+	return context.WithValue(ctx, selfTestPollKey{}, max(interval, 0))
+}
+
+// selfTestPollInterval returns the fixed poll interval for the self-test
+// calls made with ctx, zero for the adaptive schedule.
+func (c *Client) selfTestPollInterval(ctx context.Context) time.Duration {
+	if interval, ok := ctx.Value(selfTestPollKey{}).(time.Duration); ok {
+		return interval
+	}
+	return c.selfTestPoll
+}
+
+// RunSelfTestAndWait starts a SMART self-test, waits for the drive to finish
+// it and returns the outcome recorded in the self-test log, so callers do not
+// have to query the log themselves. The drive is polled as described for
+// WithSelfTestPollInterval, or as set by ContextWithSelfTestPollInterval for
+// this call. It returns ctx's error when ctx ends first; the
+// test keeps running on the drive.
+//
+// A test that was aborted or interrupted before it finished returns its
+// result together with an error wrapping ErrSelfTestAborted. A test that ran
+// to the end returns a nil error even when it failed; check result.Passed.
+//
+// The newest self-test log entry is read before the test starts, so that the
+// previous test's entry is not mistaken for the result while the drive has
+// not flagged the new test as running yet. The test counts as finished once
+// it was seen running or a newer entry is logged, or, for a drive that shows
+// neither, once its expected duration has passed.
+func (c *Client) RunSelfTestAndWait(ctx context.Context, devicePath string, testType string) (*SelfTestResult, error) {
+	ctx = c.resolveCtx(ctx)
+	selfTestInfo, err := c.checkSelfTest(ctx, devicePath, testType)
+	if err != nil {
+		return nil, err
+	}
+	baseline := c.selfTestBaseline(ctx, devicePath)
+	start := time.Now()
+	if err := c.RunSelfTest(ctx, devicePath, testType); err != nil {
+		return nil, err
+	}
+
+	expected := time.Duration(expectedSelfTestMinutes(testType, selfTestInfo)) * time.Minute
+	poll := c.selfTestPollInterval(ctx)
+	timer := time.NewTimer(selfTestFirstPoll(poll))
+	defer timer.Stop()
+	var lastErr error
+	seenRunning := false
+	for {
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w (last poll failed: %w)", ctx.Err(), lastErr)
+			}
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		timer.Reset(selfTestPollDelay(poll, expected, time.Since(start)))
+		info, err := c.refreshSMARTInfo(ctx, devicePath)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if smtypes.SelfTestInProgress(info) {
+			seenRunning = true
+			continue
+		}
+		if !selfTestStarted(info, baseline, seenRunning, time.Since(start) >= expected) {
+			continue
+		}
+		result, ok := smtypes.LastSelfTestResult(info)
+		if !ok {
+			return nil, fmt.Errorf("self-test finished but %s has no self-test log entry", devicePath)
+		}
+		result.DevicePath = devicePath
+		result.TestType = testType
+		result.Duration = time.Since(start)
+		return result, result.Err()
+	}
+}
+
+// SelfTestRunning reports whether info shows a self-test in progress: ATA
+// self-test execution status 0xF0–0xFF, or a non-zero NVMe current self-test
+// operation. It is the definition RunSelfTestAndWait, the monitor package and
+// SmartStatus.Running share.
+func SelfTestRunning(info *SMARTInfo) bool {
+	return smtypes.SelfTestInProgress(info)
+}
+
+// selfTestLogMark identifies the newest entry of a self-test log, telling a
+// newly logged test from the one that was newest before.
+type selfTestLogMark struct {
+	entries, count, hours, kind, status int
+}
+
+// selfTestBaseline returns the mark of the self-test log of devicePath before
+// a test starts, or nil when it cannot be read.
+func (c *Client) selfTestBaseline(ctx context.Context, devicePath string) *selfTestLogMark {
+	info, err := c.refreshSMARTInfo(ctx, devicePath)
+	if err != nil {
+		return nil
+	}
+	mark := selfTestLogMarkOf(info)
+	return &mark
+}
+
+// selfTestStarted reports whether a poll that shows no running self-test
+// belongs to the test started after baseline was taken: it was seen running
+// before, a newer log entry appeared, or the test is overdue. Otherwise the
+// drive has not flagged the new test as running yet, and info still shows the
+// previous test.
+func selfTestStarted(info *SMARTInfo, baseline *selfTestLogMark, seenRunning, overdue bool) bool {
+	newEntry := baseline != nil && selfTestLogMarkOf(info) != *baseline
+	return seenRunning || newEntry || overdue
+}
+
+// selfTestLogMarkOf returns the mark of the ATA or NVMe self-test log in
+// info; the zero mark when there is none.
+func selfTestLogMarkOf(info *SMARTInfo) selfTestLogMark {
+	var mark selfTestLogMark
+	if log := info.AtaSmartSelfTestLog; log != nil && log.Standard != nil && len(log.Standard.Table) > 0 {
+		entry := log.Standard.Table[0]
+		mark = selfTestLogMark{entries: len(log.Standard.Table), count: log.Standard.Count, hours: entry.LifetimeHours}
+		if entry.Type != nil {
+			mark.kind = entry.Type.Value
+		}
+		if entry.Status != nil {
+			mark.status = entry.Status.Value
+		}
+	} else if log := info.NvmeSelfTestLog; log != nil && len(log.Table) > 0 {
+		entry := log.Table[0]
+		mark = selfTestLogMark{entries: len(log.Table), hours: entry.PowerOnHours}
+		if entry.SelfTestCode != nil {
+			mark.kind = entry.SelfTestCode.Value
+		}
+		if entry.SelfTestResult != nil {
+			mark.status = entry.SelfTestResult.Value
+		}
+	}
+	return mark
+}
+
+// checkSelfTest validates testType and checks that devicePath supports it,
+// returning the device's self-test capabilities.
+func (c *Client) checkSelfTest(ctx context.Context, devicePath string, testType string) (*SelfTestInfo, error) {
+	// Valid test types: short, long, conveyance, offline
+	if !slices.Contains(smtypes.ValidSelfTestTypes, testType) {
+		return nil, fmt.Errorf("invalid test type: %s (must be one of: short, long, conveyance, offline)", testType)
+	}
+
+	// First check if self-tests are supported and get durations
+	selfTestInfo, err := c.GetAvailableSelfTests(ctx, devicePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get self-test info: %w", err)
+	}
+
+	if len(selfTestInfo.Available) == 0 {
+		return nil, fmt.Errorf("%w: self-tests are not supported by this device", ErrTestNotSupported)
+	}
+
+	// Check if the requested test is available
+	if !slices.Contains(selfTestInfo.Available, testType) {
+		return nil, fmt.Errorf("%w: test type %s is not available for this device", ErrTestNotSupported, testType)
+	}
+	return selfTestInfo, nil
+}
+
+// expectedSelfTestMinutes returns the duration the drive advertises for
+// testType, or a typical duration when it advertises none.
+func expectedSelfTestMinutes(testType string, selfTestInfo *SelfTestInfo) int {
+	if duration, ok := selfTestInfo.Durations[testType]; ok && duration > 0 {
+		return duration
+	}
+	return map[string]int{
+		"short":      2,
+		"long":       120,
+		"conveyance": 5,
+		"offline":    10,
+	}[testType]
+}
+
+// selfTestFirstPoll returns the delay before the first poll of a self-test:
+// the fixed interval when positive, otherwise selfTestMinPoll, so that a test
+// the drive aborted or finished at once is noticed early.
+func selfTestFirstPoll(fixed time.Duration) time.Duration {
+	if fixed > 0 {
+		return fixed
+	}
+	return selfTestMinPoll
+}
+
+// selfTestPollDelay returns the delay before the next poll of a self-test
+// expected to take expected, elapsed after it started. A positive fixed
+// interval is returned as is. Otherwise the delay is a quarter of the time
+// left until the expected end, bounded by selfTestMinPoll and
+// selfTestMaxPoll: a 10-hour test is polled every 15 minutes at first and
+// more and more often as its end approaches, instead of waking a USB
+// enclosure hundreds of times. Past the expected end the delay grows with the
+// overrun again, but only up to selfTestOverrunPoll.
+func selfTestPollDelay(fixed, expected, elapsed time.Duration) time.Duration {
+	if fixed > 0 {
+		return fixed
+	}
+	if elapsed >= expected {
+		return max(selfTestMinPoll, min(selfTestOverrunPoll, (elapsed-expected)/4))
+	}
+	return max(selfTestMinPoll, min(selfTestMaxPoll, (expected-elapsed)/4))
+}
+
+// selfTestFinished inspects a poll of a running self-test and returns the
+// status to report, whether the drive measured its progress, and whether the
+// test has ended. NVMe drives whose smartctl reports no self-test state are
+// considered finished once overdue, after the expected duration.
+func selfTestFinished(info *SMARTInfo, overdue bool) (status string, source ProgressSource, done bool) {
+	source = ProgressEstimated
+	if _, ok := measuredSelfTestProgress(info); ok {
+		source = ProgressMeasured
+	}
+	running := smtypes.SelfTestInProgress(info)
+	switch {
+	case info.AtaSmartData != nil && info.AtaSmartData.SelfTest != nil && info.AtaSmartData.SelfTest.Status != nil:
+		status = info.AtaSmartData.SelfTest.Status.String
+	case info.NvmeSelfTestLog != nil && info.NvmeSelfTestLog.CurrentSelfTestOperation != nil,
+		info.NvmeSmartTestLog != nil && info.NvmeSmartTestLog.CurrentOpeation != nil:
+		status = "Test in progress"
+		if !running {
+			status = "Test completed"
+		}
+	case info.NvmeSmartHealth != nil && overdue:
+		return "Test completed", ProgressEstimated, true
+	default:
+		return "Test in progress", source, false
+	}
+	if !running {
+		return status, ProgressMeasured, true
+	}
+	return status, source, false
+}
+
+// selfTestOutcome classifies the self-test that info shows as ended, and
+// returns the drive's status text for it. ATA drives report the outcome in the
+// current execution status; NVMe drives only in the self-test log.
+func selfTestOutcome(info *SMARTInfo) (SelfTestOutcome, string) {
+	if ata := info.AtaSmartData; ata != nil && ata.SelfTest != nil && ata.SelfTest.Status != nil {
+		return smtypes.AtaSelfTestOutcome(ata.SelfTest.Status.Value), ata.SelfTest.Status.String
+	}
+	if result, ok := smtypes.LastSelfTestResult(info); ok && result.Outcome != "" {
+		return result.Outcome, result.Status
+	}
+	return SelfTestUnknown, ""
+}
+
+// measuredSelfTestProgress returns the completion percentage the drive
+// reports for a running self-test, if any.
+func measuredSelfTestProgress(info *SMARTInfo) (int, bool) {
+	if ata := info.AtaSmartData; ata != nil && ata.SelfTest != nil && ata.SelfTest.Status != nil && ata.SelfTest.Status.RemainingPercent != nil {
+		return 100 - *ata.SelfTest.Status.RemainingPercent, true
+	}
+	if log := info.NvmeSelfTestLog; log != nil && log.CurrentSelfTestCompletionPercent != nil {
+		return *log.CurrentSelfTestCompletionPercent, true
+	}
+	if log := info.NvmeSmartTestLog; log != nil && log.CurrentCompletion != nil {
+		return *log.CurrentCompletion, true
+	}
+	return 0, false
+}
+
+// progressEstimator turns sparse drive measurements into a steadily rising
+// progress value. Between measurements it extrapolates from the last one at
+// the rate implied by the expected duration. It never goes backwards and
+// stays below 100 until the drive reports the test finished.
+type progressEstimator struct {
+	expected   time.Duration
+	last       int
+	measured   int
+	measuredAt time.Duration
+}
+
+// measure records a percentage reported by the drive at elapsed.
+func (e *progressEstimator) measure(elapsed time.Duration, percent int) int {
+	e.measured, e.measuredAt = percent, elapsed
+	e.last = max(e.last, min(percent, 99))
+	return e.last
+}
+
+// estimate extrapolates the progress at elapsed.
+func (e *progressEstimator) estimate(elapsed time.Duration) int {
+	percent := e.measured
+	if e.expected > 0 {
+		percent += int((elapsed - e.measuredAt) * 100 / e.expected)
+	}
+	e.last = max(e.last, min(percent, 99))
+	return e.last
+}
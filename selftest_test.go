@@ -0,0 +1,457 @@
+package smartmontools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	osexec "os/exec"
+	"testing"
+	"time"
+
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSelfTestWithOptions_Captive(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -t short -C /dev/sda": {output: []byte("Testing has begun.\n")},
+			"/usr/sbin/smartctl -t long -C /dev/sda": {
+				output: []byte("Command \"Execute SMART Extended self-test routine immediately in captive mode\" failed: Input/output error\n"),
+				err:    &osexec.ExitError{},
+			},
+		}}),
+	)
+	require.NoError(t, err)
+
+	var statuses []string
+	var last int
+	callback := func(progress int, status string, _ ProgressSource, _ SelfTestOutcome) {
+		statuses = append(statuses, status)
+		last = progress
+	}
+
+	require.NoError(t, client.RunSelfTestWithOptions(context.Background(), "/dev/sda", "short", SelfTestOptions{Captive: true}, callback))
+	require.Len(t, statuses, 2)
+	assert.Contains(t, statuses[0], "Captive test started")
+	assert.Contains(t, statuses[1], "Captive test completed")
+	assert.Equal(t, 100, last)
+
+	statuses = nil
+	err = client.RunSelfTestWithOptions(context.Background(), "/dev/sda", "long", SelfTestOptions{Captive: true}, callback)
+	require.Error(t, err)
+	require.Len(t, statuses, 2)
+	assert.Contains(t, statuses[1], "Captive test failed")
+}
+
+func TestRunSelfTestWithOptions_WithoutOptions(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -t short /dev/sda": {},
+		}}),
+	)
+	require.NoError(t, err)
+
+	assert.NoError(t, client.RunSelfTestWithOptions(context.Background(), "/dev/sda", "short", SelfTestOptions{}, nil))
+}
+
+func TestRunSelfTestWithOptions_UnsupportedBackend(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	err = client.RunSelfTestWithOptions(context.Background(), "/dev/sda", "short", SelfTestOptions{Captive: true}, nil)
+	assert.ErrorContains(t, err, `backend "plain" does not run captive self-tests`)
+}
+
+func TestSelectiveSelfTest_UnsupportedBackend(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	err = client.RunSelectiveSelfTest(context.Background(), "/dev/sda", []LBASpan{{Start: 0, End: 1000}})
+	assert.ErrorContains(t, err, `backend "plain" does not run selective self-tests`)
+	_, err = client.GetSelectiveSelfTestLog(context.Background(), "/dev/sda")
+	assert.ErrorContains(t, err, "does not run selective self-tests")
+}
+
+func TestRunSelectiveSelfTestWithOptions_ExecBackend(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -t select,next -t afterselect,off /dev/sda": {},
+		}}),
+	)
+	require.NoError(t, err)
+
+	off := false
+	opts := SelectiveSelfTestOptions{Continue: SelectiveNext, AfterSelect: &off}
+	assert.NoError(t, client.RunSelectiveSelfTestWithOptions(context.Background(), "/dev/sda", nil, opts))
+}
+
+// selfTestBackend runs self-tests instantly and reports the drive state
+// through the script of the embedded scriptedBackend.
+type selfTestBackend struct {
+	*scriptedBackend
+	started []string
+}
+
+func (b *selfTestBackend) GetAvailableSelfTests(ctx context.Context, devicePath string) (*SelfTestInfo, error) {
+	return &SelfTestInfo{Available: []string{"short"}, Durations: map[string]int{"short": 1}}, nil
+}
+
+func (b *selfTestBackend) RunSelfTest(ctx context.Context, devicePath string, testType string) error {
+	b.started = append(b.started, testType)
+	return nil
+}
+
+func fastSelfTestPolls(t *testing.T) {
+	t.Helper()
+	minPoll, maxPoll, overrunPoll := selfTestMinPoll, selfTestMaxPoll, selfTestOverrunPoll
+	selfTestMinPoll, selfTestMaxPoll, selfTestOverrunPoll = time.Millisecond, 5*time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { selfTestMinPoll, selfTestMaxPoll, selfTestOverrunPoll = minPoll, maxPoll, overrunPoll })
+}
+
+func TestRunSelfTestAndWait(t *testing.T) {
+	fastSelfTestPolls(t)
+	var finished SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"ata_smart_data": {"self_test": {"status": {"value": 121, "string": "completed: read failure", "passed": false}}},
+		"ata_smart_self_test_log": {"standard": {"revision": 1, "count": 1, "table": [
+			{"type": {"value": 1, "string": "Short offline"},
+			 "status": {"value": 121, "string": "Completed: read failure", "remaining_percent": 10, "passed": false},
+			 "lifetime_hours": 35824, "lba": 123456}
+		]}}
+	}`), &finished))
+	running := &SMARTInfo{AtaSmartData: &AtaSmartData{SelfTest: &SelfTest{Status: &StatusField{Value: 249, String: "in progress"}}}}
+	backend := &selfTestBackend{scriptedBackend: &scriptedBackend{script: []scriptedResult{
+		{info: running},
+		{err: errors.New("transient")},
+		{info: running},
+		{info: &finished},
+	}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	result, err := client.RunSelfTestAndWait(context.Background(), "/dev/sda", "short")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"short"}, backend.started)
+	assert.Equal(t, "/dev/sda", result.DevicePath)
+	assert.Equal(t, "short", result.TestType)
+	assert.Equal(t, "Completed: read failure", result.Status)
+	assert.False(t, result.Passed)
+	assert.Equal(t, 10, result.RemainingPercent)
+	require.NotNil(t, result.FirstErrorLBA)
+	assert.Equal(t, uint64(123456), *result.FirstErrorLBA)
+	assert.Equal(t, 35824, result.PowerOnHours)
+	assert.Positive(t, result.Duration)
+}
+
+func TestRunSelfTestAndWait_NVMe(t *testing.T) {
+	fastSelfTestPolls(t)
+	var finished SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"nvme_self_test_log": {
+			"current_self_test_operation": {"value": 0, "string": "No self-test in progress"},
+			"table": [{"self_test_code": {"value": 1, "string": "Short"}, "self_test_result": {"value": 0, "string": "Completed without error"}, "power_on_hours": 120}]
+		}
+	}`), &finished))
+	backend := &selfTestBackend{scriptedBackend: &scriptedBackend{script: []scriptedResult{{info: &SMARTInfo{}}, {info: &finished}}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	result, err := client.RunSelfTestAndWait(context.Background(), "/dev/nvme0", "short")
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Equal(t, "Completed without error", result.Status)
+	assert.Nil(t, result.FirstErrorLBA)
+}
+
+func TestRunSelfTestAndWait_ContextEnds(t *testing.T) {
+	fastSelfTestPolls(t)
+	running := &SMARTInfo{AtaSmartData: &AtaSmartData{SelfTest: &SelfTest{Status: &StatusField{Value: 249}}}}
+	backend := &selfTestBackend{scriptedBackend: &scriptedBackend{script: []scriptedResult{{info: running}}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.RunSelfTestAndWait(ctx, "/dev/sda", "short")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = client.RunSelfTestAndWait(context.Background(), "/dev/sda", "long")
+	assert.ErrorIs(t, err, ErrTestNotSupported)
+}
+
+func TestProgressEstimator(t *testing.T) {
+	e := &progressEstimator{expected: 100 * time.Second}
+	assert.Equal(t, 10, e.estimate(10*time.Second))
+	assert.Equal(t, 30, e.measure(20*time.Second, 30))
+	assert.Equal(t, 40, e.estimate(30*time.Second), "extrapolates from the last measurement")
+	assert.Equal(t, 40, e.measure(35*time.Second, 30), "never goes backwards")
+	assert.Equal(t, 99, e.estimate(500*time.Second), "stays below 100 until the test ends")
+}
+
+func TestRunSelfTestWithProgress_ReportsSource(t *testing.T) {
+	fastSelfTestPolls(t)
+	remaining := func(percent int) *SMARTInfo {
+		return &SMARTInfo{AtaSmartData: &AtaSmartData{SelfTest: &SelfTest{Status: &StatusField{
+			Value: 0xF0 | percent/10, String: "Self-test routine in progress", RemainingPercent: &percent,
+		}}}}
+	}
+	backend := &selfTestBackend{scriptedBackend: &scriptedBackend{script: []scriptedResult{
+		{info: &SMARTInfo{}},
+		{info: remaining(90)},
+		{err: errors.New("transient")},
+		{info: remaining(40)},
+		{info: &SMARTInfo{AtaSmartData: &AtaSmartData{SelfTest: &SelfTest{Status: &StatusField{Value: 0, String: "Completed without error"}}}}},
+	}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	type update struct {
+		progress int
+		source   ProgressSource
+	}
+	updates := make(chan update, 16)
+	err = client.RunSelfTestWithProgress(context.Background(), "/dev/sda", "short", func(progress int, status string, source ProgressSource, _ SelfTestOutcome) {
+		updates <- update{progress, source}
+	})
+	require.NoError(t, err)
+
+	var got []update
+	for u := range updates {
+		got = append(got, u)
+		if u.progress >= 100 {
+			break
+		}
+	}
+	require.Len(t, got, 5)
+	assert.Equal(t, update{0, ProgressEstimated}, got[0])
+	assert.Equal(t, update{10, ProgressMeasured}, got[1])
+	assert.Equal(t, ProgressEstimated, got[2].source)
+	assert.GreaterOrEqual(t, got[2].progress, 10)
+	assert.Equal(t, update{60, ProgressMeasured}, got[3])
+	assert.Equal(t, update{100, ProgressMeasured}, got[4])
+}
+
+func TestRunSelfTestAndWait_Aborted(t *testing.T) {
+	fastSelfTestPolls(t)
+	var aborted SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"ata_smart_data": {"self_test": {"status": {"value": 33, "string": "was interrupted by the host with a hard or soft reset"}}},
+		"ata_smart_self_test_log": {"standard": {"revision": 1, "count": 1, "table": [
+			{"type": {"value": 1, "string": "Short offline"},
+			 "status": {"value": 33, "string": "Interrupted (host reset)", "remaining_percent": 10},
+			 "lifetime_hours": 35824}
+		]}}
+	}`), &aborted))
+	backend := &selfTestBackend{scriptedBackend: &scriptedBackend{script: []scriptedResult{{info: &SMARTInfo{}}, {info: &aborted}}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	result, err := client.RunSelfTestAndWait(context.Background(), "/dev/sda", "short")
+	assert.ErrorIs(t, err, ErrSelfTestAborted)
+	require.NotNil(t, result)
+	assert.Equal(t, SelfTestInterrupted, result.Outcome)
+	assert.False(t, result.Passed)
+}
+
+func TestSelfTestOutcome(t *testing.T) {
+	for value, want := range map[int]SelfTestOutcome{
+		0x00: SelfTestPassed, 0x19: SelfTestAborted, 0x21: SelfTestInterrupted,
+		0x79: SelfTestFailed, 0x80: SelfTestFailed, 0xF9: SelfTestUnknown,
+	} {
+		assert.Equal(t, want, smtypes.AtaSelfTestOutcome(value), "ATA status %#x", value)
+	}
+	for value, want := range map[int]SelfTestOutcome{
+		0x0: SelfTestPassed, 0x1: SelfTestAborted, 0x2: SelfTestInterrupted,
+		0x7: SelfTestFailed, 0x9: SelfTestAborted, 0xF: SelfTestUnknown,
+	} {
+		assert.Equal(t, want, smtypes.NvmeSelfTestOutcome(value), "NVMe result %#x", value)
+	}
+	assert.NoError(t, (&SelfTestResult{Outcome: SelfTestFailed}).Err())
+	assert.True(t, SelfTestFailed.Completed())
+	assert.False(t, SelfTestAborted.Completed())
+}
+
+func TestRunSelfTestWithProgress_ReportsAbort(t *testing.T) {
+	fastSelfTestPolls(t)
+	var aborted SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"nvme_self_test_log": {
+			"current_self_test_operation": {"value": 0, "string": "No self-test in progress"},
+			"table": [{"self_test_code": {"value": 1, "string": "Short"}, "self_test_result": {"value": 1, "string": "Aborted: Self-test command"}, "power_on_hours": 120}]
+		}
+	}`), &aborted))
+	backend := &selfTestBackend{scriptedBackend: &scriptedBackend{script: []scriptedResult{{info: &SMARTInfo{}}, {info: &aborted}}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	type update struct {
+		status  string
+		outcome SelfTestOutcome
+	}
+	final := make(chan update, 1)
+	err = client.RunSelfTestWithProgress(context.Background(), "/dev/nvme0", "short", func(progress int, status string, _ ProgressSource, outcome SelfTestOutcome) {
+		if progress >= 100 {
+			final <- update{status, outcome}
+		}
+	})
+	require.NoError(t, err)
+	got := <-final
+	assert.Contains(t, got.status, "Test aborted: Aborted: Self-test command")
+	assert.Equal(t, SelfTestAborted, got.outcome)
+}
+
+func TestSelfTestPollDelay(t *testing.T) {
+	const expected = 10 * time.Hour
+	assert.Equal(t, 15*time.Minute, selfTestPollDelay(0, expected, 0))
+	assert.Equal(t, 150*time.Second, selfTestPollDelay(0, expected, 9*time.Hour+50*time.Minute))
+	assert.Equal(t, 5*time.Second, selfTestPollDelay(0, expected, expected))
+	assert.Equal(t, 5*time.Second, selfTestPollDelay(0, expected, expected+10*time.Second))
+	assert.Equal(t, time.Minute, selfTestPollDelay(0, expected, expected+time.Hour), "an overrunning test is polled at least every minute")
+	assert.Equal(t, time.Minute, selfTestPollDelay(time.Minute, expected, 0))
+	assert.Equal(t, 5*time.Second, selfTestFirstPoll(0))
+	assert.Equal(t, time.Minute, selfTestFirstPoll(time.Minute))
+
+	polls := 0
+	for elapsed := selfTestFirstPoll(0); elapsed < expected; elapsed += selfTestPollDelay(0, expected, elapsed) {
+		polls++
+	}
+	assert.Less(t, polls, 100, "a 10-hour test must not wake the drive hundreds of times")
+}
+
+func TestRunSelfTest_FixedPollInterval(t *testing.T) {
+	minPoll := selfTestMinPoll
+	selfTestMinPoll = time.Hour
+	t.Cleanup(func() { selfTestMinPoll = minPoll })
+
+	var done SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"nvme_self_test_log": {
+			"current_self_test_operation": {"value": 0, "string": "No self-test in progress"},
+			"table": [{"self_test_code": {"value": 1, "string": "Short"}, "self_test_result": {"value": 0, "string": "Completed without error"}, "power_on_hours": 120}]
+		}
+	}`), &done))
+	running := &SMARTInfo{AtaSmartData: &AtaSmartData{SelfTest: &SelfTest{Status: &StatusField{Value: 249, String: "in progress"}}}}
+	script := func() []scriptedResult { return []scriptedResult{{info: &SMARTInfo{}}, {info: running}, {info: &done}} }
+
+	backend := &selfTestBackend{scriptedBackend: &scriptedBackend{script: script()}}
+	client, err := NewClient(WithBackend(backend), WithSelfTestPollInterval(time.Millisecond))
+	require.NoError(t, err)
+	result, err := client.RunSelfTestAndWait(context.Background(), "/dev/nvme0", "short")
+	require.NoError(t, err)
+	assert.Equal(t, SelfTestPassed, result.Outcome)
+
+	backend = &selfTestBackend{scriptedBackend: &scriptedBackend{script: script()}}
+	client, err = NewClient(WithBackend(backend))
+	require.NoError(t, err)
+	result, err = client.RunSelfTestAndWait(ContextWithSelfTestPollInterval(context.Background(), time.Millisecond), "/dev/nvme0", "short")
+	require.NoError(t, err)
+	assert.Equal(t, SelfTestPassed, result.Outcome)
+
+	backend = &selfTestBackend{scriptedBackend: &scriptedBackend{script: script()}}
+	client, err = NewClient(WithBackend(backend))
+	require.NoError(t, err)
+	final := make(chan int, 1)
+	err = client.RunSelfTestWithOptions(ContextWithSelfTestPollInterval(context.Background(), time.Millisecond), "/dev/nvme0", "short", SelfTestOptions{}, func(progress int, _ string, _ ProgressSource, _ SelfTestOutcome) {
+		if progress >= 100 {
+			final <- progress
+		}
+	})
+	require.NoError(t, err)
+	select {
+	case <-final:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ContextWithSelfTestPollInterval did not override the adaptive schedule")
+	}
+}
+
+func TestRunSelfTestAndWait_IgnoresPreviousEntry(t *testing.T) {
+	fastSelfTestPolls(t)
+	logged := func(status int, text string, hours int) *SMARTInfo {
+		var info SMARTInfo
+		require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{
+			"ata_smart_data": {"self_test": {"status": {"value": %d, "string": %q}}},
+			"ata_smart_self_test_log": {"standard": {"revision": 1, "count": 21, "table": [
+				{"type": {"value": 1, "string": "Short offline"}, "status": {"value": %[1]d, "string": %[2]q}, "lifetime_hours": %d}
+			]}}
+		}`, status, text, hours)), &info))
+		return &info
+	}
+	previous := logged(0, "Completed without error", 100)
+	backend := &selfTestBackend{scriptedBackend: &scriptedBackend{script: []scriptedResult{
+		{info: previous},
+		{info: previous}, // the drive has not flagged the new test yet
+		{info: previous},
+		{info: logged(0x79, "Completed: read failure", 101)},
+	}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	result, err := client.RunSelfTestAndWait(context.Background(), "/dev/sda", "short")
+	require.NoError(t, err)
+	assert.Equal(t, SelfTestFailed, result.Outcome, "the previous test's entry is not the result")
+	assert.Equal(t, 101, result.PowerOnHours)
+}
+
+func TestRunSelfTestWithProgress_IgnoresPreviousEntry(t *testing.T) {
+	fastSelfTestPolls(t)
+	logged := func(status int, text string, hours int) *SMARTInfo {
+		var info SMARTInfo
+		require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{
+			"nvme_self_test_log": {
+				"current_self_test_operation": {"value": 0, "string": "No self-test in progress"},
+				"table": [{"self_test_code": {"value": 1, "string": "Short"}, "self_test_result": {"value": %d, "string": %q}, "power_on_hours": %d}]
+			}
+		}`, status, text, hours)), &info))
+		return &info
+	}
+	previous := logged(0, "Completed without error", 100)
+	backend := &selfTestBackend{scriptedBackend: &scriptedBackend{script: []scriptedResult{
+		{info: previous},
+		{info: previous}, // the drive has not flagged the new test yet
+		{info: previous},
+		{info: logged(1, "Aborted: Self-test command", 101)},
+	}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+
+	type update struct {
+		progress int
+		status   string
+	}
+	updates := make(chan update, 16)
+	err = client.RunSelfTestWithProgress(context.Background(), "/dev/nvme0", "short", func(progress int, status string, _ ProgressSource, _ SelfTestOutcome) {
+		updates <- update{progress, status}
+	})
+	require.NoError(t, err)
+
+	var got []update
+	for u := range updates {
+		got = append(got, u)
+		if u.progress >= 100 {
+			break
+		}
+	}
+	require.Len(t, got, 4, "the previous test's entry does not end the new one")
+	assert.Less(t, got[1].progress, 100)
+	assert.Less(t, got[2].progress, 100)
+	assert.Contains(t, got[3].status, "Test aborted: Aborted: Self-test command")
+}
+
+func TestSelfTestRunning(t *testing.T) {
+	ata := func(value int) *SMARTInfo {
+		return &SMARTInfo{AtaSmartData: &AtaSmartData{SelfTest: &SelfTest{Status: &StatusField{Value: value}}}}
+	}
+	assert.True(t, SelfTestRunning(ata(0xF0)), "100% left is still running")
+	assert.True(t, SelfTestRunning(ata(0xF9)))
+	assert.False(t, SelfTestRunning(ata(0x00)))
+	assert.False(t, SelfTestRunning(ata(0x79)))
+	assert.True(t, SelfTestRunning(&SMARTInfo{NvmeSelfTestLog: &NvmeSelfTestLog{CurrentSelfTestOperation: &StatusField{Value: 1}}}))
+	assert.False(t, SelfTestRunning(&SMARTInfo{NvmeSelfTestLog: &NvmeSelfTestLog{CurrentSelfTestOperation: &StatusField{Value: 0}}}))
+	assert.False(t, SelfTestRunning(nil))
+}
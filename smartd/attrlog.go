@@ -0,0 +1,188 @@
+package smartd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/dianlight/smartmontools-go/history"
+)
+
+// AttrLogEntry is one line of a smartd attribute log ("smartd -A", by default
+// /var/lib/smartmontools/attrlog.MODEL-SERIAL.TYPE.csv), written at every
+// check of the device.
+type AttrLogEntry struct {
+	Time time.Time `json:"time"`
+	// Attributes holds the ATA SMART attributes of ATA devices.
+	Attributes []AttrLogAttribute `json:"attributes,omitempty"`
+	// Counters holds the named values logged for SCSI devices, such as
+	// "read-total-unc-errors", "non-medium-errors" and "temperature".
+	Counters map[string]float64 `json:"counters,omitempty"`
+}
+
+// AttrLogAttribute is an ATA SMART attribute in an attribute log. smartd
+// logs the normalized value and the 48-bit raw value, but not the worst value
+// or the threshold.
+type AttrLogAttribute struct {
+	ID    int    `json:"id"`
+	Value int    `json:"value"`
+	Raw   uint64 `json:"raw"`
+}
+
+// ParseAttrLog parses a smartd attribute log. Each line holds a
+// "YYYY-MM-DD HH:MM:SS;" timestamp in smartd's local time, read in loc
+// (time.Local when nil), followed by tab-separated "ID;VALUE;RAW;" groups for
+// ATA devices or "NAME;VALUE;" groups for SCSI devices.
+func ParseAttrLog(r io.Reader, loc *time.Location) ([]AttrLogEntry, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	var entries []AttrLogEntry
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entry, err := parseAttrLogLine(line, loc)
+		if err != nil {
+			return nil, fmt.Errorf("smartd: attribute log line %d: %w", lineNo, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("smartd: read attribute log: %w", err)
+	}
+	return entries, nil
+}
+
+func parseAttrLogLine(line string, loc *time.Location) (AttrLogEntry, error) {
+	groups := strings.Split(line, "\t")
+	at, err := time.ParseInLocation(time.DateTime, strings.TrimSuffix(strings.TrimSpace(groups[0]), ";"), loc)
+	if err != nil {
+		return AttrLogEntry{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	entry := AttrLogEntry{Time: at}
+	for _, group := range groups[1:] {
+		fields := strings.Split(strings.TrimSuffix(strings.TrimSpace(group), ";"), ";")
+		switch len(fields) {
+		case 3:
+			attr, err := parseAttrLogAttribute(fields)
+			if err != nil {
+				return AttrLogEntry{}, fmt.Errorf("attribute %q: %w", group, err)
+			}
+			entry.Attributes = append(entry.Attributes, attr)
+		case 2:
+			value, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return AttrLogEntry{}, fmt.Errorf("counter %q: %w", fields[0], err)
+			}
+			if entry.Counters == nil {
+				entry.Counters = make(map[string]float64)
+			}
+			entry.Counters[fields[0]] = value
+		default:
+			return AttrLogEntry{}, fmt.Errorf("malformed group %q", group)
+		}
+	}
+	return entry, nil
+}
+
+func parseAttrLogAttribute(fields []string) (AttrLogAttribute, error) {
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return AttrLogAttribute{}, err
+	}
+	value, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return AttrLogAttribute{}, err
+	}
+	raw, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return AttrLogAttribute{}, err
+	}
+	return AttrLogAttribute{ID: id, Value: value, Raw: raw}, nil
+}
+
+// SMARTInfo converts the entry into a snapshot of the device with the given
+// model and serial, as recorded by the history package. The current
+// temperature is taken from attribute 194 or 190 on ATA devices and from the
+// "temperature" counter on SCSI devices. Fields smartd does not log, such as
+// attribute names beyond the registry's and worst values, are left empty.
+func (e AttrLogEntry) SMARTInfo(model, serial string) *smartmontools.SMARTInfo {
+	info := &smartmontools.SMARTInfo{ModelName: model, SerialNumber: serial}
+	if len(e.Attributes) > 0 {
+		data := &smartmontools.AtaSmartData{}
+		for _, a := range e.Attributes {
+			attr := smartmontools.SmartAttribute{ID: a.ID, Value: a.Value, Raw: smartmontools.Raw{Value: int64(a.Raw)}}
+			if desc, ok := smartmontools.DescribeAttribute(a.ID); ok {
+				attr.Name = desc.Name
+			}
+			data.Table = append(data.Table, attr)
+		}
+		info.AtaSmartData = data
+		for _, id := range []int{smartmontools.SmartAttrTemperatureCelsius, smartmontools.SmartAttrAirflowTemperature} {
+			if attr := data.GetAttributeByID(id); attr != nil {
+				info.Temperature = &smartmontools.Temperature{Current: int(attr.Raw.Bytes()[0])}
+				break
+			}
+		}
+	}
+	if temp, ok := e.Counters["temperature"]; ok {
+		info.Temperature = &smartmontools.Temperature{Current: int(temp)}
+	}
+	return info
+}
+
+// ParseAttrLogFileName extracts the model, serial and device type from an
+// attribute log name such as "attrlog.WDC_WD40EFRX_68N32N0-WD_WCC7K0123456.ata.csv",
+// sanitized as described for ParseStateFileName.
+func ParseAttrLogFileName(name string) (model, serial, deviceType string, ok bool) {
+	return parseDeviceFileName(filepath.Base(name), "attrlog.", ".csv")
+}
+
+// AttrLogFileName returns the name smartd gives the attribute log of an ATA
+// device.
+func AttrLogFileName(model, serial, deviceType string) string {
+	return "attrlog." + FileNameID(model) + "-" + FileNameID(serial) + "." + deviceType + ".csv"
+}
+
+// ImportAttrLog loads the smartd attribute log at path into store, one
+// snapshot per line, so trend queries cover the time before the Go monitor
+// was deployed. Snapshots are keyed by serial, which should be the device's
+// serial number as smartctl reports it; when empty, the sanitized serial from
+// the file name is used; the model is always the sanitized one from the file
+// name. Timestamps are read in time.Local. It returns the number of snapshots
+// recorded.
+func ImportAttrLog(ctx context.Context, store history.Store, path string, serial string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("smartd: %w", err)
+	}
+	defer f.Close()
+	entries, err := ParseAttrLog(f, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%w (%s)", err, path)
+	}
+
+	model, fileSerial, _, _ := ParseAttrLogFileName(path)
+	if serial == "" {
+		serial = fileSerial
+	}
+	for i, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if err := store.Record(ctx, entry.Time, entry.SMARTInfo(model, serial)); err != nil {
+			return i, fmt.Errorf("smartd: import %s: %w", path, err)
+		}
+	}
+	return len(entries), nil
+}
@@ -0,0 +1,70 @@
+package smartd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dianlight/smartmontools-go/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleATALog = "2025-01-01 10:00:00;\t1;200;0;\t5;100;0;\t194;115;167503724579;\t197;200;0;\t\n" +
+	"2025-01-01 10:30:00;\t1;200;0;\t5;100;8;\t194;114;167503724580;\t197;200;2;\t\n"
+
+func TestParseAttrLog(t *testing.T) {
+	entries, err := ParseAttrLog(strings.NewReader(sampleATALog), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.True(t, entries[1].Time.Equal(time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)))
+	assert.Equal(t, AttrLogAttribute{ID: 5, Value: 100, Raw: 8}, entries[1].Attributes[1])
+
+	info := entries[1].SMARTInfo("WDC_WD40EFRX", "WD-WCC7K0123456")
+	assert.Equal(t, "WD-WCC7K0123456", info.SerialNumber)
+	require.NotNil(t, info.Temperature)
+	assert.Equal(t, 36, info.Temperature.Current)
+	pending, ok := info.AtaSmartData.PendingSectors()
+	require.True(t, ok)
+	assert.Equal(t, int64(2), pending)
+
+	scsi, err := ParseAttrLog(strings.NewReader("2025-01-01 10:00:00;\tread-gb-processed;1234.567;\tread-total-unc-errors;0;\ttemperature;38;\t\n"), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, scsi, 1)
+	assert.Equal(t, 1234.567, scsi[0].Counters["read-gb-processed"])
+	assert.Equal(t, 38, scsi[0].SMARTInfo("", "S1").Temperature.Current)
+
+	_, err = ParseAttrLog(strings.NewReader("yesterday;\t1;200;0;\n"), nil)
+	assert.ErrorContains(t, err, "line 1")
+	_, err = ParseAttrLog(strings.NewReader("2025-01-01 10:00:00;\t1;200;0;9;\n"), nil)
+	assert.ErrorContains(t, err, "malformed")
+}
+
+func TestImportAttrLog(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), AttrLogFileName("WDC WD40EFRX-68N32N0", "WD-WCC7K0123456", "ata"))
+	require.NoError(t, os.WriteFile(path, []byte(sampleATALog), 0o600))
+
+	store := history.NewMemoryStore()
+	n, err := ImportAttrLog(ctx, store, path, "WD-WCC7K0123456")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	series, err := history.AttributeSeries(ctx, store, "WD-WCC7K0123456", 5, history.Query{})
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	assert.Equal(t, int64(8), series[1].Raw)
+	temps, err := history.TemperatureSeries(ctx, store, "WD-WCC7K0123456", history.Query{})
+	require.NoError(t, err)
+	assert.Len(t, temps, 2)
+
+	n, err = ImportAttrLog(ctx, store, path, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	serials, err := store.Serials(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"WD-WCC7K0123456", "WD_WCC7K0123456"}, serials)
+}
@@ -0,0 +1,290 @@
+/*
+Package smartd reads the files written by an existing smartd installation, so
+applications replacing or running alongside smartd can import the history it
+recorded.
+
+smartd keeps one state file per device ("smartd -s", by default
+/var/lib/smartmontools/smartd.MODEL-SERIAL.TYPE.state) holding the
+temperature extremes, error counts and attribute values seen so far.
+ReadStateFile and ReadStateDir parse them.
+
+When configured with "-A", smartd also appends the attributes of every check
+to a CSV log per device (attrlog.MODEL-SERIAL.TYPE.csv). ImportAttrLog loads
+such a log into a history.Store.
+*/
+package smartd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStateDir is where smartd writes its state files unless started with
+// a different "-s" prefix.
+const DefaultStateDir = "/var/lib/smartmontools"
+
+// State is the content of one smartd state file. Counters smartd never
+// recorded are zero; in particular a zero TemperatureMin or TemperatureMax
+// means no temperature was seen.
+type State struct {
+	// Model, Serial and DeviceType ("ata", "scsi" or "nvme") come from the file
+	// name, with the characters smartd replaced by underscores left as is.
+	// They are empty when the state was parsed with ParseState.
+	Model      string `json:"model,omitempty"`
+	Serial     string `json:"serial,omitempty"`
+	DeviceType string `json:"device_type,omitempty"`
+
+	TemperatureMin int `json:"temperature_min"`
+	TemperatureMax int `json:"temperature_max"`
+	// SelfTestErrors is the number of failed self-tests in the self-test log
+	// and SelfTestLastErrorHour the power-on hour of the newest one.
+	SelfTestErrors        int `json:"self_test_errors"`
+	SelfTestLastErrorHour int `json:"self_test_last_err_hour"`
+	// ScheduledTestNextCheck is when smartd next checks its test schedule.
+	ScheduledTestNextCheck time.Time `json:"scheduled_test_next_check,omitzero"`
+	SelectiveTestLastStart uint64    `json:"selective_test_last_start"`
+	SelectiveTestLastEnd   uint64    `json:"selective_test_last_end"`
+	// ATAErrorCount is the ATA error log count and NVMeErrorLogEntries the
+	// NVMe error information log entry count smartd last saw.
+	ATAErrorCount       int    `json:"ata_error_count"`
+	NVMeErrorLogEntries uint64 `json:"nvme_err_log_entries"`
+
+	Attributes []AttributeState `json:"attributes,omitempty"`
+	Mail       []MailState      `json:"mail,omitempty"`
+
+	// Values holds every "name = value" line of the file, including those
+	// not mapped to a field above.
+	Values map[string]uint64 `json:"values"`
+}
+
+// AttributeState is an ATA SMART attribute as last recorded by smartd.
+type AttributeState struct {
+	ID       int    `json:"id"`
+	Value    int    `json:"value"`
+	Worst    int    `json:"worst"`
+	Raw      uint64 `json:"raw"`
+	Reserved int    `json:"reserved"`
+}
+
+// MailState records the warning emails smartd sent of one kind. Type is
+// smartd's index of the warning kind.
+type MailState struct {
+	Type      int       `json:"type"`
+	Count     int       `json:"count"`
+	FirstSent time.Time `json:"first_sent,omitzero"`
+	LastSent  time.Time `json:"last_sent,omitzero"`
+}
+
+// ParseState parses the content of a smartd state file. Blank lines and
+// comments starting with '#' are skipped; any other line must have the form
+// "name = value" with an unsigned decimal value.
+func ParseState(r io.Reader) (*State, error) {
+	state := &State{Values: make(map[string]uint64)}
+	attrs := make(map[int]*AttributeState)
+	mails := make(map[int]*MailState)
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("smartd: state line %d: missing '='", lineNo)
+		}
+		name = strings.TrimSpace(name)
+		value, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("smartd: state line %d: %s: %w", lineNo, name, err)
+		}
+		state.Values[name] = value
+		state.set(name, value, attrs, mails)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("smartd: read state: %w", err)
+	}
+
+	for _, index := range slices.Sorted(maps.Keys(attrs)) {
+		state.Attributes = append(state.Attributes, *attrs[index])
+	}
+	for _, index := range slices.Sorted(maps.Keys(mails)) {
+		state.Mail = append(state.Mail, *mails[index])
+	}
+	return state, nil
+}
+
+// set stores one state file value in the field it belongs to.
+func (s *State) set(name string, value uint64, attrs map[int]*AttributeState, mails map[int]*MailState) {
+	switch name {
+	case "temperature-min":
+		s.TemperatureMin = int(value)
+	case "temperature-max":
+		s.TemperatureMax = int(value)
+	case "self-test-errors":
+		s.SelfTestErrors = int(value)
+	case "self-test-last-err-hour":
+		s.SelfTestLastErrorHour = int(value)
+	case "scheduled-test-next-check":
+		s.ScheduledTestNextCheck = unixTime(value)
+	case "selective-test-last-start":
+		s.SelectiveTestLastStart = value
+	case "selective-test-last-end":
+		s.SelectiveTestLastEnd = value
+	case "ata-error-count":
+		s.ATAErrorCount = int(value)
+	case "nvme-err-log-entries":
+		s.NVMeErrorLogEntries = value
+	default:
+		if index, field, ok := indexedName(name, "ata-smart-attribute."); ok {
+			attr := attrs[index]
+			if attr == nil {
+				attr = &AttributeState{}
+				attrs[index] = attr
+			}
+			switch field {
+			case "id":
+				attr.ID = int(value)
+			case "val":
+				attr.Value = int(value)
+			case "worst":
+				attr.Worst = int(value)
+			case "raw":
+				attr.Raw = value
+			case "resvd":
+				attr.Reserved = int(value)
+			}
+		} else if index, field, ok := indexedName(name, "mail."); ok {
+			mail := mails[index]
+			if mail == nil {
+				mail = &MailState{Type: index}
+				mails[index] = mail
+			}
+			switch field {
+			case "count":
+				mail.Count = int(value)
+			case "first-sent-time":
+				mail.FirstSent = unixTime(value)
+			case "last-sent-time":
+				mail.LastSent = unixTime(value)
+			}
+		}
+	}
+}
+
+// indexedName splits names such as "mail.3.count" after prefix into the
+// index and the field name.
+func indexedName(name, prefix string) (int, string, bool) {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return 0, "", false
+	}
+	indexText, field, ok := strings.Cut(rest, ".")
+	if !ok {
+		return 0, "", false
+	}
+	index, err := strconv.Atoi(indexText)
+	if err != nil {
+		return 0, "", false
+	}
+	return index, field, true
+}
+
+func unixTime(value uint64) time.Time {
+	if value == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(value), 0)
+}
+
+// ParseStateFileName extracts the model, serial and device type from a state
+// file name such as "smartd.WDC_WD40EFRX_68N32N0-WD_WCC7K0123456.ata.state".
+// The model and serial are as sanitized by smartd (see FileNameID); for SCSI
+// devices the model is "VENDOR-PRODUCT".
+func ParseStateFileName(name string) (model, serial, deviceType string, ok bool) {
+	return parseDeviceFileName(filepath.Base(name), "smartd.", ".state")
+}
+
+// StateFileName returns the name smartd gives the state file of an ATA or
+// NVMe device, e.g. StateFileName("WDC WD40EFRX-68N32N0", "WD-WCC7K0123456",
+// "ata"). Join it to DefaultStateDir to locate the file of a known device.
+func StateFileName(model, serial, deviceType string) string {
+	return "smartd." + FileNameID(model) + "-" + FileNameID(serial) + "." + deviceType + ".state"
+}
+
+// FileNameID sanitizes a model or serial number the way smartd does when
+// naming state files and attribute logs: every character other than an ASCII
+// letter or digit becomes '_'. The '-' separating model and serial therefore
+// never appears inside either.
+func FileNameID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if ('0' <= r && r <= '9') || ('A' <= r && r <= 'Z') || ('a' <= r && r <= 'z') {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(s))
+}
+
+// parseDeviceFileName splits PREFIX MODEL-SERIAL.TYPE SUFFIX file names as
+// written by smartd for state files and attribute logs.
+func parseDeviceFileName(name, prefix, suffix string) (model, serial, deviceType string, ok bool) {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return "", "", "", false
+	}
+	if rest, ok = strings.CutSuffix(rest, suffix); !ok {
+		return "", "", "", false
+	}
+	dot := strings.LastIndexByte(rest, '.')
+	if dot < 0 {
+		return "", "", "", false
+	}
+	id, deviceType := rest[:dot], rest[dot+1:]
+	dash := strings.LastIndexByte(id, '-')
+	if dash <= 0 || dash == len(id)-1 {
+		return "", "", "", false
+	}
+	return id[:dash], id[dash+1:], deviceType, true
+}
+
+// ReadStateFile parses the smartd state file at path, filling in Model,
+// Serial and DeviceType from its name when it follows smartd's naming.
+func ReadStateFile(path string) (*State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("smartd: %w", err)
+	}
+	defer f.Close()
+	state, err := ParseState(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, path)
+	}
+	state.Model, state.Serial, state.DeviceType, _ = ParseStateFileName(path)
+	return state, nil
+}
+
+// ReadStateDir parses every smartd.*.state file in dir, sorted by file name.
+// Use DefaultStateDir for a stock smartd installation.
+func ReadStateDir(dir string) ([]*State, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "smartd.*.state"))
+	if err != nil {
+		return nil, fmt.Errorf("smartd: %w", err)
+	}
+	states := make([]*State, 0, len(paths))
+	for _, path := range paths {
+		state, err := ReadStateFile(path)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
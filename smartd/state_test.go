@@ -0,0 +1,90 @@
+package smartd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleState = `# smartd state file
+temperature-min = 24
+temperature-max = 47
+self-test-errors = 1
+self-test-last-err-hour = 35824
+scheduled-test-next-check = 1735689600
+mail.0.count = 2
+mail.0.first-sent-time = 1735000000
+mail.0.last-sent-time = 1735600000
+ata-error-count = 3
+ata-smart-attribute.1.id = 5
+ata-smart-attribute.1.val = 100
+ata-smart-attribute.1.worst = 100
+ata-smart-attribute.1.raw = 8
+ata-smart-attribute.0.id = 1
+ata-smart-attribute.0.val = 200
+ata-smart-attribute.0.worst = 199
+ata-smart-attribute.0.raw = 0
+future-field = 42
+`
+
+func TestParseState(t *testing.T) {
+	state, err := ParseState(strings.NewReader(sampleState))
+	require.NoError(t, err)
+	assert.Equal(t, 24, state.TemperatureMin)
+	assert.Equal(t, 47, state.TemperatureMax)
+	assert.Equal(t, 1, state.SelfTestErrors)
+	assert.Equal(t, 35824, state.SelfTestLastErrorHour)
+	assert.True(t, state.ScheduledTestNextCheck.Equal(time.Unix(1735689600, 0)))
+	assert.Equal(t, 3, state.ATAErrorCount)
+	assert.Equal(t, []AttributeState{
+		{ID: 1, Value: 200, Worst: 199},
+		{ID: 5, Value: 100, Worst: 100, Raw: 8},
+	}, state.Attributes)
+	require.Len(t, state.Mail, 1)
+	assert.Equal(t, 2, state.Mail[0].Count)
+	assert.True(t, state.Mail[0].LastSent.Equal(time.Unix(1735600000, 0)))
+	assert.Equal(t, uint64(42), state.Values["future-field"])
+
+	_, err = ParseState(strings.NewReader("temperature-min 24\n"))
+	assert.ErrorContains(t, err, "line 1")
+	_, err = ParseState(strings.NewReader("temperature-min = hot\n"))
+	assert.ErrorContains(t, err, "temperature-min")
+}
+
+func TestStateFileName(t *testing.T) {
+	name := StateFileName("WDC WD40EFRX-68N32N0", "WD-WCC7K0123456", "ata")
+	assert.Equal(t, "smartd.WDC_WD40EFRX_68N32N0-WD_WCC7K0123456.ata.state", name)
+
+	model, serial, deviceType, ok := ParseStateFileName("/var/lib/smartmontools/" + name)
+	require.True(t, ok)
+	assert.Equal(t, "WDC_WD40EFRX_68N32N0", model)
+	assert.Equal(t, "WD_WCC7K0123456", serial)
+	assert.Equal(t, "ata", deviceType)
+
+	_, _, _, ok = ParseStateFileName("smartd.conf")
+	assert.False(t, ok)
+}
+
+func TestReadStateDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, StateFileName("Samsung SSD 980", "S64DNX0R123", "nvme")), []byte("temperature-max = 61\nnvme-err-log-entries = 12\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "smartd.conf"), []byte("DEVICESCAN\n"), 0o600))
+
+	states, err := ReadStateDir(dir)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "Samsung_SSD_980", states[0].Model)
+	assert.Equal(t, "S64DNX0R123", states[0].Serial)
+	assert.Equal(t, "nvme", states[0].DeviceType)
+	assert.Equal(t, 61, states[0].TemperatureMax)
+	assert.Equal(t, uint64(12), states[0].NVMeErrorLogEntries)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "smartd.bad-X.ata.state"), []byte("garbage\n"), 0o600))
+	_, err = ReadStateDir(dir)
+	assert.ErrorContains(t, err, "smartd.bad-X.ata.state")
+}
@@ -2,8 +2,10 @@ package smartmontools
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"os/exec"
+	"sync"
 	"testing"
 	"time"
 
@@ -34,6 +36,10 @@ func (m *mockCmd) CombinedOutput() ([]byte, error) {
 // mockCommander implements Commander interface for testing
 type mockCommander struct {
 	cmds map[string]*mockCmd
+	// scripts answers a command with successive results and repeats the last
+	// one once exhausted; it takes precedence over cmds.
+	scripts map[string][]*mockCmd
+	mu      sync.Mutex
 }
 
 func (m *mockCommander) Command(ctx context.Context, logger LogAdapter, name string, arg ...string) Cmd {
@@ -41,6 +47,14 @@ func (m *mockCommander) Command(ctx context.Context, logger LogAdapter, name str
 	for _, a := range arg {
 		key += " " + a
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if script := m.scripts[key]; len(script) > 0 {
+		if len(script) > 1 {
+			m.scripts[key] = script[1:]
+		}
+		return script[0]
+	}
 	if cmd, ok := m.cmds[key]; ok {
 		return cmd
 	}
@@ -126,6 +140,7 @@ func TestScanDevicesFallback(t *testing.T) {
 	assert.NoError(t, err, "should succeed after --scan fallback")
 	assert.Len(t, devices, 1)
 	assert.Equal(t, "/dev/sda", devices[0].Name)
+	assert.True(t, devices[0].NotOpened)
 }
 
 func TestGetSMARTInfo(t *testing.T) {
@@ -887,6 +902,103 @@ func TestGetSMARTInfo(t *testing.T) {
 	assert.NotNil(t, info.RotationRate, "Expected rotation_rate to be set")
 	assert.Equal(t, 0, *info.RotationRate, "Expected rotation_rate 0 for SSD")
 	assert.Equal(t, "SSD", info.DiskType)
+
+	// Identity data
+	require.NotNil(t, info.WWN)
+	assert.Equal(t, "0x50026b77560145cf", info.WWN.String())
+	require.NotNil(t, info.AtaVersion)
+	assert.Equal(t, 508, info.AtaVersion.MajorValue)
+	require.NotNil(t, info.SataVersion)
+	assert.Equal(t, "SATA 3.0", info.SataVersion.String)
+	require.NotNil(t, info.InterfaceSpeed)
+	assert.Equal(t, int64(6_000_000_000), info.InterfaceSpeed.Max.BitsPerSecond())
+	assert.Equal(t, "6.0 Gb/s", info.InterfaceSpeed.Current.String)
+	assert.Nil(t, info.FormFactor)
+
+	// Media geometry
+	assert.Equal(t, 512, info.LogicalBlockSize)
+	assert.Equal(t, 512, info.PhysicalBlockSize)
+	assert.False(t, info.Is512e())
+	require.NotNil(t, info.Trim)
+	assert.True(t, info.Trim.Supported)
+	assert.False(t, info.Trim.Deterministic)
+	assert.Equal(t, ZonedNone, info.ZonedModel())
+	assert.NotContains(t, info.Extra, "wwn")
+	assert.NotContains(t, info.Extra, "interface_speed")
+}
+
+func TestLinkSpeedBitsPerSecondNil(t *testing.T) {
+	var speed *LinkSpeed
+	assert.Zero(t, speed.BitsPerSecond())
+}
+
+func TestInterfaceSpeedDegraded(t *testing.T) {
+	gen2 := &LinkSpeed{SataValue: 2, String: "3.0 Gb/s", UnitsPerSecond: 30, BitsPerUnit: 100_000_000}
+	gen3 := &LinkSpeed{SataValue: 3, String: "6.0 Gb/s", UnitsPerSecond: 60, BitsPerUnit: 100_000_000}
+
+	assert.True(t, (&InterfaceSpeed{Max: gen3, Current: gen2}).Degraded())
+	assert.False(t, (&InterfaceSpeed{Max: gen3, Current: gen3}).Degraded())
+	assert.False(t, (&InterfaceSpeed{Max: gen3}).Degraded())
+	assert.False(t, (&InterfaceSpeed{Current: gen2}).Degraded())
+	var none *InterfaceSpeed
+	assert.False(t, none.Degraded())
+}
+
+func TestZonedModel(t *testing.T) {
+	tests := []struct {
+		name string
+		info SMARTInfo
+		want ZonedModel
+	}{
+		{"conventional", SMARTInfo{}, ZonedNone},
+		{"ata device managed", SMARTInfo{ZonedDevice: &ZonedDevice{Capabilities: "Device managed zones"}}, ZonedDeviceManaged},
+		{"ata host aware", SMARTInfo{ZonedDevice: &ZonedDevice{Capabilities: "host_aware"}}, ZonedHostAware},
+		{"scsi host managed", SMARTInfo{ScsiDeviceType: &ScsiDeviceType{ScsiValue: 0x14, Name: "host managed zoned block"}}, ZonedHostManaged},
+		{"scsi disk", SMARTInfo{ScsiDeviceType: &ScsiDeviceType{ScsiValue: 0, Name: "disk"}}, ZonedNone},
+		{"not zoned", SMARTInfo{ZonedDevice: &ZonedDevice{Capabilities: "not zoned"}}, ZonedNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.info.ZonedModel())
+		})
+	}
+}
+
+func TestIs512e(t *testing.T) {
+	assert.True(t, (&SMARTInfo{LogicalBlockSize: 512, PhysicalBlockSize: 4096}).Is512e())
+	assert.False(t, (&SMARTInfo{LogicalBlockSize: 4096, PhysicalBlockSize: 4096}).Is512e())
+	assert.False(t, (&SMARTInfo{}).Is512e())
+}
+
+func TestNvmeIdentity(t *testing.T) {
+	var info SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{
+"device": {"name": "/dev/nvme0", "type": "nvme"},
+"nvme_ieee_oui_identifier": 9528,
+"nvme_total_capacity": 500107862016,
+"nvme_unallocated_capacity": 0,
+"nvme_namespaces": [{
+  "id": 1,
+  "size": {"blocks": 976773168, "bytes": 500107862016},
+  "capacity": {"blocks": 976773168, "bytes": 500107862016},
+  "utilization": {"blocks": 83751520, "bytes": 42880778240},
+  "formatted_lba_size": 512,
+  "eui64": {"oui": 9528, "ext_id": 354363188037}
+}]
+}`), &info))
+
+	require.NotNil(t, info.NvmeIeeeOuiIdentifier)
+	assert.Equal(t, uint64(0x002538), *info.NvmeIeeeOuiIdentifier)
+	assert.Equal(t, int64(500107862016), info.NvmeTotalCapacity)
+	assert.Zero(t, info.NvmeUnallocatedCapacity)
+	require.Len(t, info.NvmeNamespaces, 1)
+	ns := info.NvmeNamespaces[0]
+	assert.Equal(t, 1, ns.ID)
+	assert.Equal(t, int64(42880778240), ns.Utilization.Bytes)
+	assert.Equal(t, 512, ns.FormattedLBASize)
+	require.NotNil(t, ns.EUI64)
+	assert.Equal(t, "0025385281b12345", ns.EUI64.String())
+	assert.Empty(t, info.Extra)
 }
 
 func TestGetSMARTInfoUnsupported(t *testing.T) {
@@ -936,6 +1048,7 @@ func TestGetSMARTInfoUnsupported(t *testing.T) {
 	// When valid JSON is returned but device name is empty, error is returned
 	assert.Error(t, err)
 	assert.Equal(t, "SMART Not Supported", err.Error())
+	assert.ErrorIs(t, err, ErrSmartNotSupported)
 	assert.NotNil(t, info)
 	assert.NotNil(t, info.Smartctl)
 	assert.Empty(t, info.Device.Name)
@@ -1101,12 +1214,25 @@ func TestRunSelfTestWithProgress(t *testing.T) {
 		}
 	}`
 
+	runningJSON := `{
+		"device": {"name": "/dev/sda", "type": "ata"},
+		"ata_smart_data": {"self_test": {"status": {"value": 249, "string": "in progress"}}}
+	}`
+
+	fastSelfTestPolls(t)
 	commander := &mockCommander{
 		cmds: map[string]*mockCmd{
-			"/usr/sbin/smartctl -a -j --nocheck=standby /dev/sda": {output: []byte(mockJSON)},
 			"/usr/sbin/smartctl -c -j --nocheck=standby /dev/sda": {output: []byte(mockCapabilitiesJSON)},
 			"/usr/sbin/smartctl -t short /dev/sda":                {},
 		},
+		scripts: map[string][]*mockCmd{
+			// Once the type is cached, polls pass -d ata.
+			"/usr/sbin/smartctl -a -j --nocheck=standby /dev/sda": {{output: []byte(mockJSON)}},
+			"/usr/sbin/smartctl -a -j --nocheck=standby -d ata /dev/sda": {
+				{output: []byte(runningJSON)},
+				{output: []byte(mockJSON)},
+			},
+		},
 	}
 
 	client, _ := NewClient(WithSmartctlPath("/usr/sbin/smartctl"), WithCommander(commander))
@@ -1120,7 +1246,7 @@ func TestRunSelfTestWithProgress(t *testing.T) {
 
 	progress := make(chan int)
 
-	callback := func(iprogress int, status string) {
+	callback := func(iprogress int, status string, _ ProgressSource, _ SelfTestOutcome) {
 		progressCalled = true
 		finalProgress = iprogress
 		finalStatus = status
@@ -1776,6 +1902,7 @@ func TestGetSMARTInfoUnknownUSBBridgeFallbackFailed(t *testing.T) {
 	info, err := client.GetSMARTInfo(context.Background(), "/dev/usb0")
 	require.Error(t, err)
 	assert.Equal(t, "SMART Not Supported", err.Error())
+	assert.ErrorIs(t, err, ErrSmartNotSupported)
 	assert.Empty(t, info.Device.Name)
 
 	// Verify the device type is NOT cached (fallback failed)
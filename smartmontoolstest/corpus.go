@@ -0,0 +1,141 @@
+package smartmontoolstest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// corpusFS holds the anonymized smartctl 7.4 output of the corpus drives,
+// one directory per drive.
+//
+//go:embed corpus
+var corpusFS embed.FS
+
+// Drive is a drive known to a fake client: how ScanDevices lists it and the
+// smartctl invocations recorded for it.
+type Drive struct {
+	// Device is the entry of the drive in the "smartctl --scan-open" output.
+	Device smartmontools.Device
+	// Fixtures are recorded with "-d Device.Type", which the fake client
+	// passes from the first call on. Drives with an OpenError have no known
+	// type; their fixtures are the invocations of a client meeting them for
+	// the first time.
+	Fixtures []Fixture
+}
+
+// SATASSD is a healthy Samsung 870 EVO SATA SSD at /dev/sda.
+func SATASSD() Drive {
+	return corpusDrive("sata-ssd", smartmontools.Device{Name: "/dev/sda", Type: "sat", InfoName: "/dev/sda [SAT]", Protocol: "ATA"})
+}
+
+// SATAHDD is a WD Red SATA hard disk at /dev/sdb with 8 reallocated sectors
+// and 48,512 power-on hours.
+func SATAHDD() Drive {
+	return corpusDrive("sata-hdd", smartmontools.Device{Name: "/dev/sdb", Type: "sat", InfoName: "/dev/sdb [SAT]", Protocol: "ATA"})
+}
+
+// USBBridge is a 2.5" hard disk at /dev/sdc in a USB enclosure whose bridge
+// smartctl does not know: every command without "-d sat" fails with
+// "Unknown USB bridge", so GetSMARTInfo retries with "-d sat".
+func USBBridge() Drive {
+	return corpusDrive("usb-bridge", smartmontools.Device{Name: "/dev/sdc", Type: "scsi", InfoName: "/dev/sdc", Protocol: "SCSI",
+		OpenError: "Unknown USB bridge [0x0e8d:0x1887 (0x100)]"})
+}
+
+// StandbyHDD is a spun-down SATA hard disk at /dev/sdd: every command skips it
+// with exit status 2.
+func StandbyHDD() Drive {
+	return corpusDrive("standby-hdd", smartmontools.Device{Name: "/dev/sdd", Type: "sat", InfoName: "/dev/sdd [SAT]", Protocol: "ATA"})
+}
+
+// SASDisk is a Seagate SAS hard disk at /dev/sde with 3 grown defects.
+func SASDisk() Drive {
+	return corpusDrive("scsi", smartmontools.Device{Name: "/dev/sde", Type: "scsi", InfoName: "/dev/sde", Protocol: "SCSI"})
+}
+
+// NVMeSSD is a Samsung 980 PRO NVMe SSD at /dev/nvme0, 3% worn.
+func NVMeSSD() Drive {
+	return corpusDrive("nvme", smartmontools.Device{Name: "/dev/nvme0", Type: "nvme", InfoName: "/dev/nvme0", Protocol: "NVMe"})
+}
+
+// Corpus returns all the drives of the corpus, one per device path.
+func Corpus() []Drive {
+	return []Drive{SATASSD(), SATAHDD(), USBBridge(), StandbyHDD(), SASDisk(), NVMeSSD()}
+}
+
+// corpusDrive loads the fixtures of the corpus directory dir. The corpus is
+// embedded and covered by tests, so failing to load it is a bug.
+func corpusDrive(dir string, device smartmontools.Device) Drive {
+	return Drive{Device: device, Fixtures: mustLoadCorpus(dir)}
+}
+
+func mustLoadCorpus(dir string) []Fixture {
+	sub, err := fs.Sub(corpusFS, "corpus/"+dir)
+	if err == nil {
+		var fixtures []Fixture
+		if fixtures, err = LoadFixtures(sub); err == nil {
+			return fixtures
+		}
+	}
+	panic(fmt.Sprintf("smartmontoolstest: corpus %s: %v", dir, err))
+}
+
+// NewFakeClient returns a client that answers from the fixtures of drives
+// instead of running smartctl, e.g.
+// NewFakeClient(smartmontoolstest.SATASSD(), smartmontoolstest.NVMeSSD()).
+// ScanDevices lists the drives, SmartctlVersion reports smartctl 7.4, and
+// the corpus drives answer GetSMARTInfo, IsSMARTSupported, CheckHealth,
+// GetDeviceInfo, GetAvailableSelfTests and GetPowerMode. Other calls fail with
+// ErrNoFixture unless a drive carries a fixture for them. Use NewReplayer
+// with smartmontools.NewClient to combine fixtures with other client options.
+func NewFakeClient(drives ...Drive) (smartmontools.SmartClient, error) {
+	fixtures := mustLoadCorpus("smartctl")
+	scan, err := scanFixtures(drives)
+	if err != nil {
+		return nil, err
+	}
+	fixtures = append(fixtures, scan...)
+	types := make(map[string]smartmontools.DeviceOptions, len(drives))
+	for _, d := range drives {
+		fixtures = append(fixtures, d.Fixtures...)
+		if d.Device.Type != "" && d.Device.OpenError == "" {
+			types[d.Device.Name] = smartmontools.DeviceOptions{Type: d.Device.Type}
+		}
+	}
+	return smartmontools.NewClient(
+		smartmontools.WithSmartctlPath("smartctl"),
+		smartmontools.WithContextCommander(newReplayer(fixtures)),
+		smartmontools.WithDeviceOptions(types))
+}
+
+// scanFixtures returns the "--scan-open" and "--scan" output listing drives.
+func scanFixtures(drives []Drive) ([]Fixture, error) {
+	type scanDevice struct {
+		Name      string `json:"name"`
+		InfoName  string `json:"info_name"`
+		Type      string `json:"type"`
+		Protocol  string `json:"protocol"`
+		OpenError string `json:"open_error,omitempty"`
+	}
+	devices := make([]scanDevice, len(drives))
+	for i, d := range drives {
+		devices[i] = scanDevice{Name: d.Device.Name, InfoName: d.Device.InfoName, Type: d.Device.Type, Protocol: d.Device.Protocol, OpenError: d.Device.OpenError}
+	}
+	var fixtures []Fixture
+	for _, args := range [][]string{{"--scan-open", "--json"}, {"--scan", "--json"}} {
+		output, err := json.Marshal(map[string]any{
+			"json_format_version": []int{1, 0},
+			"smartctl":            map[string]any{"version": []int{7, 4}, "argv": append([]string{"smartctl"}, args...), "exit_status": 0},
+			"devices":             devices,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("encode scan output: %w", err)
+		}
+		fixtures = append(fixtures, Fixture{Args: args, Output: output})
+	}
+	return fixtures, nil
+}
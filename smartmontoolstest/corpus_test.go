@@ -0,0 +1,116 @@
+package smartmontoolstest
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"testing"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorpus_FixtureNames(t *testing.T) {
+	err := fs.WalkDir(corpusFS, "corpus", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(corpusFS, name)
+		require.NoError(t, err)
+		var f Fixture
+		require.NoError(t, json.Unmarshal(data, &f), name)
+		assert.Equal(t, fixtureName(f.Args), path.Base(name), "fixtures are named after their arguments")
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestNewFakeClient(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewFakeClient(Corpus()...)
+	require.NoError(t, err)
+
+	devices, err := client.ScanDevices(ctx)
+	require.NoError(t, err)
+	require.Len(t, devices, 6)
+	assert.Equal(t, "/dev/sda", devices[0].Name)
+	assert.NotEmpty(t, devices[2].OpenError, "the USB bridge cannot be opened")
+
+	version, err := client.SmartctlVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 7, version.Major)
+
+	tests := []struct {
+		path     string
+		model    string
+		diskType string
+	}{
+		{"/dev/sda", "Samsung SSD 870 EVO 1TB", "SSD"},
+		{"/dev/sdb", "WDC WD40EFRX-68N32N0", "HDD"},
+		{"/dev/sdc", "ST2000LM015-2E8174", "HDD"},
+		{"/dev/sde", "SEAGATE ST4000NM0023", "HDD"},
+		{"/dev/nvme0", "Samsung SSD 980 PRO 1TB", "NVMe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			info, err := client.GetSMARTInfo(ctx, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.model, info.ModelName)
+			assert.Equal(t, tt.diskType, info.DiskType)
+			assert.True(t, info.SmartStatus.Passed)
+
+			_, err = client.GetDeviceInfo(ctx, tt.path)
+			assert.NoError(t, err)
+			_, err = client.GetAvailableSelfTests(ctx, tt.path)
+			assert.NoError(t, err)
+			_, err = client.GetPowerMode(ctx, tt.path)
+			assert.NoError(t, err)
+		})
+	}
+
+	healthy, err := client.CheckHealth(ctx, "/dev/nvme0")
+	require.NoError(t, err)
+	assert.True(t, healthy)
+	selfTests, err := client.GetAvailableSelfTests(ctx, "/dev/sdb")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"short", "long", "conveyance", "offline"}, selfTests.Available)
+}
+
+func TestNewFakeClient_Standby(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewFakeClient(StandbyHDD())
+	require.NoError(t, err)
+
+	info, err := client.GetSMARTInfo(ctx, "/dev/sdd")
+	require.NoError(t, err)
+	assert.True(t, info.InStandby)
+	_, err = client.GetDeviceInfo(ctx, "/dev/sdd")
+	assert.ErrorIs(t, err, smartmontools.ErrDeviceInStandby)
+	mode, err := client.GetPowerMode(ctx, "/dev/sdd")
+	require.NoError(t, err)
+	assert.Equal(t, smartmontools.PowerModeStandby, mode)
+}
+
+func TestNewFakeClient_UnknownUSBBridge(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewFakeClient(USBBridge())
+	require.NoError(t, err)
+
+	healthy, err := client.CheckHealth(ctx, "/dev/sdc")
+	require.NoError(t, err)
+	assert.False(t, healthy, "the bridge fails without -d sat")
+	_, err = client.GetSMARTInfo(ctx, "/dev/sdc")
+	require.NoError(t, err)
+	healthy, err = client.CheckHealth(ctx, "/dev/sdc")
+	require.NoError(t, err)
+	assert.True(t, healthy, "later calls use the type found by the SAT probe")
+}
+
+func TestNewFakeClient_NoFixture(t *testing.T) {
+	client, err := NewFakeClient(SATASSD())
+	require.NoError(t, err)
+	_, err = client.GetSMARTInfo(context.Background(), "/dev/sdz")
+	assert.ErrorIs(t, err, ErrNoFixture)
+}
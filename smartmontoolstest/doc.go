@@ -0,0 +1,34 @@
+/*
+Package smartmontoolstest helps test code that uses smartmontools-go without
+real drives.
+
+NewFakeClient returns a client serving a corpus of anonymized smartctl output
+recorded from typical drives: a SATA SSD and hard disk, an NVMe SSD, a SAS
+disk, a disk behind a USB bridge smartctl does not know and a disk in standby:
+
+	client, err := smartmontoolstest.NewFakeClient(smartmontoolstest.Corpus()...)
+	info, err := client.GetSMARTInfo(ctx, "/dev/nvme0")
+
+A Recorder wraps the commander of a client run against real drives and saves
+every smartctl invocation as a fixture file; a Replayer serves the fixtures
+back, so the behavior captured once can be replayed in CI:
+
+	// Capture, on a machine with the drives:
+	rec := smartmontoolstest.NewRecorder("testdata/nas", nil)
+	client, _ := smartmontools.NewClient(smartmontools.WithContextCommander(rec))
+	client.ScanDevices(ctx) // ...
+
+	// Replay, in tests:
+	replayer, err := smartmontoolstest.NewReplayer(os.DirFS("testdata/nas"))
+	client, _ := smartmontools.NewClient(
+		smartmontools.WithSmartctlPath("smartctl"),
+		smartmontools.WithContextCommander(replayer))
+
+MockClient implements SmartClient with data set by the test instead of smartctl
+output, for tests of code that only consumes the interface:
+
+	mock := smartmontoolstest.NewMockClient()
+	mock.SetSMARTInfo("/dev/sda", &smartmontools.SMARTInfo{ModelName: "Test SSD"})
+	mock.SetError("/dev/sdb", smartmontools.ErrDeviceInStandby)
+*/
+package smartmontoolstest
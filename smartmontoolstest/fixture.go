@@ -0,0 +1,79 @@
+package smartmontoolstest
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// Fixture is a recorded smartctl invocation, stored as one JSON file.
+type Fixture struct {
+	// Args are the smartctl arguments, without the binary and any elevation
+	// command.
+	Args []string `json:"args"`
+	// Output holds stdout when it is JSON, so fixtures stay readable, and
+	// Stdout holds it otherwise.
+	Output   json.RawMessage `json:"output,omitempty"`
+	Stdout   string          `json:"stdout,omitempty"`
+	Stderr   string          `json:"stderr,omitempty"`
+	ExitCode int             `json:"exit_code"`
+}
+
+// newFixture records the result of running smartctl with args.
+func newFixture(args []string, res *smartmontools.CommandResult) Fixture {
+	f := Fixture{Args: args, Stderr: string(res.Stderr), ExitCode: res.ExitCode}
+	if json.Valid(res.Stdout) {
+		f.Output = json.RawMessage(res.Stdout)
+	} else {
+		f.Stdout = string(res.Stdout)
+	}
+	return f
+}
+
+// result returns the recorded output as a CommandResult.
+func (f Fixture) result() *smartmontools.CommandResult {
+	res := &smartmontools.CommandResult{Stdout: []byte(f.Stdout), Stderr: []byte(f.Stderr), ExitCode: f.ExitCode}
+	if len(f.Output) > 0 {
+		res.Stdout = []byte(f.Output)
+	}
+	return res
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9=,.+-]+`)
+
+// fixtureName returns the file name of the fixture for args, e.g.
+// "-a_-j_--nocheck=standby_dev_sda.json".
+func fixtureName(args []string) string {
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(strings.Join(args, "_"), "_"), "_")
+	if name == "" {
+		name = "smartctl"
+	}
+	return name + ".json"
+}
+
+// fixtureKey identifies the invocation of args.
+func fixtureKey(args []string) string {
+	return strings.Join(args, "\x00")
+}
+
+// smartctlArgs returns the smartctl arguments of req, dropping an elevation
+// command such as "sudo -n /usr/sbin/smartctl".
+func smartctlArgs(req smartmontools.CommandRequest) []string {
+	if isSmartctl(req.Name) {
+		return req.Args
+	}
+	for i, arg := range req.Args {
+		if isSmartctl(arg) {
+			return req.Args[i+1:]
+		}
+	}
+	return req.Args
+}
+
+func isSmartctl(path string) bool {
+	base := strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ".exe")
+	return base == "smartctl"
+}
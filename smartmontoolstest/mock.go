@@ -0,0 +1,802 @@
+package smartmontoolstest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// Call is a SmartClient method invocation recorded by MockClient.
+type Call struct {
+	Method string
+	// DevicePath is empty for methods that do not take a device.
+	DevicePath string
+	// TestType is set for the self-test methods.
+	TestType string
+}
+
+// MockClient is a SmartClient whose devices, SMART data, health and errors are
+// set by the test, without running smartctl. It implements every method of
+// SmartClient and follows the interface as it grows, so tests using it keep
+// compiling. Self-tests finish after the duration set with
+// SetSelfTestDuration, immediately by default. A MockClient is safe for
+// concurrent use.
+type MockClient struct {
+	mu         sync.Mutex
+	devices    []string
+	drives     map[string]*mockDrive
+	methodErrs map[string]error
+	version    smartmontools.SmartctlVersionInfo
+	calls      []Call
+	helpers    smartmontools.Client // zero value; only its *FromInfo helpers are used
+}
+
+var _ smartmontools.SmartClient = (*MockClient)(nil)
+
+// mockDrive is the state of one device of a MockClient.
+type mockDrive struct {
+	info           *smartmontools.SMARTInfo
+	healthy        *bool
+	err            error
+	durations      map[string]time.Duration
+	result         *smartmontools.SelfTestResult
+	powerMode      smartmontools.PowerMode
+	writeCache     bool
+	reorder        bool
+	dsn            bool
+	secureErase    *smartmontools.SecureEraseInfo
+	selectiveSpans []smartmontools.LBASpan
+}
+
+// NewMockClient returns a MockClient without devices that reports smartctl 7.4.
+func NewMockClient() *MockClient {
+	return &MockClient{
+		drives:     make(map[string]*mockDrive),
+		methodErrs: make(map[string]error),
+		version:    smartmontools.SmartctlVersionInfo{Major: 7, Minor: 4},
+	}
+}
+
+// drive returns the state of devicePath, adding the device if needed. The
+// caller holds m.mu.
+func (m *MockClient) drive(devicePath string) *mockDrive {
+	d, ok := m.drives[devicePath]
+	if !ok {
+		d = &mockDrive{powerMode: smartmontools.PowerModeActive, writeCache: true, reorder: true}
+		m.drives[devicePath] = d
+		m.devices = append(m.devices, devicePath)
+	}
+	return d
+}
+
+// SetSMARTInfo adds devicePath to the scanned devices and sets the SMARTInfo
+// GetSMARTInfo returns for it; the device type and protocol of the scan come
+// from info.Device. Callers get copies, so later changes to info only take
+// effect through another SetSMARTInfo.
+func (m *MockClient) SetSMARTInfo(devicePath string, info *smartmontools.SMARTInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drive(devicePath).info = cloneInfo(info)
+}
+
+// SetHealth sets the result of CheckHealth for devicePath, which otherwise
+// reports SMARTInfo.SmartStatus.Passed.
+func (m *MockClient) SetHealth(devicePath string, healthy bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drive(devicePath).healthy = &healthy
+}
+
+// SetError makes every call for devicePath fail with err; nil clears it. The
+// device is still listed by ScanDevices.
+func (m *MockClient) SetError(devicePath string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drive(devicePath).err = err
+}
+
+// SetMethodError makes every call of the named SmartClient method, such as
+// "ScanDevices" or "RunSelfTest", fail with err; nil clears it. Method errors
+// take precedence over device errors.
+func (m *MockClient) SetMethodError(method string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil {
+		delete(m.methodErrs, method)
+		return
+	}
+	m.methodErrs[method] = err
+}
+
+// SetSelfTestDuration makes testType available on devicePath and sets how long
+// it runs: GetAvailableSelfTests reports it in whole minutes, rounded up, and
+// RunSelfTestWithProgress and RunSelfTestAndWait take that long to return.
+func (m *MockClient) SetSelfTestDuration(devicePath, testType string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	drive := m.drive(devicePath)
+	if drive.durations == nil {
+		drive.durations = make(map[string]time.Duration)
+	}
+	drive.durations[testType] = d
+}
+
+// SetSelfTestResult sets the outcome RunSelfTestAndWait reports for
+// devicePath, which otherwise is a passed test. DevicePath, TestType and
+// Duration are filled in by RunSelfTestAndWait.
+func (m *MockClient) SetSelfTestResult(devicePath string, result smartmontools.SelfTestResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drive(devicePath).result = &result
+}
+
+// SetPowerMode sets the power mode GetPowerMode reports for devicePath,
+// PowerModeActive by default. StandbyNow and WakeDevice change it too.
+func (m *MockClient) SetPowerMode(devicePath string, mode smartmontools.PowerMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drive(devicePath).powerMode = mode
+}
+
+// SetSecureEraseInfo sets what GetSecureEraseInfo reports for devicePath,
+// which otherwise fails with ErrSmartNotSupported.
+func (m *MockClient) SetSecureEraseInfo(devicePath string, info smartmontools.SecureEraseInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drive(devicePath).secureErase = &info
+}
+
+// SetVersion sets the version SmartctlVersion reports.
+func (m *MockClient) SetVersion(version smartmontools.SmartctlVersionInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.version = version
+}
+
+// Calls returns the method calls made so far, oldest first.
+func (m *MockClient) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.calls)
+}
+
+// begin records a call and returns the drive it is for, or the error it
+// fails with. Unknown devices fail with ErrDeviceNotFound. The caller must
+// call m.mu.Unlock when begin returns no error.
+func (m *MockClient) begin(call Call) (*mockDrive, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	err := m.methodErrs[call.Method]
+	var drive *mockDrive
+	if err == nil && call.DevicePath != "" {
+		var ok bool
+		if drive, ok = m.drives[call.DevicePath]; !ok {
+			err = fmt.Errorf("%w: %s", smartmontools.ErrDeviceNotFound, call.DevicePath)
+		} else {
+			err = drive.err
+		}
+	}
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	return drive, nil
+}
+
+// smartInfo returns a copy of the SMARTInfo of drive. The caller holds m.mu.
+func smartInfo(drive *mockDrive, devicePath string) (*smartmontools.SMARTInfo, error) {
+	if drive.info == nil {
+		return nil, fmt.Errorf("%w: no SMARTInfo set for %s", smartmontools.ErrSmartNotSupported, devicePath)
+	}
+	return cloneInfo(drive.info), nil
+}
+
+// cloneInfo returns a deep copy of info: a JSON round trip, plus the computed
+// fields SMARTInfo does not encode.
+func cloneInfo(info *smartmontools.SMARTInfo) *smartmontools.SMARTInfo {
+	if info == nil {
+		return nil
+	}
+	c := &smartmontools.SMARTInfo{}
+	data, err := json.Marshal(info)
+	if err == nil {
+		err = json.Unmarshal(data, c)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("smartmontoolstest: cannot copy SMARTInfo: %v", err))
+	}
+	c.DiskType = info.DiskType
+	if info.ExitCodeInfo != nil {
+		exitCodeInfo := *info.ExitCodeInfo
+		c.ExitCodeInfo = &exitCodeInfo
+	}
+	c.Warnings = slices.Clone(info.Warnings)
+	return c
+}
+
+// ScanDevices returns the devices given SMARTInfo or other settings, in the
+// order they were first configured.
+func (m *MockClient) ScanDevices(ctx context.Context) ([]smartmontools.Device, error) {
+	if _, err := m.begin(Call{Method: "ScanDevices"}); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	return m.scan(smartmontools.ScanOptions{}), nil
+}
+
+// ScanDevicesWithOptions returns the devices matching opts.
+func (m *MockClient) ScanDevicesWithOptions(ctx context.Context, opts smartmontools.ScanOptions) ([]smartmontools.Device, error) {
+	if _, err := m.begin(Call{Method: "ScanDevicesWithOptions"}); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	return m.scan(opts), nil
+}
+
+// Devices returns an iterator over the devices of ScanDevices. A configured
+// error is yielded once with a zero Device.
+func (m *MockClient) Devices(ctx context.Context) iter.Seq2[smartmontools.Device, error] {
+	return func(yield func(smartmontools.Device, error) bool) {
+		if _, err := m.begin(Call{Method: "Devices"}); err != nil {
+			yield(smartmontools.Device{}, err)
+			return
+		}
+		devices := m.scan(smartmontools.ScanOptions{})
+		m.mu.Unlock()
+		for _, d := range devices {
+			if !yield(d, nil) {
+				return
+			}
+		}
+	}
+}
+
+// scan lists the devices matching opts. The caller holds m.mu.
+func (m *MockClient) scan(opts smartmontools.ScanOptions) []smartmontools.Device {
+	devices := []smartmontools.Device{}
+	for _, path := range m.devices {
+		device := smartmontools.Device{Name: path}
+		if info := m.drives[path].info; info != nil {
+			device = info.Device
+			device.Name = path
+		}
+		if opts.HasType(device.Type) && opts.MatchesInterface(device) {
+			devices = append(devices, device)
+		}
+	}
+	return devices
+}
+
+// GetSMARTInfo returns a copy of the SMARTInfo set for devicePath.
+func (m *MockClient) GetSMARTInfo(ctx context.Context, devicePath string) (*smartmontools.SMARTInfo, error) {
+	drive, err := m.begin(Call{Method: "GetSMARTInfo", DevicePath: devicePath})
+	if err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	return smartInfo(drive, devicePath)
+}
+
+// GetSMARTInfoRaw returns the SMARTInfo of devicePath and its JSON encoding.
+func (m *MockClient) GetSMARTInfoRaw(ctx context.Context, devicePath string) (*smartmontools.SMARTInfo, json.RawMessage, error) {
+	drive, err := m.begin(Call{Method: "GetSMARTInfoRaw", DevicePath: devicePath})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer m.mu.Unlock()
+	info, err := smartInfo(drive, devicePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := json.Marshal(info)
+	return info, raw, err
+}
+
+// RunSmartctl fails with errors.ErrUnsupported: a MockClient has no smartctl.
+func (m *MockClient) RunSmartctl(ctx context.Context, args ...string) (json.RawMessage, *smartmontools.ExitStatus, error) {
+	if _, err := m.begin(Call{Method: "RunSmartctl"}); err != nil {
+		return nil, nil, err
+	}
+	m.mu.Unlock()
+	return nil, nil, fmt.Errorf("mock client does not run smartctl: %w", errors.ErrUnsupported)
+}
+
+// CheckHealth returns the health set with SetHealth, or else whether the
+// SMARTInfo of devicePath reports a passed SMART status.
+func (m *MockClient) CheckHealth(ctx context.Context, devicePath string) (bool, error) {
+	drive, err := m.begin(Call{Method: "CheckHealth", DevicePath: devicePath})
+	if err != nil {
+		return false, err
+	}
+	defer m.mu.Unlock()
+	if drive.healthy != nil {
+		return *drive.healthy, nil
+	}
+	return drive.info != nil && drive.info.SmartStatus != nil && drive.info.SmartStatus.Passed, nil
+}
+
+// GetDeviceInfo returns the SMARTInfo of devicePath as a generic JSON map.
+func (m *MockClient) GetDeviceInfo(ctx context.Context, devicePath string) (map[string]interface{}, error) {
+	drive, err := m.begin(Call{Method: "GetDeviceInfo", DevicePath: devicePath})
+	if err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	info, err := smartInfo(drive, devicePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// selfTests returns the self-tests of drive: those its SMARTInfo advertises
+// and those given a duration. The caller holds m.mu.
+func (m *MockClient) selfTests(drive *mockDrive) *smartmontools.SelfTestInfo {
+	info := m.helpers.GetAvailableSelfTestsFromInfo(drive.info)
+	for testType, d := range drive.durations {
+		if !slices.Contains(info.Available, testType) {
+			info.Available = append(info.Available, testType)
+		}
+		info.Durations[testType] = int((d + time.Minute - 1) / time.Minute)
+	}
+	return info
+}
+
+// startSelfTest records a self-test call and checks that testType is
+// available on devicePath, returning how long the test runs.
+func (m *MockClient) startSelfTest(method, devicePath, testType string) (time.Duration, *smartmontools.SelfTestResult, error) {
+	drive, err := m.begin(Call{Method: method, DevicePath: devicePath, TestType: testType})
+	if err != nil {
+		return 0, nil, err
+	}
+	defer m.mu.Unlock()
+	if !slices.Contains(m.selfTests(drive).Available, testType) {
+		return 0, nil, fmt.Errorf("%w: test type %s is not available for this device", smartmontools.ErrTestNotSupported, testType)
+	}
+	result := smartmontools.SelfTestResult{Status: "Completed without error", Outcome: smartmontools.SelfTestPassed, Passed: true}
+	if drive.result != nil {
+		result = *drive.result
+	}
+	return drive.durations[testType], &result, nil
+}
+
+// RunSelfTest starts testType on devicePath and returns at once.
+func (m *MockClient) RunSelfTest(ctx context.Context, devicePath string, testType string) error {
+	_, _, err := m.startSelfTest("RunSelfTest", devicePath, testType)
+	return err
+}
+
+// RunSelfTestWithProgress runs testType on devicePath for its duration,
+// reporting measured progress in steps of 10%.
+func (m *MockClient) RunSelfTestWithProgress(ctx context.Context, devicePath string, testType string, callback smartmontools.ProgressCallback) error {
+	d, _, err := m.startSelfTest("RunSelfTestWithProgress", devicePath, testType)
+	if err != nil {
+		return err
+	}
+	return runProgress(ctx, d, callback)
+}
+
+// RunSelfTestWithOptions is RunSelfTestWithProgress; opts are ignored.
+func (m *MockClient) RunSelfTestWithOptions(ctx context.Context, devicePath string, testType string, opts smartmontools.SelfTestOptions, callback smartmontools.ProgressCallback) error {
+	d, _, err := m.startSelfTest("RunSelfTestWithOptions", devicePath, testType)
+	if err != nil {
+		return err
+	}
+	return runProgress(ctx, d, callback)
+}
+
+// runProgress reports progress from 0 to 100 over d, or until ctx ends.
+func runProgress(ctx context.Context, d time.Duration, callback smartmontools.ProgressCallback) error {
+	for percent := 0; percent < 100; percent += 10 {
+		if callback != nil {
+			callback(percent, "Self-test routine in progress", smartmontools.ProgressMeasured, "")
+		}
+		if err := sleep(ctx, d/10); err != nil {
+			return err
+		}
+	}
+	if callback != nil {
+		callback(100, "Completed without error", smartmontools.ProgressMeasured, smartmontools.SelfTestPassed)
+	}
+	return nil
+}
+
+// sleep waits for d or until ctx ends.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RunSelfTestAndWait runs testType on devicePath for its duration and returns
+// the result set with SetSelfTestResult, a passed test by default. Like
+// Client.RunSelfTestAndWait, aborted and interrupted results also return an
+// error wrapping ErrSelfTestAborted.
+func (m *MockClient) RunSelfTestAndWait(ctx context.Context, devicePath string, testType string) (*smartmontools.SelfTestResult, error) {
+	d, result, err := m.startSelfTest("RunSelfTestAndWait", devicePath, testType)
+	if err != nil {
+		return nil, err
+	}
+	if err := sleep(ctx, d); err != nil {
+		return nil, err
+	}
+	result.DevicePath, result.TestType, result.Duration = devicePath, testType, d
+	return result, result.Err()
+}
+
+// RunSelectiveSelfTest records spans as the selective self-test log of
+// devicePath.
+func (m *MockClient) RunSelectiveSelfTest(ctx context.Context, devicePath string, spans []smartmontools.LBASpan) error {
+	return m.runSelective("RunSelectiveSelfTest", devicePath, spans)
+}
+
+// RunSelectiveSelfTestWithOptions is RunSelectiveSelfTest after validating
+// spans against opts.
+func (m *MockClient) RunSelectiveSelfTestWithOptions(ctx context.Context, devicePath string, spans []smartmontools.LBASpan, opts smartmontools.SelectiveSelfTestOptions) error {
+	if err := opts.Validate(spans); err != nil {
+		return err
+	}
+	return m.runSelective("RunSelectiveSelfTestWithOptions", devicePath, spans)
+}
+
+func (m *MockClient) runSelective(method, devicePath string, spans []smartmontools.LBASpan) error {
+	drive, err := m.begin(Call{Method: method, DevicePath: devicePath, TestType: "selective"})
+	if err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	drive.selectiveSpans = slices.Clone(spans)
+	return nil
+}
+
+// GetSelectiveSelfTestLog returns the selective self-test log of the
+// SMARTInfo of devicePath, or one listing the spans of the last
+// RunSelectiveSelfTest.
+func (m *MockClient) GetSelectiveSelfTestLog(ctx context.Context, devicePath string) (*smartmontools.SelectiveSelfTestLog, error) {
+	drive, err := m.begin(Call{Method: "GetSelectiveSelfTestLog", DevicePath: devicePath})
+	if err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	if drive.selectiveSpans != nil {
+		log := &smartmontools.SelectiveSelfTestLog{}
+		for _, span := range drive.selectiveSpans {
+			log.Table = append(log.Table, smartmontools.SelectiveSelfTestSpan{LbaMin: span.Start, LbaMax: span.End})
+		}
+		return log, nil
+	}
+	if drive.info != nil && drive.info.AtaSmartSelectiveSelfTestLog != nil {
+		log := *drive.info.AtaSmartSelectiveSelfTestLog
+		return &log, nil
+	}
+	return nil, fmt.Errorf("%w: %s has no selective self-test log", smartmontools.ErrTestNotSupported, devicePath)
+}
+
+// GetAvailableSelfTests returns the self-tests the SMARTInfo of devicePath
+// advertises, together with those given a duration.
+func (m *MockClient) GetAvailableSelfTests(ctx context.Context, devicePath string) (*smartmontools.SelfTestInfo, error) {
+	drive, err := m.begin(Call{Method: "GetAvailableSelfTests", DevicePath: devicePath})
+	if err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	return m.selfTests(drive), nil
+}
+
+// GetAvailableSelfTestsFromInfo works like Client.GetAvailableSelfTestsFromInfo.
+func (m *MockClient) GetAvailableSelfTestsFromInfo(smartInfo *smartmontools.SMARTInfo) *smartmontools.SelfTestInfo {
+	return m.helpers.GetAvailableSelfTestsFromInfo(smartInfo)
+}
+
+// IsSMARTSupported reports the SMART support of the SMARTInfo of devicePath.
+func (m *MockClient) IsSMARTSupported(ctx context.Context, devicePath string) (*smartmontools.SmartSupport, error) {
+	drive, err := m.begin(Call{Method: "IsSMARTSupported", DevicePath: devicePath})
+	if err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	info, err := smartInfo(drive, devicePath)
+	if err != nil {
+		return nil, err
+	}
+	return m.helpers.GetSMARTSupportFromInfo(info), nil
+}
+
+// GetSMARTSupportFromInfo works like Client.GetSMARTSupportFromInfo.
+func (m *MockClient) GetSMARTSupportFromInfo(smartInfo *smartmontools.SMARTInfo) *smartmontools.SmartSupport {
+	return m.helpers.GetSMARTSupportFromInfo(smartInfo)
+}
+
+// EnableSMART marks SMART enabled in the SMARTInfo of devicePath.
+func (m *MockClient) EnableSMART(ctx context.Context, devicePath string) error {
+	return m.setSMARTEnabled("EnableSMART", devicePath, true)
+}
+
+// DisableSMART marks SMART disabled in the SMARTInfo of devicePath.
+func (m *MockClient) DisableSMART(ctx context.Context, devicePath string) error {
+	return m.setSMARTEnabled("DisableSMART", devicePath, false)
+}
+
+func (m *MockClient) setSMARTEnabled(method, devicePath string, enabled bool) error {
+	drive, err := m.begin(Call{Method: method, DevicePath: devicePath})
+	if err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	if drive.info != nil {
+		info := cloneInfo(drive.info)
+		info.SmartSupport = &smartmontools.SmartSupport{Available: true, Enabled: enabled}
+		drive.info = info
+	}
+	return nil
+}
+
+// AbortSelfTest records the call.
+func (m *MockClient) AbortSelfTest(ctx context.Context, devicePath string) error {
+	return m.record("AbortSelfTest", devicePath)
+}
+
+// DiscoverDevices probes every scanned device with GetSMARTInfo.
+func (m *MockClient) DiscoverDevices(ctx context.Context) ([]smartmontools.DiscoveryResult, error) {
+	if _, err := m.begin(Call{Method: "DiscoverDevices"}); err != nil {
+		return nil, err
+	}
+	devices := m.scan(smartmontools.ScanOptions{})
+	m.mu.Unlock()
+	results := make([]smartmontools.DiscoveryResult, 0, len(devices))
+	for _, device := range devices {
+		result := smartmontools.DiscoveryResult{DevicePath: device.Name}
+		if info, err := m.GetSMARTInfo(ctx, device.Name); err == nil {
+			result.DetectedProtocol = info.Device.Type
+			result.SMARTReadable = true
+			result.Model = info.ModelName
+			result.Serial = info.SerialNumber
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SmartctlVersion returns the version set with SetVersion, 7.4 by default.
+func (m *MockClient) SmartctlVersion(ctx context.Context) (*smartmontools.SmartctlVersionInfo, error) {
+	if _, err := m.begin(Call{Method: "SmartctlVersion"}); err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	version := m.version
+	return &version, nil
+}
+
+// GetExtendedSMARTInfo returns the SMARTInfo of devicePath without extended
+// sections.
+func (m *MockClient) GetExtendedSMARTInfo(ctx context.Context, devicePath string) (*smartmontools.ExtendedSMARTInfo, error) {
+	drive, err := m.begin(Call{Method: "GetExtendedSMARTInfo", DevicePath: devicePath})
+	if err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	info, err := smartInfo(drive, devicePath)
+	if err != nil {
+		return nil, err
+	}
+	return &smartmontools.ExtendedSMARTInfo{SMARTInfo: *info}, nil
+}
+
+// Watch sends a Reading of devicePath right away and then every interval,
+// until ctx ends. Unlike Client.Watch it neither skips standby readings nor
+// backs off after failures.
+func (m *MockClient) Watch(ctx context.Context, devicePath string, interval time.Duration) <-chan smartmontools.Reading {
+	if interval <= 0 {
+		interval = smartmontools.DefaultWatchInterval
+	}
+	readings := make(chan smartmontools.Reading, 1)
+	go func() {
+		defer close(readings)
+		for {
+			info, err := m.GetSMARTInfo(ctx, devicePath)
+			select {
+			case readings <- smartmontools.Reading{DevicePath: devicePath, Time: time.Now(), Info: info, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if sleep(ctx, interval) != nil {
+				return
+			}
+		}
+	}()
+	return readings
+}
+
+// GetPowerMode returns the power mode of devicePath.
+func (m *MockClient) GetPowerMode(ctx context.Context, devicePath string) (smartmontools.PowerMode, error) {
+	drive, err := m.begin(Call{Method: "GetPowerMode", DevicePath: devicePath})
+	if err != nil {
+		return smartmontools.PowerModeUnknown, err
+	}
+	defer m.mu.Unlock()
+	return drive.powerMode, nil
+}
+
+// SetStandbyTimer records the call.
+func (m *MockClient) SetStandbyTimer(ctx context.Context, devicePath string, timeout time.Duration) error {
+	return m.record("SetStandbyTimer", devicePath)
+}
+
+// StandbyNow puts devicePath in PowerModeStandby.
+func (m *MockClient) StandbyNow(ctx context.Context, devicePath string) error {
+	return m.update("StandbyNow", devicePath, func(d *mockDrive) { d.powerMode = smartmontools.PowerModeStandby })
+}
+
+// WakeDevice puts devicePath in PowerModeActive.
+func (m *MockClient) WakeDevice(ctx context.Context, devicePath string) error {
+	return m.update("WakeDevice", devicePath, func(d *mockDrive) { d.powerMode = smartmontools.PowerModeActive })
+}
+
+// GetWriteCache reports the write cache setting of devicePath, enabled by default.
+func (m *MockClient) GetWriteCache(ctx context.Context, devicePath string) (bool, error) {
+	return m.read("GetWriteCache", devicePath, func(d *mockDrive) bool { return d.writeCache })
+}
+
+// SetWriteCache changes the write cache setting of devicePath.
+func (m *MockClient) SetWriteCache(ctx context.Context, devicePath string, enabled bool) error {
+	return m.update("SetWriteCache", devicePath, func(d *mockDrive) { d.writeCache = enabled })
+}
+
+// GetWriteCacheReorder reports the write cache reordering setting of
+// devicePath, enabled by default.
+func (m *MockClient) GetWriteCacheReorder(ctx context.Context, devicePath string) (bool, error) {
+	return m.read("GetWriteCacheReorder", devicePath, func(d *mockDrive) bool { return d.reorder })
+}
+
+// SetWriteCacheReorder changes the write cache reordering setting of devicePath.
+func (m *MockClient) SetWriteCacheReorder(ctx context.Context, devicePath string, enabled bool) error {
+	return m.update("SetWriteCacheReorder", devicePath, func(d *mockDrive) { d.reorder = enabled })
+}
+
+// GetDSN reports the DSN setting of devicePath, disabled by default.
+func (m *MockClient) GetDSN(ctx context.Context, devicePath string) (bool, error) {
+	return m.read("GetDSN", devicePath, func(d *mockDrive) bool { return d.dsn })
+}
+
+// SetDSN changes the DSN setting of devicePath.
+func (m *MockClient) SetDSN(ctx context.Context, devicePath string, enabled bool) error {
+	return m.update("SetDSN", devicePath, func(d *mockDrive) { d.dsn = enabled })
+}
+
+// EnableAttributeAutosave records the call.
+func (m *MockClient) EnableAttributeAutosave(ctx context.Context, devicePath string) error {
+	return m.record("EnableAttributeAutosave", devicePath)
+}
+
+// DisableAttributeAutosave records the call.
+func (m *MockClient) DisableAttributeAutosave(ctx context.Context, devicePath string) error {
+	return m.record("DisableAttributeAutosave", devicePath)
+}
+
+// EnableAutoOfflineCollection records the call.
+func (m *MockClient) EnableAutoOfflineCollection(ctx context.Context, devicePath string) error {
+	return m.record("EnableAutoOfflineCollection", devicePath)
+}
+
+// DisableAutoOfflineCollection records the call.
+func (m *MockClient) DisableAutoOfflineCollection(ctx context.Context, devicePath string) error {
+	return m.record("DisableAutoOfflineCollection", devicePath)
+}
+
+// SecurityFreeze marks the security state set with SetSecureEraseInfo frozen.
+func (m *MockClient) SecurityFreeze(ctx context.Context, devicePath string) error {
+	return m.update("SecurityFreeze", devicePath, func(d *mockDrive) {
+		if d.secureErase != nil {
+			d.secureErase.Frozen = true
+		}
+	})
+}
+
+// GetSecureEraseInfo returns the security state set with SetSecureEraseInfo.
+func (m *MockClient) GetSecureEraseInfo(ctx context.Context, devicePath string) (*smartmontools.SecureEraseInfo, error) {
+	drive, err := m.begin(Call{Method: "GetSecureEraseInfo", DevicePath: devicePath})
+	if err != nil {
+		return nil, err
+	}
+	defer m.mu.Unlock()
+	if drive.secureErase == nil {
+		return nil, fmt.Errorf("%w: no security state set for %s", smartmontools.ErrSmartNotSupported, devicePath)
+	}
+	info := *drive.secureErase
+	return &info, nil
+}
+
+// CollectAll calls GetSMARTInfo for opts.Devices, or every scanned device,
+// one after another. Only ScanDevices failures are returned as an error.
+func (m *MockClient) CollectAll(ctx context.Context, opts smartmontools.CollectOptions) (map[string]smartmontools.CollectResult, error) {
+	if _, err := m.begin(Call{Method: "CollectAll"}); err != nil {
+		return nil, err
+	}
+	m.mu.Unlock()
+	devices := opts.Devices
+	if len(devices) == 0 {
+		scanned, err := m.ScanDevices(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan devices: %w", err)
+		}
+		for _, d := range scanned {
+			devices = append(devices, d.Name)
+		}
+	}
+	results := make(map[string]smartmontools.CollectResult, len(devices))
+	for _, device := range devices {
+		info, err := m.GetSMARTInfo(ctx, device)
+		results[device] = smartmontools.CollectResult{Info: info, Err: err}
+	}
+	return results, nil
+}
+
+// FleetHealth summarizes the CollectAll results of every scanned device with
+// SummarizeFleet.
+func (m *MockClient) FleetHealth(ctx context.Context) (*smartmontools.FleetSummary, error) {
+	if _, err := m.begin(Call{Method: "FleetHealth"}); err != nil {
+		return nil, err
+	}
+	m.mu.Unlock()
+	results, err := m.CollectAll(ctx, smartmontools.CollectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return smartmontools.SummarizeFleet(results), nil
+}
+
+// Close records the call.
+func (m *MockClient) Close() error {
+	return m.record("Close", "")
+}
+
+// record records a call that only fails with a configured error.
+func (m *MockClient) record(method, devicePath string) error {
+	return m.update(method, devicePath, func(*mockDrive) {})
+}
+
+// update records a call and applies change to the drive it is for.
+func (m *MockClient) update(method, devicePath string, change func(*mockDrive)) error {
+	drive, err := m.begin(Call{Method: method, DevicePath: devicePath})
+	if err != nil {
+		return err
+	}
+	defer m.mu.Unlock()
+	if drive != nil {
+		change(drive)
+	}
+	return nil
+}
+
+// read records a call and returns a setting of the drive it is for.
+func (m *MockClient) read(method, devicePath string, setting func(*mockDrive) bool) (bool, error) {
+	drive, err := m.begin(Call{Method: method, DevicePath: devicePath})
+	if err != nil {
+		return false, err
+	}
+	defer m.mu.Unlock()
+	return setting(drive), nil
+}
@@ -0,0 +1,226 @@
+package smartmontoolstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockClient(t *testing.T) {
+	ctx := context.Background()
+	m := NewMockClient()
+	m.SetSMARTInfo("/dev/sda", &smartmontools.SMARTInfo{
+		Device:      smartmontools.Device{Name: "ignored", Type: "sat"},
+		ModelName:   "Mock SSD",
+		SmartStatus: &smartmontools.SmartStatus{Passed: true},
+	})
+	m.SetSMARTInfo("/dev/nvme0", &smartmontools.SMARTInfo{Device: smartmontools.Device{Type: "nvme"}})
+	m.SetHealth("/dev/nvme0", false)
+
+	devices, err := m.ScanDevices(ctx)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, smartmontools.Device{Name: "/dev/sda", Type: "sat"}, devices[0])
+	devices, err = m.ScanDevicesWithOptions(ctx, smartmontools.ScanOptions{Interfaces: []string{"nvme"}})
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "/dev/nvme0", devices[0].Name)
+
+	info, err := m.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "Mock SSD", info.ModelName)
+	info.ModelName = "changed"
+	info.SmartStatus.Passed = false
+	info, err = m.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, "Mock SSD", info.ModelName, "callers get copies")
+	assert.True(t, info.SmartStatus.Passed, "copies are deep")
+
+	healthy, err := m.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.True(t, healthy, "health follows the SMART status")
+	healthy, err = m.CheckHealth(ctx, "/dev/nvme0")
+	require.NoError(t, err)
+	assert.False(t, healthy)
+
+	_, err = m.GetSMARTInfo(ctx, "/dev/sdz")
+	assert.ErrorIs(t, err, smartmontools.ErrDeviceNotFound)
+
+	results, err := m.CollectAll(ctx, smartmontools.CollectOptions{})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	assert.Equal(t, []Call{
+		{Method: "ScanDevices"},
+		{Method: "ScanDevicesWithOptions"},
+		{Method: "GetSMARTInfo", DevicePath: "/dev/sda"},
+		{Method: "GetSMARTInfo", DevicePath: "/dev/sda"},
+		{Method: "CheckHealth", DevicePath: "/dev/sda"},
+		{Method: "CheckHealth", DevicePath: "/dev/nvme0"},
+		{Method: "GetSMARTInfo", DevicePath: "/dev/sdz"},
+		{Method: "CollectAll"},
+		{Method: "ScanDevices"},
+		{Method: "GetSMARTInfo", DevicePath: "/dev/sda"},
+		{Method: "GetSMARTInfo", DevicePath: "/dev/nvme0"},
+	}, m.Calls())
+}
+
+func TestMockClient_Errors(t *testing.T) {
+	ctx := context.Background()
+	m := NewMockClient()
+	m.SetSMARTInfo("/dev/sda", &smartmontools.SMARTInfo{})
+	m.SetError("/dev/sda", smartmontools.ErrDeviceInStandby)
+
+	_, err := m.GetSMARTInfo(ctx, "/dev/sda")
+	assert.ErrorIs(t, err, smartmontools.ErrDeviceInStandby)
+	results, err := m.CollectAll(ctx, smartmontools.CollectOptions{})
+	require.NoError(t, err)
+	assert.ErrorIs(t, results["/dev/sda"].Err, smartmontools.ErrDeviceInStandby)
+
+	m.SetError("/dev/sda", nil)
+	_, err = m.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+
+	scanErr := errors.New("smartctl not found")
+	m.SetMethodError("ScanDevices", scanErr)
+	_, err = m.ScanDevices(ctx)
+	assert.ErrorIs(t, err, scanErr)
+	_, err = m.CollectAll(ctx, smartmontools.CollectOptions{})
+	assert.ErrorIs(t, err, scanErr)
+	m.SetMethodError("ScanDevices", nil)
+	_, err = m.ScanDevices(ctx)
+	require.NoError(t, err)
+
+	_, _, err = m.RunSmartctl(ctx, "-i", "/dev/sda")
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+}
+
+func TestMockClient_SelfTest(t *testing.T) {
+	ctx := context.Background()
+	m := NewMockClient()
+	m.SetSelfTestDuration("/dev/sda", "short", 30*time.Millisecond)
+
+	tests, err := m.GetAvailableSelfTests(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"short"}, tests.Available)
+	assert.Equal(t, 1, tests.Durations["short"], "durations are rounded up to minutes")
+
+	err = m.RunSelfTest(ctx, "/dev/sda", "long")
+	assert.ErrorIs(t, err, smartmontools.ErrTestNotSupported)
+
+	var progress []int
+	start := time.Now()
+	err = m.RunSelfTestWithProgress(ctx, "/dev/sda", "short", func(p int, _ string, _ smartmontools.ProgressSource, _ smartmontools.SelfTestOutcome) {
+		progress = append(progress, p)
+	})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	assert.Equal(t, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100}, progress)
+
+	result, err := m.RunSelfTestAndWait(ctx, "/dev/sda", "short")
+	require.NoError(t, err)
+	assert.Equal(t, smartmontools.SelfTestPassed, result.Outcome)
+	assert.Equal(t, "/dev/sda", result.DevicePath)
+	assert.Equal(t, 30*time.Millisecond, result.Duration)
+
+	m.SetSelfTestResult("/dev/sda", smartmontools.SelfTestResult{Status: "Completed: read failure", Outcome: smartmontools.SelfTestFailed})
+	result, err = m.RunSelfTestAndWait(ctx, "/dev/sda", "short")
+	require.NoError(t, err, "failed tests are reported in the result")
+	assert.Equal(t, smartmontools.SelfTestFailed, result.Outcome)
+	m.SetSelfTestResult("/dev/sda", smartmontools.SelfTestResult{Status: "Aborted by host", Outcome: smartmontools.SelfTestAborted})
+	_, err = m.RunSelfTestAndWait(ctx, "/dev/sda", "short")
+	assert.ErrorIs(t, err, smartmontools.ErrSelfTestAborted)
+
+	m.SetSelfTestDuration("/dev/sda", "long", time.Hour)
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = m.RunSelfTestAndWait(cctx, "/dev/sda", "long")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMockClient_Settings(t *testing.T) {
+	ctx := context.Background()
+	m := NewMockClient()
+	m.SetPowerMode("/dev/sda", smartmontools.PowerModeIdle)
+
+	mode, err := m.GetPowerMode(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, smartmontools.PowerModeIdle, mode)
+	require.NoError(t, m.StandbyNow(ctx, "/dev/sda"))
+	mode, err = m.GetPowerMode(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, smartmontools.PowerModeStandby, mode)
+
+	enabled, err := m.GetWriteCache(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	require.NoError(t, m.SetWriteCache(ctx, "/dev/sda", false))
+	enabled, err = m.GetWriteCache(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	_, err = m.GetSecureEraseInfo(ctx, "/dev/sda")
+	assert.ErrorIs(t, err, smartmontools.ErrSmartNotSupported)
+	m.SetSecureEraseInfo("/dev/sda", smartmontools.SecureEraseInfo{Supported: true})
+	require.NoError(t, m.SecurityFreeze(ctx, "/dev/sda"))
+	erase, err := m.GetSecureEraseInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.True(t, erase.Frozen)
+}
+
+func TestMockClient_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewMockClient()
+	m.SetSMARTInfo("/dev/sda", &smartmontools.SMARTInfo{ModelName: "Mock SSD"})
+
+	readings := m.Watch(ctx, "/dev/sda", time.Millisecond)
+	for range 3 {
+		r := <-readings
+		require.NoError(t, r.Err)
+		assert.Equal(t, "Mock SSD", r.Info.ModelName)
+	}
+	cancel()
+	for range readings {
+	}
+}
+
+func TestMockClient_FleetHealth(t *testing.T) {
+	m := NewMockClient()
+	m.SetSMARTInfo("/dev/sda", &smartmontools.SMARTInfo{SmartStatus: &smartmontools.SmartStatus{Passed: true}})
+	m.SetSMARTInfo("/dev/sdb", &smartmontools.SMARTInfo{InStandby: true})
+	m.SetError("/dev/sdc", smartmontools.ErrPermissionDenied)
+
+	summary, err := m.FleetHealth(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, 1, summary.Healthy)
+	assert.Equal(t, 1, summary.Standby)
+	assert.Equal(t, 1, summary.Unknown)
+
+	m.SetMethodError("FleetHealth", errors.New("boom"))
+	_, err = m.FleetHealth(context.Background())
+	assert.EqualError(t, err, "boom")
+}
+
+func TestMockClient_Devices(t *testing.T) {
+	m := NewMockClient()
+	m.SetSMARTInfo("/dev/sda", &smartmontools.SMARTInfo{})
+	m.SetSMARTInfo("/dev/sdb", &smartmontools.SMARTInfo{})
+
+	var names []string
+	for d, err := range m.Devices(context.Background()) {
+		require.NoError(t, err)
+		names = append(names, d.Name)
+	}
+	assert.Equal(t, []string{"/dev/sda", "/dev/sdb"}, names)
+
+	m.SetMethodError("Devices", errors.New("boom"))
+	for _, err := range m.Devices(context.Background()) {
+		assert.EqualError(t, err, "boom")
+	}
+}
@@ -0,0 +1,75 @@
+package smartmontoolstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// Recorder is a ContextCommander that runs commands with another commander
+// and saves every smartctl invocation that ran as a Fixture in a directory,
+// one file per argument list. Repeated invocations with the same arguments
+// overwrite the fixture, so the last output wins.
+type Recorder struct {
+	dir  string
+	next smartmontools.ContextCommander
+
+	mu  sync.Mutex
+	err error
+}
+
+var _ smartmontools.ContextCommander = (*Recorder)(nil)
+
+// NewRecorder returns a Recorder saving fixtures to dir, which is created on
+// the first write. A nil next runs commands with
+// smartmontools.DefaultCommander.
+func NewRecorder(dir string, next smartmontools.ContextCommander) *Recorder {
+	if next == nil {
+		next = smartmontools.DefaultCommander()
+	}
+	return &Recorder{dir: dir, next: next}
+}
+
+// CommandContext runs req with the wrapped commander and records its output.
+// Failures to write the fixture do not fail the command; see Err.
+func (r *Recorder) CommandContext(ctx context.Context, logger smartmontools.LogAdapter, req smartmontools.CommandRequest) (*smartmontools.CommandResult, error) {
+	res, err := r.next.CommandContext(ctx, logger, req)
+	if res != nil && res.ExitCode >= 0 {
+		r.save(newFixture(smartctlArgs(req), res))
+	}
+	return res, err
+}
+
+// Err returns the first error writing a fixture, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) save(f Fixture) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := writeFixture(r.dir, f); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+func writeFixture(dir string, f Fixture) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode fixture: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create fixture directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, fixtureName(f.Args)), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write fixture: %w", err)
+	}
+	return nil
+}
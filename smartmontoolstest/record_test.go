@@ -0,0 +1,109 @@
+package smartmontoolstest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sdaInfo = `{
+"json_format_version": [1, 0],
+"smartctl": {"version": [7, 4], "exit_status": 0},
+"device": {"name": "/dev/sda", "type": "sat"},
+"model_name": "Test Drive",
+"serial_number": "S1",
+"smart_status": {"passed": true},
+"temperature": {"current": 34}
+}`
+
+// scriptedCommander answers smartctl invocations by their joined arguments.
+type scriptedCommander map[string]*smartmontools.CommandResult
+
+func (s scriptedCommander) CommandContext(_ context.Context, _ smartmontools.LogAdapter, req smartmontools.CommandRequest) (*smartmontools.CommandResult, error) {
+	res, ok := s[strings.Join(req.Args, " ")]
+	if !ok {
+		return &smartmontools.CommandResult{ExitCode: -1}, errors.New("exec: not scripted")
+	}
+	if res.ExitCode != 0 {
+		return res, errors.New("exit status")
+	}
+	return res, nil
+}
+
+func newClient(t *testing.T, commander smartmontools.ContextCommander) smartmontools.SmartClient {
+	t.Helper()
+	client, err := smartmontools.NewClient(smartmontools.WithSmartctlPath("/usr/sbin/smartctl"), smartmontools.WithContextCommander(commander))
+	require.NoError(t, err)
+	return client
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	rec := NewRecorder(dir, scriptedCommander{
+		"-a -j --nocheck=standby /dev/sda":     {Stdout: []byte(sdaInfo)},
+		"-H --nocheck=standby -d sat /dev/sda": {Stdout: []byte("SMART overall-health self-assessment test result: PASSED\n")},
+		"-i -j --nocheck=standby /dev/sdb":     {Stdout: []byte(`{"smartctl":{"exit_status":2}}`), ExitCode: 2},
+	})
+	recorded := newClient(t, rec)
+	want, err := recorded.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, err = recorded.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	_, _ = recorded.GetDeviceInfo(ctx, "/dev/sdb")
+	_, err = recorded.GetDeviceInfo(ctx, "/dev/sdc")
+	require.Error(t, err)
+	require.NoError(t, rec.Err())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "commands that did not run are not recorded")
+	data, err := os.ReadFile(filepath.Join(dir, "-a_-j_--nocheck=standby_dev_sda.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"model_name": "Test Drive"`, "JSON output is stored as JSON")
+
+	replayer, err := NewReplayer(os.DirFS(dir))
+	require.NoError(t, err)
+	replayed := newClient(t, replayer)
+
+	got, err := replayed.GetSMARTInfo(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	healthy, err := replayed.CheckHealth(ctx, "/dev/sda")
+	require.NoError(t, err)
+	assert.True(t, healthy)
+	_, err = replayed.GetDeviceInfo(ctx, "/dev/sdb")
+	assert.ErrorIs(t, err, smartmontools.ErrDeviceInStandby, "recorded exit codes are replayed")
+	_, err = replayed.GetDeviceInfo(ctx, "/dev/sdc")
+	assert.ErrorIs(t, err, ErrNoFixture)
+}
+
+func TestSmartctlArgs(t *testing.T) {
+	assert.Equal(t, []string{"-H", "/dev/sda"}, smartctlArgs(smartmontools.CommandRequest{Name: "/usr/sbin/smartctl", Args: []string{"-H", "/dev/sda"}}))
+	assert.Equal(t, []string{"-H", "/dev/sda"}, smartctlArgs(smartmontools.CommandRequest{Name: "sudo", Args: []string{"-n", "/usr/sbin/smartctl", "-H", "/dev/sda"}}))
+	assert.Equal(t, []string{"-H", "/dev/sda"}, smartctlArgs(smartmontools.CommandRequest{Name: `C:\smartmontools\bin\smartctl.exe`, Args: []string{"-H", "/dev/sda"}}))
+}
+
+func TestRecorder_WriteError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+	rec := NewRecorder(file, scriptedCommander{"-H /dev/sda": {Stdout: []byte("PASSED")}})
+	res, err := rec.CommandContext(context.Background(), nil, smartmontools.CommandRequest{Name: "smartctl", Args: []string{"-H", "/dev/sda"}})
+	require.NoError(t, err, "the command itself succeeds")
+	assert.Equal(t, "PASSED", string(res.Stdout))
+	assert.ErrorContains(t, rec.Err(), "fixture directory")
+}
+
+func TestNewReplayer_InvalidFixture(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0o644))
+	_, err := NewReplayer(os.DirFS(dir))
+	assert.ErrorContains(t, err, "fixture bad.json")
+}
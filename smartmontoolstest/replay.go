@@ -0,0 +1,85 @@
+package smartmontoolstest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	osexec "os/exec"
+	"strings"
+
+	smartmontools "github.com/dianlight/smartmontools-go"
+)
+
+// ErrNoFixture is returned by a Replayer for invocations it has no fixture for.
+var ErrNoFixture = errors.New("no fixture recorded")
+
+// Replayer is a ContextCommander that serves recorded fixtures instead of
+// running smartctl. Invocations are matched on their exact smartctl
+// arguments; the binary path and any elevation command are ignored.
+type Replayer struct {
+	fixtures map[string]Fixture
+}
+
+var _ smartmontools.ContextCommander = (*Replayer)(nil)
+
+// NewReplayer loads every *.json fixture at the top of fsys, such as
+// os.DirFS("testdata/nas") or an embed.FS.
+func NewReplayer(fsys fs.FS) (*Replayer, error) {
+	fixtures, err := LoadFixtures(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return newReplayer(fixtures), nil
+}
+
+// newReplayer serves fixtures; of several with the same arguments, the last
+// one wins.
+func newReplayer(fixtures []Fixture) *Replayer {
+	r := &Replayer{fixtures: make(map[string]Fixture, len(fixtures))}
+	for _, f := range fixtures {
+		r.fixtures[fixtureKey(f.Args)] = f
+	}
+	return r
+}
+
+// LoadFixtures reads every *.json fixture at the top of fsys.
+func LoadFixtures(fsys fs.FS) ([]Fixture, error) {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	fixtures := make([]Fixture, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var f Fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", name, err)
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+// CommandContext returns the recorded output for the arguments of req. A
+// non-zero recorded exit code is returned in the result together with an
+// *exec.ExitError, like a real failed run.
+func (r *Replayer) CommandContext(ctx context.Context, _ smartmontools.LogAdapter, req smartmontools.CommandRequest) (*smartmontools.CommandResult, error) {
+	if err := ctx.Err(); err != nil {
+		return &smartmontools.CommandResult{ExitCode: -1}, err
+	}
+	args := smartctlArgs(req)
+	f, ok := r.fixtures[fixtureKey(args)]
+	if !ok {
+		return &smartmontools.CommandResult{ExitCode: -1}, fmt.Errorf("smartctl %s: %w", strings.Join(args, " "), ErrNoFixture)
+	}
+	res := f.result()
+	if res.ExitCode != 0 {
+		return res, fmt.Errorf("exit status %d: %w", res.ExitCode, &osexec.ExitError{})
+	}
+	return res, nil
+}
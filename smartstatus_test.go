@@ -126,6 +126,19 @@ func TestWearLevelPercent_SSD_Attr173(t *testing.T) {
 	assert.Equal(t, 42, *got)
 }
 
+func TestWearLevelPercent_SSD_SharesLifeRemainingAttributes(t *testing.T) {
+	info := &SMARTInfo{DiskType: "SSD", AtaSmartData: &AtaSmartData{Table: []SmartAttribute{
+		{ID: SmartAttrSSDLifeLeft, Name: "Temperature_Celsius", Value: 40},
+		{ID: SmartAttrMediaWearout, Name: "Media_Wearout_Indicator", Value: 97},
+	}}}
+	got := info.WearLevelPercent()
+	require.NotNil(t, got)
+	assert.Equal(t, 3, *got)
+	life, ok := GetSSDLifeRemaining(info)
+	require.True(t, ok)
+	assert.Equal(t, 100-*got, life.Percent)
+}
+
 func TestWearLevelPercent_HDD(t *testing.T) {
 	assert.Nil(t, (&SMARTInfo{DiskType: "HDD"}).WearLevelPercent())
 }
@@ -0,0 +1,93 @@
+package smartmontools
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SnapshotSchemaVersion is the schema version written by MarshalSnapshot.
+// Bump it, and append a step to snapshotMigrations, whenever a change to
+// SMARTInfo would make previously stored snapshots decode differently.
+const SnapshotSchemaVersion = 1
+
+// snapshotMigrations upgrade a decoded snapshot envelope one version at a
+// time: snapshotMigrations[v] turns version v into version v+1.
+var snapshotMigrations = []func(map[string]json.RawMessage) (map[string]json.RawMessage, error){
+	// Version 0 is the bare smartctl-compatible SMARTInfo JSON stored before
+	// snapshots were versioned. It lacks the computed disk type, which stays
+	// empty.
+	func(bare map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+		info, err := json.Marshal(bare)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]json.RawMessage{"info": info}, nil
+	},
+}
+
+// snapshotEnvelope is the serialized form of a snapshot. Info keeps the
+// smartctl-compatible JSON of SMARTInfo, so it stays readable by other tools;
+// fields SMARTInfo does not serialize, such as DiskType, are stored beside it.
+type snapshotEnvelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	DiskType      string          `json:"disk_type,omitempty"`
+	Info          json.RawMessage `json:"info"`
+}
+
+// MarshalSnapshot serializes info for storage, tagged with
+// SnapshotSchemaVersion so UnmarshalSnapshot can still load it after SMARTInfo
+// changes. Unlike json.Marshal it also keeps the computed DiskType.
+func MarshalSnapshot(info *SMARTInfo) ([]byte, error) {
+	if info == nil {
+		return nil, fmt.Errorf("marshal snapshot: nil SMARTInfo")
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("marshal snapshot: %w", err)
+	}
+	return json.Marshal(snapshotEnvelope{SchemaVersion: SnapshotSchemaVersion, DiskType: info.DiskType, Info: data})
+}
+
+// UnmarshalSnapshot loads a snapshot written by MarshalSnapshot of this or any
+// earlier library version, migrating it to the current schema. Bare SMARTInfo
+// JSON, as stored before snapshots were versioned, is read as version 0.
+// Snapshots from a newer version fail with ErrUnsupportedSnapshotVersion.
+// Warnings are classified again from the stored smartctl messages.
+func UnmarshalSnapshot(data []byte) (*SMARTInfo, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot: %w", err)
+	}
+	version := 0
+	if raw, ok := fields["schema_version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, fmt.Errorf("unmarshal snapshot: schema_version: %w", err)
+		}
+	}
+	if version > SnapshotSchemaVersion || version < 0 {
+		return nil, fmt.Errorf("%w %d (supported: up to %d)", ErrUnsupportedSnapshotVersion, version, SnapshotSchemaVersion)
+	}
+	for ; version < SnapshotSchemaVersion; version++ {
+		migrated, err := snapshotMigrations[version](fields)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal snapshot: migrate from version %d: %w", version, err)
+		}
+		fields = migrated
+	}
+
+	migrated, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot: %w", err)
+	}
+	var envelope snapshotEnvelope
+	if err := json.Unmarshal(migrated, &envelope); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot: %w", err)
+	}
+	info := &SMARTInfo{}
+	if err := json.Unmarshal(envelope.Info, info); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot: info: %w", err)
+	}
+	info.DiskType = envelope.DiskType
+	info.Warnings = ClassifyMessages(info.Smartctl)
+	return info, nil
+}
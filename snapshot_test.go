@@ -0,0 +1,53 @@
+package smartmontools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	info := &SMARTInfo{
+		ModelName:    "Test SSD",
+		SerialNumber: "SER1",
+		DiskType:     "SSD",
+		PowerOnTime:  &PowerOnTime{Hours: 10, Minutes: 5},
+		Extra:        map[string]json.RawMessage{"future_field": json.RawMessage(`{"x":1}`)},
+	}
+	data, err := MarshalSnapshot(info)
+	require.NoError(t, err)
+
+	var envelope map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &envelope))
+	assert.JSONEq(t, "1", string(envelope["schema_version"]))
+
+	got, err := UnmarshalSnapshot(data)
+	require.NoError(t, err)
+	assert.Equal(t, "SER1", got.SerialNumber)
+	assert.Equal(t, "SSD", got.DiskType)
+	assert.Equal(t, &PowerOnTime{Hours: 10, Minutes: 5}, got.PowerOnTime)
+	assert.JSONEq(t, `{"x":1}`, string(got.Extra["future_field"]))
+
+	_, err = MarshalSnapshot(nil)
+	assert.Error(t, err)
+}
+
+func TestUnmarshalSnapshot_Legacy(t *testing.T) {
+	// Bare SMARTInfo JSON, as stored before snapshots were versioned.
+	got, err := UnmarshalSnapshot([]byte(`{"model_name":"Old HDD","serial_number":"SER0","power_on_time":{"hours":42}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "SER0", got.SerialNumber)
+	assert.Equal(t, 42, got.PowerOnTime.Hours)
+	assert.Empty(t, got.DiskType)
+	assert.Nil(t, got.Extra)
+}
+
+func TestUnmarshalSnapshot_Errors(t *testing.T) {
+	_, err := UnmarshalSnapshot([]byte(`{"schema_version":99,"info":{}}`))
+	assert.ErrorIs(t, err, ErrUnsupportedSnapshotVersion)
+
+	_, err = UnmarshalSnapshot([]byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package smartmontools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TemperatureLimit returns the highest temperature the drive itself declares
+// acceptable, in Celsius: the top of its recommended operating range when
+// reported, otherwise its tolerated maximum, critical limit or SCSI trip
+// point. It returns false when info carries none of them.
+func TemperatureLimit(info *SMARTInfo) (int, bool) {
+	if info == nil || info.Temperature == nil {
+		return 0, false
+	}
+	t := info.Temperature
+	for _, limit := range []*int{t.OpLimitMax, t.LimitMax, t.CriticalLimitMax, t.DriveTrip} {
+		if limit != nil && *limit > 0 {
+			return *limit, true
+		}
+	}
+	return 0, false
+}
+
+// IsOverheating reports whether the drive's current temperature is above the
+// limit returned by TemperatureLimit. Drives that report no limit are never
+// considered overheating; apply a fixed threshold to those.
+func IsOverheating(info *SMARTInfo) bool {
+	limit, ok := TemperatureLimit(info)
+	if !ok {
+		return false
+	}
+	current, _ := CurrentTemperature(info)
+	return current > limit
+}
+
+// CurrentTemperature returns the current drive temperature in Celsius, from
+// the temperature section or else the NVMe health log. It returns false when
+// info reports neither.
+func CurrentTemperature(info *SMARTInfo) (int, bool) {
+	switch {
+	case info == nil:
+		return 0, false
+	case info.Temperature != nil && info.Temperature.Current != 0:
+		return info.Temperature.Current, true
+	case info.NvmeSmartHealth != nil && info.NvmeSmartHealth.Temperature != 0:
+		return info.NvmeSmartHealth.Temperature, true
+	case info.Temperature != nil:
+		return info.Temperature.Current, true
+	}
+	return 0, false
+}
+
+// CelsiusToFahrenheit converts a temperature from degrees Celsius to
+// degrees Fahrenheit.
+func CelsiusToFahrenheit(celsius float64) float64 {
+	return celsius*9/5 + 32
+}
+
+// FahrenheitToCelsius converts a temperature from degrees Fahrenheit to
+// degrees Celsius.
+func FahrenheitToCelsius(fahrenheit float64) float64 {
+	return (fahrenheit - 32) * 5 / 9
+}
+
+// TemperatureLevel grades a drive temperature against a TemperaturePolicy.
+type TemperatureLevel int
+
+const (
+	// TemperatureUnknown means the drive reported no temperature.
+	TemperatureUnknown TemperatureLevel = iota
+	// TemperatureNormal means the temperature is at or below the warning
+	// threshold.
+	TemperatureNormal
+	// TemperatureWarning means the temperature is above the warning threshold.
+	TemperatureWarning
+	// TemperatureCritical means the temperature is above the critical
+	// threshold.
+	TemperatureCritical
+)
+
+// String returns the temperature level name.
+func (l TemperatureLevel) String() string {
+	switch l {
+	case TemperatureNormal:
+		return "normal"
+	case TemperatureWarning:
+		return "warning"
+	case TemperatureCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l TemperatureLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so temperature statuses
+// and monitor events read back from JSON keep their level.
+func (l *TemperatureLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "unknown", "":
+		*l = TemperatureUnknown
+	case "normal":
+		*l = TemperatureNormal
+	case "warning":
+		*l = TemperatureWarning
+	case "critical":
+		*l = TemperatureCritical
+	default:
+		return fmt.Errorf("unknown temperature level %q", text)
+	}
+	return nil
+}
+
+// TemperatureThresholds are the Celsius temperatures above which a drive is
+// graded TemperatureWarning and TemperatureCritical. Zero disables a level.
+type TemperatureThresholds struct {
+	Warning  int `json:"warning"`
+	Critical int `json:"critical"`
+}
+
+// Level grades celsius against t.
+func (t TemperatureThresholds) Level(celsius int) TemperatureLevel {
+	switch {
+	case t.Critical > 0 && celsius > t.Critical:
+		return TemperatureCritical
+	case t.Warning > 0 && celsius > t.Warning:
+		return TemperatureWarning
+	}
+	return TemperatureNormal
+}
+
+// TemperaturePolicy holds the temperature thresholds of each device class,
+// so that a hot NVMe drive and a hot hard disk can be judged differently.
+type TemperaturePolicy struct {
+	// Classes maps a SMARTInfo.DiskType ("HDD", "SSD" or "NVMe") to its
+	// thresholds.
+	Classes map[string]TemperatureThresholds `json:"classes,omitempty"`
+	// Default applies to disk types missing from Classes.
+	Default TemperatureThresholds `json:"default"`
+}
+
+// DefaultTemperaturePolicy returns thresholds suited to common drives: hard
+// disks warn above 45 °C and are critical above 55 °C, SATA SSDs above 60 and
+// 70 °C, NVMe drives above 70 and 80 °C. Other drives use the hard disk
+// thresholds. Drives that report their own limits may be checked with
+// IsOverheating as well.
+func DefaultTemperaturePolicy() TemperaturePolicy {
+	hdd := TemperatureThresholds{Warning: 45, Critical: 55}
+	return TemperaturePolicy{
+		Classes: map[string]TemperatureThresholds{
+			"HDD":  hdd,
+			"SSD":  {Warning: 60, Critical: 70},
+			"NVMe": {Warning: 70, Critical: 80},
+		},
+		Default: hdd,
+	}
+}
+
+// TemperatureStatus is the result of TemperaturePolicy.Evaluate.
+type TemperatureStatus struct {
+	Level TemperatureLevel `json:"level"`
+	// Current is the temperature in Celsius; zero when Level is
+	// TemperatureUnknown.
+	Current    int                   `json:"current"`
+	Thresholds TemperatureThresholds `json:"thresholds"`
+}
+
+// Threshold returns the threshold exceeded at s.Level, or zero when the
+// temperature is normal or unknown.
+func (s TemperatureStatus) Threshold() int {
+	switch s.Level {
+	case TemperatureCritical:
+		return s.Thresholds.Critical
+	case TemperatureWarning:
+		return s.Thresholds.Warning
+	}
+	return 0
+}
+
+// Thresholds returns the thresholds that apply to the disk type of info.
+func (p TemperaturePolicy) Thresholds(info *SMARTInfo) TemperatureThresholds {
+	if info != nil {
+		if t, ok := p.Classes[info.DiskType]; ok {
+			return t
+		}
+	}
+	return p.Default
+}
+
+// Evaluate grades the current temperature of info against the thresholds of
+// its disk type.
+func (p TemperaturePolicy) Evaluate(info *SMARTInfo) TemperatureStatus {
+	status := TemperatureStatus{Thresholds: p.Thresholds(info)}
+	current, ok := CurrentTemperature(info)
+	if !ok {
+		return status
+	}
+	status.Current = current
+	status.Level = status.Thresholds.Level(current)
+	return status
+}
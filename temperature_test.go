@@ -0,0 +1,133 @@
+package smartmontools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemperatureLimits_ATA(t *testing.T) {
+	var info SMARTInfo
+	require.NoError(t, json.Unmarshal([]byte(`{
+"temperature": {
+  "current": 58,
+  "power_cycle_min": 24,
+  "power_cycle_max": 58,
+  "lifetime_min": 17,
+  "lifetime_max": 61,
+  "op_limit_max": 55,
+  "limit_min": -41,
+  "limit_max": 85
+}
+}`), &info))
+
+	require.NotNil(t, info.Temperature.OpLimitMax)
+	assert.Equal(t, 55, *info.Temperature.OpLimitMax)
+	require.NotNil(t, info.Temperature.LimitMin)
+	assert.Equal(t, -41, *info.Temperature.LimitMin)
+	limit, ok := TemperatureLimit(&info)
+	require.True(t, ok)
+	assert.Equal(t, 55, limit)
+	assert.True(t, IsOverheating(&info))
+
+	info.Temperature.Current = 55
+	assert.False(t, IsOverheating(&info))
+}
+
+func TestTemperatureLimits_Fallbacks(t *testing.T) {
+	limit := func(v int) *int { return &v }
+
+	nvme := &SMARTInfo{
+		Temperature:     &Temperature{CriticalLimitMax: limit(84)},
+		NvmeSmartHealth: &NvmeSmartHealth{Temperature: 90},
+	}
+	got, ok := TemperatureLimit(nvme)
+	require.True(t, ok)
+	assert.Equal(t, 84, got)
+	assert.True(t, IsOverheating(nvme))
+
+	scsi := &SMARTInfo{Temperature: &Temperature{Current: 40, DriveTrip: limit(65)}}
+	got, ok = TemperatureLimit(scsi)
+	require.True(t, ok)
+	assert.Equal(t, 65, got)
+	assert.False(t, IsOverheating(scsi))
+
+	// Without drive limits there is no verdict, however hot the drive is.
+	unknown := &SMARTInfo{Temperature: &Temperature{Current: 80}}
+	_, ok = TemperatureLimit(unknown)
+	assert.False(t, ok)
+	assert.False(t, IsOverheating(unknown))
+	assert.False(t, IsOverheating(nil))
+}
+
+func TestTemperatureConversion(t *testing.T) {
+	assert.InDelta(t, 32, CelsiusToFahrenheit(0), 1e-9)
+	assert.InDelta(t, 212, CelsiusToFahrenheit(100), 1e-9)
+	assert.InDelta(t, -40, CelsiusToFahrenheit(-40), 1e-9)
+	assert.InDelta(t, 45, FahrenheitToCelsius(113), 1e-9)
+	assert.InDelta(t, 37.5, FahrenheitToCelsius(CelsiusToFahrenheit(37.5)), 1e-9)
+}
+
+func TestCurrentTemperature(t *testing.T) {
+	_, ok := CurrentTemperature(nil)
+	assert.False(t, ok)
+	_, ok = CurrentTemperature(&SMARTInfo{})
+	assert.False(t, ok)
+	c, ok := CurrentTemperature(&SMARTInfo{Temperature: &Temperature{Current: 41}})
+	assert.True(t, ok)
+	assert.Equal(t, 41, c)
+	c, ok = CurrentTemperature(&SMARTInfo{NvmeSmartHealth: &NvmeSmartHealth{Temperature: 52}})
+	assert.True(t, ok)
+	assert.Equal(t, 52, c)
+}
+
+func TestTemperaturePolicy(t *testing.T) {
+	policy := DefaultTemperaturePolicy()
+	tests := []struct {
+		diskType string
+		temp     int
+		want     TemperatureLevel
+		limit    int
+	}{
+		{"HDD", 45, TemperatureNormal, 0},
+		{"HDD", 46, TemperatureWarning, 45},
+		{"HDD", 56, TemperatureCritical, 55},
+		{"SSD", 56, TemperatureNormal, 0},
+		{"SSD", 65, TemperatureWarning, 60},
+		{"NVMe", 65, TemperatureNormal, 0},
+		{"NVMe", 81, TemperatureCritical, 80},
+		{"Unknown", 50, TemperatureWarning, 45},
+	}
+	for _, tt := range tests {
+		status := policy.Evaluate(&SMARTInfo{DiskType: tt.diskType, Temperature: &Temperature{Current: tt.temp}})
+		assert.Equal(t, tt.want, status.Level, "%s at %d °C", tt.diskType, tt.temp)
+		assert.Equal(t, tt.temp, status.Current)
+		assert.Equal(t, tt.limit, status.Threshold(), "%s at %d °C", tt.diskType, tt.temp)
+	}
+
+	status := policy.Evaluate(&SMARTInfo{DiskType: "NVMe"})
+	assert.Equal(t, TemperatureUnknown, status.Level)
+	assert.Equal(t, TemperatureThresholds{Warning: 70, Critical: 80}, status.Thresholds)
+
+	warnOnly := TemperaturePolicy{Default: TemperatureThresholds{Warning: 50}}
+	assert.Equal(t, TemperatureWarning, warnOnly.Evaluate(&SMARTInfo{Temperature: &Temperature{Current: 90}}).Level,
+		"a zero threshold disables its level")
+
+	data, err := json.Marshal(status)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"level":"unknown","current":0,"thresholds":{"warning":70,"critical":80}}`, string(data))
+}
+
+func TestTemperatureLevel_Text(t *testing.T) {
+	for _, level := range []TemperatureLevel{TemperatureUnknown, TemperatureNormal, TemperatureWarning, TemperatureCritical} {
+		data, err := json.Marshal(TemperatureStatus{Level: level})
+		require.NoError(t, err)
+		var status TemperatureStatus
+		require.NoError(t, json.Unmarshal(data, &status))
+		assert.Equal(t, level, status.Level)
+	}
+	var level TemperatureLevel
+	assert.Error(t, level.UnmarshalText([]byte("hot")))
+}
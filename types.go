@@ -1,10 +1,21 @@
 package smartmontools
 
-import smtypes "github.com/dianlight/smartmontools-go/internal/types"
+import (
+	"context"
+	"time"
+
+	smtypes "github.com/dianlight/smartmontools-go/internal/types"
+)
 
 // Device represents a storage device.
 type Device = smtypes.Device
 
+// SysfsInfo describes a block device as the Linux kernel and udev see it.
+type SysfsInfo = smtypes.SysfsInfo
+
+// ScanOptions narrows a device scan to device types and interfaces.
+type ScanOptions = smtypes.ScanOptions
+
 // NvmeControllerCapabilities represents NVMe controller capabilities.
 type NvmeControllerCapabilities = smtypes.NvmeControllerCapabilities
 
@@ -17,6 +28,50 @@ type NvmeSmartTestLog = smtypes.NvmeSmartTestLog
 // UserCapacity represents storage device capacity information.
 type UserCapacity = smtypes.UserCapacity
 
+// WWN is the World Wide Name of a device.
+type WWN = smtypes.WWN
+
+// FormFactor is the nominal form factor of a drive.
+type FormFactor = smtypes.FormFactor
+
+// AtaVersion is the ATA standard a drive claims to support.
+type AtaVersion = smtypes.AtaVersion
+
+// SataVersion is the SATA revision a drive claims to support.
+type SataVersion = smtypes.SataVersion
+
+// InterfaceSpeed holds the supported and negotiated SATA link speeds.
+type InterfaceSpeed = smtypes.InterfaceSpeed
+
+// LinkSpeed is a SATA link speed.
+type LinkSpeed = smtypes.LinkSpeed
+
+// Trim describes a drive's TRIM/UNMAP support.
+type Trim = smtypes.Trim
+
+// ZonedDevice reports the zoned capabilities of a drive.
+type ZonedDevice = smtypes.ZonedDevice
+
+// ScsiDeviceType is the SCSI peripheral device type.
+type ScsiDeviceType = smtypes.ScsiDeviceType
+
+// ZonedModel is the zoned block model of a drive.
+type ZonedModel = smtypes.ZonedModel
+
+// Zoned block models.
+const (
+	ZonedNone          = smtypes.ZonedNone
+	ZonedDeviceManaged = smtypes.ZonedDeviceManaged
+	ZonedHostAware     = smtypes.ZonedHostAware
+	ZonedHostManaged   = smtypes.ZonedHostManaged
+)
+
+// NvmeNamespace is one NVMe namespace with its size, capacity and utilization.
+type NvmeNamespace = smtypes.NvmeNamespace
+
+// EUI64 is the IEEE Extended Unique Identifier of an NVMe namespace.
+type EUI64 = smtypes.EUI64
+
 // SMARTInfo represents comprehensive SMART information for a storage device.
 type SMARTInfo = smtypes.SMARTInfo
 
@@ -29,6 +84,12 @@ type SmartSupport = smtypes.SmartSupport
 // AtaSmartData represents ATA SMART attributes.
 type AtaSmartData = smtypes.AtaSmartData
 
+// AtaSmartErrorLog represents the ATA SMART error log summary.
+type AtaSmartErrorLog = smtypes.AtaSmartErrorLog
+
+// AtaErrorLogSummary holds the error counters of an ATA error log.
+type AtaErrorLogSummary = smtypes.AtaErrorLogSummary
+
 // StatusField represents a SMART status field.
 type StatusField = smtypes.StatusField
 
@@ -74,11 +135,314 @@ type Message = smtypes.Message
 // SmartctlInfo represents smartctl metadata and messages.
 type SmartctlInfo = smtypes.SmartctlInfo
 
+// WarningCode classifies a message smartctl printed with its output.
+type WarningCode = smtypes.WarningCode
+
+// Warning is a smartctl message with its WarningCode, as listed in
+// SMARTInfo.Warnings.
+type Warning = smtypes.Warning
+
+// Warning codes of smartctl messages.
+const (
+	WarningUnknownUSBBridge       = smtypes.WarningUnknownUSBBridge
+	WarningUnsupportedDevice      = smtypes.WarningUnsupportedDevice
+	WarningPermissionDenied       = smtypes.WarningPermissionDenied
+	WarningMandatoryCommandFailed = smtypes.WarningMandatoryCommandFailed
+	WarningOther                  = smtypes.WarningOther
+)
+
+// ClassifyMessage returns the WarningCode of a smartctl message text.
+func ClassifyMessage(text string) WarningCode {
+	return smtypes.ClassifyMessage(text)
+}
+
+// ClassifyMessages returns the messages of s as Warnings, in the order
+// smartctl printed them, or nil when there are none.
+func ClassifyMessages(s *SmartctlInfo) []Warning {
+	return smtypes.ClassifyMessages(s)
+}
+
+// ExtendedSMARTInfo is SMARTInfo plus the sections only "smartctl -x" reports.
+type ExtendedSMARTInfo = smtypes.ExtendedSMARTInfo
+
+// ExtendedSections holds the sections "smartctl -x" adds to the "-a" output.
+type ExtendedSections = smtypes.ExtendedSections
+
+// AtaSctStatus is the SCT status of an ATA device.
+type AtaSctStatus = smtypes.AtaSctStatus
+
+// SctDeviceState is the device activity state reported in the SCT status.
+type SctDeviceState = smtypes.SctDeviceState
+
+// SctTemperature holds the SCT status temperatures.
+type SctTemperature = smtypes.SctTemperature
+
+// SctSmartStatus is the SMART status field of the SCT status.
+type SctSmartStatus = smtypes.SctSmartStatus
+
+// SataPhyEventCounters is the SATA Phy event counters log.
+type SataPhyEventCounters = smtypes.SataPhyEventCounters
+
+// SataPhyEventCounter is a single SATA Phy event counter.
+type SataPhyEventCounter = smtypes.SataPhyEventCounter
+
+// AtaDeviceStatistics is the ATA device statistics log.
+type AtaDeviceStatistics = smtypes.AtaDeviceStatistics
+
+// DeviceStatisticsPage is one page of the device statistics log.
+type DeviceStatisticsPage = smtypes.DeviceStatisticsPage
+
+// DeviceStatistic is a single device statistics entry.
+type DeviceStatistic = smtypes.DeviceStatistic
+
+// DeviceStatisticFlags are the flags of a device statistic.
+type DeviceStatisticFlags = smtypes.DeviceStatisticFlags
+
+// AtaLogDirectory is the ATA log directory.
+type AtaLogDirectory = smtypes.AtaLogDirectory
+
+// LogDirectoryEntry describes one log address in the log directory.
+type LogDirectoryEntry = smtypes.LogDirectoryEntry
+
+// SmartctlVersionInfo describes the smartctl binary a backend runs.
+type SmartctlVersionInfo = smtypes.SmartctlVersionInfo
+
+// DriveDatabaseVersion is the drive database version reported by smartctl.
+type DriveDatabaseVersion = smtypes.DriveDatabaseVersion
+
 // ProgressCallback reports self-test progress.
 type ProgressCallback = smtypes.ProgressCallback
 
+// ProgressSource tells whether reported self-test progress was measured by
+// the drive or estimated.
+type ProgressSource = smtypes.ProgressSource
+
+// Self-test progress sources.
+const (
+	ProgressMeasured  = smtypes.ProgressMeasured
+	ProgressEstimated = smtypes.ProgressEstimated
+)
+
+// SelfTestOptions tunes how a self-test is run, e.g. in captive mode.
+type SelfTestOptions = smtypes.SelfTestOptions
+
+// SelfTestResult is the outcome of a finished self-test.
+type SelfTestResult = smtypes.SelfTestResult
+
+// SecureEraseInfo is the ATA security state of a drive with its secure-erase
+// time estimates.
+type SecureEraseInfo = smtypes.SecureEraseInfo
+
+// SelfTestOutcome classifies how a self-test ended.
+type SelfTestOutcome = smtypes.SelfTestOutcome
+
+// Self-test outcomes.
+const (
+	SelfTestPassed      = smtypes.SelfTestPassed
+	SelfTestFailed      = smtypes.SelfTestFailed
+	SelfTestAborted     = smtypes.SelfTestAborted
+	SelfTestInterrupted = smtypes.SelfTestInterrupted
+	SelfTestUnknown     = smtypes.SelfTestUnknown
+)
+
+// AtaSelfTestLog is the ATA SMART self-test log.
+type AtaSelfTestLog = smtypes.AtaSelfTestLog
+
+// AtaSelfTestLogTable holds the entries of one ATA self-test log.
+type AtaSelfTestLogTable = smtypes.AtaSelfTestLogTable
+
+// AtaSelfTestEntry is one ATA self-test log entry.
+type AtaSelfTestEntry = smtypes.AtaSelfTestEntry
+
+// NvmeSelfTestLog is the NVMe self-test log.
+type NvmeSelfTestLog = smtypes.NvmeSelfTestLog
+
+// NvmeSelfTestEntry is one NVMe self-test log entry.
+type NvmeSelfTestEntry = smtypes.NvmeSelfTestEntry
+
+// LBASpan is an inclusive range of logical block addresses.
+type LBASpan = smtypes.LBASpan
+
+// SelectiveSelfTestLog is the ATA selective self-test log.
+type SelectiveSelfTestLog = smtypes.SelectiveSelfTestLog
+
+// SelectiveSelfTestSpan is one span of the selective self-test log.
+type SelectiveSelfTestSpan = smtypes.SelectiveSelfTestSpan
+
+// SelectiveSelfTestFlags are the flags of the selective self-test log.
+type SelectiveSelfTestFlags = smtypes.SelectiveSelfTestFlags
+
+// MaxSelectiveSpans is the number of LBA spans a selective self-test covers.
+const MaxSelectiveSpans = smtypes.MaxSelectiveSpans
+
+// SelectiveContinuation picks selective self-test spans relative to the
+// previous run.
+type SelectiveContinuation = smtypes.SelectiveContinuation
+
+// Selective self-test continuations.
+const (
+	SelectiveNext = smtypes.SelectiveNext
+	SelectiveRedo = smtypes.SelectiveRedo
+	SelectiveCont = smtypes.SelectiveCont
+)
+
+// MaxPendingMinutes is the longest power-up delay of a remainder scan.
+const MaxPendingMinutes = smtypes.MaxPendingMinutes
+
+// SelectiveSelfTestOptions tunes a selective self-test: continuation of the
+// previous spans, remainder scan and its power-up delay.
+type SelectiveSelfTestOptions = smtypes.SelectiveSelfTestOptions
+
 // ExitCodeInfo breaks down the smartctl exit status into semantic groups.
 type ExitCodeInfo = smtypes.ExitCodeInfo
 
+// ExitStatus is the smartctl exit status bitmask with per-bit accessors.
+type ExitStatus = smtypes.ExitStatus
+
+// SmartctlError reports a smartctl run that exited with a non-zero status.
+type SmartctlError = smtypes.SmartctlError
+
+// Sentinel errors returned (wrapped) by SmartClient methods; test with errors.Is.
+var (
+	ErrSmartNotSupported = smtypes.ErrSmartNotSupported
+	ErrPermissionDenied  = smtypes.ErrPermissionDenied
+	ErrDeviceNotFound    = smtypes.ErrDeviceNotFound
+	ErrDeviceBusy        = smtypes.ErrDeviceBusy
+	ErrDeviceInStandby   = smtypes.ErrDeviceInStandby
+	ErrTestNotSupported  = smtypes.ErrTestNotSupported
+	ErrSelfTestAborted   = smtypes.ErrSelfTestAborted
+
+	ErrFeatureNotSupported   = smtypes.ErrFeatureNotSupported
+	ErrUnsupportedJSONFormat = smtypes.ErrUnsupportedJSONFormat
+	ErrVirtualDevice         = smtypes.ErrVirtualDevice
+	ErrCommandTimeout        = smtypes.ErrCommandTimeout
+)
+
+// VirtualDeviceError reports a virtual disk or LUN without SMART data; it
+// matches ErrVirtualDevice and ErrSmartNotSupported.
+type VirtualDeviceError = smtypes.VirtualDeviceError
+
+// CommandTimeoutError reports a smartctl invocation killed by its command
+// timeout; it matches ErrCommandTimeout and context.DeadlineExceeded.
+type CommandTimeoutError = smtypes.CommandTimeoutError
+
+// CommandCanceledError reports a smartctl invocation killed because the
+// context of the call ended, with the output printed until then.
+type CommandCanceledError = smtypes.CommandCanceledError
+
+// ErrUnsupportedSnapshotVersion is returned by UnmarshalSnapshot for snapshots
+// written by a newer library version.
+var ErrUnsupportedSnapshotVersion = smtypes.ErrUnsupportedSnapshotVersion
+
+// SupportedJSONFormatMajor is the smartctl json_format_version major version
+// the SMART types are modeled on.
+const SupportedJSONFormatMajor = smtypes.SupportedJSONFormatMajor
+
+// CheckJSONFormatVersion reports ErrUnsupportedJSONFormat for a
+// json_format_version with an unsupported major version.
+func CheckJSONFormatVersion(version []int) error {
+	return smtypes.CheckJSONFormatVersion(version)
+}
+
+// smartctl exit status bits.
+const (
+	ExitCommandLineError     = smtypes.ExitCommandLineError
+	ExitDeviceOpenFailed     = smtypes.ExitDeviceOpenFailed
+	ExitCommandFailed        = smtypes.ExitCommandFailed
+	ExitDiskFailing          = smtypes.ExitDiskFailing
+	ExitPrefailAttributes    = smtypes.ExitPrefailAttributes
+	ExitPastPrefailAttribute = smtypes.ExitPastPrefailAttribute
+	ExitErrorLogged          = smtypes.ExitErrorLogged
+	ExitSelfTestErrors       = smtypes.ExitSelfTestErrors
+	ExitExecMask             = smtypes.ExitExecMask
+	ExitHealthMask           = smtypes.ExitHealthMask
+)
+
+// PowerMode is the ATA power mode of a drive as reported by smartctl.
+type PowerMode = smtypes.PowerMode
+
+// Drive power modes.
+const (
+	PowerModeActive  = smtypes.PowerModeActive
+	PowerModeIdle    = smtypes.PowerModeIdle
+	PowerModeStandby = smtypes.PowerModeStandby
+	PowerModeSleep   = smtypes.PowerModeSleep
+	PowerModeUnknown = smtypes.PowerModeUnknown
+)
+
+// MaxStandbyTimer is the longest standby timer ATA can express.
+const MaxStandbyTimer = smtypes.MaxStandbyTimer
+
+// NoCheckMode is the smartctl --nocheck power mode below which drives are skipped.
+type NoCheckMode = smtypes.NoCheckMode
+
+// NoCheckPolicy controls whether SMART queries wake drives in a low-power mode.
+type NoCheckPolicy = smtypes.NoCheckPolicy
+
+// NoCheck modes.
+const (
+	NoCheckNever   = smtypes.NoCheckNever
+	NoCheckSleep   = smtypes.NoCheckSleep
+	NoCheckStandby = smtypes.NoCheckStandby
+	NoCheckIdle    = smtypes.NoCheckIdle
+)
+
+// DefaultNoCheckPolicy skips drives in standby and never wakes them.
+var DefaultNoCheckPolicy = smtypes.DefaultNoCheckPolicy
+
+// ParseNoCheckPolicy parses "never", "sleep", "standby", "idle" or "standby,N".
+func ParseNoCheckPolicy(s string) (NoCheckPolicy, error) {
+	return smtypes.ParseNoCheckPolicy(s)
+}
+
+// ContextWithNoCheck returns a context that overrides the client's
+// NoCheckPolicy for the calls made with it, e.g. to read a sleeping drive once.
+func ContextWithNoCheck(ctx context.Context, policy NoCheckPolicy) context.Context {
+	return smtypes.ContextWithNoCheck(ctx, policy)
+}
+
+// Tolerance is the smartctl -T level applied to ATA SMART command failures.
+type Tolerance = smtypes.Tolerance
+
+// Tolerance levels.
+const (
+	ToleranceConservative   = smtypes.ToleranceConservative
+	ToleranceNormal         = smtypes.ToleranceNormal
+	TolerancePermissive     = smtypes.TolerancePermissive
+	ToleranceVeryPermissive = smtypes.ToleranceVeryPermissive
+)
+
+// ContextWithTolerance returns a context that overrides the client's
+// Tolerance for the calls made with it, e.g. to read one flaky USB drive with
+// TolerancePermissive.
+func ContextWithTolerance(ctx context.Context, t Tolerance) context.Context {
+	return smtypes.ContextWithTolerance(ctx, t)
+}
+
+// ContextWithCommandTimeout returns a context that overrides the client's
+// command timeout for the calls made with it, e.g. to give a long "-x" read
+// more time or to fail fast on a drive known to hang. Zero or a negative
+// timeout disables the limit for those calls.
+func ContextWithCommandTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return smtypes.ContextWithCommandTimeout(ctx, timeout)
+}
+
+// CommandEvent describes a finished smartctl invocation. See WithCommandHook.
+type CommandEvent = smtypes.CommandEvent
+
+// CommandHook is called after every smartctl invocation.
+type CommandHook = smtypes.CommandHook
+
+// Fallback reasons reported in CommandEvent.Fallback.
+const (
+	FallbackDeviceType = smtypes.FallbackDeviceType
+	FallbackScan       = smtypes.FallbackScan
+	FallbackOpenRetry  = smtypes.FallbackOpenRetry
+)
+
+// DeviceOptions overrides the device type, extra smartctl arguments,
+// tolerance and --nocheck policy of one device. See WithDeviceOptions.
+type DeviceOptions = smtypes.DeviceOptions
+
 // DiscoveryResult holds the outcome of probing a single device during discovery.
 type DiscoveryResult = smtypes.DiscoveryResult
@@ -0,0 +1,32 @@
+package smartmontools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmartctlVersion(t *testing.T) {
+	client, err := NewClient(
+		WithSmartctlPath("/usr/sbin/smartctl"),
+		WithCommander(&mockCommander{cmds: map[string]*mockCmd{
+			"/usr/sbin/smartctl -j -V": {output: []byte(`{"smartctl":{"version":[7,3],"svn_revision":"5338","platform_info":"x86_64-linux-6.1.0"}}`)},
+		}}),
+	)
+	require.NoError(t, err)
+
+	v, err := client.SmartctlVersion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "7.3 r5338", v.String())
+	assert.Equal(t, "x86_64-linux-6.1.0", v.Platform)
+}
+
+func TestSmartctlVersion_UnsupportedBackend(t *testing.T) {
+	client, err := NewClient(WithBackend(plainBackend{}))
+	require.NoError(t, err)
+
+	_, err = client.SmartctlVersion(context.Background())
+	assert.ErrorContains(t, err, `backend "plain"`)
+}
@@ -0,0 +1,83 @@
+package smartmontools
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultWatchInterval is the Watch polling interval used for non-positive intervals.
+const DefaultWatchInterval = time.Minute
+
+// watchMaxBackoff caps the Watch retry delay at this multiple of the interval.
+const watchMaxBackoff = 8
+
+// Reading is one result delivered by Watch: either a SMART snapshot or the
+// error of a failed poll.
+type Reading struct {
+	DevicePath string
+	Time       time.Time
+	Info       *SMARTInfo
+	Err        error
+}
+
+// Watch polls the SMART information of devicePath every interval, starting
+// immediately, and delivers each snapshot or error on the returned channel so
+// callers can range over readings instead of writing a polling loop. Polls
+// that find the device in standby are skipped silently. After a failed poll
+// the delay doubles per consecutive failure, up to 8x interval, and returns
+// to interval after the next success. Readings are not dropped: polling pauses
+// until the consumer receives. The channel is closed when ctx is done.
+func (c *Client) Watch(ctx context.Context, devicePath string, interval time.Duration) <-chan Reading {
+	ctx = c.resolveCtx(ctx)
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	readings := make(chan Reading, 1)
+	go func() {
+		defer close(readings)
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		failures := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			info, err := c.GetSMARTInfo(ctx, devicePath)
+			if ctx.Err() != nil {
+				return
+			}
+			delay := interval
+			if err != nil {
+				failures++
+				delay = watchBackoff(interval, failures)
+			} else {
+				failures = 0
+			}
+			if err != nil || (info != nil && !info.InStandby) {
+				reading := Reading{DevicePath: devicePath, Time: time.Now(), Info: info, Err: err}
+				select {
+				case readings <- reading:
+				case <-ctx.Done():
+					return
+				}
+			}
+			timer.Reset(delay)
+		}
+	}()
+	return readings
+}
+
+// watchBackoff returns the delay after the given number of consecutive failures.
+func watchBackoff(interval time.Duration, failures int) time.Duration {
+	delay := interval
+	for range failures {
+		if delay >= interval*watchMaxBackoff {
+			return interval * watchMaxBackoff
+		}
+		delay *= 2
+	}
+	return min(delay, interval*watchMaxBackoff)
+}
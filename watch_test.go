@@ -0,0 +1,115 @@
+package smartmontools
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedBackend answers GetSMARTInfo with a fixed sequence of results and
+// repeats the last one once the script is exhausted.
+type scriptedBackend struct {
+	Backend
+	mu     sync.Mutex
+	script []scriptedResult
+	calls  []time.Time
+}
+
+type scriptedResult struct {
+	info *SMARTInfo
+	err  error
+}
+
+func (b *scriptedBackend) Name() string { return "scripted" }
+
+func (b *scriptedBackend) GetSMARTInfo(ctx context.Context, devicePath string) (*SMARTInfo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, time.Now())
+	r := b.script[0]
+	if len(b.script) > 1 {
+		b.script = b.script[1:]
+	}
+	return r.info, r.err
+}
+
+func TestWatch_SkipsStandbyAndReportsErrors(t *testing.T) {
+	backend := &scriptedBackend{script: []scriptedResult{
+		{info: &SMARTInfo{ModelName: "first"}},
+		{info: &SMARTInfo{InStandby: true}},
+		{err: errors.New("transient")},
+		{info: &SMARTInfo{ModelName: "second"}},
+	}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	readings := client.Watch(ctx, "/dev/sda", 5*time.Millisecond)
+
+	r := <-readings
+	require.NoError(t, r.Err)
+	assert.Equal(t, "first", r.Info.ModelName)
+	assert.Equal(t, "/dev/sda", r.DevicePath)
+	assert.False(t, r.Time.IsZero())
+
+	r = <-readings
+	assert.EqualError(t, r.Err, "transient")
+	assert.Nil(t, r.Info)
+
+	r = <-readings
+	require.NoError(t, r.Err)
+	assert.Equal(t, "second", r.Info.ModelName)
+
+	cancel()
+	for range readings {
+	}
+}
+
+func TestWatch_BacksOffAfterFailures(t *testing.T) {
+	backend := &scriptedBackend{script: []scriptedResult{{err: errors.New("down")}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	readings := client.Watch(ctx, "/dev/sda", 10*time.Millisecond)
+	for range 3 {
+		assert.Error(t, (<-readings).Err)
+	}
+	cancel()
+	for range readings {
+	}
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	require.GreaterOrEqual(t, len(backend.calls), 3)
+	assert.GreaterOrEqual(t, backend.calls[2].Sub(backend.calls[1]), 40*time.Millisecond)
+}
+
+func TestWatchBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, watchBackoff(time.Second, 0))
+	assert.Equal(t, 2*time.Second, watchBackoff(time.Second, 1))
+	assert.Equal(t, 4*time.Second, watchBackoff(time.Second, 2))
+	assert.Equal(t, 8*time.Second, watchBackoff(time.Second, 3))
+	assert.Equal(t, 8*time.Second, watchBackoff(time.Second, 50))
+}
+
+func TestWatch_ClosesOnCancel(t *testing.T) {
+	backend := &scriptedBackend{script: []scriptedResult{{info: &SMARTInfo{InStandby: true}}}}
+	client, err := NewClient(WithBackend(backend))
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var got []Reading
+	for r := range client.Watch(ctx, "/dev/sda", time.Millisecond) {
+		got = append(got, r)
+	}
+	assert.Empty(t, got, "standby polls produce no readings")
+}